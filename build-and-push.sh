@@ -28,9 +28,8 @@ cd ..
 
 echo ""
 echo "📦 Building and pushing location-tracker..."
-cd location-tracker
-docker buildx build --platform linux/amd64 -t ${ECR_REGISTRY}/location-tracker:latest --push .
-cd ..
+# Built from the repo root so the shared solid-poc module is in the context
+docker buildx build --platform linux/amd64 -f location-tracker/Dockerfile -t ${ECR_REGISTRY}/location-tracker:latest --push .
 
 echo ""
 echo "📦 Building and pushing code-fix-generator..."
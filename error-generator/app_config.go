@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"error-generator/config"
+)
+
+// appConfig holds every tunable, loaded at startup by initializeConfig
+var appConfig = config.Default()
+
+// initializeConfig loads the configuration from --config (default $CONFIG_FILE) and
+// the environment. --print-config shows the result with secrets redacted and
+// --check-config validates it; both exit without starting the generator.
+func initializeConfig(args []string) {
+	flags := flag.NewFlagSet("error-generator", flag.ExitOnError)
+	path := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file (default $CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
+	checkConfig := flags.Bool("check-config", false, "Validate the configuration and exit")
+	flags.Parse(args)
+
+	cfg, err := config.Load(*path)
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	if *printConfig {
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("❌ Failed to print config: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	err = cfg.Validate()
+	if *checkConfig {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Invalid configuration:\n%s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✅ Configuration ok")
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration:\n%v", err)
+	}
+
+	appConfig = cfg
+}
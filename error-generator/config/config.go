@@ -0,0 +1,173 @@
+/*
+# Module: config/config.go
+Typed configuration for the error generator: every tunable with its default, the
+file key it is read from and the environment variable that overrides it.
+
+Fields are tagged with their YAML/TOML key, an `env` variable name, and `secret`
+when the value must never be printed. The env names are the ones the generator has
+always read, so existing deployments keep working without a config file.
+
+## Linked Modules
+- [config/load](./load.go) - Reads a YAML or TOML file and applies env overrides
+
+## Tags
+config, settings, environment, defaults, validation
+
+## Exports
+Config, Server, Generation, Services, APIKeys, Models, Memes, Default, Validate
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "config/config.go" ;
+    code:description "Typed configuration for the error generator with defaults, file keys, env overrides and validation" ;
+    code:linksTo [
+        code:name "config/load" ;
+        code:path "./load.go" ;
+        code:relationship "Reads a YAML or TOML file and applies env overrides"
+    ] ;
+    code:exports :Config, :Server, :Generation, :Services, :APIKeys, :Models, :Memes, :Default, :Validate ;
+    code:tags "config", "settings", "environment", "defaults", "validation" .
+<!-- End LinkedDoc RDF -->
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Config is the complete error generator configuration.
+type Config struct {
+	Server     Server     `yaml:"server" toml:"server"`
+	Generation Generation `yaml:"generation" toml:"generation"`
+	Services   Services   `yaml:"services" toml:"services"`
+	APIKeys    APIKeys    `yaml:"api_keys" toml:"api_keys"`
+	Models     Models     `yaml:"models" toml:"models"`
+	Memes      Memes      `yaml:"memes" toml:"memes"`
+}
+
+// Server covers the HTTP listener for health, metrics and rhythm triggers.
+type Server struct {
+	Port            string        `yaml:"port" toml:"port" env:"ERROR_GENERATOR_PORT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	MetricsToken    string        `yaml:"metrics_token" toml:"metrics_token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// Generation controls how often error logs are made and what goes into them.
+type Generation struct {
+	IntervalSeconds   float64 `yaml:"interval_seconds" toml:"interval_seconds" env:"ERROR_INTERVAL_SECONDS"` // ignored in rhythm mode
+	GIFsPerError      int     `yaml:"gifs_per_error" toml:"gifs_per_error" env:"GIFS_PER_ERROR"`
+	MemeEvery         int     `yaml:"meme_every" toml:"meme_every" env:"MEME_EVERY"`                      // a meme is forced after this many errors without one
+	RhythmQueueSize   int     `yaml:"rhythm_queue_size" toml:"rhythm_queue_size" env:"RHYTHM_QUEUE_SIZE"` // triggers beyond this are dropped
+	SpotifySeedGenres string  `yaml:"spotify_seed_genres" toml:"spotify_seed_genres" env:"SPOTIFY_SEED_GENRES"`
+}
+
+// Services locates the other services; all but the slogan server are optional.
+type Services struct {
+	SloganServerURL    string `yaml:"slogan_server_url" toml:"slogan_server_url" env:"SLOGAN_SERVER_URL"`
+	LocationTrackerURL string `yaml:"location_tracker_url" toml:"location_tracker_url" env:"LOCATION_TRACKER_URL"`
+	FixGeneratorURL    string `yaml:"fix_generator_url" toml:"fix_generator_url" env:"FIX_GENERATOR_URL"`
+	RhythmServiceURL   string `yaml:"rhythm_service_url" toml:"rhythm_service_url" env:"RHYTHM_SERVICE_URL"` // enables rhythm mode
+}
+
+// APIKeys are the credentials for third-party APIs.
+type APIKeys struct {
+	Anthropic             string `yaml:"anthropic" toml:"anthropic" env:"ANTHROPIC_API_KEY" secret:"true"`
+	Giphy                 string `yaml:"giphy" toml:"giphy" env:"GIPHY_API_KEY" secret:"true"`
+	Pexels                string `yaml:"pexels" toml:"pexels" env:"PEXELS_API_KEY" secret:"true"`
+	Gemini                string `yaml:"gemini" toml:"gemini" env:"GEMINI_API_KEY" secret:"true"`
+	SpotifyClientID       string `yaml:"spotify_client_id" toml:"spotify_client_id" env:"SPOTIFY_CLIENT_ID"`
+	SpotifyClientSecret   string `yaml:"spotify_client_secret" toml:"spotify_client_secret" env:"SPOTIFY_CLIENT_SECRET" secret:"true"`
+	GCPServiceAccountJSON string `yaml:"gcp_service_account_json" toml:"gcp_service_account_json" env:"GCP_SERVICE_ACCOUNT_JSON" secret:"true"`
+}
+
+// Models names the AI models used for generated content.
+type Models struct {
+	Story      string `yaml:"story" toml:"story" env:"STORY_MODEL"`                   // Anthropic
+	MemePrompt string `yaml:"meme_prompt" toml:"meme_prompt" env:"MEME_PROMPT_MODEL"` // Gemini
+	MemeImage  string `yaml:"meme_image" toml:"meme_image" env:"MEME_IMAGE_MODEL"`    // Vertex AI Imagen
+}
+
+// Memes configures where meme images are generated and stored.
+type Memes struct {
+	GCPProjectID string `yaml:"gcp_project_id" toml:"gcp_project_id" env:"GCP_PROJECT_ID"`
+	GCPLocation  string `yaml:"gcp_location" toml:"gcp_location" env:"GCP_LOCATION"`
+	S3Bucket     string `yaml:"s3_bucket" toml:"s3_bucket" env:"S3_MEME_BUCKET"`
+	S3Region     string `yaml:"s3_region" toml:"s3_region" env:"AWS_REGION"`
+}
+
+// Default returns the configuration used when neither a file nor the environment sets a value.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            "9090",
+			ShutdownTimeout: 25 * time.Second,
+		},
+		Generation: Generation{
+			IntervalSeconds: 60,
+			GIFsPerError:    8,
+			MemeEvery:       8,
+			RhythmQueueSize: 10,
+		},
+		Services: Services{
+			SloganServerURL: "http://localhost:8080",
+		},
+		Models: Models{
+			Story:      "claude-sonnet-4-5-20250929",
+			MemePrompt: "gemini-2.0-flash-exp",
+			MemeImage:  "imagen-3.0-generate-001",
+		},
+		Memes: Memes{
+			GCPProjectID: "notspies",
+			GCPLocation:  "us-central1",
+			S3Bucket:     "error-generator-memes",
+			S3Region:     "us-east-1",
+		},
+	}
+}
+
+// Validate reports every invalid setting, or nil when the configuration can be used.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "server.port (ERROR_GENERATOR_PORT) %q must be a port number", c.Server.Port)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout (SHUTDOWN_TIMEOUT) must be a positive duration")
+
+	if c.Services.RhythmServiceURL == "" {
+		check(c.Generation.IntervalSeconds > 0, "generation.interval_seconds (ERROR_INTERVAL_SECONDS) must be positive")
+	}
+	check(c.Generation.GIFsPerError > 0, "generation.gifs_per_error (GIFS_PER_ERROR) must be positive")
+	check(c.Generation.MemeEvery > 0, "generation.meme_every (MEME_EVERY) must be positive")
+	check(c.Generation.RhythmQueueSize > 0, "generation.rhythm_queue_size (RHYTHM_QUEUE_SIZE) must be positive")
+
+	check(c.Services.SloganServerURL != "", "services.slogan_server_url (SLOGAN_SERVER_URL) must be set")
+	for _, service := range []struct{ key, value string }{
+		{"slogan_server_url (SLOGAN_SERVER_URL)", c.Services.SloganServerURL},
+		{"location_tracker_url (LOCATION_TRACKER_URL)", c.Services.LocationTrackerURL},
+		{"fix_generator_url (FIX_GENERATOR_URL)", c.Services.FixGeneratorURL},
+		{"rhythm_service_url (RHYTHM_SERVICE_URL)", c.Services.RhythmServiceURL},
+	} {
+		if service.value == "" {
+			continue
+		}
+		u, err := url.Parse(service.value)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"services.%s %q must be an absolute http(s) URL", service.key, service.value)
+	}
+
+	check(c.Models.Story != "", "models.story (STORY_MODEL) must be set")
+	check(c.Models.MemePrompt != "", "models.meme_prompt (MEME_PROMPT_MODEL) must be set")
+	check(c.Models.MemeImage != "", "models.meme_image (MEME_IMAGE_MODEL) must be set")
+
+	return errors.Join(errs...)
+}
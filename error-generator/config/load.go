@@ -0,0 +1,189 @@
+/*
+# Module: config/load.go
+Loads the configuration: defaults, then a YAML or TOML file, then environment
+overrides. Also renders it with secrets redacted for --print-config.
+
+File keys not known to Config are rejected, so a typo fails loudly instead of being
+ignored. An environment variable overrides the file only when it is non-empty; a
+value that doesn't parse is an error.
+
+## Linked Modules
+- [config/config](./config.go) - Config fields and their tags
+
+## Tags
+config, yaml, toml, environment, redaction
+
+## Exports
+Load, Redacted, WriteYAML
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "config/load.go" ;
+    code:description "Loads configuration from defaults, a YAML or TOML file and the environment" ;
+    code:linksTo [
+        code:name "config/config" ;
+        code:path "./config.go" ;
+        code:relationship "Config fields and their tags"
+    ] ;
+    code:exports :Load, :Redacted, :WriteYAML ;
+    code:tags "config", "yaml", "toml", "environment", "redaction" .
+<!-- End LinkedDoc RDF -->
+*/
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// Load returns the defaults overlaid with the file at path (skipped when path is
+// empty) and then the environment. It does not validate; call Validate for that.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeFile reads YAML (.yaml, .yml) or TOML (.toml) into cfg, leaving absent keys as they are
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parse %s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q (use .yaml, .yml or .toml)", path, ext)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides every field tagged env whose variable is set and non-empty
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		raw := os.Getenv(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		if err := setFromString(value, raw); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, raw, err)
+		}
+	}
+	return nil
+}
+
+// setFromString parses raw into a field of one of the kinds Config uses
+func setFromString(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy with every set secret replaced by a placeholder. Unset
+// secrets stay empty so the output still shows what is missing.
+func (c *Config) Redacted() *Config {
+	copied := *c
+	redact(reflect.ValueOf(&copied).Elem())
+	return &copied
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			redact(value)
+			continue
+		}
+		if field.Tag.Get("secret") == "true" && value.String() != "" {
+			value.SetString(redacted)
+		}
+	}
+}
+
+// WriteYAML writes the configuration, secrets redacted, in the file format Load reads.
+func (c *Config) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,68 @@
+/*
+# Module: health/checks.go
+Heartbeats for the background loops: the error loop and the rhythm trigger worker.
+
+## Linked Modules
+- [health/registry](./registry.go) - Registry the heartbeats are added to
+
+## Tags
+health, probes, background
+
+## Exports
+Heartbeat, NewHeartbeat
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "health/checks.go" ;
+    code:description "Heartbeats for background loops" ;
+    code:linksTo [
+        code:name "health/registry" ;
+        code:path "./registry.go" ;
+        code:relationship "Registry the heartbeats are added to"
+    ] ;
+    code:exports :Heartbeat, :NewHeartbeat ;
+    code:tags "health", "probes", "background" .
+<!-- End LinkedDoc RDF -->
+*/
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat shows a background loop is still going round. The loop calls Beat each
+// iteration; Check fails once no beat has arrived within MaxAge, which catches loops
+// that have exited or are stuck.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64 // unix nanoseconds
+}
+
+// NewHeartbeat returns a heartbeat that counts as beaten now.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+// Beat records that the loop is alive.
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last returns when the loop last beat.
+func (h *Heartbeat) Last() time.Time {
+	return time.Unix(0, h.last.Load())
+}
+
+// Check fails when the last beat is older than MaxAge.
+func (h *Heartbeat) Check(ctx context.Context) error {
+	if age := time.Since(h.Last()); age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s (expected every %s at most)", age.Round(time.Second), h.maxAge)
+	}
+	return nil
+}
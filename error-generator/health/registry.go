@@ -0,0 +1,216 @@
+/*
+# Module: health/registry.go
+Registry of named health checks with per-check timeouts and cached results, served as JSON.
+
+## Linked Modules
+- [health/checks](./checks.go) - Heartbeats to register
+
+## Tags
+health, probes, http, monitoring
+
+## Exports
+Check, Options, Result, Report, Registry, NewRegistry
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "health/registry.go" ;
+    code:description "Registry of named health checks with timeouts and cached results" ;
+    code:linksTo [
+        code:name "health/checks" ;
+        code:path "./checks.go" ;
+        code:relationship "Heartbeats to register"
+    ] ;
+    code:exports :Check, :Options, :Result, :Report, :Registry, :NewRegistry ;
+    code:tags "health", "probes", "http", "monitoring" .
+<!-- End LinkedDoc RDF -->
+*/
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Check reports whether one dependency or component is healthy; nil means healthy.
+// Checks should honour ctx, but a check that doesn't is abandoned at its timeout.
+type Check func(ctx context.Context) error
+
+// Options tune how a check is run.
+type Options struct {
+	Timeout  time.Duration // how long a run may take (default 2s)
+	CacheFor time.Duration // how long a result is reused before running again (default 10s)
+	Optional bool          // a failure is reported but doesn't fail the probe
+}
+
+const (
+	defaultTimeout  = 2 * time.Second
+	defaultCacheFor = 10 * time.Second
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Status     string    `json:"status"` // "ok" or "fail"
+	Error      string    `json:"error,omitempty"`
+	Optional   bool      `json:"optional,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+	DurationMS float64   `json:"duration_ms"`
+	Cached     bool      `json:"cached,omitempty"`
+}
+
+// Report is the outcome of every check in a registry. Status is "ok", "degraded" when
+// only optional checks failed, or "fail".
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+	Failed []string          `json:"failed,omitempty"`
+}
+
+type entry struct {
+	check Check
+	opts  Options
+
+	mu     sync.Mutex // held while running, so concurrent probes share one run
+	last   Result
+	hasRun bool
+}
+
+// Registry holds named checks. Probes run the checks concurrently and reuse each
+// check's last result for its CacheFor, so frequent probing stays cheap.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// NewRegistry returns an empty registry. With no checks its report is "ok".
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register adds or replaces the check called name.
+func (r *Registry) Register(name string, check Check, opts Options) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.CacheFor <= 0 {
+		opts.CacheFor = defaultCacheFor
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[name] = &entry{check: check, opts: opts}
+}
+
+// Run runs (or reuses) every check and reports the combined status.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make(map[string]*entry, len(r.entries))
+	for name, e := range r.entries {
+		entries[name] = e
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: "ok", Checks: make(map[string]Result, len(entries))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, e := range entries {
+		wg.Add(1)
+		go func(name string, e *entry) {
+			defer wg.Done()
+			result := e.run(ctx)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, e)
+	}
+	wg.Wait()
+
+	for name, result := range report.Checks {
+		if result.Status == "ok" {
+			continue
+		}
+		report.Failed = append(report.Failed, name)
+		if !result.Optional {
+			report.Status = "fail"
+		} else if report.Status == "ok" {
+			report.Status = "degraded"
+		}
+	}
+	sort.Strings(report.Failed)
+	return report
+}
+
+// Handler serves the report as JSON: 200 when the status is "ok" or "degraded", 503
+// when a required check failed.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		report := r.Run(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == "fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// run returns the cached result if it is fresh, otherwise runs the check
+func (e *entry) run(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.hasRun && time.Since(e.last.CheckedAt) < e.opts.CacheFor {
+		cached := e.last
+		cached.Cached = true
+		return cached
+	}
+
+	start := time.Now()
+	err := e.call(ctx)
+	result := Result{
+		Status:     "ok",
+		Optional:   e.opts.Optional,
+		CheckedAt:  start,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	// a probe cancelled by its caller says nothing about the dependency
+	if ctx.Err() == nil {
+		e.last, e.hasRun = result, true
+	}
+	return result
+}
+
+// call runs the check under its timeout, converting panics and overruns into errors
+func (e *entry) call(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- e.check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %s", e.opts.Timeout)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"error-generator/health"
+)
+
+// Health probes, served at /livez and /readyz in the same JSON shape as the other
+// services. Liveness fails only when a restart would help; readiness adds the
+// dependencies needed to do useful work.
+var (
+	livenessChecks  = health.NewRegistry()
+	readinessChecks = health.NewRegistry()
+
+	// lastErrorGenerated is when an error log last got a slogan back (unix nanoseconds)
+	lastErrorGenerated atomic.Int64
+)
+
+// recordErrorGenerated notes a successfully generated error log
+func recordErrorGenerated() {
+	lastErrorGenerated.Store(time.Now().UnixNano())
+}
+
+// generationMaxGap is how long a worker may go without finishing a generation before
+// it counts as stuck. A generation includes several slow API calls, so allow a few
+// intervals and at least five minutes.
+func generationMaxGap(interval time.Duration) time.Duration {
+	maxGap := 3 * interval
+	if maxGap < 5*time.Minute {
+		maxGap = 5 * time.Minute
+	}
+	return maxGap
+}
+
+// workerHeartbeat registers a liveness and readiness heartbeat for a background worker
+// that beats at least every maxAge. Call it from the worker and Beat on each iteration.
+func workerHeartbeat(name string, maxAge time.Duration) *health.Heartbeat {
+	heartbeat := health.NewHeartbeat(maxAge)
+	for _, registry := range []*health.Registry{livenessChecks, readinessChecks} {
+		registry.Register("goroutine:"+name, heartbeat.Check, health.Options{Timeout: time.Second, CacheFor: time.Second})
+	}
+	return heartbeat
+}
+
+// initializeHealthChecks registers the readiness checks for this mode. The worker
+// heartbeats are registered by the workers. In normal mode the error loop must produce
+// an error log within generationMaxGap; in rhythm mode errors depend on triggers, so
+// readiness watches the trigger queue instead.
+func initializeHealthChecks(sloganServerURL string, interval time.Duration) {
+	lastErrorGenerated.Store(time.Now().UnixNano()) // grace period from startup
+
+	readinessChecks.Register("slogan_server", func(ctx context.Context) error {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, sloganServerURL+"/health", nil)
+		if err != nil {
+			return err
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("slogan server returned status: %d", response.StatusCode)
+		}
+		return nil
+	}, health.Options{Timeout: 3 * time.Second, CacheFor: 30 * time.Second})
+
+	if rhythmModeEnabled {
+		readinessChecks.Register("rhythm_trigger_queue", func(ctx context.Context) error {
+			if depth := len(rhythmTriggerChan); depth >= cap(rhythmTriggerChan) {
+				return fmt.Errorf("trigger queue full (%d/%d), new triggers are dropped", depth, cap(rhythmTriggerChan))
+			}
+			return nil
+		}, health.Options{Timeout: time.Second, CacheFor: time.Second})
+		return
+	}
+
+	maxGap := generationMaxGap(interval)
+	readinessChecks.Register("last_error_generated", func(ctx context.Context) error {
+		last := time.Unix(0, lastErrorGenerated.Load())
+		if age := time.Since(last); age > maxGap {
+			return fmt.Errorf("last error log generated %s (%s ago, want within %s)",
+				last.UTC().Format(time.RFC3339), age.Round(time.Second), maxGap)
+		}
+		return nil
+	}, health.Options{Timeout: time.Second, CacheFor: time.Second})
+}
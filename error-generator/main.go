@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -464,7 +466,7 @@ var locationTrackerHTTPClient = &http.Client{
 // Global variables for rhythm mode integration
 var (
 	rhythmModeEnabled     = false
-	rhythmTriggerChan     = make(chan RhythmTrigger, 10)
+	rhythmTriggerChan     chan RhythmTrigger // sized by generation.rhythm_queue_size
 	globalGifCache        *GifCache
 	globalSpotifyCache    *SpotifyCache
 	globalFoodImageCache  *FoodImageCache
@@ -619,6 +621,7 @@ func (gifCache *GifCache) loadGifsFromGiphy(searchTerm string) error {
 		gifCache.lastRefresh = time.Now()
 		gifCache.currentIndex = 0
 		gifCache.refreshNeeded = false
+		cacheSize.WithLabelValues("gif").Set(float64(len(gifCache.gifURLs)))
 		return nil
 	}
 
@@ -668,6 +671,7 @@ func (gifCache *GifCache) loadGifsFromGiphy(searchTerm string) error {
 	gifCache.lastRefresh = time.Now()
 	gifCache.currentIndex = 0
 	gifCache.refreshNeeded = false
+	cacheSize.WithLabelValues("gif").Set(float64(len(gifCache.gifURLs)))
 
 	log.Printf("🎬 Loaded %d GIF URLs from Giphy (search: '%s')", len(gifCache.gifURLs), searchTerm)
 	return nil
@@ -682,6 +686,7 @@ func (gifCache *GifCache) getNextGif(errorMessage string) string {
 
 	// If we need to refresh or the search term changed, load new GIFs
 	needsRefresh := gifCache.refreshNeeded || len(gifCache.gifURLs) == 0 || searchTerm != gifCache.lastSearchTerm
+	recordCacheLookup("gif", !needsRefresh)
 
 	if needsRefresh {
 		if err := gifCache.loadGifsFromGiphy(searchTerm); err != nil {
@@ -727,6 +732,7 @@ func (gifCache *GifCache) getMultipleGifs(errorMessage string, userNotes string,
 	}
 
 	// Load GIFs with search term
+	recordCacheLookup("gif", false)
 	if err := gifCache.loadGifsFromGiphy(searchTerm); err != nil {
 		log.Printf("Error loading multiple GIFs: %v", err)
 		// Return fallback GIFs
@@ -736,8 +742,8 @@ func (gifCache *GifCache) getMultipleGifs(errorMessage string, userNotes string,
 		}
 	}
 
-	// Return up to 8 GIFs
-	maxGifs := 8
+	// Return up to generation.gifs_per_error GIFs
+	maxGifs := appConfig.Generation.GIFsPerError
 	if len(gifCache.gifURLs) < maxGifs {
 		maxGifs = len(gifCache.gifURLs)
 	}
@@ -946,6 +952,7 @@ func (cache *FoodImageCache) getNextFoodImage(errorMessage string) FoodImage {
 
 	// If we need to refresh or the search term changed, load new images
 	needsRefresh := cache.refreshNeeded || len(cache.foodImages) == 0 || searchTerm != cache.lastSearchTerm
+	recordCacheLookup("food_image", !needsRefresh)
 
 	if needsRefresh {
 		if err := cache.loadFoodImagesFromPexels(searchTerm); err != nil {
@@ -976,6 +983,7 @@ func (cache *FoodImageCache) getNextFoodImage(errorMessage string) FoodImage {
 		}
 	}
 
+	cacheSize.WithLabelValues("food_image").Set(float64(len(cache.foodImages)))
 	foodImage := cache.foodImages[cache.currentIndex]
 	cache.currentIndex++
 
@@ -1178,7 +1186,9 @@ func (spotifyCache *SpotifyCache) getNextSong() Song {
 	defer spotifyCache.mu.Unlock()
 
 	// Refresh song pool every hour or if needed
-	if spotifyCache.refreshNeeded || len(spotifyCache.songs) == 0 || time.Since(spotifyCache.lastRefresh) > time.Hour {
+	needsRefresh := spotifyCache.refreshNeeded || len(spotifyCache.songs) == 0 || time.Since(spotifyCache.lastRefresh) > time.Hour
+	recordCacheLookup("spotify", !needsRefresh)
+	if needsRefresh {
 		if err := spotifyCache.loadSongsFromSpotify(); err != nil {
 			log.Printf("⚠️  Error loading songs: %v, using cult classic soundtracks + originals", err)
 			// Use cult classic soundtracks + originals on error
@@ -1211,6 +1221,8 @@ func (spotifyCache *SpotifyCache) getNextSong() Song {
 		}
 	}
 
+	cacheSize.WithLabelValues("spotify").Set(float64(len(spotifyCache.songs)))
+
 	// Ensure we have songs available
 	if len(spotifyCache.songs) == 0 {
 		log.Printf("⚠️  No songs available in pool")
@@ -1884,7 +1896,7 @@ Return ONLY valid HTML (no markdown code fences). Make collapsible details flow
 
 	// Create Anthropic API request
 	reqBody := AnthropicRequest{
-		Model:     "claude-sonnet-4-5-20250929",
+		Model:     appConfig.Models.Story,
 		MaxTokens: 800,
 		Messages: []AnthropicMessage{
 			{
@@ -1990,8 +2002,10 @@ func handleRhythmTrigger(responseWriter http.ResponseWriter, request *http.Reque
 	// Send trigger to channel (non-blocking)
 	select {
 	case rhythmTriggerChan <- trigger:
+		rhythmTriggersTotal.WithLabelValues("queued").Inc()
 		log.Printf("✓ Trigger queued for processing")
 	default:
+		rhythmTriggersTotal.WithLabelValues("dropped").Inc()
 		log.Printf("⚠️  Trigger channel full, skipping")
 	}
 
@@ -2009,6 +2023,37 @@ func handleHealthCheck(responseWriter http.ResponseWriter, request *http.Request
 	fmt.Fprintf(responseWriter, "OK - Error Generator (Rhythm Mode: %v)", rhythmModeEnabled)
 }
 
+// processRhythmTriggers works through queued triggers. Once ctx is done it drains the
+// triggers already queued, so accepted triggers aren't lost on shutdown. It beats while
+// idle and after each trigger, so only a trigger stuck past generationMaxGap fails liveness.
+func processRhythmTriggers(ctx context.Context) error {
+	heartbeat := workerHeartbeat("rhythm_triggers", generationMaxGap(0))
+	idle := time.NewTicker(time.Minute)
+	defer idle.Stop()
+
+	for {
+		select {
+		case trigger := <-rhythmTriggerChan:
+			processRhythmTrigger(trigger)
+			heartbeat.Beat()
+		case <-idle.C:
+			heartbeat.Beat()
+		case <-ctx.Done():
+			if queued := len(rhythmTriggerChan); queued > 0 {
+				log.Printf("🎵 Draining %d queued rhythm triggers before shutdown", queued)
+			}
+			for {
+				select {
+				case trigger := <-rhythmTriggerChan:
+					processRhythmTrigger(trigger)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
 func processRhythmTrigger(trigger RhythmTrigger) {
 	log.Printf("🎼 Processing %s trigger (beat %d)", trigger.ErrorType, trigger.Beat)
 
@@ -2075,7 +2120,7 @@ func processRhythmTrigger(trigger RhythmTrigger) {
 
 	globalGifCache.mu.Lock()
 	globalGifCache.loadGifsFromGiphy(gifSearchTerm)
-	maxGifs := 8
+	maxGifs := appConfig.Generation.GIFsPerError
 	if len(globalGifCache.gifURLs) < maxGifs {
 		maxGifs = len(globalGifCache.gifURLs)
 	}
@@ -2159,22 +2204,22 @@ func processRhythmTrigger(trigger RhythmTrigger) {
 
 	// Generate absurdist meme conditionally:
 	// 1. Always generate for errors with tips/SMS (when we add that functionality)
-	// 2. Generate every 8th error without a meme to maintain some meme generation
+	// 2. Generate every generation.meme_every errors without a meme to maintain some meme generation
 	var memeURL string
 	shouldGenerateMeme := false
 
 	// Check if we should generate a meme
 	errorCounterMutex.Lock()
 	errorCounterNoMeme++
-	if errorCounterNoMeme >= 8 {
+	if errorCounterNoMeme >= appConfig.Generation.MemeEvery {
 		shouldGenerateMeme = true
 		errorCounterNoMeme = 0
-		log.Printf("🎲 Generating meme for 8th error without meme")
+		log.Printf("🎲 Generating meme after %d errors without meme", appConfig.Generation.MemeEvery)
 	}
 	errorCounterMutex.Unlock()
 
 	// Generate meme if conditions are met
-	if shouldGenerateMeme && os.Getenv("GEMINI_API_KEY") != "" && os.Getenv("GEMINI_API_KEY") != "YOUR_GEMINI_API_KEY_HERE" {
+	if shouldGenerateMeme && appConfig.APIKeys.Gemini != "" && appConfig.APIKeys.Gemini != "YOUR_GEMINI_API_KEY_HERE" {
 		meme, err := GenerateMemeForError(errorMessage, sloganResponse.Slogan, sloganResponse.VerboseDesc, childrensStory)
 		if err != nil {
 			log.Printf("Warning: Failed to generate meme: %v", err)
@@ -2232,58 +2277,61 @@ func processRhythmTrigger(trigger RhythmTrigger) {
 	}
 }
 
-func startHTTPServer(port string) {
-	http.HandleFunc("/api/rhythm-trigger", handleRhythmTrigger)
+// startHTTPServer serves health probes and /metrics, and rhythm triggers in rhythm mode
+func startHTTPServer(port string) *http.Server {
+	if rhythmModeEnabled {
+		http.HandleFunc("/api/rhythm-trigger", handleRhythmTrigger)
+	}
 	http.HandleFunc("/health", handleHealthCheck)
+	http.Handle("/livez", livenessChecks.Handler())
+	http.Handle("/readyz", readinessChecks.Handler())
 
-	log.Printf("🎵 Starting HTTP server on port %s for rhythm triggers...", port)
+	log.Printf("🎵 Starting HTTP server on port %s (health, metrics, rhythm triggers: %v)...", port, rhythmModeEnabled)
 
+	server := &http.Server{Addr: ":" + port, Handler: instrumentRoutes(http.DefaultServeMux)}
 	go func() {
-		if err := http.ListenAndServe(":"+port, nil); err != nil {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Printf("HTTP server error: %v", err)
 		}
 	}()
+	return server
 }
 
 func main() {
+	// Load and validate the configuration (exits for --print-config and --check-config)
+	initializeConfig(os.Args[1:])
+
 	rand.Seed(time.Now().UnixNano())
+	initializeMetrics()
 
-	giphyAPIKey := os.Getenv("GIPHY_API_KEY")
-	pexelsAPIKey := os.Getenv("PEXELS_API_KEY")
-	spotifyClientID := os.Getenv("SPOTIFY_CLIENT_ID")
-	spotifyClientSecret := os.Getenv("SPOTIFY_CLIENT_SECRET")
-	spotifySeedGenres := os.Getenv("SPOTIFY_SEED_GENRES")
+	giphyAPIKey := appConfig.APIKeys.Giphy
+	pexelsAPIKey := appConfig.APIKeys.Pexels
+	spotifyClientID := appConfig.APIKeys.SpotifyClientID
+	spotifyClientSecret := appConfig.APIKeys.SpotifyClientSecret
+	spotifySeedGenres := appConfig.Generation.SpotifySeedGenres
 
-	sloganServerURL := os.Getenv("SLOGAN_SERVER_URL")
-	if sloganServerURL == "" {
-		sloganServerURL = "http://localhost:8080"
-	}
+	sloganServerURL := appConfig.Services.SloganServerURL
 
 	// Location tracker URL (optional)
-	locationTrackerURL := os.Getenv("LOCATION_TRACKER_URL")
+	locationTrackerURL := appConfig.Services.LocationTrackerURL
 
 	// Fix generator URL (optional)
-	fixGeneratorURL := os.Getenv("FIX_GENERATOR_URL")
+	fixGeneratorURL := appConfig.Services.FixGeneratorURL
 
 	// Anthropic API key for children's story generation (optional)
-	anthropicAPIKey := os.Getenv("ANTHROPIC_API_KEY")
+	anthropicAPIKey := appConfig.APIKeys.Anthropic
 
 	// Rhythm mode configuration
-	rhythmServiceURL := os.Getenv("RHYTHM_SERVICE_URL")
+	rhythmServiceURL := appConfig.Services.RhythmServiceURL
 	if rhythmServiceURL != "" {
 		rhythmModeEnabled = true
 	}
+	rhythmTriggerChan = make(chan RhythmTrigger, appConfig.Generation.RhythmQueueSize)
 
 	// HTTP server port for rhythm triggers
-	httpServerPort := os.Getenv("ERROR_GENERATOR_PORT")
-	if httpServerPort == "" {
-		httpServerPort = "9090"
-	}
+	httpServerPort := appConfig.Server.Port
 
-	intervalSeconds := 60.0
-	if envInterval := os.Getenv("ERROR_INTERVAL_SECONDS"); envInterval != "" {
-		fmt.Sscanf(envInterval, "%f", &intervalSeconds)
-	}
+	intervalSeconds := appConfig.Generation.IntervalSeconds
 
 	log.Printf("Error Generator starting...")
 	log.Printf("Slogan server URL: %s", sloganServerURL)
@@ -2322,20 +2370,17 @@ func main() {
 		log.Printf("🍽️  Pexels API key configured for food blog images")
 	}
 
-	// Start HTTP server for rhythm triggers if rhythm mode is enabled
-	if rhythmModeEnabled {
-		startHTTPServer(httpServerPort)
+	// Convert interval to duration (handle decimal seconds)
+	intervalDuration := time.Duration(intervalSeconds * float64(time.Second))
 
-		// Process rhythm triggers from channel
-		go func() {
-			for trigger := range rhythmTriggerChan {
-				processRhythmTrigger(trigger)
-			}
-		}()
+	// Start HTTP server for health, metrics and (in rhythm mode) triggers
+	initializeHealthChecks(sloganServerURL, intervalDuration)
+	server := startHTTPServer(httpServerPort)
+
+	if rhythmModeEnabled {
+		background.Go("rhythm_triggers", processRhythmTriggers)
 	}
 
-	// Convert interval to duration (handle decimal seconds)
-	intervalDuration := time.Duration(intervalSeconds * float64(time.Second))
 	ticker := time.NewTicker(intervalDuration)
 	defer ticker.Stop()
 
@@ -2395,7 +2440,7 @@ func main() {
 
 		gifCache.mu.Lock()
 		gifCache.loadGifsFromGiphy(gifSearchTerm)
-		maxGifs := 8
+		maxGifs := appConfig.Generation.GIFsPerError
 		if len(gifCache.gifURLs) < maxGifs {
 			maxGifs = len(gifCache.gifURLs)
 		}
@@ -2441,6 +2486,7 @@ func main() {
 		}
 
 		log.Printf("Received response: %s %s", sloganResponse.Emoji, sloganResponse.Slogan)
+		recordErrorGenerated()
 
 		// Generate satirical fix if configured (use "basic" as default error type for non-rhythm mode)
 		var satiricalFix string
@@ -2488,22 +2534,22 @@ func main() {
 
 	// Generate absurdist meme conditionally:
 	// 1. Always generate for errors with tips/SMS (when we add that functionality)
-	// 2. Generate every 8th error without a meme to maintain some meme generation
+	// 2. Generate every generation.meme_every errors without a meme to maintain some meme generation
 	var memeURL string
 	shouldGenerateMeme := false
 
 	// Check if we should generate a meme
 	errorCounterMutex.Lock()
 	errorCounterNoMeme++
-	if errorCounterNoMeme >= 8 {
+	if errorCounterNoMeme >= appConfig.Generation.MemeEvery {
 		shouldGenerateMeme = true
 		errorCounterNoMeme = 0
-		log.Printf("🎲 Generating meme for 8th error without meme")
+		log.Printf("🎲 Generating meme after %d errors without meme", appConfig.Generation.MemeEvery)
 	}
 	errorCounterMutex.Unlock()
 
 	// Generate meme if conditions are met
-	if shouldGenerateMeme && os.Getenv("GEMINI_API_KEY") != "" && os.Getenv("GEMINI_API_KEY") != "YOUR_GEMINI_API_KEY_HERE" {
+	if shouldGenerateMeme && appConfig.APIKeys.Gemini != "" && appConfig.APIKeys.Gemini != "YOUR_GEMINI_API_KEY_HERE" {
 		meme, err := GenerateMemeForError(errorMessage, sloganResponse.Slogan, sloganResponse.VerboseDesc, childrensStory)
 		if err != nil {
 			log.Printf("Warning: Failed to generate meme: %v", err)
@@ -2582,19 +2628,26 @@ func main() {
 		}
 	}
 
-	// In rhythm mode, just keep server running
+	// In rhythm mode, triggers drive generation
 	// In normal mode, generate errors periodically
 	if rhythmModeEnabled {
 		log.Printf("🎵 Rhythm mode active - waiting for triggers from rhythm service...")
 		log.Printf("Send triggers to: http://localhost:%s/api/rhythm-trigger", httpServerPort)
-
-		// Keep the program running
-		select {}
 	} else {
-		generateAndSendError()
-
-		for range ticker.C {
+		background.Go("error_loop", func(ctx context.Context) error {
+			heartbeat := workerHeartbeat("error_loop", generationMaxGap(intervalDuration))
 			generateAndSendError()
-		}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+				heartbeat.Beat()
+				generateAndSendError()
+			}
+		})
 	}
+
+	waitForShutdown(server)
 }
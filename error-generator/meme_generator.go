@@ -14,13 +14,12 @@ import (
 	"log"
 	"math"
 	"net/http"
-	"os"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
 	"golang.org/x/image/font"
@@ -199,7 +198,7 @@ func extractKeywords(errorMessage, slogan, verboseDesc, story string) []string {
 
 // generateMemePromptWithGemini uses Gemini to create a brand bible-aligned meme concept
 func generateMemePromptWithGemini(keywords []string) (*MemePrompt, error) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
+	apiKey := appConfig.APIKeys.Gemini
 	if apiKey == "" || apiKey == "YOUR_GEMINI_API_KEY_HERE" {
 		return nil, fmt.Errorf("GEMINI_API_KEY not set")
 	}
@@ -222,7 +221,7 @@ func generateMemePromptWithGemini(keywords []string) (*MemePrompt, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash-exp:generateContent?key=%s", apiKey)
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", appConfig.Models.MemePrompt, apiKey)
 
 	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -269,12 +268,11 @@ func generateMemePromptWithGemini(keywords []string) (*MemePrompt, error) {
 }
 
 // getVertexAIAccessToken gets an OAuth2 access token from the service account
-// SECURITY: Now uses GCP_SERVICE_ACCOUNT_JSON environment variable instead of file
+// SECURITY: Uses api_keys.gcp_service_account_json (GCP_SERVICE_ACCOUNT_JSON) instead of a file
 func getVertexAIAccessToken(ctx context.Context) (string, error) {
-	// Read service account JSON from environment variable
-	credsJSON := os.Getenv("GCP_SERVICE_ACCOUNT_JSON")
+	credsJSON := appConfig.APIKeys.GCPServiceAccountJSON
 	if credsJSON == "" {
-		return "", fmt.Errorf("GCP_SERVICE_ACCOUNT_JSON environment variable not set")
+		return "", fmt.Errorf("GCP_SERVICE_ACCOUNT_JSON not set")
 	}
 
 	// Create credentials from JSON
@@ -294,15 +292,8 @@ func getVertexAIAccessToken(ctx context.Context) (string, error) {
 
 // generateMemeImageWithGemini generates an image using Vertex AI Imagen API
 func generateMemeImageWithGemini(imagePrompt string) ([]byte, error) {
-	projectID := os.Getenv("GCP_PROJECT_ID")
-	location := os.Getenv("GCP_LOCATION")
-
-	if projectID == "" {
-		projectID = "notspies"
-	}
-	if location == "" {
-		location = "us-central1"
-	}
+	projectID := appConfig.Memes.GCPProjectID
+	location := appConfig.Memes.GCPLocation
 
 	// Get OAuth2 access token
 	ctx := context.Background()
@@ -312,9 +303,8 @@ func generateMemeImageWithGemini(imagePrompt string) ([]byte, error) {
 	}
 
 	// Build Vertex AI Imagen endpoint
-	// Using imagen-3.0-generate-001 model
-	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/imagen-3.0-generate-001:predict",
-		location, projectID, location)
+	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+		location, projectID, location, appConfig.Models.MemeImage)
 
 	// Create request body
 	requestBody := map[string]interface{}{
@@ -560,17 +550,10 @@ func drawString(img *image.RGBA, x, y int, text string, col color.Color) {
 
 // uploadMemeToS3 uploads the generated meme image to S3
 func uploadMemeToS3(imageData []byte, mimeType string) (string, error) {
-	bucket := os.Getenv("S3_MEME_BUCKET")
-	if bucket == "" {
-		bucket = "error-generator-memes"
-	}
-
-	region := os.Getenv("AWS_REGION")
-	if region == "" {
-		region = "us-east-1"
-	}
+	bucket := appConfig.Memes.S3Bucket
+	region := appConfig.Memes.S3Region
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO(), awsconfig.WithRegion(region))
 	if err != nil {
 		return "", fmt.Errorf("failed to load AWS config: %w", err)
 	}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"error-generator/supervisor"
+)
+
+// background runs the error loop and the rhythm trigger worker; workers that crash are
+// restarted, and on SIGTERM they finish the case in hand
+var background = supervisor.New(context.Background(), supervisor.Options{})
+
+// waitForShutdown blocks until SIGINT or SIGTERM, then within server.shutdown_timeout
+// stops the HTTP server, so no new rhythm triggers are accepted, and lets the workers
+// finish the error log in hand and any queued triggers
+func waitForShutdown(server *http.Server) {
+	timeout := appConfig.Server.ShutdownTimeout
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	log.Printf("🛑 Shutting down (up to %s)...", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  HTTP server did not drain: %v", err)
+	}
+	if err := background.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  %v", err)
+		return
+	}
+	log.Printf("✅ Shutdown complete")
+}
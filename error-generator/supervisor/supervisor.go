@@ -0,0 +1,178 @@
+/*
+# Module: supervisor/supervisor.go
+Runs background workers under one cancellable context, restarting crashed workers
+with exponential backoff and waiting for them all on shutdown.
+
+A worker that returns an error or panics is restarted after a delay that doubles up
+to MaxBackoff, and resets once the worker has stayed up for MaxBackoff. A worker that
+returns nil, or returns after the context is cancelled, is finished. One-shot jobs
+started with Async are not restarted but are still waited for on shutdown, so writes
+in flight when the process is asked to stop get to finish.
+
+## Linked Modules
+(None - supervisor package has no dependencies)
+
+## Tags
+background, goroutines, shutdown, restart, backoff
+
+## Exports
+Worker, Options, Supervisor, New, ErrShutdownTimeout
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "supervisor/supervisor.go" ;
+    code:description "Cancellable background workers with restart-with-backoff and graceful shutdown" ;
+    code:exports :Worker, :Options, :Supervisor, :New, :ErrShutdownTimeout ;
+    code:tags "background", "goroutines", "shutdown", "restart", "backoff" .
+<!-- End LinkedDoc RDF -->
+*/
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrShutdownTimeout is returned by Shutdown when workers are still running at the deadline.
+var ErrShutdownTimeout = errors.New("supervisor: workers still running at shutdown deadline")
+
+// Worker is a long-running task. It should return when ctx is done.
+type Worker func(ctx context.Context) error
+
+// Options tune restarts.
+type Options struct {
+	MinBackoff time.Duration // delay before the first restart (default 1s)
+	MaxBackoff time.Duration // longest delay between restarts (default 1m)
+}
+
+// Supervisor owns a set of workers and the context that stops them.
+type Supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   Options
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]int // worker name -> goroutines still running under it
+}
+
+// New returns a supervisor whose workers stop when parent is done or Shutdown is called.
+func New(parent context.Context, opts Options) *Supervisor {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = time.Minute
+		if opts.MaxBackoff < opts.MinBackoff {
+			opts.MaxBackoff = opts.MinBackoff
+		}
+	}
+	ctx, cancel := context.WithCancel(parent)
+	return &Supervisor{ctx: ctx, cancel: cancel, opts: opts, running: make(map[string]int)}
+}
+
+// Context is cancelled when the supervisor begins shutting down.
+func (s *Supervisor) Context() context.Context {
+	return s.ctx
+}
+
+// Go starts a worker, restarting it with backoff whenever it fails or panics.
+func (s *Supervisor) Go(name string, worker Worker) {
+	s.start(name, func() {
+		backoff := s.opts.MinBackoff
+		for {
+			started := time.Now()
+			err := s.call(name, func() error { return worker(s.ctx) })
+			if s.ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				log.Printf("✅ Background worker %s finished", name)
+				return
+			}
+
+			if time.Since(started) >= s.opts.MaxBackoff {
+				backoff = s.opts.MinBackoff
+			}
+			log.Printf("⚠️  Background worker %s failed, restarting in %s: %v", name, backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-s.ctx.Done():
+				return
+			}
+			backoff *= 2
+			if backoff > s.opts.MaxBackoff {
+				backoff = s.opts.MaxBackoff
+			}
+		}
+	})
+}
+
+// Async runs a one-shot job that is not restarted. Shutdown waits for it, so jobs
+// such as database writes should bound themselves with a timeout rather than watch
+// Context.
+func (s *Supervisor) Async(name string, job func()) {
+	s.start(name, func() {
+		if err := s.call(name, func() error { job(); return nil }); err != nil {
+			log.Printf("❌ Background job %s failed: %v", name, err)
+		}
+	})
+}
+
+// Shutdown cancels the workers and waits for them and any Async jobs until ctx is
+// done. On timeout the error names what is still running.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrShutdownTimeout, s.running)
+	}
+}
+
+// start runs fn in a goroutine counted by the wait group and the running map
+func (s *Supervisor) start(name string, fn func()) {
+	s.wg.Add(1)
+	s.mu.Lock()
+	s.running[name]++
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			if s.running[name]--; s.running[name] == 0 {
+				delete(s.running, name)
+			}
+			s.mu.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// call runs fn, turning a panic into an error so the worker can be restarted
+func (s *Supervisor) call(name string, fn func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("❌ Background worker %s panicked: %v\n%s", name, p, debug.Stack())
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"location-tracker/config"
+)
+
+// appConfig holds every tunable. It starts as the defaults so code that runs before
+// loading (and the offline tools) still sees sensible values.
+var appConfig = config.Default()
+
+// initializeConfig loads the configuration for the server from --config (default
+// $CONFIG_FILE) and the environment. --print-config shows the result with secrets
+// redacted and --check-config validates it; both exit without starting the server.
+func initializeConfig(args []string) {
+	flags := flag.NewFlagSet("location-tracker", flag.ExitOnError)
+	path := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file (default $CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
+	checkConfig := flags.Bool("check-config", false, "Validate the configuration and exit")
+	flags.Parse(args)
+
+	cfg, err := config.Load(*path)
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	if *printConfig {
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("❌ Failed to print config: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	err = cfg.Validate()
+	if *checkConfig {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Invalid configuration:\n%s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✅ Configuration ok")
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration:\n%v", err)
+	}
+
+	applyConfig(cfg)
+}
+
+// initializeToolConfig loads $CONFIG_FILE for the offline subcommands, which need the
+// table names and keys but not a complete server configuration
+func initializeToolConfig() {
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+	applyConfig(cfg)
+}
+
+// applyConfig makes cfg current and copies the settings main.go keeps in globals
+func applyConfig(cfg *config.Config) {
+	appConfig = cfg
+
+	globalPassword = cfg.Auth.TrackerPassword
+	googleMapsAPIKey = cfg.APIKeys.GoogleMaps
+	perplexityAPIKey = cfg.APIKeys.Perplexity
+	openaiAPIKey = cfg.APIKeys.OpenAI
+	tipEncryptionKey = cfg.Tips.EncryptionKey
+	tipMaxLength = cfg.Tips.MaxLength
+	tipRateLimit = cfg.Tips.RateLimitPerHour
+	useHTTPS = cfg.Server.UseHTTPS
+
+	tables := cfg.DynamoDB.Tables
+	errorLogsTableName = tables.ErrorLogs
+	locationsTableName = tables.Locations
+	commercialRealEstateTableName = tables.CommercialRealEstate
+	anonymousTipsTableName = tables.AnonymousTips
+	bannedUsersTableName = tables.BannedUsers
+	donationsTableName = tables.Donations
+	solidSessionsTableName = tables.SolidSessions
+	smsMessagesTableName = tables.SMSMessages
+	privacyAuditTableName = tables.PrivacyAudit
+}
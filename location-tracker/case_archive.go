@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"location-tracker/sanitize"
+	"location-tracker/types"
+)
+
+// caseArchiveTTL bounds how often the full DynamoDB archive is rescanned
+const caseArchiveTTL = 5 * time.Minute
+
+var (
+	caseArchiveCache      []types.ErrorLog
+	caseArchiveLoadedAt   time.Time
+	caseArchiveCacheMutex sync.Mutex
+)
+
+// CaseFilter selects cases from the archive. Matching only looks at puzzle-level
+// fields, so a filter can never reveal notes or nearby businesses.
+type CaseFilter struct {
+	Keyword  string    // Matches seed keywords or any word in the public text
+	Business string    // Matches cases whose public text names the business
+	Since    time.Time // Inclusive; zero means unbounded
+	Until    time.Time // Exclusive; zero means unbounded
+}
+
+// loadCaseArchive returns puzzle-level cases, newest first
+func loadCaseArchive() []types.ErrorLog {
+	cases := loadAllCases()
+	for i := range cases {
+		cases[i] = redactErrorLog(cases[i], false)
+	}
+	return cases
+}
+
+// loadAllCases returns every case unredacted, newest first, for operator tooling.
+// The in-memory cache is merged with a periodically refreshed DynamoDB scan.
+func loadAllCases() []types.ErrorLog {
+	errorLogMutex.RLock()
+	recent := make([]types.ErrorLog, len(errorLogs))
+	copy(recent, errorLogs)
+	errorLogMutex.RUnlock()
+
+	var persisted []types.ErrorLog
+	if useDynamoDB && errorLogRepo != nil {
+		caseArchiveCacheMutex.Lock()
+		if caseArchiveCache == nil || time.Since(caseArchiveLoadedAt) > caseArchiveTTL {
+			all, err := errorLogRepo.GetAll()
+			if err != nil {
+				log.Printf("⚠️  Failed to load case archive: %v", err)
+			} else {
+				caseArchiveCache = all
+				caseArchiveLoadedAt = time.Now()
+			}
+		}
+		persisted = caseArchiveCache
+		caseArchiveCacheMutex.Unlock()
+	}
+
+	seen := make(map[string]bool, len(recent)+len(persisted))
+	cases := make([]types.ErrorLog, 0, len(recent)+len(persisted))
+	for _, source := range [][]types.ErrorLog{recent, persisted} {
+		for _, errorLog := range source {
+			if errorLog.ID == "" || seen[errorLog.ID] {
+				continue
+			}
+			seen[errorLog.ID] = true
+			cases = append(cases, errorLog)
+		}
+	}
+
+	sort.SliceStable(cases, func(i, j int) bool {
+		return cases[i].Timestamp.After(cases[j].Timestamp)
+	})
+	return cases
+}
+
+// filterCases returns the cases matching filter, keeping at most limit (0 = no limit)
+func filterCases(cases []types.ErrorLog, filter CaseFilter, limit int) []types.ErrorLog {
+	matched := make([]types.ErrorLog, 0)
+	for _, errorLog := range cases {
+		if !filter.Matches(&errorLog) {
+			continue
+		}
+		matched = append(matched, errorLog)
+		if limit > 0 && len(matched) >= limit {
+			break
+		}
+	}
+	return matched
+}
+
+// Matches reports whether a (redacted) case passes the filter
+func (f CaseFilter) Matches(errorLog *types.ErrorLog) bool {
+	if !f.Since.IsZero() && errorLog.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !errorLog.Timestamp.Before(f.Until) {
+		return false
+	}
+
+	if keyword := strings.ToLower(strings.TrimSpace(f.Keyword)); keyword != "" {
+		found := false
+		for _, seed := range errorLog.SeedKeywords {
+			if strings.EqualFold(seed, keyword) {
+				found = true
+				break
+			}
+		}
+		if !found && !containsWord(casePublicText(errorLog), keyword) {
+			return false
+		}
+	}
+
+	if business := strings.ToLower(strings.TrimSpace(f.Business)); business != "" {
+		if !strings.Contains(casePublicText(errorLog), business) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether the filter selects everything
+func (f CaseFilter) IsZero() bool {
+	return f.Keyword == "" && f.Business == "" && f.Since.IsZero() && f.Until.IsZero()
+}
+
+// casePublicText is the lowercased puzzle-level text of a case used for matching
+func casePublicText(errorLog *types.ErrorLog) string {
+	return strings.ToLower(strings.Join([]string{
+		errorLog.Message,
+		errorLog.Slogan,
+		errorLog.VerboseDesc,
+		errorLog.SatiricalFix,
+		sanitize.PlainText(errorLog.ChildrensStory),
+	}, "\n"))
+}
+
+// containsWord reports whether word occurs in text on word boundaries
+func containsWord(text, word string) bool {
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], word)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(word)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 0x80
+}
+
+// parseCaseFilter reads the keyword, business, since and until parameters shared by feeds and exports
+func parseCaseFilter(query url.Values) (CaseFilter, error) {
+	since, err := parseCaseDate(query.Get("since"), false)
+	if err != nil {
+		return CaseFilter{}, err
+	}
+	until, err := parseCaseDate(query.Get("until"), true)
+	if err != nil {
+		return CaseFilter{}, err
+	}
+	return CaseFilter{
+		Keyword:  strings.TrimSpace(query.Get("keyword")),
+		Business: strings.TrimSpace(query.Get("business")),
+		Since:    since,
+		Until:    until,
+	}, nil
+}
+
+// parseCaseDate accepts YYYY-MM-DD or RFC 3339. A date-only upper bound
+// (endOfDay) covers that whole day. Empty input yields the zero time.
+func parseCaseDate(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"location-tracker/sanitize"
+	"location-tracker/types"
+)
+
+// linkPreviewCrawlers are User-Agent substrings of chat apps and social networks that
+// fetch shared links to build previews. They never carry auth cookies. Search engine
+// crawlers are left out on purpose: anyone can claim their User-Agent.
+var linkPreviewCrawlers = []string{
+	"facebookexternalhit",
+	"facebookcatalog",
+	"twitterbot",
+	"slackbot",
+	"slack-imgproxy",
+	"discordbot",
+	"whatsapp",
+	"telegrambot",
+	"linkedinbot",
+	"skypeuripreview",
+	"mastodon",
+	"redditbot",
+	"embedly",
+	"signal",
+}
+
+// CasePageData is the view model for the public case page template
+type CasePageData struct {
+	Title         string
+	Description   string
+	Slogan        string
+	PageURL       string
+	JSONURL       string
+	FeedURL       string
+	ImageURL      string
+	ImageWidth    int
+	ImageHeight   int
+	SiteName      string
+	Published     string
+	PublishedISO  string
+	StoryHTML     template.HTML
+	SatiricalFix  string
+	GifURLs       []string
+	MemeURL       string
+	FoodImageURL  string
+	FoodImageAttr string
+	SongTitle     string
+	SongArtist    string
+	SongURL       string
+	FullAccess    bool
+	UserNote      string
+	Businesses    []string
+	PreviewOnly   bool // crawler view: head metadata and headline only
+}
+
+var casePageTemplate = template.Must(template.New("case").Parse(casePageHTML))
+
+// getBaseURL returns the public base URL used in generated links
+func getBaseURL() string {
+	return strings.TrimRight(appConfig.Server.BaseURL, "/")
+}
+
+// caseURL returns the canonical URL for an error log
+func caseURL(errorLog *types.ErrorLog) string {
+	if errorLog.URL != "" {
+		return errorLog.URL
+	}
+	timestampStr := url.QueryEscape(errorLog.Timestamp.Format(time.RFC3339Nano))
+	return fmt.Sprintf("%s/api/errorlogs/%s/%s", getBaseURL(), errorLog.ID, timestampStr)
+}
+
+// shareImageURL returns the absolute URL of the OpenGraph share image for an error log
+func shareImageURL(errorLogID string) string {
+	return fmt.Sprintf("%s/api/share-image/share_%s", getBaseURL(), errorLogID)
+}
+
+// isLinkPreviewCrawler reports whether the request comes from a link-unfurling bot
+func isLinkPreviewCrawler(r *http.Request) bool {
+	userAgent := strings.ToLower(r.UserAgent())
+	if userAgent == "" {
+		return false
+	}
+	for _, crawler := range linkPreviewCrawlers {
+		if strings.Contains(userAgent, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsCasePage decides between the HTML case page and JSON.
+// API clients keep getting JSON; ?format=json / ?format=html override the Accept header.
+func wantsCasePage(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "json":
+		return false
+	case "html":
+		return true
+	}
+	if isLinkPreviewCrawler(r) {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// buildCasePageData maps an (already redacted) error log onto the page view model
+func buildCasePageData(errorLog types.ErrorLog, hasFullAccess bool) CasePageData {
+	description := errorLog.VerboseDesc
+	if description == "" && errorLog.ChildrensStory != "" {
+		description = errorLog.ChildrensStory
+	}
+	if description == "" {
+		description = errorLog.Slogan
+	}
+	description = sanitize.Excerpt(description, 200)
+
+	title := sanitize.Excerpt(errorLog.Message, 90)
+	if title == "" {
+		title = "Classified Error Report"
+	}
+
+	gifURLs := errorLog.GifURLs
+	if len(gifURLs) == 0 && errorLog.GifURL != "" {
+		gifURLs = []string{errorLog.GifURL}
+	}
+
+	pageURL := caseURL(&errorLog)
+	jsonURL := pageURL + "?format=json"
+	if strings.Contains(pageURL, "?") {
+		jsonURL = pageURL + "&format=json"
+	}
+
+	data := CasePageData{
+		Title:         title,
+		Description:   description,
+		Slogan:        errorLog.Slogan,
+		PageURL:       pageURL,
+		JSONURL:       jsonURL,
+		FeedURL:       getBaseURL() + "/feeds/cases.atom",
+		ImageURL:      shareImageURL(errorLog.ID),
+		ImageWidth:    fbShareWidth,
+		ImageHeight:   fbShareHeight,
+		SiteName:      "notspies.org",
+		Published:     errorLog.Timestamp.Format("January 2, 2006 15:04 MST"),
+		PublishedISO:  errorLog.Timestamp.Format(time.RFC3339),
+		StoryHTML:     template.HTML(sanitize.StoryHTML(errorLog.ChildrensStory)),
+		SatiricalFix:  errorLog.SatiricalFix,
+		GifURLs:       gifURLs,
+		MemeURL:       errorLog.MemeURL,
+		FoodImageURL:  errorLog.FoodImageURL,
+		FoodImageAttr: errorLog.FoodImageAttr,
+		SongTitle:     errorLog.SongTitle,
+		SongArtist:    errorLog.SongArtist,
+		SongURL:       errorLog.SongURL,
+		FullAccess:    hasFullAccess,
+	}
+	if hasFullAccess {
+		data.UserNote = errorLog.UserExperienceNote
+		data.Businesses = errorLog.NearbyBusinesses
+	}
+	return data
+}
+
+// serveCasePage renders the server-side case page with OpenGraph/Twitter card metadata.
+// The caller is responsible for redacting the error log to the viewer's access level.
+func serveCasePage(w http.ResponseWriter, r *http.Request, errorLog types.ErrorLog, hasFullAccess bool) {
+	executeCasePage(w, buildCasePageData(errorLog, hasFullAccess))
+}
+
+// serveCasePreview renders what a link-preview crawler needs to unfurl a case: the
+// title, description and share image. The crawler is recognized by its User-Agent, which
+// anyone can send, so the preview is public data only: it is built from the fields the
+// public case feeds already publish (message, slogan, story excerpt), never the verbose
+// description or anything else behind the puzzle.
+func serveCasePreview(w http.ResponseWriter, r *http.Request, errorLog types.ErrorLog) {
+	public := types.ErrorLog{
+		ID:             errorLog.ID,
+		Timestamp:      errorLog.Timestamp,
+		URL:            errorLog.URL,
+		Message:        errorLog.Message,
+		Slogan:         errorLog.Slogan,
+		ChildrensStory: errorLog.ChildrensStory,
+	}
+	full := buildCasePageData(public, false)
+	executeCasePage(w, CasePageData{
+		Title:        full.Title,
+		Description:  full.Description,
+		Slogan:       full.Slogan,
+		PageURL:      full.PageURL,
+		FeedURL:      full.FeedURL,
+		ImageURL:     full.ImageURL,
+		ImageWidth:   full.ImageWidth,
+		ImageHeight:  full.ImageHeight,
+		SiteName:     full.SiteName,
+		Published:    full.Published,
+		PublishedISO: full.PublishedISO,
+		PreviewOnly:  true,
+	})
+}
+
+// executeCasePage writes the case page template with caching headers for the access level
+func executeCasePage(w http.ResponseWriter, data CasePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Vary", "Accept, Cookie, User-Agent")
+	if data.FullAccess {
+		w.Header().Set("Cache-Control", "private, no-store")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+
+	if err := casePageTemplate.Execute(w, data); err != nil {
+		log.Printf("❌ Error executing case page template: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+const casePageHTML = `<!DOCTYPE html>
+<html lang="en" prefix="og: https://ogp.me/ns#">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}} | {{.SiteName}}</title>
+    <meta name="description" content="{{.Description}}">
+    <link rel="canonical" href="{{.PageURL}}">
+    {{if .JSONURL}}<link rel="alternate" type="application/json" href="{{.JSONURL}}">{{end}}
+    <link rel="alternate" type="application/atom+xml" title="Case archive" href="{{.FeedURL}}">
+
+    <!-- OpenGraph -->
+    <meta property="og:type" content="article">
+    <meta property="og:site_name" content="{{.SiteName}}">
+    <meta property="og:title" content="{{.Title}}">
+    <meta property="og:description" content="{{.Description}}">
+    <meta property="og:url" content="{{.PageURL}}">
+    <meta property="og:image" content="{{.ImageURL}}">
+    <meta property="og:image:type" content="image/png">
+    <meta property="og:image:width" content="{{.ImageWidth}}">
+    <meta property="og:image:height" content="{{.ImageHeight}}">
+    <meta property="og:image:alt" content="{{.Slogan}}">
+    <meta property="article:published_time" content="{{.PublishedISO}}">
+
+    <!-- Twitter card -->
+    <meta name="twitter:card" content="summary_large_image">
+    <meta name="twitter:title" content="{{.Title}}">
+    <meta name="twitter:description" content="{{.Description}}">
+    <meta name="twitter:image" content="{{.ImageURL}}">
+    <meta name="twitter:image:alt" content="{{.Slogan}}">
+
+    <style>
+        body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; background: linear-gradient(135deg, #141428 0%, #283c78 100%); color: #eee; }
+        main { max-width: 820px; margin: 0 auto; padding: 32px 20px 60px; }
+        .stamp { display: inline-block; border: 2px solid #ff6b6b; color: #ff6b6b; padding: 4px 10px; font-weight: bold; letter-spacing: 2px; transform: rotate(-2deg); }
+        h1 { font-size: 1.6em; line-height: 1.3; color: #ffdcdc; }
+        .slogan { font-size: 1.2em; color: #ffffb4; font-style: italic; }
+        .meta { color: #aaa; font-size: 0.9em; }
+        .panel { background: rgba(255,255,255,0.07); border-radius: 10px; padding: 18px 22px; margin: 22px 0; }
+        .story p { line-height: 1.6; }
+        .story a { color: #9ecbff; }
+        .story details { background: rgba(0,0,0,0.2); padding: 6px 10px; border-radius: 6px; display: inline; }
+        .gifs { display: grid; grid-template-columns: repeat(auto-fill, minmax(180px, 1fr)); gap: 10px; }
+        .gifs img, .media img { width: 100%; border-radius: 8px; }
+        pre { white-space: pre-wrap; background: rgba(0,0,0,0.3); padding: 12px; border-radius: 6px; }
+        a.button { display: inline-block; background: #667eea; color: #fff; padding: 10px 18px; border-radius: 6px; text-decoration: none; }
+        .attr { font-size: 0.8em; color: #aaa; }
+    </style>
+</head>
+<body>
+<main>
+    <span class="stamp">CASE FILE</span>
+    <h1>🚨 {{.Title}}</h1>
+    {{if .Slogan}}<p class="slogan">💡 {{.Slogan}}</p>{{end}}
+    <p class="meta">Filed <time datetime="{{.PublishedISO}}">{{.Published}}</time></p>
+
+    {{if .Description}}<div class="panel"><p>{{.Description}}</p></div>{{end}}
+
+    {{if not .PreviewOnly}}
+    {{if .StoryHTML}}
+    <section class="panel story">
+        <h2>📖 The Case</h2>
+        {{.StoryHTML}}
+    </section>
+    {{end}}
+
+    {{if .GifURLs}}
+    <section class="panel">
+        <h2>🎞️ Evidence</h2>
+        <div class="gifs">{{range .GifURLs}}<img src="{{.}}" alt="Evidence GIF" loading="lazy">{{end}}</div>
+    </section>
+    {{end}}
+
+    {{if or .MemeURL .FoodImageURL}}
+    <section class="panel media">
+        {{if .MemeURL}}<img src="{{.MemeURL}}" alt="Case meme" loading="lazy">{{end}}
+        {{if .FoodImageURL}}<img src="{{.FoodImageURL}}" alt="Case food" loading="lazy">{{if .FoodImageAttr}}<p class="attr">{{.FoodImageAttr}}</p>{{end}}{{end}}
+    </section>
+    {{end}}
+
+    {{if .SongTitle}}
+    <section class="panel">
+        <h2>🎵 Soundtrack</h2>
+        <p>{{if .SongURL}}<a href="{{.SongURL}}" target="_blank" rel="noopener noreferrer">{{.SongTitle}}</a>{{else}}{{.SongTitle}}{{end}}{{if .SongArtist}} — {{.SongArtist}}{{end}}</p>
+    </section>
+    {{end}}
+
+    {{if .SatiricalFix}}
+    <section class="panel">
+        <h2>🔧 Proposed Fix</h2>
+        <pre>{{.SatiricalFix}}</pre>
+    </section>
+    {{end}}
+
+    {{if .FullAccess}}
+    {{if or .UserNote .Businesses}}
+    <section class="panel">
+        <h2>🔒 Field Notes</h2>
+        {{if .UserNote}}<p>{{.UserNote}}</p>{{end}}
+        {{if .Businesses}}<ul>{{range .Businesses}}<li>{{.}}</li>{{end}}</ul>{{end}}
+    </section>
+    {{end}}
+    {{end}}
+    {{end}}
+
+    <p><a class="button" href="/">🕵️ Open the tracker</a></p>
+</main>
+</body>
+</html>`
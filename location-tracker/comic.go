@@ -0,0 +1,632 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/math/fixed"
+
+	"location-tracker/sanitize"
+	"location-tracker/storage"
+	"location-tracker/types"
+)
+
+const (
+	comicPanelSize      = 420
+	comicGutter         = 18
+	comicMargin         = 24
+	comicBorder         = 5
+	comicMinCaptions    = 2
+	comicMaxCaptions    = 5
+	comicMaxCaptionLen  = 220
+	comicCaptionPadding = 12
+	comicLayoutVersion  = "comic-v1"
+)
+
+var (
+	comicPageColor    = color.RGBA{255, 250, 235, 255}
+	comicInkColor     = color.RGBA{20, 20, 20, 255}
+	comicTitleColor   = color.RGBA{255, 214, 0, 255}
+	comicBubbleColor  = color.RGBA{255, 255, 255, 255}
+	comicHalftoneDark = color.RGBA{230, 120, 90, 255}
+
+	sentenceBoundary = regexp.MustCompile(`([.!?…])\s+`)
+)
+
+// comicPanel is one panel of the strip: the title panel or a caption paired with media
+type comicPanel struct {
+	Title    bool
+	Heading  string
+	Caption  string
+	ImageURL string
+	image    image.Image
+}
+
+// comicBoldFont is Go Bold, parsed once for titles
+var (
+	comicBoldFont     *truetype.Font
+	comicBoldFontErr  error
+	comicBoldFontOnce sync.Once
+)
+
+func getComicBoldFont() (*truetype.Font, error) {
+	comicBoldFontOnce.Do(func() {
+		comicBoldFont, comicBoldFontErr = truetype.Parse(gobold.TTF)
+	})
+	return comicBoldFont, comicBoldFontErr
+}
+
+// comicKey derives the content-hash key of a rendered comic
+func comicKey(errorLog *types.ErrorLog, ext string) string {
+	return storage.ContentHashKey("comic", "."+ext,
+		comicLayoutVersion,
+		ext,
+		errorLog.ID,
+		errorLog.Message,
+		errorLog.Slogan,
+		errorLog.ChildrensStory,
+		errorLog.MemeURL,
+		errorLog.FoodImageURL,
+		errorLog.GifURL,
+		strings.Join(errorLog.GifURLs, "\n"),
+	)
+}
+
+// buildComicPanels splits a case into a title panel plus 2-5 caption panels.
+// Captions come from the story's <p> paragraphs; each is paired with a GIF, meme or food image in turn.
+func buildComicPanels(errorLog *types.ErrorLog) []comicPanel {
+	captions := comicCaptions(errorLog)
+
+	media := make([]string, 0)
+	media = append(media, errorLog.GifURLs...)
+	if len(errorLog.GifURLs) == 0 && errorLog.GifURL != "" {
+		media = append(media, errorLog.GifURL)
+	}
+	if errorLog.MemeURL != "" {
+		media = append(media, errorLog.MemeURL)
+	}
+	if errorLog.FoodImageURL != "" {
+		media = append(media, errorLog.FoodImageURL)
+	}
+
+	panels := []comicPanel{{
+		Title:   true,
+		Heading: errorLog.Message,
+		Caption: errorLog.Slogan,
+	}}
+	for i, caption := range captions {
+		panel := comicPanel{Caption: caption}
+		if len(media) > 0 {
+			panel.ImageURL = media[i%len(media)]
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// comicCaptions picks between comicMinCaptions and comicMaxCaptions captions from the story
+func comicCaptions(errorLog *types.ErrorLog) []string {
+	paragraphs := sanitize.Paragraphs(errorLog.ChildrensStory)
+
+	// Too few paragraphs: split into sentences and regroup
+	if len(paragraphs) < comicMinCaptions {
+		sentences := splitSentences(strings.Join(paragraphs, " "))
+		if len(sentences) >= comicMinCaptions {
+			paragraphs = groupSentences(sentences, minInt(len(sentences), comicMaxCaptions))
+		}
+	}
+
+	// Too many paragraphs: sample evenly so the beginning and ending both survive
+	if len(paragraphs) > comicMaxCaptions {
+		sampled := make([]string, comicMaxCaptions)
+		for i := range sampled {
+			sampled[i] = paragraphs[i*(len(paragraphs)-1)/(comicMaxCaptions-1)]
+		}
+		paragraphs = sampled
+	}
+
+	// Still short: fall back to the other generated text
+	fallbacks := []string{errorLog.VerboseDesc, errorLog.SatiricalFix, "The investigation continues. The agency declines to comment."}
+	for _, fallback := range fallbacks {
+		if len(paragraphs) >= comicMinCaptions {
+			break
+		}
+		if text := sanitize.PlainText(fallback); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+
+	captions := make([]string, len(paragraphs))
+	for i, paragraph := range paragraphs {
+		captions[i] = sanitize.Excerpt(paragraph, comicMaxCaptionLen)
+	}
+	return captions
+}
+
+// splitSentences breaks text after sentence-ending punctuation
+func splitSentences(text string) []string {
+	marked := sentenceBoundary.ReplaceAllString(text, "$1\x00")
+	sentences := make([]string, 0)
+	for _, sentence := range strings.Split(marked, "\x00") {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+// groupSentences joins sentences into n roughly equal groups
+func groupSentences(sentences []string, n int) []string {
+	groups := make([]string, n)
+	for i, sentence := range sentences {
+		group := i * n / len(sentences)
+		if groups[group] != "" {
+			groups[group] += " "
+		}
+		groups[group] += sentence
+	}
+	return groups
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// loadComicImages downloads panel media concurrently; failures leave a halftone background
+func loadComicImages(panels []comicPanel) {
+	var wg sync.WaitGroup
+	for i := range panels {
+		if panels[i].ImageURL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(panel *comicPanel) {
+			defer wg.Done()
+			img, err := downloadAndDecodeImage(panel.ImageURL)
+			if err != nil {
+				log.Printf("⚠️  Comic panel image failed (%s): %v", panel.ImageURL, err)
+				return
+			}
+			panel.image = img
+		}(&panels[i])
+	}
+	wg.Wait()
+}
+
+// comicGrid returns the column/row layout for n panels
+func comicGrid(n int) (int, int) {
+	cols := 2
+	if n == 3 || n >= 5 {
+		cols = 3
+	}
+	return cols, (n + cols - 1) / cols
+}
+
+// comicPanelRects lays out panels left-to-right, top-to-bottom, centering a short last row
+func comicPanelRects(n int) (image.Rectangle, []image.Rectangle) {
+	cols, rows := comicGrid(n)
+	width := 2*comicMargin + cols*comicPanelSize + (cols-1)*comicGutter
+	height := 2*comicMargin + rows*comicPanelSize + (rows-1)*comicGutter
+
+	rects := make([]image.Rectangle, n)
+	for i := 0; i < n; i++ {
+		row, col := i/cols, i%cols
+		rowCount := cols
+		if row == rows-1 && n%cols != 0 {
+			rowCount = n % cols
+		}
+		rowOffset := (cols - rowCount) * (comicPanelSize + comicGutter) / 2
+		x := comicMargin + rowOffset + col*(comicPanelSize+comicGutter)
+		y := comicMargin + row*(comicPanelSize+comicGutter)
+		rects[i] = image.Rect(x, y, x+comicPanelSize, y+comicPanelSize)
+	}
+	return image.Rect(0, 0, width, height), rects
+}
+
+// comicTextLayout is the wrapped caption text for a panel, shared by PNG and SVG output
+type comicTextLayout struct {
+	lines      []string
+	fontSize   float64
+	lineHeight int
+	box        image.Rectangle
+}
+
+// layoutComicCaption fits caption into a speech box at the top of the panel,
+// shrinking the font until it fits within 45% of the panel height
+func layoutComicCaption(ttf *truetype.Font, caption string, panel image.Rectangle) comicTextLayout {
+	maxWidth := panel.Dx() - 2*comicBorder - 4*comicCaptionPadding
+	maxHeight := panel.Dy() * 45 / 100
+
+	var layout comicTextLayout
+	for size := 20.0; size >= 11; size-- {
+		face := truetype.NewFace(ttf, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingFull})
+		lines := wrapTextLines(face, caption, maxWidth)
+		lineHeight := int(size * 1.3)
+		layout = comicTextLayout{lines: lines, fontSize: size, lineHeight: lineHeight}
+		if len(lines)*lineHeight+2*comicCaptionPadding <= maxHeight {
+			break
+		}
+	}
+
+	boxHeight := len(layout.lines)*layout.lineHeight + 2*comicCaptionPadding
+	if boxHeight > maxHeight {
+		// Drop trailing lines rather than overflow the panel
+		keep := (maxHeight - 2*comicCaptionPadding) / layout.lineHeight
+		if keep < 1 {
+			keep = 1
+		}
+		layout.lines = append(layout.lines[:keep-1], layout.lines[keep-1]+"…")
+		boxHeight = keep*layout.lineHeight + 2*comicCaptionPadding
+	}
+	inset := comicBorder + comicCaptionPadding
+	layout.box = image.Rect(panel.Min.X+inset, panel.Min.Y+inset, panel.Max.X-inset, panel.Min.Y+inset+boxHeight)
+	return layout
+}
+
+// renderComicPNG draws the strip with TrueType captions in speech boxes
+func renderComicPNG(errorLog *types.ErrorLog, panels []comicPanel) ([]byte, error) {
+	regular, err := getShareFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	bold, err := getComicBoldFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bold font: %w", err)
+	}
+
+	bounds, rects := comicPanelRects(len(panels))
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, image.NewUniform(comicPageColor), image.Point{}, draw.Src)
+
+	for i, panel := range panels {
+		rect := rects[i]
+		inner := rect.Inset(comicBorder)
+		draw.Draw(canvas, rect, image.NewUniform(comicInkColor), image.Point{}, draw.Src)
+
+		if panel.Title {
+			draw.Draw(canvas, inner, image.NewUniform(comicTitleColor), image.Point{}, draw.Src)
+			drawComicTitle(canvas, bold, regular, errorLog, panel, inner)
+			continue
+		}
+
+		if panel.image != nil {
+			drawCover(canvas, inner, panel.image)
+		} else {
+			drawHalftone(canvas, inner)
+		}
+
+		layout := layoutComicCaption(regular, panel.Caption, rect)
+		drawSpeechBox(canvas, layout.box)
+		drawComicLines(canvas, regular, layout)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode comic: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawComicTitle renders the case title, slogan and case number inside the title panel
+func drawComicTitle(canvas *image.RGBA, bold, regular *truetype.Font, errorLog *types.ErrorLog, panel comicPanel, inner image.Rectangle) {
+	width := inner.Dx() - 2*comicCaptionPadding
+	y := inner.Min.Y + comicCaptionPadding
+
+	y = drawComicParagraph(canvas, bold, 26, "CASE FILE", inner.Min.X+comicCaptionPadding, y, width, 1, comicInkColor)
+	y += 8
+	y = drawComicParagraph(canvas, bold, 22, panel.Heading, inner.Min.X+comicCaptionPadding, y, width, 7, comicInkColor)
+	if panel.Caption != "" {
+		y += 10
+		drawComicParagraph(canvas, regular, 17, "“"+panel.Caption+"”", inner.Min.X+comicCaptionPadding, y, width, 4, comicInkColor)
+	}
+	footer := fmt.Sprintf("#%s · %s", errorLog.ID, errorLog.Timestamp.Format("Jan 2, 2006"))
+	drawComicParagraph(canvas, regular, 13, footer, inner.Min.X+comicCaptionPadding, inner.Max.Y-comicCaptionPadding-18, width, 1, comicInkColor)
+}
+
+// drawComicParagraph wraps and draws text, returning the y below the last line
+func drawComicParagraph(canvas *image.RGBA, ttf *truetype.Font, size float64, text string, x, y, width, maxLines int, textColor color.RGBA) int {
+	face := truetype.NewFace(ttf, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingFull})
+	drawer := &font.Drawer{Dst: canvas, Src: image.NewUniform(textColor), Face: face}
+	lineHeight := int(size * 1.25)
+
+	lines := wrapTextLines(face, text, width)
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], lines[maxLines-1]+"…")
+	}
+	for _, line := range lines {
+		y += lineHeight
+		drawer.Dot = fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)}
+		drawer.DrawString(line)
+	}
+	return y
+}
+
+// drawComicLines draws a caption layout inside its speech box
+func drawComicLines(canvas *image.RGBA, ttf *truetype.Font, layout comicTextLayout) {
+	face := truetype.NewFace(ttf, &truetype.Options{Size: layout.fontSize, DPI: 72, Hinting: font.HintingFull})
+	drawer := &font.Drawer{Dst: canvas, Src: image.NewUniform(comicInkColor), Face: face}
+	y := layout.box.Min.Y + comicCaptionPadding/2
+	for _, line := range layout.lines {
+		y += layout.lineHeight
+		drawer.Dot = fixed.Point26_6{X: fixed.I(layout.box.Min.X + comicCaptionPadding), Y: fixed.I(y)}
+		drawer.DrawString(line)
+	}
+}
+
+// drawCover scales img to fill rect, cropping the overflow (CSS object-fit: cover)
+func drawCover(canvas *image.RGBA, rect image.Rectangle, img image.Image) {
+	src := img.Bounds()
+	scaleX := float64(rect.Dx()) / float64(src.Dx())
+	scaleY := float64(rect.Dy()) / float64(src.Dy())
+	scale := scaleX
+	if scaleY > scale {
+		scale = scaleY
+	}
+	cropW := int(float64(rect.Dx()) / scale)
+	cropH := int(float64(rect.Dy()) / scale)
+	cropX := src.Min.X + (src.Dx()-cropW)/2
+	cropY := src.Min.Y + (src.Dy()-cropH)/2
+	xdraw.ApproxBiLinear.Scale(canvas, rect, img, image.Rect(cropX, cropY, cropX+cropW, cropY+cropH), draw.Src, nil)
+}
+
+// drawHalftone fills rect with a comic-style dot pattern for panels without media
+func drawHalftone(canvas *image.RGBA, rect image.Rectangle) {
+	draw.Draw(canvas, rect, image.NewUniform(color.RGBA{255, 200, 170, 255}), image.Point{}, draw.Src)
+	const spacing = 14
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Dots grow toward the bottom-right corner
+			cx := (x-rect.Min.X)%spacing - spacing/2
+			cy := (y-rect.Min.Y)%spacing - spacing/2
+			radius := 1 + 5*float64((x-rect.Min.X)+(y-rect.Min.Y))/float64(rect.Dx()+rect.Dy())
+			if float64(cx*cx+cy*cy) <= radius*radius {
+				canvas.SetRGBA(x, y, comicHalftoneDark)
+			}
+		}
+	}
+}
+
+// drawSpeechBox draws a rounded white box with an ink outline and a tail pointing down
+func drawSpeechBox(canvas *image.RGBA, box image.Rectangle) {
+	const radius = 14
+	const outline = 3
+
+	tailBase := box.Min.X + box.Dx()/5
+	tail := [3]image.Point{{tailBase, box.Max.Y - 2}, {tailBase + 26, box.Max.Y - 2}, {tailBase - 6, box.Max.Y + 24}}
+
+	fillRoundedRect(canvas, box.Inset(-outline), radius+outline, comicInkColor)
+	fillTriangle(canvas, tail[0].Add(image.Pt(-outline, 0)), tail[1].Add(image.Pt(outline, 0)), tail[2].Add(image.Pt(-outline/2, outline+1)), comicInkColor)
+	fillRoundedRect(canvas, box, radius, comicBubbleColor)
+	fillTriangle(canvas, tail[0], tail[1], tail[2], comicBubbleColor)
+}
+
+// fillRoundedRect fills rect with rounded corners of the given radius
+func fillRoundedRect(canvas *image.RGBA, rect image.Rectangle, radius int, fill color.RGBA) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			dx, dy := 0, 0
+			if x < rect.Min.X+radius {
+				dx = rect.Min.X + radius - x
+			} else if x >= rect.Max.X-radius {
+				dx = x - (rect.Max.X - radius - 1)
+			}
+			if y < rect.Min.Y+radius {
+				dy = rect.Min.Y + radius - y
+			} else if y >= rect.Max.Y-radius {
+				dy = y - (rect.Max.Y - radius - 1)
+			}
+			if dx*dx+dy*dy <= radius*radius {
+				canvas.SetRGBA(x, y, fill)
+			}
+		}
+	}
+}
+
+// fillTriangle fills the triangle a-b-c using edge functions
+func fillTriangle(canvas *image.RGBA, a, b, c image.Point, fill color.RGBA) {
+	minX, maxX := minInt(a.X, minInt(b.X, c.X)), -minInt(-a.X, minInt(-b.X, -c.X))
+	minY, maxY := minInt(a.Y, minInt(b.Y, c.Y)), -minInt(-a.Y, minInt(-b.Y, -c.Y))
+	edge := func(p, q, r image.Point) int { return (q.X-p.X)*(r.Y-p.Y) - (q.Y-p.Y)*(r.X-p.X) }
+	area := edge(a, b, c)
+	if area == 0 {
+		return
+	}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			p := image.Pt(x, y)
+			w0, w1, w2 := edge(b, c, p), edge(c, a, p), edge(a, b, p)
+			if (area > 0 && w0 >= 0 && w1 >= 0 && w2 >= 0) || (area < 0 && w0 <= 0 && w1 <= 0 && w2 <= 0) {
+				canvas.SetRGBA(x, y, fill)
+			}
+		}
+	}
+}
+
+// renderComicSVG renders the same layout as SVG with panel images embedded as JPEG data URIs
+func renderComicSVG(errorLog *types.ErrorLog, panels []comicPanel) ([]byte, error) {
+	regular, err := getShareFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	bounds, rects := comicPanelRects(len(panels))
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="'Go', 'Helvetica Neue', Arial, sans-serif">`,
+		bounds.Dx(), bounds.Dy(), bounds.Dx(), bounds.Dy())
+	fmt.Fprintf(&svg, `<title>%s</title>`, html.EscapeString(errorLog.Message))
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="%s"/>`, svgColor(comicPageColor))
+
+	for i, panel := range panels {
+		rect := rects[i]
+		inner := rect.Inset(comicBorder)
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), svgColor(comicInkColor))
+
+		if panel.Title {
+			fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy(), svgColor(comicTitleColor))
+			bold, _ := getComicBoldFont()
+			width := inner.Dx() - 2*comicCaptionPadding
+			x := inner.Min.X + comicCaptionPadding
+			y := inner.Min.Y + comicCaptionPadding
+			y = writeSVGParagraph(&svg, bold, 26, "bold", "CASE FILE", x, y, width, 1)
+			y = writeSVGParagraph(&svg, bold, 22, "bold", panel.Heading, x, y+8, width, 7)
+			if panel.Caption != "" {
+				writeSVGParagraph(&svg, regular, 17, "normal", "“"+panel.Caption+"”", x, y+10, width, 4)
+			}
+			footer := fmt.Sprintf("#%s · %s", errorLog.ID, errorLog.Timestamp.Format("Jan 2, 2006"))
+			writeSVGParagraph(&svg, regular, 13, "normal", footer, x, inner.Max.Y-comicCaptionPadding-18, width, 1)
+			continue
+		}
+
+		if panel.image != nil {
+			panelImage := image.NewRGBA(image.Rect(0, 0, inner.Dx(), inner.Dy()))
+			drawCover(panelImage, panelImage.Bounds(), panel.image)
+			var jpegBuf bytes.Buffer
+			if err := jpeg.Encode(&jpegBuf, panelImage, &jpeg.Options{Quality: 80}); err == nil {
+				fmt.Fprintf(&svg, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/jpeg;base64,%s"/>`,
+					inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy(), base64.StdEncoding.EncodeToString(jpegBuf.Bytes()))
+			}
+		} else {
+			fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="rgb(255,200,170)"/>`, inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy())
+		}
+
+		layout := layoutComicCaption(regular, panel.Caption, rect)
+		box := layout.box
+		tailBase := box.Min.X + box.Dx()/5
+		fmt.Fprintf(&svg, `<polygon points="%d,%d %d,%d %d,%d" fill="%s" stroke="%s" stroke-width="3"/>`,
+			tailBase, box.Max.Y-2, tailBase+26, box.Max.Y-2, tailBase-6, box.Max.Y+24, svgColor(comicBubbleColor), svgColor(comicInkColor))
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" rx="14" fill="%s" stroke="%s" stroke-width="3"/>`,
+			box.Min.X, box.Min.Y, box.Dx(), box.Dy(), svgColor(comicBubbleColor), svgColor(comicInkColor))
+		// Cover the outline where the tail joins the box
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="26" height="4" fill="%s"/>`, tailBase, box.Max.Y-3, svgColor(comicBubbleColor))
+
+		fmt.Fprintf(&svg, `<text font-size="%.0f" fill="%s">`, layout.fontSize, svgColor(comicInkColor))
+		y := box.Min.Y + comicCaptionPadding/2
+		for _, line := range layout.lines {
+			y += layout.lineHeight
+			fmt.Fprintf(&svg, `<tspan x="%d" y="%d">%s</tspan>`, box.Min.X+comicCaptionPadding, y, html.EscapeString(line))
+		}
+		svg.WriteString(`</text>`)
+	}
+
+	svg.WriteString(`</svg>`)
+	return []byte(svg.String()), nil
+}
+
+// writeSVGParagraph wraps text with the TrueType metrics used for PNG output and writes it as <text>
+func writeSVGParagraph(svg *strings.Builder, ttf *truetype.Font, size float64, weight string, text string, x, y, width, maxLines int) int {
+	face := truetype.NewFace(ttf, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingFull})
+	lineHeight := int(size * 1.25)
+	lines := wrapTextLines(face, text, width)
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], lines[maxLines-1]+"…")
+	}
+	fmt.Fprintf(svg, `<text font-size="%.0f" font-weight="%s" fill="%s">`, size, weight, svgColor(comicInkColor))
+	for _, line := range lines {
+		y += lineHeight
+		fmt.Fprintf(svg, `<tspan x="%d" y="%d">%s</tspan>`, x, y, html.EscapeString(line))
+	}
+	svg.WriteString(`</text>`)
+	return y
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B)
+}
+
+// renderComic builds panels, loads media and renders the strip as "png" or "svg"
+func renderComic(errorLog *types.ErrorLog, ext string) ([]byte, error) {
+	log.Printf("🗯️  Rendering %s comic for error: %s", ext, errorLog.ID)
+	panels := buildComicPanels(errorLog)
+	loadComicImages(panels)
+	if ext == "svg" {
+		return renderComicSVG(errorLog, panels)
+	}
+	return renderComicPNG(errorLog, panels)
+}
+
+// handleErrorLogComic serves /api/errorlogs/{id}/comic[.png|.svg] (?format=png|svg).
+// The comic contains only puzzle-level fields, so puzzle access is enough.
+func handleErrorLogComic(w http.ResponseWriter, r *http.Request, errorLogID string, variant string) {
+	if !hasPuzzleAccess(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ext := strings.TrimPrefix(path.Ext(variant), ".")
+	if format := r.URL.Query().Get("format"); format != "" {
+		ext = format
+	}
+	if ext == "" {
+		ext = "png"
+	}
+	if ext != "png" && ext != "svg" {
+		http.Error(w, "Unsupported comic format (use png or svg)", http.StatusBadRequest)
+		return
+	}
+
+	errorLog, err := findErrorLog(errorLogID, "")
+	if err == errErrorLogNotFound {
+		http.Error(w, "Error log not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to retrieve error log %s: %v", errorLogID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	redacted := redactErrorLog(*errorLog, false)
+
+	key := comicKey(&redacted, ext)
+	etag := `"` + path.Base(key) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	contentType := "image/png"
+	if ext == "svg" {
+		contentType = "image/svg+xml"
+	}
+	data, contentType, err := shareImageService.Get(r.Context(), key, contentType, func() ([]byte, error) {
+		return renderComic(&redacted, ext)
+	})
+	if err == errRenderQueueFull {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Comic is being drawn, try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to render comic for %s: %v", errorLogID, err)
+		http.Error(w, "Failed to render comic", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if ext == "svg" {
+		// Embedded data is images only; forbid scripts if the SVG is opened directly
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'")
+	}
+	w.Write(data)
+}
@@ -0,0 +1,281 @@
+/*
+# Module: config/config.go
+Typed configuration for the location tracker: every tunable with its default, the
+file key it is read from and the environment variable that overrides it.
+
+Fields are tagged with their YAML/TOML key, an `env` variable name, and `secret`
+when the value must never be printed. The env names are the ones the tracker has
+always read, so existing deployments keep working without a config file.
+
+## Linked Modules
+- [config/load](./load.go) - Reads a YAML or TOML file and applies env overrides
+- [config/validate](./validate.go) - Startup validation
+
+## Tags
+config, settings, environment, defaults
+
+## Exports
+Config, Server, Auth, APIKeys, DynamoDB, Tables, Limits, Intervals, Places, Models, Tips, GeoPrivacy, Stats, ShareImages, Solid, FieldEncryption, Sync, Default
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "config/config.go" ;
+    code:description "Typed configuration for the location tracker with defaults, file keys and env overrides" ;
+    code:linksTo [
+        code:name "config/load" ;
+        code:path "./load.go" ;
+        code:relationship "Reads a YAML or TOML file and applies env overrides"
+    ], [
+        code:name "config/validate" ;
+        code:path "./validate.go" ;
+        code:relationship "Startup validation"
+    ] ;
+    code:exports :Config, :Server, :Auth, :APIKeys, :DynamoDB, :Tables, :Limits, :Intervals, :Places, :Models, :Tips, :GeoPrivacy, :Stats, :ShareImages, :Solid, :FieldEncryption, :Sync, :Default ;
+    code:tags "config", "settings", "environment", "defaults" .
+<!-- End LinkedDoc RDF -->
+*/
+package config
+
+import "time"
+
+// Config is the complete tracker configuration.
+type Config struct {
+	Server          Server          `yaml:"server" toml:"server"`
+	Auth            Auth            `yaml:"auth" toml:"auth"`
+	APIKeys         APIKeys         `yaml:"api_keys" toml:"api_keys"`
+	DynamoDB        DynamoDB        `yaml:"dynamodb" toml:"dynamodb"`
+	Limits          Limits          `yaml:"limits" toml:"limits"`
+	Intervals       Intervals       `yaml:"intervals" toml:"intervals"`
+	Places          Places          `yaml:"places" toml:"places"`
+	Models          Models          `yaml:"models" toml:"models"`
+	Tips            Tips            `yaml:"tips" toml:"tips"`
+	GeoPrivacy      GeoPrivacy      `yaml:"geo_privacy" toml:"geo_privacy"`
+	Stats           Stats           `yaml:"stats" toml:"stats"`
+	ShareImages     ShareImages     `yaml:"share_images" toml:"share_images"`
+	Solid           Solid           `yaml:"solid" toml:"solid"`
+	FieldEncryption FieldEncryption `yaml:"field_encryption" toml:"field_encryption"`
+	Sync            Sync            `yaml:"sync" toml:"sync"`
+}
+
+// Server covers listeners, TLS, public URLs and the operational endpoints.
+type Server struct {
+	HTTPPort        string        `yaml:"http_port" toml:"http_port" env:"HTTP_PORT"`
+	HTTPSPort       string        `yaml:"https_port" toml:"https_port" env:"HTTPS_PORT"`
+	UseHTTPS        bool          `yaml:"use_https" toml:"use_https" env:"USE_HTTPS"`
+	CertFile        string        `yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"` // self-signed server.crt when unset
+	KeyFile         string        `yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
+	BaseURL         string        `yaml:"base_url" toml:"base_url" env:"BASE_URL"` // public URL used in generated links
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	CertMinValidity time.Duration `yaml:"cert_min_validity" toml:"cert_min_validity" env:"HEALTH_CERT_MIN_VALIDITY"` // readiness fails below this
+	MetricsToken    string        `yaml:"metrics_token" toml:"metrics_token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// Auth covers the tracker password and the Turnstile challenge on tips.
+type Auth struct {
+	TrackerPassword    string `yaml:"tracker_password" toml:"tracker_password" env:"TRACKER_PASSWORD" secret:"true"`
+	TurnstileSiteKey   string `yaml:"turnstile_site_key" toml:"turnstile_site_key" env:"TURNSTILE_SITE_KEY"`
+	TurnstileSecretKey string `yaml:"turnstile_secret_key" toml:"turnstile_secret_key" env:"TURNSTILE_SECRET_KEY" secret:"true"`
+}
+
+// APIKeys are the credentials for third-party APIs.
+type APIKeys struct {
+	GoogleMaps        string `yaml:"google_maps" toml:"google_maps" env:"GOOGLE_MAPS_API_KEY" secret:"true"`
+	Perplexity        string `yaml:"perplexity" toml:"perplexity" env:"PERPLEXITY_API_KEY" secret:"true"`
+	OpenAI            string `yaml:"openai" toml:"openai" env:"OPENAI_API_KEY" secret:"true"`
+	StripeSecret      string `yaml:"stripe_secret" toml:"stripe_secret" env:"STRIPE_SECRET_KEY" secret:"true"`
+	StripePublishable string `yaml:"stripe_publishable" toml:"stripe_publishable" env:"STRIPE_PUBLISHABLE_KEY"`
+}
+
+// DynamoDB locates the tables.
+type DynamoDB struct {
+	Region string `yaml:"region" toml:"region" env:"DYNAMODB_REGION"`
+	Tables Tables `yaml:"tables" toml:"tables"`
+}
+
+// Tables names every DynamoDB table the tracker uses.
+type Tables struct {
+	ErrorLogs            string `yaml:"error_logs" toml:"error_logs" env:"DYNAMODB_TABLE_ERROR_LOGS"`
+	Locations            string `yaml:"locations" toml:"locations" env:"DYNAMODB_TABLE_LOCATIONS"`
+	CommercialRealEstate string `yaml:"commercial_realestate" toml:"commercial_realestate" env:"DYNAMODB_TABLE_COMMERCIAL_REALESTATE"`
+	AnonymousTips        string `yaml:"anonymous_tips" toml:"anonymous_tips" env:"DYNAMODB_TABLE_ANONYMOUS_TIPS"`
+	BannedUsers          string `yaml:"banned_users" toml:"banned_users" env:"DYNAMODB_TABLE_BANNED_USERS"`
+	Donations            string `yaml:"donations" toml:"donations" env:"DYNAMODB_TABLE_DONATIONS"`
+	SolidSessions        string `yaml:"solid_sessions" toml:"solid_sessions" env:"DYNAMODB_TABLE_SOLID_SESSIONS"`
+	SMSMessages          string `yaml:"sms_messages" toml:"sms_messages" env:"DYNAMODB_TABLE_SMS_MESSAGES"`
+	PrivacyAudit         string `yaml:"privacy_audit" toml:"privacy_audit" env:"DYNAMODB_TABLE_PRIVACY_AUDIT"`
+}
+
+// Limits cap what is held in memory and for how long.
+type Limits struct {
+	ErrorLogsInMemory   int           `yaml:"error_logs_in_memory" toml:"error_logs_in_memory" env:"ERROR_LOGS_IN_MEMORY"`
+	TipsInMemory        int           `yaml:"tips_in_memory" toml:"tips_in_memory" env:"TIPS_IN_MEMORY"`
+	SMSMessagesInMemory int           `yaml:"sms_messages_in_memory" toml:"sms_messages_in_memory" env:"SMS_MESSAGES_IN_MEMORY"`
+	LocationMaxAge      time.Duration `yaml:"location_max_age" toml:"location_max_age" env:"LOCATION_MAX_AGE"`             // shared locations are dropped after this
+	CommercialCacheTTL  time.Duration `yaml:"commercial_cache_ttl" toml:"commercial_cache_ttl" env:"COMMERCIAL_CACHE_TTL"` // commercial real estate results are reused this long
+}
+
+// Intervals are the periods of the background cleanup loops.
+type Intervals struct {
+	LocationCleanup     time.Duration `yaml:"location_cleanup" toml:"location_cleanup" env:"LOCATION_CLEANUP_INTERVAL"`
+	RateLimitCleanup    time.Duration `yaml:"rate_limit_cleanup" toml:"rate_limit_cleanup" env:"RATE_LIMIT_CLEANUP_INTERVAL"`
+	BanCleanup          time.Duration `yaml:"ban_cleanup" toml:"ban_cleanup" env:"BAN_CLEANUP_INTERVAL"`
+	SolidSessionCleanup time.Duration `yaml:"solid_session_cleanup" toml:"solid_session_cleanup" env:"SOLID_SESSION_CLEANUP_INTERVAL"`
+}
+
+// Places sets the search radii for nearby businesses and commercial real estate.
+type Places struct {
+	BusinessRadiusMeters        int     `yaml:"business_radius_meters" toml:"business_radius_meters" env:"BUSINESS_RADIUS_METERS"`
+	CommercialSearchRadiusMiles float64 `yaml:"commercial_search_radius_miles" toml:"commercial_search_radius_miles" env:"COMMERCIAL_SEARCH_RADIUS_MILES"` // query point is picked at random within this
+	CommercialCacheRadiusMiles  float64 `yaml:"commercial_cache_radius_miles" toml:"commercial_cache_radius_miles" env:"COMMERCIAL_CACHE_RADIUS_MILES"`    // cached results this close are reused
+}
+
+// Models names the AI models used for generated content.
+type Models struct {
+	CommercialSearch string `yaml:"commercial_search" toml:"commercial_search" env:"COMMERCIAL_SEARCH_MODEL"` // Perplexity
+	Rorschach        string `yaml:"rorschach" toml:"rorschach" env:"RORSCHACH_MODEL"`                         // OpenAI
+}
+
+// Tips configures anonymous tip submission.
+type Tips struct {
+	EncryptionKey    string `yaml:"encryption_key" toml:"encryption_key" env:"TIP_ENCRYPTION_KEY" secret:"true"` // random per process when unset
+	MaxLength        int    `yaml:"max_length" toml:"max_length" env:"TIP_MAX_LENGTH"`
+	RateLimitPerHour int    `yaml:"rate_limit_per_hour" toml:"rate_limit_per_hour" env:"TIP_RATE_LIMIT_PER_HOUR"`
+}
+
+// GeoPrivacy configures the noise added to shared locations.
+type GeoPrivacy struct {
+	Mode    string        `yaml:"mode" toml:"mode" env:"GEO_PRIVACY"` // "on" or "off"
+	Epsilon float64       `yaml:"epsilon" toml:"epsilon" env:"GEO_PRIVACY_EPSILON"`
+	Budget  float64       `yaml:"budget" toml:"budget" env:"GEO_PRIVACY_BUDGET"`
+	Window  time.Duration `yaml:"window" toml:"window" env:"GEO_PRIVACY_WINDOW"`
+	Snap    string        `yaml:"snap" toml:"snap" env:"GEO_PRIVACY_SNAP"` // grid:<metres>, geohash:<precision> or none
+}
+
+// Stats configures the differentially private public statistics.
+type Stats struct {
+	Epsilon float64       `yaml:"epsilon" toml:"epsilon" env:"STATS_EPSILON"`
+	Delta   float64       `yaml:"delta" toml:"delta" env:"STATS_DELTA"`
+	Budget  float64       `yaml:"budget" toml:"budget" env:"STATS_BUDGET"`
+	Window  time.Duration `yaml:"window" toml:"window" env:"STATS_WINDOW"`
+	Refresh time.Duration `yaml:"refresh" toml:"refresh" env:"STATS_REFRESH"`
+	Days    int           `yaml:"days" toml:"days" env:"STATS_DAYS"`
+}
+
+// ShareImages configures where rendered share images are stored and how they are rendered.
+type ShareImages struct {
+	S3Bucket    string `yaml:"s3_bucket" toml:"s3_bucket" env:"SHARE_IMAGE_S3_BUCKET"` // local disk when unset
+	S3Prefix    string `yaml:"s3_prefix" toml:"s3_prefix" env:"SHARE_IMAGE_S3_PREFIX"`
+	S3Endpoint  string `yaml:"s3_endpoint" toml:"s3_endpoint" env:"SHARE_IMAGE_S3_ENDPOINT"` // for S3-compatible services
+	S3Region    string `yaml:"s3_region" toml:"s3_region" env:"AWS_REGION"`
+	Dir         string `yaml:"dir" toml:"dir" env:"SHARE_IMAGE_DIR"`
+	CacheMB     int    `yaml:"cache_mb" toml:"cache_mb" env:"SHARE_IMAGE_CACHE_MB"`
+	StoreMB     int    `yaml:"store_mb" toml:"store_mb" env:"SHARE_IMAGE_STORE_MB"` // local disk budget
+	Workers     int    `yaml:"workers" toml:"workers" env:"SHARE_IMAGE_WORKERS"`
+	QueueSize   int    `yaml:"queue_size" toml:"queue_size" env:"SHARE_IMAGE_QUEUE_SIZE"`
+	GIFMaxBytes int    `yaml:"gif_max_bytes" toml:"gif_max_bytes" env:"SHARE_GIF_MAX_BYTES"`
+}
+
+// Solid configures login with Solid pods.
+type Solid struct {
+	Enabled            bool          `yaml:"enabled" toml:"enabled" env:"SOLID_ENABLED"`
+	OwnerWebIDs        []string      `yaml:"owner_webids" toml:"owner_webids" env:"SOLID_OWNER_WEBIDS"` // comma-separated in the environment
+	ClientID           string        `yaml:"client_id" toml:"client_id" env:"SOLID_CLIENT_ID"`
+	SessionStore       string        `yaml:"session_store" toml:"session_store" env:"SOLID_SESSION_STORE"` // memory, file or dynamodb; dynamodb when available if unset
+	SessionKey         string        `yaml:"session_key" toml:"session_key" env:"SOLID_SESSION_KEY" secret:"true"`
+	SessionDir         string        `yaml:"session_dir" toml:"session_dir" env:"SOLID_SESSION_DIR"`
+	SessionIdleTimeout time.Duration `yaml:"session_idle_timeout" toml:"session_idle_timeout" env:"SOLID_SESSION_IDLE_TIMEOUT"` // 0 disables
+}
+
+// FieldEncryption selects the key provider for sensitive fields.
+type FieldEncryption struct {
+	Provider    string `yaml:"provider" toml:"provider" env:"FIELD_ENCRYPTION_PROVIDER"` // local or kms; plaintext when unset
+	KeyringPath string `yaml:"keyring_path" toml:"keyring_path" env:"FIELD_KEYRING_PATH"`
+	KMSKeyID    string `yaml:"kms_key_id" toml:"kms_key_id" env:"FIELD_KMS_KEY_ID"`
+}
+
+// Sync configures replication with pods and offline clients.
+type Sync struct {
+	NodeID string `yaml:"node_id" toml:"node_id" env:"SYNC_NODE_ID"` // hostname when unset
+}
+
+// Default returns the configuration used when neither a file nor the environment sets a value.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			HTTPPort:        "8080",
+			HTTPSPort:       "8443",
+			BaseURL:         "https://notspies.org",
+			ShutdownTimeout: 25 * time.Second,
+			CertMinValidity: 7 * 24 * time.Hour,
+		},
+		DynamoDB: DynamoDB{
+			Region: "us-east-1",
+			Tables: Tables{
+				ErrorLogs:            "location-tracker-error-logs",
+				Locations:            "location-tracker-locations",
+				CommercialRealEstate: "location-tracker-commercial-realestate",
+				AnonymousTips:        "location-tracker-anonymous-tips",
+				BannedUsers:          "location-tracker-banned-users",
+				Donations:            "location-tracker-donations",
+				SolidSessions:        "location-tracker-solid-sessions",
+				SMSMessages:          "location-tracker-sms-messages",
+				PrivacyAudit:         "location-tracker-privacy-audit",
+			},
+		},
+		Limits: Limits{
+			ErrorLogsInMemory:   50,
+			TipsInMemory:        100,
+			SMSMessagesInMemory: 100,
+			LocationMaxAge:      24 * time.Hour,
+			CommercialCacheTTL:  30 * 24 * time.Hour,
+		},
+		Intervals: Intervals{
+			LocationCleanup:     time.Hour,
+			RateLimitCleanup:    5 * time.Minute,
+			BanCleanup:          10 * time.Minute,
+			SolidSessionCleanup: time.Minute,
+		},
+		Places: Places{
+			BusinessRadiusMeters:        500,
+			CommercialSearchRadiusMiles: 10,
+			CommercialCacheRadiusMiles:  5,
+		},
+		Models: Models{
+			CommercialSearch: "sonar",
+			Rorschach:        "gpt-4",
+		},
+		Tips: Tips{
+			MaxLength:        1000,
+			RateLimitPerHour: 10,
+		},
+		GeoPrivacy: GeoPrivacy{
+			Mode:    "on",
+			Epsilon: 0.01,
+			Budget:  1.0,
+			Window:  24 * time.Hour,
+			Snap:    "geohash:7",
+		},
+		Stats: Stats{
+			Epsilon: 0.5,
+			Delta:   1e-6,
+			Budget:  2.0,
+			Window:  24 * time.Hour,
+			Refresh: 6 * time.Hour,
+			Days:    30,
+		},
+		ShareImages: ShareImages{
+			S3Region:    "us-east-1",
+			Dir:         "share-images",
+			CacheMB:     64,
+			StoreMB:     1024,
+			Workers:     2,
+			QueueSize:   32,
+			GIFMaxBytes: 5 * 1024 * 1024,
+		},
+		Solid: Solid{
+			SessionDir:         "solid-sessions",
+			SessionIdleTimeout: 24 * time.Hour,
+		},
+	}
+}
@@ -0,0 +1,169 @@
+/*
+# Module: config/validate.go
+Startup validation: checks every value is usable and reports all problems at once,
+naming each by its file key and environment variable.
+
+## Linked Modules
+- [config/config](./config.go) - Config fields and their tags
+
+## Tags
+config, validation, startup
+
+## Exports
+Validate
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "config/validate.go" ;
+    code:description "Startup validation of the tracker configuration" ;
+    code:linksTo [
+        code:name "config/config" ;
+        code:path "./config.go" ;
+        code:relationship "Config fields and their tags"
+    ] ;
+    code:exports :Validate ;
+    code:tags "config", "validation", "startup" .
+<!-- End LinkedDoc RDF -->
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DynamoDB table names: 3-255 letters, digits, underscores, hyphens and dots
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,255}$`)
+
+// Validate reports every invalid setting, or nil when the configuration can be used.
+func (c *Config) Validate() error {
+	var v validator
+
+	v.check(c.Auth.TrackerPassword != "", "auth.tracker_password (TRACKER_PASSWORD) must be set")
+
+	v.port("server.http_port (HTTP_PORT)", c.Server.HTTPPort)
+	if c.Server.UseHTTPS {
+		v.port("server.https_port (HTTPS_PORT)", c.Server.HTTPSPort)
+	}
+	v.check((c.Server.CertFile == "") == (c.Server.KeyFile == ""),
+		"server.cert_file (CERT_FILE) and server.key_file (KEY_FILE) must be set together")
+	if u, err := url.Parse(c.Server.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.fail("server.base_url (BASE_URL) %q must be an absolute http(s) URL", c.Server.BaseURL)
+	}
+	v.positiveDuration("server.shutdown_timeout (SHUTDOWN_TIMEOUT)", c.Server.ShutdownTimeout)
+	v.positiveDuration("server.cert_min_validity (HEALTH_CERT_MIN_VALIDITY)", c.Server.CertMinValidity)
+
+	v.check(c.DynamoDB.Region != "", "dynamodb.region (DYNAMODB_REGION) must be set")
+	tables := c.DynamoDB.Tables
+	for _, table := range []struct{ key, name string }{
+		{"error_logs (DYNAMODB_TABLE_ERROR_LOGS)", tables.ErrorLogs},
+		{"locations (DYNAMODB_TABLE_LOCATIONS)", tables.Locations},
+		{"commercial_realestate (DYNAMODB_TABLE_COMMERCIAL_REALESTATE)", tables.CommercialRealEstate},
+		{"anonymous_tips (DYNAMODB_TABLE_ANONYMOUS_TIPS)", tables.AnonymousTips},
+		{"banned_users (DYNAMODB_TABLE_BANNED_USERS)", tables.BannedUsers},
+		{"donations (DYNAMODB_TABLE_DONATIONS)", tables.Donations},
+		{"solid_sessions (DYNAMODB_TABLE_SOLID_SESSIONS)", tables.SolidSessions},
+		{"sms_messages (DYNAMODB_TABLE_SMS_MESSAGES)", tables.SMSMessages},
+		{"privacy_audit (DYNAMODB_TABLE_PRIVACY_AUDIT)", tables.PrivacyAudit},
+	} {
+		v.check(tableNamePattern.MatchString(table.name), "dynamodb.tables.%s %q is not a valid table name", table.key, table.name)
+	}
+
+	v.positive("limits.error_logs_in_memory (ERROR_LOGS_IN_MEMORY)", c.Limits.ErrorLogsInMemory)
+	v.positive("limits.tips_in_memory (TIPS_IN_MEMORY)", c.Limits.TipsInMemory)
+	v.positive("limits.sms_messages_in_memory (SMS_MESSAGES_IN_MEMORY)", c.Limits.SMSMessagesInMemory)
+	v.positiveDuration("limits.location_max_age (LOCATION_MAX_AGE)", c.Limits.LocationMaxAge)
+	v.positiveDuration("limits.commercial_cache_ttl (COMMERCIAL_CACHE_TTL)", c.Limits.CommercialCacheTTL)
+
+	v.positiveDuration("intervals.location_cleanup (LOCATION_CLEANUP_INTERVAL)", c.Intervals.LocationCleanup)
+	v.positiveDuration("intervals.rate_limit_cleanup (RATE_LIMIT_CLEANUP_INTERVAL)", c.Intervals.RateLimitCleanup)
+	v.positiveDuration("intervals.ban_cleanup (BAN_CLEANUP_INTERVAL)", c.Intervals.BanCleanup)
+	v.positiveDuration("intervals.solid_session_cleanup (SOLID_SESSION_CLEANUP_INTERVAL)", c.Intervals.SolidSessionCleanup)
+
+	v.check(c.Places.BusinessRadiusMeters > 0 && c.Places.BusinessRadiusMeters <= 50000,
+		"places.business_radius_meters (BUSINESS_RADIUS_METERS) must be between 1 and 50000, the Places API maximum")
+	v.check(c.Places.CommercialSearchRadiusMiles > 0, "places.commercial_search_radius_miles (COMMERCIAL_SEARCH_RADIUS_MILES) must be positive")
+	v.check(c.Places.CommercialCacheRadiusMiles >= 0, "places.commercial_cache_radius_miles (COMMERCIAL_CACHE_RADIUS_MILES) must not be negative")
+
+	v.check(c.Models.CommercialSearch != "", "models.commercial_search (COMMERCIAL_SEARCH_MODEL) must be set")
+	v.check(c.Models.Rorschach != "", "models.rorschach (RORSCHACH_MODEL) must be set")
+
+	v.positive("tips.max_length (TIP_MAX_LENGTH)", c.Tips.MaxLength)
+	v.positive("tips.rate_limit_per_hour (TIP_RATE_LIMIT_PER_HOUR)", c.Tips.RateLimitPerHour)
+
+	switch c.GeoPrivacy.Mode {
+	case "on":
+		v.check(c.GeoPrivacy.Epsilon > 0, "geo_privacy.epsilon (GEO_PRIVACY_EPSILON) must be positive")
+		v.check(c.GeoPrivacy.Budget >= c.GeoPrivacy.Epsilon, "geo_privacy.budget (GEO_PRIVACY_BUDGET) must be at least the epsilon")
+		v.positiveDuration("geo_privacy.window (GEO_PRIVACY_WINDOW)", c.GeoPrivacy.Window)
+	case "off":
+	default:
+		v.fail("geo_privacy.mode (GEO_PRIVACY) %q must be on or off", c.GeoPrivacy.Mode)
+	}
+
+	v.check(c.Stats.Epsilon > 0 && c.Stats.Epsilon < 1, "stats.epsilon (STATS_EPSILON) must be between 0 and 1")
+	v.check(c.Stats.Delta > 0 && c.Stats.Delta < 1, "stats.delta (STATS_DELTA) must be between 0 and 1")
+	v.check(c.Stats.Budget >= c.Stats.Epsilon, "stats.budget (STATS_BUDGET) must be at least the epsilon; nothing could be released")
+	v.positiveDuration("stats.window (STATS_WINDOW)", c.Stats.Window)
+	v.positiveDuration("stats.refresh (STATS_REFRESH)", c.Stats.Refresh)
+	v.positive("stats.days (STATS_DAYS)", c.Stats.Days)
+
+	v.positive("share_images.cache_mb (SHARE_IMAGE_CACHE_MB)", c.ShareImages.CacheMB)
+	v.positive("share_images.store_mb (SHARE_IMAGE_STORE_MB)", c.ShareImages.StoreMB)
+	v.positive("share_images.workers (SHARE_IMAGE_WORKERS)", c.ShareImages.Workers)
+	v.positive("share_images.queue_size (SHARE_IMAGE_QUEUE_SIZE)", c.ShareImages.QueueSize)
+	v.positive("share_images.gif_max_bytes (SHARE_GIF_MAX_BYTES)", c.ShareImages.GIFMaxBytes)
+	v.check(c.ShareImages.S3Bucket == "" || c.ShareImages.S3Region != "", "share_images.s3_region (AWS_REGION) must be set with an S3 bucket")
+
+	switch c.Solid.SessionStore {
+	case "", "memory", "file", "dynamodb":
+	default:
+		v.fail("solid.session_store (SOLID_SESSION_STORE) %q must be memory, file or dynamodb", c.Solid.SessionStore)
+	}
+	v.check(c.Solid.SessionIdleTimeout >= 0, "solid.session_idle_timeout (SOLID_SESSION_IDLE_TIMEOUT) must not be negative")
+
+	switch c.FieldEncryption.Provider {
+	case "":
+	case "local":
+		v.check(c.FieldEncryption.KeyringPath != "", "field_encryption.keyring_path (FIELD_KEYRING_PATH) must be set for the local key provider")
+	case "kms":
+		v.check(c.FieldEncryption.KMSKeyID != "", "field_encryption.kms_key_id (FIELD_KMS_KEY_ID) must be set for the kms key provider")
+	default:
+		v.fail("field_encryption.provider (FIELD_ENCRYPTION_PROVIDER) %q must be local or kms", c.FieldEncryption.Provider)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// validator collects failures so they can all be reported together
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.fail(format, args...)
+	}
+}
+
+func (v *validator) positive(name string, n int) {
+	v.check(n > 0, "%s must be positive, got %d", name, n)
+}
+
+func (v *validator) positiveDuration(name string, d time.Duration) {
+	v.check(d > 0, "%s must be a positive duration, got %s", name, d)
+}
+
+func (v *validator) port(name, port string) {
+	n, err := strconv.Atoi(port)
+	v.check(err == nil && n > 0 && n < 65536, "%s %q must be a port number", name, port)
+}
@@ -0,0 +1,139 @@
+/*
+# Module: dpstats/accountant.go
+Privacy budget accounting per query over a sliding window.
+
+## Linked Modules
+- [dpstats/mechanism](./mechanism.go) - Mechanisms whose epsilon and delta are spent
+
+## Tags
+privacy, differential-privacy, budget
+
+## Exports
+Accountant, NewAccountant, Budget
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "dpstats/accountant.go" ;
+    code:description "Privacy budget accounting per query over a sliding window" ;
+    code:linksTo [
+        code:name "dpstats/mechanism" ;
+        code:path "./mechanism.go" ;
+        code:relationship "Mechanisms whose epsilon and delta are spent"
+    ] ;
+    code:exports :Accountant, :NewAccountant, :Budget ;
+    code:tags "privacy", "differential-privacy", "budget" .
+<!-- End LinkedDoc RDF -->
+*/
+package dpstats
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Budget is an amount of (epsilon, delta) privacy loss.
+type Budget struct {
+	Epsilon float64 `json:"epsilon"`
+	Delta   float64 `json:"delta"`
+}
+
+type spend struct {
+	at     time.Time
+	amount Budget
+}
+
+// Accountant tracks the privacy loss of each query over a sliding window, using basic
+// composition: the epsilons and deltas of releases in the window add up. Each query has
+// its own budget, so the total guarantee across all queries is the sum of their budgets.
+type Accountant struct {
+	limit  Budget
+	window time.Duration
+
+	mu     sync.Mutex
+	spends map[string][]spend
+}
+
+// NewAccountant returns an accountant allowing limit per query within each window.
+func NewAccountant(limit Budget, window time.Duration) (*Accountant, error) {
+	if limit.Epsilon <= 0 || limit.Delta < 0 || limit.Delta >= 1 {
+		return nil, fmt.Errorf("budget epsilon must be positive and delta in [0, 1)")
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("budget window must be positive")
+	}
+	return &Accountant{limit: limit, window: window, spends: make(map[string][]spend)}, nil
+}
+
+// Limit returns the budget each query has per window.
+func (a *Accountant) Limit() Budget {
+	return a.limit
+}
+
+// Window returns the accounting window.
+func (a *Accountant) Window() time.Duration {
+	return a.window
+}
+
+// Spend charges cost to query if its remaining budget covers it, and returns the budget
+// left afterwards. Nothing is charged when it returns false.
+func (a *Accountant) Spend(query string, cost Budget, now time.Time) (Budget, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	remaining := a.remainingLocked(query, now)
+	if cost.Epsilon > remaining.Epsilon || cost.Delta > remaining.Delta {
+		return remaining, false
+	}
+	a.spends[query] = append(a.spends[query], spend{at: now, amount: cost})
+	return a.remainingLocked(query, now), true
+}
+
+// Remaining returns the budget query has left in the current window.
+func (a *Accountant) Remaining(query string, now time.Time) Budget {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.remainingLocked(query, now)
+}
+
+// Refill returns when the oldest spend for query leaves the window, or the zero time
+// if nothing has been spent.
+func (a *Accountant) Refill(query string, now time.Time) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expireLocked(query, now)
+	if spends := a.spends[query]; len(spends) > 0 {
+		return spends[0].at.Add(a.window)
+	}
+	return time.Time{}
+}
+
+func (a *Accountant) remainingLocked(query string, now time.Time) Budget {
+	a.expireLocked(query, now)
+	remaining := a.limit
+	for _, s := range a.spends[query] {
+		remaining.Epsilon -= s.amount.Epsilon
+		remaining.Delta -= s.amount.Delta
+	}
+	// round away float drift so a budget of exact multiples is fully usable
+	remaining.Epsilon = math.Max(0, math.Round(remaining.Epsilon*1e9)/1e9)
+	remaining.Delta = math.Max(0, math.Round(remaining.Delta*1e15)/1e15)
+	return remaining
+}
+
+// expireLocked drops spends older than the window
+func (a *Accountant) expireLocked(query string, now time.Time) {
+	spends := a.spends[query]
+	cutoff := now.Add(-a.window)
+	i := 0
+	for i < len(spends) && !spends[i].at.After(cutoff) {
+		i++
+	}
+	if i == len(spends) {
+		delete(a.spends, query)
+		return
+	}
+	a.spends[query] = spends[i:]
+}
@@ -0,0 +1,115 @@
+package dpstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccountantExhaustion(t *testing.T) {
+	window := 24 * time.Hour
+	accountant, err := NewAccountant(Budget{Epsilon: 2, Delta: 4e-6}, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	cost := Budget{Epsilon: 0.5, Delta: 1e-6}
+
+	for i := 0; i < 4; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		remaining, ok := accountant.Spend("cases", cost, at)
+		if !ok {
+			t.Fatalf("spend %d refused with %+v left", i+1, remaining)
+		}
+		if want := 2 - 0.5*float64(i+1); remaining.Epsilon != want {
+			t.Errorf("after spend %d, %g epsilon left, want %g", i+1, remaining.Epsilon, want)
+		}
+	}
+
+	// Spent: refused without charging, and other queries are unaffected
+	now := start.Add(5 * time.Hour)
+	if remaining, ok := accountant.Spend("cases", cost, now); ok || remaining != (Budget{}) {
+		t.Fatalf("spend past the budget = %+v, %v", remaining, ok)
+	}
+	if remaining := accountant.Remaining("cases", now); remaining != (Budget{}) {
+		t.Errorf("refused spend was charged: %+v left", remaining)
+	}
+	if remaining := accountant.Remaining("tips", now); remaining != accountant.Limit() {
+		t.Errorf("another query has %+v left, want the full budget", remaining)
+	}
+	if refill := accountant.Refill("cases", now); !refill.Equal(start.Add(window)) {
+		t.Errorf("Refill = %s, want %s", refill, start.Add(window))
+	}
+	if refill := accountant.Refill("tips", now); !refill.IsZero() {
+		t.Errorf("Refill with nothing spent = %s, want zero", refill)
+	}
+
+	// The oldest spend leaves the window exactly a window after it was made
+	if _, ok := accountant.Spend("cases", cost, start.Add(window-time.Nanosecond)); ok {
+		t.Error("spend allowed before the oldest one left the window")
+	}
+	remaining, ok := accountant.Spend("cases", cost, start.Add(window))
+	if !ok || remaining.Epsilon != 0 {
+		t.Errorf("spend once the oldest left the window = %+v, %v", remaining, ok)
+	}
+	if remaining := accountant.Remaining("cases", start.Add(window+4*time.Hour)); remaining.Epsilon != 1.5 {
+		t.Errorf("after the first four spends expired, %g epsilon left, want 1.5", remaining.Epsilon)
+	}
+}
+
+func TestAccountantDeltaExhaustion(t *testing.T) {
+	accountant, err := NewAccountant(Budget{Epsilon: 10, Delta: 2e-6}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cost := Budget{Epsilon: 0.5, Delta: 1e-6}
+	for i := 0; i < 2; i++ {
+		if _, ok := accountant.Spend("keywords", cost, now); !ok {
+			t.Fatalf("spend %d refused", i+1)
+		}
+	}
+	remaining, ok := accountant.Spend("keywords", cost, now)
+	if ok {
+		t.Fatal("spend allowed past the delta budget")
+	}
+	if remaining.Epsilon != 9 || remaining.Delta != 0 {
+		t.Errorf("remaining = %+v, want 9 epsilon and no delta", remaining)
+	}
+	if _, ok := accountant.Spend("keywords", Budget{Epsilon: 0.5}, now); !ok {
+		t.Error("a spend without delta was refused while epsilon is left")
+	}
+}
+
+func TestAccountantFloatDrift(t *testing.T) {
+	// 0.1 ten times does not add up to exactly 1 in floating point
+	accountant, err := NewAccountant(Budget{Epsilon: 1}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		if remaining, ok := accountant.Spend("q", Budget{Epsilon: 0.1}, now); !ok {
+			t.Fatalf("spend %d refused with %+v left", i+1, remaining)
+		}
+	}
+	if _, ok := accountant.Spend("q", Budget{Epsilon: 0.1}, now); ok {
+		t.Error("an eleventh spend was allowed")
+	}
+}
+
+func TestNewAccountantValidation(t *testing.T) {
+	invalid := []struct {
+		limit  Budget
+		window time.Duration
+	}{
+		{Budget{Epsilon: 0}, time.Hour},
+		{Budget{Epsilon: 1, Delta: -1e-6}, time.Hour},
+		{Budget{Epsilon: 1, Delta: 1}, time.Hour},
+		{Budget{Epsilon: 1}, 0},
+	}
+	for _, tt := range invalid {
+		if _, err := NewAccountant(tt.limit, tt.window); err == nil {
+			t.Errorf("NewAccountant(%+v, %s) succeeded", tt.limit, tt.window)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+# Module: dpstats/histogram.go
+Noisy counts and histograms over fixed and open-ended sets of keys.
+
+## Linked Modules
+- [dpstats/mechanism](./mechanism.go) - Noise added to each bin
+
+## Tags
+privacy, differential-privacy, histogram, statistics
+
+## Exports
+Bin, Histogram, OpenHistogram, Threshold, BoundContributions
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "dpstats/histogram.go" ;
+    code:description "Noisy counts and histograms over fixed and open-ended sets of keys" ;
+    code:linksTo [
+        code:name "dpstats/mechanism" ;
+        code:path "./mechanism.go" ;
+        code:relationship "Noise added to each bin"
+    ] ;
+    code:exports :Bin, :Histogram, :OpenHistogram, :Threshold, :BoundContributions ;
+    code:tags "privacy", "differential-privacy", "histogram", "statistics" .
+<!-- End LinkedDoc RDF -->
+*/
+package dpstats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Bin is one released histogram bar.
+type Bin struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Histogram releases a noisy count for every key in keys, including keys with no
+// records. Because the keys are fixed in advance, nothing about their presence leaks
+// and no threshold is needed. Counts are rounded and clamped at zero, which is
+// post-processing and costs no budget.
+func Histogram(keys []string, counts map[string]int, m Mechanism) []Bin {
+	bins := make([]Bin, 0, len(keys))
+	for _, key := range keys {
+		bins = append(bins, Bin{Key: key, Count: release(counts[key], m)})
+	}
+	return bins
+}
+
+// OpenHistogram releases noisy counts for keys that come from the data itself, such
+// as keywords. Only keys that appear are considered, so a key's presence is itself
+// sensitive: bins whose noisy count falls below threshold are withheld. Bins are
+// returned largest first.
+func OpenHistogram(counts map[string]int, m Mechanism, threshold float64) []Bin {
+	bins := make([]Bin, 0)
+	for key, count := range counts {
+		noisy := float64(count) + m.Noise()
+		if noisy < threshold {
+			continue
+		}
+		bins = append(bins, Bin{Key: key, Count: int(math.Round(noisy))})
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		if bins[i].Count != bins[j].Count {
+			return bins[i].Count > bins[j].Count
+		}
+		return bins[i].Key < bins[j].Key
+	})
+	return bins
+}
+
+// Threshold returns the cut-off for OpenHistogram under m when each record touches at
+// most maxKeys keys: a key held by a single record is released with probability at
+// most delta in total. That delta adds to the mechanism's own.
+func Threshold(m Mechanism, maxKeys int, delta float64) (float64, error) {
+	if maxKeys < 1 || delta <= 0 || delta >= 1 {
+		return 0, fmt.Errorf("threshold needs maxKeys >= 1 and delta in (0, 1)")
+	}
+	p := delta / float64(maxKeys)
+	switch mech := m.(type) {
+	case Laplace:
+		// P(Laplace(b) > t) = exp(-t/b) / 2
+		return 1 + mech.Scale()*math.Log(1/(2*p)), nil
+	case Gaussian:
+		// P(N(0, sigma^2) > t) = erfc(t / (sigma sqrt 2)) / 2
+		return 1 + mech.Sigma()*math.Sqrt2*math.Erfcinv(2*p), nil
+	default:
+		return 0, fmt.Errorf("no threshold for mechanism %q", m.Params().Mechanism)
+	}
+}
+
+// BoundContributions normalises keys (trimmed, lower case), drops duplicates and keeps
+// at most max of them, so one record changes at most max bins by one each.
+func BoundContributions(keys []string, max int) []string {
+	seen := make(map[string]bool, len(keys))
+	bounded := make([]string, 0, max)
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" || seen[key] {
+			continue
+		}
+		if len(bounded) == max {
+			break
+		}
+		seen[key] = true
+		bounded = append(bounded, key)
+	}
+	return bounded
+}
+
+// release adds noise to a count, then rounds and clamps it
+func release(count int, m Mechanism) int {
+	noisy := math.Round(float64(count) + m.Noise())
+	if noisy < 0 {
+		return 0
+	}
+	return int(noisy)
+}
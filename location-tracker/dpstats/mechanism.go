@@ -0,0 +1,149 @@
+/*
+# Module: dpstats/mechanism.go
+Laplace and Gaussian mechanisms for releasing counts with differential privacy.
+
+## Linked Modules
+- [dpstats/histogram](./histogram.go) - Noisy histograms built on the mechanisms
+- [dpstats/accountant](./accountant.go) - Privacy budget per query
+
+## Tags
+privacy, differential-privacy, laplace, gaussian, statistics
+
+## Exports
+Mechanism, Laplace, Gaussian, Params
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "dpstats/mechanism.go" ;
+    code:description "Laplace and Gaussian mechanisms for differentially private counts" ;
+    code:linksTo [
+        code:name "dpstats/histogram" ;
+        code:path "./histogram.go" ;
+        code:relationship "Noisy histograms built on the mechanisms"
+    ], [
+        code:name "dpstats/accountant" ;
+        code:path "./accountant.go" ;
+        code:relationship "Privacy budget per query"
+    ] ;
+    code:exports :Mechanism, :Laplace, :Gaussian, :Params ;
+    code:tags "privacy", "differential-privacy", "laplace", "gaussian", "statistics" .
+<!-- End LinkedDoc RDF -->
+*/
+package dpstats
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Mechanism adds calibrated noise to a value whose sensitivity is known.
+type Mechanism interface {
+	// Noise returns one noise sample to add to a true value
+	Noise() float64
+	// Params discloses the mechanism and its parameters
+	Params() Params
+	// Validate checks the parameters give a privacy guarantee
+	Validate() error
+}
+
+// Params describes a mechanism, for publishing next to its output.
+type Params struct {
+	Mechanism   string  `json:"mechanism"`
+	Epsilon     float64 `json:"epsilon"`
+	Delta       float64 `json:"delta,omitempty"`
+	Sensitivity float64 `json:"sensitivity"`         // L1 for Laplace, L2 for Gaussian
+	Scale       float64 `json:"scale,omitempty"`     // Laplace b = sensitivity / epsilon
+	Sigma       float64 `json:"sigma,omitempty"`     // Gaussian standard deviation
+	Threshold   float64 `json:"threshold,omitempty"` // noisy counts below this are withheld
+	MaxKeys     int     `json:"max_keys_per_record,omitempty"`
+}
+
+// Laplace is the Laplace mechanism: noise with scale Sensitivity/Epsilon gives
+// epsilon-differential privacy for a query with that L1 sensitivity.
+type Laplace struct {
+	Epsilon     float64
+	Sensitivity float64
+	Uniform     func() float64 // [0, 1); nil uses crypto/rand
+}
+
+// Scale returns the Laplace scale b.
+func (m Laplace) Scale() float64 {
+	return m.Sensitivity / m.Epsilon
+}
+
+// Noise returns a Laplace(0, b) sample.
+func (m Laplace) Noise() float64 {
+	u := uniform(m.Uniform) - 0.5
+	sign := 1.0
+	if u < 0 {
+		sign = -1
+	}
+	return -m.Scale() * sign * math.Log(1-2*math.Abs(u))
+}
+
+// Params discloses the mechanism.
+func (m Laplace) Params() Params {
+	return Params{Mechanism: "laplace", Epsilon: m.Epsilon, Sensitivity: m.Sensitivity, Scale: m.Scale()}
+}
+
+// Validate checks epsilon and sensitivity are positive.
+func (m Laplace) Validate() error {
+	if m.Epsilon <= 0 || m.Sensitivity <= 0 {
+		return fmt.Errorf("laplace: epsilon and sensitivity must be positive")
+	}
+	return nil
+}
+
+// Gaussian is the classic Gaussian mechanism: noise with standard deviation
+// sqrt(2 ln(1.25/delta)) * Sensitivity / Epsilon gives (epsilon, delta)-differential
+// privacy for a query with that L2 sensitivity, for epsilon < 1.
+type Gaussian struct {
+	Epsilon     float64
+	Delta       float64
+	Sensitivity float64
+	Uniform     func() float64 // [0, 1); nil uses crypto/rand
+}
+
+// Sigma returns the noise standard deviation.
+func (m Gaussian) Sigma() float64 {
+	return math.Sqrt(2*math.Log(1.25/m.Delta)) * m.Sensitivity / m.Epsilon
+}
+
+// Noise returns a N(0, sigma^2) sample (Box-Muller).
+func (m Gaussian) Noise() float64 {
+	u1 := 1 - uniform(m.Uniform) // (0, 1]
+	u2 := uniform(m.Uniform)
+	return m.Sigma() * math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+// Params discloses the mechanism.
+func (m Gaussian) Params() Params {
+	return Params{Mechanism: "gaussian", Epsilon: m.Epsilon, Delta: m.Delta, Sensitivity: m.Sensitivity, Sigma: m.Sigma()}
+}
+
+// Validate checks the parameters are in the range the classic analysis covers.
+func (m Gaussian) Validate() error {
+	switch {
+	case m.Epsilon <= 0 || m.Epsilon >= 1:
+		return fmt.Errorf("gaussian: epsilon must be in (0, 1)")
+	case m.Delta <= 0 || m.Delta >= 1:
+		return fmt.Errorf("gaussian: delta must be in (0, 1)")
+	case m.Sensitivity <= 0:
+		return fmt.Errorf("gaussian: sensitivity must be positive")
+	}
+	return nil
+}
+
+func uniform(source func() float64) float64 {
+	if source != nil {
+		return source()
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("dpstats: crypto/rand failed: " + err.Error())
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
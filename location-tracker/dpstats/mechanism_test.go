@@ -0,0 +1,152 @@
+package dpstats
+
+import (
+	"math"
+	"testing"
+)
+
+// sequence returns a Uniform source that yields values in turn
+func sequence(values ...float64) func() float64 {
+	i := 0
+	return func() float64 {
+		v := values[i%len(values)]
+		i++
+		return v
+	}
+}
+
+func TestLaplaceCalibration(t *testing.T) {
+	tests := []struct {
+		epsilon, sensitivity, scale float64
+	}{
+		{0.5, 1, 2},
+		{1, 1, 1},
+		{0.1, 3, 30},
+	}
+	for _, tt := range tests {
+		m := Laplace{Epsilon: tt.epsilon, Sensitivity: tt.sensitivity}
+		if got := m.Scale(); math.Abs(got-tt.scale) > 1e-12 {
+			t.Errorf("Laplace(%g, %g).Scale() = %g, want %g", tt.epsilon, tt.sensitivity, got, tt.scale)
+		}
+		params := m.Params()
+		if params.Mechanism != "laplace" || params.Epsilon != tt.epsilon || params.Sensitivity != tt.sensitivity || params.Scale != m.Scale() {
+			t.Errorf("Laplace(%g, %g).Params() = %+v", tt.epsilon, tt.sensitivity, params)
+		}
+
+		// The inverse CDF at u = 0.75 is b ln 2, and the distribution is symmetric
+		m.Uniform = sequence(0.75)
+		if got, want := m.Noise(), tt.scale*math.Ln2; math.Abs(got-want) > 1e-9 {
+			t.Errorf("Laplace(%g, %g) noise at 0.75 = %g, want %g", tt.epsilon, tt.sensitivity, got, want)
+		}
+		m.Uniform = sequence(0.25)
+		if got, want := m.Noise(), -tt.scale*math.Ln2; math.Abs(got-want) > 1e-9 {
+			t.Errorf("Laplace(%g, %g) noise at 0.25 = %g, want %g", tt.epsilon, tt.sensitivity, got, want)
+		}
+	}
+
+	// Sampled from crypto/rand: mean 0, mean absolute deviation b, variance 2b^2
+	m := Laplace{Epsilon: 0.5, Sensitivity: 1}
+	mean, meanAbs, variance := sampleMoments(m, 200000)
+	if math.Abs(mean) > 0.05 || math.Abs(meanAbs/m.Scale()-1) > 0.02 || math.Abs(variance/(2*m.Scale()*m.Scale())-1) > 0.05 {
+		t.Errorf("Laplace b=%g samples: mean %g, mean |x| %g, variance %g", m.Scale(), mean, meanAbs, variance)
+	}
+}
+
+func TestGaussianCalibration(t *testing.T) {
+	tests := []struct {
+		epsilon, delta, sensitivity float64
+	}{
+		{0.5, 1e-6, math.Sqrt(5)},
+		{0.9, 1e-5, 1},
+		{0.1, 0.01, 2},
+	}
+	for _, tt := range tests {
+		m := Gaussian{Epsilon: tt.epsilon, Delta: tt.delta, Sensitivity: tt.sensitivity}
+		want := math.Sqrt(2*math.Log(1.25/tt.delta)) * tt.sensitivity / tt.epsilon
+		if got := m.Sigma(); math.Abs(got-want) > 1e-9*want {
+			t.Errorf("Gaussian(%g, %g, %g).Sigma() = %g, want %g", tt.epsilon, tt.delta, tt.sensitivity, got, want)
+		}
+		params := m.Params()
+		if params.Mechanism != "gaussian" || params.Delta != tt.delta || params.Sigma != m.Sigma() {
+			t.Errorf("Gaussian(%g, %g, %g).Params() = %+v", tt.epsilon, tt.delta, tt.sensitivity, params)
+		}
+
+		// Box-Muller with u1 = e^-1/2 and u2 = 0 gives exactly one standard deviation
+		m.Uniform = sequence(1-math.Exp(-0.5), 0)
+		if got := m.Noise(); math.Abs(got-want) > 1e-9*want {
+			t.Errorf("Gaussian(%g, %g, %g) noise = %g, want sigma %g", tt.epsilon, tt.delta, tt.sensitivity, got, want)
+		}
+	}
+
+	m := Gaussian{Epsilon: 0.5, Delta: 1e-6, Sensitivity: 1}
+	mean, _, variance := sampleMoments(m, 200000)
+	if math.Abs(mean) > 0.05*m.Sigma() || math.Abs(math.Sqrt(variance)/m.Sigma()-1) > 0.02 {
+		t.Errorf("Gaussian sigma=%g samples: mean %g, standard deviation %g", m.Sigma(), mean, math.Sqrt(variance))
+	}
+}
+
+func TestMechanismValidate(t *testing.T) {
+	valid := []Mechanism{
+		Laplace{Epsilon: 2, Sensitivity: 1},
+		Gaussian{Epsilon: 0.5, Delta: 1e-6, Sensitivity: 1},
+	}
+	for _, m := range valid {
+		if err := m.Validate(); err != nil {
+			t.Errorf("%+v: %v", m, err)
+		}
+	}
+
+	invalid := []Mechanism{
+		Laplace{Epsilon: 0, Sensitivity: 1},
+		Laplace{Epsilon: 1, Sensitivity: -1},
+		Gaussian{Epsilon: 1, Delta: 1e-6, Sensitivity: 1}, // outside the classic analysis
+		Gaussian{Epsilon: 0.5, Delta: 0, Sensitivity: 1},
+		Gaussian{Epsilon: 0.5, Delta: 1, Sensitivity: 1},
+		Gaussian{Epsilon: 0.5, Delta: 1e-6, Sensitivity: 0},
+	}
+	for _, m := range invalid {
+		if err := m.Validate(); err == nil {
+			t.Errorf("%+v validated", m)
+		}
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	// A key held by one record (true count 1) passes with probability delta/maxKeys
+	laplace := Laplace{Epsilon: 0.5, Sensitivity: 1}
+	threshold, err := Threshold(laplace, 1, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := math.Exp(-(threshold-1)/laplace.Scale()) / 2; math.Abs(p/1e-6-1) > 1e-9 {
+		t.Errorf("Laplace threshold %g passes a single record with probability %g, want 1e-6", threshold, p)
+	}
+
+	gaussian := Gaussian{Epsilon: 0.5, Delta: 1e-6, Sensitivity: math.Sqrt(5)}
+	threshold, err = Threshold(gaussian, 5, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := math.Erfc((threshold-1)/(gaussian.Sigma()*math.Sqrt2)) / 2; math.Abs(p/2e-7-1) > 1e-6 {
+		t.Errorf("Gaussian threshold %g passes a single record with probability %g, want 2e-7", threshold, p)
+	}
+
+	if _, err := Threshold(laplace, 0, 1e-6); err == nil {
+		t.Error("Threshold with maxKeys 0 succeeded")
+	}
+	if _, err := Threshold(laplace, 1, 0); err == nil {
+		t.Error("Threshold with delta 0 succeeded")
+	}
+}
+
+func sampleMoments(m Mechanism, n int) (mean, meanAbs, variance float64) {
+	var sum, sumAbs, sumSquares float64
+	for i := 0; i < n; i++ {
+		x := m.Noise()
+		sum += x
+		sumAbs += math.Abs(x)
+		sumSquares += x * x
+	}
+	mean = sum / float64(n)
+	return mean, sumAbs / float64(n), sumSquares/float64(n) - mean*mean
+}
@@ -0,0 +1,381 @@
+/*
+# Module: envelope/encoder.go
+Field-level envelope encoder: struct fields tagged envelope:"encrypt" are sealed under a per-record data key.
+
+## Linked Modules
+- [envelope/provider](./provider.go) - KeyProvider interface
+- [types/location](../types/location.go) - Location fields sealed at rest
+- [storage/dynamodb](../storage/dynamodb.go) - Repositories that seal and open records
+
+## Tags
+security, encryption, envelope, reflection
+
+## Exports
+Encoder, NewEncoder, IsSealed, ErrNoKeyProvider, ErrAlreadySealed
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "envelope/encoder.go" ;
+    code:description "Field-level envelope encoder" ;
+    code:linksTo [
+        code:name "envelope/provider" ;
+        code:path "./provider.go" ;
+        code:relationship "KeyProvider interface"
+    ], [
+        code:name "types/location" ;
+        code:path "../types/location.go" ;
+        code:relationship "Location fields sealed at rest"
+    ], [
+        code:name "storage/dynamodb" ;
+        code:path "../storage/dynamodb.go" ;
+        code:relationship "Repositories that seal and open records"
+    ] ;
+    code:exports :Encoder, :NewEncoder, :IsSealed, :ErrNoKeyProvider, :ErrAlreadySealed ;
+    code:tags "security", "encryption", "envelope", "reflection" .
+<!-- End LinkedDoc RDF -->
+*/
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sealedPrefix marks a sealed value; the rest is base64url of a sealedValue
+const sealedPrefix = "env2:"
+
+// maxCachedKeys bounds the cache of unwrapped data keys
+const maxCachedKeys = 1024
+
+var (
+	// ErrNoKeyProvider is returned when opening a sealed record without a key provider.
+	ErrNoKeyProvider = errors.New("record is sealed but no key provider is configured")
+	// ErrAlreadySealed is returned when sealing a record whose sealed field is already set.
+	ErrAlreadySealed = errors.New("record is already sealed")
+)
+
+// sealedValue is what gets stored: the wrapped data key and one ciphertext per field
+type sealedValue struct {
+	Provider string            `json:"p"`
+	KeyID    string            `json:"kid"`
+	Wrapped  []byte            `json:"dk"`
+	Fields   map[string][]byte `json:"f"`
+}
+
+// Encoder seals and opens struct fields annotated with struct tags:
+//
+//	DeviceID string  `json:"device_id" dynamodbav:"device_id" envelope:"key"`
+//	Latitude float64 `json:"latitude" dynamodbav:"latitude" envelope:"encrypt"`
+//	Sealed   string  `json:"-" dynamodbav:"sealed,omitempty" envelope:"sealed"`
+//
+// Seal encrypts every envelope:"encrypt" field under a fresh data key, stores the result
+// in the envelope:"sealed" string field and zeroes the plaintext fields. Open reverses it.
+// The envelope:"key" fields identify the record and are bound to each ciphertext, so a
+// sealed value copied onto another record fails to open.
+// Records whose sealed field is empty were written before encryption was enabled and are
+// left as they are.
+//
+// A nil *Encoder seals nothing, so callers don't need to check whether encryption is on.
+type Encoder struct {
+	provider KeyProvider
+
+	cacheMu sync.Mutex
+	cache   map[string][]byte
+}
+
+// NewEncoder returns an encoder using provider for data keys.
+func NewEncoder(provider KeyProvider) *Encoder {
+	return &Encoder{provider: provider, cache: make(map[string][]byte)}
+}
+
+// IsSealed reports whether s was produced by the encoder.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, sealedPrefix)
+}
+
+// associatedData authenticates a ciphertext as the named field of the identified record
+func associatedData(name, identity string) []byte {
+	return []byte(name + "\x00" + identity)
+}
+
+// recordIdentity encodes the envelope:"key" fields of a record. Times are normalised to
+// UTC so a value read back from storage in another zone still matches.
+func recordIdentity(v reflect.Value, spec *fieldSpec) (string, error) {
+	var b strings.Builder
+	for _, index := range spec.keys {
+		value := v.Field(index).Interface()
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode key field %s: %w", v.Type().Field(index).Name, err)
+		}
+		b.WriteString(v.Type().Field(index).Name)
+		b.WriteByte('=')
+		b.Write(data)
+		b.WriteByte(0)
+	}
+	return b.String(), nil
+}
+
+// Seal encrypts the annotated fields of the struct record points to.
+func (e *Encoder) Seal(ctx context.Context, record interface{}) error {
+	if e == nil {
+		return nil
+	}
+	v, spec, err := inspect(record)
+	if err != nil || len(spec.fields) == 0 {
+		return err
+	}
+	sealedField := v.Field(spec.sealed)
+	if sealedField.String() != "" {
+		return ErrAlreadySealed
+	}
+
+	plaintexts := make(map[string][]byte, len(spec.fields))
+	for name, index := range spec.fields {
+		data, err := json.Marshal(v.Field(index).Interface())
+		if err != nil {
+			return fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		plaintexts[name] = data
+	}
+	identity, err := recordIdentity(v, spec)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := e.seal(ctx, plaintexts, identity)
+	if err != nil {
+		return err
+	}
+	for _, index := range spec.fields {
+		field := v.Field(index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	sealedField.SetString(sealed)
+	return nil
+}
+
+// Open decrypts the annotated fields of the struct record points to and clears its sealed field.
+func (e *Encoder) Open(ctx context.Context, record interface{}) error {
+	v, spec, err := inspect(record)
+	if err != nil || len(spec.fields) == 0 {
+		return err
+	}
+	sealedField := v.Field(spec.sealed)
+	if sealedField.String() == "" {
+		return nil
+	}
+	if e == nil {
+		return ErrNoKeyProvider
+	}
+	identity, err := recordIdentity(v, spec)
+	if err != nil {
+		return err
+	}
+
+	plaintexts, err := e.open(ctx, sealedField.String(), identity)
+	if err != nil {
+		return err
+	}
+	for name, data := range plaintexts {
+		index, ok := spec.fields[name]
+		if !ok {
+			return fmt.Errorf("sealed field %s is not annotated on %s", name, v.Type())
+		}
+		if err := json.Unmarshal(data, v.Field(index).Addr().Interface()); err != nil {
+			return fmt.Errorf("failed to decode field %s: %w", name, err)
+		}
+	}
+	sealedField.SetString("")
+	return nil
+}
+
+// SealBytes seals a single value outside a struct. label is bound to the ciphertext
+// and must be given again to open it.
+func (e *Encoder) SealBytes(ctx context.Context, plaintext []byte, label string) (string, error) {
+	if e == nil {
+		return "", ErrNoKeyProvider
+	}
+	return e.seal(ctx, map[string][]byte{label: plaintext}, "")
+}
+
+// OpenBytes opens a value sealed by SealBytes with the same label.
+func (e *Encoder) OpenBytes(ctx context.Context, sealed string, label string) ([]byte, error) {
+	if e == nil {
+		return nil, ErrNoKeyProvider
+	}
+	plaintexts, err := e.open(ctx, sealed, "")
+	if err != nil {
+		return nil, err
+	}
+	plaintext, ok := plaintexts[label]
+	if !ok {
+		return nil, fmt.Errorf("sealed value has no %q part", label)
+	}
+	return plaintext, nil
+}
+
+func (e *Encoder) seal(ctx context.Context, plaintexts map[string][]byte, identity string) (string, error) {
+	key, err := e.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key.Plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	value := sealedValue{
+		Provider: e.provider.Name(),
+		KeyID:    key.KeyID,
+		Wrapped:  key.Wrapped,
+		Fields:   make(map[string][]byte, len(plaintexts)),
+	}
+	for name, plaintext := range plaintexts {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		value.Fields[name] = gcm.Seal(nonce, nonce, plaintext, associatedData(name, identity))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (e *Encoder) open(ctx context.Context, sealed string, identity string) (map[string][]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, fmt.Errorf("not a sealed value")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed sealed value: %w", err)
+	}
+	var value sealedValue
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("malformed sealed value: %w", err)
+	}
+	if value.Provider != e.provider.Name() {
+		return nil, fmt.Errorf("value was sealed by the %q key provider, configured provider is %q", value.Provider, e.provider.Name())
+	}
+
+	key, err := e.dataKey(ctx, value.KeyID, value.Wrapped)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintexts := make(map[string][]byte, len(value.Fields))
+	for name, ciphertext := range value.Fields {
+		if len(ciphertext) < gcm.NonceSize() {
+			return nil, fmt.Errorf("sealed field %s is too short", name)
+		}
+		nonce, body := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+		plaintext, err := gcm.Open(nil, nonce, body, associatedData(name, identity))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt field %s: %w", name, err)
+		}
+		plaintexts[name] = plaintext
+	}
+	return plaintexts, nil
+}
+
+// dataKey unwraps a data key, remembering recent ones so repeated reads of the same
+// records (scans) don't go back to the provider each time
+func (e *Encoder) dataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + "|" + string(wrapped)
+
+	e.cacheMu.Lock()
+	key, ok := e.cache[cacheKey]
+	e.cacheMu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := e.provider.UnwrapDataKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cacheMu.Lock()
+	if len(e.cache) >= maxCachedKeys {
+		e.cache = make(map[string][]byte)
+	}
+	e.cache[cacheKey] = key
+	e.cacheMu.Unlock()
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// fieldSpec lists a struct type's annotated fields by Go field name, and its key
+// fields in declaration order
+type fieldSpec struct {
+	fields map[string]int
+	keys   []int
+	sealed int
+}
+
+var fieldSpecs sync.Map // reflect.Type -> *fieldSpec
+
+// inspect checks record is a pointer to a struct and returns its annotated fields
+func inspect(record interface{}) (reflect.Value, *fieldSpec, error) {
+	v := reflect.ValueOf(record)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("envelope: expected a pointer to a struct, got %T", record)
+	}
+	v = v.Elem()
+
+	if cached, ok := fieldSpecs.Load(v.Type()); ok {
+		return v, cached.(*fieldSpec), nil
+	}
+
+	spec := &fieldSpec{fields: make(map[string]int), sealed: -1}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		switch field.Tag.Get("envelope") {
+		case "encrypt":
+			spec.fields[field.Name] = i
+		case "key":
+			spec.keys = append(spec.keys, i)
+		case "sealed":
+			if field.Type.Kind() != reflect.String {
+				return reflect.Value{}, nil, fmt.Errorf("envelope: sealed field %s.%s must be a string", t, field.Name)
+			}
+			spec.sealed = i
+		}
+	}
+	if len(spec.fields) > 0 && spec.sealed < 0 {
+		return reflect.Value{}, nil, fmt.Errorf("envelope: %s has encrypted fields but no envelope:\"sealed\" field", t)
+	}
+
+	fieldSpecs.Store(t, spec)
+	return v, spec, nil
+}
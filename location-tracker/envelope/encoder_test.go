@@ -0,0 +1,205 @@
+package envelope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecord has the same shape as the sealed records in types
+type testRecord struct {
+	ID        string    `envelope:"key"`
+	Timestamp time.Time `envelope:"key"`
+	Latitude  float64   `envelope:"encrypt"`
+	Note      string    `envelope:"encrypt"`
+	Public    string
+	Sealed    string `envelope:"sealed"`
+}
+
+func newTestEncoder(t *testing.T) *Encoder {
+	t.Helper()
+	keyring, err := NewLocalKeyring("k1", map[string][]byte{"k1": make([]byte, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEncoder(keyring)
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	encoder := newTestEncoder(t)
+	ctx := context.Background()
+	at := time.Date(2025, 11, 12, 9, 30, 0, 123456789, time.UTC)
+	record := testRecord{ID: "device-1", Timestamp: at, Latitude: 38.8977, Note: "north gate", Public: "shown"}
+
+	if err := encoder.Seal(ctx, &record); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(record.Sealed) {
+		t.Fatalf("Sealed = %q, want a sealed value", record.Sealed)
+	}
+	if record.Latitude != 0 || record.Note != "" {
+		t.Errorf("plaintext left after Seal: %+v", record)
+	}
+	if record.Public != "shown" || record.ID != "device-1" {
+		t.Errorf("unannotated fields changed: %+v", record)
+	}
+	if err := encoder.Seal(ctx, &record); err != ErrAlreadySealed {
+		t.Errorf("sealing twice = %v, want ErrAlreadySealed", err)
+	}
+
+	// Storage may hand the timestamp back in another zone
+	record.Timestamp = at.In(time.FixedZone("UTC+2", 2*60*60))
+	if err := encoder.Open(ctx, &record); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if record.Latitude != 38.8977 || record.Note != "north gate" || record.Sealed != "" {
+		t.Errorf("opened record = %+v", record)
+	}
+
+	unsealed := testRecord{ID: "device-2", Latitude: 1}
+	if err := encoder.Open(ctx, &unsealed); err != nil || unsealed.Latitude != 1 {
+		t.Errorf("opening a record written before encryption = %v, %+v", err, unsealed)
+	}
+
+	var none *Encoder
+	plain := testRecord{ID: "device-3", Latitude: 2}
+	if err := none.Seal(ctx, &plain); err != nil || plain.Sealed != "" || plain.Latitude != 2 {
+		t.Errorf("nil encoder Seal = %v, %+v", err, plain)
+	}
+	record.Sealed = "env2:x"
+	if err := none.Open(ctx, &record); err != ErrNoKeyProvider {
+		t.Errorf("nil encoder Open = %v, want ErrNoKeyProvider", err)
+	}
+}
+
+func TestSealedValueBoundToRecord(t *testing.T) {
+	encoder := newTestEncoder(t)
+	ctx := context.Background()
+	at := time.Date(2025, 11, 12, 9, 30, 0, 0, time.UTC)
+	original := testRecord{ID: "device-1", Timestamp: at, Latitude: 38.8977, Note: "north gate"}
+	if err := encoder.Seal(ctx, &original); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		record testRecord
+	}{
+		{"other id", testRecord{ID: "device-2", Timestamp: at}},
+		{"other timestamp", testRecord{ID: "device-1", Timestamp: at.Add(time.Millisecond)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved := tt.record
+			moved.Sealed = original.Sealed
+			if err := encoder.Open(ctx, &moved); err == nil {
+				t.Fatalf("a value sealed for %s/%s opened on %s/%s", original.ID, at, moved.ID, moved.Timestamp)
+			}
+			if moved.Latitude != 0 || moved.Note != "" {
+				t.Errorf("plaintext leaked on failure: %+v", moved)
+			}
+		})
+	}
+
+	// Swapping two fields' ciphertexts within the record
+	t.Run("other field", func(t *testing.T) {
+		value := decodeSealed(t, original.Sealed)
+		value.Fields["Latitude"], value.Fields["Note"] = value.Fields["Note"], value.Fields["Latitude"]
+		swapped := original
+		swapped.Sealed = encodeSealed(t, value)
+		if err := encoder.Open(ctx, &swapped); err == nil {
+			t.Fatal("swapped field ciphertexts opened")
+		}
+	})
+
+	t.Run("bytes label", func(t *testing.T) {
+		sealed, err := encoder.SealBytes(ctx, []byte("metadata"), "user_metadata")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := encoder.OpenBytes(ctx, sealed, "health"); err == nil {
+			t.Fatal("opened with another label")
+		}
+		value := decodeSealed(t, sealed)
+		value.Fields["health"] = value.Fields["user_metadata"]
+		delete(value.Fields, "user_metadata")
+		if _, err := encoder.OpenBytes(ctx, encodeSealed(t, value), "health"); err == nil {
+			t.Fatal("a part renamed to another label opened")
+		}
+		plaintext, err := encoder.OpenBytes(ctx, sealed, "user_metadata")
+		if err != nil || string(plaintext) != "metadata" {
+			t.Fatalf("OpenBytes = %q, %v", plaintext, err)
+		}
+	})
+}
+
+func TestTamperedValueFails(t *testing.T) {
+	encoder := newTestEncoder(t)
+	ctx := context.Background()
+	record := testRecord{ID: "device-1", Latitude: 38.8977, Note: "north gate"}
+	if err := encoder.Seal(ctx, &record); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(*sealedValue)
+	}{
+		{"ciphertext bit", func(v *sealedValue) { v.Fields["Note"][len(v.Fields["Note"])-1] ^= 1 }},
+		{"nonce bit", func(v *sealedValue) { v.Fields["Latitude"][0] ^= 1 }},
+		{"truncated", func(v *sealedValue) { v.Fields["Note"] = v.Fields["Note"][:4] }},
+		{"wrapped key", func(v *sealedValue) { v.Wrapped[len(v.Wrapped)-1] ^= 1 }},
+		{"other provider", func(v *sealedValue) { v.Provider = "kms" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := decodeSealed(t, record.Sealed)
+			tt.tamper(&value)
+			tampered := record
+			tampered.Sealed = encodeSealed(t, value)
+			if err := encoder.Open(ctx, &tampered); err == nil {
+				t.Fatal("tampered value opened")
+			}
+		})
+	}
+
+	values := []string{
+		"env2:",
+		"env2:!!!",
+		"env2:" + base64.RawURLEncoding.EncodeToString([]byte("{")),
+		"plain",
+		"env1:" + strings.TrimPrefix(record.Sealed, sealedPrefix), // the format without record identity
+	}
+	for _, malformed := range values {
+		bad := record
+		bad.Sealed = malformed
+		if err := encoder.Open(ctx, &bad); err == nil {
+			t.Errorf("malformed value %q opened", malformed)
+		}
+	}
+}
+
+func decodeSealed(t *testing.T, sealed string) sealedValue {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value sealedValue
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func encodeSealed(t *testing.T, value sealedValue) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(data)
+}
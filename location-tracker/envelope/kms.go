@@ -0,0 +1,87 @@
+/*
+# Module: envelope/kms.go
+AWS KMS key provider: data keys generated and unwrapped by a KMS key that never leaves KMS.
+
+## Linked Modules
+- [envelope/provider](./provider.go) - KeyProvider interface
+
+## Tags
+security, encryption, envelope, kms, aws
+
+## Exports
+KMSProvider, NewKMSProvider
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "envelope/kms.go" ;
+    code:description "AWS KMS key provider" ;
+    code:linksTo [
+        code:name "envelope/provider" ;
+        code:path "./provider.go" ;
+        code:relationship "KeyProvider interface"
+    ] ;
+    code:exports :KMSProvider, :NewKMSProvider ;
+    code:tags "security", "encryption", "envelope", "kms", "aws" .
+<!-- End LinkedDoc RDF -->
+*/
+package envelope
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KMSProvider generates data keys with AWS KMS GenerateDataKey and unwraps them with
+// Decrypt. Every call is bound to an encryption context, so wrapped keys from other
+// applications sharing the KMS key are refused.
+type KMSProvider struct {
+	client            *kms.Client
+	keyID             string
+	encryptionContext map[string]string
+}
+
+// NewKMSProvider returns a provider for the KMS key keyID (an ID, ARN or alias).
+func NewKMSProvider(client *kms.Client, keyID string) *KMSProvider {
+	return &KMSProvider{
+		client:            client,
+		keyID:             keyID,
+		encryptionContext: map[string]string{"application": "location-tracker"},
+	}
+}
+
+// Name identifies the provider in sealed records.
+func (p *KMSProvider) Name() string {
+	return "kms"
+}
+
+// GenerateDataKey asks KMS for a new AES-256 data key.
+func (p *KMSProvider) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	out, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(p.keyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: p.encryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS GenerateDataKey failed: %w", err)
+	}
+	return &DataKey{Plaintext: out.Plaintext, Wrapped: out.CiphertextBlob, KeyID: aws.ToString(out.KeyId)}, nil
+}
+
+// UnwrapDataKey asks KMS to decrypt a wrapped data key. keyID is the key ARN recorded
+// when the data key was generated, so records stay readable after the alias moves.
+func (p *KMSProvider) UnwrapDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	out, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    wrapped,
+		KeyId:             aws.String(keyID),
+		EncryptionContext: p.encryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS Decrypt failed: %w", err)
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,199 @@
+/*
+# Module: envelope/local.go
+Local file keyring: AES-256 key encryption keys kept in a JSON file, with rotation.
+
+## Linked Modules
+- [envelope/provider](./provider.go) - KeyProvider interface
+
+## Tags
+security, encryption, envelope, keyring
+
+## Exports
+LocalKeyring, NewLocalKeyring, LoadLocalKeyring, RotateLocalKeyring
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "envelope/local.go" ;
+    code:description "Local file keyring with rotation" ;
+    code:linksTo [
+        code:name "envelope/provider" ;
+        code:path "./provider.go" ;
+        code:relationship "KeyProvider interface"
+    ] ;
+    code:exports :LocalKeyring, :NewLocalKeyring, :LoadLocalKeyring, :RotateLocalKeyring ;
+    code:tags "security", "encryption", "envelope", "keyring" .
+<!-- End LinkedDoc RDF -->
+*/
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keyringFile is the on-disk format:
+//
+//	{"primary": "k20261018T120000", "keys": {"k20261018T120000": "<base64 32 bytes>"}}
+//
+// New data keys are wrapped with the primary key; older keys stay to unwrap old records.
+type keyringFile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LocalKeyring wraps data keys with AES-256-GCM key encryption keys held in memory.
+type LocalKeyring struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyring returns a keyring that wraps new data keys with keys[primary].
+func NewLocalKeyring(primary string, keys map[string][]byte) (*LocalKeyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %d", id, len(key))
+		}
+	}
+	return &LocalKeyring{primary: primary, keys: keys}, nil
+}
+
+// LoadLocalKeyring reads a keyring file.
+func LoadLocalKeyring(path string) (*LocalKeyring, error) {
+	file, err := readKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("keyring %s: key %q is not base64", path, id)
+		}
+		keys[id] = key
+	}
+	return NewLocalKeyring(file.Primary, keys)
+}
+
+func readKeyringFile(path string) (*keyringFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("keyring %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// RotateLocalKeyring adds a new random key to the keyring file (creating it if needed)
+// and makes it primary. Existing keys are kept, so records sealed under them still open.
+// It returns the new key's ID.
+func RotateLocalKeyring(path string) (string, error) {
+	file, err := readKeyringFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		file, err = &keyringFile{Keys: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	id := "k" + time.Now().UTC().Format("20060102T150405")
+	if _, taken := file.Keys[id]; taken {
+		return "", fmt.Errorf("key %q already exists; rotate again in a second", id)
+	}
+	file.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	file.Primary = id
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keyring-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return id, os.Rename(tmp.Name(), path)
+}
+
+// Name identifies the provider in sealed records.
+func (k *LocalKeyring) Name() string {
+	return "local"
+}
+
+// GenerateDataKey returns a random data key wrapped with the primary key.
+func (k *LocalKeyring) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+	gcm, err := k.cipher(k.primary)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	wrapped := gcm.Seal(nonce, nonce, plaintext, []byte("data-key:"+k.primary))
+	return &DataKey{Plaintext: plaintext, Wrapped: wrapped, KeyID: k.primary}, nil
+}
+
+// UnwrapDataKey decrypts a data key wrapped by keyID.
+func (k *LocalKeyring) UnwrapDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	gcm, err := k.cipher(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key too short")
+	}
+	nonce, ciphertext := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte("data-key:"+keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return plaintext, nil
+}
+
+func (k *LocalKeyring) cipher(keyID string) (cipher.AEAD, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,64 @@
+/*
+# Module: envelope/provider.go
+Key providers for envelope encryption: data keys generated per record, wrapped by a key encryption key.
+
+## Linked Modules
+- [envelope/local](./local.go) - File keyring provider
+- [envelope/kms](./kms.go) - AWS KMS provider
+- [envelope/encoder](./encoder.go) - Field-level encoder using the providers
+
+## Tags
+security, encryption, envelope, kms, keyring
+
+## Exports
+DataKey, KeyProvider, ErrUnknownKey
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "envelope/provider.go" ;
+    code:description "Key providers for envelope encryption" ;
+    code:linksTo [
+        code:name "envelope/local" ;
+        code:path "./local.go" ;
+        code:relationship "File keyring provider"
+    ], [
+        code:name "envelope/kms" ;
+        code:path "./kms.go" ;
+        code:relationship "AWS KMS provider"
+    ], [
+        code:name "envelope/encoder" ;
+        code:path "./encoder.go" ;
+        code:relationship "Field-level encoder using the providers"
+    ] ;
+    code:exports :DataKey, :KeyProvider, :ErrUnknownKey ;
+    code:tags "security", "encryption", "envelope", "kms", "keyring" .
+<!-- End LinkedDoc RDF -->
+*/
+package envelope
+
+import (
+	"context"
+	"errors"
+)
+
+// DataKey is a fresh 256-bit key for one record. Plaintext encrypts the record and is
+// never stored; Wrapped is Plaintext encrypted under the provider's key KeyID, and is
+// stored next to the record.
+type DataKey struct {
+	Plaintext []byte
+	Wrapped   []byte
+	KeyID     string
+}
+
+// KeyProvider creates and unwraps data keys. Key encryption keys stay with the
+// provider: in a keyring file, or inside AWS KMS.
+type KeyProvider interface {
+	// Name identifies the provider in sealed records, e.g. "local" or "kms"
+	Name() string
+	GenerateDataKey(ctx context.Context) (*DataKey, error)
+	UnwrapDataKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// ErrUnknownKey is returned when a record was wrapped with a key the provider doesn't have.
+var ErrUnknownKey = errors.New("unknown key encryption key")
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"location-tracker/sanitize"
+	"location-tracker/types"
+)
+
+const (
+	epubMaxChapters  = 500
+	epubCoverWidth   = 1600
+	epubCoverHeight  = 2560
+	epubMediaWorkers = 4
+	epubDefaultTitle = "The Agency Casebook"
+)
+
+// errNoCasesToExport is returned when a casebook selection is empty
+var errNoCasesToExport = errors.New("no cases to export")
+
+// epubMediaTypes are the image core media types EPUB readers must support
+var epubMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Casebook is an EPUB anthology of cases, one chapter per case
+type Casebook struct {
+	Identifier string
+	Title      string
+	Subtitle   string
+	Modified   string
+	Chapters   []casebookChapter
+	Images     []*casebookImage
+}
+
+// casebookChapter is one case rendered as an XHTML chapter
+type casebookChapter struct {
+	FileName     string
+	Title        string
+	Dateline     string
+	Slogan       string
+	Story        string
+	SatiricalFix string
+	Meme         *casebookImage
+	Food         *casebookImage
+	FoodAttr     string
+}
+
+// casebookImage is an image embedded in the book
+type casebookImage struct {
+	ID        string
+	Href      string
+	MediaType string
+	Cover     bool
+	data      []byte
+}
+
+// buildCasebook collects chapters and embedded media for cases (oldest first, like a book)
+func buildCasebook(ctx context.Context, cases []types.ErrorLog, title, subtitle string) (*Casebook, error) {
+	if len(cases) == 0 {
+		return nil, errNoCasesToExport
+	}
+	if len(cases) > epubMaxChapters {
+		cases = cases[:epubMaxChapters]
+	}
+
+	ordered := make([]types.ErrorLog, len(cases))
+	for i := range cases {
+		ordered[len(cases)-1-i] = cases[i]
+	}
+
+	// Stable identifier: the same selection of cases yields the same book ID
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "%s|%s", title, subtitle)
+	for _, errorLog := range ordered {
+		fmt.Fprintf(hasher, "|%s", errorLog.ID)
+	}
+	sum := hasher.Sum(nil)
+	book := &Casebook{
+		Identifier: fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16]),
+		Title:      title,
+		Subtitle:   subtitle,
+		Modified:   time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+
+	media := loadCasebookMedia(ctx, ordered)
+	for i, errorLog := range ordered {
+		chapter := casebookChapter{
+			FileName:     fmt.Sprintf("case-%04d.xhtml", i+1),
+			Title:        errorLog.Message,
+			Dateline:     fmt.Sprintf("Case #%s · %s", errorLog.ID, errorLog.Timestamp.Format("January 2, 2006")),
+			Slogan:       errorLog.Slogan,
+			Story:        sanitize.StoryXHTML(errorLog.ChildrensStory),
+			SatiricalFix: sanitize.PlainText(errorLog.SatiricalFix),
+			Meme:         media[errorLog.MemeURL],
+			Food:         media[errorLog.FoodImageURL],
+			FoodAttr:     sanitize.PlainText(errorLog.FoodImageAttr),
+		}
+		if chapter.Title == "" {
+			chapter.Title = fmt.Sprintf("Case #%s", errorLog.ID)
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+
+	seen := make(map[*casebookImage]bool)
+	for _, chapter := range book.Chapters {
+		for _, img := range []*casebookImage{chapter.Meme, chapter.Food} {
+			if img != nil && !seen[img] {
+				seen[img] = true
+				book.Images = append(book.Images, img)
+			}
+		}
+	}
+
+	cover, err := renderCasebookCover(title, subtitle, len(book.Chapters))
+	if err != nil {
+		return nil, err
+	}
+	book.Images = append(book.Images, &casebookImage{ID: "cover-image", Href: "images/cover.png", MediaType: "image/png", Cover: true, data: cover})
+	return book, nil
+}
+
+// loadCasebookMedia fetches meme and food images through the blob store media cache.
+// Missing or unsupported images are left out rather than failing the export.
+func loadCasebookMedia(ctx context.Context, cases []types.ErrorLog) map[string]*casebookImage {
+	urls := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, errorLog := range cases {
+		for _, mediaURL := range []string{errorLog.MemeURL, errorLog.FoodImageURL} {
+			if mediaURL != "" && !seen[mediaURL] {
+				seen[mediaURL] = true
+				urls = append(urls, mediaURL)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	media := make(map[string]*casebookImage)
+	jobs := make(chan int)
+	for w := 0; w < epubMediaWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, contentType, err := fetchCachedMedia(ctx, urls[i])
+				if err != nil {
+					log.Printf("⚠️  Casebook image skipped (%s): %v", urls[i], err)
+					continue
+				}
+				ext, ok := epubMediaTypes[contentType]
+				if !ok {
+					log.Printf("⚠️  Casebook image skipped (%s): unsupported type %s", urls[i], contentType)
+					continue
+				}
+				id := fmt.Sprintf("img-%04d", i+1)
+				mu.Lock()
+				media[urls[i]] = &casebookImage{ID: id, Href: "images/" + id + ext, MediaType: contentType, data: data}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return media
+}
+
+// renderCasebookCover draws the cover in the share-image style
+func renderCasebookCover(title, subtitle string, chapterCount int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, epubCoverWidth, epubCoverHeight))
+	drawGradientBackground(img)
+
+	margin := 120
+	width := epubCoverWidth - 2*margin
+	y := 500
+	y = drawTextBoxSized(img, "CLASSIFIED", margin, y, width, 120, color.RGBA{255, 120, 120, 255}, 72)
+	y = drawTextBoxSized(img, title, margin, y+40, width, 600, color.RGBA{255, 255, 255, 255}, 110)
+	if subtitle != "" {
+		drawTextBoxSized(img, subtitle, margin, y+40, width, 300, color.RGBA{255, 255, 180, 255}, 60)
+	}
+	drawTextBoxSized(img, fmt.Sprintf("%d case files · notspies.org", chapterCount), margin, epubCoverHeight-300, width, 100, color.RGBA{200, 200, 200, 255}, 48)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode cover: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteEPUB writes the book as an EPUB 3 container
+func (b *Casebook) WriteEPUB(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write mimetype: %w", err)
+	}
+	mimetype.Write([]byte("application/epub+zip"))
+
+	files := []struct {
+		name     string
+		template *template.Template
+		data     interface{}
+	}{
+		{"META-INF/container.xml", epubContainerTemplate, nil},
+		{"OEBPS/content.opf", epubPackageTemplate, b},
+		{"OEBPS/nav.xhtml", epubNavTemplate, b},
+		{"OEBPS/toc.ncx", epubNCXTemplate, b},
+		{"OEBPS/cover.xhtml", epubCoverTemplate, b},
+	}
+	for _, file := range files {
+		if err := writeEPUBTemplate(archive, file.name, file.template, file.data); err != nil {
+			return err
+		}
+	}
+	for _, chapter := range b.Chapters {
+		if err := writeEPUBTemplate(archive, "OEBPS/text/"+chapter.FileName, epubChapterTemplate, chapter); err != nil {
+			return err
+		}
+	}
+
+	if err := writeEPUBFile(archive, "OEBPS/style.css", []byte(epubStylesheet)); err != nil {
+		return err
+	}
+	for _, img := range b.Images {
+		if err := writeEPUBFile(archive, "OEBPS/"+img.Href, img.data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish EPUB: %w", err)
+	}
+	return nil
+}
+
+func writeEPUBTemplate(archive *zip.Writer, name string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return writeEPUBFile(archive, name, buf.Bytes())
+}
+
+func writeEPUBFile(archive *zip.Writer, name string, data []byte) error {
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// exportCasebook selects cases with filter and renders the EPUB
+func exportCasebook(ctx context.Context, filter CaseFilter, title string) ([]byte, error) {
+	cases := filterCases(loadCaseArchive(), filter, epubMaxChapters)
+	if title == "" {
+		title = epubDefaultTitle
+	}
+	book, err := buildCasebook(ctx, cases, title, casebookSubtitle(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := book.WriteEPUB(&buf); err != nil {
+		return nil, err
+	}
+	log.Printf("📚 Exported casebook with %d chapters (%d bytes)", len(book.Chapters), buf.Len())
+	return buf.Bytes(), nil
+}
+
+// casebookSubtitle describes the selection, e.g. "Cases mentioning \"tax\", Jan 2, 2025 – Feb 1, 2025"
+func casebookSubtitle(filter CaseFilter) string {
+	parts := make([]string, 0, 3)
+	if filter.Keyword != "" {
+		parts = append(parts, fmt.Sprintf("Cases mentioning %q", filter.Keyword))
+	}
+	if filter.Business != "" {
+		parts = append(parts, "Cases at "+filter.Business)
+	}
+	switch {
+	case !filter.Since.IsZero() && !filter.Until.IsZero():
+		parts = append(parts, fmt.Sprintf("%s – %s", filter.Since.Format("Jan 2, 2006"), filter.Until.Add(-time.Second).Format("Jan 2, 2006")))
+	case !filter.Since.IsZero():
+		parts = append(parts, "Since "+filter.Since.Format("Jan 2, 2006"))
+	case !filter.Until.IsZero():
+		parts = append(parts, "Through "+filter.Until.Add(-time.Second).Format("Jan 2, 2006"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleCasebookExport serves GET /api/export/casebook.epub?since=&until=&keyword=&business=&title=
+// The book contains puzzle-level data only, so puzzle access is enough.
+func handleCasebookExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !hasPuzzleAccess(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseCaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	data, err := exportCasebook(ctx, filter, r.URL.Query().Get("title"))
+	if errors.Is(err, errNoCasesToExport) {
+		http.Error(w, "No cases match the selection", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to export casebook: %v", err)
+		http.Error(w, "Failed to export casebook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="casebook.epub"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(data)
+}
+
+// runExportEPUBCommand implements `location-tracker export-epub [flags]`
+func runExportEPUBCommand(args []string) int {
+	flags := flag.NewFlagSet("export-epub", flag.ContinueOnError)
+	since := flags.String("since", "", "First day to include (YYYY-MM-DD or RFC 3339)")
+	until := flags.String("until", "", "Last day to include (YYYY-MM-DD or RFC 3339)")
+	keyword := flags.String("keyword", "", "Only cases mentioning this keyword")
+	business := flags.String("business", "", "Only cases naming this business")
+	title := flags.String("title", epubDefaultTitle, "Book title")
+	out := flags.String("out", "casebook.epub", "Output file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	filter, err := parseCaseFilter(map[string][]string{
+		"since":    {*since},
+		"until":    {*until},
+		"keyword":  {*keyword},
+		"business": {*business},
+	})
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 2
+	}
+
+	initializeDynamoDB()
+	initializeShareImages()
+
+	data, err := exportCasebook(context.Background(), filter, *title)
+	if err != nil {
+		log.Printf("❌ Failed to export casebook: %v", err)
+		return 1
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Printf("❌ Failed to write %s: %v", *out, err)
+		return 1
+	}
+	log.Printf("✅ Wrote %s", *out)
+	return 0
+}
+
+// xmlEscape escapes text for XML content and attribute values
+func xmlEscape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+var epubFuncs = template.FuncMap{
+	"x":   xmlEscape,
+	"inc": func(i int) int { return i + 1 },
+}
+
+var epubContainerTemplate = template.Must(template.New("container").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`))
+
+var epubPackageTemplate = template.Must(template.New("opf").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="en">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.Identifier}}</dc:identifier>
+    <dc:title>{{x .Title}}</dc:title>
+    <dc:language>en</dc:language>
+    <dc:creator>The Agency</dc:creator>
+    <dc:publisher>notspies.org</dc:publisher>
+    {{- if .Subtitle}}
+    <dc:description>{{x .Subtitle}}</dc:description>
+    {{- end}}
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+    <meta name="cover" content="cover-image"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    {{- range $i, $chapter := .Chapters}}
+    <item id="chapter-{{$i}}" href="text/{{$chapter.FileName}}" media-type="application/xhtml+xml"/>
+    {{- end}}
+    {{- range .Images}}
+    <item id="{{.ID}}" href="{{.Href}}" media-type="{{.MediaType}}"{{if .Cover}} properties="cover-image"{{end}}/>
+    {{- end}}
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="cover" linear="yes"/>
+    <itemref idref="nav"/>
+    {{- range $i, $chapter := .Chapters}}
+    <itemref idref="chapter-{{$i}}"/>
+    {{- end}}
+  </spine>
+</package>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="en" lang="en">
+<head>
+  <meta charset="UTF-8"/>
+  <title>Contents</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Case Files</h1>
+    <ol>
+      {{- range .Chapters}}
+      <li><a href="text/{{.FileName}}">{{x .Title}}</a></li>
+      {{- end}}
+    </ol>
+  </nav>
+  <nav epub:type="landmarks" hidden="hidden">
+    <ol>
+      <li><a epub:type="cover" href="cover.xhtml">Cover</a></li>
+      <li><a epub:type="toc" href="#toc">Contents</a></li>
+      {{- with index .Chapters 0}}
+      <li><a epub:type="bodymatter" href="text/{{.FileName}}">First case</a></li>
+      {{- end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var epubNCXTemplate = template.Must(template.New("ncx").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{.Identifier}}"/>
+  </head>
+  <docTitle><text>{{x .Title}}</text></docTitle>
+  <navMap>
+    {{- range $i, $chapter := .Chapters}}
+    <navPoint id="nav-{{$i}}" playOrder="{{inc $i}}">
+      <navLabel><text>{{x $chapter.Title}}</text></navLabel>
+      <content src="text/{{$chapter.FileName}}"/>
+    </navPoint>
+    {{- end}}
+  </navMap>
+</ncx>
+`))
+
+var epubCoverTemplate = template.Must(template.New("cover").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="en" lang="en">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{x .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body class="cover">
+  <section epub:type="cover">
+    <img src="images/cover.png" alt="{{x .Title}}"/>
+  </section>
+</body>
+</html>
+`))
+
+var epubChapterTemplate = template.Must(template.New("chapter").Funcs(epubFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="en" lang="en">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{x .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="../style.css"/>
+</head>
+<body>
+  <section epub:type="chapter">
+    <h1>{{x .Title}}</h1>
+    <p class="dateline">{{x .Dateline}}</p>
+    {{- if .Slogan}}
+    <blockquote class="epigraph" epub:type="epigraph"><p>{{x .Slogan}}</p></blockquote>
+    {{- end}}
+    {{- with .Meme}}
+    <figure class="meme"><img src="../{{.Href}}" alt="Case illustration"/></figure>
+    {{- end}}
+    <div class="story">
+{{.Story}}
+    </div>
+    {{- if .SatiricalFix}}
+    <p class="fix"><em>Proposed fix:</em> {{x .SatiricalFix}}</p>
+    {{- end}}
+    {{- if .Food}}
+    <figure class="food">
+      <img src="../{{.Food.Href}}" alt="Evidence: lunch"/>
+      {{- if .FoodAttr}}
+      <figcaption>{{x .FoodAttr}}</figcaption>
+      {{- end}}
+    </figure>
+    {{- end}}
+  </section>
+</body>
+</html>
+`))
+
+const epubStylesheet = `body { font-family: Georgia, serif; line-height: 1.5; margin: 0 5%; }
+h1 { font-family: Helvetica, Arial, sans-serif; font-size: 1.4em; margin-top: 2em; }
+.dateline { color: #666; font-size: 0.85em; text-transform: uppercase; letter-spacing: 0.05em; }
+.epigraph { font-style: italic; margin: 1.5em 10% 1.5em 20%; text-align: right; }
+figure { margin: 1.5em 0; text-align: center; }
+figure img { max-width: 100%; max-height: 60vh; }
+figcaption { color: #666; font-size: 0.8em; }
+.fix { border-top: 1px solid #ccc; padding-top: 0.5em; font-size: 0.9em; }
+.cover { margin: 0; padding: 0; text-align: center; }
+.cover img { max-width: 100%; max-height: 100vh; }
+nav ol { list-style: none; padding-left: 0; }
+`
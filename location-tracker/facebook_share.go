@@ -12,7 +12,7 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -35,51 +35,104 @@ const (
 
 // FacebookShareResponse represents the response for a Facebook share request
 type FacebookShareResponse struct {
-	ImageURL    string `json:"image_url"`
-	ShareURL    string `json:"share_url"`
-	Caption     string `json:"caption"`
-	DirectShare string `json:"direct_share_url"`
+	ImageURL    string            `json:"image_url"`
+	AnimatedURL string            `json:"animated_url"` // Animated GIF compilation (OpenGraph size)
+	Images      map[string]string `json:"images"`       // Image URL per share format (og, story, square)
+	ShareURL    string            `json:"share_url"`
+	Caption     string            `json:"caption"`
+	DirectShare string            `json:"direct_share_url"`
 }
 
 // generateFacebookShareImage creates a compilation image from an error log
 func generateFacebookShareImage(errorLog *types.ErrorLog) ([]byte, error) {
-	log.Printf("🎨 Starting image generation for error: %s", errorLog.ID)
+	return renderShareImage(errorLog, shareFormatOG)
+}
+
+// renderShareImage renders the compilation layout at the dimensions of the given format
+func renderShareImage(errorLog *types.ErrorLog, format ShareImageFormat) ([]byte, error) {
+	log.Printf("🎨 Starting %s image generation for error: %s", format.Name, errorLog.ID)
 
 	// Create base canvas
-	img := image.NewRGBA(image.Rect(0, 0, fbShareWidth, fbShareHeight))
+	img := image.NewRGBA(image.Rect(0, 0, format.Width, format.Height))
+	imageArea := drawShareChrome(img, errorLog, format)
+
+	// Collect images to display
+	imagesToComposite := collectShareImages(errorLog)
+	log.Printf("🖼️  Total images collected: %d", len(imagesToComposite))
+
+	// Composite images into the canvas
+	if len(imagesToComposite) > 0 {
+		log.Printf("🎨 Compositing %d images", len(imagesToComposite))
+		compositeImages(img, imagesToComposite, imageArea.Min.X, imageArea.Min.Y, imageArea.Dx(), imageArea.Dy())
+	} else {
+		// No images available, add placeholder text
+		log.Printf("⚠️  No images available, adding placeholder")
+		drawTextBoxSized(img, "No visual media available for this error", imageArea.Min.X, imageArea.Min.Y+50, imageArea.Dx(), 100, color.RGBA{180, 180, 180, 255}, 18*format.FontScale)
+	}
+
+	// Encode to PNG
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
 
+// drawShareChrome draws the background, header text and footer for a share image
+// and returns the rectangle left over for media
+func drawShareChrome(img *image.RGBA, errorLog *types.ErrorLog, format ShareImageFormat) image.Rectangle {
 	// Fill with gradient background
 	drawGradientBackground(img)
 
-	currentY := 20
+	margin := 20
+	textWidth := format.Width - 2*margin
+	scale := format.FontScale
+	currentY := int(20 * scale)
 
 	// Add title header
-	currentY = drawTextBox(img, "🚨 ERROR COMPILATION 🚨", 20, currentY, 1160, 40, color.RGBA{255, 255, 255, 255}, true)
+	currentY = drawTextBoxSized(img, "🚨 ERROR COMPILATION 🚨", margin, currentY, textWidth, int(40*scale), color.RGBA{255, 255, 255, 255}, 24*scale)
 	currentY += 10
 
 	// Add error message
-	currentY = drawTextBox(img, errorLog.Message, 20, currentY, 1160, 60, color.RGBA{255, 220, 220, 255}, false)
+	currentY = drawTextBoxSized(img, errorLog.Message, margin, currentY, textWidth, int(60*scale*format.TextRoom), color.RGBA{255, 220, 220, 255}, 18*scale)
 	currentY += 10
 
 	// Add slogan if available
 	if errorLog.Slogan != "" {
-		currentY = drawTextBox(img, fmt.Sprintf("💡 %s", errorLog.Slogan), 20, currentY, 1160, 40, color.RGBA{255, 255, 180, 255}, false)
+		currentY = drawTextBoxSized(img, fmt.Sprintf("💡 %s", errorLog.Slogan), margin, currentY, textWidth, int(40*scale*format.TextRoom), color.RGBA{255, 255, 180, 255}, 18*scale)
 		currentY += 10
 	}
 
 	log.Printf("📝 Text rendering complete, currentY=%d", currentY)
 
-	// Calculate remaining space for images
-	remainingHeight := fbShareHeight - currentY - 20
-	imageAreaY := currentY
+	// Add footer with timestamp
+	footerHeight := int(25 * scale)
+	footerY := format.Height - footerHeight
+	drawTextBoxSized(img, fmt.Sprintf("Generated: %s | notspies.org", errorLog.Timestamp.Format("Jan 2, 2006")), margin, footerY-int(10*(scale-1)), textWidth, 20, color.RGBA{200, 200, 200, 255}, 14*scale)
 
-	// Collect images to display
-	var imagesToComposite []image.Image
+	// Remaining space for images
+	remainingHeight := footerY - currentY - 10
+	if remainingHeight < 0 {
+		remainingHeight = 0
+	}
+	return image.Rect(margin, currentY, format.Width-margin, currentY+remainingHeight)
+}
+
+// collectShareImages downloads the media shown in a share image
+func collectShareImages(errorLog *types.ErrorLog) []image.Image {
+	return collectShareMedia(errorLog, downloadAndDecodeImage)
+}
+
+// collectShareMedia loads the media shown in a share image in priority order:
+// meme, then food image (only without a meme), then up to 4 GIFs
+func collectShareMedia[T any](errorLog *types.ErrorLog, load func(string) (T, error)) []T {
+	var imagesToComposite []T
 
 	// Priority 1: Meme image (if available)
 	if errorLog.MemeURL != "" {
 		log.Printf("📥 Downloading meme image: %s", errorLog.MemeURL)
-		if memeImg, err := downloadAndDecodeImage(errorLog.MemeURL); err == nil {
+		if memeImg, err := load(errorLog.MemeURL); err == nil {
 			imagesToComposite = append(imagesToComposite, memeImg)
 			log.Printf("✅ Meme image downloaded")
 		} else {
@@ -90,7 +143,7 @@ func generateFacebookShareImage(errorLog *types.ErrorLog) ([]byte, error) {
 	// Priority 2: Food image (if available and no meme)
 	if len(imagesToComposite) == 0 && errorLog.FoodImageURL != "" {
 		log.Printf("📥 Downloading food image: %s", errorLog.FoodImageURL)
-		if foodImg, err := downloadAndDecodeImage(errorLog.FoodImageURL); err == nil {
+		if foodImg, err := load(errorLog.FoodImageURL); err == nil {
 			imagesToComposite = append(imagesToComposite, foodImg)
 			log.Printf("✅ Food image downloaded")
 		} else {
@@ -105,7 +158,7 @@ func generateFacebookShareImage(errorLog *types.ErrorLog) ([]byte, error) {
 			break
 		}
 		log.Printf("📥 Downloading GIF %d: %s", gifCount+1, gifURL)
-		if gifImg, err := downloadAndDecodeImage(gifURL); err == nil {
+		if gifImg, err := load(gifURL); err == nil {
 			imagesToComposite = append(imagesToComposite, gifImg)
 			gifCount++
 			log.Printf("✅ GIF %d downloaded", gifCount)
@@ -117,7 +170,7 @@ func generateFacebookShareImage(errorLog *types.ErrorLog) ([]byte, error) {
 	// Fallback to single GIF URL if GifURLs is empty
 	if len(imagesToComposite) == 0 && errorLog.GifURL != "" {
 		log.Printf("📥 Downloading fallback GIF: %s", errorLog.GifURL)
-		if gifImg, err := downloadAndDecodeImage(errorLog.GifURL); err == nil {
+		if gifImg, err := load(errorLog.GifURL); err == nil {
 			imagesToComposite = append(imagesToComposite, gifImg)
 			log.Printf("✅ Fallback GIF downloaded")
 		} else {
@@ -125,29 +178,7 @@ func generateFacebookShareImage(errorLog *types.ErrorLog) ([]byte, error) {
 		}
 	}
 
-	log.Printf("🖼️  Total images collected: %d", len(imagesToComposite))
-
-	// Composite images into the canvas
-	if len(imagesToComposite) > 0 {
-		log.Printf("🎨 Compositing %d images", len(imagesToComposite))
-		compositeImages(img, imagesToComposite, 20, imageAreaY, fbShareWidth-40, remainingHeight-30)
-	} else {
-		// No images available, add placeholder text
-		log.Printf("⚠️  No images available, adding placeholder")
-		drawTextBox(img, "No visual media available for this error", 20, imageAreaY+50, 1160, 100, color.RGBA{180, 180, 180, 255}, false)
-	}
-
-	// Add footer with timestamp
-	footerY := fbShareHeight - 25
-	drawTextBox(img, fmt.Sprintf("Generated: %s | notspies.org", errorLog.Timestamp.Format("Jan 2, 2006")), 20, footerY, 1160, 20, color.RGBA{200, 200, 200, 255}, false)
-
-	// Encode to PNG
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
-		return nil, fmt.Errorf("failed to encode image: %w", err)
-	}
-
-	return buf.Bytes(), nil
+	return imagesToComposite
 }
 
 // drawGradientBackground fills the image with a gradient
@@ -167,18 +198,35 @@ func drawGradientBackground(img *image.RGBA) {
 
 // drawTextBox draws text within a box with word wrapping
 func drawTextBox(img *image.RGBA, text string, x, y, maxWidth, maxHeight int, textColor color.RGBA, bold bool) int {
-	// Parse font
-	fontData := goregular.TTF
-	parsedFont, err := freetype.ParseFont(fontData)
-	if err != nil {
-		log.Printf("Failed to parse font: %v", err)
-		return y + 20
-	}
-
 	fontSize := 18.0
 	if bold {
 		fontSize = 24.0
 	}
+	return drawTextBoxSized(img, text, x, y, maxWidth, maxHeight, textColor, fontSize)
+}
+
+// shareFont is the parsed Go Regular font, parsed once on first use
+var (
+	shareFont     *truetype.Font
+	shareFontErr  error
+	shareFontOnce sync.Once
+)
+
+// getShareFont returns the parsed TrueType font used for all share rendering
+func getShareFont() (*truetype.Font, error) {
+	shareFontOnce.Do(func() {
+		shareFont, shareFontErr = freetype.ParseFont(goregular.TTF)
+	})
+	return shareFont, shareFontErr
+}
+
+// drawTextBoxSized draws word-wrapped text at an explicit font size
+func drawTextBoxSized(img *image.RGBA, text string, x, y, maxWidth, maxHeight int, textColor color.RGBA, fontSize float64) int {
+	parsedFont, err := getShareFont()
+	if err != nil {
+		log.Printf("Failed to parse font: %v", err)
+		return y + 20
+	}
 
 	// Create font face
 	face := truetype.NewFace(parsedFont, &truetype.Options{
@@ -194,6 +242,29 @@ func drawTextBox(img *image.RGBA, text string, x, y, maxWidth, maxHeight int, te
 	}
 
 	// Word wrap
+	lines := wrapTextLines(face, text, maxWidth-20)
+
+	// Draw lines
+	currentY := y + int(fontSize) + 5
+	lineHeight := int(fontSize * 1.3)
+
+	for i, line := range lines {
+		if i*lineHeight > maxHeight {
+			break
+		}
+		drawer.Dot = fixed.Point26_6{
+			X: fixed.I(x + 10),
+			Y: fixed.I(currentY),
+		}
+		drawer.DrawString(line)
+		currentY += lineHeight
+	}
+
+	return currentY + 10
+}
+
+// wrapTextLines greedily word-wraps text so each line measures at most maxWidth pixels in face
+func wrapTextLines(face font.Face, text string, maxWidth int) []string {
 	words := strings.Fields(text)
 	lines := []string{}
 	currentLine := ""
@@ -205,8 +276,8 @@ func drawTextBox(img *image.RGBA, text string, x, y, maxWidth, maxHeight int, te
 		}
 		testLine += word
 
-		advance := drawer.MeasureString(testLine)
-		if advance.Ceil() > maxWidth-20 && currentLine != "" {
+		advance := font.MeasureString(face, testLine)
+		if advance.Ceil() > maxWidth && currentLine != "" {
 			lines = append(lines, currentLine)
 			currentLine = word
 		} else {
@@ -216,28 +287,14 @@ func drawTextBox(img *image.RGBA, text string, x, y, maxWidth, maxHeight int, te
 	if currentLine != "" {
 		lines = append(lines, currentLine)
 	}
-
-	// Draw lines
-	currentY := y + int(fontSize) + 5
-	lineHeight := int(fontSize * 1.3)
-
-	for i, line := range lines {
-		if i*lineHeight > maxHeight {
-			break
-		}
-		drawer.Dot = fixed.Point26_6{
-			X: fixed.I(x + 10),
-			Y: fixed.I(currentY),
-		}
-		drawer.DrawString(line)
-		currentY += lineHeight
-	}
-
-	return currentY + 10
+	return lines
 }
 
-// downloadAndDecodeImage downloads an image from a URL and decodes it
-func downloadAndDecodeImage(imageURL string) (image.Image, error) {
+// maxDownloadedImageBytes caps remote media downloads (Giphy GIFs can be large)
+const maxDownloadedImageBytes = 20 * 1024 * 1024
+
+// downloadImageData downloads raw image bytes from a URL
+func downloadImageData(imageURL string) ([]byte, error) {
 	// Add timeout to prevent hanging
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -254,10 +311,20 @@ func downloadAndDecodeImage(imageURL string) (image.Image, error) {
 	}
 
 	// Read response body
-	data, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadedImageBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read image data: %w", err)
 	}
+	return data, nil
+}
+
+// downloadAndDecodeImage downloads an image from a URL and decodes it
+// (first frame only for animated GIFs)
+func downloadAndDecodeImage(imageURL string) (image.Image, error) {
+	data, err := downloadImageData(imageURL)
+	if err != nil {
+		return nil, err
+	}
 
 	// Try to decode as various formats
 	img, _, err := image.Decode(bytes.NewReader(data))
@@ -282,9 +349,25 @@ func compositeImages(canvas *image.RGBA, images []image.Image, x, y, maxWidth, m
 		return
 	}
 
+	cells := gridCells(len(images), image.Rect(x, y, x+maxWidth, y+maxHeight))
+	for i, img := range images {
+		if i >= len(cells) {
+			break
+		}
+		drawImageInCell(canvas, img, cells[i])
+	}
+}
+
+// gridCells splits area into the grid used for count images.
+// Tall areas (story format) stack images vertically instead of side by side.
+func gridCells(count int, area image.Rectangle) []image.Rectangle {
+	if count == 0 {
+		return nil
+	}
+
 	// Determine layout
 	var cols, rows int
-	switch len(images) {
+	switch count {
 	case 1:
 		cols, rows = 1, 1
 	case 2:
@@ -294,30 +377,34 @@ func compositeImages(canvas *image.RGBA, images []image.Image, x, y, maxWidth, m
 	default:
 		cols, rows = 3, 2
 	}
+	if area.Dy() > area.Dx() {
+		cols, rows = rows, cols
+	}
 
-	cellWidth := maxWidth / cols
-	cellHeight := maxHeight / rows
-
-	for i, img := range images {
-		if i >= cols*rows {
-			break
-		}
+	cellWidth := area.Dx() / cols
+	cellHeight := area.Dy() / rows
 
+	cells := make([]image.Rectangle, 0, cols*rows)
+	for i := 0; i < count && i < cols*rows; i++ {
 		row := i / cols
 		col := i % cols
+		cellX := area.Min.X + col*cellWidth
+		cellY := area.Min.Y + row*cellHeight
+		cells = append(cells, image.Rect(cellX, cellY, cellX+cellWidth, cellY+cellHeight))
+	}
+	return cells
+}
 
-		cellX := x + col*cellWidth
-		cellY := y + row*cellHeight
-
-		// Resize and draw image
-		resized := resizeImage(img, cellWidth-10, cellHeight-10)
+// drawImageInCell resizes img to fit cell (with padding) and draws it centered
+func drawImageInCell(canvas *image.RGBA, img image.Image, cell image.Rectangle) {
+	// Resize and draw image
+	resized := resizeImage(img, cell.Dx()-10, cell.Dy()-10)
 
-		// Center the image in the cell
-		offsetX := cellX + (cellWidth-resized.Bounds().Dx())/2
-		offsetY := cellY + (cellHeight-resized.Bounds().Dy())/2
+	// Center the image in the cell
+	offsetX := cell.Min.X + (cell.Dx()-resized.Bounds().Dx())/2
+	offsetY := cell.Min.Y + (cell.Dy()-resized.Bounds().Dy())/2
 
-		draw.Draw(canvas, image.Rect(offsetX, offsetY, offsetX+resized.Bounds().Dx(), offsetY+resized.Bounds().Dy()), resized, image.Point{0, 0}, draw.Over)
-	}
+	draw.Draw(canvas, image.Rect(offsetX, offsetY, offsetX+resized.Bounds().Dx(), offsetY+resized.Bounds().Dy()), resized, resized.Bounds().Min, draw.Over)
 }
 
 // resizeImage resizes an image to fit within maxWidth x maxHeight while maintaining aspect ratio
@@ -347,8 +434,8 @@ func resizeImage(img image.Image, maxWidth, maxHeight int) image.Image {
 	// Simple nearest-neighbor scaling
 	for y := 0; y < newHeight; y++ {
 		for x := 0; x < newWidth; x++ {
-			srcX := int(float64(x) / scale)
-			srcY := int(float64(y) / scale)
+			srcX := bounds.Min.X + int(float64(x)/scale)
+			srcY := bounds.Min.Y + int(float64(y)/scale)
 			resized.Set(x, y, img.At(srcX, srcY))
 		}
 	}
@@ -374,53 +461,40 @@ func handleFacebookShare(w http.ResponseWriter, r *http.Request) {
 	log.Printf("📱 Facebook share request: errorID=%s", errorID)
 
 	// Find the error log by ID (same as regular error log endpoint)
-	errorLogMutex.RLock()
-	var targetLog *types.ErrorLog
-	for i := range errorLogs {
-		if errorLogs[i].ID == errorID {
-			targetLog = &errorLogs[i]
-			break
-		}
-	}
-	errorLogMutex.RUnlock()
-
-	if targetLog == nil {
-		log.Printf("❌ Facebook share: Error log not found (ID: %s)", errorID)
+	targetLog, err := findErrorLog(errorID, "")
+	if err != nil {
+		log.Printf("❌ Facebook share: Error log not found (ID: %s): %v", errorID, err)
 		http.Error(w, "Error log not found", http.StatusNotFound)
 		return
 	}
 
 	log.Printf("✅ Facebook share: Found error log: %s", targetLog.Message)
 
-	// Generate compilation image
-	imageData, err := generateFacebookShareImage(targetLog)
+	// Make sure the OpenGraph image exists before handing out its URL; other formats render in the background
+	imageData, _, err := shareImageService.GetShareImage(r.Context(), targetLog, shareFormatOG)
 	if err != nil {
 		log.Printf("❌ Failed to generate Facebook share image: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to generate share image: %v", err), http.StatusInternalServerError)
 		return
 	}
+	log.Printf("✅ Facebook share image ready (%d bytes)", len(imageData))
 
-	log.Printf("✅ Facebook share image generated (%d bytes)", len(imageData))
-
-	// Store image temporarily (in production, upload to S3)
-	shareImageID := fmt.Sprintf("share_%s", errorID)
-	shareImagesMutex.Lock()
-	shareImages[shareImageID] = imageData
-	shareImagesMutex.Unlock()
-
-	// Create share URLs
-	baseURL := "https://notspies.org"
-	if os.Getenv("BASE_URL") != "" {
-		baseURL = os.Getenv("BASE_URL")
+	images := make(map[string]string, len(shareImageFormats))
+	for name, format := range shareImageFormats {
+		images[name] = shareImageFormatURL(errorID, name)
+		if format != shareFormatOG {
+			shareImageService.PrefetchShareImage(targetLog, format)
+		}
 	}
 
-	imageURL := fmt.Sprintf("%s/api/share-image/%s", baseURL, shareImageID)
+	// Create share URLs
+	imageURL := shareImageURL(errorID)
 
 	// Use the error log's existing URL (which has proper timestamp format)
 	shareURL := targetLog.URL
 	if shareURL == "" {
 		// Fallback if URL not set
-		shareURL = fmt.Sprintf("%s/", baseURL)
+		shareURL = fmt.Sprintf("%s/", getBaseURL())
 	}
 
 	// Create caption
@@ -439,6 +513,8 @@ func handleFacebookShare(w http.ResponseWriter, r *http.Request) {
 	// Return response
 	response := FacebookShareResponse{
 		ImageURL:    imageURL,
+		AnimatedURL: shareAnimatedImageURL(errorID, shareFormatOG.Name),
+		Images:      images,
 		ShareURL:    shareURL,
 		Caption:     caption,
 		DirectShare: fbShareURL,
@@ -448,38 +524,74 @@ func handleFacebookShare(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleShareImage serves the generated share image
+// handleShareImage serves a generated share image.
+// Path: /api/share-image/share_{id} (PNG) or share_{id}.gif (animated),
+// optional ?format=og|story|square (default og)
 func handleShareImage(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Extract share image ID from path: /api/share-image/{id}
+	// Extract share image ID from path: /api/share-image/{id}[.png|.gif]
 	shareID := strings.TrimPrefix(r.URL.Path, "/api/share-image/")
-	if shareID == "" {
+	animated := isAnimatedShareRequest(shareID)
+	shareID = strings.TrimSuffix(strings.TrimSuffix(shareID, ".gif"), ".png")
+	errorID := strings.TrimPrefix(shareID, "share_")
+	if shareID == "" || errorID == shareID || errorID == "" {
 		http.Error(w, "Invalid share ID", http.StatusBadRequest)
 		return
 	}
 
-	// Retrieve image data
-	shareImagesMutex.Lock()
-	imageData, exists := shareImages[shareID]
-	shareImagesMutex.Unlock()
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = shareFormatOG.Name
+	}
+	format, ok := shareImageFormats[formatName]
+	if !ok {
+		http.Error(w, "Unknown share image format", http.StatusBadRequest)
+		return
+	}
 
-	if !exists {
+	targetLog, err := findErrorLog(errorID, "")
+	if err != nil {
 		http.Error(w, "Share image not found", http.StatusNotFound)
 		return
 	}
 
+	// Content-hash keys double as strong ETags
+	key := shareImageKey(targetLog, format)
+	contentType := "image/png"
+	render := func() ([]byte, error) { return renderShareImage(targetLog, format) }
+	if animated {
+		key = shareAnimatedImageKey(targetLog, format)
+		contentType = "image/gif"
+		render = func() ([]byte, error) { return renderAnimatedShareImage(targetLog, format) }
+	}
+	etag := `"` + path.Base(key) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	imageData, contentType, err := shareImageService.Get(r.Context(), key, contentType, render)
+	if err == errRenderQueueFull {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Share image is being generated, try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to serve share image %s (%s): %v", shareID, format.Name, err)
+		http.Error(w, "Failed to generate share image", http.StatusInternalServerError)
+		return
+	}
+
 	// Serve image
-	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
 	w.Header().Set("Cache-Control", "public, max-age=86400") // Cache for 1 day
+	if r.Method == http.MethodHead {
+		return
+	}
 	w.Write(imageData)
 }
-
-// In-memory storage for share images (in production, use S3)
-var (
-	shareImages      = make(map[string][]byte)
-	shareImagesMutex sync.Mutex
-)
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"location-tracker/sanitize"
+	"location-tracker/types"
+)
+
+const (
+	feedDefaultLimit = 30
+	feedMaxLimit     = 100
+	feedTitle        = "Agency Case Archive"
+	feedDescription  = "Declassified error reports, field stories and slogans from the agency."
+	feedMaxVersions  = 1000
+)
+
+// feedVersions holds the current body of each feed URL by ETag and when it first changed
+// to it, which is the feed's Last-Modified. The newest entry's date misses an older case
+// being edited, redacted or purged; the body hash does not.
+var (
+	feedVersions      = make(map[string]feedVersion)
+	feedVersionsMutex sync.Mutex
+)
+
+type feedVersion struct {
+	ETag  string
+	Since time.Time
+}
+
+// feedItem is the format-neutral view of one case shared by the Atom, RSS and JSON writers
+type feedItem struct {
+	ID          string
+	Title       string
+	Link        string
+	Summary     string
+	ContentHTML string
+	Published   time.Time
+	Categories  []string
+	Enclosures  []feedEnclosure
+}
+
+// feedEnclosure is a media attachment (meme, share image)
+type feedEnclosure struct {
+	URL  string
+	Type string
+}
+
+// feedRequest is a parsed /feeds/ request
+type feedRequest struct {
+	Format string // atom, rss or json
+	Filter CaseFilter
+	Limit  int
+}
+
+// handleCaseFeed serves /feeds/cases.{atom,rss,json}.
+// ?keyword= and ?business= produce filtered feeds (?since=/?until= bound the dates); ?limit= caps the item count.
+// Feeds are public and contain puzzle-level data only.
+func handleCaseFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if name := path.Base(r.URL.Path); !isFeedName(name) {
+		http.Error(w, "Unknown feed (use /feeds/cases.atom, .rss or .json)", http.StatusNotFound)
+		return
+	}
+	req, err := parseFeedRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cases := filterCases(loadCaseArchive(), req.Filter, req.Limit)
+	items := make([]feedItem, len(cases))
+	for i := range cases {
+		items[i] = buildFeedItem(&cases[i])
+	}
+
+	updated := time.Unix(0, 0).UTC()
+	if len(items) > 0 {
+		updated = items[0].Published.UTC()
+	}
+
+	selfURL := getBaseURL() + r.URL.RequestURI()
+	var body []byte
+	var contentType string
+	switch req.Format {
+	case "atom":
+		body, err = renderAtomFeed(items, req.Filter, selfURL, updated)
+		contentType = "application/atom+xml; charset=utf-8"
+	case "rss":
+		body, err = renderRSSFeed(items, req.Filter, selfURL, updated)
+		contentType = "application/rss+xml; charset=utf-8"
+	case "json":
+		body, err = renderJSONFeed(items, req.Filter, selfURL)
+		contentType = "application/feed+json; charset=utf-8"
+	}
+	if err != nil {
+		log.Printf("❌ Failed to render %s feed: %v", req.Format, err)
+		http.Error(w, "Failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := feedLastModified(selfURL, etag, time.Now())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if feedNotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == "HEAD" {
+		return
+	}
+	w.Write(body)
+}
+
+// parseFeedRequest reads the feed format and query parameters
+func parseFeedRequest(r *http.Request) (feedRequest, error) {
+	format := strings.TrimPrefix(path.Ext(r.URL.Path), ".")
+
+	query := r.URL.Query()
+	limit := feedDefaultLimit
+	if n, err := strconv.Atoi(query.Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if limit > feedMaxLimit {
+		limit = feedMaxLimit
+	}
+
+	filter, err := parseCaseFilter(query)
+	if err != nil {
+		return feedRequest{}, err
+	}
+
+	return feedRequest{
+		Format: format,
+		Filter: filter,
+		Limit:  limit,
+	}, nil
+}
+
+// isFeedName reports whether name is one of cases.atom, cases.rss or cases.json
+func isFeedName(name string) bool {
+	return name == "cases.atom" || name == "cases.rss" || name == "cases.json"
+}
+
+// feedLastModified returns when the feed at feedURL last changed to the body with this
+// ETag. A feed seen for the first time (or since a restart) counts as modified now, which
+// can only cost a client a 200 instead of a 304.
+func feedLastModified(feedURL, etag string, now time.Time) time.Time {
+	feedVersionsMutex.Lock()
+	defer feedVersionsMutex.Unlock()
+
+	previous, ok := feedVersions[feedURL]
+	if ok && previous.ETag == etag {
+		return previous.Since
+	}
+	since := now.UTC().Truncate(time.Second)
+	if ok && !since.After(previous.Since) {
+		// Changed twice within a second: If-Modified-Since must still see a newer time
+		since = previous.Since.Add(time.Second)
+	}
+	if !ok && len(feedVersions) >= feedMaxVersions {
+		feedVersions = make(map[string]feedVersion)
+	}
+	feedVersions[feedURL] = feedVersion{ETag: etag, Since: since}
+	return since
+}
+
+// feedNotModified implements conditional GET; If-None-Match takes precedence over If-Modified-Since
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if since, err := http.ParseTime(ims); err == nil {
+			return !lastModified.After(since)
+		}
+	}
+	return false
+}
+
+// buildFeedItem converts a redacted case into a feed entry
+func buildFeedItem(errorLog *types.ErrorLog) feedItem {
+	var content strings.Builder
+	if errorLog.Slogan != "" {
+		fmt.Fprintf(&content, "<p><strong>%s</strong></p>\n", html.EscapeString(errorLog.Slogan))
+	}
+	fmt.Fprintf(&content, "<p><img src=\"%s\" alt=\"%s\"></p>\n", html.EscapeString(shareImageURL(errorLog.ID)), html.EscapeString(errorLog.Message))
+	if story := sanitize.StoryHTML(errorLog.ChildrensStory); story != "" {
+		content.WriteString(story)
+		content.WriteString("\n")
+	}
+	if errorLog.SatiricalFix != "" {
+		fmt.Fprintf(&content, "<p><em>Proposed fix:</em> %s</p>\n", html.EscapeString(sanitize.PlainText(errorLog.SatiricalFix)))
+	}
+	if errorLog.SongTitle != "" && errorLog.SongURL != "" {
+		fmt.Fprintf(&content, "<p>Soundtrack: <a href=\"%s\">%s – %s</a></p>\n",
+			html.EscapeString(errorLog.SongURL), html.EscapeString(errorLog.SongTitle), html.EscapeString(errorLog.SongArtist))
+	}
+
+	summary := errorLog.Slogan
+	if story := sanitize.Excerpt(errorLog.ChildrensStory, 280); story != "" {
+		summary = story
+	}
+
+	enclosures := make([]feedEnclosure, 0, 2)
+	if errorLog.MemeURL != "" {
+		enclosures = append(enclosures, feedEnclosure{URL: errorLog.MemeURL, Type: imageTypeForURL(errorLog.MemeURL)})
+	}
+	enclosures = append(enclosures, feedEnclosure{URL: shareImageURL(errorLog.ID), Type: "image/png"})
+
+	return feedItem{
+		ID:          caseTagURI(errorLog),
+		Title:       errorLog.Message,
+		Link:        caseURL(errorLog),
+		Summary:     summary,
+		ContentHTML: content.String(),
+		Published:   errorLog.Timestamp,
+		Categories:  errorLog.SeedKeywords,
+		Enclosures:  enclosures,
+	}
+}
+
+// caseTagURI returns a stable tag: URI (RFC 4151) so readers don't duplicate entries if BASE_URL changes
+func caseTagURI(errorLog *types.ErrorLog) string {
+	host := "notspies.org"
+	if parsed, err := url.Parse(getBaseURL()); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:case/%s", host, errorLog.Timestamp.UTC().Format("2006-01-02"), errorLog.ID)
+}
+
+// imageTypeForURL guesses an image MIME type from a URL's extension
+func imageTypeForURL(imageURL string) string {
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return "image/png"
+	}
+	switch strings.ToLower(path.Ext(parsed.Path)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "image/png"
+	}
+}
+
+// feedTitleFor names a (possibly filtered) feed
+func feedTitleFor(filter CaseFilter) string {
+	switch {
+	case filter.Keyword != "" && filter.Business != "":
+		return fmt.Sprintf("%s: %q at %s", feedTitle, filter.Keyword, filter.Business)
+	case filter.Keyword != "":
+		return fmt.Sprintf("%s: %q", feedTitle, filter.Keyword)
+	case filter.Business != "":
+		return fmt.Sprintf("%s: %s", feedTitle, filter.Business)
+	}
+	return feedTitle
+}
+
+// Atom 1.0 (RFC 4287)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+func renderAtomFeed(items []feedItem, filter CaseFilter, selfURL string, updated time.Time) ([]byte, error) {
+	feed := atomFeed{
+		Title:    feedTitleFor(filter),
+		Subtitle: feedDescription,
+		ID:       selfURL,
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: getBaseURL(), Rel: "alternate", Type: "text/html"},
+		},
+		Author: atomPerson{Name: "The Agency"},
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Summary:   atomText{Type: "text", Body: item.Summary},
+			Content:   atomText{Type: "html", Body: item.ContentHTML},
+		}
+		for _, enclosure := range item.Enclosures {
+			entry.Links = append(entry.Links, atomLink{Href: enclosure.URL, Rel: "enclosure", Type: enclosure.Type})
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalFeedXML(feed)
+}
+
+// RSS 2.0
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+func renderRSSFeed(items []feedItem, filter CaseFilter, selfURL string, updated time.Time) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feedTitleFor(filter),
+			Link:          getBaseURL(),
+			Description:   feedDescription,
+			AtomLink:      atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: updated.Format(time.RFC1123Z),
+			TTL:           5,
+		},
+	}
+	for _, item := range items {
+		rss := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Categories:  item.Categories,
+			Description: item.ContentHTML,
+		}
+		// RSS allows a single enclosure; prefer the meme, else the share image
+		if len(item.Enclosures) > 0 {
+			enclosure := item.Enclosures[0]
+			rss.Enclosure = &rssEnclosure{URL: enclosure.URL, Type: enclosure.Type}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rss)
+	}
+	return marshalFeedXML(feed)
+}
+
+func marshalFeedXML(feed interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// JSON Feed 1.1 (https://jsonfeed.org/version/1.1)
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+func renderJSONFeed(items []feedItem, filter CaseFilter, selfURL string) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitleFor(filter),
+		HomePageURL: getBaseURL(),
+		FeedURL:     selfURL,
+		Description: feedDescription,
+		Items:       make([]jsonFeedItem, 0, len(items)),
+	}
+	for _, item := range items {
+		entry := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			Summary:       item.Summary,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		for _, enclosure := range item.Enclosures {
+			entry.Attachments = append(entry.Attachments, jsonFeedAttachment{URL: enclosure.URL, MimeType: enclosure.Type})
+		}
+		if len(item.Enclosures) > 0 {
+			entry.Image = item.Enclosures[len(item.Enclosures)-1].URL
+		}
+		feed.Items = append(feed.Items, entry)
+	}
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return data, nil
+}
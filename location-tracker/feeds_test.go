@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"location-tracker/types"
+)
+
+func getFeed(t *testing.T, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/feeds/cases.atom", nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	handleCaseFeed(w, r)
+	return w
+}
+
+// TestCaseFeedLastModified edits a case older than the newest one and checks a client
+// holding the old feed gets the new one, whichever validator it sends
+func TestCaseFeedLastModified(t *testing.T) {
+	savedBaseURL, savedErrorLogs, savedVersions := appConfig.Server.BaseURL, errorLogs, feedVersions
+	t.Cleanup(func() {
+		appConfig.Server.BaseURL, errorLogs, feedVersions = savedBaseURL, savedErrorLogs, savedVersions
+	})
+	appConfig.Server.BaseURL = "https://tracker.example"
+	feedVersions = make(map[string]feedVersion)
+
+	newest := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	errorLogs = []types.ErrorLog{
+		{ID: "new", Message: "Newest case", Timestamp: newest},
+		{ID: "old", Message: "Older case", Timestamp: newest.Add(-24 * time.Hour)},
+	}
+
+	first := getFeed(t, nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status %d", first.Code)
+	}
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if lastModified == newest.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified is the newest entry's date")
+	}
+	if again := getFeed(t, nil); again.Header().Get("Last-Modified") != lastModified || again.Header().Get("ETag") != etag {
+		t.Errorf("unchanged feed: Last-Modified %s -> %s, ETag %s -> %s",
+			lastModified, again.Header().Get("Last-Modified"), etag, again.Header().Get("ETag"))
+	}
+	for _, header := range []http.Header{{"If-None-Match": {etag}}, {"If-Modified-Since": {lastModified}}} {
+		if w := getFeed(t, header); w.Code != http.StatusNotModified {
+			t.Errorf("unchanged feed with %v: status %d, want 304", header, w.Code)
+		}
+	}
+
+	errorLogs[1].Message = "Older case, corrected"
+
+	for _, header := range []http.Header{{"If-None-Match": {etag}}, {"If-Modified-Since": {lastModified}}} {
+		if w := getFeed(t, header); w.Code != http.StatusOK {
+			t.Errorf("edited feed with %v: status %d, want 200", header, w.Code)
+		}
+	}
+	edited := getFeed(t, nil)
+	editedAt, err := http.ParseTime(edited.Header().Get("Last-Modified"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before, _ := http.ParseTime(lastModified); !editedAt.After(before) {
+		t.Errorf("Last-Modified %s after an edit, want later than %s", editedAt, before)
+	}
+	if w := getFeed(t, http.Header{"If-Modified-Since": {edited.Header().Get("Last-Modified")}}); w.Code != http.StatusNotModified {
+		t.Errorf("edited feed with its own Last-Modified: status %d, want 304", w.Code)
+	}
+
+	// Back to a body seen before: still newer than anything a client may hold
+	errorLogs[1].Message = "Older case"
+	reverted, _ := http.ParseTime(getFeed(t, nil).Header().Get("Last-Modified"))
+	if !reverted.After(editedAt) {
+		t.Errorf("Last-Modified %s after reverting, want later than %s", reverted, editedAt)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"location-tracker/envelope"
+)
+
+// fieldEncoder seals sensitive fields (coordinates, IP addresses, SMS bodies) before
+// they are stored. nil when field encryption is not configured.
+var fieldEncoder *envelope.Encoder
+
+// initializeFieldEncryption builds the field encoder from appConfig.FieldEncryption:
+//
+//	provider: local  keyring_path: /etc/location-tracker/keyring.json
+//	provider: kms    kms_key_id: alias/location-tracker
+//
+// A configured provider that can't be loaded stops the server rather than silently
+// writing plaintext.
+func initializeFieldEncryption(ctx context.Context, cfg aws.Config) *envelope.Encoder {
+	var provider envelope.KeyProvider
+
+	switch name := appConfig.FieldEncryption.Provider; name {
+	case "":
+		log.Printf("⚠️  FIELD_ENCRYPTION_PROVIDER not set, sensitive fields will be stored in plaintext")
+		return nil
+	case "local":
+		path := appConfig.FieldEncryption.KeyringPath
+		if path == "" {
+			log.Fatal("❌ FIELD_KEYRING_PATH must be set for the local key provider")
+		}
+		keyring, err := envelope.LoadLocalKeyring(path)
+		if err != nil {
+			log.Fatalf("❌ Failed to load keyring: %v", err)
+		}
+		provider = keyring
+	case "kms":
+		keyID := appConfig.FieldEncryption.KMSKeyID
+		if keyID == "" {
+			log.Fatal("❌ FIELD_KMS_KEY_ID must be set for the kms key provider")
+		}
+		provider = envelope.NewKMSProvider(kms.NewFromConfig(cfg), keyID)
+	default:
+		log.Fatalf("❌ Unknown FIELD_ENCRYPTION_PROVIDER %q (expected local or kms)", name)
+	}
+
+	encoder := envelope.NewEncoder(provider)
+
+	// Round-trip a value so a bad key or missing KMS permission shows up at startup
+	sealed, err := encoder.SealBytes(ctx, []byte("ok"), "startup")
+	if err == nil {
+		_, err = encoder.OpenBytes(ctx, sealed, "startup")
+	}
+	if err != nil {
+		log.Fatalf("❌ Field encryption check failed: %v", err)
+	}
+
+	log.Printf("🔒 Field encryption enabled (%s key provider)", provider.Name())
+	return encoder
+}
+
+// runRotateKeyringCommand adds a new primary key to a local keyring file, creating it if needed
+func runRotateKeyringCommand(args []string) int {
+	flags := flag.NewFlagSet("rotate-keyring", flag.ContinueOnError)
+	path := flags.String("path", appConfig.FieldEncryption.KeyringPath, "Keyring file (default field_encryption.keyring_path)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" {
+		log.Printf("❌ -path or FIELD_KEYRING_PATH is required")
+		return 2
+	}
+
+	keyID, err := envelope.RotateLocalKeyring(*path)
+	if err != nil {
+		log.Printf("❌ Keyring rotation failed: %v", err)
+		return 1
+	}
+	log.Printf("🔄 Keyring %s: new primary key %s (older keys kept for existing records)", *path, keyID)
+	return 0
+}
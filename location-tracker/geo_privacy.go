@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"location-tracker/geoprivacy"
+	"location-tracker/types"
+)
+
+// locationFuzzer turns reported positions into the points that are shared. nil when
+// GEO_PRIVACY=off, in which case exact positions are shared as before.
+var locationFuzzer *geoprivacy.Fuzzer
+
+// initializeGeoPrivacy builds the location fuzzer from appConfig.GeoPrivacy: epsilon
+// is the privacy per release in 1/metres (0.01 gives ~200 m mean noise), snap is
+// grid:<metres>, geohash:<precision> or none, and budget is the total epsilon per
+// device per window.
+func initializeGeoPrivacy() {
+	settings := appConfig.GeoPrivacy
+	if settings.Mode == "off" {
+		log.Printf("⚠️  GEO_PRIVACY=off, exact locations will be shared")
+		return
+	}
+
+	config := geoprivacy.Config{
+		Epsilon: settings.Epsilon,
+		Budget:  settings.Budget,
+		Window:  settings.Window,
+	}
+
+	snap := settings.Snap
+	snapper, err := geoprivacy.ParseSnapper(snap)
+	if err != nil {
+		log.Fatalf("❌ Invalid GEO_PRIVACY_SNAP: %v", err)
+	}
+	config.Snap = snapper
+
+	locationFuzzer, err = geoprivacy.NewFuzzer(config)
+	if err != nil {
+		log.Fatalf("❌ Invalid location privacy settings: %v", err)
+	}
+
+	log.Printf("🔒 Location privacy: epsilon %g/m (mean noise %.0fm), snap %s, budget %g per %s",
+		config.Epsilon, geoprivacy.ExpectedRadius(config.Epsilon), snap, config.Budget, config.Window)
+}
+
+// fuzzLocation replaces a reported position with the point to share. The exact position
+// moves to the Exact fields, which are never served and only stored sealed. Accuracy
+// grows by the radius the noise stays within 95% of the time, so maps draw an honest circle.
+// The budget is charged at the server's clock: a client-supplied timestamp in the future
+// would otherwise age every earlier spend out of the window.
+func fuzzLocation(loc types.Location) (types.Location, *geoprivacy.Release) {
+	if locationFuzzer == nil {
+		return loc, nil
+	}
+
+	release := locationFuzzer.Fuzz(loc.DeviceID, loc.Latitude, loc.Longitude, time.Now())
+	loc.ExactLatitude, loc.ExactLongitude = loc.Latitude, loc.Longitude
+	loc.Latitude, loc.Longitude = release.Latitude, release.Longitude
+	loc.Accuracy += geoprivacy.RadiusQuantile(locationFuzzer.Config().Epsilon, 0.95)
+	return loc, &release
+}
@@ -0,0 +1,213 @@
+/*
+# Module: geoprivacy/fuzzer.go
+Location fuzzer: planar Laplace noise, cell snapping and a per-device privacy budget over a sliding window.
+
+## Linked Modules
+- [geoprivacy/planar](./planar.go) - Planar Laplace noise
+- [geoprivacy/snap](./snap.go) - Grid and geohash snapping
+
+## Tags
+privacy, location, geo-indistinguishability, budget
+
+## Exports
+Config, Fuzzer, NewFuzzer, Release
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "geoprivacy/fuzzer.go" ;
+    code:description "Location fuzzer with noise, snapping and per-device privacy budget" ;
+    code:linksTo [
+        code:name "geoprivacy/planar" ;
+        code:path "./planar.go" ;
+        code:relationship "Planar Laplace noise"
+    ], [
+        code:name "geoprivacy/snap" ;
+        code:path "./snap.go" ;
+        code:relationship "Grid and geohash snapping"
+    ] ;
+    code:exports :Config, :Fuzzer, :NewFuzzer, :Release ;
+    code:tags "privacy", "location", "geo-indistinguishability", "budget" .
+<!-- End LinkedDoc RDF -->
+*/
+package geoprivacy
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Config sets how much each released point reveals and how much a device may reveal.
+type Config struct {
+	// Epsilon is the privacy parameter per release, in 1/metres. Smaller is more private:
+	// the mean displacement is 2/Epsilon metres.
+	Epsilon float64
+	// Snap moves noisy points to cell centres; nil releases them as they are.
+	Snap Snapper
+	// Budget is the total epsilon a device may spend within Window. Once it is spent,
+	// the device's last released point is repeated, which reveals nothing new.
+	Budget float64
+	Window time.Duration
+	// Uniform returns values in [0, 1) for the noise; nil uses crypto/rand.
+	Uniform func() float64
+}
+
+// Validate checks the configuration is usable.
+func (c Config) Validate() error {
+	switch {
+	case c.Epsilon <= 0:
+		return fmt.Errorf("epsilon must be positive, got %g", c.Epsilon)
+	case c.Budget < c.Epsilon:
+		return fmt.Errorf("budget %g is smaller than one release (epsilon %g)", c.Budget, c.Epsilon)
+	case c.Window <= 0:
+		return fmt.Errorf("budget window must be positive, got %s", c.Window)
+	}
+	return nil
+}
+
+// Release is a point that may be shared in place of the exact one.
+type Release struct {
+	Latitude  float64
+	Longitude float64
+	Cell      string  // snapping cell label, empty without snapping
+	Spent     float64 // epsilon spent on this release, 0 when Reused
+	Remaining float64 // budget left in the window after this release
+	Reused    bool    // budget was exhausted and the previous release was repeated
+}
+
+// spend is one release charged against a device's budget
+type spend struct {
+	at      time.Time
+	epsilon float64
+}
+
+// deviceState is a device's spending history and last release
+type deviceState struct {
+	spends []spend
+	last   *Release
+	seen   time.Time // time of the device's latest position
+}
+
+// Fuzzer turns exact positions into releasable ones and accounts for each device's
+// budget. Budgets are kept in memory and start afresh when the process restarts.
+// Devices that stop reporting are dropped by Expire.
+type Fuzzer struct {
+	config Config
+
+	mu      sync.Mutex
+	devices map[string]*deviceState
+}
+
+// NewFuzzer returns a fuzzer for a validated configuration.
+func NewFuzzer(config Config) (*Fuzzer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Fuzzer{config: config, devices: make(map[string]*deviceState)}, nil
+}
+
+// Config returns the fuzzer's configuration.
+func (f *Fuzzer) Config() Config {
+	return f.config
+}
+
+// Fuzz returns the point to release for a device at lat/lng. While the device has budget
+// left in the window, a fresh noisy point is drawn and charged; after that, the previous
+// release is returned unchanged until older spends leave the window.
+func (f *Fuzzer) Fuzz(deviceID string, lat, lng float64, now time.Time) Release {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := f.devices[deviceID]
+	if state == nil {
+		state = &deviceState{}
+		f.devices[deviceID] = state
+	}
+	if now.After(state.seen) {
+		state.seen = now
+	}
+	remaining := f.remaining(state, now)
+
+	// A device with no release yet always gets one, even with too little budget left
+	// (only possible after the configuration changed): withholding it would block sharing
+	if remaining < f.config.Epsilon && state.last != nil {
+		release := *state.last
+		release.Spent = 0
+		release.Remaining = remaining
+		release.Reused = true
+		return release
+	}
+
+	noisyLat, noisyLng := PlanarLaplace(lat, lng, f.config.Epsilon, f.config.Uniform)
+	release := Release{Latitude: noisyLat, Longitude: noisyLng}
+	if f.config.Snap != nil {
+		release.Latitude, release.Longitude, release.Cell = f.config.Snap.Snap(noisyLat, noisyLng)
+	}
+
+	state.spends = append(state.spends, spend{at: now, epsilon: f.config.Epsilon})
+	release.Spent = f.config.Epsilon
+	release.Remaining = math.Max(0, math.Round((remaining-f.config.Epsilon)*1e9)/1e9)
+	state.last = &release
+	return release
+}
+
+// Remaining returns the budget a device has left in the window at now.
+func (f *Fuzzer) Remaining(deviceID string, now time.Time) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := f.devices[deviceID]
+	if state == nil {
+		return f.config.Budget
+	}
+	return f.remaining(state, now)
+}
+
+// Forget drops a device's history and last release, e.g. when its data is erased.
+func (f *Fuzzer) Forget(deviceID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.devices, deviceID)
+}
+
+// Expire drops devices that have not reported within maxIdle of now, together with their
+// last release, and returns how many were dropped. A device whose spends are still inside
+// the budget window is kept, so expiring it never hands out fresh budget early.
+func (f *Fuzzer) Expire(maxIdle time.Duration, now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cutoff := now.Add(-maxIdle)
+	expired := 0
+	for deviceID, state := range f.devices {
+		if state.seen.After(cutoff) || f.remaining(state, now) < f.config.Budget {
+			continue
+		}
+		delete(f.devices, deviceID)
+		expired++
+	}
+	return expired
+}
+
+// remaining prunes spends that left the window and returns what is left; caller holds mu
+func (f *Fuzzer) remaining(state *deviceState, now time.Time) float64 {
+	cutoff := now.Add(-f.config.Window)
+	kept := state.spends[:0]
+	spent := 0.0
+	for _, s := range state.spends {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+			spent += s.epsilon
+		}
+	}
+	state.spends = kept
+
+	// Round away float error from summing many small spends
+	remaining := math.Round((f.config.Budget-spent)*1e9) / 1e9
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
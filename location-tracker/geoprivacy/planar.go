@@ -0,0 +1,113 @@
+/*
+# Module: geoprivacy/planar.go
+Planar Laplace noise for geo-indistinguishability: a point is moved by a random vector whose length follows Gamma(2, 1/epsilon).
+
+## Linked Modules
+- [geoprivacy/fuzzer](./fuzzer.go) - Applies noise, snapping and budgets to location updates
+
+## Tags
+privacy, location, geo-indistinguishability, laplace
+
+## Exports
+PlanarLaplace, Offset, ExpectedRadius, RadiusQuantile
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "geoprivacy/planar.go" ;
+    code:description "Planar Laplace noise for geo-indistinguishability" ;
+    code:linksTo [
+        code:name "geoprivacy/fuzzer" ;
+        code:path "./fuzzer.go" ;
+        code:relationship "Applies noise, snapping and budgets to location updates"
+    ] ;
+    code:exports :PlanarLaplace, :Offset, :ExpectedRadius, :RadiusQuantile ;
+    code:tags "privacy", "location", "geo-indistinguishability", "laplace" .
+<!-- End LinkedDoc RDF -->
+*/
+package geoprivacy
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+)
+
+// metersPerDegree is the length of one degree of latitude (and of longitude at the equator)
+const metersPerDegree = 111320.0
+
+// PlanarLaplace returns lat/lng moved by planar Laplace noise for epsilon, in 1/metres.
+// Any two points r metres apart produce the same output with probabilities within a
+// factor of e^(epsilon*r), which is geo-indistinguishability (Andrés et al., 2013).
+//
+// The noise density is proportional to e^(-epsilon*distance): a uniform direction and a
+// radius whose density is epsilon^2 * r * e^(-epsilon*r), i.e. the sum of two
+// exponentials. uniform returns values in [0, 1); nil uses crypto/rand.
+func PlanarLaplace(lat, lng, epsilon float64, uniform func() float64) (float64, float64) {
+	if uniform == nil {
+		uniform = cryptoUniform
+	}
+	theta := 2 * math.Pi * uniform()
+	radius := (-math.Log(1-uniform()) - math.Log(1-uniform())) / epsilon
+	return Offset(lat, lng, radius*math.Cos(theta), radius*math.Sin(theta))
+}
+
+// Offset moves lat/lng by east and north metres on a local flat approximation, clamping
+// latitude to the poles and wrapping longitude into [-180, 180).
+func Offset(lat, lng, east, north float64) (float64, float64) {
+	newLat := lat + north/metersPerDegree
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 1e-6 {
+		cosLat = 1e-6
+	}
+	newLng := lng + east/(metersPerDegree*cosLat)
+	return clampLatitude(newLat), wrapLongitude(newLng)
+}
+
+// ExpectedRadius is the mean distance planar Laplace noise moves a point, in metres.
+func ExpectedRadius(epsilon float64) float64 {
+	return 2 / epsilon
+}
+
+// RadiusQuantile is the distance, in metres, within which the noise stays with
+// probability p (0 < p < 1). It solves 1 - (1 + epsilon*r) e^(-epsilon*r) = p by bisection.
+func RadiusQuantile(epsilon, p float64) float64 {
+	cdf := func(r float64) float64 {
+		return 1 - (1+epsilon*r)*math.Exp(-epsilon*r)
+	}
+	lo, hi := 0.0, 1/epsilon
+	for cdf(hi) < p {
+		hi *= 2
+	}
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if cdf(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+func clampLatitude(lat float64) float64 {
+	return math.Max(-90, math.Min(90, lat))
+}
+
+func wrapLongitude(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
+
+// cryptoUniform returns a uniform float in [0, 1) from crypto/rand, so the noise can't be
+// reproduced from a guessed seed
+func cryptoUniform() float64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("geoprivacy: crypto/rand failed: " + err.Error())
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
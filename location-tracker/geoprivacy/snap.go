@@ -0,0 +1,174 @@
+/*
+# Module: geoprivacy/snap.go
+Snapping released points to the centre of a metric grid cell or a geohash cell.
+
+## Linked Modules
+- [geoprivacy/fuzzer](./fuzzer.go) - Snaps points after noise is applied
+
+## Tags
+privacy, location, geohash, grid
+
+## Exports
+Snapper, Grid, Geohash, EncodeGeohash, DecodeGeohash, ParseSnapper
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "geoprivacy/snap.go" ;
+    code:description "Snapping points to grid or geohash cells" ;
+    code:linksTo [
+        code:name "geoprivacy/fuzzer" ;
+        code:path "./fuzzer.go" ;
+        code:relationship "Snaps points after noise is applied"
+    ] ;
+    code:exports :Snapper, :Grid, :Geohash, :EncodeGeohash, :DecodeGeohash, :ParseSnapper ;
+    code:tags "privacy", "location", "geohash", "grid" .
+<!-- End LinkedDoc RDF -->
+*/
+package geoprivacy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Snapper moves a point to the centre of the cell containing it. Snapping a noisy point
+// is post-processing and costs no privacy budget; it keeps the released precision honest.
+type Snapper interface {
+	// Snap returns the cell centre and a label for the cell
+	Snap(lat, lng float64) (float64, float64, string)
+}
+
+// Grid snaps to square cells of roughly CellMeters on each side. Rows are fixed bands of
+// latitude; the column width is adjusted for the row's latitude.
+type Grid struct {
+	CellMeters float64
+}
+
+// Snap returns the centre of the grid cell containing lat/lng.
+func (g Grid) Snap(lat, lng float64) (float64, float64, string) {
+	latStep := g.CellMeters / metersPerDegree
+	row := math.Floor((lat + 90) / latStep)
+	centreLat := clampLatitude(row*latStep + latStep/2 - 90)
+
+	cosLat := math.Cos(centreLat * math.Pi / 180)
+	if cosLat < 1e-6 {
+		cosLat = 1e-6
+	}
+	lngStep := g.CellMeters / (metersPerDegree * cosLat)
+	col := math.Floor((lng + 180) / lngStep)
+	centreLng := wrapLongitude(col*lngStep + lngStep/2 - 180)
+
+	return centreLat, centreLng, fmt.Sprintf("grid:%g:%d:%d", g.CellMeters, int64(row), int64(col))
+}
+
+// Geohash snaps to geohash cells of the given precision (1-12 characters). Precision 7
+// cells are about 153 m x 153 m at the equator.
+type Geohash struct {
+	Precision int
+}
+
+// Snap returns the centre of the geohash cell containing lat/lng.
+func (g Geohash) Snap(lat, lng float64) (float64, float64, string) {
+	hash := EncodeGeohash(lat, lng, g.Precision)
+	centreLat, centreLng, _ := DecodeGeohash(hash)
+	return centreLat, centreLng, hash
+}
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// EncodeGeohash returns the geohash of lat/lng with precision characters.
+func EncodeGeohash(lat, lng float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for hash.Len() < precision {
+		if even {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if lng >= mid {
+				ch |= 1 << (4 - bit)
+				lngRange[0] = mid
+			} else {
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				latRange[0] = mid
+			} else {
+				latRange[1] = mid
+			}
+		}
+		even = !even
+		if bit < 4 {
+			bit++
+		} else {
+			hash.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// DecodeGeohash returns the centre of a geohash cell.
+func DecodeGeohash(hash string) (float64, float64, error) {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		index := strings.IndexRune(geohashAlphabet, c)
+		if index < 0 {
+			return 0, 0, fmt.Errorf("invalid geohash character %q", c)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := index&(1<<bit) != 0
+			if even {
+				mid := (lngRange[0] + lngRange[1]) / 2
+				if set {
+					lngRange[0] = mid
+				} else {
+					lngRange[1] = mid
+				}
+			} else {
+				mid := (latRange[0] + latRange[1]) / 2
+				if set {
+					latRange[0] = mid
+				} else {
+					latRange[1] = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lngRange[0] + lngRange[1]) / 2, nil
+}
+
+// ParseSnapper parses "grid:<metres>", "geohash:<precision>" or "none" (nil Snapper).
+func ParseSnapper(spec string) (Snapper, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch kind {
+	case "", "none":
+		return nil, nil
+	case "grid":
+		metres, err := strconv.ParseFloat(arg, 64)
+		if err != nil || metres <= 0 {
+			return nil, fmt.Errorf("grid cell size must be a positive number of metres, got %q", arg)
+		}
+		return Grid{CellMeters: metres}, nil
+	case "geohash":
+		precision, err := strconv.Atoi(arg)
+		if err != nil || precision < 1 || precision > 12 {
+			return nil, fmt.Errorf("geohash precision must be 1-12, got %q", arg)
+		}
+		return Geohash{Precision: precision}, nil
+	default:
+		return nil, fmt.Errorf("unknown snapping %q (expected grid:<metres>, geohash:<precision> or none)", spec)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+# Module: health/checks.go
+Reusable checks: heartbeats for background loops and TLS certificate validity.
+
+## Linked Modules
+- [health/registry](./registry.go) - Registry the checks are added to
+
+## Tags
+health, probes, tls, background
+
+## Exports
+Heartbeat, NewHeartbeat, CertificateValidity
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "health/checks.go" ;
+    code:description "Heartbeats for background loops and TLS certificate validity checks" ;
+    code:linksTo [
+        code:name "health/registry" ;
+        code:path "./registry.go" ;
+        code:relationship "Registry the checks are added to"
+    ] ;
+    code:exports :Heartbeat, :NewHeartbeat, :CertificateValidity ;
+    code:tags "health", "probes", "tls", "background" .
+<!-- End LinkedDoc RDF -->
+*/
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat shows a background loop is still going round. The loop calls Beat each
+// iteration; Check fails once no beat has arrived within MaxAge, which catches loops
+// that have exited or are stuck.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   atomic.Int64 // unix nanoseconds
+}
+
+// NewHeartbeat returns a heartbeat that counts as beaten now.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{maxAge: maxAge}
+	h.Beat()
+	return h
+}
+
+// Beat records that the loop is alive.
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Last returns when the loop last beat.
+func (h *Heartbeat) Last() time.Time {
+	return time.Unix(0, h.last.Load())
+}
+
+// Check fails when the last beat is older than MaxAge.
+func (h *Heartbeat) Check(ctx context.Context) error {
+	if age := time.Since(h.Last()); age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s (expected every %s at most)", age.Round(time.Second), h.maxAge)
+	}
+	return nil
+}
+
+// CertificateValidity checks the first certificate in a PEM file is already valid and
+// stays valid for at least minRemaining.
+func CertificateValidity(path string, minRemaining time.Duration) Check {
+	return func(ctx context.Context) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			now := time.Now()
+			if now.Before(cert.NotBefore) {
+				return fmt.Errorf("certificate not valid until %s", cert.NotBefore.UTC().Format(time.RFC3339))
+			}
+			if remaining := cert.NotAfter.Sub(now); remaining < minRemaining {
+				return fmt.Errorf("certificate expires %s (in %s, want at least %s)",
+					cert.NotAfter.UTC().Format(time.RFC3339), remaining.Round(time.Minute), minRemaining)
+			}
+			return nil
+		}
+		return fmt.Errorf("%s: no certificate found", path)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"location-tracker/health"
+)
+
+// livenessChecks fail only when restarting the process would help (a stuck background
+// loop); readinessChecks add the dependencies needed to serve traffic. Every liveness
+// check is also a readiness check.
+var (
+	livenessChecks  = health.NewRegistry()
+	readinessChecks = health.NewRegistry()
+)
+
+// tipKeyEphemeral is set when TIP_ENCRYPTION_KEY was missing and a random key is in use
+var tipKeyEphemeral bool
+
+// backgroundHeartbeat registers a heartbeat for a loop that wakes every interval. Call
+// it from the loop's goroutine and Beat on each iteration.
+func backgroundHeartbeat(name string, interval time.Duration) *health.Heartbeat {
+	heartbeat := health.NewHeartbeat(2*interval + time.Minute)
+	for _, registry := range []*health.Registry{livenessChecks, readinessChecks} {
+		registry.Register("goroutine:"+name, heartbeat.Check, health.Options{CacheFor: time.Second})
+	}
+	return heartbeat
+}
+
+// initializeHealthChecks registers the readiness checks for dependencies configured at
+// startup. Call it after DynamoDB and the tip system are initialized.
+func initializeHealthChecks() {
+	if useDynamoDB && dynamoClient != nil {
+		for _, table := range []string{
+			locationsTableName,
+			errorLogsTableName,
+			anonymousTipsTableName,
+			donationsTableName,
+			smsMessagesTableName,
+			privacyAuditTableName,
+			commercialRealEstateTableName,
+			bannedUsersTableName,
+		} {
+			readinessChecks.Register("dynamodb:"+table, dynamoDBTableCheck(table),
+				health.Options{Timeout: 3 * time.Second, CacheFor: 30 * time.Second})
+		}
+	}
+
+	readinessChecks.Register("keys:tip_identity", func(ctx context.Context) error {
+		if identityManager == nil {
+			return fmt.Errorf("identity manager not initialized")
+		}
+		if tipKeyEphemeral {
+			return fmt.Errorf("TIP_ENCRYPTION_KEY not set; a random key is in use and tip identities will not survive a restart")
+		}
+		return nil
+	}, health.Options{Optional: true})
+
+	if fieldEncoder != nil {
+		readinessChecks.Register("keys:field_encryption", fieldEncryptionCheck,
+			health.Options{Timeout: 3 * time.Second, CacheFor: 5 * time.Minute})
+	}
+}
+
+// registerCertificateCheck adds the serving certificate's remaining validity to readiness
+func registerCertificateCheck(certFile string) {
+	readinessChecks.Register("tls_certificate", health.CertificateValidity(certFile, appConfig.Server.CertMinValidity),
+		health.Options{CacheFor: time.Hour})
+}
+
+// dynamoDBTableCheck fails unless the table exists and is usable
+func dynamoDBTableCheck(table string) health.Check {
+	return func(ctx context.Context) error {
+		out, err := dynamoClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err != nil {
+			return err
+		}
+		switch status := out.Table.TableStatus; status {
+		case dynamodbtypes.TableStatusActive, dynamodbtypes.TableStatusUpdating:
+			return nil
+		default:
+			return fmt.Errorf("table status %s", status)
+		}
+	}
+}
+
+// fieldEncryptionCheck seals and opens a probe value with the current key provider
+func fieldEncryptionCheck(ctx context.Context) error {
+	probe := []byte("readiness probe")
+	sealed, err := fieldEncoder.SealBytes(ctx, probe, "health")
+	if err != nil {
+		return fmt.Errorf("seal: %w", err)
+	}
+	opened, err := fieldEncoder.OpenBytes(ctx, sealed, "health")
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	if !bytes.Equal(opened, probe) {
+		return fmt.Errorf("round trip returned different bytes")
+	}
+	return nil
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"location-tracker/envelope"
 )
 
 // UserMetadata contains information used to generate anonymous IDs
@@ -24,15 +29,18 @@ type UserMetadata struct {
 
 // UserIdentityManager handles anonymous ID generation and reversal
 type UserIdentityManager struct {
-	encryptionKey []byte // 32-byte AES-256 key
+	encryptionKey []byte            // 32-byte AES-256 key, used for HashIdentifier and legacy metadata
+	encoder       *envelope.Encoder // seals new metadata under per-record data keys when set
 }
 
-// NewUserIdentityManager creates a new identity manager with encryption key
-func NewUserIdentityManager(key []byte) (*UserIdentityManager, error) {
+// NewUserIdentityManager creates a new identity manager with encryption key. With an
+// encoder, new metadata is sealed with envelope encryption instead of the single key;
+// metadata sealed either way can still be reversed.
+func NewUserIdentityManager(key []byte, encoder *envelope.Encoder) (*UserIdentityManager, error) {
 	if len(key) != 32 {
 		return nil, fmt.Errorf("encryption key must be exactly 32 bytes for AES-256")
 	}
-	return &UserIdentityManager{encryptionKey: key}, nil
+	return &UserIdentityManager{encryptionKey: key, encoder: encoder}, nil
 }
 
 // GenerateAnonymousID creates a reversible anonymous ID from request metadata
@@ -51,14 +59,22 @@ func (uim *UserIdentityManager) GenerateAnonymousID(r *http.Request) (hash strin
 		return "", "", fmt.Errorf("failed to serialize metadata: %w", err)
 	}
 
-	// Encrypt metadata with AES-256-GCM
-	encryptedData, err := uim.encrypt(metadataJSON)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to encrypt metadata: %w", err)
-	}
+	if uim.encoder != nil {
+		// Seal metadata under a fresh data key
+		encryptedMetadata, err = uim.encoder.SealBytes(r.Context(), metadataJSON, "user_metadata")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encrypt metadata: %w", err)
+		}
+	} else {
+		// Encrypt metadata with AES-256-GCM
+		encryptedData, err := uim.encrypt(metadataJSON)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encrypt metadata: %w", err)
+		}
 
-	// Base64 encode encrypted data
-	encryptedMetadata = base64.StdEncoding.EncodeToString(encryptedData)
+		// Base64 encode encrypted data
+		encryptedMetadata = base64.StdEncoding.EncodeToString(encryptedData)
+	}
 
 	// Generate display hash (first 12 chars of SHA-256)
 	hashBytes := sha256.Sum256([]byte(encryptedMetadata))
@@ -69,16 +85,26 @@ func (uim *UserIdentityManager) GenerateAnonymousID(r *http.Request) (hash strin
 
 // ReverseHash decrypts metadata to reveal original user information (admin only)
 func (uim *UserIdentityManager) ReverseHash(encryptedMetadata string) (*UserMetadata, error) {
-	// Decode base64
-	encryptedData, err := base64.StdEncoding.DecodeString(encryptedMetadata)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64: %w", err)
-	}
-
-	// Decrypt AES
-	decryptedJSON, err := uim.decrypt(encryptedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	var decryptedJSON []byte
+	if envelope.IsSealed(encryptedMetadata) {
+		// Envelope-sealed metadata
+		var err error
+		decryptedJSON, err = uim.encoder.OpenBytes(context.Background(), encryptedMetadata, "user_metadata")
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+	} else {
+		// Decode base64
+		encryptedData, err := base64.StdEncoding.DecodeString(encryptedMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64: %w", err)
+		}
+
+		// Decrypt AES
+		decryptedJSON, err = uim.decrypt(encryptedData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
 	}
 
 	// Parse metadata
@@ -90,6 +116,14 @@ func (uim *UserIdentityManager) ReverseHash(encryptedMetadata string) (*UserMeta
 	return &metadata, nil
 }
 
+// HashIdentifier returns a keyed hash of an identifier such as a phone number, so records
+// can be found by it again without storing it. Values are trimmed and lowercased first.
+func (uim *UserIdentityManager) HashIdentifier(value string) string {
+	mac := hmac.New(sha256.New, uim.encryptionKey)
+	mac.Write([]byte("identifier:" + strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
 // encrypt encrypts data using AES-256-GCM
 func (uim *UserIdentityManager) encrypt(plaintext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(uim.encryptionKey)
@@ -150,6 +150,7 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
@@ -171,7 +172,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -182,12 +183,12 @@ import (
 )
 
 var (
-	// In-memory cache (locations expire after 24 hours)
+	// In-memory cache (locations expire after limits.location_max_age)
 	locations     = make(map[string]types.Location)
 	locationMutex sync.RWMutex
 
-	// Error log cache (keep last 50 errors)
-	errorLogs     = make([]types.ErrorLog, 0, 50)
+	// Error log cache (keep the last limits.error_logs_in_memory errors)
+	errorLogs     = make([]types.ErrorLog, 0)
 	errorLogMutex sync.RWMutex
 
 	// Nearby businesses from last shared location
@@ -201,16 +202,17 @@ var (
 	// Pending user experience note from Twilio SMS
 	pendingUserExperienceNote string
 	pendingUserNoteKeywords   []string
+	pendingUserNoteSID        string
 	userExperienceNoteMutex   sync.RWMutex
 
-	// Global password from environment
-	globalPassword = os.Getenv("TRACKER_PASSWORD")
+	// Global password (auth.tracker_password)
+	globalPassword string
 
 	// Google Maps API key
-	googleMapsAPIKey = os.Getenv("GOOGLE_MAPS_API_KEY")
+	googleMapsAPIKey string
 
 	// Perplexity API key
-	perplexityAPIKey = os.Getenv("PERPLEXITY_API_KEY")
+	perplexityAPIKey string
 
 	// HTTPS mode flag
 	useHTTPS = false
@@ -219,13 +221,16 @@ var (
 	dynamoClient *dynamodb.Client
 	useDynamoDB  = false
 
-	// DynamoDB table names
-	errorLogsTableName            = "location-tracker-error-logs"
-	locationsTableName            = "location-tracker-locations"
-	commercialRealEstateTableName = "location-tracker-commercial-realestate"
-	anonymousTipsTableName        = "location-tracker-anonymous-tips"
-	bannedUsersTableName          = "location-tracker-banned-users"
-	donationsTableName            = "location-tracker-donations"
+	// DynamoDB table names (dynamodb.tables in the config)
+	errorLogsTableName            string
+	locationsTableName            string
+	commercialRealEstateTableName string
+	anonymousTipsTableName        string
+	bannedUsersTableName          string
+	donationsTableName            string
+	solidSessionsTableName        string
+	smsMessagesTableName          string
+	privacyAuditTableName         string
 
 	// Anonymous tips cache
 	anonymousTips      = make([]types.AnonymousTip, 0, 100)
@@ -239,11 +244,11 @@ var (
 	rateLimiter       *RateLimiter
 	banManager        *BanManager
 
-	// Configuration from environment
-	openaiAPIKey       = os.Getenv("OPENAI_API_KEY")
-	tipEncryptionKey   = os.Getenv("TIP_ENCRYPTION_KEY")
-	tipMaxLength       = 1000
-	tipRateLimit       = 10 // tips per hour per user
+	// Configuration copied from appConfig
+	openaiAPIKey     string
+	tipEncryptionKey string
+	tipMaxLength     int
+	tipRateLimit     int // tips per hour per user
 
 	// Last interaction context - tracks the most recent user-driven interaction
 	// All subsequent generated content (errors, GIFs, songs, etc.) traces back to this seed event
@@ -256,25 +261,39 @@ var (
 	contextService    *services.ContextService
 
 	// Repositories
-	errorLogRepo   storage.ErrorLogRepository
-	locationRepo   storage.LocationRepository
-	commercialRepo storage.CommercialRepository
-	tipRepo        storage.TipRepository
+	errorLogRepo     storage.ErrorLogRepository
+	locationRepo     storage.LocationRepository
+	commercialRepo   storage.CommercialRepository
+	tipRepo          storage.TipRepository
+	donationRepo     storage.DonationRepository
+	smsRepo          storage.SMSRepository
+	privacyAuditRepo storage.PrivacyAuditRepository
 )
 
+// subcommands run offline tools instead of the server
+var subcommands = map[string]func(args []string) int{
+	"export-epub":         runExportEPUBCommand,
+	"export-vault":        runExportVaultCommand,
+	"rotate-keyring":      runRotateKeyringCommand,
+	"simulate-trajectory": runSimulateTrajectoryCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			initializeToolConfig()
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	// Load and validate the configuration (exits for --print-config and --check-config)
+	initializeConfig(os.Args[1:])
+
 	// Initialize random seed for location generation
 	mrand.Seed(time.Now().UnixNano())
 
-	// Require password to be set
-	if globalPassword == "" {
-		log.Fatal("❌ TRACKER_PASSWORD environment variable must be set!")
-	}
-
-	// Check if HTTPS should be enabled
-	if os.Getenv("USE_HTTPS") == "true" {
-		useHTTPS = true
-	}
+	// Initialize metrics first so outbound calls made during startup are counted
+	initializeMetrics()
 
 	// Initialize DynamoDB connection (reads existing tables, never creates/modifies)
 	initializeDynamoDB()
@@ -282,9 +301,25 @@ func main() {
 	// Initialize anonymous tip system
 	initializeTipSystem()
 
+	// Initialize the change log replicas sync against
+	initializeSync()
+
+	// Initialize location fuzzing (geo-indistinguishability)
+	initializeGeoPrivacy()
+	initializeStats()
+
+	// Register readiness checks for the dependencies initialized above
+	initializeHealthChecks()
+
+	// Initialize share image rendering (blob store, LRU cache, render queue)
+	initializeShareImages()
+
+	// Initialize Solid pod login (opt-in via SOLID_ENABLED)
+	initializeSolid()
+
 	// Initialize services
-	businessService = services.NewBusinessService(googleMapsAPIKey)
-	commercialService = services.NewCommercialService(perplexityAPIKey)
+	businessService = services.NewBusinessService(googleMapsAPIKey, appConfig.Places.BusinessRadiusMeters)
+	commercialService = services.NewCommercialService(perplexityAPIKey, appConfig.Models.CommercialSearch, appConfig.Places.CommercialSearchRadiusMiles)
 	contextService = services.NewContextService()
 
 	log.Printf("✅ Location tracker starting...")
@@ -310,10 +345,18 @@ func main() {
 	http.HandleFunc("/api/cryptogram", handleCryptogram)
 	http.HandleFunc("/api/cryptogram/info", handleCryptogramInfo)
 	http.HandleFunc("/api/location", handleLocation)
+	http.HandleFunc("/api/sync", handleSync)
+	http.HandleFunc("/api/privacy/export", handlePrivacyExport)
+	http.HandleFunc("/api/privacy/erasure", handlePrivacyErasure)
+	http.HandleFunc("/api/privacy/erasure/", handlePrivacyErasureStatus)
+	http.HandleFunc("/api/privacy/audit", handlePrivacyAudit)
 	http.HandleFunc("/api/errorlogs/", handleErrorLogByID)
 	http.HandleFunc("/api/errorlogs", handleErrorLogs)
 	http.HandleFunc("/api/facebook-share/", handleFacebookShare)
 	http.HandleFunc("/api/share-image/", handleShareImage)
+	http.HandleFunc("/feeds/", handleCaseFeed)
+	http.HandleFunc("/sparql", handleSPARQL)
+	http.HandleFunc("/api/export/casebook.epub", handleCasebookExport)
 	http.HandleFunc("/api/rorschach/interpret/", handleRorschachInterpret)
 	http.HandleFunc("/api/rorschach/respond/", handleRorschachUserResponse)
 	http.HandleFunc("/api/businesses", handleBusinesses)
@@ -322,33 +365,35 @@ func main() {
 	http.HandleFunc("/api/last-interaction-context", handleLastInteractionContext)
 	http.HandleFunc("/api/commercialrealestate", handleCommercialRealEstate)
 	http.HandleFunc("/api/health", handleHealth)
+	http.Handle("/livez", livenessChecks.Handler())
+	http.Handle("/readyz", readinessChecks.Handler())
+	http.HandleFunc("/api/stats", handleStats)
+	http.HandleFunc("/api/solid/login", handleSolidLogin)
+	http.HandleFunc("/api/solid/callback", handleSolidCallback)
+	http.HandleFunc("/api/solid/session", handleSolidSessionStatus)
+	http.HandleFunc("/api/solid/logout", handleSolidLogout)
+	http.HandleFunc("/api/solid/clientid.jsonld", handleSolidClientID)
+	http.HandleFunc("/api/solid/share", handleSolidShare)
 	http.HandleFunc("/api/twilio/sms", handleTwilioWebhook)
 	http.HandleFunc("/api/tips", handleTips)
 	http.HandleFunc("/api/tips/", handleTipByID)
 
 	// Start cleanup goroutines
-	go cleanupOldLocations()
+	background.Go("location_cleanup", cleanupOldLocations)
 
 	// Load existing data from DynamoDB on startup (preserves all existing records)
 	if useDynamoDB {
-		go loadExistingData()
+		background.Async("load_existing_data", loadExistingData)
 	}
 
-	httpPort := "8080"
-	httpsPort := "8443"
-
-	// Allow custom ports via environment
-	if customHTTPPort := os.Getenv("HTTP_PORT"); customHTTPPort != "" {
-		httpPort = customHTTPPort
-	}
-	if customHTTPSPort := os.Getenv("HTTPS_PORT"); customHTTPSPort != "" {
-		httpsPort = customHTTPSPort
-	}
+	httpPort := appConfig.Server.HTTPPort
+	httpsPort := appConfig.Server.HTTPSPort
 
+	handler := instrumentRoutes(http.DefaultServeMux)
 	if useHTTPS {
 		// Check for certificate files or generate self-signed ones
-		certFile := os.Getenv("CERT_FILE")
-		keyFile := os.Getenv("KEY_FILE")
+		certFile := appConfig.Server.CertFile
+		keyFile := appConfig.Server.KeyFile
 
 		if certFile == "" || keyFile == "" {
 			log.Printf("📜 No certificates provided, generating self-signed certificate...")
@@ -360,23 +405,25 @@ func main() {
 			}
 			log.Printf("✅ Self-signed certificate generated")
 		}
+		registerCertificateCheck(certFile)
 
-		// Start HTTP server for Twilio webhooks (in background)
-		go func() {
-			log.Printf("🌍 HTTP server running on http://:%s (for Twilio webhooks)", httpPort)
-			if err := http.ListenAndServe(":"+httpPort, nil); err != nil {
-				log.Fatalf("❌ HTTP server failed: %v", err)
-			}
-		}()
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to load certificate: %v", err)
+		}
 
-		// Start HTTPS server for browser access (main thread)
+		// HTTP for Twilio webhooks, HTTPS for browser access
+		log.Printf("🌍 HTTP server running on http://:%s (for Twilio webhooks)", httpPort)
 		log.Printf("🌍 HTTPS server running on https://:%s (for browser access)", httpsPort)
-		log.Fatal(http.ListenAndServeTLS(":"+httpsPort, certFile, keyFile, nil))
+		serve(
+			&http.Server{Addr: ":" + httpPort, Handler: handler},
+			&http.Server{Addr: ":" + httpsPort, Handler: handler, TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}}},
+		)
 	} else {
 		log.Printf("⚠️  Running in HTTP mode - geolocation may not work in browsers!")
 		log.Printf("💡 Set USE_HTTPS=true to enable HTTPS")
 		log.Printf("🌍 Server running on http://:%s", httpPort)
-		log.Fatal(http.ListenAndServe(":"+httpPort, nil))
+		serve(&http.Server{Addr: ":" + httpPort, Handler: handler})
 	}
 }
 
@@ -490,8 +537,8 @@ func getCachedCommercialRealEstate(queryLat, queryLng float64, radiusMiles float
 		return nil, err
 	}
 
-	// Find a record within the specified radius and not too old (30 days)
-	cacheExpiryDuration := 30 * 24 * time.Hour
+	// Find a record within the specified radius and not too old
+	cacheExpiryDuration := appConfig.Limits.CommercialCacheTTL
 	now := time.Now()
 
 	for i := range records {
@@ -507,12 +554,14 @@ func getCachedCommercialRealEstate(queryLat, queryLng float64, radiusMiles float
 
 		// If within radius, return this cached record
 		if distance <= radiusMiles {
+			recordCacheLookup("commercial", true)
 			log.Printf("💾 Cache HIT: Found cached commercial real estate data %.2f miles away (age: %v)",
 				distance, now.Sub(record.Timestamp).Round(time.Hour))
 			return record, nil
 		}
 	}
 
+	recordCacheLookup("commercial", false)
 	log.Printf("🔍 Cache MISS: No cached data found within %.1f miles", radiusMiles)
 	return nil, nil
 }
@@ -544,7 +593,7 @@ func calculateDistance(lat1, lng1, lat2, lng2 float64) float64 {
 // It first checks the cache to avoid unnecessary API calls
 func searchCommercialRealEstate(baseLat, baseLng float64, userKeywords []string) ([]types.CommercialPropertyDetails, []types.GoverningBody, float64, float64, error) {
 	// Try to find cached data first
-	cached, err := getCachedCommercialRealEstate(baseLat, baseLng, 5.0)
+	cached, err := getCachedCommercialRealEstate(baseLat, baseLng, appConfig.Places.CommercialCacheRadiusMiles)
 	if err == nil && cached != nil {
 		return cached.Properties, cached.GoverningBodies, cached.QueryLat, cached.QueryLng, nil
 	}
@@ -585,24 +634,9 @@ func saveTipToDynamoDB(tip types.AnonymousTip) {
 		return
 	}
 
-	ctx := context.Background()
-
-	item, err := attributevalue.MarshalMap(tip)
-	if err != nil {
-		log.Printf("❌ Failed to marshal tip: %v", err)
-		return
-	}
-
-	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(anonymousTipsTableName),
-		Item:      item,
-	})
-	if err != nil {
+	if err := tipRepo.Save(tip); err != nil {
 		log.Printf("❌ Failed to save tip to DynamoDB: %v", err)
-		return
 	}
-
-	log.Printf("💾 Anonymous tip saved to DynamoDB: %s", tip.ID)
 }
 
 // getTipFromDynamoDB retrieves a tip by ID from DynamoDB
@@ -611,28 +645,7 @@ func getTipFromDynamoDB(tipID string) (*types.AnonymousTip, error) {
 		return nil, fmt.Errorf("DynamoDB not available")
 	}
 
-	ctx := context.Background()
-
-	result, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(anonymousTipsTableName),
-		Key: map[string]dynamodbtypes.AttributeValue{
-			"id": &dynamodbtypes.AttributeValueMemberS{Value: tipID},
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.Item == nil {
-		return nil, fmt.Errorf("tip not found")
-	}
-
-	var tip types.AnonymousTip
-	if err := attributevalue.UnmarshalMap(result.Item, &tip); err != nil {
-		return nil, err
-	}
-
-	return &tip, nil
+	return tipRepo.GetByID(tipID)
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -702,7 +715,7 @@ func handleVerifyTurnstile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify the Turnstile token with Cloudflare
-	turnstileSecretKey := os.Getenv("TURNSTILE_SECRET_KEY")
+	turnstileSecretKey := appConfig.Auth.TurnstileSecretKey
 	if turnstileSecretKey == "" {
 		// Use Cloudflare's test secret key that forces interactive challenge
 		turnstileSecretKey = "2x0000000000000000000000000000000AA"
@@ -806,7 +819,7 @@ func handleCreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stripeSecretKey := os.Getenv("STRIPE_SECRET_KEY")
+	stripeSecretKey := appConfig.APIKeys.StripeSecret
 	if stripeSecretKey == "" {
 		log.Printf("⚠️  STRIPE_SECRET_KEY not configured")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -870,7 +883,7 @@ func handleCreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if useDynamoDB {
-		go saveDonationToDynamoDB(donation)
+		background.Async("save_donation", func() { saveDonationToDynamoDB(donation) })
 	}
 
 	log.Printf("💰 Payment intent created: %s for %s ($%.2f)", donation.StripePaymentID, req.DonationType, float64(amount)/100)
@@ -923,19 +936,7 @@ func handleStripeWebhook(w http.ResponseWriter, r *http.Request) {
 
 // saveDonationToDynamoDB saves donation record to DynamoDB
 func saveDonationToDynamoDB(donation types.Donation) {
-	ctx := context.Background()
-
-	item, err := attributevalue.MarshalMap(donation)
-	if err != nil {
-		log.Printf("❌ Failed to marshal donation: %v", err)
-		return
-	}
-
-	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(donationsTableName),
-		Item:      item,
-	})
-	if err != nil {
+	if err := donationRepo.Save(donation); err != nil {
 		log.Printf("❌ Failed to save donation to DynamoDB: %v", err)
 		return
 	}
@@ -1043,21 +1044,28 @@ func handleLocation(w http.ResponseWriter, r *http.Request) {
 		loc.Timestamp = time.Now()
 		loc.UserAgent = r.UserAgent()
 
-		// Store in memory cache
-		locationMutex.Lock()
-		locations[loc.DeviceID] = loc
-		locationMutex.Unlock()
+		// Share a fuzzed point from here on; the exact one is only stored sealed
+		loc, release := fuzzLocation(loc)
+
+		// Store in memory cache, versioned for /api/sync
+		loc = recordLocalLocation(loc)
 
 		// Persist to DynamoDB (appends to existing data, never deletes)
 		if useDynamoDB {
-			go saveLocationToDynamoDB(loc)
+			background.Async("save_location", func() { saveLocationToDynamoDB(loc) })
 		}
 
+		// Copy to the sharer's Solid pod if one is connected
+		mirrorLocationToPod(r, loc)
+
 		log.Printf("📍 Location updated: %s at (%.6f, %.6f) ±%.0fm",
 			loc.DeviceID, loc.Latitude, loc.Longitude, loc.Accuracy)
+		if release != nil && release.Reused {
+			log.Printf("🔒 Privacy budget spent for %s, repeating its last shared point", loc.DeviceID)
+		}
 
 		// Fetch nearby businesses from Google Maps
-		go func() {
+		background.Async("fetch_businesses", func() {
 			businesses, err := fetchNearbyBusinesses(loc.Latitude, loc.Longitude)
 			if err != nil {
 				log.Printf("⚠️  Error fetching businesses: %v", err)
@@ -1105,9 +1113,20 @@ func handleLocation(w http.ResponseWriter, r *http.Request) {
 					"",
 				)
 			}
-		}()
+		})
 
-		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+		response := map[string]interface{}{
+			"success": true,
+			"shared": map[string]float64{
+				"latitude":  loc.Latitude,
+				"longitude": loc.Longitude,
+				"accuracy":  loc.Accuracy,
+			},
+		}
+		if release != nil {
+			response["privacy_budget_remaining"] = release.Remaining
+		}
+		json.NewEncoder(w).Encode(response)
 
 	case "GET":
 		// Return all recent locations
@@ -1138,10 +1157,7 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 		errorLog.ID = fmt.Sprintf("%d", errorLog.Timestamp.UnixNano())
 
 		// Generate retrievable URL for this error log with ID and timestamp
-		baseURL := os.Getenv("BASE_URL")
-		if baseURL == "" {
-			baseURL = "https://notspies.org" // Default to production URL
-		}
+		baseURL := getBaseURL()
 		// URL format: /api/errorlogs/{id}/{timestamp}
 		// Timestamp is URL-encoded to handle special characters
 		timestampStr := url.QueryEscape(errorLog.Timestamp.Format(time.RFC3339Nano))
@@ -1162,6 +1178,7 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 		if pendingUserExperienceNote != "" {
 			errorLog.UserExperienceNote = pendingUserExperienceNote
 			errorLog.UserNoteKeywords = pendingUserNoteKeywords
+			errorLog.UserNoteSID = pendingUserNoteSID
 			userKeywords = pendingUserNoteKeywords
 			log.Printf("💬 Attached user experience note: %s", pendingUserExperienceNote)
 			if len(pendingUserNoteKeywords) > 0 {
@@ -1169,6 +1186,7 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 			}
 			pendingUserExperienceNote = "" // Clear after attaching
 			pendingUserNoteKeywords = nil
+			pendingUserNoteSID = ""
 		}
 		userExperienceNoteMutex.Unlock()
 
@@ -1202,7 +1220,8 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 
 		// Search for commercial real estate near current location (asynchronously)
 		if currentLocation != nil {
-			go func(lat, lng float64, keywords []string) {
+			lat, lng, keywords := currentLocation.Latitude, currentLocation.Longitude, userKeywords
+			background.Async("commercial_search", func() {
 				properties, governingBodies, queryLat, queryLng, err := searchCommercialRealEstate(lat, lng, keywords)
 				if err != nil {
 					log.Printf("⚠️  Error searching commercial real estate: %v", err)
@@ -1248,7 +1267,7 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 						log.Printf("🏛️  %s (%s) - %s - %s", body.Name, body.Type, body.Jurisdiction, body.Contact)
 					}
 				}
-			}(currentLocation.Latitude, currentLocation.Longitude, userKeywords)
+			})
 		}
 
 		// Attach anonymous tips from pending queue
@@ -1284,17 +1303,25 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 		errorLogMutex.Lock()
 		// Prepend new error to beginning (most recent first)
 		errorLogs = append([]types.ErrorLog{errorLog}, errorLogs...)
-		// Keep only last 50 errors in memory
-		if len(errorLogs) > 50 {
-			errorLogs = errorLogs[:50]
+		// Keep only the most recent errors in memory
+		if len(errorLogs) > appConfig.Limits.ErrorLogsInMemory {
+			errorLogs = errorLogs[:appConfig.Limits.ErrorLogsInMemory]
 		}
 		errorLogMutex.Unlock()
 
 		// Persist to DynamoDB (appends to existing data, never deletes)
 		if useDynamoDB {
-			go saveErrorLogToDynamoDB(errorLog)
+			background.Async("save_error_log", func() { saveErrorLogToDynamoDB(errorLog) })
 		}
 
+		// Pre-render the OpenGraph share image so link previews are instant
+		shareImageService.PrefetchShareImage(&errorLog, shareFormatOG)
+
+		// Keep copies of the meme and food image; the source URLs can expire
+		cacheCaseMedia(&errorLog)
+		// Listing sessions can block on the store, so copy to pods off the request path
+		background.Async("mirror_error_log", func() { mirrorErrorLogToPods(errorLog) })
+
 		log.Printf("📝 Error logged: %s", errorLog.Message)
 
 		json.NewEncoder(w).Encode(map[string]bool{"success": true})
@@ -1322,10 +1349,7 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 			// Create sanitized copy without location data and user notes
 			sanitized := make([]types.ErrorLog, len(recentLogs))
 			for i, log := range recentLogs {
-				sanitized[i] = log
-				sanitized[i].NearbyBusinesses = nil         // Hide nearby businesses (location-specific)
-				sanitized[i].UserExperienceNote = ""        // Hide user experience notes
-				sanitized[i].UserNoteKeywords = nil         // Hide user note keywords
+				sanitized[i] = redactErrorLog(log, false)
 			}
 			json.NewEncoder(w).Encode(sanitized)
 		} else {
@@ -1337,7 +1361,12 @@ func handleErrorLogs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// errErrorLogNotFound is returned by findErrorLog when no matching error log exists
+var errErrorLogNotFound = fmt.Errorf("error log not found")
+
 // handleErrorLogByID retrieves a single error log by its ID
+// Browsers and link-preview crawlers get the server-rendered case page,
+// API clients (or ?format=json) get JSON.
 func handleErrorLogByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1358,147 +1387,137 @@ func handleErrorLogByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// /api/errorlogs/{id}/comic[.png|.svg] renders the case as a comic strip
+	if len(pathParts) == 5 && strings.HasPrefix(pathParts[4], "comic") {
+		handleErrorLogComic(w, r, errorLogID, pathParts[4])
+		return
+	}
+
 	// Check if timestamp is provided (for new URL format)
 	var timestampStr string
-	hasTimestamp := len(pathParts) >= 5 && pathParts[4] != ""
-	if hasTimestamp {
+	if len(pathParts) >= 5 && pathParts[4] != "" {
 		// URL-decode the timestamp
 		timestampStr, _ = url.QueryUnescape(pathParts[4])
 	}
 
-	// Check if this is a browser request (Accept header contains text/html)
-	isBrowserRequest := strings.Contains(r.Header.Get("Accept"), "text/html")
-
-	// First check in-memory cache
-	errorLogMutex.RLock()
-	for _, log := range errorLogs {
-		if log.ID == errorLogID {
-			errorLogMutex.RUnlock()
-
-			// Determine access level and sanitize if needed
-			hasFullAccess := isAuthenticated(r)
-			hasPuzzleAccessOnly := !hasFullAccess && hasPuzzleAccess(r)
+	errorLog, err := findErrorLog(errorLogID, timestampStr)
+	if err == errErrorLogNotFound {
+		http.Error(w, "Error log not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to retrieve error log %s: %v", errorLogID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
-			if !hasFullAccess && !hasPuzzleAccessOnly {
-				// If browser request, show authentication page
-				if isBrowserRequest {
-					serveTurnstileAuthPage(w, r, errorLogID)
-					return
-				}
-				// Otherwise return JSON error for API requests
-				w.Header().Set("Content-Type", "application/json")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+	// Determine access level and sanitize if needed
+	hasFullAccess := isAuthenticated(r)
+	hasPuzzleAccessOnly := !hasFullAccess && hasPuzzleAccess(r)
+	wantsHTML := wantsCasePage(r)
 
-			// Return JSON response for authenticated requests
-			w.Header().Set("Content-Type", "application/json")
-
-			// If user only has puzzle access, sanitize the log
-			if hasPuzzleAccessOnly {
-				sanitizedLog := log
-				sanitizedLog.NearbyBusinesses = nil
-				sanitizedLog.UserExperienceNote = ""
-				sanitizedLog.UserNoteKeywords = nil
-				json.NewEncoder(w).Encode(sanitizedLog)
-			} else {
-				json.NewEncoder(w).Encode(log)
-			}
+	if !hasFullAccess && !hasPuzzleAccessOnly {
+		// Link-preview crawlers get just enough of the case for shared links to unfurl
+		if isLinkPreviewCrawler(r) {
+			serveCasePreview(w, r, *errorLog)
 			return
 		}
+		// If browser request, show authentication page
+		if wantsHTML {
+			serveTurnstileAuthPage(w, r, errorLogID)
+			return
+		}
+		// Otherwise return JSON error for API requests
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	errorLogMutex.RUnlock()
 
-	// Not found in memory, check DynamoDB if enabled
-	if useDynamoDB {
-		ctx := context.Background()
-		var errorLog types.ErrorLog
-		var err error
-
-		if hasTimestamp {
-			// Fast path: Use GetItem with both id and timestamp (composite key)
-			result, getErr := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
-				TableName: aws.String(errorLogsTableName),
-				Key: map[string]dynamodbtypes.AttributeValue{
-					"id":        &dynamodbtypes.AttributeValueMemberS{Value: errorLogID},
-					"timestamp": &dynamodbtypes.AttributeValueMemberS{Value: timestampStr},
-				},
-			})
-
-			if getErr != nil {
-				log.Printf("❌ Failed to retrieve error log from DynamoDB: %v", getErr)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-
-			if result.Item == nil {
-				http.Error(w, "Error log not found", http.StatusNotFound)
-				return
-			}
+	redacted := redactErrorLog(*errorLog, hasFullAccess)
+	if wantsHTML {
+		serveCasePage(w, r, redacted, hasFullAccess)
+		return
+	}
 
-			err = attributevalue.UnmarshalMap(result.Item, &errorLog)
-		} else {
-			// Fallback: Query by id (partition key) - requires scanning through timestamps
-			queryResult, queryErr := dynamoClient.Query(ctx, &dynamodb.QueryInput{
-				TableName:              aws.String(errorLogsTableName),
-				KeyConditionExpression: aws.String("id = :id"),
-				ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
-					":id": &dynamodbtypes.AttributeValueMemberS{Value: errorLogID},
-				},
-				Limit: aws.Int32(1),
-			})
+	// Return JSON response for authenticated requests
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(redacted)
+}
 
-			if queryErr != nil {
-				log.Printf("❌ Failed to query error log from DynamoDB: %v", queryErr)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
+// findErrorLog looks up an error log in the in-memory cache, then DynamoDB.
+// timestampStr is optional; when present DynamoDB uses the composite key fast path.
+func findErrorLog(errorLogID string, timestampStr string) (*types.ErrorLog, error) {
+	// First check in-memory cache
+	errorLogMutex.RLock()
+	for _, cached := range errorLogs {
+		if cached.ID == errorLogID {
+			errorLogMutex.RUnlock()
+			found := cached
+			return &found, nil
+		}
+	}
+	errorLogMutex.RUnlock()
 
-			if len(queryResult.Items) == 0 {
-				http.Error(w, "Error log not found", http.StatusNotFound)
-				return
-			}
+	// Not found in memory and DynamoDB not enabled
+	if !useDynamoDB {
+		return nil, errErrorLogNotFound
+	}
 
-			err = attributevalue.UnmarshalMap(queryResult.Items[0], &errorLog)
-		}
+	ctx := context.Background()
+	var errorLog types.ErrorLog
+
+	if timestampStr != "" {
+		// Fast path: Use GetItem with both id and timestamp (composite key)
+		result, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(errorLogsTableName),
+			Key: map[string]dynamodbtypes.AttributeValue{
+				"id":        &dynamodbtypes.AttributeValueMemberS{Value: errorLogID},
+				"timestamp": &dynamodbtypes.AttributeValueMemberS{Value: timestampStr},
+			},
+		})
 		if err != nil {
-			log.Printf("❌ Failed to unmarshal error log: %v", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("failed to retrieve error log from DynamoDB: %w", err)
 		}
-
-		// Determine access level and sanitize if needed
-		hasFullAccess := isAuthenticated(r)
-		hasPuzzleAccessOnly := !hasFullAccess && hasPuzzleAccess(r)
-
-		if !hasFullAccess && !hasPuzzleAccessOnly {
-			// If browser request, show authentication page
-			if isBrowserRequest {
-				serveTurnstileAuthPage(w, r, errorLogID)
-				return
-			}
-			// Otherwise return JSON error for API requests
-			w.Header().Set("Content-Type", "application/json")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+		if result.Item == nil {
+			return nil, errErrorLogNotFound
 		}
-
-		// Return JSON response for authenticated requests
-		w.Header().Set("Content-Type", "application/json")
-
-		// If user only has puzzle access, sanitize the log
-		if hasPuzzleAccessOnly {
-			errorLog.NearbyBusinesses = nil
-			errorLog.UserExperienceNote = ""
-			errorLog.UserNoteKeywords = nil
+		if err := attributevalue.UnmarshalMap(result.Item, &errorLog); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal error log: %w", err)
 		}
+		return &errorLog, nil
+	}
 
-		json.NewEncoder(w).Encode(errorLog)
-		return
+	// Fallback: Query by id (partition key) - requires scanning through timestamps
+	queryResult, err := dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(errorLogsTableName),
+		KeyConditionExpression: aws.String("id = :id"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":id": &dynamodbtypes.AttributeValueMemberS{Value: errorLogID},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error log from DynamoDB: %w", err)
+	}
+	if len(queryResult.Items) == 0 {
+		return nil, errErrorLogNotFound
 	}
+	if err := attributevalue.UnmarshalMap(queryResult.Items[0], &errorLog); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal error log: %w", err)
+	}
+	return &errorLog, nil
+}
 
-	// Not found in memory and DynamoDB not enabled
-	http.Error(w, "Error log not found", http.StatusNotFound)
+// redactErrorLog strips location-specific data and user notes unless the caller has full access
+func redactErrorLog(errorLog types.ErrorLog, hasFullAccess bool) types.ErrorLog {
+	if hasFullAccess {
+		return errorLog
+	}
+	errorLog.NearbyBusinesses = nil  // Hide nearby businesses (location-specific)
+	errorLog.UserExperienceNote = "" // Hide user experience notes
+	errorLog.UserNoteKeywords = nil  // Hide user note keywords
+	errorLog.UserNoteSID = ""        // Hide which SMS the note came from
+	return errorLog
 }
 
 func handleBusinesses(w http.ResponseWriter, r *http.Request) {
@@ -1648,8 +1667,12 @@ func handleTwilioWebhook(w http.ResponseWriter, r *http.Request) {
 	userExperienceNoteMutex.Lock()
 	pendingUserExperienceNote = messageBody
 	pendingUserNoteKeywords = keywords
+	pendingUserNoteSID = messageSid
 	userExperienceNoteMutex.Unlock()
 
+	// Keep the message (with a hash of the sender) so it can be exported or erased later
+	recordSMSMessage(messageSid, messageFrom, messageBody, keywords)
+
 	log.Printf("📱 Received SMS from %s (SID: %s): %s", messageFrom, messageSid, messageBody)
 	log.Printf("💬 Stored user experience note, will attach to next error log")
 	if len(keywords) > 0 {
@@ -1762,14 +1785,8 @@ func handleTips(w http.ResponseWriter, r *http.Request) {
 			IPAddress:        getClientIP(r),
 		}
 
-		// Store in memory cache
-		anonymousTipsMutex.Lock()
-		anonymousTips = append(anonymousTips, tip)
-		// Keep only last 100 tips in memory
-		if len(anonymousTips) > 100 {
-			anonymousTips = anonymousTips[len(anonymousTips)-100:]
-		}
-		anonymousTipsMutex.Unlock()
+		// Store in memory cache, versioned for /api/sync
+		tip = recordLocalTip(tip)
 
 		// Add to pending tip queue
 		pendingTipMutex.Lock()
@@ -1778,9 +1795,12 @@ func handleTips(w http.ResponseWriter, r *http.Request) {
 
 		// Persist to DynamoDB
 		if useDynamoDB {
-			go saveTipToDynamoDB(tip)
+			background.Async("save_tip", func() { saveTipToDynamoDB(tip) })
 		}
 
+		// Copy to the submitter's Solid pod if one is connected
+		mirrorTipToPod(r, tip)
+
 		log.Printf("📝 Anonymous tip submitted: %s (status: %s, user: %s)", tip.ID, tip.ModerationStatus, userHash)
 
 		// Update last interaction context
@@ -1892,7 +1912,7 @@ func serveTurnstileAuthPage(w http.ResponseWriter, r *http.Request, errorLogID s
 	w.Header().Set("Content-Type", "text/html")
 	w.WriteHeader(http.StatusUnauthorized)
 
-	turnstileSiteKey := os.Getenv("TURNSTILE_SITE_KEY")
+	turnstileSiteKey := appConfig.Auth.TurnstileSiteKey
 	if turnstileSiteKey == "" {
 		// Use Cloudflare's test site key that forces interactive challenge
 		turnstileSiteKey = "2x00000000000000000000AB"
@@ -2172,34 +2192,51 @@ func serveTurnstileAuthPage(w http.ResponseWriter, r *http.Request, errorLogID s
         };
     </script>
 </body>
-</html>`, turnstileSiteKey, errorLogID, errorLogID, errorLogID, errorLogID)
+</html>`, errorLogID, turnstileSiteKey, errorLogID, errorLogID, errorLogID, errorLogID)
 
 	w.Write([]byte(html))
 }
 
-func cleanupOldLocations() {
-	ticker := time.NewTicker(1 * time.Hour)
+// cleanupOldLocations drops locations not updated within limits.location_max_age
+func cleanupOldLocations(ctx context.Context) error {
+	interval := appConfig.Intervals.LocationCleanup
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	heartbeat := backgroundHeartbeat("location_cleanup", interval)
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		heartbeat.Beat()
 		locationMutex.Lock()
 		now := time.Now()
 		for id, loc := range locations {
-			if now.Sub(loc.Timestamp) > 24*time.Hour {
+			if now.Sub(loc.Timestamp) > appConfig.Limits.LocationMaxAge {
 				delete(locations, id)
 				log.Printf("🗑️  Removed old location: %s", id)
 			}
 		}
 		locationMutex.Unlock()
+
+		// Privacy budgets and last releases of devices that stopped sharing go too
+		if locationFuzzer != nil {
+			if expired := locationFuzzer.Expire(appConfig.Limits.LocationMaxAge, now); expired > 0 {
+				log.Printf("🗑️  Expired location privacy state of %d device(s)", expired)
+			}
+		}
 	}
 }
 
 func serveHTML(w http.ResponseWriter, r *http.Request) {
 	tmpl := template.Must(template.New("index").Parse(indexHTML))
-	stripePublishableKey := os.Getenv("STRIPE_PUBLISHABLE_KEY")
+	stripePublishableKey := appConfig.APIKeys.StripePublishable
 	data := map[string]interface{}{
 		"GoogleMapsAPIKey":       googleMapsAPIKey,
 		"StripePublishableKey":   stripePublishableKey,
+		"TipMaxLength":           tipMaxLength,
 	}
 	if err := tmpl.Execute(w, data); err != nil {
 		log.Printf("❌ Error executing template: %v", err)
@@ -2276,14 +2313,17 @@ func initializeDynamoDB() {
 	ctx := context.Background()
 
 	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(appConfig.DynamoDB.Region))
 	if err != nil {
 		log.Printf("⚠️  Failed to load AWS config: %v", err)
 		return
 	}
 
+	// Sensitive fields are sealed before they reach the tables
+	fieldEncoder = initializeFieldEncryption(ctx, cfg)
+
 	// Create DynamoDB client
-	dynamoClient = dynamodb.NewFromConfig(cfg)
+	dynamoClient = dynamodb.NewFromConfig(cfg, instrumentDynamoDB)
 
 	// Test connection by describing one of the tables (read-only operation)
 	_, err = dynamoClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
@@ -2298,9 +2338,12 @@ func initializeDynamoDB() {
 
 	// Initialize repositories
 	errorLogRepo = storage.NewErrorLogDynamoDBRepository(dynamoClient, errorLogsTableName)
-	locationRepo = storage.NewLocationDynamoDBRepository(dynamoClient, locationsTableName)
+	locationRepo = storage.NewLocationDynamoDBRepository(dynamoClient, locationsTableName, fieldEncoder)
 	commercialRepo = storage.NewCommercialDynamoDBRepository(dynamoClient, commercialRealEstateTableName)
-	tipRepo = storage.NewTipDynamoDBRepository(dynamoClient, anonymousTipsTableName)
+	tipRepo = storage.NewTipDynamoDBRepository(dynamoClient, anonymousTipsTableName, fieldEncoder)
+	donationRepo = storage.NewDonationDynamoDBRepository(dynamoClient, donationsTableName, fieldEncoder)
+	smsRepo = storage.NewSMSDynamoDBRepository(dynamoClient, smsMessagesTableName, fieldEncoder)
+	privacyAuditRepo = storage.NewPrivacyAuditDynamoDBRepository(dynamoClient, privacyAuditTableName)
 
 	log.Printf("💾 DynamoDB repositories initialized")
 }
@@ -2310,6 +2353,7 @@ func initializeTipSystem() {
 	// Initialize identity manager with encryption key
 	if tipEncryptionKey == "" {
 		log.Printf("⚠️  TIP_ENCRYPTION_KEY not set, generating random key (will not persist across restarts)")
+		tipKeyEphemeral = true
 		// Generate a random 32-byte key
 		randomKey := make([]byte, 32)
 		_, err := rand.Read(randomKey)
@@ -2334,7 +2378,7 @@ func initializeTipSystem() {
 	}
 
 	var err error
-	identityManager, err = NewUserIdentityManager(keyBytes)
+	identityManager, err = NewUserIdentityManager(keyBytes, fieldEncoder)
 	if err != nil {
 		log.Fatalf("❌ Failed to create identity manager: %v", err)
 	}
@@ -2380,28 +2424,11 @@ func saveErrorLogToDynamoDB(errorLog types.ErrorLog) {
 	log.Printf("💾 Error log saved to DynamoDB: %s", errorLog.ID)
 }
 
-// saveLocationToDynamoDB appends location to DynamoDB (never deletes existing data)
+// saveLocationToDynamoDB writes a device's latest location to DynamoDB
 func saveLocationToDynamoDB(location types.Location) {
-	ctx := context.Background()
-
-	// Marshal the location to DynamoDB attribute values
-	item, err := attributevalue.MarshalMap(location)
-	if err != nil {
-		log.Printf("❌ Failed to marshal location: %v", err)
-		return
-	}
-
-	// Put item into DynamoDB (appends new record, preserves all existing data)
-	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(locationsTableName),
-		Item:      item,
-	})
-	if err != nil {
+	if err := locationRepo.Save(location); err != nil {
 		log.Printf("❌ Failed to save location to DynamoDB: %v", err)
-		return
 	}
-
-	log.Printf("💾 Location saved to DynamoDB: %s", location.DeviceID)
 }
 
 // loadExistingData loads existing records from DynamoDB on startup (preserves all data)
@@ -2412,7 +2439,7 @@ func loadExistingData() {
 	log.Printf("📥 Loading error logs from DynamoDB...")
 	errorLogsResult, err := dynamoClient.Scan(ctx, &dynamodb.ScanInput{
 		TableName: aws.String(errorLogsTableName),
-		Limit:     aws.Int32(50), // Load last 50 errors for in-memory cache
+		Limit:     aws.Int32(int32(appConfig.Limits.ErrorLogsInMemory)), // Load enough errors for the in-memory cache
 	})
 	if err != nil {
 		log.Printf("⚠️  Failed to load error logs: %v", err)
@@ -2427,9 +2454,9 @@ func loadExistingData() {
 				return loadedErrorLogs[i].Timestamp.After(loadedErrorLogs[j].Timestamp)
 			})
 
-			// Keep only last 50 in memory cache
-			if len(loadedErrorLogs) > 50 {
-				loadedErrorLogs = loadedErrorLogs[:50]
+			// Keep only the most recent in memory cache
+			if len(loadedErrorLogs) > appConfig.Limits.ErrorLogsInMemory {
+				loadedErrorLogs = loadedErrorLogs[:appConfig.Limits.ErrorLogsInMemory]
 			}
 
 			errorLogMutex.Lock()
@@ -2440,38 +2467,25 @@ func loadExistingData() {
 		}
 	}
 
-	// Load locations from last 24 hours
+	// Load locations still within the retention window
 	log.Printf("📥 Loading recent locations from DynamoDB...")
-	locationsResult, err := dynamoClient.Scan(ctx, &dynamodb.ScanInput{
-		TableName: aws.String(locationsTableName),
-	})
+	loadedLocations, err := locationRepo.GetAll()
 	if err != nil {
 		log.Printf("⚠️  Failed to load locations: %v", err)
 	} else {
-		var loadedLocations []types.Location
-		err = attributevalue.UnmarshalListOfMaps(locationsResult.Items, &loadedLocations)
-		if err != nil {
-			log.Printf("⚠️  Failed to unmarshal locations: %v", err)
-		} else {
-			// Filter to last 24 hours and get most recent per device
-			now := time.Now()
-			recentLocations := make(map[string]types.Location)
-
-			for _, loc := range loadedLocations {
-				if now.Sub(loc.Timestamp) <= 24*time.Hour {
-					// Keep the most recent location for each device
-					if existing, ok := recentLocations[loc.DeviceID]; !ok || loc.Timestamp.After(existing.Timestamp) {
-						recentLocations[loc.DeviceID] = loc
-					}
-				}
-			}
-
-			locationMutex.Lock()
-			locations = recentLocations
-			locationMutex.Unlock()
+		// Filter to the retention window; each goes back into the sync log under its stored
+		// version, merging with anything posted since startup
+		now := time.Now()
+		loaded := 0
 
-			log.Printf("✅ Loaded %d locations from DynamoDB into memory", len(recentLocations))
+		for _, loc := range loadedLocations {
+			if now.Sub(loc.Timestamp) <= appConfig.Limits.LocationMaxAge {
+				restoreSyncLocation(loc)
+				loaded++
+			}
 		}
+
+		log.Printf("✅ Loaded %d locations from DynamoDB into memory", loaded)
 	}
 }
 
@@ -3229,9 +3243,9 @@ const indexHTML = `<!DOCTYPE html>
                 <div style="background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); padding: 20px; border-radius: 12px; margin-top: 20px; border: 3px solid var(--swiss-black); box-shadow: 4px 4px 0px rgba(0, 0, 0, 0.2);">
                     <h3 style="color: var(--swiss-white); margin-bottom: 15px; text-transform: uppercase; letter-spacing: 0.1em;">🕵️ Report Not Spy Work</h3>
                     <p style="color: rgba(255,255,255,0.9); font-size: 13px; margin-bottom: 15px; font-family: 'Courier New', monospace;">Anonymously submit tips about suspicious non-espionage activities</p>
-                    <textarea id="tip-content" placeholder="Describe what you observed..." maxlength="1000" style="width: 100%; min-height: 100px; padding: 12px; border: 2px solid var(--swiss-black); border-radius: 8px; font-size: 14px; font-family: inherit; resize: vertical; box-shadow: 2px 2px 0px rgba(0, 0, 0, 0.1);"></textarea>
+                    <textarea id="tip-content" placeholder="Describe what you observed..." maxlength="{{.TipMaxLength}}" style="width: 100%; min-height: 100px; padding: 12px; border: 2px solid var(--swiss-black); border-radius: 8px; font-size: 14px; font-family: inherit; resize: vertical; box-shadow: 2px 2px 0px rgba(0, 0, 0, 0.1);"></textarea>
                     <div style="display: flex; justify-content: space-between; align-items: center; margin-top: 10px; color: rgba(255,255,255,0.8); font-size: 12px;">
-                        <span><span id="char-count">0</span>/1000</span>
+                        <span><span id="char-count">0</span>/{{.TipMaxLength}}</span>
                         <span id="rate-limit-info" style="font-family: 'Courier New', monospace;"></span>
                     </div>
                     <button onclick="submitTip()" style="margin-top: 12px; background: linear-gradient(135deg, var(--pop-hot-pink) 0%, var(--memphis-pink) 100%);">📝 Submit Anonymous Tip</button>
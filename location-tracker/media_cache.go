@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"location-tracker/storage"
+	"location-tracker/types"
+)
+
+// mediaBlobKey returns the blob key of the cached copy of a remote image.
+// Meme and food URLs (DALL-E, Unsplash) can expire, so copies are kept in the blob store.
+func mediaBlobKey(mediaURL string) string {
+	return storage.ContentHashKey("media", "", mediaURL)
+}
+
+// fetchCachedMedia returns a remote image from the blob store, downloading and storing it on a miss
+func fetchCachedMedia(ctx context.Context, mediaURL string) ([]byte, string, error) {
+	if blobStore == nil {
+		return nil, "", fmt.Errorf("blob store not initialized")
+	}
+
+	key := mediaBlobKey(mediaURL)
+	data, contentType, err := blobStore.Get(ctx, key)
+	if err == nil {
+		return data, contentType, nil
+	}
+	if !errors.Is(err, storage.ErrBlobNotFound) {
+		log.Printf("⚠️  Media cache read failed for %s: %v", mediaURL, err)
+	}
+
+	data, err = downloadImageData(mediaURL)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType = http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("unexpected media type %s", contentType)
+	}
+
+	if err := blobStore.Put(ctx, key, data, contentType); err != nil {
+		log.Printf("⚠️  Failed to cache media %s: %v", mediaURL, err)
+	}
+	return data, contentType, nil
+}
+
+// cacheCaseMedia copies a new case's meme and food image into the blob store in the background
+func cacheCaseMedia(errorLog *types.ErrorLog) {
+	urls := make([]string, 0, 2)
+	for _, mediaURL := range []string{errorLog.MemeURL, errorLog.FoodImageURL} {
+		if mediaURL != "" {
+			urls = append(urls, mediaURL)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	background.Async("media_prefetch", func() {
+		for _, mediaURL := range urls {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, _, err := fetchCachedMedia(ctx, mediaURL); err != nil {
+				log.Printf("⚠️  Failed to cache case media %s: %v", mediaURL, err)
+			}
+			cancel()
+		}
+	})
+}
@@ -0,0 +1,316 @@
+/*
+# Module: mobility/generator.go
+Deterministic trajectory generator: random waypoint and Lévy flight mobility models with dwell times and GPS noise.
+
+## Linked Modules
+- [mobility/profiles](./profiles.go) - Speed profiles, POIs and dwell times
+- [mobility/gpx](./gpx.go) - GPX export of generated trajectories
+
+## Tags
+simulation, mobility, location, random-waypoint, levy-flight
+
+## Exports
+Model, RandomWaypoint, LevyFlight, Config, Point, Generate
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "mobility/generator.go" ;
+    code:description "Deterministic trajectory generator with random waypoint and Lévy flight models" ;
+    code:linksTo [
+        code:name "mobility/profiles" ;
+        code:path "./profiles.go" ;
+        code:relationship "Speed profiles, POIs and dwell times"
+    ], [
+        code:name "mobility/gpx" ;
+        code:path "./gpx.go" ;
+        code:relationship "GPX export of generated trajectories"
+    ] ;
+    code:exports :Model, :RandomWaypoint, :LevyFlight, :Config, :Point, :Generate ;
+    code:tags "simulation", "mobility", "location", "random-waypoint", "levy-flight" .
+<!-- End LinkedDoc RDF -->
+*/
+package mobility
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Model is a mobility model choosing where to go next.
+type Model string
+
+const (
+	// RandomWaypoint picks destinations uniformly in the area (or a POI), travels there in
+	// a straight line and pauses.
+	RandomWaypoint Model = "waypoint"
+	// LevyFlight takes steps whose lengths and pauses follow heavy-tailed (Pareto)
+	// distributions: mostly short hops with occasional long trips, like human movement.
+	LevyFlight Model = "levy"
+)
+
+// metersPerDegree is the length of one degree of latitude
+const metersPerDegree = 111320.0
+
+// Config describes a simulated person. Equal configs produce equal trajectories.
+type Config struct {
+	Seed  int64
+	Model Model
+	Mode  Mode
+
+	// Latitude and Longitude are the starting point and the centre of the area; the
+	// trajectory stays within Radius metres of it.
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+
+	// POIs are places to stop at; POIProbability is the chance a destination is a POI
+	// rather than an arbitrary point (default 0.7 when POIs are given).
+	POIs           []POI
+	POIProbability float64
+
+	Start    time.Time
+	Duration time.Duration
+	Interval time.Duration // time between samples
+
+	// GPSNoise is the standard deviation of position error per axis, in metres.
+	GPSNoise float64
+
+	// LevyAlpha is the Pareto tail exponent for step lengths and pauses (default 1.6);
+	// LevyMinStep is the shortest step in metres (default 50).
+	LevyAlpha   float64
+	LevyMinStep float64
+}
+
+// Point is one sample of a trajectory.
+type Point struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // reported accuracy radius, metres
+	Speed     float64 // true speed, metres per second
+	Place     string  // POI name while dwelling at one
+}
+
+// leg is a piece of the true path: travel from one point to another, or stay put
+type leg struct {
+	start, end       time.Time
+	fromLat, fromLng float64
+	toLat, toLng     float64
+	speed            float64
+	place            string
+}
+
+// Generate returns a trajectory sampled every Interval from Start for Duration.
+func Generate(cfg Config) ([]Point, error) {
+	cfg, err := withDefaults(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	profile, _ := Profile(cfg.Mode)
+
+	end := cfg.Start.Add(cfg.Duration)
+	var legs []leg
+	lat, lng := cfg.Latitude, cfg.Longitude
+	t := cfg.Start
+	for t.Before(end) {
+		toLat, toLng, poi := nextDestination(cfg, rng, lat, lng)
+
+		// Travel
+		speed := truncatedNormal(rng, profile.Mean, profile.StdDev, profile.Min, profile.Max)
+		travel := time.Duration(distance(lat, lng, toLat, toLng) / speed * float64(time.Second))
+		legs = append(legs, leg{start: t, end: t.Add(travel), fromLat: lat, fromLng: lng, toLat: toLat, toLng: toLng, speed: speed})
+		t = t.Add(travel)
+		lat, lng = toLat, toLng
+
+		// Dwell
+		dwell := dwellTime(cfg, rng, poi)
+		if travel+dwell <= 0 {
+			// Already there and no pause drawn; make sure time moves on
+			dwell = cfg.Interval
+		}
+		stay := leg{start: t, end: t.Add(dwell), fromLat: lat, fromLng: lng, toLat: lat, toLng: lng}
+		if poi != nil {
+			stay.place = poi.Name
+		}
+		legs = append(legs, stay)
+		t = t.Add(dwell)
+	}
+
+	var points []Point
+	current := 0
+	for at := cfg.Start; !at.After(end); at = at.Add(cfg.Interval) {
+		for current < len(legs)-1 && !at.Before(legs[current].end) {
+			current++
+		}
+		l := legs[current]
+		trueLat, trueLng := l.fromLat, l.fromLng
+		if span := l.end.Sub(l.start); span > 0 {
+			f := math.Min(1, float64(at.Sub(l.start))/float64(span))
+			trueLat += (l.toLat - l.fromLat) * f
+			trueLng += (l.toLng - l.fromLng) * f
+		}
+
+		noisyLat, noisyLng := offset(trueLat, trueLng, rng.NormFloat64()*cfg.GPSNoise, rng.NormFloat64()*cfg.GPSNoise)
+		points = append(points, Point{
+			Time:      at,
+			Latitude:  noisyLat,
+			Longitude: noisyLng,
+			// Browsers report a ~68% radius; for 2-D Gaussian error that's 1.5 sigma
+			Accuracy: math.Round(cfg.GPSNoise*1.5*10) / 10,
+			Speed:    l.speed,
+			Place:    l.place,
+		})
+	}
+	return points, nil
+}
+
+func withDefaults(cfg Config) (Config, error) {
+	switch cfg.Model {
+	case RandomWaypoint, LevyFlight:
+	default:
+		return cfg, fmt.Errorf("unknown mobility model %q (expected %s or %s)", cfg.Model, RandomWaypoint, LevyFlight)
+	}
+	if _, ok := Profile(cfg.Mode); !ok {
+		return cfg, fmt.Errorf("unknown travel mode %q (expected walk, bike or drive)", cfg.Mode)
+	}
+	switch {
+	case cfg.Latitude < -85 || cfg.Latitude > 85 || cfg.Longitude < -180 || cfg.Longitude > 180:
+		return cfg, fmt.Errorf("start point out of range")
+	case cfg.Radius <= 0:
+		return cfg, fmt.Errorf("radius must be positive")
+	case cfg.Duration <= 0 || cfg.Interval <= 0:
+		return cfg, fmt.Errorf("duration and interval must be positive")
+	case cfg.GPSNoise < 0:
+		return cfg, fmt.Errorf("GPS noise can't be negative")
+	}
+
+	// Only POIs inside the area are visited
+	var pois []POI
+	for _, poi := range cfg.POIs {
+		if distance(cfg.Latitude, cfg.Longitude, poi.Latitude, poi.Longitude) <= cfg.Radius {
+			pois = append(pois, poi)
+		}
+	}
+	cfg.POIs = pois
+
+	if cfg.POIProbability == 0 {
+		cfg.POIProbability = 0.7
+	}
+	if cfg.LevyAlpha == 0 {
+		cfg.LevyAlpha = 1.6
+	}
+	if cfg.LevyMinStep == 0 {
+		cfg.LevyMinStep = 50
+	}
+	if cfg.Start.IsZero() {
+		cfg.Start = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	}
+	return cfg, nil
+}
+
+// nextDestination picks where to go from lat/lng, and the POI there if any
+func nextDestination(cfg Config, rng *rand.Rand, lat, lng float64) (float64, float64, *POI) {
+	usePOI := len(cfg.POIs) > 0 && rng.Float64() < cfg.POIProbability
+
+	if cfg.Model == RandomWaypoint {
+		if usePOI {
+			poi := &cfg.POIs[rng.Intn(len(cfg.POIs))]
+			return poi.Latitude, poi.Longitude, poi
+		}
+		// Uniform over the disk
+		r := cfg.Radius * math.Sqrt(rng.Float64())
+		theta := 2 * math.Pi * rng.Float64()
+		toLat, toLng := offset(cfg.Latitude, cfg.Longitude, r*math.Cos(theta), r*math.Sin(theta))
+		return toLat, toLng, nil
+	}
+
+	// Lévy flight: a Pareto step in a random direction, kept inside the area
+	step := math.Min(pareto(rng, cfg.LevyMinStep, cfg.LevyAlpha), 2*cfg.Radius)
+	var toLat, toLng float64
+	for attempt := 0; ; attempt++ {
+		theta := 2 * math.Pi * rng.Float64()
+		toLat, toLng = offset(lat, lng, step*math.Cos(theta), step*math.Sin(theta))
+		if distance(cfg.Latitude, cfg.Longitude, toLat, toLng) <= cfg.Radius {
+			break
+		}
+		if attempt == 8 {
+			// Step back towards the centre instead
+			toLat, toLng = towards(lat, lng, cfg.Latitude, cfg.Longitude, step)
+			break
+		}
+	}
+	if usePOI {
+		// Stop at the POI nearest to where the step landed
+		poi := nearestPOI(cfg.POIs, toLat, toLng)
+		return poi.Latitude, poi.Longitude, poi
+	}
+	return toLat, toLng, nil
+}
+
+// dwellTime is how long to stay at a destination
+func dwellTime(cfg Config, rng *rand.Rand, poi *POI) time.Duration {
+	if poi != nil {
+		min, max := DwellRange(poi.Type)
+		return min + time.Duration(rng.Int63n(int64(max-min)+1))
+	}
+	if cfg.Model == LevyFlight {
+		// Heavy-tailed pauses from 30 s, capped at an hour
+		pause := math.Min(pareto(rng, 30, cfg.LevyAlpha), 3600)
+		return time.Duration(pause * float64(time.Second))
+	}
+	return time.Duration(rng.Int63n(int64(5*time.Minute) + 1))
+}
+
+func nearestPOI(pois []POI, lat, lng float64) *POI {
+	best := &pois[0]
+	bestDistance := distance(lat, lng, best.Latitude, best.Longitude)
+	for i := range pois[1:] {
+		if d := distance(lat, lng, pois[i+1].Latitude, pois[i+1].Longitude); d < bestDistance {
+			best, bestDistance = &pois[i+1], d
+		}
+	}
+	return best
+}
+
+// pareto draws from a Pareto distribution with minimum xmin and tail exponent alpha
+func pareto(rng *rand.Rand, xmin, alpha float64) float64 {
+	return xmin * math.Pow(1-rng.Float64(), -1/alpha)
+}
+
+func truncatedNormal(rng *rand.Rand, mean, stddev, min, max float64) float64 {
+	for i := 0; i < 16; i++ {
+		if v := mean + rng.NormFloat64()*stddev; v >= min && v <= max {
+			return v
+		}
+	}
+	return mean
+}
+
+// offset moves lat/lng by east and north metres
+func offset(lat, lng, east, north float64) (float64, float64) {
+	return lat + north/metersPerDegree, lng + east/(metersPerDegree*math.Cos(lat*math.Pi/180))
+}
+
+// towards moves from lat/lng up to step metres in the direction of toLat/toLng
+func towards(lat, lng, toLat, toLng, step float64) (float64, float64) {
+	d := distance(lat, lng, toLat, toLng)
+	if d <= step {
+		return toLat, toLng
+	}
+	f := step / d
+	return lat + (toLat-lat)*f, lng + (toLng-lng)*f
+}
+
+// distance is the haversine distance in metres
+func distance(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000.0
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
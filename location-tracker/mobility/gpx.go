@@ -0,0 +1,98 @@
+/*
+# Module: mobility/gpx.go
+GPX 1.1 export of generated trajectories.
+
+## Linked Modules
+- [mobility/generator](./generator.go) - Trajectory points to export
+
+## Tags
+simulation, mobility, gpx, export
+
+## Exports
+WriteGPX
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "mobility/gpx.go" ;
+    code:description "GPX 1.1 export of generated trajectories" ;
+    code:linksTo [
+        code:name "mobility/generator" ;
+        code:path "./generator.go" ;
+        code:relationship "Trajectory points to export"
+    ] ;
+    code:exports :WriteGPX ;
+    code:tags "simulation", "mobility", "gpx", "export" .
+<!-- End LinkedDoc RDF -->
+*/
+package mobility
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type gpxDocument struct {
+	XMLName  xml.Name    `xml:"gpx"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Version  string      `xml:"version,attr"`
+	Creator  string      `xml:"creator,attr"`
+	Metadata gpxMetadata `xml:"metadata"`
+	Track    gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name"`
+	Segment gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+	Name string  `xml:"name,omitempty"`
+}
+
+// WriteGPX writes points as a single-segment GPX 1.1 track. Points dwelling at a POI
+// carry its name.
+func WriteGPX(w io.Writer, name string, points []Point) error {
+	doc := gpxDocument{
+		Xmlns:    "http://www.topografix.com/GPX/1/1",
+		Version:  "1.1",
+		Creator:  "location-tracker",
+		Metadata: gpxMetadata{Name: name},
+		Track:    gpxTrack{Name: name},
+	}
+	if len(points) > 0 {
+		doc.Metadata.Time = points[0].Time.UTC().Format(time.RFC3339)
+	}
+	for _, p := range points {
+		doc.Track.Segment.Points = append(doc.Track.Segment.Points, gpxPoint{
+			Lat:  p.Latitude,
+			Lon:  p.Longitude,
+			Time: p.Time.UTC().Format(time.RFC3339),
+			Name: p.Place,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,121 @@
+/*
+# Module: mobility/profiles.go
+Travel modes, speed profiles and dwell times at points of interest for simulated trajectories.
+
+## Linked Modules
+- [mobility/generator](./generator.go) - Trajectory generator using the profiles
+- [types/business](../types/business.go) - Cached businesses used as points of interest
+
+## Tags
+simulation, mobility, location
+
+## Exports
+Mode, Walk, Bike, Drive, SpeedProfile, Profile, POI, POIsFromBusinesses, DwellRange
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "mobility/profiles.go" ;
+    code:description "Travel modes, speed profiles and dwell times" ;
+    code:linksTo [
+        code:name "mobility/generator" ;
+        code:path "./generator.go" ;
+        code:relationship "Trajectory generator using the profiles"
+    ], [
+        code:name "types/business" ;
+        code:path "../types/business.go" ;
+        code:relationship "Cached businesses used as points of interest"
+    ] ;
+    code:exports :Mode, :Walk, :Bike, :Drive, :SpeedProfile, :Profile, :POI, :POIsFromBusinesses, :DwellRange ;
+    code:tags "simulation", "mobility", "location" .
+<!-- End LinkedDoc RDF -->
+*/
+package mobility
+
+import (
+	"time"
+
+	"location-tracker/types"
+)
+
+// Mode is a way of travelling between places.
+type Mode string
+
+const (
+	Walk  Mode = "walk"
+	Bike  Mode = "bike"
+	Drive Mode = "drive"
+)
+
+// SpeedProfile describes travel speeds in metres per second. Each leg of a trip draws
+// its speed from a normal distribution truncated to [Min, Max].
+type SpeedProfile struct {
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+}
+
+// speedProfiles are typical urban speeds; driving includes stops at lights
+var speedProfiles = map[Mode]SpeedProfile{
+	Walk:  {Mean: 1.4, StdDev: 0.25, Min: 0.6, Max: 2.2},
+	Bike:  {Mean: 4.5, StdDev: 1.0, Min: 2.0, Max: 8.0},
+	Drive: {Mean: 11.0, StdDev: 4.0, Min: 3.0, Max: 25.0},
+}
+
+// Profile returns the speed profile for a travel mode.
+func Profile(mode Mode) (SpeedProfile, bool) {
+	profile, ok := speedProfiles[mode]
+	return profile, ok
+}
+
+// POI is a place a simulated person may stop at.
+type POI struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// POIsFromBusinesses turns cached nearby businesses into points of interest.
+func POIsFromBusinesses(businesses []types.Business) []POI {
+	pois := make([]POI, 0, len(businesses))
+	for _, b := range businesses {
+		if b.Location.Lat == 0 && b.Location.Lng == 0 {
+			continue
+		}
+		pois = append(pois, POI{Name: b.Name, Type: b.Type, Latitude: b.Location.Lat, Longitude: b.Location.Lng})
+	}
+	return pois
+}
+
+// dwellRanges are plausible visit lengths by business type (the types BusinessService reports)
+var dwellRanges = map[string][2]time.Duration{
+	"restaurant":    {30 * time.Minute, 90 * time.Minute},
+	"cafe":          {10 * time.Minute, 45 * time.Minute},
+	"bar":           {30 * time.Minute, 2 * time.Hour},
+	"food":          {10 * time.Minute, 40 * time.Minute},
+	"store":         {5 * time.Minute, 30 * time.Minute},
+	"shop":          {5 * time.Minute, 30 * time.Minute},
+	"shopping_mall": {30 * time.Minute, 2 * time.Hour},
+	"park":          {15 * time.Minute, 90 * time.Minute},
+	"museum":        {45 * time.Minute, 3 * time.Hour},
+	"library":       {30 * time.Minute, 2 * time.Hour},
+	"school":        {1 * time.Hour, 6 * time.Hour},
+	"hospital":      {30 * time.Minute, 3 * time.Hour},
+	"pharmacy":      {5 * time.Minute, 15 * time.Minute},
+	"bank":          {5 * time.Minute, 20 * time.Minute},
+	"atm":           {1 * time.Minute, 5 * time.Minute},
+	"post_office":   {5 * time.Minute, 20 * time.Minute},
+	"gas_station":   {3 * time.Minute, 10 * time.Minute},
+	"parking":       {1 * time.Minute, 5 * time.Minute},
+}
+
+// DwellRange returns how long a visit to a place of this business type lasts, at least
+// and at most. Unknown types get 5-30 minutes.
+func DwellRange(businessType string) (time.Duration, time.Duration) {
+	if r, ok := dwellRanges[businessType]; ok {
+		return r[0], r[1]
+	}
+	return 5 * time.Minute, 30 * time.Minute
+}
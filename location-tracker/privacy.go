@@ -0,0 +1,761 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"location-tracker/replica"
+	"location-tracker/types"
+)
+
+const (
+	privacyMaxBodyBytes   = 64 << 10
+	privacyMaxIdentifiers = 100
+	privacyAuditInMemory  = 200
+)
+
+var (
+	// SMS notes received since startup (keep the last limits.sms_messages_in_memory); older ones are in DynamoDB
+	smsMessages      = make([]types.SMSMessage, 0)
+	smsMessagesMutex sync.RWMutex
+
+	// Export and erasure audit trail, oldest first; persisted when DynamoDB is available
+	privacyAudit      []types.PrivacyAuditEntry
+	privacyAuditMutex sync.RWMutex
+
+	// erasureMutex runs one erasure at a time, so two jobs never rewrite the same case
+	erasureMutex sync.Mutex
+)
+
+// recordSMSMessage keeps an SMS note with a keyed hash of the sender's number
+func recordSMSMessage(sid, from, body string, keywords []string) {
+	if sid == "" {
+		sid = fmt.Sprintf("sms-%d", time.Now().UnixNano())
+	}
+	message := types.SMSMessage{
+		SID:        sid,
+		SenderHash: identityManager.HashIdentifier(from),
+		Body:       body,
+		Keywords:   keywords,
+		Timestamp:  time.Now(),
+	}
+
+	smsMessagesMutex.Lock()
+	smsMessages = append(smsMessages, message)
+	if limit := appConfig.Limits.SMSMessagesInMemory; len(smsMessages) > limit {
+		smsMessages = smsMessages[len(smsMessages)-limit:]
+	}
+	smsMessagesMutex.Unlock()
+
+	if useDynamoDB && smsRepo != nil {
+		background.Async("save_sms", func() {
+			if err := smsRepo.Save(message); err != nil {
+				log.Printf("❌ Failed to save SMS message to DynamoDB: %v", err)
+			}
+		})
+	}
+}
+
+// dataSubject identifies the person a data request is about. Any identifier may be
+// given; records matching any of them are theirs.
+type dataSubject struct {
+	DeviceIDs     []string `json:"device_ids,omitempty"`     // locations
+	PhoneNumbers  []string `json:"phone_numbers,omitempty"`  // SMS notes
+	IPAddresses   []string `json:"ip_addresses,omitempty"`   // tips and donations
+	SessionTokens []string `json:"session_tokens,omitempty"` // tips (X-Session-Token)
+	UserHashes    []string `json:"user_hashes,omitempty"`    // tips and donations
+	TipIDs        []string `json:"tip_ids,omitempty"`
+}
+
+// normalize trims and dedupes the identifiers and checks there are some, but not too many
+func (s *dataSubject) normalize() error {
+	total := 0
+	for _, list := range []*[]string{&s.DeviceIDs, &s.PhoneNumbers, &s.IPAddresses, &s.SessionTokens, &s.UserHashes, &s.TipIDs} {
+		seen := make(map[string]bool)
+		cleaned := (*list)[:0]
+		for _, value := range *list {
+			value = strings.TrimSpace(value)
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			cleaned = append(cleaned, value)
+		}
+		*list = cleaned
+		total += len(cleaned)
+	}
+	switch {
+	case total == 0:
+		return fmt.Errorf("subject needs at least one identifier")
+	case total > privacyMaxIdentifiers:
+		return fmt.Errorf("subject has more than %d identifiers", privacyMaxIdentifiers)
+	}
+	return nil
+}
+
+// digest is a keyed hash of all identifiers, for audit entries that must not hold them
+func (s dataSubject) digest() string {
+	var parts []string
+	add := func(kind string, values []string) {
+		for _, value := range values {
+			parts = append(parts, kind+":"+value)
+		}
+	}
+	add("device", s.DeviceIDs)
+	add("phone", s.PhoneNumbers)
+	add("ip", s.IPAddresses)
+	add("session", s.SessionTokens)
+	add("user", s.UserHashes)
+	add("tip", s.TipIDs)
+	sort.Strings(parts)
+	return identityManager.HashIdentifier(strings.Join(parts, "\n"))
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// subjectTip is a tip with its decrypted submission metadata, for the subject's own export
+type subjectTip struct {
+	types.AnonymousTip
+	Metadata *UserMetadata `json:"metadata,omitempty"`
+}
+
+// subjectData is everything held about a data subject
+type subjectData struct {
+	Locations []types.Location   `json:"locations"`
+	Tips      []subjectTip       `json:"tips"`
+	Donations []types.Donation   `json:"donations"`
+	SMS       []types.SMSMessage `json:"sms"`
+	Cases     []types.ErrorLog   `json:"cases"` // cases seeded by or quoting the subject
+
+	links caseLinks
+}
+
+func (d *subjectData) counts() map[string]int {
+	return map[string]int{
+		"locations": len(d.Locations),
+		"tips":      len(d.Tips),
+		"donations": len(d.Donations),
+		"sms":       len(d.SMS),
+		"cases":     len(d.Cases),
+	}
+}
+
+// caseLinks are the subject's records that cases can refer to
+type caseLinks struct {
+	devices   map[string]bool
+	tipIDs    map[string]bool
+	smsSIDs   map[string]bool
+	smsBodies map[string]bool // notes attached before cases recorded the SMS SID
+}
+
+// seededBy reports whether a case was seeded by, or quotes, the subject
+func (l caseLinks) seededBy(errorLog types.ErrorLog) bool {
+	if l.seed(errorLog) || l.note(errorLog) {
+		return true
+	}
+	for _, tipID := range errorLog.AnonymousTips {
+		if l.tipIDs[tipID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (l caseLinks) seed(errorLog types.ErrorLog) bool {
+	id := errorLog.SeedInteractionID
+	return id != "" && (l.devices[id] || l.tipIDs[id] || l.smsSIDs[id])
+}
+
+func (l caseLinks) note(errorLog types.ErrorLog) bool {
+	if errorLog.UserNoteSID != "" {
+		return l.smsSIDs[errorLog.UserNoteSID]
+	}
+	return errorLog.UserExperienceNote != "" && l.smsBodies[errorLog.UserExperienceNote]
+}
+
+// scrub removes the subject's references from a case, keeping the generated content
+func (l caseLinks) scrub(errorLog *types.ErrorLog) {
+	if l.seed(*errorLog) {
+		if errorLog.SeedInteractionType == "location_share" {
+			errorLog.NearbyBusinesses = nil // they place the subject
+		}
+		errorLog.SeedInteractionType = "erased"
+		errorLog.SeedInteractionID = ""
+		errorLog.SeedInteractionTimestamp = time.Time{}
+		errorLog.SeedKeywords = nil
+	}
+	if l.note(*errorLog) {
+		errorLog.UserExperienceNote = ""
+		errorLog.UserNoteKeywords = nil
+		errorLog.UserNoteSID = ""
+	}
+	if len(errorLog.AnonymousTips) > 0 {
+		kept := make([]string, 0, len(errorLog.AnonymousTips))
+		for _, tipID := range errorLog.AnonymousTips {
+			if !l.tipIDs[tipID] {
+				kept = append(kept, tipID)
+			}
+		}
+		errorLog.AnonymousTips = kept
+	}
+}
+
+// isRecordNotFound matches the repositories' not-found errors
+func isRecordNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+// collectSubjectData finds every record matching the subject in memory and DynamoDB.
+// Any store that cannot be read fails the whole collection: a partial export or erasure
+// would look complete when it isn't.
+func collectSubjectData(subject dataSubject) (*subjectData, error) {
+	data := &subjectData{
+		Locations: []types.Location{},
+		Tips:      []subjectTip{},
+		Donations: []types.Donation{},
+		SMS:       []types.SMSMessage{},
+		Cases:     []types.ErrorLog{},
+	}
+	devices := stringSet(subject.DeviceIDs)
+	ips := stringSet(subject.IPAddresses)
+	sessions := stringSet(subject.SessionTokens)
+	userHashes := stringSet(subject.UserHashes)
+	tipIDs := stringSet(subject.TipIDs)
+	phoneHashes := make(map[string]bool)
+	for _, phone := range subject.PhoneNumbers {
+		phoneHashes[identityManager.HashIdentifier(phone)] = true
+	}
+
+	// Locations: one current record per device
+	locationMutex.RLock()
+	for _, deviceID := range subject.DeviceIDs {
+		if loc, ok := locations[deviceID]; ok {
+			data.Locations = append(data.Locations, loc)
+			delete(devices, deviceID)
+		}
+	}
+	locationMutex.RUnlock()
+	if useDynamoDB && locationRepo != nil {
+		for deviceID := range devices {
+			loc, err := locationRepo.GetByDeviceID(deviceID)
+			if isRecordNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			data.Locations = append(data.Locations, *loc)
+		}
+	}
+
+	// Tips: by ID or user hash, or by the IP address and session token sealed in their metadata
+	anonymousTipsMutex.RLock()
+	tips := append([]types.AnonymousTip(nil), anonymousTips...)
+	anonymousTipsMutex.RUnlock()
+	if useDynamoDB && tipRepo != nil {
+		stored, err := tipRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		tips = append(tips, stored...)
+	}
+	seenTips := make(map[string]bool)
+	for _, tip := range tips {
+		if seenTips[tip.ID] {
+			continue
+		}
+		seenTips[tip.ID] = true
+
+		var metadata *UserMetadata
+		if tip.UserMetadata != "" {
+			metadata, _ = identityManager.ReverseHash(tip.UserMetadata)
+		}
+		matched := tipIDs[tip.ID] || userHashes[tip.UserHash] || ips[tip.IPAddress]
+		if metadata != nil {
+			matched = matched || ips[metadata.IPAddress] || (metadata.SessionToken != "" && sessions[metadata.SessionToken])
+		}
+		if matched {
+			data.Tips = append(data.Tips, subjectTip{AnonymousTip: tip, Metadata: metadata})
+		}
+	}
+
+	// Donations: only in DynamoDB
+	if useDynamoDB && donationRepo != nil {
+		donations, err := donationRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, donation := range donations {
+			if (donation.UserHash != "" && userHashes[donation.UserHash]) || (donation.IPAddress != "" && ips[donation.IPAddress]) {
+				data.Donations = append(data.Donations, donation)
+			}
+		}
+	}
+
+	// SMS notes: by sender hash
+	if len(phoneHashes) > 0 {
+		smsMessagesMutex.RLock()
+		messages := append([]types.SMSMessage(nil), smsMessages...)
+		smsMessagesMutex.RUnlock()
+		if useDynamoDB && smsRepo != nil {
+			stored, err := smsRepo.GetAll()
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, stored...)
+		}
+		seenSMS := make(map[string]bool)
+		for _, message := range messages {
+			if !seenSMS[message.SID] && phoneHashes[message.SenderHash] {
+				data.SMS = append(data.SMS, message)
+			}
+			seenSMS[message.SID] = true
+		}
+	}
+
+	// Cases seeded by any of the above, read fresh rather than from the archive cache
+	data.links = caseLinks{
+		devices:   stringSet(subject.DeviceIDs),
+		tipIDs:    make(map[string]bool),
+		smsSIDs:   make(map[string]bool),
+		smsBodies: make(map[string]bool),
+	}
+	for _, tip := range data.Tips {
+		data.links.tipIDs[tip.ID] = true
+	}
+	for _, message := range data.SMS {
+		data.links.smsSIDs[message.SID] = true
+		data.links.smsBodies[message.Body] = true
+	}
+	invalidateCaseArchive()
+	for _, errorLog := range loadAllCases() {
+		if data.links.seededBy(errorLog) {
+			data.Cases = append(data.Cases, errorLog)
+		}
+	}
+
+	return data, nil
+}
+
+// invalidateCaseArchive makes the next loadAllCases rescan DynamoDB
+func invalidateCaseArchive() {
+	caseArchiveCacheMutex.Lock()
+	caseArchiveCache = nil
+	caseArchiveCacheMutex.Unlock()
+}
+
+// newPrivacyAuditEntry starts an audit entry for a request about subject
+func newPrivacyAuditEntry(action string, subject dataSubject) types.PrivacyAuditEntry {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return types.PrivacyAuditEntry{
+		ID:            action + "-" + hex.EncodeToString(id),
+		Action:        action,
+		SubjectDigest: subject.digest(),
+		Status:        "running",
+		RequestedAt:   time.Now(),
+	}
+}
+
+// savePrivacyAuditEntry adds or updates an audit entry in memory and DynamoDB
+func savePrivacyAuditEntry(entry types.PrivacyAuditEntry) {
+	privacyAuditMutex.Lock()
+	replaced := false
+	for i := range privacyAudit {
+		if privacyAudit[i].ID == entry.ID {
+			privacyAudit[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		privacyAudit = append(privacyAudit, entry)
+		if len(privacyAudit) > privacyAuditInMemory {
+			privacyAudit = privacyAudit[len(privacyAudit)-privacyAuditInMemory:]
+		}
+	}
+	privacyAuditMutex.Unlock()
+
+	if useDynamoDB && privacyAuditRepo != nil {
+		if err := privacyAuditRepo.Save(entry); err != nil {
+			log.Printf("❌ Failed to save privacy audit entry %s: %v", entry.ID, err)
+		}
+	}
+	log.Printf("🔒 Privacy %s %s: %s %v", entry.Action, entry.ID, entry.Status, entry.Counts)
+}
+
+// privacyRequest is the body of export and erasure requests
+type privacyRequest struct {
+	Subject dataSubject `json:"subject"`
+	Confirm bool        `json:"confirm"` // erasure only
+}
+
+// decodePrivacyRequest checks access and reads the subject of a privacy request
+func decodePrivacyRequest(w http.ResponseWriter, r *http.Request) (*privacyRequest, bool) {
+	if !isAuthenticated(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	var req privacyRequest
+	r.Body = http.MaxBytesReader(w, r.Body, privacyMaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := req.Subject.normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// handlePrivacyExport returns a ZIP of JSON files with everything held about a data
+// subject: locations, tips (with their decrypted submission metadata), donations, SMS
+// notes and the cases they seeded. Owner only; each export gets an audit entry.
+func handlePrivacyExport(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePrivacyRequest(w, r)
+	if !ok {
+		return
+	}
+
+	entry := newPrivacyAuditEntry("export", req.Subject)
+	data, err := collectSubjectData(req.Subject)
+	if err != nil {
+		log.Printf("❌ Privacy export failed: %v", err)
+		entry.Status = "failed"
+		entry.Errors = []string{err.Error()}
+		entry.CompletedAt = time.Now()
+		savePrivacyAuditEntry(entry)
+		http.Error(w, "Failed to collect data", http.StatusServiceUnavailable)
+		return
+	}
+
+	entry.Status = "completed"
+	entry.Counts = data.counts()
+	entry.CompletedAt = time.Now()
+
+	manifest := map[string]interface{}{
+		"generated_at": entry.CompletedAt.UTC(),
+		"audit_id":     entry.ID,
+		"subject":      req.Subject,
+		"counts":       entry.Counts,
+		"files": map[string]string{
+			"locations.json": "Last shared location of each device",
+			"tips.json":      "Anonymous tips, with the metadata sealed at submission",
+			"donations.json": "Donation records",
+			"sms.json":       "SMS notes received from the phone numbers",
+			"cases.json":     "Cases seeded by the locations, tips or SMS notes, or quoting them",
+		},
+	}
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"manifest.json", manifest},
+		{"locations.json", data.Locations},
+		{"tips.json", data.Tips},
+		{"donations.json", data.Donations},
+		{"sms.json", data.SMS},
+		{"cases.json", data.Cases},
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.zip"`, entry.CompletedAt.Format("2006-01-02")))
+	w.Header().Set("Cache-Control", "no-store")
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		part, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: entry.CompletedAt})
+		if err == nil {
+			encoder := json.NewEncoder(part)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(file.value)
+		}
+		if err != nil {
+			log.Printf("❌ Privacy export %s: failed to write %s: %v", entry.ID, file.name, err)
+			entry.Status = "failed"
+			entry.Errors = []string{err.Error()}
+			break
+		}
+	}
+	if err := archive.Close(); err != nil && entry.Status != "failed" {
+		entry.Status = "failed"
+		entry.Errors = []string{err.Error()}
+	}
+	savePrivacyAuditEntry(entry)
+}
+
+// handlePrivacyErasure starts an erasure job for a data subject and answers 202 with the
+// job's audit entry. The body must set "confirm": true.
+func handlePrivacyErasure(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePrivacyRequest(w, r)
+	if !ok {
+		return
+	}
+	if !req.Confirm {
+		http.Error(w, `Erasure cannot be undone; set "confirm": true`, http.StatusBadRequest)
+		return
+	}
+
+	entry := newPrivacyAuditEntry("erasure", req.Subject)
+	savePrivacyAuditEntry(entry)
+	background.Async("erasure", func() { runErasure(entry, req.Subject) })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         entry.ID,
+		"status":     entry.Status,
+		"status_url": "/api/privacy/erasure/" + entry.ID,
+	})
+}
+
+// runErasure removes or tombstones the subject's records everywhere they are kept:
+//   - locations are deleted
+//   - tips become tombstones: the ID stays, so cases and links to it still resolve, but
+//     the content, user hash, metadata and IP address are gone
+//   - donations keep the payment record the bank requires, without user hash or IP address
+//   - SMS notes are deleted
+//   - cases keep their generated content but lose the seed, note and tip references
+func runErasure(entry types.PrivacyAuditEntry, subject dataSubject) {
+	erasureMutex.Lock()
+	defer erasureMutex.Unlock()
+
+	data, err := collectSubjectData(subject)
+	if err != nil {
+		entry.Status = "failed"
+		entry.Errors = []string{err.Error()}
+		entry.CompletedAt = time.Now()
+		savePrivacyAuditEntry(entry)
+		return
+	}
+
+	counts := map[string]int{}
+	var failures []string
+	fail := func(what string, err error) {
+		failures = append(failures, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	for _, loc := range data.Locations {
+		locationMutex.Lock()
+		delete(locations, loc.DeviceID)
+		locationMutex.Unlock()
+		syncLog.Forget(replica.KindLocation, loc.DeviceID)
+		if locationFuzzer != nil {
+			locationFuzzer.Forget(loc.DeviceID)
+		}
+		if useDynamoDB && locationRepo != nil {
+			if err := locationRepo.Delete(loc.DeviceID); err != nil {
+				fail("location", err)
+				continue
+			}
+		}
+		counts["locations_deleted"]++
+	}
+
+	for _, tip := range data.Tips {
+		tombstone := types.AnonymousTip{
+			ID:               tip.ID,
+			ModerationStatus: "erased",
+			ModerationReason: "erased at the request of the submitter",
+			Timestamp:        tip.Timestamp,
+		}
+		anonymousTipsMutex.Lock()
+		for i := range anonymousTips {
+			if anonymousTips[i].ID == tip.ID {
+				anonymousTips[i] = tombstone
+			}
+		}
+		anonymousTipsMutex.Unlock()
+		pendingTipMutex.Lock()
+		kept := pendingTipQueue[:0]
+		for _, pendingID := range pendingTipQueue {
+			if pendingID != tip.ID {
+				kept = append(kept, pendingID)
+			}
+		}
+		pendingTipQueue = kept
+		pendingTipMutex.Unlock()
+		syncLog.Forget(replica.KindTip, tip.ID)
+		if useDynamoDB && tipRepo != nil {
+			if err := tipRepo.Save(tombstone); err != nil {
+				fail("tip", err)
+				continue
+			}
+		}
+		counts["tips_tombstoned"]++
+	}
+
+	for _, donation := range data.Donations {
+		donation.UserHash = ""
+		donation.IPAddress = ""
+		if err := donationRepo.Save(donation); err != nil {
+			fail("donation", err)
+			continue
+		}
+		counts["donations_scrubbed"]++
+	}
+
+	for _, message := range data.SMS {
+		if useDynamoDB && smsRepo != nil {
+			if err := smsRepo.Delete(message.SID); err != nil {
+				fail("sms", err)
+				continue
+			}
+		}
+		counts["sms_deleted"]++
+	}
+	smsMessagesMutex.Lock()
+	keptSMS := smsMessages[:0]
+	for _, message := range smsMessages {
+		if !data.links.smsSIDs[message.SID] {
+			keptSMS = append(keptSMS, message)
+		}
+	}
+	smsMessages = keptSMS
+	smsMessagesMutex.Unlock()
+	userExperienceNoteMutex.Lock()
+	if data.links.smsSIDs[pendingUserNoteSID] || data.links.smsBodies[pendingUserExperienceNote] {
+		pendingUserExperienceNote = ""
+		pendingUserNoteKeywords = nil
+		pendingUserNoteSID = ""
+	}
+	userExperienceNoteMutex.Unlock()
+
+	for _, errorLog := range data.Cases {
+		data.links.scrub(&errorLog)
+		errorLogMutex.Lock()
+		for i := range errorLogs {
+			if errorLogs[i].ID == errorLog.ID {
+				errorLogs[i] = errorLog
+			}
+		}
+		errorLogMutex.Unlock()
+		if useDynamoDB && errorLogRepo != nil {
+			if err := errorLogRepo.Save(errorLog); err != nil {
+				fail("case", err)
+				continue
+			}
+		}
+		counts["cases_scrubbed"]++
+	}
+	invalidateCaseArchive()
+
+	// The next generated case must not be seeded by them either
+	if seed := getLastInteractionContext(); seed != nil {
+		seedLog := types.ErrorLog{SeedInteractionID: seed.SourceID, UserExperienceNote: seed.RawContent}
+		if data.links.seed(seedLog) || data.links.smsBodies[seed.RawContent] {
+			contextService.ClearContext()
+			lastInteractionContextMutex.Lock()
+			lastInteractionContext = nil
+			lastInteractionContextMutex.Unlock()
+			if seed.InteractionType == "location_share" {
+				currentBusinessesMutex.Lock()
+				currentBusinesses = []types.Business{}
+				currentBusinessesMutex.Unlock()
+			}
+			counts["seed_cleared"] = 1
+		}
+	}
+
+	entry.Status = "completed"
+	if len(failures) > 0 {
+		entry.Status = "failed"
+	}
+	entry.Counts = counts
+	entry.Errors = failures
+	entry.CompletedAt = time.Now()
+	savePrivacyAuditEntry(entry)
+}
+
+// handlePrivacyErasureStatus returns the audit entry of an erasure job
+func handlePrivacyErasureStatus(w http.ResponseWriter, r *http.Request) {
+	if !isAuthenticated(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/privacy/erasure/")
+	for _, entry := range recentPrivacyAudit() {
+		if entry.ID == id && entry.Action == "erasure" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(entry)
+			return
+		}
+	}
+	http.Error(w, "Erasure job not found", http.StatusNotFound)
+}
+
+// handlePrivacyAudit lists recent export and erasure audit entries, newest first
+func handlePrivacyAudit(w http.ResponseWriter, r *http.Request) {
+	if !isAuthenticated(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries := recentPrivacyAudit()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// recentPrivacyAudit merges the in-memory audit trail with DynamoDB, newest first
+func recentPrivacyAudit() []types.PrivacyAuditEntry {
+	privacyAuditMutex.RLock()
+	entries := append([]types.PrivacyAuditEntry(nil), privacyAudit...)
+	privacyAuditMutex.RUnlock()
+
+	if useDynamoDB && privacyAuditRepo != nil {
+		stored, err := privacyAuditRepo.GetRecent(privacyAuditInMemory)
+		if err != nil {
+			log.Printf("⚠️  Failed to load privacy audit: %v", err)
+		}
+		seen := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			seen[entry.ID] = true
+		}
+		for _, entry := range stored {
+			if !seen[entry.ID] {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RequestedAt.After(entries[j].RequestedAt)
+	})
+	if len(entries) > privacyAuditInMemory {
+		entries = entries[:privacyAuditInMemory]
+	}
+	return entries
+}
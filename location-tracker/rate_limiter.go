@@ -27,7 +27,7 @@ func NewRateLimiter(maxPerHour int) *RateLimiter {
 	}
 
 	// Start cleanup goroutine (remove old timestamps every 5 minutes)
-	go rl.cleanupOldTimestamps()
+	background.Go("rate_limit_cleanup", rl.cleanupOldTimestamps)
 
 	return rl
 }
@@ -94,11 +94,19 @@ func (rl *RateLimiter) GetRemainingQuota(userHash string) int {
 }
 
 // cleanupOldTimestamps removes timestamps older than 1 hour
-func (rl *RateLimiter) cleanupOldTimestamps() {
-	ticker := time.NewTicker(5 * time.Minute)
+func (rl *RateLimiter) cleanupOldTimestamps(ctx context.Context) error {
+	interval := appConfig.Intervals.RateLimitCleanup
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	heartbeat := backgroundHeartbeat("rate_limit_cleanup", interval)
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		heartbeat.Beat()
 		rl.mutex.Lock()
 		now := time.Now()
 		hourAgo := now.Add(-1 * time.Hour)
@@ -141,11 +149,11 @@ func NewBanManager(dynamoClient *dynamodb.Client, tableName string) *BanManager
 
 	// Load existing bans from DynamoDB
 	if bm.useDynamoDB {
-		go bm.loadBannedUsers()
+		background.Async("load_bans", bm.loadBannedUsers)
 	}
 
 	// Start cleanup goroutine
-	go bm.cleanupExpiredBans()
+	background.Go("ban_cleanup", bm.cleanupExpiredBans)
 
 	return bm
 }
@@ -269,12 +277,20 @@ func (bm *BanManager) loadBannedUsers() {
 	fmt.Printf("📋 Loaded %d active bans from DynamoDB\n", len(bm.bannedUsers))
 }
 
-// cleanupExpiredBans removes expired bans from memory every 10 minutes
-func (bm *BanManager) cleanupExpiredBans() {
-	ticker := time.NewTicker(10 * time.Minute)
+// cleanupExpiredBans removes expired bans from memory every intervals.ban_cleanup
+func (bm *BanManager) cleanupExpiredBans(ctx context.Context) error {
+	interval := appConfig.Intervals.BanCleanup
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	heartbeat := backgroundHeartbeat("ban_cleanup", interval)
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		heartbeat.Beat()
 		bm.mutex.Lock()
 		now := time.Now()
 		for userHash, expiry := range bm.bannedUsers {
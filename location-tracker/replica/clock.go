@@ -0,0 +1,187 @@
+/*
+# Module: replica/clock.go
+Hybrid Logical Clock timestamps for versioning records written on different replicas.
+
+A timestamp is physical time in milliseconds, a logical counter for events within
+the same millisecond, and the node that made it. They are totally ordered, stay close
+to wall-clock time, and never go backwards on a node even if its clock does.
+
+## Linked Modules
+- [replica/log](./log.go) - Versioned records and merge rules
+
+## Tags
+sync, hlc, clock, replication
+
+## Exports
+Timestamp, Clock, NewClock, ParseTimestamp, ErrClockDrift
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "replica/clock.go" ;
+    code:description "Hybrid Logical Clock timestamps for versioning records written on different replicas" ;
+    code:linksTo [
+        code:name "replica/log" ;
+        code:path "./log.go" ;
+        code:relationship "Versioned records and merge rules"
+    ] ;
+    code:exports :Timestamp, :Clock, :NewClock, :ParseTimestamp, :ErrClockDrift ;
+    code:tags "sync", "hlc", "clock", "replication" .
+<!-- End LinkedDoc RDF -->
+*/
+package replica
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timestamp is a Hybrid Logical Clock reading. Its text form is "wall.logical.node",
+// e.g. "1730000000000.2.server".
+type Timestamp struct {
+	Wall    int64  // Unix milliseconds
+	Logical uint32 // orders events within the same millisecond
+	Node    string // replica that made the timestamp; breaks ties
+}
+
+// Compare orders timestamps by wall time, then logical counter, then node.
+func (t Timestamp) Compare(other Timestamp) int {
+	switch {
+	case t.Wall != other.Wall:
+		if t.Wall < other.Wall {
+			return -1
+		}
+		return 1
+	case t.Logical != other.Logical:
+		if t.Logical < other.Logical {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(t.Node, other.Node)
+}
+
+// After reports whether t is later than other.
+func (t Timestamp) After(other Timestamp) bool {
+	return t.Compare(other) > 0
+}
+
+// IsZero reports whether t is unset.
+func (t Timestamp) IsZero() bool {
+	return t.Wall == 0 && t.Logical == 0 && t.Node == ""
+}
+
+// Time returns the physical part of the timestamp.
+func (t Timestamp) Time() time.Time {
+	return time.UnixMilli(t.Wall)
+}
+
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.%d.%s", t.Wall, t.Logical, t.Node)
+}
+
+// MarshalText encodes the timestamp in its text form.
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes the text form.
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// ParseTimestamp reads the "wall.logical.node" form.
+func ParseTimestamp(value string) (Timestamp, error) {
+	parts := strings.SplitN(value, ".", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return Timestamp{}, fmt.Errorf("invalid timestamp %q: want wall.logical.node", value)
+	}
+	wall, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || wall < 0 {
+		return Timestamp{}, fmt.Errorf("invalid timestamp %q: bad wall time", value)
+	}
+	logical, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("invalid timestamp %q: bad logical counter", value)
+	}
+	return Timestamp{Wall: wall, Logical: uint32(logical), Node: parts[2]}, nil
+}
+
+// ErrClockDrift is returned for remote timestamps too far ahead of the local clock.
+// Accepting them would let one replica win every last-writer-wins merge.
+var ErrClockDrift = errors.New("timestamp is too far in the future")
+
+// Clock issues Hybrid Logical Clock timestamps for one node.
+type Clock struct {
+	Node     string
+	MaxDrift time.Duration    // how far ahead a remote timestamp may be
+	Now      func() time.Time // physical clock, time.Now by default
+
+	mu   sync.Mutex
+	last Timestamp
+}
+
+// NewClock returns a clock for node that accepts up to a minute of drift.
+func NewClock(node string) *Clock {
+	return &Clock{Node: node, MaxDrift: time.Minute, Now: time.Now}
+}
+
+// Tick returns a timestamp for a local event, later than every timestamp the clock
+// has issued or seen.
+func (c *Clock) Tick() Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	physical := c.Now().UnixMilli()
+	if physical > c.last.Wall {
+		c.last = Timestamp{Wall: physical, Node: c.Node}
+	} else {
+		c.last = Timestamp{Wall: c.last.Wall, Logical: c.last.Logical + 1, Node: c.Node}
+	}
+	return c.last
+}
+
+// Observe merges a timestamp received from another node, so later local timestamps
+// order after it. It refuses timestamps beyond MaxDrift.
+func (c *Clock) Observe(remote Timestamp) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	physical := c.Now().UnixMilli()
+	if c.MaxDrift > 0 && remote.Wall-physical > c.MaxDrift.Milliseconds() {
+		return ErrClockDrift
+	}
+
+	wall := physical
+	if c.last.Wall > wall {
+		wall = c.last.Wall
+	}
+	if remote.Wall > wall {
+		wall = remote.Wall
+	}
+
+	var logical uint32
+	switch {
+	case wall == c.last.Wall && wall == remote.Wall:
+		logical = c.last.Logical
+		if remote.Logical > logical {
+			logical = remote.Logical
+		}
+		logical++
+	case wall == c.last.Wall:
+		logical = c.last.Logical + 1
+	case wall == remote.Wall:
+		logical = remote.Logical + 1
+	}
+	c.last = Timestamp{Wall: wall, Logical: logical, Node: c.Node}
+	return nil
+}
@@ -0,0 +1,345 @@
+/*
+# Module: replica/log.go
+Versioned records, deterministic merge rules and a change log for pull/push sync.
+
+Locations are last-writer-wins by HLC version; tips are append-only, so once a tip
+ID exists its content never changes. Every accepted write gets a sequence number in
+the log, and replicas pull everything after the cursor they last saw.
+
+## Linked Modules
+- [replica/clock](./clock.go) - Hybrid Logical Clock timestamps
+
+## Tags
+sync, replication, conflict-resolution, lww, append-only
+
+## Exports
+Kind, KindLocation, KindTip, Record, Change, Conflict, Outcome, Result, Resolve, Log, NewLog, ErrInvalidCursor
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "replica/log.go" ;
+    code:description "Versioned records, deterministic merge rules and a change log for pull/push sync" ;
+    code:linksTo [
+        code:name "replica/clock" ;
+        code:path "./clock.go" ;
+        code:relationship "Hybrid Logical Clock timestamps"
+    ] ;
+    code:exports :Kind, :KindLocation, :KindTip, :Record, :Change, :Conflict, :Outcome, :Result, :Resolve, :Log, :NewLog, :ErrInvalidCursor ;
+    code:tags "sync", "replication", "conflict-resolution", "lww", "append-only" .
+<!-- End LinkedDoc RDF -->
+*/
+package replica
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind names a record type and selects its merge rule.
+type Kind string
+
+const (
+	KindLocation Kind = "location" // last-writer-wins
+	KindTip      Kind = "tip"      // append-only
+)
+
+// Valid reports whether k is a known kind.
+func (k Kind) Valid() bool {
+	return k == KindLocation || k == KindTip
+}
+
+// Record is one versioned value. ID is unique within its kind.
+type Record struct {
+	Kind    Kind            `json:"kind"`
+	ID      string          `json:"id"`
+	Version Timestamp       `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Change is a write offered to a replica. Base is the version the writer last saw
+// for this record, nil if it saw none; a Base older than the stored version means
+// the write was made concurrently with another one.
+type Change struct {
+	Record
+	Base *Timestamp `json:"base,omitempty"`
+}
+
+// Conflict describes a write that raced another one, and which side was kept.
+type Conflict struct {
+	Kind     Kind      `json:"kind"`
+	ID       string    `json:"id"`
+	Existing Timestamp `json:"existing"` // version already stored
+	Incoming Timestamp `json:"incoming"` // version of the write
+	Winner   string    `json:"winner"`   // "existing" or "incoming"
+	Reason   string    `json:"reason"`
+}
+
+// Outcome says what applying a change did.
+type Outcome string
+
+const (
+	Applied   Outcome = "applied"   // the change is now the stored record
+	Unchanged Outcome = "unchanged" // the stored record was kept
+)
+
+// Result is the outcome of one change, the record stored afterwards and the conflict,
+// if there was one.
+type Result struct {
+	Outcome  Outcome
+	Record   Record
+	Conflict *Conflict
+}
+
+// Resolve merges an incoming change into the current record (nil if there is none).
+// A stored location depends only on the set of versions and data seen, never on the
+// order they arrive in, so replicas that exchange all changes converge. A tip keeps the
+// version of whichever copy arrived first; its content is the same everywhere.
+func Resolve(current *Record, in Change) Result {
+	if current == nil {
+		return Result{Outcome: Applied, Record: in.Record}
+	}
+	switch in.Kind {
+	case KindTip:
+		return resolveTip(*current, in)
+	default:
+		return resolveLocation(*current, in)
+	}
+}
+
+// resolveLocation keeps the higher version, breaking the (unlikely) tie of equal
+// versions carrying different data by comparing the data itself
+func resolveLocation(current Record, in Change) Result {
+	order := in.Version.Compare(current.Version)
+	sameData := bytes.Equal(in.Data, current.Data)
+	if order == 0 && sameData {
+		return Result{Outcome: Unchanged, Record: current}
+	}
+
+	incomingWins := order > 0 || (order == 0 && bytes.Compare(in.Data, current.Data) > 0)
+	conflict := &Conflict{
+		Kind:     in.Kind,
+		ID:       in.ID,
+		Existing: current.Version,
+		Incoming: in.Version,
+		Winner:   "existing",
+	}
+	if incomingWins {
+		conflict.Winner = "incoming"
+	}
+
+	switch {
+	case order == 0:
+		conflict.Reason = "same version with different data; kept the greater value"
+	case !incomingWins:
+		conflict.Reason = "a newer write is already stored; last writer wins"
+	case in.Base == nil || *in.Base != current.Version:
+		conflict.Reason = "concurrent write overwritten; last writer wins"
+	default:
+		// The writer saw the stored version and replaced it: an ordinary update
+		conflict = nil
+	}
+	if sameData {
+		conflict = nil
+	}
+
+	if incomingWins {
+		return Result{Outcome: Applied, Record: in.Record, Conflict: conflict}
+	}
+	return Result{Outcome: Unchanged, Record: current, Conflict: conflict}
+}
+
+// resolveTip keeps whichever tip was stored first; tips are never edited
+func resolveTip(current Record, in Change) Result {
+	result := Result{Outcome: Unchanged, Record: current}
+	if !bytes.Equal(in.Data, current.Data) {
+		result.Conflict = &Conflict{
+			Kind:     in.Kind,
+			ID:       in.ID,
+			Existing: current.Version,
+			Incoming: in.Version,
+			Winner:   "existing",
+			Reason:   "tips are append-only; an existing tip cannot be changed",
+		}
+	}
+	return result
+}
+
+// ErrInvalidCursor is returned by Since for a cursor it cannot parse.
+var ErrInvalidCursor = errors.New("invalid sync cursor")
+
+type recordKey struct {
+	kind Kind
+	id   string
+}
+
+type logEntry struct {
+	record Record
+	seq    uint64
+}
+
+// Log holds the current record for every key and the order they were last written
+// in. Sequence numbers are only meaningful within one epoch; the epoch changes on
+// every restart, so a cursor from before one means a full pull.
+type Log struct {
+	Clock *Clock
+
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	entries map[recordKey]*logEntry
+}
+
+// NewLog returns an empty log versioning local writes with clock.
+func NewLog(clock *Clock) *Log {
+	epoch := make([]byte, 4)
+	rand.Read(epoch)
+	return &Log{
+		Clock:   clock,
+		epoch:   hex.EncodeToString(epoch),
+		entries: make(map[recordKey]*logEntry),
+	}
+}
+
+// Get returns the current record for a key.
+func (l *Log) Get(kind Kind, id string) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[recordKey{kind, id}]
+	if !ok {
+		return Record{}, false
+	}
+	return entry.record, true
+}
+
+// Apply merges a change from another replica. When the change wins, commit is called
+// with it before it is logged, still holding the log's lock, so writes to the backing
+// store happen in version order; if commit fails nothing is logged. commit may replace
+// the record's Data with what should be stored and served instead, such as a fuzzed
+// location; the result carries the record as logged. commit may be nil.
+func (l *Log) Apply(in Change, commit func(*Record) error) (Result, error) {
+	if !in.Kind.Valid() {
+		return Result{}, fmt.Errorf("unknown record kind %q", in.Kind)
+	}
+	if in.ID == "" {
+		return Result{}, fmt.Errorf("record has no id")
+	}
+	if in.Version.IsZero() {
+		return Result{}, fmt.Errorf("record %s/%s has no version", in.Kind, in.ID)
+	}
+	if err := l.Clock.Observe(in.Version); err != nil {
+		return Result{}, fmt.Errorf("record %s/%s: %w", in.Kind, in.ID, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.apply(in, commit)
+}
+
+// Local records a write made on this replica, versioned after everything the log has
+// seen. It goes through the same merge rules, so writing an existing tip ID is refused.
+func (l *Log) Local(kind Kind, id string, data json.RawMessage, commit func(*Record) error) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	in := Change{Record: Record{Kind: kind, ID: id, Version: l.Clock.Tick(), Data: data}}
+	if current, ok := l.entries[recordKey{kind, id}]; ok {
+		base := current.record.Version
+		in.Base = &base
+	}
+	return l.apply(in, commit)
+}
+
+// apply resolves and stores a change; l.mu must be held
+func (l *Log) apply(in Change, commit func(*Record) error) (Result, error) {
+	key := recordKey{in.Kind, in.ID}
+	var current *Record
+	if entry, ok := l.entries[key]; ok {
+		current = &entry.record
+	}
+
+	result := Resolve(current, in)
+	if result.Outcome != Applied {
+		return result, nil
+	}
+	if commit != nil {
+		if err := commit(&result.Record); err != nil {
+			return Result{}, err
+		}
+	}
+	l.seq++
+	l.entries[key] = &logEntry{record: result.Record, seq: l.seq}
+	return result, nil
+}
+
+// Cursor returns the position after the latest write.
+func (l *Log) Cursor() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cursor(l.seq)
+}
+
+func (l *Log) cursor(seq uint64) string {
+	return l.epoch + ":" + strconv.FormatUint(seq, 10)
+}
+
+// Since returns up to limit records written after cursor in write order, the cursor
+// to pull from next and whether more records are waiting. An empty cursor or one from
+// another epoch starts from the beginning.
+func (l *Log) Since(cursor string, limit int) ([]Record, string, bool, error) {
+	var after uint64
+	if cursor != "" {
+		epoch, seq, ok := strings.Cut(cursor, ":")
+		if !ok {
+			return nil, "", false, ErrInvalidCursor
+		}
+		n, err := strconv.ParseUint(seq, 10, 64)
+		if err != nil {
+			return nil, "", false, ErrInvalidCursor
+		}
+		if epoch == l.epoch {
+			after = n
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var pending []*logEntry
+	for _, entry := range l.entries {
+		if entry.seq > after {
+			pending = append(pending, entry)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].seq < pending[j].seq })
+
+	more := limit > 0 && len(pending) > limit
+	if more {
+		pending = pending[:limit]
+	}
+	next := l.seq
+	if more {
+		next = pending[len(pending)-1].seq
+	}
+
+	records := make([]Record, len(pending))
+	for i, entry := range pending {
+		records[i] = entry.record
+	}
+	return records, l.cursor(next), more, nil
+}
+
+// Forget drops a record from the log, e.g. when the server stops keeping it in memory.
+func (l *Log) Forget(kind Kind, id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, recordKey{kind, id})
+}
@@ -0,0 +1,232 @@
+package replica
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestMergeProperties generates random change histories and checks that, for each one:
+//   - replicas applying the changes in different orders end with the same records
+//   - applying every change a second time changes nothing
+//   - each location holds its highest version
+//   - no tip is lost and a stored tip never changes
+//   - paging through Since returns every record exactly once
+//   - the clock issues timestamps after every version it observed
+func TestMergeProperties(t *testing.T) {
+	iterations := 500
+	if testing.Short() {
+		iterations = 50
+	}
+	for _, seed := range []int64{1, 2, time.Now().UnixNano()} {
+		rng := rand.New(rand.NewSource(seed))
+		for i := 0; i < iterations; i++ {
+			if err := checkHistory(rng, randomHistory(rng)); err != nil {
+				t.Fatalf("seed %d, iteration %d: %v", seed, i, err)
+			}
+		}
+	}
+}
+
+// propertyClock observes generated timestamps without a drift limit
+func propertyClock(node string) *Clock {
+	clock := NewClock(node)
+	clock.MaxDrift = 0
+	clock.Now = func() time.Time { return time.UnixMilli(1000) }
+	return clock
+}
+
+// randomHistory writes a few location and tip IDs from three nodes with colliding
+// wall times; tip content is derived from the ID, as real tip IDs are unique
+func randomHistory(rng *rand.Rand) []Change {
+	nodes := []string{"server", "pod", "phone"}
+	var seen []Timestamp
+	changes := make([]Change, 1+rng.Intn(40))
+	for i := range changes {
+		version := Timestamp{
+			Wall:    1000 + int64(rng.Intn(20)),
+			Logical: uint32(rng.Intn(3)),
+			Node:    nodes[rng.Intn(len(nodes))],
+		}
+		var in Change
+		if rng.Intn(3) == 0 {
+			id := fmt.Sprintf("tip-%d", rng.Intn(5))
+			in.Record = Record{Kind: KindTip, ID: id, Version: version, Data: json.RawMessage(fmt.Sprintf(`{"tip_content":%q}`, id))}
+		} else {
+			id := fmt.Sprintf("device-%d", rng.Intn(3))
+			in.Record = Record{Kind: KindLocation, ID: id, Version: version, Data: json.RawMessage(fmt.Sprintf(`{"latitude":%d}`, rng.Intn(4)))}
+		}
+		if len(seen) > 0 && rng.Intn(2) == 0 {
+			base := seen[rng.Intn(len(seen))]
+			in.Base = &base
+		}
+		seen = append(seen, version)
+		changes[i] = in
+	}
+	return changes
+}
+
+func checkHistory(rng *rand.Rand, changes []Change) error {
+	first, err := replay(changes, rng.Perm(len(changes)))
+	if err != nil {
+		return err
+	}
+	second, err := replay(changes, rng.Perm(len(changes)))
+	if err != nil {
+		return err
+	}
+	want := snapshot(first)
+	if got := snapshot(second); !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("replicas diverged: %v vs %v", want, got)
+	}
+
+	for _, in := range changes {
+		result, err := first.Apply(in, nil)
+		if err != nil {
+			return err
+		}
+		if result.Outcome != Unchanged {
+			return fmt.Errorf("re-applying %s/%s@%s changed the record", in.Kind, in.ID, in.Version)
+		}
+	}
+	if got := snapshot(first); !reflect.DeepEqual(want, got) {
+		return fmt.Errorf("re-applying changes altered the records")
+	}
+
+	if err := checkWinners(changes, want); err != nil {
+		return err
+	}
+	if err := checkTipImmutable(first, want); err != nil {
+		return err
+	}
+	if err := checkPaging(first, want, 1+rng.Intn(5)); err != nil {
+		return err
+	}
+
+	tick := first.Clock.Tick()
+	for _, in := range changes {
+		if !tick.After(in.Version) {
+			return fmt.Errorf("clock issued %s, not after observed %s", tick, in.Version)
+		}
+	}
+	return nil
+}
+
+func replay(changes []Change, order []int) (*Log, error) {
+	replica := NewLog(propertyClock("server"))
+	for _, i := range order {
+		if _, err := replica.Apply(changes[i], nil); err != nil {
+			return nil, err
+		}
+	}
+	return replica, nil
+}
+
+// snapshot keys the records of a log; tip versions are left out, since a tip keeps
+// the version of the first copy each replica happened to receive
+func snapshot(l *Log) map[string]Record {
+	records, _, _, _ := l.Since("", 0)
+	out := make(map[string]Record, len(records))
+	for _, record := range records {
+		if record.Kind == KindTip {
+			record.Version = Timestamp{}
+		}
+		out[string(record.Kind)+"/"+record.ID] = record
+	}
+	return out
+}
+
+// checkWinners expects the highest version of each location, and every tip ID written
+func checkWinners(changes []Change, records map[string]Record) error {
+	best := make(map[string]Record)
+	for _, in := range changes {
+		key := string(in.Kind) + "/" + in.ID
+		current, ok := best[key]
+		if !ok {
+			best[key] = in.Record
+			continue
+		}
+		if in.Kind == KindLocation {
+			order := in.Version.Compare(current.Version)
+			if order > 0 || (order == 0 && bytes.Compare(in.Data, current.Data) > 0) {
+				best[key] = in.Record
+			}
+		}
+	}
+	if len(best) != len(records) {
+		return fmt.Errorf("expected %d records, have %d", len(best), len(records))
+	}
+	for key, expected := range best {
+		got := records[key]
+		if !bytes.Equal(got.Data, expected.Data) {
+			return fmt.Errorf("%s holds %s, expected %s", key, got.Data, expected.Data)
+		}
+		if expected.Kind == KindLocation && got.Version != expected.Version {
+			return fmt.Errorf("%s holds version %s, expected the highest %s", key, got.Version, expected.Version)
+		}
+	}
+	return nil
+}
+
+// checkTipImmutable tries to overwrite each tip with a newer version
+func checkTipImmutable(l *Log, records map[string]Record) error {
+	for key, record := range records {
+		if record.Kind != KindTip {
+			continue
+		}
+		edit := Change{Record: Record{
+			Kind:    KindTip,
+			ID:      record.ID,
+			Version: Timestamp{Wall: record.Version.Wall + 1, Node: "phone"},
+			Data:    json.RawMessage(`{"tip_content":"edited"}`),
+		}, Base: &record.Version}
+		result, err := l.Apply(edit, nil)
+		if err != nil {
+			return err
+		}
+		if result.Outcome != Unchanged || result.Conflict == nil {
+			return fmt.Errorf("%s was edited without a conflict", key)
+		}
+		if stored, _ := l.Get(KindTip, record.ID); !bytes.Equal(stored.Data, record.Data) {
+			return fmt.Errorf("%s changed to %s", key, stored.Data)
+		}
+	}
+	return nil
+}
+
+// checkPaging pulls with a small page size and expects each record once
+func checkPaging(l *Log, records map[string]Record, limit int) error {
+	seen := make(map[string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > len(records)+1 {
+			return fmt.Errorf("paging did not finish")
+		}
+		page, next, more, err := l.Since(cursor, limit)
+		if err != nil {
+			return err
+		}
+		for _, record := range page {
+			key := string(record.Kind) + "/" + record.ID
+			if seen[key] {
+				return fmt.Errorf("%s returned twice", key)
+			}
+			seen[key] = true
+		}
+		cursor = next
+		if !more {
+			break
+		}
+	}
+	if len(seen) != len(records) {
+		return fmt.Errorf("paging returned %d of %d records", len(seen), len(records))
+	}
+	if page, _, _, _ := l.Since(cursor, limit); len(page) != 0 {
+		return fmt.Errorf("final cursor still returns %d records", len(page))
+	}
+	return nil
+}
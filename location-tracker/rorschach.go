@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
 	"location-tracker/clients"
@@ -92,10 +91,10 @@ func handleRorschachInterpret(w http.ResponseWriter, r *http.Request) {
 
 	// Save to DynamoDB asynchronously
 	if useDynamoDB {
-		go func() {
+		background.Async("save_error_log", func() {
 			updatedLog := errorLogs[targetIndex]
 			saveErrorLogToDynamoDB(updatedLog)
-		}()
+		})
 	}
 
 	// Return interpretation
@@ -171,10 +170,10 @@ func handleRorschachUserResponse(w http.ResponseWriter, r *http.Request) {
 
 	// Save to DynamoDB asynchronously
 	if useDynamoDB {
-		go func() {
+		background.Async("save_error_log", func() {
 			updatedLog := errorLogs[targetIndex]
 			saveErrorLogToDynamoDB(updatedLog)
-		}()
+		})
 	}
 
 	log.Printf("✅ Saved user Rorschach response")
@@ -188,8 +187,7 @@ func handleRorschachUserResponse(w http.ResponseWriter, r *http.Request) {
 
 // generateRorschachInterpretation calls OpenAI to generate a humorous Freudian interpretation
 func generateRorschachInterpretation(imageNumber int, errorMessage string) (string, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	openAIClient := clients.NewOpenAIClient(apiKey)
+	openAIClient := clients.NewOpenAIClient(openaiAPIKey)
 
 	prompt := fmt.Sprintf(`You are a neurotic patient being shown Rorschach inkblot Card #%d during a psychological evaluation. You are acutely self-aware of your own neuroses and describe them with dark humor.
 
@@ -219,5 +217,5 @@ Example tones:
 		},
 	}
 
-	return openAIClient.ChatCompletion("gpt-4", messages)
+	return openAIClient.ChatCompletion(appConfig.Models.Rorschach, messages)
 }
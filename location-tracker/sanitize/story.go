@@ -0,0 +1,281 @@
+/*
+# Module: sanitize/story.go
+Allowlist sanitizer for AI-generated story HTML.
+
+Children's stories arrive from the error-generator as HTML fragments (paragraphs,
+details/summary reveals, links to real websites). Anything rendered outside the
+SPA - case pages, feeds, exports - passes through here so only a small set of
+formatting tags survives and links are restricted to http(s).
+
+## Linked Modules
+- [types/error_log](../types/error_log.go) - ChildrensStory field
+
+## Tags
+sanitize, html, security, story
+
+## Exports
+StoryHTML, StoryXHTML, PlainText, Paragraphs, Excerpt
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "sanitize/story.go" ;
+    code:description "Allowlist sanitizer for AI-generated story HTML" ;
+    code:linksTo [
+        code:name "types/error_log" ;
+        code:path "../types/error_log.go" ;
+        code:relationship "ChildrensStory field"
+    ] ;
+    code:exports :StoryHTML, :StoryXHTML, :PlainText, :Paragraphs, :Excerpt ;
+    code:tags "sanitize", "html", "security", "story" .
+<!-- End LinkedDoc RDF -->
+*/
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// allowedTags lists the elements kept by StoryHTML; everything else is dropped (text is kept)
+var allowedTags = map[string]bool{
+	"p": true, "br": true, "em": true, "strong": true, "i": true, "b": true, "u": true,
+	"details": true, "summary": true, "a": true, "ul": true, "ol": true, "li": true,
+	"blockquote": true, "h3": true, "h4": true,
+}
+
+// voidTags are emitted without a closing tag
+var voidTags = map[string]bool{"br": true}
+
+// droppedContentTags have their content removed along with the tag
+var droppedContentTags = map[string]bool{"script": true, "style": true, "iframe": true, "object": true, "template": true}
+
+// inlineTags are removed from plain text without inserting a word boundary
+var inlineTags = map[string]bool{"em": true, "strong": true, "i": true, "b": true, "u": true, "a": true, "span": true}
+
+var (
+	tagPattern  = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>|<!--.*?-->`)
+	hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*("([^"]*)"|'([^']*)'|([^\s>]+))`)
+	spaceRun    = regexp.MustCompile(`\s+`)
+)
+
+// StoryHTML returns story markup reduced to an allowlisted set of tags.
+// Attributes are stripped except href on links, which must be http(s); links
+// always open in a new tab with rel="noopener noreferrer nofollow".
+// Unbalanced tags are closed so the fragment can be embedded safely.
+func StoryHTML(input string) string {
+	var out strings.Builder
+	var open []string
+	skipUntil := ""
+
+	last := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(input, -1) {
+		if skipUntil == "" {
+			writeText(&out, input[last:m[0]])
+		}
+		last = m[1]
+
+		// Comment
+		if m[4] < 0 {
+			continue
+		}
+
+		closing := input[m[2]:m[3]] == "/"
+		name := strings.ToLower(input[m[4]:m[5]])
+		attrs := input[m[6]:m[7]]
+
+		if skipUntil != "" {
+			if closing && name == skipUntil {
+				skipUntil = ""
+			}
+			continue
+		}
+		if droppedContentTags[name] {
+			if !closing && !strings.HasSuffix(strings.TrimSpace(attrs), "/") {
+				skipUntil = name
+			}
+			continue
+		}
+		if !allowedTags[name] {
+			continue
+		}
+
+		if closing {
+			// Close back to the matching open tag, ignoring strays
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == name {
+					for j := len(open) - 1; j >= i; j-- {
+						out.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		if voidTags[name] {
+			out.WriteString("<" + name + ">")
+			continue
+		}
+
+		if name == "a" {
+			href := safeHref(attrs)
+			if href == "" {
+				out.WriteString("<a>")
+			} else {
+				out.WriteString(`<a href="` + html.EscapeString(href) + `" target="_blank" rel="noopener noreferrer nofollow">`)
+			}
+		} else {
+			out.WriteString("<" + name + ">")
+		}
+		open = append(open, name)
+	}
+	if skipUntil == "" {
+		writeText(&out, input[last:])
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// StoryXHTML returns StoryHTML output that is also well-formed XML (for EPUB):
+// void elements are self-closed and characters not allowed in XML are dropped.
+func StoryXHTML(input string) string {
+	sanitized := StoryHTML(strings.Map(xmlRune, input))
+	for name := range voidTags {
+		sanitized = strings.ReplaceAll(sanitized, "<"+name+">", "<"+name+"/>")
+	}
+	return sanitized
+}
+
+// xmlRune drops control characters that are not legal in XML 1.0
+func xmlRune(r rune) rune {
+	if r < 0x20 && r != '\t' && r != '\n' && r != '\r' || r == 0xFFFE || r == 0xFFFF {
+		return -1
+	}
+	return r
+}
+
+// PlainText strips all markup and collapses whitespace
+func PlainText(input string) string {
+	var out strings.Builder
+	skipUntil := ""
+	last := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(input, -1) {
+		if skipUntil == "" {
+			out.WriteString(input[last:m[0]])
+		}
+		last = m[1]
+		if m[4] < 0 {
+			continue
+		}
+		closing := input[m[2]:m[3]] == "/"
+		name := strings.ToLower(input[m[4]:m[5]])
+		if skipUntil != "" {
+			if closing && name == skipUntil {
+				skipUntil = ""
+			}
+			continue
+		}
+		if droppedContentTags[name] && !closing {
+			skipUntil = name
+			continue
+		}
+		// Block-level boundaries become spaces so words don't run together
+		if !inlineTags[name] {
+			out.WriteString(" ")
+		}
+	}
+	if skipUntil == "" {
+		out.WriteString(input[last:])
+	}
+	text := html.UnescapeString(out.String())
+	return strings.TrimSpace(spaceRun.ReplaceAllString(text, " "))
+}
+
+// Paragraphs splits a story into the plain text of each <p> element.
+// Stories without paragraph markup are returned as a single paragraph.
+func Paragraphs(input string) []string {
+	paragraphs := make([]string, 0)
+	lower := strings.ToLower(input)
+	pos := 0
+	for {
+		start := strings.Index(lower[pos:], "<p")
+		if start < 0 {
+			break
+		}
+		start += pos
+		// Make sure this is <p> or <p ...>, not <pre> etc.
+		next := start + 2
+		if next < len(lower) && lower[next] != '>' && lower[next] != ' ' && lower[next] != '\t' && lower[next] != '\n' {
+			pos = next
+			continue
+		}
+		bodyStart := strings.Index(lower[start:], ">")
+		if bodyStart < 0 {
+			break
+		}
+		bodyStart += start + 1
+		end := strings.Index(lower[bodyStart:], "</p>")
+		if end < 0 {
+			end = len(lower) - bodyStart
+		}
+		if text := PlainText(input[bodyStart : bodyStart+end]); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		pos = bodyStart + end
+	}
+
+	if len(paragraphs) == 0 {
+		if text := PlainText(input); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return paragraphs
+}
+
+// Excerpt returns the plain text of input truncated to maxLen runes on a word boundary
+func Excerpt(input string, maxLen int) string {
+	text := PlainText(input)
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	cut := string(runes[:maxLen])
+	if idx := strings.LastIndex(cut, " "); idx > maxLen/2 {
+		cut = cut[:idx]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// writeText escapes a text run, normalizing any entities already present
+func writeText(out *strings.Builder, text string) {
+	if text == "" {
+		return
+	}
+	out.WriteString(html.EscapeString(html.UnescapeString(text)))
+}
+
+// safeHref extracts an href attribute and returns it only if it is an absolute http(s) URL
+func safeHref(attrs string) string {
+	m := hrefPattern.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	raw := m[2] + m[3] + m[4]
+	raw = strings.TrimSpace(html.UnescapeString(raw))
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return parsed.String()
+}
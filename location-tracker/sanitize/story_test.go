@@ -0,0 +1,132 @@
+package sanitize
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var storyTests = []struct {
+	name  string
+	input string
+	want  string
+}{
+	{"allowed tags", `<p>Once <em>upon</em> a <strong>time</strong></p>`, `<p>Once <em>upon</em> a <strong>time</strong></p>`},
+	{"uppercase tags", `<P>Loud</P>`, `<p>Loud</p>`},
+	{"unknown tags keep text", `<div><span>kept</span></div>`, `kept`},
+	{"comment", `<p>a<!-- <script>alert(1)</script> -->b</p>`, `<p>ab</p>`},
+
+	{"script", `<p>hi<script>alert(1)</script></p>`, `<p>hi</p>`},
+	{"script with attributes", `<script type="text/javascript" src="x.js">alert(1)</script>ok`, `ok`},
+	{"script closing tag with space", `<script>alert(1)</script >ok`, `ok`},
+	{"unclosed script", `<p>hi</p><script>alert(1)<p>more</p>`, `<p>hi</p>`},
+	{"split script tag", `<scr<script>ipt>alert(1)</script>`, `ipt&gt;alert(1)`},
+	{"style", `<style>p { color: red }</style><p>x</p>`, `<p>x</p>`},
+	{"iframe", `<iframe src="https://evil.example"><p>inside</p></iframe>after`, `after`},
+	{"svg script", `<svg><script>alert(1)</script></svg>`, ``},
+
+	{"event handler", `<p onclick="alert(1)">x</p>`, `<p>x</p>`},
+	{"event handler on link", `<a href="https://example.com" onmouseover="alert(1)">x</a>`, `<a href="https://example.com" target="_blank" rel="noopener noreferrer nofollow">x</a>`},
+	{"style attribute", `<em style="background:url(javascript:alert(1))">x</em>`, `<em>x</em>`},
+	{"img onerror", `<img src=x onerror=alert(1)>`, ``},
+	{"quoted greater-than", `<p title="a>b" onclick="alert(1)">x</p>`, `<p>b&#34; onclick=&#34;alert(1)&#34;&gt;x</p>`},
+
+	{"https link", `<a href="https://example.com/a?b=1&amp;c=2">x</a>`, `<a href="https://example.com/a?b=1&amp;c=2" target="_blank" rel="noopener noreferrer nofollow">x</a>`},
+	{"single-quoted link", `<a href='http://example.com'>x</a>`, `<a href="http://example.com" target="_blank" rel="noopener noreferrer nofollow">x</a>`},
+	{"javascript url", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+	{"javascript url uppercase", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a>x</a>`},
+	{"javascript url padded", `<a href="  javascript:alert(1)">x</a>`, `<a>x</a>`},
+	{"javascript url unquoted", `<a href=javascript:alert(1)>x</a>`, `<a>x</a>`},
+	{"javascript url entity encoded", `<a href="&#106;avascript:alert(1)">x</a>`, `<a>x</a>`},
+	{"javascript url tab", `<a href="java&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+	{"data url", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a>x</a>`},
+	{"relative url", `<a href="/api/login">x</a>`, `<a>x</a>`},
+	{"href in another attribute", `<a title="href=javascript:alert(1)" href="https://example.com">x</a>`, `<a>x</a>`},
+
+	{"unclosed tags", `<p>one <em>two`, `<p>one <em>two</em></p>`},
+	{"misnested tags", `<p><em>a<strong>b</em>c</strong></p>`, `<p><em>a<strong>b</strong></em>c</p>`},
+	{"stray closing tag", `</p>a</em>`, `a`},
+	{"nested details", `<details><summary>Hint</summary><details><summary>More</summary>x</details></details>`, `<details><summary>Hint</summary><details><summary>More</summary>x</details></details>`},
+	{"line break", `a<br>b<br/>c`, `a<br>b<br>c`},
+
+	{"encoded script", `&lt;script&gt;alert(1)&lt;/script&gt;`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+	{"numeric encoded script", `&#60;script&#62;alert(1)&#60;/script&#62;`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+	{"double encoded script", `&amp;lt;script&amp;gt;`, `&amp;lt;script&amp;gt;`},
+	{"bare ampersand and quotes", `Fish & "chips" <3`, `Fish &amp; &#34;chips&#34; &lt;3`},
+}
+
+func TestStoryHTML(t *testing.T) {
+	for _, tt := range storyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StoryHTML(tt.input); got != tt.want {
+				t.Errorf("StoryHTML(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStoryXHTMLWellFormed parses every StoryXHTML output as XML and checks only
+// allowlisted elements and attributes come out
+func TestStoryXHTMLWellFormed(t *testing.T) {
+	inputs := []string{
+		"<p>control\x00chars\x0bdropped\x1f</p>",
+		"<p>a<br>b<BR/>c</p>",
+		`<p>&nbsp;&copy;&hellip; entities</p>`,
+		`<p>unclosed <a href="https://example.com/?a=1&b=2">link`,
+		"<p>\ufffe\uffff kept out</p>",
+	}
+	for _, tt := range storyTests {
+		inputs = append(inputs, tt.input)
+	}
+
+	allowedAttrs := map[string]bool{"href": true, "target": true, "rel": true}
+	for _, input := range inputs {
+		output := StoryXHTML(input)
+		decoder := xml.NewDecoder(strings.NewReader("<story>" + output + "</story>"))
+		for {
+			token, err := decoder.Token()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Errorf("StoryXHTML(%q) = %q is not well-formed: %v", input, output, err)
+				break
+			}
+			start, ok := token.(xml.StartElement)
+			if !ok || start.Name.Local == "story" {
+				continue
+			}
+			if !allowedTags[start.Name.Local] {
+				t.Errorf("StoryXHTML(%q) kept <%s>", input, start.Name.Local)
+			}
+			for _, attr := range start.Attr {
+				if !allowedAttrs[attr.Name.Local] || start.Name.Local != "a" {
+					t.Errorf("StoryXHTML(%q) kept %s=%q on <%s>", input, attr.Name.Local, attr.Value, start.Name.Local)
+				}
+			}
+		}
+	}
+
+	if got := StoryXHTML("a<br>b"); got != "a<br/>b" {
+		t.Errorf("StoryXHTML void element = %q, want a<br/>b", got)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`<p>One</p><p>two</p>`, `One two`},
+		{`<em>in</em>line`, `inline`},
+		{`a<script>alert(1)</script>b<style>p{}</style>c`, `abc`},
+		{`Fish &amp; chips&nbsp;&lt;3`, "Fish & chips\u00a0<3"},
+	}
+	for _, tt := range tests {
+		if got := PlainText(tt.input); got != tt.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -42,18 +42,20 @@ import (
 // BusinessService handles business search and discovery
 type BusinessService struct {
 	placesClient *clients.GooglePlacesClient
+	radiusMeters int
 }
 
-// NewBusinessService creates a new BusinessService instance
-func NewBusinessService(googleMapsAPIKey string) *BusinessService {
+// NewBusinessService creates a new BusinessService that searches within radiusMeters
+func NewBusinessService(googleMapsAPIKey string, radiusMeters int) *BusinessService {
 	return &BusinessService{
 		placesClient: clients.NewGooglePlacesClient(googleMapsAPIKey),
+		radiusMeters: radiusMeters,
 	}
 }
 
 // FetchNearbyBusinesses finds businesses near the given coordinates using Google Places API
 func (s *BusinessService) FetchNearbyBusinesses(lat, lng float64) ([]types.Business, error) {
-	return s.placesClient.SearchNearby(lat, lng, 500) // 500 meters radius
+	return s.placesClient.SearchNearby(lat, lng, s.radiusMeters)
 }
 
 // GetBusinessType returns a human-readable business type from Google Places types
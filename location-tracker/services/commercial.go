@@ -52,21 +52,26 @@ import (
 // CommercialService handles commercial real estate and governance queries
 type CommercialService struct {
 	perplexityClient *clients.PerplexityClient
+	model            string
+	radiusMiles      float64
 }
 
-// NewCommercialService creates a new CommercialService instance
-func NewCommercialService(perplexityAPIKey string) *CommercialService {
+// NewCommercialService creates a new CommercialService that asks model about a random
+// point within radiusMiles of each location
+func NewCommercialService(perplexityAPIKey, model string, radiusMiles float64) *CommercialService {
 	return &CommercialService{
 		perplexityClient: clients.NewPerplexityClient(perplexityAPIKey),
+		model:            model,
+		radiusMiles:      radiusMiles,
 	}
 }
 
 // SearchCommercialRealEstate searches for commercial properties and governing bodies near coordinates
 // Returns properties, governing bodies, query coordinates, and error
 func (s *CommercialService) SearchCommercialRealEstate(baseLat, baseLng float64, userKeywords []string) ([]types.CommercialPropertyDetails, []types.GoverningBody, float64, float64, error) {
-	// Generate random location within 10 mile radius
-	queryLat, queryLng := s.generateRandomLocationInRadius(baseLat, baseLng, 10.0)
-	log.Printf("🎲 Searching for commercial real estate at random location: (%.6f, %.6f) within 10 miles of base", queryLat, queryLng)
+	// Generate random location within the search radius
+	queryLat, queryLng := s.generateRandomLocationInRadius(baseLat, baseLng, s.radiusMiles)
+	log.Printf("🎲 Searching for commercial real estate at random location: (%.6f, %.6f) within %.0f miles of base", queryLat, queryLng, s.radiusMiles)
 
 	// Build satirical prompt that references user keywords if available
 	keywordContext := ""
@@ -97,7 +102,7 @@ Return ONLY valid JSON.`, queryLat, queryLng, keywordContext)
 	}
 
 	// Use client to make the API call
-	content, err := s.perplexityClient.ChatCompletion("sonar", messages)
+	content, err := s.perplexityClient.ChatCompletion(s.model, messages)
 	if err != nil {
 		log.Printf("⚠️  Perplexity API call failed: %v", err)
 		return []types.CommercialPropertyDetails{}, []types.GoverningBody{}, queryLat, queryLng, nil
@@ -0,0 +1,591 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"location-tracker/storage"
+	"location-tracker/types"
+)
+
+const (
+	// animatedShareFrameDelay is the output frame duration in centiseconds (10 fps)
+	animatedShareFrameDelay = 10
+	// animatedShareMaxFrames caps the length of the compilation loop
+	animatedShareMaxFrames = 40
+	// animatedShareMinDuration keeps very short GIFs from flickering (centiseconds)
+	animatedShareMinDuration = 100
+	// animatedShareLayoutVersion is mixed into render keys alongside shareImageLayoutVersion
+	animatedShareLayoutVersion = "animated-v1"
+
+	// animatedSourceMaxPixels caps the canvas of an animated source; larger GIFs
+	// contribute their first frame only
+	animatedSourceMaxPixels = 4 << 20
+	// animatedSourceMaxDecodedPixels caps the pixels of all frames of a source GIF, which
+	// is what decoding it allocates; longer GIFs contribute their first frame only
+	animatedSourceMaxDecodedPixels = 64 << 20
+)
+
+// animatedShareAttempts are tried in order until the encoded GIF fits the size budget.
+// frameStep 0 means a single still frame, which always fits.
+var animatedShareAttempts = []struct {
+	scale     float64
+	frameStep int
+}{
+	{0.5, 1},
+	{0.5, 2},
+	{0.375, 2},
+	{0.25, 3},
+	{0.25, 0},
+}
+
+// animatedSource is the media for one grid cell: the coalesced frames of a GIF,
+// or a single frame for still images. Only frames the output samples are kept (the
+// others are nil), already reduced to fit the largest cell.
+type animatedSource struct {
+	frames []*image.RGBA
+	delays []int // centiseconds per frame
+	total  int   // loop length in centiseconds
+}
+
+// maxAnimatedFrameStep is the largest frameStep of animatedShareAttempts
+func maxAnimatedFrameStep() int {
+	step := 1
+	for _, attempt := range animatedShareAttempts {
+		if attempt.frameStep > step {
+			step = attempt.frameStep
+		}
+	}
+	return step
+}
+
+// sampledFrames returns the indices of the source frames some attempt shows. Output
+// frames are animatedShareFrameDelay*frameStep apart and at most animatedShareMaxFrames
+// long, so every attempt samples within the first animatedShareMaxFrames*maxStep instants.
+func (src *animatedSource) sampledFrames() map[int]bool {
+	sampled := make(map[int]bool)
+	for j := 0; j < animatedShareMaxFrames*maxAnimatedFrameStep(); j++ {
+		sampled[src.frameIndexAt(j*animatedShareFrameDelay)] = true
+	}
+	return sampled
+}
+
+// frameIndexAt returns the index of the source frame visible t centiseconds into its loop
+func (src *animatedSource) frameIndexAt(t int) int {
+	if len(src.frames) == 1 || src.total == 0 {
+		return 0
+	}
+	t %= src.total
+	for i, delay := range src.delays {
+		if t < delay {
+			return i
+		}
+		t -= delay
+	}
+	return len(src.frames) - 1
+}
+
+// shareAnimatedImageKey derives the content-hash key of the animated share image
+func shareAnimatedImageKey(errorLog *types.ErrorLog, format ShareImageFormat) string {
+	pngKey := shareImageKey(errorLog, format)
+	return storage.ContentHashKey("share-gif/"+format.Name, ".gif", animatedShareLayoutVersion, pngKey)
+}
+
+// shareAnimatedImageURL returns the absolute URL of the animated share image
+func shareAnimatedImageURL(errorLogID string, formatName string) string {
+	url := fmt.Sprintf("%s/api/share-image/share_%s.gif", getBaseURL(), errorLogID)
+	if formatName != shareFormatOG.Name {
+		url += "?format=" + formatName
+	}
+	return url
+}
+
+// loadAnimatedSource downloads media and coalesces GIF frames; other formats become a
+// single frame. Frames are reduced to fit within maxWidth x maxHeight.
+func loadAnimatedSource(mediaURL string, maxWidth, maxHeight int) (*animatedSource, error) {
+	data, err := downloadImageData(mediaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config, formatName, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if formatName == "gif" {
+		frames, pixels, err := gifFramePixels(data)
+		switch {
+		case err != nil || frames < 2:
+		case config.Width*config.Height > animatedSourceMaxPixels || pixels > animatedSourceMaxDecodedPixels:
+			log.Printf("⚠️  %s is %dx%d with %d frames, using its first frame", mediaURL, config.Width, config.Height, frames)
+		default:
+			if decoded, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(decoded.Image) > 0 {
+				return coalesceGIF(decoded, maxWidth, maxHeight), nil
+			}
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return &animatedSource{frames: []*image.RGBA{toRGBA(resizeImage(img, maxWidth, maxHeight))}, delays: []int{0}}, nil
+}
+
+// gifFramePixels walks a GIF's blocks without decoding any image data and returns the
+// number of frames and their total pixel area, which is what gif.DecodeAll allocates
+func gifFramePixels(data []byte) (frames int, pixels int64, err error) {
+	if len(data) < 13 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (int(data[10]&7) + 1) // global color table
+	}
+	skipSubBlocks := func() error {
+		for {
+			if pos >= len(data) {
+				return io.ErrUnexpectedEOF
+			}
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				return nil
+			}
+		}
+	}
+
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension: introducer, label, sub-blocks
+			pos += 2
+			if err := skipSubBlocks(); err != nil {
+				return frames, pixels, err
+			}
+		case 0x2C: // image descriptor
+			if pos+10 > len(data) {
+				return frames, pixels, io.ErrUnexpectedEOF
+			}
+			width := int64(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int64(binary.LittleEndian.Uint16(data[pos+7:]))
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (int(flags&7) + 1) // local color table
+			}
+			pos++ // LZW minimum code size
+			if err := skipSubBlocks(); err != nil {
+				return frames, pixels, err
+			}
+			frames++
+			pixels += width * height
+		case 0x3B: // trailer
+			return frames, pixels, nil
+		default:
+			return frames, pixels, fmt.Errorf("unknown GIF block 0x%02x", data[pos])
+		}
+	}
+	return frames, pixels, nil
+}
+
+// coalesceGIF renders GIF frames onto a full canvas, honoring disposal methods, so each
+// frame can be drawn independently. Only sampled frames are kept, reduced to fit within
+// maxWidth x maxHeight.
+func coalesceGIF(decoded *gif.GIF, maxWidth, maxHeight int) *animatedSource {
+	bounds := image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height)
+	if bounds.Empty() {
+		bounds = decoded.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+	src := &animatedSource{frames: make([]*image.RGBA, len(decoded.Image))}
+
+	for i := range decoded.Image {
+		delay := 10
+		if i < len(decoded.Delay) && decoded.Delay[i] > 1 {
+			delay = decoded.Delay[i]
+		}
+		src.delays = append(src.delays, delay)
+		src.total += delay
+	}
+	sampled := src.sampledFrames()
+
+	for i, frame := range decoded.Image {
+		disposal := byte(0)
+		if i < len(decoded.Disposal) {
+			disposal = decoded.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if sampled[i] {
+			src.frames[i] = toRGBA(resizeImage(canvas, maxWidth, maxHeight))
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return src
+}
+
+// renderAnimatedShareImage renders the compilation as an animated GIF within share_images.gif_max_bytes
+func renderAnimatedShareImage(errorLog *types.ErrorLog, format ShareImageFormat) ([]byte, error) {
+	log.Printf("🎞️  Starting animated %s share image for error: %s", format.Name, errorLog.ID)
+
+	// No cell is ever larger than the whole media area of the first, largest attempt
+	_, _, area := animatedShareLayout(errorLog, format, animatedShareAttempts[0].scale)
+	sources := collectShareMedia(errorLog, func(mediaURL string) (*animatedSource, error) {
+		return loadAnimatedSource(mediaURL, area.Dx()-6, area.Dy()-6)
+	})
+	maxBytes := appConfig.ShareImages.GIFMaxBytes
+	cellFrames := make(map[cellFrameKey]image.Image)
+
+	for _, attempt := range animatedShareAttempts {
+		start := time.Now()
+		data, err := encodeAnimatedShare(errorLog, format, sources, attempt.scale, attempt.frameStep, cellFrames)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) <= maxBytes {
+			log.Printf("✅ Animated share image: %d bytes (scale %.3f, step %d) in %v", len(data), attempt.scale, attempt.frameStep, time.Since(start))
+			return data, nil
+		}
+		log.Printf("⚠️  Animated share image %d bytes over budget %d at scale %.3f step %d, retrying smaller", len(data), maxBytes, attempt.scale, attempt.frameStep)
+	}
+	return nil, fmt.Errorf("animated share image exceeds %d byte budget", maxBytes)
+}
+
+// cellFrameKey identifies a source frame scaled to a cell size
+type cellFrameKey struct {
+	source, frame, width, height int
+}
+
+// animatedShareLayout draws the share chrome at scale and returns the scaled format,
+// the chrome and the area left for media
+func animatedShareLayout(errorLog *types.ErrorLog, format ShareImageFormat, scale float64) (ShareImageFormat, *image.RGBA, image.Rectangle) {
+	scaled := ShareImageFormat{
+		Name:      format.Name,
+		Width:     int(float64(format.Width) * scale),
+		Height:    int(float64(format.Height) * scale),
+		FontScale: format.FontScale * scale * 1.6, // keep text legible at reduced size
+		TextRoom:  format.TextRoom,
+	}
+	base := image.NewRGBA(image.Rect(0, 0, scaled.Width, scaled.Height))
+	area := drawShareChrome(base, errorLog, scaled)
+	return scaled, base, area
+}
+
+// encodeAnimatedShare lays out all sources on the share chrome for every output frame and
+// encodes a GIF. Source frames are scaled to their cell on first use and kept in
+// cellFrames, so attempts with the same cell size share them.
+func encodeAnimatedShare(errorLog *types.ErrorLog, format ShareImageFormat, sources []*animatedSource, scale float64, frameStep int, cellFrames map[cellFrameKey]image.Image) ([]byte, error) {
+	// Chrome (background, error banner, slogan, footer) is drawn once and copied into every frame
+	scaled, base, area := animatedShareLayout(errorLog, format, scale)
+	if len(sources) == 0 {
+		drawTextBoxSized(base, "No visual media available for this error", area.Min.X, area.Min.Y, area.Dx(), 60, color.RGBA{180, 180, 180, 255}, 18*scaled.FontScale)
+	}
+
+	cells := gridCells(len(sources), area)
+	duration := 0
+	for i := range cells {
+		if sources[i].total > duration {
+			duration = sources[i].total
+		}
+	}
+	cellFrame := func(i, index int) image.Image {
+		key := cellFrameKey{source: i, frame: index, width: cells[i].Dx() - 6, height: cells[i].Dy() - 6}
+		img, ok := cellFrames[key]
+		if !ok {
+			img = resizeImage(sources[i].frames[index], key.width, key.height)
+			cellFrames[key] = img
+		}
+		return img
+	}
+
+	frameCount := 1
+	frameDelay := animatedShareFrameDelay
+	if frameStep > 0 && duration > 0 {
+		frameDelay = animatedShareFrameDelay * frameStep
+		if duration < animatedShareMinDuration {
+			duration = animatedShareMinDuration
+		}
+		frameCount = (duration + frameDelay - 1) / frameDelay
+		if frameCount > animatedShareMaxFrames {
+			frameCount = animatedShareMaxFrames
+		}
+	}
+
+	frames := make([]*image.RGBA, frameCount)
+	for k := 0; k < frameCount; k++ {
+		t := k * frameDelay
+		frame := cloneRGBA(base)
+		for i, cell := range cells {
+			img := cellFrame(i, sources[i].frameIndexAt(t))
+			offsetX := cell.Min.X + (cell.Dx()-img.Bounds().Dx())/2
+			offsetY := cell.Min.Y + (cell.Dy()-img.Bounds().Dy())/2
+			draw.Draw(frame, image.Rect(offsetX, offsetY, offsetX+img.Bounds().Dx(), offsetY+img.Bounds().Dy()), img, img.Bounds().Min, draw.Over)
+		}
+		frames[k] = frame
+	}
+
+	return encodeOptimizedGIF(frames, frameDelay)
+}
+
+// encodeOptimizedGIF quantizes frames to one median-cut palette and stores only the
+// changed rectangle of each frame, with unchanged pixels transparent
+func encodeOptimizedGIF(frames []*image.RGBA, delay int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames to encode")
+	}
+
+	palette := medianCutPalette(frames, 255)
+	transparentIndex := uint8(len(palette))
+	palette = append(palette, color.RGBA{0, 0, 0, 0})
+	lookup := newPaletteLookup(palette[:transparentIndex])
+
+	bounds := frames[0].Bounds()
+	output := &gif.GIF{
+		LoopCount: 0,
+		Config: image.Config{
+			ColorModel: palette,
+			Width:      bounds.Dx(),
+			Height:     bounds.Dy(),
+		},
+	}
+
+	var previous []uint8
+	for _, frame := range frames {
+		indices := make([]uint8, bounds.Dx()*bounds.Dy())
+		for y := 0; y < bounds.Dy(); y++ {
+			row := frame.Pix[y*frame.Stride:]
+			for x := 0; x < bounds.Dx(); x++ {
+				indices[y*bounds.Dx()+x] = lookup.index(row[x*4], row[x*4+1], row[x*4+2])
+			}
+		}
+
+		if previous == nil {
+			paletted := image.NewPaletted(bounds, palette)
+			copy(paletted.Pix, indices)
+			output.Image = append(output.Image, paletted)
+			output.Delay = append(output.Delay, delay)
+			output.Disposal = append(output.Disposal, gif.DisposalNone)
+			previous = indices
+			continue
+		}
+
+		changed := changedBounds(previous, indices, bounds.Dx(), bounds.Dy())
+		if changed.Empty() {
+			// Identical frame: extend the previous frame instead of emitting a new one
+			output.Delay[len(output.Delay)-1] += delay
+			continue
+		}
+
+		paletted := image.NewPaletted(changed, palette)
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			for x := changed.Min.X; x < changed.Max.X; x++ {
+				offset := y*bounds.Dx() + x
+				value := indices[offset]
+				if value == previous[offset] {
+					value = transparentIndex
+				}
+				paletted.Pix[(y-changed.Min.Y)*paletted.Stride+(x-changed.Min.X)] = value
+			}
+		}
+		output.Image = append(output.Image, paletted)
+		output.Delay = append(output.Delay, delay)
+		output.Disposal = append(output.Disposal, gif.DisposalNone)
+		previous = indices
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, output); err != nil {
+		return nil, fmt.Errorf("failed to encode animated GIF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// changedBounds returns the bounding box of pixels that differ between two index buffers
+func changedBounds(previous, current []uint8, width, height int) image.Rectangle {
+	minX, minY, maxX, maxY := width, height, -1, -1
+	for y := 0; y < height; y++ {
+		rowOffset := y * width
+		for x := 0; x < width; x++ {
+			if previous[rowOffset+x] != current[rowOffset+x] {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+				if y < minY {
+					minY = y
+				}
+				maxY = y
+			}
+		}
+	}
+	if maxX < 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
+
+// medianCutPalette builds a palette of up to size colors by recursively splitting
+// a pixel sample along its widest color channel
+func medianCutPalette(frames []*image.RGBA, size int) color.Palette {
+	const maxSamples = 1 << 16
+
+	totalPixels := 0
+	for _, frame := range frames {
+		totalPixels += frame.Bounds().Dx() * frame.Bounds().Dy()
+	}
+	step := totalPixels/maxSamples + 1
+
+	samples := make([][3]uint8, 0, maxSamples+len(frames))
+	for _, frame := range frames {
+		for offset := 0; offset+3 < len(frame.Pix); offset += 4 * step {
+			samples = append(samples, [3]uint8{frame.Pix[offset], frame.Pix[offset+1], frame.Pix[offset+2]})
+		}
+	}
+
+	boxes := [][][3]uint8{samples}
+	for len(boxes) < size {
+		splitIndex, splitChannel, widest := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			channel, spread := widestChannel(box)
+			if spread > widest {
+				splitIndex, splitChannel, widest = i, channel, spread
+			}
+		}
+		if splitIndex < 0 {
+			break
+		}
+
+		box := boxes[splitIndex]
+		sort.Slice(box, func(a, b int) bool { return box[a][splitChannel] < box[b][splitChannel] })
+		mid := len(box) / 2
+		boxes[splitIndex] = box[:mid]
+		boxes = append(boxes, box[mid:])
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		if len(box) == 0 {
+			continue
+		}
+		var r, g, b int
+		for _, c := range box {
+			r += int(c[0])
+			g += int(c[1])
+			b += int(c[2])
+		}
+		n := len(box)
+		palette = append(palette, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255})
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.RGBA{0, 0, 0, 255})
+	}
+	return palette
+}
+
+// widestChannel returns the RGB channel with the largest value range in box
+func widestChannel(box [][3]uint8) (int, int) {
+	minC := [3]uint8{255, 255, 255}
+	maxC := [3]uint8{}
+	for _, c := range box {
+		for ch := 0; ch < 3; ch++ {
+			if c[ch] < minC[ch] {
+				minC[ch] = c[ch]
+			}
+			if c[ch] > maxC[ch] {
+				maxC[ch] = c[ch]
+			}
+		}
+	}
+	channel, spread := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		if s := int(maxC[ch]) - int(minC[ch]); s > spread {
+			channel, spread = ch, s
+		}
+	}
+	return channel, spread
+}
+
+// paletteLookup maps RGB colors to the nearest palette index, memoized on 15-bit color
+type paletteLookup struct {
+	palette color.Palette
+	cache   [1 << 15]int16
+}
+
+func newPaletteLookup(palette color.Palette) *paletteLookup {
+	lookup := &paletteLookup{palette: palette}
+	for i := range lookup.cache {
+		lookup.cache[i] = -1
+	}
+	return lookup
+}
+
+func (p *paletteLookup) index(r, g, b uint8) uint8 {
+	key := int(r>>3)<<10 | int(g>>3)<<5 | int(b>>3)
+	if cached := p.cache[key]; cached >= 0 {
+		return uint8(cached)
+	}
+
+	best, bestDistance := 0, 1<<30
+	for i, entry := range p.palette {
+		c := entry.(color.RGBA)
+		dr := int(c.R) - int(r)
+		dg := int(c.G) - int(g)
+		db := int(c.B) - int(b)
+		distance := 3*dr*dr + 4*dg*dg + 2*db*db
+		if distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	p.cache[key] = int16(best)
+	return uint8(best)
+}
+
+// toRGBA converts any image to RGBA with its origin at (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// cloneRGBA returns a deep copy of img
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := &image.RGBA{
+		Pix:    make([]uint8, len(img.Pix)),
+		Stride: img.Stride,
+		Rect:   img.Rect,
+	}
+	copy(clone.Pix, img.Pix)
+	return clone
+}
+
+// isAnimatedShareRequest reports whether a share image path asks for the GIF variant
+func isAnimatedShareRequest(shareID string) bool {
+	return strings.HasSuffix(shareID, ".gif")
+}
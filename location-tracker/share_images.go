@@ -0,0 +1,368 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"location-tracker/storage"
+	"location-tracker/types"
+)
+
+// ShareImageFormat describes one output size of the share image layout
+type ShareImageFormat struct {
+	Name      string
+	Width     int
+	Height    int
+	FontScale float64 // Multiplier applied to header/message font sizes
+	TextRoom  float64 // Multiplier for the height budget of message and slogan boxes
+}
+
+var (
+	shareFormatOG     = ShareImageFormat{Name: "og", Width: fbShareWidth, Height: fbShareHeight, FontScale: 1.0, TextRoom: 1.0}
+	shareFormatStory  = ShareImageFormat{Name: "story", Width: 1080, Height: 1920, FontScale: 1.8, TextRoom: 2.5}
+	shareFormatSquare = ShareImageFormat{Name: "square", Width: 1080, Height: 1080, FontScale: 1.3, TextRoom: 1.5}
+
+	// shareImageFormats lists every format served from /api/share-image/
+	shareImageFormats = map[string]ShareImageFormat{
+		shareFormatOG.Name:     shareFormatOG,
+		shareFormatStory.Name:  shareFormatStory,
+		shareFormatSquare.Name: shareFormatSquare,
+	}
+)
+
+// shareImageLayoutVersion is mixed into render keys; bump it when the layout changes
+// so previously stored renders are not served for the new design
+const shareImageLayoutVersion = "share-layout-v2"
+
+// errRenderQueueFull is returned when the background render queue cannot accept more work
+var errRenderQueueFull = errors.New("render queue full")
+
+var (
+	// blobStore persists generated assets (share images, comics, cached media)
+	blobStore storage.BlobStore
+
+	// shareImageService renders and caches share images
+	shareImageService *RenderCacheService
+)
+
+// shareImageKey derives the content-hash key for an error log's share image.
+// Only fields that affect the rendered output participate in the hash.
+func shareImageKey(errorLog *types.ErrorLog, format ShareImageFormat) string {
+	return storage.ContentHashKey("share/"+format.Name, ".png",
+		shareImageLayoutVersion,
+		format.Name,
+		errorLog.ID,
+		errorLog.Message,
+		errorLog.Slogan,
+		errorLog.MemeURL,
+		errorLog.FoodImageURL,
+		errorLog.GifURL,
+		strings.Join(errorLog.GifURLs, "\n"),
+		errorLog.Timestamp.UTC().Format(time.RFC3339Nano),
+	)
+}
+
+// shareImageFormatURL returns the absolute URL of a specific share image format
+func shareImageFormatURL(errorLogID string, formatName string) string {
+	if formatName == shareFormatOG.Name {
+		return shareImageURL(errorLogID)
+	}
+	return fmt.Sprintf("%s?format=%s", shareImageURL(errorLogID), formatName)
+}
+
+// lruCache is a byte-bounded least-recently-used cache of rendered assets
+type lruCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	curBytes int64
+	order    *list.List
+	items    map[string]*list.Element
+	hits     int64
+	misses   int64
+}
+
+type lruEntry struct {
+	key         string
+	data        []byte
+	contentType string
+}
+
+// newLRUCache creates a cache that evicts once maxBytes is exceeded
+func newLRUCache(maxBytes int64) *lruCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns a cached entry and marks it most recently used
+func (c *lruCache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, "", false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	entry := element.Value.(*lruEntry)
+	return entry.data, entry.contentType, true
+}
+
+// Add inserts or replaces an entry, evicting the oldest entries to stay within budget.
+// Entries larger than the whole budget are not cached.
+func (c *lruCache) Add(key string, data []byte, contentType string) {
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		c.curBytes += size - int64(len(entry.data))
+		entry.data = data
+		entry.contentType = contentType
+		c.order.MoveToFront(element)
+	} else {
+		c.items[key] = c.order.PushFront(&lruEntry{key: key, data: data, contentType: contentType})
+		c.curBytes += size
+	}
+
+	for c.curBytes > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		entry := oldest.Value.(*lruEntry)
+		c.order.Remove(oldest)
+		delete(c.items, entry.key)
+		c.curBytes -= int64(len(entry.data))
+	}
+}
+
+// Stats returns entry count, bytes used, hits and misses
+func (c *lruCache) Stats() (entries int, bytes int64, hits int64, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items), c.curBytes, c.hits, c.misses
+}
+
+// renderJob is a unit of work for the background render workers
+type renderJob struct {
+	key         string
+	contentType string
+	render      func() ([]byte, error)
+}
+
+// renderCall tracks an in-flight render so concurrent requests share one result
+type renderCall struct {
+	done        chan struct{}
+	data        []byte
+	contentType string
+	err         error
+}
+
+// RenderCacheService fronts expensive renders with an in-memory LRU, a durable
+// blob store and a bounded background render queue with request coalescing
+type RenderCacheService struct {
+	store    storage.BlobStore
+	cache    *lruCache
+	queue    chan renderJob
+	mu       sync.Mutex
+	inflight map[string]*renderCall
+}
+
+// NewRenderCacheService starts workers goroutines consuming a queue of queueSize jobs
+func NewRenderCacheService(store storage.BlobStore, cacheBytes int64, workers int, queueSize int) *RenderCacheService {
+	service := &RenderCacheService{
+		store:    store,
+		cache:    newLRUCache(cacheBytes),
+		queue:    make(chan renderJob, queueSize),
+		inflight: make(map[string]*renderCall),
+	}
+	for i := 0; i < workers; i++ {
+		background.Go("share_image_render", service.worker)
+	}
+	return service
+}
+
+// worker renders queued jobs, persists the result and wakes any waiters
+func (s *RenderCacheService) worker(ctx context.Context) error {
+	for {
+		var job renderJob
+		select {
+		case <-ctx.Done():
+			return nil
+		case job = <-s.queue:
+		}
+		s.process(job)
+	}
+}
+
+// process renders and persists one job. Waiters are always released, even when the
+// render or the store panics; a panicking render becomes the job's error.
+func (s *RenderCacheService) process(job renderJob) {
+	var data []byte
+	err := errors.New("render did not complete")
+	defer func() {
+		s.mu.Lock()
+		call := s.inflight[job.key]
+		delete(s.inflight, job.key)
+		s.mu.Unlock()
+
+		if call != nil {
+			call.data, call.contentType, call.err = data, job.contentType, err
+			close(call.done)
+		}
+	}()
+
+	data, err = renderRecovered(job.render)
+	if err != nil {
+		data = nil
+		log.Printf("❌ Render failed for %s: %v", job.key, err)
+		return
+	}
+	s.cache.Add(job.key, data, job.contentType)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if putErr := s.store.Put(ctx, job.key, data, job.contentType); putErr != nil {
+		log.Printf("⚠️  Failed to persist render %s: %v", job.key, putErr)
+	}
+}
+
+// renderRecovered runs render, turning a panic into an error
+func renderRecovered(render func() ([]byte, error)) (data []byte, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			data, err = nil, fmt.Errorf("render panicked: %v", recovered)
+		}
+	}()
+	return render()
+}
+
+// lookup checks the LRU, then the blob store (promoting hits into the LRU)
+func (s *RenderCacheService) lookup(ctx context.Context, key string) ([]byte, string, bool) {
+	if data, contentType, ok := s.cache.Get(key); ok {
+		return data, contentType, true
+	}
+	data, contentType, err := s.store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, storage.ErrBlobNotFound) {
+			log.Printf("⚠️  Blob store read failed for %s: %v", key, err)
+		}
+		return nil, "", false
+	}
+	s.cache.Add(key, data, contentType)
+	return data, contentType, true
+}
+
+// enqueue schedules a render unless one is already in flight for key
+func (s *RenderCacheService) enqueue(key, contentType string, render func() ([]byte, error)) (*renderCall, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if call, ok := s.inflight[key]; ok {
+		return call, nil
+	}
+	call := &renderCall{done: make(chan struct{})}
+	select {
+	case s.queue <- renderJob{key: key, contentType: contentType, render: render}:
+		s.inflight[key] = call
+		return call, nil
+	default:
+		return nil, errRenderQueueFull
+	}
+}
+
+// Get returns the asset for key, rendering it through the queue if it is not cached.
+// It waits for the render until ctx is done.
+func (s *RenderCacheService) Get(ctx context.Context, key, contentType string, render func() ([]byte, error)) ([]byte, string, error) {
+	if data, storedType, ok := s.lookup(ctx, key); ok {
+		return data, storedType, nil
+	}
+
+	call, err := s.enqueue(key, contentType, render)
+	if err != nil {
+		return nil, "", err
+	}
+
+	select {
+	case <-call.done:
+		return call.data, call.contentType, call.err
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
+
+// Prefetch schedules a render in the background if the asset is not already stored
+func (s *RenderCacheService) Prefetch(key, contentType string, render func() ([]byte, error)) {
+	background.Async("share_image_prefetch", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, _, ok := s.lookup(ctx, key); ok {
+			return
+		}
+		if _, err := s.enqueue(key, contentType, render); err != nil {
+			log.Printf("⚠️  Skipping prefetch of %s: %v", key, err)
+		}
+	})
+}
+
+// GetShareImage returns the PNG share image of an error log in the given format
+func (s *RenderCacheService) GetShareImage(ctx context.Context, errorLog *types.ErrorLog, format ShareImageFormat) ([]byte, string, error) {
+	snapshot := *errorLog
+	return s.Get(ctx, shareImageKey(&snapshot, format), "image/png", func() ([]byte, error) {
+		return renderShareImage(&snapshot, format)
+	})
+}
+
+// PrefetchShareImage renders a share image in the background
+func (s *RenderCacheService) PrefetchShareImage(errorLog *types.ErrorLog, format ShareImageFormat) {
+	snapshot := *errorLog
+	s.Prefetch(shareImageKey(&snapshot, format), "image/png", func() ([]byte, error) {
+		return renderShareImage(&snapshot, format)
+	})
+}
+
+// initializeShareImages configures the blob store and render cache from
+// appConfig.ShareImages. An S3 bucket selects S3 (s3_endpoint for S3-compatible
+// services), otherwise images are kept on local disk under dir.
+func initializeShareImages() {
+	settings := appConfig.ShareImages
+	if settings.S3Bucket != "" {
+		s3Store, err := storage.NewS3BlobStore(context.Background(), settings.S3Region, settings.S3Bucket, settings.S3Prefix, settings.S3Endpoint)
+		if err != nil {
+			log.Printf("⚠️  S3 blob store unavailable: %v", err)
+		} else {
+			blobStore = s3Store
+			log.Printf("🪣 Share images stored in S3 bucket %s", settings.S3Bucket)
+		}
+	}
+
+	if blobStore == nil {
+		localStore, err := storage.NewLocalBlobStore(settings.Dir, int64(settings.StoreMB)*1024*1024)
+		if err != nil {
+			// Memory is scarcer than disk: hold no more than the LRU would
+			log.Printf("⚠️  Local blob store unavailable (%v), keeping share images in memory", err)
+			blobStore = storage.NewMemoryBlobStore(int64(settings.CacheMB) * 1024 * 1024)
+		} else {
+			blobStore = localStore
+			log.Printf("🗂️  Share images stored in %s (up to %d MB)", settings.Dir, settings.StoreMB)
+		}
+	}
+
+	shareImageService = NewRenderCacheService(blobStore, int64(settings.CacheMB)*1024*1024, settings.Workers, settings.QueueSize)
+	log.Printf("🖼️  Share image renderer ready (%d workers, %d MB cache)", settings.Workers, settings.CacheMB)
+}
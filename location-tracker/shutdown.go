@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"location-tracker/supervisor"
+)
+
+// background runs the long-lived workers and the fire-and-forget writes, so shutdown can
+// stop the former and wait for the latter
+var background = supervisor.New(context.Background(), supervisor.Options{})
+
+// serve starts the servers, over TLS when they have a TLSConfig, and blocks until
+// SIGINT or SIGTERM. Shutdown then has SHUTDOWN_TIMEOUT (default 25s, inside the usual
+// 30s grace period): the servers stop accepting connections and finish in-flight
+// requests, then background workers are cancelled and pending writes waited for.
+func serve(servers ...*http.Server) {
+	for _, server := range servers {
+		go func(server *http.Server) {
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ListenAndServeTLS("", "")
+			} else {
+				err = server.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("❌ Server on %s failed: %v", server.Addr, err)
+			}
+		}(server)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	timeout := appConfig.Server.ShutdownTimeout
+	log.Printf("🛑 Shutting down (up to %s)...", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Printf("⚠️  Server on %s did not drain: %v", server.Addr, err)
+			}
+		}(server)
+	}
+	wg.Wait()
+
+	if err := background.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  %v", err)
+		return
+	}
+	log.Printf("✅ Shutdown complete")
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"strings"
+	"time"
+
+	"location-tracker/mobility"
+	"location-tracker/replica"
+	"location-tracker/types"
+)
+
+// runSimulateTrajectoryCommand generates a simulated trajectory, writes it as GPX and/or
+// sends it to a running server: pushed through /api/sync with its own timestamps, or
+// posted point by point to /api/location with -realtime
+func runSimulateTrajectoryCommand(args []string) int {
+	flags := flag.NewFlagSet("simulate-trajectory", flag.ContinueOnError)
+	seed := flags.Int64("seed", 1, "Random seed; equal flags give equal trajectories")
+	model := flags.String("model", string(mobility.RandomWaypoint), "Mobility model: waypoint or levy")
+	mode := flags.String("mode", string(mobility.Walk), "Travel mode: walk, bike or drive")
+	lat := flags.Float64("lat", 0, "Starting latitude (centre of the area)")
+	lng := flags.Float64("lng", 0, "Starting longitude (centre of the area)")
+	radius := flags.Float64("radius", 2000, "Area radius in metres")
+	duration := flags.Duration("duration", 2*time.Hour, "Length of the trajectory")
+	interval := flags.Duration("interval", 30*time.Second, "Time between points")
+	noise := flags.Float64("noise", 8, "GPS noise (standard deviation per axis) in metres")
+	start := flags.String("start", "", "Start time, RFC 3339 (default now)")
+	poisFile := flags.String("pois", "", "JSON file of places to visit ([]types.Business or []mobility.POI)")
+	gpxFile := flags.String("gpx", "", "Write the trajectory to this GPX file")
+	server := flags.String("server", "", "Send points to this server (e.g. http://localhost:8080)")
+	device := flags.String("device", "", "Device ID for posted points (default sim-<seed>)")
+	realtime := flags.Bool("realtime", false, "Post points to /api/location at the pace of their timestamps, stamped with the server's clock")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *gpxFile == "" && *server == "" {
+		log.Printf("❌ Nothing to do: give -gpx and/or -server")
+		return 2
+	}
+	if *device == "" {
+		*device = fmt.Sprintf("sim-%d", *seed)
+	}
+
+	cfg := mobility.Config{
+		Seed:      *seed,
+		Model:     mobility.Model(*model),
+		Mode:      mobility.Mode(*mode),
+		Latitude:  *lat,
+		Longitude: *lng,
+		Radius:    *radius,
+		Start:     time.Now().UTC().Truncate(time.Second),
+		Duration:  *duration,
+		Interval:  *interval,
+		GPSNoise:  *noise,
+	}
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			log.Printf("❌ Invalid -start: %v", err)
+			return 2
+		}
+		cfg.Start = t
+	}
+
+	var client *http.Client
+	if *server != "" {
+		var err error
+		client, err = loginToServer(*server)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+	}
+
+	switch {
+	case *poisFile != "":
+		pois, err := loadPOIs(*poisFile)
+		if err != nil {
+			log.Printf("❌ Failed to load POIs: %v", err)
+			return 2
+		}
+		cfg.POIs = pois
+	case client != nil:
+		// The server's business cache; it changes over time, so use -pois for repeatable runs
+		pois, err := fetchServerPOIs(client, *server)
+		if err != nil {
+			log.Printf("⚠️  No POIs from server: %v", err)
+		}
+		cfg.POIs = pois
+	}
+
+	points, err := mobility.Generate(cfg)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 2
+	}
+	log.Printf("🧭 Generated %d points (%s, %s, seed %d, %d POIs)", len(points), cfg.Model, cfg.Mode, cfg.Seed, len(cfg.POIs))
+
+	if *gpxFile != "" {
+		f, err := os.Create(*gpxFile)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		err = mobility.WriteGPX(f, fmt.Sprintf("%s %s (seed %d)", cfg.Model, cfg.Mode, cfg.Seed), points)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			log.Printf("❌ Failed to write GPX: %v", err)
+			return 1
+		}
+		log.Printf("✅ GPX written to %s", *gpxFile)
+	}
+
+	if client != nil && *realtime {
+		if err := postTrajectory(client, *server, *device, points); err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		log.Printf("✅ Posted %d simulated points for %s", len(points), *device)
+	} else if client != nil {
+		applied, err := pushTrajectory(client, *server, *device, points)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 1
+		}
+		log.Printf("✅ Synced %d simulated points for %s (%d kept, the rest lost to newer versions)", len(points), *device, applied)
+	}
+	return 0
+}
+
+// loginToServer logs in with TRACKER_PASSWORD and returns a client carrying the auth cookie
+func loginToServer(server string) (*http.Client, error) {
+	password := appConfig.Auth.TrackerPassword
+	if password == "" {
+		return nil, fmt.Errorf("TRACKER_PASSWORD must be set to post to a server")
+	}
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar, Timeout: 30 * time.Second}
+
+	body, _ := json.Marshal(map[string]string{"password": password})
+	resp, err := client.Post(strings.TrimRight(server, "/")+"/api/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("login failed: %s", resp.Status)
+	}
+	return client, nil
+}
+
+// loadPOIs reads places from a JSON file, either cached businesses or POIs
+func loadPOIs(path string) ([]mobility.POI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var businesses []types.Business
+	if err := json.Unmarshal(data, &businesses); err == nil {
+		if pois := mobility.POIsFromBusinesses(businesses); len(pois) > 0 {
+			return pois, nil
+		}
+	}
+	var pois []mobility.POI
+	if err := json.Unmarshal(data, &pois); err != nil {
+		return nil, err
+	}
+	return pois, nil
+}
+
+// fetchServerPOIs reads the server's current nearby businesses
+func fetchServerPOIs(client *http.Client, server string) ([]mobility.POI, error) {
+	resp, err := client.Get(strings.TrimRight(server, "/") + "/api/businesses")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /api/businesses: %s", resp.Status)
+	}
+
+	var result struct {
+		Businesses []types.Business `json:"businesses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return mobility.POIsFromBusinesses(result.Businesses), nil
+}
+
+// postTrajectory sends points through the normal ingestion path, marked as simulated, at
+// the pace of their timestamps. The server stamps each point with its own clock.
+func postTrajectory(client *http.Client, server, deviceID string, points []mobility.Point) error {
+	url := strings.TrimRight(server, "/") + "/api/location"
+	for i, p := range points {
+		if i > 0 {
+			time.Sleep(p.Time.Sub(points[i-1].Time))
+		}
+
+		body, _ := json.Marshal(types.Location{
+			Latitude:     p.Latitude,
+			Longitude:    p.Longitude,
+			Accuracy:     p.Accuracy,
+			DeviceID:     deviceID,
+			Simulated:    true,
+			LocationName: p.Place,
+		})
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("point %d: %w", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("point %d: %s", i, resp.Status)
+		}
+	}
+	return nil
+}
+
+// pushTrajectory pushes points through /api/sync as one device's location history,
+// versioned by their own timestamps, and returns how many were stored. Each stored point
+// spends the device's privacy budget at the server's clock, so a long trajectory pushed at
+// once runs out of budget and its later points share the last fuzzed one.
+func pushTrajectory(client *http.Client, server, deviceID string, points []mobility.Point) (int, error) {
+	// Pull as little as possible; the simulator only reads the push results
+	url := strings.TrimRight(server, "/") + "/api/sync?limit=1"
+	applied := 0
+	var base *replica.Timestamp
+
+	for start := 0; start < len(points); start += syncMaxChanges {
+		end := min(start+syncMaxChanges, len(points))
+		var req syncRequest
+		for _, p := range points[start:end] {
+			version := replica.Timestamp{Wall: p.Time.UnixMilli(), Node: deviceID}
+			req.Changes = append(req.Changes, replica.Change{
+				Record: replica.Record{
+					Kind:    replica.KindLocation,
+					ID:      deviceID,
+					Version: version,
+					Data: locationSyncData(types.Location{
+						Latitude:     p.Latitude,
+						Longitude:    p.Longitude,
+						Accuracy:     p.Accuracy,
+						Timestamp:    p.Time,
+						DeviceID:     deviceID,
+						Simulated:    true,
+						LocationName: p.Place,
+					}),
+				},
+				Base: base,
+			})
+			base = &version
+		}
+
+		body, _ := json.Marshal(req)
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return applied, fmt.Errorf("points %d-%d: %w", start, end-1, err)
+		}
+		var result syncResponse
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&result)
+		} else {
+			err = fmt.Errorf("%s", resp.Status)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return applied, fmt.Errorf("points %d-%d: %w", start, end-1, err)
+		}
+
+		for i, status := range result.Results {
+			switch status.Status {
+			case "rejected":
+				return applied, fmt.Errorf("point %d: %s", start+i, status.Reason)
+			case string(replica.Applied):
+				applied++
+			}
+		}
+	}
+	return applied, nil
+}
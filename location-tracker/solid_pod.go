@@ -0,0 +1,827 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"location-tracker/types"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid/dynamodbstore"
+)
+
+const (
+	solidSessionCookie = "solid_session"
+	solidStateCookie   = "solid_state"
+	solidLoginTTL      = 10 * time.Minute
+	solidWriteTimeout  = 30 * time.Second
+)
+
+// solidPendingLogin is an authorization request waiting for the provider's redirect
+type solidPendingLogin struct {
+	Meta       *solid.ProviderMetadata
+	ClientID   string
+	PKCE       *solid.PKCE
+	Nonce      string
+	Key        *solid.DPoPKey
+	FullAccess bool // the browser was already password-authenticated
+	CreatedAt  time.Time
+}
+
+// Session attributes of a connected pod
+const (
+	solidFullAccessAttr  = "full_access"  // receives unredacted error logs
+	solidGrantedAuthAttr = "granted_auth" // the Solid login also set the tracker auth cookie
+)
+
+var (
+	// Solid login is opt-in while the integration is in beta
+	solidEnabled bool
+
+	// WebIDs that get full tracker access when they log in with Solid
+	solidOwnerWebIDs = make(map[string]bool)
+
+	solidPendingLogins = make(map[string]*solidPendingLogin) // keyed by OAuth state
+	solidClientIDs     = make(map[string]string)             // dynamically registered client IDs by issuer
+	solidMutex         sync.Mutex
+
+	// Connected pods, keyed by session cookie, in the store picked by SOLID_SESSION_STORE
+	solidSessions *solid.SessionManager
+
+	// App containers known to have an access policy, by container URL
+	solidPrivateContainers = make(map[string]bool)
+	solidACLMutex          sync.Mutex
+
+	// Provider discovery documents and JWKS, shared by every login
+	solidKeyCache = solid.NewKeyCache(nil)
+)
+
+// initializeSolid opens the session store and starts background refresh and cleanup
+// of Solid sessions and logins
+func initializeSolid() {
+	solidEnabled = appConfig.Solid.Enabled
+	if !solidEnabled {
+		log.Printf("⚠️  Solid pod integration disabled (set SOLID_ENABLED=true to enable)")
+		return
+	}
+	for _, webID := range appConfig.Solid.OwnerWebIDs {
+		solidOwnerWebIDs[webID] = true
+	}
+	solidSessions = solid.NewSessionManager(openSolidSessionStore(), solidKeyCache.Metadata)
+	solidSessions.IdleTimeout = appConfig.Solid.SessionIdleTimeout
+	log.Printf("🌐 Solid pod integration enabled (%d owner WebIDs)", len(solidOwnerWebIDs))
+	background.Go("solid_session_cleanup", cleanupSolidSessions)
+}
+
+// openSolidSessionStore picks the session store from solid.session_store: "dynamodb" (the
+// default when DynamoDB is available), "file" or "memory". The persistent stores encrypt
+// tokens with solid.session_key; without a key sessions stay in memory.
+func openSolidSessionStore() solid.SessionStore {
+	kind := appConfig.Solid.SessionStore
+	if kind == "" {
+		kind = "memory"
+		if useDynamoDB {
+			kind = "dynamodb"
+		}
+	}
+	if kind == "memory" {
+		log.Printf("⚠️  Solid sessions kept in memory, everyone is logged out on restart")
+		return solid.NewMemorySessionStore()
+	}
+
+	key, err := solid.ParseSessionKey(appConfig.Solid.SessionKey)
+	if err != nil {
+		log.Printf("⚠️  SOLID_SESSION_KEY not usable (%v), keeping Solid sessions in memory", err)
+		return solid.NewMemorySessionStore()
+	}
+	sessionCipher, err := solid.NewSessionCipher(key)
+	if err != nil {
+		log.Fatalf("❌ Failed to create Solid session cipher: %v", err)
+	}
+
+	switch kind {
+	case "dynamodb":
+		if !useDynamoDB {
+			log.Printf("⚠️  DynamoDB not available, keeping Solid sessions in memory")
+			return solid.NewMemorySessionStore()
+		}
+		log.Printf("💾 Solid sessions stored encrypted in DynamoDB table %s", solidSessionsTableName)
+		return dynamodbstore.New(dynamoClient, solidSessionsTableName, sessionCipher, solid.DefaultMaxLifetime)
+	case "file":
+		dir := appConfig.Solid.SessionDir
+		store, err := solid.NewFileSessionStore(dir, sessionCipher)
+		if err != nil {
+			log.Fatalf("❌ Failed to open Solid session directory: %v", err)
+		}
+		log.Printf("💾 Solid sessions stored encrypted in %s", dir)
+		return store
+	default:
+		log.Fatalf("❌ Unknown SOLID_SESSION_STORE %q (use memory, file or dynamodb)", kind)
+		return nil
+	}
+}
+
+// cleanupSolidSessions refreshes tokens before they expire, removes idle and expired
+// sessions, and drops abandoned login attempts
+func cleanupSolidSessions(ctx context.Context) error {
+	interval := appConfig.Intervals.SolidSessionCleanup
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	heartbeat := backgroundHeartbeat("solid_session_cleanup", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		heartbeat.Beat()
+		now := time.Now()
+		solidMutex.Lock()
+		for state, pending := range solidPendingLogins {
+			if now.Sub(pending.CreatedAt) > solidLoginTTL {
+				delete(solidPendingLogins, state)
+			}
+		}
+		solidMutex.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), solidWriteTimeout)
+		result, err := solidSessions.Sweep(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("⚠️  Solid session sweep failed: %v", err)
+			continue
+		}
+		for webID, err := range result.Failed {
+			log.Printf("⚠️  Could not refresh Solid session for WebID %s: %v", webID, err)
+		}
+		if result.Refreshed > 0 || result.Removed > 0 {
+			log.Printf("🌐 Solid sessions: %d refreshed, %d ended", result.Refreshed, result.Removed)
+		}
+	}
+}
+
+// solidRedirectURI is where providers send the browser back after login
+func solidRedirectURI() string {
+	return getBaseURL() + "/api/solid/callback"
+}
+
+// solidClientIDDocumentURL is the Solid-OIDC Client ID Document served by this app
+func solidClientIDDocumentURL() string {
+	return getBaseURL() + "/api/solid/clientid.jsonld"
+}
+
+// solidClientID picks the client_id for a provider: SOLID_CLIENT_ID, a dynamic
+// registration when the provider supports it, otherwise our Client ID Document
+func solidClientID(ctx context.Context, meta *solid.ProviderMetadata) (string, error) {
+	if clientID := appConfig.Solid.ClientID; clientID != "" {
+		return clientID, nil
+	}
+	if meta.RegistrationEndpoint == "" {
+		return solidClientIDDocumentURL(), nil
+	}
+
+	solidMutex.Lock()
+	clientID, ok := solidClientIDs[meta.Issuer]
+	solidMutex.Unlock()
+	if ok {
+		return clientID, nil
+	}
+
+	registration, err := solid.RegisterClient(ctx, nil, meta, "Location Tracker", []string{solidRedirectURI()})
+	if err != nil {
+		return "", err
+	}
+
+	solidMutex.Lock()
+	solidClientIDs[meta.Issuer] = registration.ClientID
+	solidMutex.Unlock()
+	return registration.ClientID, nil
+}
+
+// solidVerifierFor checks ID tokens issued to clientID. Users pick their own provider,
+// so the verifier trusts exactly the issuer the login started with
+func solidVerifierFor(issuer, clientID string) *solid.Verifier {
+	verifier := solid.NewVerifier([]string{issuer}, []string{clientID}, nil)
+	verifier.Keys = solidKeyCache
+	return verifier
+}
+
+// validSolidIssuer accepts HTTPS issuers, and plain HTTP only for a local development server
+func validSolidIssuer(issuer string) bool {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if parsed.Scheme == "https" {
+		return true
+	}
+	host := parsed.Hostname()
+	return parsed.Scheme == "http" && (host == "localhost" || host == "127.0.0.1")
+}
+
+// validWebID accepts HTTPS WebIDs, and plain HTTP only for a local development pod
+func validWebID(webID string) bool {
+	return validSolidIssuer(webID)
+}
+
+// writeSolidError sends the JSON error shape the frontend's solidLogin() expects
+func writeSolidError(w http.ResponseWriter, status int, code, message, help string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body := map[string]string{"error": code, "message": message}
+	if help != "" {
+		body["help"] = help
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// handleSolidLogin starts an authorization code + PKCE login with the chosen provider
+func handleSolidLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !solidEnabled {
+		writeSolidError(w, http.StatusServiceUnavailable, "solid_not_enabled", "Solid authentication is disabled on this server", "")
+		return
+	}
+
+	var req struct {
+		IssuerURL string `json:"issuer_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSolidError(w, http.StatusBadRequest, "invalid_request", "Invalid request body", "")
+		return
+	}
+	issuer := strings.TrimRight(strings.TrimSpace(req.IssuerURL), "/")
+	if !validSolidIssuer(issuer) {
+		writeSolidError(w, http.StatusBadRequest, "invalid_issuer", "Pod provider must be an HTTPS URL", "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	meta, err := solid.Discover(ctx, nil, issuer)
+	if err != nil {
+		log.Printf("⚠️  Solid discovery failed for %s: %v", issuer, err)
+		writeSolidError(w, http.StatusBadGateway, "discovery_failed", "Could not reach your Pod provider",
+			"Check the provider URL. It must serve /.well-known/openid-configuration.")
+		return
+	}
+
+	clientID, err := solidClientID(ctx, meta)
+	if err != nil {
+		log.Printf("⚠️  Solid client registration failed for %s: %v", issuer, err)
+		writeSolidError(w, http.StatusBadGateway, "registration_failed", "Your Pod provider rejected the app registration", "")
+		return
+	}
+
+	pkce, err := solid.NewPKCE()
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	state, err := solid.RandomToken(24)
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	nonce, err := solid.RandomToken(24)
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	key, err := solid.NewDPoPKey()
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	solidMutex.Lock()
+	solidPendingLogins[state] = &solidPendingLogin{
+		Meta:       meta,
+		ClientID:   clientID,
+		PKCE:       pkce,
+		Nonce:      nonce,
+		Key:        key,
+		FullAccess: isAuthenticated(r),
+		CreatedAt:  time.Now(),
+	}
+	solidMutex.Unlock()
+
+	// Bind the state to this browser so a callback can't be replayed elsewhere
+	http.SetCookie(w, &http.Cookie{
+		Name:     solidStateCookie,
+		Value:    state,
+		HttpOnly: true,
+		Secure:   useHTTPS,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(solidLoginTTL.Seconds()),
+		Path:     "/api/solid/",
+	})
+
+	log.Printf("🌐 Solid login started with %s", meta.Issuer)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"authorization_url": solid.AuthorizationURL(meta, clientID, solidRedirectURI(), state, nonce, pkce),
+	})
+}
+
+// handleSolidCallback finishes the login: exchanges the code, checks the ID token and finds the pod
+func handleSolidCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	stateCookie, err := r.Cookie(solidStateCookie)
+	if state == "" || err != nil || stateCookie.Value != state {
+		http.Error(w, "Invalid login state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: solidStateCookie, Value: "", MaxAge: -1, Path: "/api/solid/"})
+
+	solidMutex.Lock()
+	pending, ok := solidPendingLogins[state]
+	delete(solidPendingLogins, state)
+	solidMutex.Unlock()
+	if !ok || time.Since(pending.CreatedAt) > solidLoginTTL {
+		http.Error(w, "Login expired, please try again", http.StatusBadRequest)
+		return
+	}
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		log.Printf("⚠️  Solid provider returned error: %s", providerErr)
+		http.Redirect(w, r, "/?solid=error", http.StatusFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	token, err := solid.ExchangeCode(ctx, nil, pending.Meta, pending.ClientID, query.Get("code"), solidRedirectURI(), pending.PKCE.Verifier, pending.Key)
+	if err != nil {
+		log.Printf("❌ Solid token exchange failed: %v", err)
+		http.Redirect(w, r, "/?solid=error", http.StatusFound)
+		return
+	}
+
+	verifier := solidVerifierFor(pending.Meta.Issuer, pending.ClientID)
+	claims, err := verifier.VerifyIDToken(ctx, token.IDToken, solid.VerifyOptions{Issuer: pending.Meta.Issuer, Nonce: pending.Nonce})
+	if err != nil {
+		log.Printf("❌ Solid ID token rejected: %v", err)
+		http.Redirect(w, r, "/?solid=error", http.StatusFound)
+		return
+	}
+	webID := claims.WebIDOrSubject()
+
+	podURL, err := solid.DiscoverStorage(ctx, nil, webID)
+	if err != nil {
+		log.Printf("⚠️  Could not read pod storage for %s, using %s: %v", webID, podURL, err)
+	}
+
+	sessionID, err := solid.RandomToken(32)
+	if err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	expiresIn := time.Duration(token.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	isOwner := solidOwnerWebIDs[webID]
+	fullAccess := pending.FullAccess || isOwner
+	grantedAuth := isOwner && !pending.FullAccess
+	session := &solid.Session{
+		ID:           sessionID,
+		WebID:        webID,
+		Name:         claims.Name,
+		Issuer:       pending.Meta.Issuer,
+		ClientID:     pending.ClientID,
+		PodURL:       podURL,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Key:          pending.Key,
+		Attributes: map[string]string{
+			solidFullAccessAttr:  strconv.FormatBool(fullAccess),
+			solidGrantedAuthAttr: strconv.FormatBool(grantedAuth),
+		},
+		ExpiresAt: time.Now().Add(expiresIn),
+	}
+	if err := solidSessions.Create(ctx, session); err != nil {
+		log.Printf("❌ Failed to store Solid session for %s: %v", webID, err)
+		http.Redirect(w, r, "/?solid=error", http.StatusFound)
+		return
+	}
+
+	// The cookie outlives the access token when it can be refreshed; an idle session
+	// still ends server-side
+	cookieAge := int(time.Until(solidSessions.Expiry(session)).Seconds())
+	http.SetCookie(w, &http.Cookie{
+		Name:     solidSessionCookie,
+		Value:    sessionID,
+		HttpOnly: true,
+		Secure:   useHTTPS,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   cookieAge,
+		Path:     "/",
+	})
+	if grantedAuth {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "auth",
+			Value:    "authenticated",
+			HttpOnly: true,
+			Secure:   useHTTPS,
+			SameSite: http.SameSiteStrictMode,
+			MaxAge:   86400, // 24 hours, like a password login
+			Path:     "/",
+		})
+	}
+
+	log.Printf("✅ Solid pod connected for WebID: %s (pod: %s, full access: %t, refreshable: %t)", webID, podURL, fullAccess, session.Refreshable())
+	http.Redirect(w, r, "/?solid=success", http.StatusFound)
+}
+
+// solidSessionFromRequest returns the caller's connected pod session, if any
+func solidSessionFromRequest(r *http.Request) *solid.Session {
+	if solidSessions == nil {
+		return nil
+	}
+	cookie, err := r.Cookie(solidSessionCookie)
+	if err != nil {
+		return nil
+	}
+
+	session, err := solidSessions.Get(r.Context(), cookie.Value)
+	if err != nil {
+		if !errors.Is(err, solid.ErrSessionNotFound) {
+			log.Printf("⚠️  Could not load Solid session: %v", err)
+		}
+		return nil
+	}
+	return session
+}
+
+// solidAttr reads a boolean session attribute
+func solidAttr(session *solid.Session, name string) bool {
+	return session.Attributes[name] == "true"
+}
+
+// handleSolidSessionStatus reports the connected WebID and pod to the frontend
+func handleSolidSessionStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	session := solidSessionFromRequest(r)
+	if session == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"authenticated": false})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"authenticated": true,
+		"auth_type":     "solid",
+		"webid":         session.WebID,
+		"name":          session.Name,
+		"pod_url":       session.PodURL,
+		"full_access":   solidAttr(session, solidFullAccessAttr),
+		"expires_at":    solidSessions.Expiry(session).Format(time.RFC3339),
+	})
+}
+
+// handleSolidLogout disconnects the pod and clears any access the Solid login granted
+func handleSolidLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(solidSessionCookie); err == nil && solidSessions != nil {
+		// Logging out also revokes the tokens, when the provider supports revocation
+		session, err := solidSessions.Logout(r.Context(), cookie.Value)
+		if session != nil {
+			if solidAttr(session, solidGrantedAuthAttr) {
+				http.SetCookie(w, &http.Cookie{Name: "auth", Value: "", MaxAge: -1, Path: "/"})
+			}
+			log.Printf("🌐 Solid pod disconnected for WebID: %s", session.WebID)
+		}
+		if err != nil && !errors.Is(err, solid.ErrSessionNotFound) {
+			log.Printf("⚠️  Solid logout incomplete: %v", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{Name: solidSessionCookie, Value: "", MaxAge: -1, Path: "/"})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// handleSolidClientID serves the Solid-OIDC Client ID Document for providers without dynamic registration
+func handleSolidClientID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/ld+json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"@context":                   []string{"https://www.w3.org/ns/solid/oidc-context.jsonld"},
+		"client_id":                  solidClientIDDocumentURL(),
+		"client_name":                "Location Tracker",
+		"client_uri":                 getBaseURL(),
+		"redirect_uris":              []string{solidRedirectURI()},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"scope":                      "openid webid offline_access",
+		"token_endpoint_auth_method": "none",
+		"application_type":           "web",
+	})
+}
+
+// podResourceURL resolves a pod-relative path against the session's pod
+func podResourceURL(session *solid.Session, path string) string {
+	return strings.TrimRight(session.PodURL, "/") + "/" + path
+}
+
+// ensurePrivateContainer gives the app container an owner-only ACL before the first
+// write of a session, unless it already has one; everything the app writes inherits it
+// until a trail is shared on its own
+func ensurePrivateContainer(ctx context.Context, session *solid.Session, client *solid.Client) error {
+	containerURL := podResourceURL(session, solid.AppContainerPath())
+	solidACLMutex.Lock()
+	defer solidACLMutex.Unlock()
+	if solidPrivateContainers[containerURL] {
+		return nil
+	}
+
+	if err := client.CreateContainer(ctx, containerURL); err != nil {
+		return err
+	}
+	_, err := client.GetAccess(ctx, containerURL)
+	if errors.Is(err, solid.ErrNotFound) {
+		err = client.SetAccess(ctx, containerURL, solid.OwnerOnly(session.WebID))
+		if err == nil {
+			log.Printf("🔒 Made %s private to %s", containerURL, session.WebID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	solidPrivateContainers[containerURL] = true
+	return nil
+}
+
+// writeToPod PUTs a resource into a session's pod in the background. Nothing is
+// written until the app container is private.
+func writeToPod(session *solid.Session, path, contentType string, body []byte) {
+	resourceURL := podResourceURL(session, path)
+	client := solid.NewClient(session.AccessToken, session.Key)
+
+	background.Async("solid_pod_write", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), solidWriteTimeout)
+		defer cancel()
+		if err := ensurePrivateContainer(ctx, session, client); err != nil {
+			log.Printf("⚠️  Not writing %s: could not make the app container private in pod of %s: %v", path, session.WebID, err)
+			return
+		}
+		if err := client.PutResource(ctx, resourceURL, contentType, body); err != nil {
+			log.Printf("⚠️  Failed to write %s to pod of %s: %v", path, session.WebID, err)
+			return
+		}
+		log.Printf("🌐 Wrote %s to pod of %s", path, session.WebID)
+	})
+}
+
+// locationData maps a location share onto the shared RDF model
+func locationData(loc types.Location) solid.LocationData {
+	return solid.LocationData{
+		Latitude:  loc.Latitude,
+		Longitude: loc.Longitude,
+		Accuracy:  loc.Accuracy,
+		Timestamp: loc.Timestamp,
+		DeviceID:  loc.DeviceID,
+		Name:      loc.LocationName,
+		Simulated: loc.Simulated,
+	}
+}
+
+// tipData maps a tip onto the shared RDF model (never IP address or identity metadata)
+func tipData(tip types.AnonymousTip) solid.TipData {
+	return solid.TipData{
+		ID:               tip.ID,
+		Content:          tip.TipContent,
+		ModeratedContent: tip.ModeratedContent,
+		ModerationStatus: tip.ModerationStatus,
+		Keywords:         tip.Keywords,
+		Timestamp:        tip.Timestamp,
+	}
+}
+
+// errorLogData maps an error log case onto the shared RDF model
+func errorLogData(errorLog types.ErrorLog) solid.ErrorLogData {
+	gifURLs := errorLog.GifURLs
+	if len(gifURLs) == 0 && errorLog.GifURL != "" {
+		gifURLs = []string{errorLog.GifURL}
+	}
+	return solid.ErrorLogData{
+		ID:           errorLog.ID,
+		URL:          errorLog.URL,
+		Message:      errorLog.Message,
+		Slogan:       errorLog.Slogan,
+		Description:  errorLog.VerboseDesc,
+		Story:        errorLog.ChildrensStory,
+		SatiricalFix: errorLog.SatiricalFix,
+		Timestamp:    errorLog.Timestamp,
+		GifURLs:      gifURLs,
+		MemeURL:      errorLog.MemeURL,
+		FoodImageURL: errorLog.FoodImageURL,
+		SongTitle:    errorLog.SongTitle,
+		SongArtist:   errorLog.SongArtist,
+		SongURL:      errorLog.SongURL,
+		Keywords:     errorLog.SeedKeywords,
+	}
+}
+
+// mirrorLocationToPod stores a location share in the sharer's pod
+func mirrorLocationToPod(r *http.Request, loc types.Location) {
+	session := solidSessionFromRequest(r)
+	if session == nil {
+		return
+	}
+
+	data := locationData(loc)
+	data.Creator = session.WebID
+	turtle, err := solid.LocationToTurtle(data)
+	if err != nil {
+		log.Printf("⚠️  Failed to serialize location for pod: %v", err)
+		return
+	}
+	writeToPod(session, solid.LocationPath(loc.Timestamp), "text/turtle", []byte(turtle))
+}
+
+// mirrorTipToPod stores a tip in the submitter's own pod (never IP address or identity metadata)
+func mirrorTipToPod(r *http.Request, tip types.AnonymousTip) {
+	session := solidSessionFromRequest(r)
+	if session == nil {
+		return
+	}
+
+	data := tipData(tip)
+	data.Creator = session.WebID
+	body, err := solid.TipToJSONLD(data)
+	if err != nil {
+		log.Printf("⚠️  Failed to serialize tip %s for pod: %v", tip.ID, err)
+		return
+	}
+	writeToPod(session, solid.TipPath(tip.Timestamp, tip.ID), "application/ld+json", body)
+}
+
+// mirrorErrorLogToPods stores a new error log in the pod of every connected full-access session.
+// It lists sessions from the store, so callers run it in the background.
+func mirrorErrorLogToPods(errorLog types.ErrorLog) {
+	if !solidEnabled {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), solidWriteTimeout)
+	defer cancel()
+	sessions, err := solidSessions.List(ctx)
+	if err != nil {
+		log.Printf("⚠️  Could not list Solid sessions to mirror error log %s: %v", errorLog.ID, err)
+		return
+	}
+
+	for _, session := range sessions {
+		if !solidAttr(session, solidFullAccessAttr) {
+			continue
+		}
+		data := errorLogData(errorLog)
+		data.Creator = session.WebID
+		body, err := solid.ErrorLogToJSONLD(data)
+		if err != nil {
+			log.Printf("⚠️  Failed to serialize error log %s for pod: %v", errorLog.ID, err)
+			return
+		}
+		writeToPod(session, solid.ErrorLogPath(errorLog.Timestamp, errorLog.ID), "application/ld+json", body)
+	}
+}
+
+// solidShareRequest shares or unshares one day's location trail
+type solidShareRequest struct {
+	Date   string `json:"date"`   // YYYY-MM-DD (UTC)
+	WebID  string `json:"webid"`  // reader to add or remove
+	Public bool   `json:"public"` // make the trail readable by anyone
+}
+
+// handleSolidShare lets a connected user see, grant or revoke read access to a single
+// location trail (one day's container) in their pod
+//
+//	GET    /api/solid/share?date=2025-01-31
+//	POST   /api/solid/share {"date": "2025-01-31", "webid": "https://friend.example/profile/card#me"}
+//	DELETE /api/solid/share?date=2025-01-31&webid=https://friend.example/profile/card#me
+func handleSolidShare(w http.ResponseWriter, r *http.Request) {
+	session := solidSessionFromRequest(r)
+	if session == nil {
+		writeSolidError(w, http.StatusUnauthorized, "not_connected", "Connect your Solid pod first", "")
+		return
+	}
+
+	var req solidShareRequest
+	switch r.Method {
+	case "GET", "DELETE":
+		query := r.URL.Query()
+		req = solidShareRequest{Date: query.Get("date"), WebID: query.Get("webid"), Public: query.Get("public") == "true"}
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeSolidError(w, http.StatusBadRequest, "invalid_request", "Invalid request body", "")
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	day, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		writeSolidError(w, http.StatusBadRequest, "invalid_date", "date must be YYYY-MM-DD", "")
+		return
+	}
+	if r.Method != "GET" {
+		if req.WebID == "" && !req.Public {
+			writeSolidError(w, http.StatusBadRequest, "invalid_request", "Give a webid or public=true", "")
+			return
+		}
+		if req.WebID != "" && !validWebID(req.WebID) {
+			writeSolidError(w, http.StatusBadRequest, "invalid_webid", "WebID must be an HTTPS URL", "")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), solidWriteTimeout)
+	defer cancel()
+	client := solid.NewClient(session.AccessToken, session.Key)
+	trailURL := podResourceURL(session, solid.LocationTrailPath(day))
+
+	if _, err := client.Head(ctx, trailURL); err != nil {
+		if errors.Is(err, solid.ErrNotFound) {
+			writeSolidError(w, http.StatusNotFound, "trail_not_found", "No locations were stored in your pod on "+req.Date, "")
+			return
+		}
+		writeSolidError(w, http.StatusBadGateway, "pod_error", "Could not reach your pod", err.Error())
+		return
+	}
+
+	// A trail without its own ACL inherits the owner-only app container policy
+	policy, err := client.GetAccess(ctx, trailURL)
+	if errors.Is(err, solid.ErrNotFound) {
+		policy, err = solid.OwnerOnly(session.WebID), nil
+	}
+	if err != nil {
+		writeSolidError(w, http.StatusBadGateway, "pod_error", "Could not read the trail's access policy", err.Error())
+		return
+	}
+	policy.Owner = session.WebID
+
+	if r.Method != "GET" {
+		if r.Method == "POST" {
+			if req.WebID != "" {
+				policy = policy.WithReader(req.WebID)
+			}
+			policy.Public = policy.Public || req.Public
+		} else {
+			if req.WebID != "" {
+				policy = policy.WithoutReader(req.WebID)
+			}
+			policy.Public = policy.Public && !req.Public
+		}
+		if err := client.SetAccess(ctx, trailURL, policy); err != nil {
+			writeSolidError(w, http.StatusBadGateway, "pod_error", "Could not update the trail's access policy", err.Error())
+			return
+		}
+		log.Printf("🔒 Trail %s is now %s (%d readers)", trailURL, policy.Visibility(), len(policy.Readers))
+	}
+
+	readers := policy.Readers
+	if readers == nil {
+		readers = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"trail":      trailURL,
+		"date":       req.Date,
+		"owner":      policy.Owner,
+		"readers":    readers,
+		"public":     policy.Public,
+		"visibility": policy.Visibility(),
+	})
+}
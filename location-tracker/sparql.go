@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"location-tracker/types"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/sparql"
+)
+
+const (
+	sparqlMaxQueryBytes = 16 << 10
+	sparqlTimeout       = 10 * time.Second
+)
+
+// handleSPARQL is the read-only SPARQL endpoint over cases, tips, locations and
+// businesses. Queries come as ?query= (GET), a form field or an
+// application/sparql-query body (POST). SELECT and ASK results are SPARQL JSON, or CSV
+// with ?format=csv or Accept: text/csv; CONSTRUCT returns Turtle, or JSON-LD.
+//
+// Like /api/errorlogs it needs at least puzzle access, and it sees the same data:
+// without full access cases are redacted and locations and businesses are left out.
+func handleSPARQL(w http.ResponseWriter, r *http.Request) {
+	if !hasPuzzleAccess(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query, status, err := sparqlQueryFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	q, err := sparql.Parse(query, getBaseURL()+"/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := sparqlResultFormat(r, q.Form)
+	if format == "" {
+		http.Error(w, "Unsupported result format for this "+q.Form.String()+" query", http.StatusNotAcceptable)
+		return
+	}
+
+	fullAccess := isAuthenticated(r)
+	dataset := sparqlDataset(fullAccess)
+	ctx, cancel := context.WithTimeout(r.Context(), sparqlTimeout)
+	defer cancel()
+	result, err := q.Exec(ctx, dataset)
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, "Query took too long", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("🔎 SPARQL %s over %d triples (full access: %t)", q.Form, dataset.Len(), fullAccess)
+
+	w.Header().Set("Content-Type", format+"; charset=utf-8")
+	switch format {
+	case sparql.MediaTypeJSON:
+		err = result.WriteJSON(w)
+	case sparql.MediaTypeCSV:
+		err = result.WriteCSV(w)
+	case rdf.MediaTypeTurtle:
+		_, err = io.WriteString(w, result.Graph.Turtle())
+	case rdf.MediaTypeJSONLD:
+		var body []byte
+		if body, err = result.Graph.JSONLD(rdf.SchemaNS); err == nil {
+			_, err = w.Write(body)
+		}
+	}
+	if err != nil {
+		log.Printf("⚠️  Failed to write SPARQL results: %v", err)
+	}
+}
+
+// sparqlQueryFromRequest extracts the query string per the SPARQL 1.1 Protocol
+func sparqlQueryFromRequest(r *http.Request) (string, int, error) {
+	var query string
+	switch r.Method {
+	case "GET":
+		query = r.URL.Query().Get("query")
+	case "POST":
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(io.LimitReader(r.Body, sparqlMaxQueryBytes+1))
+		if err != nil {
+			return "", http.StatusBadRequest, errors.New("Could not read the request body")
+		}
+		switch mediaType {
+		case "application/sparql-query":
+			query = string(body)
+		case "application/x-www-form-urlencoded":
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				return "", http.StatusBadRequest, errors.New("Invalid form body")
+			}
+			if form.Get("update") != "" {
+				return "", http.StatusForbidden, errors.New("SPARQL Update is not allowed: the endpoint is read-only")
+			}
+			query = form.Get("query")
+		case "application/sparql-update":
+			return "", http.StatusForbidden, errors.New("SPARQL Update is not allowed: the endpoint is read-only")
+		default:
+			return "", http.StatusUnsupportedMediaType, errors.New("POST the query as application/sparql-query or a form")
+		}
+	default:
+		return "", http.StatusMethodNotAllowed, errors.New("Method not allowed")
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return "", http.StatusBadRequest, errors.New("Missing query")
+	}
+	if len(query) > sparqlMaxQueryBytes {
+		return "", http.StatusRequestEntityTooLarge, errors.New("Query is too long")
+	}
+	return query, 0, nil
+}
+
+// sparqlResultFormat picks the response media type from ?format= or Accept; it
+// returns "" when the requested format doesn't fit the query form
+func sparqlResultFormat(r *http.Request, form sparql.Form) string {
+	requested := strings.ToLower(r.URL.Query().Get("format"))
+	if requested == "" {
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "text/csv"):
+			requested = "csv"
+		case strings.Contains(accept, rdf.MediaTypeTurtle):
+			requested = "turtle"
+		case strings.Contains(accept, rdf.MediaTypeJSONLD):
+			requested = "jsonld"
+		}
+	}
+
+	if form == sparql.Construct {
+		switch requested {
+		case "", "turtle", "ttl":
+			return rdf.MediaTypeTurtle
+		case "jsonld", "json":
+			return rdf.MediaTypeJSONLD
+		}
+		return ""
+	}
+	switch requested {
+	case "", "json":
+		return sparql.MediaTypeJSON
+	case "csv":
+		if form == sparql.Select {
+			return sparql.MediaTypeCSV
+		}
+	}
+	return ""
+}
+
+// sparqlDataset builds the RDF view of the repository at the caller's access level.
+// Cases go through redactErrorLog like the REST API; tips are the approved ones
+// /api/tips lists, without identity metadata; locations and businesses need full access.
+//
+// Cases, tips and locations use the pod mappings (schema:Report, schema:Comment,
+// schema:Place), each rooted at its API URL, e.g. <.../api/errorlogs/{id}#error-log>.
+// A case links the tip that seeded it with schema:isBasedOn and its nearby businesses
+// (schema:LocalBusiness with schema:additionalType) with schema:contentLocation.
+func sparqlDataset(fullAccess bool) *rdf.Graph {
+	base := getBaseURL()
+	g := rdf.NewGraph()
+	g.UseCommonPrefixes("rdf", "xsd", "schema", "geo", "dcterms")
+
+	add := func(document *rdf.Graph, documentURL string) {
+		resolved, err := document.Resolve(documentURL)
+		if err != nil {
+			log.Printf("⚠️  Skipping %s in SPARQL dataset: %v", documentURL, err)
+			return
+		}
+		g.Merge(resolved)
+	}
+
+	// Businesses near the current location give it away, so they need full access too
+	knownBusinesses := make(map[string]types.Business)
+	if fullAccess {
+		currentBusinessesMutex.RLock()
+		for _, business := range currentBusinesses {
+			knownBusinesses[business.Name] = business
+		}
+		currentBusinessesMutex.RUnlock()
+		for _, business := range knownBusinesses {
+			addSPARQLBusiness(g, base, business)
+		}
+	}
+
+	tipURL := func(id string) string { return base + "/api/tips/" + url.PathEscape(id) }
+	anonymousTipsMutex.RLock()
+	tips := make([]types.AnonymousTip, 0, len(anonymousTips))
+	for _, tip := range anonymousTips {
+		if tip.ModerationStatus == "approved" || tip.ModerationStatus == "redacted" {
+			tips = append(tips, tip)
+		}
+	}
+	anonymousTipsMutex.RUnlock()
+	for _, tip := range tips {
+		add(solid.TipToGraph(tipData(tip)), tipURL(tip.ID))
+	}
+
+	for _, errorLog := range loadAllCases() {
+		errorLog = redactErrorLog(errorLog, fullAccess)
+		documentURL := base + "/api/errorlogs/" + url.PathEscape(errorLog.ID)
+		add(solid.ErrorLogToGraph(errorLogData(errorLog)), documentURL)
+
+		report := rdf.IRI(documentURL + "#error-log")
+		if errorLog.SeedInteractionType == "tip_submission" && errorLog.SeedInteractionID != "" {
+			g.Add(report, rdf.IRI(rdf.SchemaNS+"isBasedOn"), rdf.IRI(tipURL(errorLog.SeedInteractionID)+"#tip"))
+		}
+		for _, name := range errorLog.NearbyBusinesses {
+			business, ok := knownBusinesses[name]
+			if !ok {
+				business = types.Business{Name: name}
+			}
+			g.Add(report, rdf.IRI(rdf.SchemaNS+"contentLocation"), addSPARQLBusiness(g, base, business))
+		}
+		if errorLog.UserExperienceNote != "" {
+			note := g.NewBlank()
+			g.Add(report, rdf.IRI(rdf.SchemaNS+"comment"), note)
+			g.Add(note, rdf.IRI(rdf.RDFType), rdf.IRI(rdf.SchemaNS+"Comment"))
+			g.Add(note, rdf.IRI(rdf.SchemaNS+"name"), rdf.Literal("User Experience Note"))
+			g.Add(note, rdf.IRI(rdf.SchemaNS+"text"), rdf.Literal(errorLog.UserExperienceNote))
+		}
+	}
+
+	if fullAccess {
+		locationMutex.RLock()
+		shares := make([]types.Location, 0, len(locations))
+		for _, loc := range locations {
+			shares = append(shares, loc)
+		}
+		locationMutex.RUnlock()
+		for _, loc := range shares {
+			add(solid.LocationToGraph(locationData(loc)), base+"/api/location/"+url.PathEscape(loc.DeviceID))
+		}
+	}
+	return g
+}
+
+// addSPARQLBusiness describes a business and returns its node
+func addSPARQLBusiness(g *rdf.Graph, base string, business types.Business) rdf.Term {
+	node := rdf.IRI(base + "/api/businesses#" + url.PathEscape(business.Name))
+	schema := func(local string) rdf.Term { return rdf.IRI(rdf.SchemaNS + local) }
+	g.Add(node, rdf.IRI(rdf.RDFType), schema("LocalBusiness"))
+	g.Add(node, schema("name"), rdf.Literal(business.Name))
+	if business.Type != "" {
+		g.Add(node, schema("additionalType"), rdf.Literal(business.Type))
+	}
+	if business.Address != "" {
+		g.Add(node, schema("address"), rdf.Literal(business.Address))
+	}
+	if business.PlaceID != "" {
+		g.Add(node, schema("identifier"), rdf.Literal(business.PlaceID))
+	}
+	if business.Location.Lat != 0 || business.Location.Lng != 0 {
+		g.Add(node, rdf.IRI(rdf.GeoNS+"lat"), rdf.Decimal(business.Location.Lat))
+		g.Add(node, rdf.IRI(rdf.GeoNS+"long"), rdf.Decimal(business.Location.Lng))
+	}
+	return node
+}
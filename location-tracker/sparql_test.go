@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"location-tracker/types"
+)
+
+// Values only full access may see
+var sparqlSecrets = []string{
+	"Secret Cafe", "1 Hidden Lane", "Unlisted Bakery", // nearby businesses
+	"met the informant at noon", "User Experience Note", // user note
+	"38.8977", "-77.0365", "device-secret", "Hidden Corner", // location share and business position
+}
+
+// useSPARQLFixtures replaces the in-memory repository with one case seeded by a tip,
+// a business near the current location and a location share
+func useSPARQLFixtures(t *testing.T) {
+	t.Helper()
+	savedBaseURL := appConfig.Server.BaseURL
+	savedErrorLogs, savedTips, savedBusinesses, savedLocations := errorLogs, anonymousTips, currentBusinesses, locations
+	t.Cleanup(func() {
+		appConfig.Server.BaseURL = savedBaseURL
+		errorLogs, anonymousTips, currentBusinesses, locations = savedErrorLogs, savedTips, savedBusinesses, savedLocations
+	})
+
+	appConfig.Server.BaseURL = "https://tracker.example"
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	business := types.Business{Name: "Secret Cafe", Type: "cafe", Address: "1 Hidden Lane", PlaceID: "place-1"}
+	business.Location.Lat, business.Location.Lng = 38.8977, -77.0365
+	currentBusinesses = []types.Business{business}
+
+	anonymousTips = []types.AnonymousTip{
+		{ID: "tip-1", TipContent: "Check the fountain", ModerationStatus: "approved", Timestamp: now.Add(-time.Hour), IPAddress: "203.0.113.7", UserHash: "user-hash"},
+		{ID: "tip-2", TipContent: "Pending rumour", ModerationStatus: "pending", Timestamp: now.Add(-time.Hour)},
+	}
+
+	errorLogs = []types.ErrorLog{{
+		ID:                  "case-1",
+		Message:             "Null pointer at the fountain",
+		Slogan:              "Always be caching",
+		Timestamp:           now,
+		NearbyBusinesses:    []string{"Secret Cafe", "Unlisted Bakery"},
+		UserExperienceNote:  "met the informant at noon",
+		SeedInteractionType: "tip_submission",
+		SeedInteractionID:   "tip-1",
+	}}
+
+	locations = map[string]types.Location{
+		"device-secret": {Latitude: 38.8977, Longitude: -77.0365, Accuracy: 10, Timestamp: now, DeviceID: "device-secret", LocationName: "Hidden Corner"},
+	}
+}
+
+func sparqlRequest(t *testing.T, auth, query, format string) *httptest.ResponseRecorder {
+	t.Helper()
+	params := url.Values{"query": {query}}
+	if format != "" {
+		params.Set("format", format)
+	}
+	r := httptest.NewRequest("GET", "/sparql?"+params.Encode(), nil)
+	if auth != "" {
+		r.AddCookie(&http.Cookie{Name: "auth", Value: auth})
+	}
+	w := httptest.NewRecorder()
+	handleSPARQL(w, r)
+	return w
+}
+
+// sparqlSelect runs a SELECT and returns one variable's values
+func sparqlSelect(t *testing.T, auth, query, variable string) []string {
+	t.Helper()
+	w := sparqlRequest(t, auth, query, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status %d: %s", query, w.Code, w.Body)
+	}
+	var results struct {
+		Results struct {
+			Bindings []map[string]struct{ Value string } `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	values := make([]string, 0, len(results.Results.Bindings))
+	for _, binding := range results.Results.Bindings {
+		values = append(values, binding[variable].Value)
+	}
+	return values
+}
+
+const sparqlPrefixes = "PREFIX schema: <http://schema.org/>\nPREFIX geo: <http://www.w3.org/2003/01/geo/wgs84_pos#>\n"
+
+func TestSPARQLPuzzleAccessRedaction(t *testing.T) {
+	useSPARQLFixtures(t)
+
+	// Everything a puzzle-level caller can reach, in one dump
+	dump := sparqlRequest(t, "puzzle_solved", `CONSTRUCT { ?s ?p ?o } WHERE { ?s ?p ?o }`, "")
+	if dump.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", dump.Code, dump.Body)
+	}
+	for _, secret := range sparqlSecrets {
+		if strings.Contains(dump.Body.String(), secret) {
+			t.Errorf("puzzle-level dataset contains %q", secret)
+		}
+	}
+	for _, secret := range []string{"Pending rumour", "203.0.113.7", "user-hash"} {
+		if strings.Contains(dump.Body.String(), secret) {
+			t.Errorf("puzzle-level dataset contains tip data %q", secret)
+		}
+	}
+	if !strings.Contains(dump.Body.String(), "Null pointer at the fountain") || !strings.Contains(dump.Body.String(), "Check the fountain") {
+		t.Errorf("puzzle-level dataset is missing the case or the approved tip:\n%s", dump.Body)
+	}
+
+	queries := map[string]string{
+		"nearby businesses": `SELECT ?x WHERE { ?case a schema:Report ; schema:contentLocation ?x }`,
+		"businesses":        `SELECT ?x WHERE { ?x a schema:LocalBusiness }`,
+		"user note":         `SELECT ?x WHERE { ?case schema:comment ?note . ?note schema:text ?x }`,
+		"places":            `SELECT ?x WHERE { ?x a schema:Place }`,
+		"coordinates":       `SELECT ?x WHERE { ?s geo:lat ?x }`,
+	}
+	for name, query := range queries {
+		if got := sparqlSelect(t, "puzzle_solved", sparqlPrefixes+query, "x"); len(got) != 0 {
+			t.Errorf("puzzle-level %s = %q", name, got)
+		}
+	}
+	// The link from a case to the tip that seeded it is not redacted
+	if got := sparqlSelect(t, "puzzle_solved", sparqlPrefixes+`SELECT ?x WHERE { ?case schema:isBasedOn ?tip . ?tip schema:text ?x }`, "x"); len(got) != 1 || got[0] != "Check the fountain" {
+		t.Errorf("seeding tip = %q", got)
+	}
+}
+
+func TestSPARQLFullAccess(t *testing.T) {
+	useSPARQLFixtures(t)
+
+	dump := sparqlRequest(t, "authenticated", `CONSTRUCT { ?s ?p ?o } WHERE { ?s ?p ?o }`, "")
+	for _, secret := range sparqlSecrets {
+		if !strings.Contains(dump.Body.String(), secret) {
+			t.Errorf("full-access dataset is missing %q", secret)
+		}
+	}
+
+	// The README example: cases seeded by tips near cafes
+	query := sparqlPrefixes + `SELECT ?title ?tipText WHERE {
+  ?case a schema:Report ; schema:name ?title ;
+        schema:isBasedOn ?tip ; schema:contentLocation ?business .
+  ?business schema:additionalType "cafe" .
+  ?tip schema:text ?tipText .
+} LIMIT 20`
+	if got := sparqlSelect(t, "authenticated", query, "tipText"); len(got) != 1 || got[0] != "Check the fountain" {
+		t.Errorf("cases near cafes = %q", got)
+	}
+	// A business that is not in the current list is still named
+	businesses := sparqlSelect(t, "authenticated", sparqlPrefixes+`SELECT ?name WHERE { ?case schema:contentLocation ?b . ?b schema:name ?name } ORDER BY ?name`, "name")
+	if strings.Join(businesses, ",") != "Secret Cafe,Unlisted Bakery" {
+		t.Errorf("nearby businesses = %q", businesses)
+	}
+	if got := sparqlSelect(t, "authenticated", sparqlPrefixes+`SELECT ?x WHERE { ?p a schema:Place ; schema:identifier ?x }`, "x"); len(got) != 1 || got[0] != "device-secret" {
+		t.Errorf("places = %q", got)
+	}
+}
+
+func TestSPARQLRequests(t *testing.T) {
+	useSPARQLFixtures(t)
+
+	tests := []struct {
+		name   string
+		auth   string
+		query  string
+		format string
+		status int
+	}{
+		{"no access", "", `ASK { ?s ?p ?o }`, "", http.StatusUnauthorized},
+		{"ask", "puzzle_solved", `ASK { ?s ?p ?o }`, "", http.StatusOK},
+		{"csv", "puzzle_solved", `SELECT ?s WHERE { ?s ?p ?o } LIMIT 1`, "csv", http.StatusOK},
+		{"csv for ask", "puzzle_solved", `ASK { ?s ?p ?o }`, "csv", http.StatusNotAcceptable},
+		{"update", "authenticated", `DELETE WHERE { ?s ?p ?o }`, "", http.StatusBadRequest},
+		{"syntax error", "puzzle_solved", `SELECT ?s WHERE {`, "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := sparqlRequest(t, tt.auth, tt.query, tt.format); w.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("POST", "/sparql", strings.NewReader("update=DELETE+WHERE+%7B+%3Fs+%3Fp+%3Fo+%7D"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(&http.Cookie{Name: "auth", Value: "authenticated"})
+	w := httptest.NewRecorder()
+	handleSPARQL(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("form update status %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"location-tracker/dpstats"
+	"location-tracker/types"
+)
+
+// Public aggregate statistics. Every figure is released through a differentially
+// private mechanism and cached; a fresh release spends from that query's budget, and
+// once the budget for the window is spent the last release keeps being served.
+const (
+	statsCasesPerDay = "cases_per_day"
+	statsTipsPerDay  = "tips_per_day"
+	statsTopKeywords = "top_keywords"
+
+	statsKeywordsPerCase = 5  // keywords one case or tip may contribute
+	statsTopKeywordCount = 10 // keywords listed
+)
+
+// statsQuery is one published statistic
+type statsQuery struct {
+	name        string
+	description string
+	unit        string // what one record is; the guarantee protects one such record
+	mechanism   dpstats.Mechanism
+	threshold   float64 // open histograms only
+	cost        dpstats.Budget
+	compute     func(q *statsQuery, now time.Time) []dpstats.Bin
+}
+
+// statsRelease is a published statistic with the parameters it was released under
+type statsRelease struct {
+	Query           string         `json:"query"`
+	Description     string         `json:"description"`
+	Unit            string         `json:"unit"`
+	Privacy         dpstats.Params `json:"privacy"`
+	ReleasedAt      time.Time      `json:"released_at"`
+	NextRefresh     time.Time      `json:"next_refresh"`
+	BudgetRemaining dpstats.Budget `json:"budget_remaining"`
+	BudgetExhausted bool           `json:"budget_exhausted,omitempty"`
+	Bins            []dpstats.Bin  `json:"bins"`
+}
+
+var (
+	statsQueries    []*statsQuery
+	statsAccountant *dpstats.Accountant
+	statsRefresh    time.Duration
+	statsDays       int
+	statsReleases   = make(map[string]*statsRelease)
+	statsMutex      sync.Mutex
+)
+
+// initializeStats configures the public statistics from appConfig.Stats: epsilon is
+// spent by each fresh release of a query, delta covers the keyword mechanism and
+// withholding rare keys, budget is the total epsilon per query per window, refresh is
+// how long a release is served and days is how many completed days the series cover.
+func initializeStats() {
+	settings := appConfig.Stats
+	epsilon := settings.Epsilon
+	delta := settings.Delta
+	statsDays = settings.Days
+	statsRefresh = settings.Refresh
+	window := settings.Window
+
+	laplace := dpstats.Laplace{Epsilon: epsilon, Sensitivity: 1}
+	gaussian := dpstats.Gaussian{
+		Epsilon:     epsilon,
+		Delta:       delta,
+		Sensitivity: math.Sqrt(statsKeywordsPerCase),
+	}
+	for _, m := range []dpstats.Mechanism{laplace, gaussian} {
+		if err := m.Validate(); err != nil {
+			log.Fatalf("❌ Invalid statistics privacy settings: %v", err)
+		}
+	}
+	keywordThreshold, err := dpstats.Threshold(gaussian, statsKeywordsPerCase, delta)
+	if err != nil {
+		log.Fatalf("❌ Invalid statistics privacy settings: %v", err)
+	}
+
+	statsQueries = []*statsQuery{
+		{
+			name:        statsCasesPerDay,
+			description: "Cases opened per day (UTC), completed days only",
+			unit:        "case",
+			mechanism:   laplace,
+			cost:        dpstats.Budget{Epsilon: epsilon},
+			compute:     computeCasesPerDay,
+		},
+		{
+			name:        statsTipsPerDay,
+			description: "Anonymous tips submitted per day (UTC), completed days only",
+			unit:        "tip",
+			mechanism:   laplace,
+			cost:        dpstats.Budget{Epsilon: epsilon},
+			compute:     computeTipsPerDay,
+		},
+		{
+			name:        statsTopKeywords,
+			description: "Most frequent keywords across cases and approved tips",
+			unit:        "case or tip",
+			mechanism:   gaussian,
+			threshold:   keywordThreshold,
+			cost:        dpstats.Budget{Epsilon: epsilon, Delta: 2 * delta},
+			compute:     computeTopKeywords,
+		},
+	}
+
+	// the delta budget follows from the epsilon budget: whatever number of releases it
+	// allows, each may also spend the keyword query's delta
+	budget := dpstats.Budget{Epsilon: settings.Budget}
+	budget.Delta = math.Floor(budget.Epsilon/epsilon) * 2 * delta
+	statsAccountant, err = dpstats.NewAccountant(budget, window)
+	if err != nil {
+		log.Fatalf("❌ Invalid statistics privacy settings: %v", err)
+	}
+	if budget.Epsilon < epsilon {
+		log.Fatalf("❌ STATS_BUDGET %g is smaller than STATS_EPSILON %g; nothing could be released", budget.Epsilon, epsilon)
+	}
+	if releases := math.Ceil(float64(window) / float64(statsRefresh)); releases*epsilon > budget.Epsilon {
+		log.Printf("⚠️  STATS_REFRESH %s would spend %g per %s against a budget of %g; stale releases will be served",
+			statsRefresh, releases*epsilon, window, budget.Epsilon)
+	}
+
+	log.Printf("🔒 Public statistics: epsilon %g per release, budget %g per query per %s, refresh %s",
+		epsilon, budget.Epsilon, window, statsRefresh)
+}
+
+// handleStats serves the public statistics with the noise parameters behind each figure
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now().UTC()
+	releases := make(map[string]statsRelease, len(statsQueries))
+	for _, q := range statsQueries {
+		releases[q.name] = currentStatsRelease(q, now)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"generated_at": now,
+		"guarantee": map[string]interface{}{
+			"model":            "differential privacy, event level: each figure changes little whether or not any single record (see unit) exists",
+			"budget_per_query": statsAccountant.Limit().Epsilon,
+			"budget_window":    statsAccountant.Window().String(),
+			"composition":      "basic; epsilons and deltas of a query's releases within the window add up",
+		},
+		"stats": releases,
+	})
+}
+
+// currentStatsRelease returns a copy of the cached release of q, replacing it with a
+// fresh one when it is due and the budget allows
+func currentStatsRelease(q *statsQuery, now time.Time) statsRelease {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	cached := statsReleases[q.name]
+	if cached != nil && now.Before(cached.NextRefresh) {
+		cached.BudgetRemaining = statsAccountant.Remaining(q.name, now)
+		return *cached
+	}
+
+	remaining, ok := statsAccountant.Spend(q.name, q.cost, now)
+	if !ok {
+		refill := statsAccountant.Refill(q.name, now)
+		if cached == nil {
+			cached = &statsRelease{Query: q.name, Description: q.description, Unit: q.unit, Privacy: statsParams(q), Bins: []dpstats.Bin{}}
+			statsReleases[q.name] = cached
+		}
+		cached.BudgetRemaining = remaining
+		cached.BudgetExhausted = true
+		cached.NextRefresh = refill
+		return *cached
+	}
+
+	release := &statsRelease{
+		Query:           q.name,
+		Description:     q.description,
+		Unit:            q.unit,
+		Privacy:         statsParams(q),
+		ReleasedAt:      now,
+		NextRefresh:     now.Add(statsRefresh),
+		BudgetRemaining: remaining,
+		Bins:            q.compute(q, now),
+	}
+	statsReleases[q.name] = release
+	log.Printf("📊 Released %s (epsilon %g, %g left in window)", q.name, q.cost.Epsilon, remaining.Epsilon)
+	return *release
+}
+
+// statsParams discloses q's mechanism, including the threshold for open histograms
+func statsParams(q *statsQuery) dpstats.Params {
+	params := q.mechanism.Params()
+	params.Threshold = q.threshold
+	params.Delta = q.cost.Delta
+	if q.name == statsTopKeywords {
+		params.MaxKeys = statsKeywordsPerCase
+	}
+	return params
+}
+
+// statsDayKeys returns the last statsDays completed UTC days, oldest first
+func statsDayKeys(now time.Time) []string {
+	today := now.UTC().Truncate(24 * time.Hour)
+	keys := make([]string, 0, statsDays)
+	for i := statsDays; i >= 1; i-- {
+		keys = append(keys, today.AddDate(0, 0, -i).Format("2006-01-02"))
+	}
+	return keys
+}
+
+// computeCasesPerDay counts cases by the day they were opened
+func computeCasesPerDay(q *statsQuery, now time.Time) []dpstats.Bin {
+	counts := make(map[string]int)
+	for _, errorLog := range loadAllCases() {
+		counts[errorLog.Timestamp.UTC().Format("2006-01-02")]++
+	}
+	return dpstats.Histogram(statsDayKeys(now), counts, q.mechanism)
+}
+
+// computeTipsPerDay counts submitted tips by day; erased tips no longer count
+func computeTipsPerDay(q *statsQuery, now time.Time) []dpstats.Bin {
+	counts := make(map[string]int)
+	for _, tip := range loadStatsTips() {
+		if tip.ModerationStatus != "erased" {
+			counts[tip.Timestamp.UTC().Format("2006-01-02")]++
+		}
+	}
+	return dpstats.Histogram(statsDayKeys(now), counts, q.mechanism)
+}
+
+// computeTopKeywords counts keywords across cases and approved tips, each record
+// contributing at most statsKeywordsPerCase distinct keywords
+func computeTopKeywords(q *statsQuery, now time.Time) []dpstats.Bin {
+	counts := make(map[string]int)
+	add := func(keywords []string) {
+		for _, keyword := range dpstats.BoundContributions(keywords, statsKeywordsPerCase) {
+			counts[keyword]++
+		}
+	}
+	for _, errorLog := range loadAllCases() {
+		add(append(append([]string{}, errorLog.UserNoteKeywords...), errorLog.SeedKeywords...))
+	}
+	for _, tip := range loadStatsTips() {
+		if tip.ModerationStatus == "approved" || tip.ModerationStatus == "redacted" {
+			add(tip.Keywords)
+		}
+	}
+
+	bins := dpstats.OpenHistogram(counts, q.mechanism, q.threshold)
+	if len(bins) > statsTopKeywordCount {
+		bins = bins[:statsTopKeywordCount]
+	}
+	return bins
+}
+
+// loadStatsTips returns every stored tip, or the in-memory ones without DynamoDB
+func loadStatsTips() []types.AnonymousTip {
+	if useDynamoDB && tipRepo != nil {
+		tips, err := tipRepo.GetAll()
+		if err == nil {
+			return tips
+		}
+		log.Printf("⚠️  Failed to load tips for statistics: %v", err)
+	}
+	anonymousTipsMutex.RLock()
+	defer anonymousTipsMutex.RUnlock()
+	tips := make([]types.AnonymousTip, len(anonymousTips))
+	copy(tips, anonymousTips)
+	return tips
+}
@@ -0,0 +1,375 @@
+/*
+# Module: storage/blob.go
+Blob store interface and local-disk implementation for generated binary assets.
+
+Share images, comic strips and cached media are addressed by content-hash keys,
+so identical inputs map to the same object and stores never need invalidation.
+
+## Linked Modules
+- [storage/blob_s3](./blob_s3.go) - S3-compatible implementation
+
+## Tags
+storage, blob, cache, persistence
+
+## Exports
+BlobStore, ErrBlobNotFound, ContentHashKey, LocalBlobStore, NewLocalBlobStore, MemoryBlobStore, NewMemoryBlobStore
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "storage/blob.go" ;
+    code:description "Blob store interface and local-disk implementation for generated binary assets" ;
+    code:linksTo [
+        code:name "storage/blob_s3" ;
+        code:path "./blob_s3.go" ;
+        code:relationship "S3-compatible implementation"
+    ] ;
+    code:exports :BlobStore, :ErrBlobNotFound, :ContentHashKey, :LocalBlobStore, :NewLocalBlobStore, :MemoryBlobStore, :NewMemoryBlobStore ;
+    code:tags "storage", "blob", "cache", "persistence" .
+<!-- End LinkedDoc RDF -->
+*/
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrBlobNotFound is returned when a key does not exist in a blob store
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore persists opaque binary objects under string keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentHashKey builds a key of the form prefix/sha256(parts...)ext.
+// Parts are length-prefixed before hashing so ("ab","c") and ("a","bc") differ.
+func ContentHashKey(prefix string, ext string, parts ...string) string {
+	hasher := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hasher, "%d:%s|", len(part), part)
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	if prefix == "" {
+		return sum + ext
+	}
+	return strings.TrimRight(prefix, "/") + "/" + sum + ext
+}
+
+// validateBlobKey rejects keys that could escape the store root
+func validateBlobKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("blob key is required")
+	}
+	if strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key: %s", key)
+	}
+	return nil
+}
+
+// sizeIndex orders stored keys from most to least recently used and picks the ones
+// to evict once their total size passes maxBytes. maxBytes <= 0 never evicts.
+// Callers hold their store's lock.
+type sizeIndex struct {
+	maxBytes int64
+	total    int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type sizeEntry struct {
+	key  string
+	size int64
+}
+
+func newSizeIndex(maxBytes int64) *sizeIndex {
+	return &sizeIndex{maxBytes: maxBytes, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// touch marks key as just used
+func (x *sizeIndex) touch(key string) {
+	if element, ok := x.items[key]; ok {
+		x.order.MoveToFront(element)
+	}
+}
+
+// add records key with its size as most recently used and returns the keys to evict,
+// oldest first. An object larger than the whole budget evicts itself.
+func (x *sizeIndex) add(key string, size int64) []string {
+	if element, ok := x.items[key]; ok {
+		entry := element.Value.(*sizeEntry)
+		x.total += size - entry.size
+		entry.size = size
+		x.order.MoveToFront(element)
+	} else {
+		x.items[key] = x.order.PushFront(&sizeEntry{key: key, size: size})
+		x.total += size
+	}
+
+	var evicted []string
+	for x.maxBytes > 0 && x.total > x.maxBytes {
+		oldest := x.order.Back()
+		if oldest == nil {
+			break
+		}
+		entry := oldest.Value.(*sizeEntry)
+		x.remove(entry.key)
+		evicted = append(evicted, entry.key)
+	}
+	return evicted
+}
+
+// remove forgets key
+func (x *sizeIndex) remove(key string) {
+	if element, ok := x.items[key]; ok {
+		x.total -= element.Value.(*sizeEntry).size
+		x.order.Remove(element)
+		delete(x.items, key)
+	}
+}
+
+// LocalBlobStore implements BlobStore on the local filesystem.
+// Content types are recorded in a sidecar file next to each object. Once the objects
+// take more than the store's byte budget, the least recently used ones are deleted.
+type LocalBlobStore struct {
+	root string
+
+	mu    sync.Mutex
+	index *sizeIndex
+}
+
+// NewLocalBlobStore creates a blob store rooted at dir, creating it if needed, that keeps
+// at most maxBytes of objects (0 for no limit). Objects already in dir count towards the
+// budget, oldest modification time first in line for eviction.
+func NewLocalBlobStore(dir string, maxBytes int64) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	s := &LocalBlobStore{root: dir, index: newSizeIndex(maxBytes)}
+
+	type existing struct {
+		key  string
+		size int64
+		mod  int64
+	}
+	var found []existing
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, ".type") || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		found = append(found, existing{key: filepath.ToSlash(rel), size: info.Size(), mod: info.ModTime().UnixNano()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index blob directory: %w", err)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].mod < found[j].mod })
+	for _, object := range found {
+		s.removeFiles(s.index.add(object.key, object.size))
+	}
+	return s, nil
+}
+
+// removeFiles deletes evicted objects and their content types; s.mu must be held
+func (s *LocalBlobStore) removeFiles(keys []string) {
+	for _, key := range keys {
+		path := filepath.Join(s.root, filepath.FromSlash(key))
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("⚠️  Failed to evict blob %s: %v", key, err)
+		}
+		os.Remove(path + ".type")
+	}
+}
+
+// Put writes an object atomically (temp file + rename)
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := writeFileAtomic(path+".type", []byte(contentType)); err != nil {
+		return fmt.Errorf("failed to write blob content type: %w", err)
+	}
+
+	s.mu.Lock()
+	s.removeFiles(s.index.add(key, int64(len(data))))
+	s.mu.Unlock()
+	return nil
+}
+
+// Get reads an object and its content type
+func (s *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	if err := validateBlobKey(key); err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	contentType := "application/octet-stream"
+	if typeData, err := os.ReadFile(path + ".type"); err == nil && len(typeData) > 0 {
+		contentType = string(typeData)
+	}
+
+	s.mu.Lock()
+	s.index.touch(key)
+	s.mu.Unlock()
+	return data, contentType, nil
+}
+
+// Exists reports whether an object is present
+func (s *LocalBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validateBlobKey(key); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete removes an object; deleting a missing key is not an error
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	os.Remove(path + ".type")
+
+	s.mu.Lock()
+	s.index.remove(key)
+	s.mu.Unlock()
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// MemoryBlobStore implements BlobStore in memory (used when no durable store is configured).
+// The least recently used objects are dropped once the store passes its byte budget.
+type MemoryBlobStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	index   *sizeIndex
+}
+
+// NewMemoryBlobStore creates an empty in-memory blob store holding at most maxBytes
+// (0 for no limit)
+func NewMemoryBlobStore(maxBytes int64) *MemoryBlobStore {
+	return &MemoryBlobStore{
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+		index:   newSizeIndex(maxBytes),
+	}
+}
+
+// Put stores a copy of data
+func (s *MemoryBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	copied := make([]byte, len(data))
+	copy(copied, data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = copied
+	s.types[key] = contentType
+	for _, evicted := range s.index.add(key, int64(len(copied))) {
+		delete(s.objects, evicted)
+		delete(s.types, evicted)
+	}
+	return nil
+}
+
+// Get returns a stored object
+func (s *MemoryBlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, "", ErrBlobNotFound
+	}
+	s.index.touch(key)
+	return data, s.types[key], nil
+}
+
+// Exists reports whether an object is present
+func (s *MemoryBlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+// Delete removes an object
+func (s *MemoryBlobStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	delete(s.types, key)
+	s.index.remove(key)
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+# Module: storage/blob_s3.go
+S3-compatible implementation of BlobStore (AWS S3, MinIO, R2, etc.).
+
+## Linked Modules
+- [storage/blob](./blob.go) - BlobStore interface
+
+## Tags
+storage, blob, s3, persistence
+
+## Exports
+S3BlobStore, NewS3BlobStore
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "storage/blob_s3.go" ;
+    code:description "S3-compatible implementation of BlobStore" ;
+    code:linksTo [
+        code:name "storage/blob" ;
+        code:path "./blob.go" ;
+        code:relationship "BlobStore interface"
+    ] ;
+    code:exports :S3BlobStore, :NewS3BlobStore ;
+    code:tags "storage", "blob", "s3", "persistence" .
+<!-- End LinkedDoc RDF -->
+*/
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// S3BlobStore implements BlobStore on an S3-compatible bucket
+type S3BlobStore struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3BlobStore creates an S3 blob store. endpoint is optional and enables
+// path-style addressing for S3-compatible services such as MinIO.
+func NewS3BlobStore(ctx context.Context, region, bucket, prefix, endpoint string) (*S3BlobStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3BlobStore{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+func (s *S3BlobStore) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+// Put uploads an object
+func (s *S3BlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.objectKey(key)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload blob to S3: %w", err)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	if err := validateBlobKey(key); err != nil {
+		return nil, "", err
+	}
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, "", ErrBlobNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get blob from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read blob from S3: %w", err)
+	}
+	return data, aws.ToString(result.ContentType), nil
+}
+
+// Exists checks for an object with HeadObject
+func (s *S3BlobStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validateBlobKey(key); err != nil {
+		return false, err
+	}
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head blob in S3: %w", err)
+	}
+	return true, nil
+}
+
+// Delete removes an object
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete blob from S3: %w", err)
+	}
+	return nil
+}
+
+// isS3NotFound recognizes both typed NoSuchKey errors and bare 404s from HeadObject
+func isS3NotFound(err error) bool {
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+	var notFound *s3types.NotFound
+	if errors.As(err, &notFound) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		return code == "NotFound" || code == "NoSuchKey"
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestBlobStoreEviction fills each bounded store past its budget and checks the least
+// recently used objects go first
+func TestBlobStoreEviction(t *testing.T) {
+	ctx := context.Background()
+	object := bytes.Repeat([]byte("x"), 100)
+
+	local, err := NewLocalBlobStore(t.TempDir(), 350)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]BlobStore{
+		"memory": NewMemoryBlobStore(350),
+		"local":  local,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"share/a.png", "share/b.png", "share/c.png"} {
+				if err := store.Put(ctx, key, object, "image/png"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Reading a keeps it; b is now the least recently used
+			if _, contentType, err := store.Get(ctx, "share/a.png"); err != nil || contentType != "image/png" {
+				t.Fatalf("Get = %q, %v", contentType, err)
+			}
+			if err := store.Put(ctx, "share/d.png", object, "image/png"); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]bool{"share/a.png": true, "share/b.png": false, "share/c.png": true, "share/d.png": true}
+			for key, kept := range want {
+				if ok, _ := store.Exists(ctx, key); ok != kept {
+					t.Errorf("Exists(%s) = %v, want %v", key, ok, kept)
+				}
+			}
+			if _, _, err := store.Get(ctx, "share/b.png"); !errors.Is(err, ErrBlobNotFound) {
+				t.Errorf("Get of an evicted object = %v, want ErrBlobNotFound", err)
+			}
+
+			// An object larger than the whole budget is not kept
+			if err := store.Put(ctx, "share/huge.png", bytes.Repeat(object, 4), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			if ok, _ := store.Exists(ctx, "share/huge.png"); ok {
+				t.Error("an object over the budget was kept")
+			}
+		})
+	}
+}
+
+// TestLocalBlobStoreReopen checks objects left on disk count towards the budget and the
+// oldest are evicted first
+func TestLocalBlobStoreReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	object := bytes.Repeat([]byte("x"), 100)
+
+	store, err := NewLocalBlobStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{"media/old.jpg", "media/mid.jpg", "media/new.jpg"}
+	for i, key := range keys {
+		if err := store.Put(ctx, key, object, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+		at := time.Now().Add(time.Duration(i-len(keys)) * time.Hour)
+		os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), at, at)
+	}
+
+	if _, err := NewLocalBlobStore(dir, 250); err != nil {
+		t.Fatal(err)
+	}
+	for key, kept := range map[string]bool{"media/old.jpg": false, "media/mid.jpg": true, "media/new.jpg": true} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)))
+		if exists := err == nil; exists != kept {
+			t.Errorf("%s on disk = %v, want %v", key, exists, kept)
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)+".type")); (err == nil) != kept {
+			t.Errorf("%s content type left = %v, want %v", key, err == nil, kept)
+		}
+	}
+}
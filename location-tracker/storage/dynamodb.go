@@ -7,6 +7,7 @@ Consolidated DynamoDB repository implementations for all data types.
 - [types/location](../types/location.go) - Location data structures
 - [types/commercial](../types/commercial.go) - Commercial real estate data structures
 - [types/tip](../types/tip.go) - Anonymous tip data structures
+- [envelope/encoder](../envelope/encoder.go) - Field encryption at rest
 
 ## Tags
 storage, dynamodb, persistence, repository
@@ -35,6 +36,10 @@ LocationDynamoDBRepository, CommercialDynamoDBRepository, TipDynamoDBRepository
         code:name "types/tip" ;
         code:path "../types/tip.go" ;
         code:relationship "Anonymous tip data structures"
+    ], [
+        code:name "envelope/encoder" ;
+        code:path "../envelope/encoder.go" ;
+        code:relationship "Field encryption at rest"
     ] ;
     code:exports :LocationDynamoDBRepository, :CommercialDynamoDBRepository, :TipDynamoDBRepository ;
     code:tags "storage", "dynamodb", "persistence", "repository" .
@@ -53,20 +58,25 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
+	"location-tracker/envelope"
 	"location-tracker/types"
 )
 
-// LocationDynamoDBRepository implements LocationRepository using DynamoDB
+// LocationDynamoDBRepository implements LocationRepository using DynamoDB.
+// Coordinates and place names are sealed by the encoder before they are written.
 type LocationDynamoDBRepository struct {
 	client    *dynamodb.Client
 	tableName string
+	encoder   *envelope.Encoder
 }
 
-// NewLocationDynamoDBRepository creates a new DynamoDB location repository
-func NewLocationDynamoDBRepository(client *dynamodb.Client, tableName string) *LocationDynamoDBRepository {
+// NewLocationDynamoDBRepository creates a new DynamoDB location repository. A nil
+// encoder stores fields in plaintext.
+func NewLocationDynamoDBRepository(client *dynamodb.Client, tableName string, encoder *envelope.Encoder) *LocationDynamoDBRepository {
 	return &LocationDynamoDBRepository{
 		client:    client,
 		tableName: tableName,
+		encoder:   encoder,
 	}
 }
 
@@ -78,6 +88,15 @@ func (r *LocationDynamoDBRepository) Save(location types.Location) error {
 
 	ctx := context.Background()
 
+	deviceID := location.DeviceID
+	if r.encoder == nil {
+		// The exact position is only ever written sealed
+		location.ExactLatitude, location.ExactLongitude = 0, 0
+	}
+	if err := r.encoder.Seal(ctx, &location); err != nil {
+		return fmt.Errorf("failed to seal location: %w", err)
+	}
+
 	item, err := attributevalue.MarshalMap(location)
 	if err != nil {
 		return fmt.Errorf("failed to marshal location: %w", err)
@@ -91,7 +110,7 @@ func (r *LocationDynamoDBRepository) Save(location types.Location) error {
 		return fmt.Errorf("failed to save location to DynamoDB: %w", err)
 	}
 
-	log.Printf("💾 Location saved to DynamoDB: device_id=%s", location.DeviceID)
+	log.Printf("💾 Location saved to DynamoDB: device_id=%s", deviceID)
 	return nil
 }
 
@@ -121,6 +140,9 @@ func (r *LocationDynamoDBRepository) GetByDeviceID(deviceID string) (*types.Loca
 	if err := attributevalue.UnmarshalMap(result.Item, &location); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal location: %w", err)
 	}
+	if err := r.encoder.Open(ctx, &location); err != nil {
+		return nil, fmt.Errorf("failed to open location: %w", err)
+	}
 
 	return &location, nil
 }
@@ -155,6 +177,10 @@ func (r *LocationDynamoDBRepository) GetAll() (map[string]types.Location, error)
 				log.Printf("⚠️  Failed to unmarshal location: %v", err)
 				continue
 			}
+			if err := r.encoder.Open(ctx, &location); err != nil {
+				log.Printf("⚠️  Failed to open location %s: %v", location.DeviceID, err)
+				continue
+			}
 			locations[location.DeviceID] = location
 		}
 
@@ -168,6 +194,28 @@ func (r *LocationDynamoDBRepository) GetAll() (map[string]types.Location, error)
 	return locations, nil
 }
 
+// Delete removes a device's location
+func (r *LocationDynamoDBRepository) Delete(deviceID string) error {
+	if r.client == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	ctx := context.Background()
+
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"device_id": &dynamodbtypes.AttributeValueMemberS{Value: deviceID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	log.Printf("🗑️  Location deleted from DynamoDB: device_id=%s", deviceID)
+	return nil
+}
+
 // CommercialDynamoDBRepository implements CommercialRepository using DynamoDB
 type CommercialDynamoDBRepository struct {
 	client    *dynamodb.Client
@@ -277,17 +325,21 @@ func (r *CommercialDynamoDBRepository) GetByName(locationName string) (*types.Co
 	return &commercial, nil
 }
 
-// TipDynamoDBRepository implements TipRepository using DynamoDB
+// TipDynamoDBRepository implements TipRepository using DynamoDB.
+// Submitter IP addresses are sealed by the encoder before they are written.
 type TipDynamoDBRepository struct {
 	client    *dynamodb.Client
 	tableName string
+	encoder   *envelope.Encoder
 }
 
-// NewTipDynamoDBRepository creates a new DynamoDB tip repository
-func NewTipDynamoDBRepository(client *dynamodb.Client, tableName string) *TipDynamoDBRepository {
+// NewTipDynamoDBRepository creates a new DynamoDB tip repository. A nil encoder stores
+// fields in plaintext.
+func NewTipDynamoDBRepository(client *dynamodb.Client, tableName string, encoder *envelope.Encoder) *TipDynamoDBRepository {
 	return &TipDynamoDBRepository{
 		client:    client,
 		tableName: tableName,
+		encoder:   encoder,
 	}
 }
 
@@ -299,6 +351,10 @@ func (r *TipDynamoDBRepository) Save(tip types.AnonymousTip) error {
 
 	ctx := context.Background()
 
+	if err := r.encoder.Seal(ctx, &tip); err != nil {
+		return fmt.Errorf("failed to seal tip: %w", err)
+	}
+
 	item, err := attributevalue.MarshalMap(tip)
 	if err != nil {
 		return fmt.Errorf("failed to marshal tip: %w", err)
@@ -342,6 +398,9 @@ func (r *TipDynamoDBRepository) GetByID(tipID string) (*types.AnonymousTip, erro
 	if err := attributevalue.UnmarshalMap(result.Item, &tip); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal tip: %w", err)
 	}
+	if err := r.encoder.Open(ctx, &tip); err != nil {
+		return nil, fmt.Errorf("failed to open tip: %w", err)
+	}
 
 	return &tip, nil
 }
@@ -369,6 +428,10 @@ func (r *TipDynamoDBRepository) GetRecent(limit int) ([]types.AnonymousTip, erro
 			log.Printf("⚠️  Failed to unmarshal tip: %v", err)
 			continue
 		}
+		if err := r.encoder.Open(ctx, &tip); err != nil {
+			log.Printf("⚠️  Failed to open tip %s: %v", tip.ID, err)
+			continue
+		}
 		tips = append(tips, tip)
 	}
 
@@ -405,6 +468,10 @@ func (r *TipDynamoDBRepository) GetAll() ([]types.AnonymousTip, error) {
 				log.Printf("⚠️  Failed to unmarshal tip: %v", err)
 				continue
 			}
+			if err := r.encoder.Open(ctx, &tip); err != nil {
+				log.Printf("⚠️  Failed to open tip %s: %v", tip.ID, err)
+				continue
+			}
 			tips = append(tips, tip)
 		}
 
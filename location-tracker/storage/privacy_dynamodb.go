@@ -0,0 +1,237 @@
+/*
+# Module: storage/privacy_dynamodb.go
+DynamoDB repositories for donations, SMS messages and privacy audit entries.
+
+## Linked Modules
+- [storage/repository](./repository.go) - Repository interfaces
+- [types/donation](../types/donation.go) - Donation data structures
+- [types/privacy](../types/privacy.go) - SMS message and privacy audit data structures
+- [envelope/encoder](../envelope/encoder.go) - Field encryption at rest
+
+## Tags
+storage, dynamodb, privacy, persistence, repository
+
+## Exports
+DonationDynamoDBRepository, NewDonationDynamoDBRepository, SMSDynamoDBRepository, NewSMSDynamoDBRepository, PrivacyAuditDynamoDBRepository, NewPrivacyAuditDynamoDBRepository
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "storage/privacy_dynamodb.go" ;
+    code:description "DynamoDB repositories for donations, SMS messages and privacy audit entries" ;
+    code:linksTo [
+        code:name "storage/repository" ;
+        code:path "./repository.go" ;
+        code:relationship "Repository interfaces"
+    ], [
+        code:name "types/donation" ;
+        code:path "../types/donation.go" ;
+        code:relationship "Donation data structures"
+    ], [
+        code:name "types/privacy" ;
+        code:path "../types/privacy.go" ;
+        code:relationship "SMS message and privacy audit data structures"
+    ], [
+        code:name "envelope/encoder" ;
+        code:path "../envelope/encoder.go" ;
+        code:relationship "Field encryption at rest"
+    ] ;
+    code:exports :DonationDynamoDBRepository, :NewDonationDynamoDBRepository, :SMSDynamoDBRepository, :NewSMSDynamoDBRepository, :PrivacyAuditDynamoDBRepository, :NewPrivacyAuditDynamoDBRepository ;
+    code:tags "storage", "dynamodb", "privacy", "persistence", "repository" .
+<!-- End LinkedDoc RDF -->
+*/
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"location-tracker/envelope"
+	"location-tracker/types"
+)
+
+// putItem marshals and stores one item
+func putItem(client *dynamodb.Client, tableName string, value interface{}) error {
+	if client == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	item, err := attributevalue.MarshalMap(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal item: %w", err)
+	}
+
+	_, err = client.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save item to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// scanAll reads every item of a table, skipping items that don't unmarshal or open
+func scanAll[T any](client *dynamodb.Client, tableName string, encoder *envelope.Encoder) ([]T, error) {
+	if client == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	ctx := context.Background()
+
+	var values []T
+	var lastEvaluatedKey map[string]dynamodbtypes.AttributeValue
+
+	for {
+		input := &dynamodb.ScanInput{
+			TableName: aws.String(tableName),
+		}
+		if lastEvaluatedKey != nil {
+			input.ExclusiveStartKey = lastEvaluatedKey
+		}
+
+		result, err := client.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", tableName, err)
+		}
+
+		for _, item := range result.Items {
+			var value T
+			if err := attributevalue.UnmarshalMap(item, &value); err != nil {
+				log.Printf("⚠️  Failed to unmarshal item from %s: %v", tableName, err)
+				continue
+			}
+			if err := encoder.Open(ctx, &value); err != nil {
+				log.Printf("⚠️  Failed to open item from %s: %v", tableName, err)
+				continue
+			}
+			values = append(values, value)
+		}
+
+		lastEvaluatedKey = result.LastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			break
+		}
+	}
+
+	return values, nil
+}
+
+// DonationDynamoDBRepository implements DonationRepository using DynamoDB
+type DonationDynamoDBRepository struct {
+	client    *dynamodb.Client
+	tableName string
+	encoder   *envelope.Encoder
+}
+
+// NewDonationDynamoDBRepository creates a new DynamoDB donation repository. A nil
+// encoder stores fields in plaintext.
+func NewDonationDynamoDBRepository(client *dynamodb.Client, tableName string, encoder *envelope.Encoder) *DonationDynamoDBRepository {
+	return &DonationDynamoDBRepository{
+		client:    client,
+		tableName: tableName,
+		encoder:   encoder,
+	}
+}
+
+// Save stores a donation
+func (r *DonationDynamoDBRepository) Save(donation types.Donation) error {
+	if err := r.encoder.Seal(context.Background(), &donation); err != nil {
+		return fmt.Errorf("failed to seal donation: %w", err)
+	}
+	return putItem(r.client, r.tableName, donation)
+}
+
+// GetAll retrieves all donations
+func (r *DonationDynamoDBRepository) GetAll() ([]types.Donation, error) {
+	return scanAll[types.Donation](r.client, r.tableName, r.encoder)
+}
+
+// SMSDynamoDBRepository implements SMSRepository using DynamoDB, keyed by message SID
+type SMSDynamoDBRepository struct {
+	client    *dynamodb.Client
+	tableName string
+	encoder   *envelope.Encoder
+}
+
+// NewSMSDynamoDBRepository creates a new DynamoDB SMS message repository. A nil
+// encoder stores message bodies in plaintext.
+func NewSMSDynamoDBRepository(client *dynamodb.Client, tableName string, encoder *envelope.Encoder) *SMSDynamoDBRepository {
+	return &SMSDynamoDBRepository{
+		client:    client,
+		tableName: tableName,
+		encoder:   encoder,
+	}
+}
+
+// Save stores an SMS message
+func (r *SMSDynamoDBRepository) Save(message types.SMSMessage) error {
+	if err := r.encoder.Seal(context.Background(), &message); err != nil {
+		return fmt.Errorf("failed to seal SMS message: %w", err)
+	}
+	return putItem(r.client, r.tableName, message)
+}
+
+// GetAll retrieves all SMS messages
+func (r *SMSDynamoDBRepository) GetAll() ([]types.SMSMessage, error) {
+	return scanAll[types.SMSMessage](r.client, r.tableName, r.encoder)
+}
+
+// Delete removes an SMS message
+func (r *SMSDynamoDBRepository) Delete(sid string) error {
+	if r.client == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	_, err := r.client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"sid": &dynamodbtypes.AttributeValueMemberS{Value: sid},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete SMS message: %w", err)
+	}
+	return nil
+}
+
+// PrivacyAuditDynamoDBRepository implements PrivacyAuditRepository using DynamoDB, keyed by entry ID
+type PrivacyAuditDynamoDBRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewPrivacyAuditDynamoDBRepository creates a new DynamoDB privacy audit repository
+func NewPrivacyAuditDynamoDBRepository(client *dynamodb.Client, tableName string) *PrivacyAuditDynamoDBRepository {
+	return &PrivacyAuditDynamoDBRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// Save stores an audit entry, replacing an earlier state of the same entry
+func (r *PrivacyAuditDynamoDBRepository) Save(entry types.PrivacyAuditEntry) error {
+	return putItem(r.client, r.tableName, entry)
+}
+
+// GetRecent retrieves the most recent audit entries (up to limit), newest first
+func (r *PrivacyAuditDynamoDBRepository) GetRecent(limit int) ([]types.PrivacyAuditEntry, error) {
+	entries, err := scanAll[types.PrivacyAuditEntry](r.client, r.tableName, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RequestedAt.After(entries[j].RequestedAt)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
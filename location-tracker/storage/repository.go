@@ -7,12 +7,14 @@ Repository interfaces for data persistence layer.
 - [types/location](../types/location.go) - Location data structures
 - [types/commercial](../types/commercial.go) - Commercial real estate data structures
 - [types/tip](../types/tip.go) - Anonymous tip data structures
+- [types/donation](../types/donation.go) - Donation data structures
+- [types/privacy](../types/privacy.go) - SMS message and privacy audit data structures
 
 ## Tags
 storage, repository, interface, persistence
 
 ## Exports
-ErrorLogRepository, LocationRepository, CommercialRepository, TipRepository
+ErrorLogRepository, LocationRepository, CommercialRepository, TipRepository, DonationRepository, SMSRepository, PrivacyAuditRepository
 
 <!-- LinkedDoc RDF -->
 @prefix code: <https://schema.codedoc.org/> .
@@ -35,8 +37,16 @@ ErrorLogRepository, LocationRepository, CommercialRepository, TipRepository
         code:name "types/tip" ;
         code:path "../types/tip.go" ;
         code:relationship "Anonymous tip data structures"
+    ], [
+        code:name "types/donation" ;
+        code:path "../types/donation.go" ;
+        code:relationship "Donation data structures"
+    ], [
+        code:name "types/privacy" ;
+        code:path "../types/privacy.go" ;
+        code:relationship "SMS message and privacy audit data structures"
     ] ;
-    code:exports :ErrorLogRepository, :LocationRepository, :CommercialRepository, :TipRepository ;
+    code:exports :ErrorLogRepository, :LocationRepository, :CommercialRepository, :TipRepository, :DonationRepository, :SMSRepository, :PrivacyAuditRepository ;
     code:tags "storage", "repository", "interface", "persistence" .
 <!-- End LinkedDoc RDF -->
 */
@@ -59,6 +69,7 @@ type LocationRepository interface {
 	Save(location types.Location) error
 	GetByDeviceID(deviceID string) (*types.Location, error)
 	GetAll() (map[string]types.Location, error)
+	Delete(deviceID string) error
 }
 
 // CommercialRepository handles commercial real estate persistence
@@ -75,3 +86,22 @@ type TipRepository interface {
 	GetRecent(limit int) ([]types.AnonymousTip, error)
 	GetAll() ([]types.AnonymousTip, error)
 }
+
+// DonationRepository handles donation persistence
+type DonationRepository interface {
+	Save(donation types.Donation) error
+	GetAll() ([]types.Donation, error)
+}
+
+// SMSRepository handles SMS message persistence
+type SMSRepository interface {
+	Save(message types.SMSMessage) error
+	GetAll() ([]types.SMSMessage, error)
+	Delete(sid string) error
+}
+
+// PrivacyAuditRepository handles persistence of data export and erasure audit entries
+type PrivacyAuditRepository interface {
+	Save(entry types.PrivacyAuditEntry) error
+	GetRecent(limit int) ([]types.PrivacyAuditEntry, error)
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"location-tracker/geoprivacy"
+	"location-tracker/replica"
+	"location-tracker/types"
+)
+
+const (
+	syncMaxBodyBytes = 1 << 20
+	syncMaxChanges   = 500
+	syncMaxPage      = 500
+)
+
+// syncLog versions every location and tip written since startup, locally or by a sync
+// push, plus the recent locations loaded from DynamoDB under their stored versions. Replicas (a user's pod client, an offline phone) pull changes after a cursor
+// and push their own writes back; see handleSync.
+var syncLog *replica.Log
+
+// syncLocationMu serializes pushed locations, so a repeated push is recognised before
+// it is fuzzed again
+var syncLocationMu sync.Mutex
+
+var syncTipIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// initializeSync starts the change log under this server's node ID
+func initializeSync() {
+	syncLog = replica.NewLog(replica.NewClock(syncNodeID()))
+}
+
+// syncNodeID names this server in HLC timestamps: sync.node_id, else the hostname
+func syncNodeID() string {
+	if node := appConfig.Sync.NodeID; node != "" {
+		return node
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "server"
+}
+
+// syncLocation is the replicated part of a location; the user agent stays on the server
+type syncLocation struct {
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Accuracy     float64   `json:"accuracy"`
+	Timestamp    time.Time `json:"timestamp"`
+	DeviceID     string    `json:"device_id"`
+	Simulated    bool      `json:"simulated,omitempty"`
+	LocationName string    `json:"location_name,omitempty"`
+}
+
+// syncTip is the replicated part of a tip: what the submitter wrote and when. Moderation
+// output, the anonymous user hash, encrypted metadata and IP address never leave the server.
+type syncTip struct {
+	ID         string    `json:"id"`
+	TipContent string    `json:"tip_content"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func locationSyncData(loc types.Location) json.RawMessage {
+	data, _ := json.Marshal(syncLocation{
+		Latitude:     loc.Latitude,
+		Longitude:    loc.Longitude,
+		Accuracy:     loc.Accuracy,
+		Timestamp:    loc.Timestamp.UTC(),
+		DeviceID:     loc.DeviceID,
+		Simulated:    loc.Simulated,
+		LocationName: loc.LocationName,
+	})
+	return data
+}
+
+func tipSyncData(tip types.AnonymousTip) json.RawMessage {
+	data, _ := json.Marshal(syncTip{
+		ID:         tip.ID,
+		TipContent: tip.TipContent,
+		Timestamp:  tip.Timestamp.UTC(),
+	})
+	return data
+}
+
+// recordLocalLocation versions a location posted to this server and stores it in memory
+func recordLocalLocation(loc types.Location) types.Location {
+	_, err := syncLog.Local(replica.KindLocation, loc.DeviceID, locationSyncData(loc), func(record *replica.Record) error {
+		loc.Version = record.Version.String()
+		locationMutex.Lock()
+		locations[loc.DeviceID] = loc
+		locationMutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to version location for sync: %v", err)
+	}
+	return loc
+}
+
+// restoreSyncLocation puts a location loaded from DynamoDB back into the log under the
+// version it was stored with, so the clock moves past it and a stale push can't win
+// over it after a restart. Rows saved before sync existed are versioned by timestamp.
+// It is kept in memory unless something newer arrived since startup.
+func restoreSyncLocation(loc types.Location) {
+	version, err := replica.ParseTimestamp(loc.Version)
+	if err != nil {
+		version = replica.Timestamp{Wall: loc.Timestamp.UnixMilli(), Node: syncLog.Clock.Node}
+		loc.Version = version.String()
+	}
+	change := replica.Change{Record: replica.Record{
+		Kind:    replica.KindLocation,
+		ID:      loc.DeviceID,
+		Version: version,
+		Data:    locationSyncData(loc),
+	}}
+	_, err = syncLog.Apply(change, func(*replica.Record) error {
+		locationMutex.Lock()
+		locations[loc.DeviceID] = loc
+		locationMutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to restore sync version of %s: %v", loc.DeviceID, err)
+		locationMutex.Lock()
+		if _, ok := locations[loc.DeviceID]; !ok {
+			locations[loc.DeviceID] = loc
+		}
+		locationMutex.Unlock()
+	}
+}
+
+// recordLocalTip versions a tip submitted to this server and stores it in memory. Should
+// its ID already be taken by a synced tip, the tip gets a fresh one.
+func recordLocalTip(tip types.AnonymousTip) types.AnonymousTip {
+	for attempt := 0; attempt < 3; attempt++ {
+		var dropped []string
+		result, err := syncLog.Local(replica.KindTip, tip.ID, tipSyncData(tip), func(*replica.Record) error {
+			dropped = storeTipInMemory(tip)
+			return nil
+		})
+		if err != nil {
+			log.Printf("⚠️  Failed to version tip for sync: %v", err)
+			break
+		}
+		if result.Outcome == replica.Applied {
+			forgetSyncTips(dropped)
+			return tip
+		}
+		tip.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	forgetSyncTips(storeTipInMemory(tip))
+	return tip
+}
+
+// storeTipInMemory appends a tip to the cache, returning the IDs of tips pushed out of it
+func storeTipInMemory(tip types.AnonymousTip) []string {
+	anonymousTipsMutex.Lock()
+	defer anonymousTipsMutex.Unlock()
+
+	anonymousTips = append(anonymousTips, tip)
+	var dropped []string
+	// Keep only the most recent tips in memory
+	if len(anonymousTips) > appConfig.Limits.TipsInMemory {
+		for _, old := range anonymousTips[:len(anonymousTips)-appConfig.Limits.TipsInMemory] {
+			dropped = append(dropped, old.ID)
+		}
+		anonymousTips = anonymousTips[len(anonymousTips)-appConfig.Limits.TipsInMemory:]
+	}
+	return dropped
+}
+
+// forgetSyncTips drops tips the server no longer keeps in memory from the sync log; a
+// replica pushing one again is matched against DynamoDB instead
+func forgetSyncTips(ids []string) {
+	for _, id := range ids {
+		syncLog.Forget(replica.KindTip, id)
+	}
+}
+
+// syncRequest is a push: the replica's writes since its last sync, and the cursor it
+// wants to pull from
+type syncRequest struct {
+	Since   string           `json:"since"`
+	Changes []replica.Change `json:"changes"`
+}
+
+type syncResult struct {
+	Kind   replica.Kind `json:"kind"`
+	ID     string       `json:"id"`
+	Status string       `json:"status"` // applied, unchanged or rejected
+	Reason string       `json:"reason,omitempty"`
+}
+
+type syncResponse struct {
+	Node      string              `json:"node"`
+	Cursor    string              `json:"cursor"`
+	More      bool                `json:"more"`
+	Results   []syncResult        `json:"results,omitempty"`
+	Conflicts []*replica.Conflict `json:"conflicts,omitempty"`
+	Changes   []replica.Record    `json:"changes"`
+}
+
+// handleSync is the offline-first sync endpoint for the owner's replicas of locations
+// and tips, such as a Solid pod client or a phone that was offline.
+//
+// GET /api/sync?since=<cursor> pulls records written after the cursor (all of them
+// without one). POST pushes {"since": cursor, "changes": [...]} and answers with the
+// outcome of each change, any conflicts and the pull. Each change carries an HLC
+// version ("wall.logical.node") and the base version the replica last saw.
+//
+// Locations are last-writer-wins by version; tips are append-only, so an existing tip ID
+// keeps its content. A write that raced another one is applied or not by those rules and
+// listed under "conflicts" either way.
+func handleSync(w http.ResponseWriter, r *http.Request) {
+	if !isAuthenticated(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := syncMaxPage
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		if n < limit {
+			limit = n
+		}
+	}
+
+	response := syncResponse{Node: syncLog.Clock.Node}
+	since := r.URL.Query().Get("since")
+	pushed := make(map[string]replica.Record)
+	var kept []replica.Record
+
+	switch r.Method {
+	case "GET":
+	case "POST":
+		var req syncRequest
+		r.Body = http.MaxBytesReader(w, r.Body, syncMaxBodyBytes)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid sync request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.Changes) > syncMaxChanges {
+			http.Error(w, fmt.Sprintf("Too many changes (max %d per request)", syncMaxChanges), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if req.Since != "" {
+			since = req.Since
+		}
+
+		for _, change := range req.Changes {
+			result, err := applySyncChange(r, change)
+			status := syncResult{Kind: change.Kind, ID: change.ID}
+			switch {
+			case err != nil:
+				status.Status = "rejected"
+				status.Reason = err.Error()
+			default:
+				status.Status = string(result.Outcome)
+				pushed[syncKey(change.Kind, change.ID)] = change.Record
+				if result.Conflict != nil {
+					response.Conflicts = append(response.Conflicts, result.Conflict)
+				}
+				if !sameRecord(result.Record, change.Record) {
+					// The replica's write lost, or was stored fuzzed: send it the record
+					// the server keeps, even if that is older than its cursor
+					kept = append(kept, result.Record)
+				}
+			}
+			response.Results = append(response.Results, status)
+		}
+		if len(response.Conflicts) > 0 {
+			log.Printf("🔄 Sync push: %d changes, %d conflicts", len(req.Changes), len(response.Conflicts))
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	records, cursor, more, err := syncLog.Since(since, limit)
+	if errors.Is(err, replica.ErrInvalidCursor) {
+		http.Error(w, "Invalid sync cursor", http.StatusBadRequest)
+		return
+	}
+
+	// Leave out the replica's own writes it just pushed, and add the winners it lost to
+	response.Changes = make([]replica.Record, 0, len(records)+len(kept))
+	included := make(map[string]bool)
+	for _, record := range append(records, kept...) {
+		key := syncKey(record.Kind, record.ID)
+		if own, ok := pushed[key]; (ok && sameRecord(own, record)) || included[key] {
+			continue
+		}
+		included[key] = true
+		response.Changes = append(response.Changes, record)
+	}
+	response.Cursor = cursor
+	response.More = more
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+func syncKey(kind replica.Kind, id string) string {
+	return string(kind) + "/" + id
+}
+
+// sameRecord reports whether a replica already holds stored as it pushed it
+func sameRecord(pushed, stored replica.Record) bool {
+	return pushed.Version == stored.Version && bytes.Equal(pushed.Data, stored.Data)
+}
+
+// applySyncChange validates a pushed change and merges it into the log, storing it when
+// it wins
+func applySyncChange(r *http.Request, change replica.Change) (replica.Result, error) {
+	switch change.Kind {
+	case replica.KindLocation:
+		return applySyncLocation(r, change)
+	case replica.KindTip:
+		return applySyncTip(r, change)
+	default:
+		return replica.Result{}, fmt.Errorf("unknown record kind %q", change.Kind)
+	}
+}
+
+func applySyncLocation(r *http.Request, change replica.Change) (replica.Result, error) {
+	var data syncLocation
+	if err := json.Unmarshal(change.Data, &data); err != nil {
+		return replica.Result{}, fmt.Errorf("invalid location data: %v", err)
+	}
+	if data.DeviceID == "" {
+		data.DeviceID = change.ID
+	}
+	switch {
+	case data.DeviceID != change.ID:
+		return replica.Result{}, fmt.Errorf("device_id %q does not match record id", data.DeviceID)
+	case data.Latitude < -90 || data.Latitude > 90 || data.Longitude < -180 || data.Longitude > 180:
+		return replica.Result{}, fmt.Errorf("coordinates out of range")
+	case data.Accuracy < 0:
+		return replica.Result{}, fmt.Errorf("accuracy must not be negative")
+	}
+	if data.Timestamp.IsZero() {
+		data.Timestamp = change.Version.Time()
+	}
+
+	loc := types.Location{
+		Latitude:     data.Latitude,
+		Longitude:    data.Longitude,
+		Accuracy:     data.Accuracy,
+		Timestamp:    data.Timestamp,
+		DeviceID:     data.DeviceID,
+		UserAgent:    r.UserAgent(),
+		Simulated:    data.Simulated,
+		LocationName: data.LocationName,
+	}
+	syncLocationMu.Lock()
+	defer syncLocationMu.Unlock()
+
+	// The stored record holds the fuzzed point, so a push of a version already stored
+	// (a retry) would look like different data under the same version. Answer it with
+	// the stored record instead of fuzzing and charging the privacy budget again.
+	if current, ok := syncLog.Get(replica.KindLocation, change.ID); ok && current.Version == change.Version {
+		return replica.Result{Outcome: replica.Unchanged, Record: current}, nil
+	}
+
+	var release *geoprivacy.Release
+	return syncLog.Apply(change, func(record *replica.Record) error {
+		// Only a winning push is fuzzed and charged. Peers get the fuzzed point like
+		// every other client; the exact one is only stored sealed.
+		loc, release = fuzzLocation(loc)
+		record.Data = locationSyncData(loc)
+		loc.Version = record.Version.String()
+		locationMutex.Lock()
+		locations[loc.DeviceID] = loc
+		locationMutex.Unlock()
+
+		if useDynamoDB {
+			background.Async("save_location", func() { saveLocationToDynamoDB(loc) })
+		}
+		log.Printf("📍 Location synced: %s at (%.6f, %.6f) version %s",
+			loc.DeviceID, loc.Latitude, loc.Longitude, loc.Version)
+		if release != nil && release.Reused {
+			log.Printf("🔒 Privacy budget spent for %s, repeating its last shared point", loc.DeviceID)
+		}
+		return nil
+	})
+}
+
+// applySyncTip stores a tip written offline. New tips go through the same validation,
+// ban check and moderation as /api/tips, but don't seed cases: they are old news.
+func applySyncTip(r *http.Request, change replica.Change) (replica.Result, error) {
+	if !syncTipIDPattern.MatchString(change.ID) {
+		return replica.Result{}, fmt.Errorf("invalid tip id")
+	}
+	var data syncTip
+	if err := json.Unmarshal(change.Data, &data); err != nil {
+		return replica.Result{}, fmt.Errorf("invalid tip data: %v", err)
+	}
+	if data.ID == "" {
+		data.ID = change.ID
+	}
+	if data.ID != change.ID {
+		return replica.Result{}, fmt.Errorf("tip id %q does not match record id", data.ID)
+	}
+	if data.Timestamp.IsZero() {
+		data.Timestamp = change.Version.Time()
+	}
+	tip := types.AnonymousTip{ID: data.ID, TipContent: data.TipContent, Timestamp: data.Timestamp}
+	change.Data = tipSyncData(tip)
+
+	// A tip the log has forgotten (or never saw since startup) may still be in DynamoDB
+	if _, known := syncLog.Get(replica.KindTip, change.ID); !known && useDynamoDB {
+		if stored, err := getTipFromDynamoDB(change.ID); err == nil {
+			syncLog.Apply(replica.Change{Record: replica.Record{
+				Kind:    replica.KindTip,
+				ID:      stored.ID,
+				Version: replica.Timestamp{Wall: stored.Timestamp.UnixMilli(), Node: syncLog.Clock.Node},
+				Data:    tipSyncData(*stored),
+			}}, nil)
+		}
+	}
+	if _, known := syncLog.Get(replica.KindTip, change.ID); known {
+		return syncLog.Apply(change, nil)
+	}
+
+	if err := ValidateTipContent(tip.TipContent, tipMaxLength); err != nil {
+		return replica.Result{}, err
+	}
+	userHash, encryptedMetadata, err := identityManager.GenerateAnonymousID(r)
+	if err != nil {
+		return replica.Result{}, fmt.Errorf("failed to generate user ID")
+	}
+	if banned, reason, _ := banManager.IsUserBanned(userHash); banned {
+		return replica.Result{}, fmt.Errorf("banned: %s", reason)
+	}
+	moderationResult, err := contentModerator.ModerateTip(tip.TipContent)
+	if err != nil {
+		log.Printf("⚠️  Moderation error: %v", err)
+		return replica.Result{}, fmt.Errorf("moderation failed")
+	}
+	if moderationResult.Status == "rejected" {
+		return replica.Result{}, fmt.Errorf("rejected: %s", moderationResult.Reason)
+	}
+
+	tip.ModeratedContent = moderationResult.ModeratedText
+	tip.UserHash = userHash
+	tip.UserMetadata = encryptedMetadata
+	tip.ModerationStatus = moderationResult.Status
+	tip.ModerationReason = moderationResult.Reason
+	tip.Keywords = extractUserKeywords(moderationResult.ModeratedText)
+	tip.IPAddress = getClientIP(r)
+
+	var dropped []string
+	result, err := syncLog.Apply(change, func(*replica.Record) error {
+		dropped = storeTipInMemory(tip)
+		if useDynamoDB {
+			background.Async("save_tip", func() { saveTipToDynamoDB(tip) })
+		}
+		log.Printf("📝 Anonymous tip synced: %s (status: %s, user: %s)", tip.ID, tip.ModerationStatus, userHash)
+		return nil
+	})
+	forgetSyncTips(dropped)
+	return result, err
+}
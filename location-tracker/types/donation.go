@@ -26,13 +26,14 @@ import "time"
 
 // Donation represents a Stripe donation record with metadata
 type Donation struct {
-	ID                string    `json:"id" dynamodbav:"id"`
+	ID                string    `json:"id" dynamodbav:"id" envelope:"key"`
 	DonationType      string    `json:"donation_type" dynamodbav:"donation_type"` // "meme_disclaimer" or "church_committee"
 	Amount            int64     `json:"amount" dynamodbav:"amount"`               // Amount in cents
 	StripePaymentID   string    `json:"stripe_payment_id" dynamodbav:"stripe_payment_id"`
 	UserHash          string    `json:"user_hash,omitempty" dynamodbav:"user_hash"`
 	Timestamp         time.Time `json:"timestamp" dynamodbav:"timestamp"`
-	IPAddress         string    `json:"ip_address,omitempty" dynamodbav:"ip_address"`
+	IPAddress         string    `json:"ip_address,omitempty" dynamodbav:"ip_address" envelope:"encrypt"`
 	Status            string    `json:"status" dynamodbav:"status"` // "pending", "succeeded", "failed"
 	BankRecordPurpose string    `json:"bank_record_purpose" dynamodbav:"bank_record_purpose"`
+	Sealed            string    `json:"-" dynamodbav:"sealed,omitempty" envelope:"sealed"`
 }
@@ -46,6 +46,7 @@ type ErrorLog struct {
 	TikTokVideo         *TikTokVideo       `json:"tiktok_video,omitempty" dynamodbav:"tiktok_video,omitempty"`
 	UserExperienceNote  string             `json:"user_experience_note,omitempty" dynamodbav:"user_experience_note"`
 	UserNoteKeywords    []string           `json:"user_note_keywords,omitempty" dynamodbav:"user_note_keywords"`
+	UserNoteSID         string             `json:"user_note_sid,omitempty" dynamodbav:"user_note_sid,omitempty"` // SMS the note came from
 	NearbyBusinesses    []string           `json:"nearby_businesses,omitempty" dynamodbav:"nearby_businesses"`
 	AnonymousTips       []string           `json:"anonymous_tips,omitempty" dynamodbav:"anonymous_tips"`
 	Timestamp           time.Time          `json:"timestamp" dynamodbav:"timestamp"`
@@ -26,12 +26,19 @@ import "time"
 
 // Location represents a tracked geographic location with metadata
 type Location struct {
-	Latitude     float64   `json:"latitude" dynamodbav:"latitude"`
-	Longitude    float64   `json:"longitude" dynamodbav:"longitude"`
-	Accuracy     float64   `json:"accuracy" dynamodbav:"accuracy"`
-	Timestamp    time.Time `json:"timestamp" dynamodbav:"timestamp"`
-	DeviceID     string    `json:"device_id" dynamodbav:"device_id"`
+	Latitude     float64   `json:"latitude" dynamodbav:"latitude" envelope:"encrypt"`
+	Longitude    float64   `json:"longitude" dynamodbav:"longitude" envelope:"encrypt"`
+	Accuracy     float64   `json:"accuracy" dynamodbav:"accuracy" envelope:"encrypt"`
+	Timestamp    time.Time `json:"timestamp" dynamodbav:"timestamp" envelope:"key"`
+	DeviceID     string    `json:"device_id" dynamodbav:"device_id" envelope:"key"`
 	UserAgent    string    `json:"user_agent" dynamodbav:"user_agent"`
 	Simulated    bool      `json:"simulated,omitempty" dynamodbav:"simulated"`
-	LocationName string    `json:"location_name,omitempty" dynamodbav:"location_name"`
+	LocationName string    `json:"location_name,omitempty" dynamodbav:"location_name" envelope:"encrypt"`
+	Version      string    `json:"version,omitempty" dynamodbav:"version,omitempty"`  // HLC version used by /api/sync
+	Sealed       string    `json:"-" dynamodbav:"sealed,omitempty" envelope:"sealed"` // encrypted fields at rest, see envelope
+
+	// Exact position as reported by the device. Latitude and Longitude hold the fuzzed
+	// point that is shared; the exact one is never served and is only stored sealed.
+	ExactLatitude  float64 `json:"-" dynamodbav:"exact_latitude,omitempty" envelope:"encrypt"`
+	ExactLongitude float64 `json:"-" dynamodbav:"exact_longitude,omitempty" envelope:"encrypt"`
 }
@@ -0,0 +1,49 @@
+/*
+# Module: types/privacy.go
+SMS message records and data subject request audit entries.
+
+## Linked Modules
+(None - types package has no dependencies)
+
+## Tags
+data-types, privacy, sms, audit
+
+## Exports
+SMSMessage, PrivacyAuditEntry
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "types/privacy.go" ;
+    code:description "SMS message records and data subject request audit entries" ;
+    code:exports :SMSMessage, :PrivacyAuditEntry ;
+    code:tags "data-types", "privacy", "sms", "audit" .
+<!-- End LinkedDoc RDF -->
+*/
+package types
+
+import "time"
+
+// SMSMessage represents a user experience note received by SMS. The sender's phone
+// number is kept only as a keyed hash, enough to find their messages again.
+type SMSMessage struct {
+	SID        string    `json:"sid" dynamodbav:"sid" envelope:"key"`
+	SenderHash string    `json:"sender_hash" dynamodbav:"sender_hash"`
+	Body       string    `json:"body" dynamodbav:"body" envelope:"encrypt"`
+	Keywords   []string  `json:"keywords,omitempty" dynamodbav:"keywords"`
+	Timestamp  time.Time `json:"timestamp" dynamodbav:"timestamp"`
+	Sealed     string    `json:"-" dynamodbav:"sealed,omitempty" envelope:"sealed"`
+}
+
+// PrivacyAuditEntry records a data export or erasure. The subject is identified only by
+// a keyed digest of the identifiers given, so the entry outlives the data it describes.
+type PrivacyAuditEntry struct {
+	ID            string         `json:"id" dynamodbav:"id"`
+	Action        string         `json:"action" dynamodbav:"action"` // "export" or "erasure"
+	SubjectDigest string         `json:"subject_digest" dynamodbav:"subject_digest"`
+	Status        string         `json:"status" dynamodbav:"status"` // "running", "completed" or "failed"
+	Counts        map[string]int `json:"counts,omitempty" dynamodbav:"counts"`
+	Errors        []string       `json:"errors,omitempty" dynamodbav:"errors"`
+	RequestedAt   time.Time      `json:"requested_at" dynamodbav:"requested_at"`
+	CompletedAt   time.Time      `json:"completed_at,omitempty" dynamodbav:"completed_at"`
+}
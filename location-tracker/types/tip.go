@@ -26,7 +26,7 @@ import "time"
 
 // AnonymousTip represents an anonymous tip submission with moderation metadata
 type AnonymousTip struct {
-	ID               string    `json:"id" dynamodbav:"id"`
+	ID               string    `json:"id" dynamodbav:"id" envelope:"key"`
 	TipContent       string    `json:"tip_content" dynamodbav:"tip_content"`
 	ModeratedContent string    `json:"moderated_content" dynamodbav:"moderated_content"`
 	UserHash         string    `json:"user_hash" dynamodbav:"user_hash"`
@@ -35,5 +35,6 @@ type AnonymousTip struct {
 	ModerationReason string    `json:"moderation_reason,omitempty" dynamodbav:"moderation_reason"`
 	Keywords         []string  `json:"keywords,omitempty" dynamodbav:"keywords"`
 	Timestamp        time.Time `json:"timestamp" dynamodbav:"timestamp"`
-	IPAddress        string    `json:"ip_address,omitempty" dynamodbav:"ip_address"`
+	IPAddress        string    `json:"ip_address,omitempty" dynamodbav:"ip_address" envelope:"encrypt"`
+	Sealed           string    `json:"-" dynamodbav:"sealed,omitempty" envelope:"sealed"`
 }
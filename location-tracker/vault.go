@@ -0,0 +1,756 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"location-tracker/sanitize"
+	"location-tracker/types"
+)
+
+// Generated note content lives between these markers; everything outside them
+// (and any frontmatter keys the exporter doesn't own) is left for humans.
+const (
+	vaultGeneratedStart = "<!-- vault:generated:start -->"
+	vaultGeneratedEnd   = "<!-- vault:generated:end -->"
+	vaultRelatedCases   = 5
+)
+
+// vaultFolders are the vault subdirectories, one per note kind
+const (
+	vaultCasesDir      = "Cases"
+	vaultBusinessesDir = "Businesses"
+	vaultKeywordsDir   = "Keywords"
+	vaultSeedsDir      = "Seeds"
+	vaultTipsDir       = "Tips"
+	vaultIndexNote     = "Case Archive"
+)
+
+// vaultNote is a generated note: managed frontmatter keys (in order) plus the generated body
+type vaultNote struct {
+	Path        string
+	Frontmatter []vaultField
+	Body        string
+}
+
+// vaultField is one managed frontmatter key; Value is a string, int, []string or time.Time
+type vaultField struct {
+	Key   string
+	Value interface{}
+}
+
+// vaultExportStats counts what an export did
+type vaultExportStats struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Skipped   int
+}
+
+// vaultIndex cross-references cases by business, keyword and seed
+type vaultIndex struct {
+	byBusiness map[string][]*types.ErrorLog
+	byKeyword  map[string][]*types.ErrorLog
+	bySeed     map[string][]*types.ErrorLog
+	tipCases   map[string][]*types.ErrorLog
+	tips       map[string]types.AnonymousTip
+	files      map[string]map[string]string // folder -> note name -> file name, see noteFileNames
+}
+
+// buildVaultNotes generates every note for cases (newest first) and tips
+func buildVaultNotes(cases []types.ErrorLog, tips []types.AnonymousTip) []vaultNote {
+	index := vaultIndex{
+		byBusiness: make(map[string][]*types.ErrorLog),
+		byKeyword:  make(map[string][]*types.ErrorLog),
+		bySeed:     make(map[string][]*types.ErrorLog),
+		tipCases:   make(map[string][]*types.ErrorLog),
+		tips:       make(map[string]types.AnonymousTip),
+	}
+	for _, tip := range tips {
+		index.tips[tip.ID] = tip
+	}
+	for i := range cases {
+		errorLog := &cases[i]
+		for _, business := range uniqueStrings(errorLog.NearbyBusinesses) {
+			index.byBusiness[business] = append(index.byBusiness[business], errorLog)
+		}
+		for _, keyword := range caseKeywords(errorLog) {
+			index.byKeyword[keyword] = append(index.byKeyword[keyword], errorLog)
+		}
+		if seed := seedNoteName(errorLog); seed != "" {
+			index.bySeed[seed] = append(index.bySeed[seed], errorLog)
+		}
+		for _, tipID := range uniqueStrings(errorLog.AnonymousTips) {
+			index.tipCases[tipID] = append(index.tipCases[tipID], errorLog)
+		}
+	}
+	index.files = map[string]map[string]string{
+		vaultBusinessesDir: noteFileNames(sortedKeys(index.byBusiness)),
+		vaultKeywordsDir:   noteFileNames(sortedKeys(index.byKeyword)),
+		vaultSeedsDir:      noteFileNames(sortedKeys(index.bySeed)),
+		vaultTipsDir:       noteFileNames(tipNoteNames(sortedKeys(index.tipCases))),
+	}
+
+	notes := make([]vaultNote, 0, len(cases)+len(index.byBusiness)+len(index.byKeyword)+len(index.bySeed)+len(index.tipCases)+1)
+	for i := range cases {
+		notes = append(notes, buildCaseNote(&cases[i], &index))
+	}
+	for _, business := range sortedKeys(index.byBusiness) {
+		notes = append(notes, buildListNote(vaultBusinessesDir, business, "business", index.byBusiness[business], &index,
+			fmt.Sprintf("Cases logged while **%s** was nearby.", business)))
+	}
+	for _, keyword := range sortedKeys(index.byKeyword) {
+		notes = append(notes, buildListNote(vaultKeywordsDir, keyword, "keyword", index.byKeyword[keyword], &index,
+			fmt.Sprintf("Cases tagged with the keyword **%s** (user notes or seed interactions).", keyword)))
+	}
+	for _, seed := range sortedKeys(index.bySeed) {
+		notes = append(notes, buildSeedNote(seed, index.bySeed[seed], &index))
+	}
+	for _, tipID := range sortedKeys(index.tipCases) {
+		notes = append(notes, buildTipNote(tipID, index.tips[tipID], index.tipCases[tipID], &index))
+	}
+	notes = append(notes, buildArchiveIndexNote(cases, &index))
+	return notes
+}
+
+// buildCaseNote renders one ErrorLog with all metadata in frontmatter and long text in the body
+func buildCaseNote(errorLog *types.ErrorLog, index *vaultIndex) vaultNote {
+	fields := []vaultField{
+		{"id", errorLog.ID},
+		{"title", errorLog.Message},
+		{"aliases", []string{errorLog.Message}},
+		{"timestamp", errorLog.Timestamp},
+		{"url", caseURL(errorLog)},
+		{"slogan", errorLog.Slogan},
+		{"gif_urls", errorLog.GifURLs},
+		{"meme_url", errorLog.MemeURL},
+		{"food_image_url", errorLog.FoodImageURL},
+		{"food_image_attr", errorLog.FoodImageAttr},
+		{"song_title", errorLog.SongTitle},
+		{"song_artist", errorLog.SongArtist},
+		{"song_url", errorLog.SongURL},
+		{"user_note_keywords", errorLog.UserNoteKeywords},
+		{"nearby_businesses", errorLog.NearbyBusinesses},
+		{"anonymous_tips", errorLog.AnonymousTips},
+		{"seed_interaction_type", errorLog.SeedInteractionType},
+		{"seed_interaction_id", errorLog.SeedInteractionID},
+		{"seed_interaction_timestamp", errorLog.SeedInteractionTimestamp},
+		{"seed_keywords", errorLog.SeedKeywords},
+		{"rorschach_image_number", errorLog.RorschachImageNumber},
+		{"rorschach_image_url", errorLog.RorschachImageURL},
+	}
+	if errorLog.CSpanVideo != nil {
+		fields = append(fields, vaultField{"cspan_video_url", errorLog.CSpanVideo.URL}, vaultField{"cspan_video_title", errorLog.CSpanVideo.Title})
+	}
+	if errorLog.CSpanLivestream != nil {
+		fields = append(fields, vaultField{"cspan_livestream_video_id", errorLog.CSpanLivestream.VideoID}, vaultField{"cspan_livestream_title", errorLog.CSpanLivestream.Title})
+	}
+	if errorLog.TikTokVideo != nil {
+		fields = append(fields, vaultField{"tiktok_url", errorLog.TikTokVideo.URL}, vaultField{"tiktok_author", errorLog.TikTokVideo.Author})
+	}
+	tags := []string{"case"}
+	for _, keyword := range caseKeywords(errorLog) {
+		tags = append(tags, tagName(keyword))
+	}
+	fields = append(fields, vaultField{"tags", tags})
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "# %s\n\n", markdownLine(errorLog.Message))
+	if errorLog.Slogan != "" {
+		fmt.Fprintf(&body, "> %s\n\n", markdownLine(errorLog.Slogan))
+	}
+	fmt.Fprintf(&body, "[Open case](%s) · %s\n", caseURL(errorLog), errorLog.Timestamp.Format("January 2, 2006 15:04 MST"))
+
+	if paragraphs := storyParagraphs(errorLog.ChildrensStory); len(paragraphs) > 0 {
+		body.WriteString("\n## Story\n\n")
+		body.WriteString(strings.Join(paragraphs, "\n\n"))
+		body.WriteString("\n")
+	}
+	writeVaultSection(&body, "Field notes", errorLog.UserExperienceNote)
+	writeVaultSection(&body, "Description", sanitize.PlainText(errorLog.VerboseDesc))
+	writeVaultSection(&body, "Proposed fix", sanitize.PlainText(errorLog.SatiricalFix))
+	if errorLog.RorschachAIResponse != "" || errorLog.RorschachUserResponse != "" {
+		body.WriteString("\n## Rorschach\n\n")
+		if errorLog.RorschachAIResponse != "" {
+			fmt.Fprintf(&body, "- Agency: %s\n", markdownLine(errorLog.RorschachAIResponse))
+		}
+		if errorLog.RorschachUserResponse != "" {
+			fmt.Fprintf(&body, "- Subject: %s\n", markdownLine(errorLog.RorschachUserResponse))
+		}
+	}
+
+	media := make([]string, 0)
+	if errorLog.MemeURL != "" {
+		media = append(media, fmt.Sprintf("![Meme](%s)", errorLog.MemeURL))
+	}
+	if errorLog.FoodImageURL != "" {
+		media = append(media, fmt.Sprintf("![Food](%s)", errorLog.FoodImageURL))
+	}
+	for _, gifURL := range errorLog.GifURLs {
+		media = append(media, fmt.Sprintf("![GIF](%s)", gifURL))
+	}
+	if errorLog.SongURL != "" {
+		media = append(media, fmt.Sprintf("🎵 [%s – %s](%s)", markdownLine(errorLog.SongTitle), markdownLine(errorLog.SongArtist), errorLog.SongURL))
+	}
+	if len(media) > 0 {
+		body.WriteString("\n## Media\n\n")
+		body.WriteString(strings.Join(media, "\n"))
+		body.WriteString("\n")
+	}
+
+	links := make([]string, 0)
+	if seed := seedNoteName(errorLog); seed != "" {
+		links = append(links, "- Seed: "+index.wikilink(vaultSeedsDir, seed))
+	}
+	if businesses := index.wikilinks(vaultBusinessesDir, uniqueStrings(errorLog.NearbyBusinesses)); businesses != "" {
+		links = append(links, "- Businesses: "+businesses)
+	}
+	if keywords := index.wikilinks(vaultKeywordsDir, caseKeywords(errorLog)); keywords != "" {
+		links = append(links, "- Keywords: "+keywords)
+	}
+	if tipNotes := index.wikilinks(vaultTipsDir, tipNoteNames(errorLog.AnonymousTips)); tipNotes != "" {
+		links = append(links, "- Tips: "+tipNotes)
+	}
+	if related := relatedCases(errorLog, index); len(related) > 0 {
+		links = append(links, "- Related cases:")
+		for _, other := range related {
+			links = append(links, "  - "+caseWikilink(other))
+		}
+	}
+	if len(links) > 0 {
+		body.WriteString("\n## Links\n\n")
+		body.WriteString(strings.Join(links, "\n"))
+		body.WriteString("\n")
+	}
+
+	return vaultNote{Path: filepath.Join(vaultCasesDir, caseNoteName(errorLog)+".md"), Frontmatter: fields, Body: body.String()}
+}
+
+// buildListNote renders a business or keyword index note
+func buildListNote(dir, name, kind string, cases []*types.ErrorLog, index *vaultIndex, intro string) vaultNote {
+	var body strings.Builder
+	fmt.Fprintf(&body, "# %s\n\n%s\n\n", markdownLine(name), intro)
+	writeCaseList(&body, cases)
+	return vaultNote{
+		Path: filepath.Join(dir, index.fileName(dir, name)+".md"),
+		Frontmatter: []vaultField{
+			{"title", name},
+			{"type", kind},
+			{"case_count", len(cases)},
+			{"first_seen", oldestCase(cases).Timestamp},
+			{"last_seen", cases[0].Timestamp},
+			{"tags", []string{kind}},
+		},
+		Body: body.String(),
+	}
+}
+
+// buildSeedNote renders the interaction that seeded one or more cases
+func buildSeedNote(name string, cases []*types.ErrorLog, index *vaultIndex) vaultNote {
+	seed := cases[0]
+	var body strings.Builder
+	fmt.Fprintf(&body, "# Seed %s\n\n", markdownLine(name))
+	fmt.Fprintf(&body, "A **%s** interaction at %s seeded these cases.\n", markdownLine(seed.SeedInteractionType), seed.SeedInteractionTimestamp.Format("January 2, 2006 15:04 MST"))
+	if keywords := index.wikilinks(vaultKeywordsDir, uniqueStrings(seed.SeedKeywords)); keywords != "" {
+		fmt.Fprintf(&body, "\nKeywords: %s\n", keywords)
+	}
+	body.WriteString("\n")
+	writeCaseList(&body, cases)
+	return vaultNote{
+		Path: filepath.Join(vaultSeedsDir, index.fileName(vaultSeedsDir, name)+".md"),
+		Frontmatter: []vaultField{
+			{"seed_interaction_id", seed.SeedInteractionID},
+			{"seed_interaction_type", seed.SeedInteractionType},
+			{"seed_interaction_timestamp", seed.SeedInteractionTimestamp},
+			{"seed_keywords", seed.SeedKeywords},
+			{"case_count", len(cases)},
+			{"tags", []string{"seed"}},
+		},
+		Body: body.String(),
+	}
+}
+
+// buildTipNote renders an anonymous tip (moderated content only, never the submitter hash or IP)
+func buildTipNote(tipID string, tip types.AnonymousTip, cases []*types.ErrorLog, index *vaultIndex) vaultNote {
+	content := tip.ModeratedContent
+	if content == "" {
+		content = "_Tip content not available._"
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "# Tip %s\n\n%s\n", markdownLine(tipID), content)
+	if keywords := index.wikilinks(vaultKeywordsDir, uniqueStrings(tip.Keywords)); keywords != "" {
+		fmt.Fprintf(&body, "\nKeywords: %s\n", keywords)
+	}
+	body.WriteString("\n## Attached to\n\n")
+	writeCaseList(&body, cases)
+
+	fields := []vaultField{
+		{"tip_id", tipID},
+		{"moderation_status", tip.ModerationStatus},
+		{"keywords", tip.Keywords},
+		{"tags", []string{"tip"}},
+	}
+	if !tip.Timestamp.IsZero() {
+		fields = append(fields, vaultField{"timestamp", tip.Timestamp})
+	}
+	return vaultNote{Path: filepath.Join(vaultTipsDir, index.fileName(vaultTipsDir, tipNoteName(tipID))+".md"), Frontmatter: fields, Body: body.String()}
+}
+
+// buildArchiveIndexNote renders the top-level map of content
+func buildArchiveIndexNote(cases []types.ErrorLog, index *vaultIndex) vaultNote {
+	var body strings.Builder
+	fmt.Fprintf(&body, "# %s\n\n", vaultIndexNote)
+	fmt.Fprintf(&body, "%d cases, %d businesses, %d keywords, %d seeds, %d tips.\n",
+		len(cases), len(index.byBusiness), len(index.byKeyword), len(index.bySeed), len(index.tipCases))
+
+	body.WriteString("\n## Businesses\n\n")
+	for _, business := range sortedKeys(index.byBusiness) {
+		fmt.Fprintf(&body, "- %s (%d)\n", index.wikilink(vaultBusinessesDir, business), len(index.byBusiness[business]))
+	}
+	body.WriteString("\n## Keywords\n\n")
+	for _, keyword := range sortedKeys(index.byKeyword) {
+		fmt.Fprintf(&body, "- %s (%d)\n", index.wikilink(vaultKeywordsDir, keyword), len(index.byKeyword[keyword]))
+	}
+
+	// Group cases by month, newest first
+	body.WriteString("\n## Cases\n")
+	month := ""
+	for i := range cases {
+		if m := cases[i].Timestamp.Format("January 2006"); m != month {
+			month = m
+			fmt.Fprintf(&body, "\n### %s\n\n", month)
+		}
+		fmt.Fprintf(&body, "- %s %s\n", cases[i].Timestamp.Format("Jan 2"), caseWikilink(&cases[i]))
+	}
+
+	return vaultNote{
+		Path:        vaultIndexNote + ".md",
+		Frontmatter: []vaultField{{"title", vaultIndexNote}, {"case_count", len(cases)}, {"tags", []string{"index"}}},
+		Body:        body.String(),
+	}
+}
+
+// writeCaseList writes a dated bullet list of case links
+func writeCaseList(body *strings.Builder, cases []*types.ErrorLog) {
+	for _, errorLog := range cases {
+		fmt.Fprintf(body, "- %s %s\n", errorLog.Timestamp.Format("2006-01-02"), caseWikilink(errorLog))
+	}
+}
+
+func writeVaultSection(body *strings.Builder, heading, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	fmt.Fprintf(body, "\n## %s\n\n%s\n", heading, strings.TrimSpace(text))
+}
+
+// relatedCases ranks other cases by shared keywords, businesses and seed
+func relatedCases(errorLog *types.ErrorLog, index *vaultIndex) []*types.ErrorLog {
+	scores := make(map[*types.ErrorLog]int)
+	for _, keyword := range caseKeywords(errorLog) {
+		for _, other := range index.byKeyword[keyword] {
+			scores[other] += 2
+		}
+	}
+	for _, business := range uniqueStrings(errorLog.NearbyBusinesses) {
+		for _, other := range index.byBusiness[business] {
+			scores[other]++
+		}
+	}
+	if seed := seedNoteName(errorLog); seed != "" {
+		for _, other := range index.bySeed[seed] {
+			scores[other] += 3
+		}
+	}
+
+	related := make([]*types.ErrorLog, 0, len(scores))
+	for other := range scores {
+		if other.ID != errorLog.ID {
+			related = append(related, other)
+		}
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if scores[related[i]] != scores[related[j]] {
+			return scores[related[i]] > scores[related[j]]
+		}
+		return related[i].Timestamp.After(related[j].Timestamp)
+	})
+	if len(related) > vaultRelatedCases {
+		related = related[:vaultRelatedCases]
+	}
+	return related
+}
+
+// caseKeywords merges user note and seed keywords, lowercased and deduplicated
+func caseKeywords(errorLog *types.ErrorLog) []string {
+	keywords := make([]string, 0, len(errorLog.UserNoteKeywords)+len(errorLog.SeedKeywords))
+	for _, keyword := range append(append([]string{}, errorLog.UserNoteKeywords...), errorLog.SeedKeywords...) {
+		keywords = append(keywords, strings.ToLower(strings.TrimSpace(keyword)))
+	}
+	return uniqueStrings(keywords)
+}
+
+func caseNoteName(errorLog *types.ErrorLog) string {
+	return fmt.Sprintf("%s case-%s", errorLog.Timestamp.Format("2006-01-02"), errorLog.ID)
+}
+
+func caseWikilink(errorLog *types.ErrorLog) string {
+	return fmt.Sprintf("[[%s/%s|%s]]", vaultCasesDir, caseNoteName(errorLog), wikilinkAlias(errorLog.Message))
+}
+
+func seedNoteName(errorLog *types.ErrorLog) string {
+	if errorLog.SeedInteractionID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s", errorLog.SeedInteractionType, errorLog.SeedInteractionID)
+}
+
+func tipNoteName(tipID string) string {
+	return "tip-" + tipID
+}
+
+func tipNoteNames(tipIDs []string) []string {
+	names := make([]string, 0, len(tipIDs))
+	for _, tipID := range uniqueStrings(tipIDs) {
+		names = append(names, tipNoteName(tipID))
+	}
+	return names
+}
+
+// wikilink links to dir/name, showing name
+func (index *vaultIndex) wikilink(dir, name string) string {
+	return fmt.Sprintf("[[%s/%s|%s]]", dir, index.fileName(dir, name), wikilinkAlias(name))
+}
+
+func (index *vaultIndex) wikilinks(dir string, names []string) string {
+	links := make([]string, len(names))
+	for i, name := range names {
+		links[i] = index.wikilink(dir, name)
+	}
+	return strings.Join(links, ", ")
+}
+
+// fileName returns the file name (without extension) of the note dir/name. Names
+// without a note of their own, like a tip keyword no case has, fall back to noteFileName.
+func (index *vaultIndex) fileName(dir, name string) string {
+	if file, ok := index.files[dir][name]; ok {
+		return file
+	}
+	return noteFileName(name)
+}
+
+// noteFileNames assigns file names to the sorted notes of one folder. noteFileName is lossy,
+// so "A/B" and "A-B" would share A-B.md; names whose file names collide (ignoring case,
+// as macOS and Windows do) get a suffix from a hash of the name. A name noteFileName
+// keeps unchanged has first claim on the plain file name, so a new "A/B" never moves
+// an existing "A-B" note and its hand-written notes.
+func noteFileNames(names []string) map[string]string {
+	groups := make(map[string][]string, len(names))
+	for _, name := range names {
+		key := strings.ToLower(noteFileName(name))
+		groups[key] = append(groups[key], name)
+	}
+
+	files := make(map[string]string, len(names))
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return noteFileName(group[i]) == group[i] && noteFileName(group[j]) != group[j]
+		})
+		for i, name := range group {
+			files[name] = noteFileName(name)
+			if i > 0 {
+				sum := sha256.Sum256([]byte(name))
+				files[name] += " " + hex.EncodeToString(sum[:4])
+			}
+		}
+	}
+	return files
+}
+
+// noteFileName replaces characters that are invalid in file names or break wikilinks
+func noteFileName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', '#', '^', '[', ']':
+			return '-'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	cleaned = strings.Trim(cleaned, ". ")
+	if cleaned == "" {
+		cleaned = "untitled"
+	}
+	if runes := []rune(cleaned); len(runes) > 120 {
+		cleaned = string(runes[:120])
+	}
+	return cleaned
+}
+
+// wikilinkAlias makes text safe for the display part of a wikilink
+func wikilinkAlias(text string) string {
+	return strings.NewReplacer("|", "-", "[", "(", "]", ")", "\n", " ").Replace(sanitize.Excerpt(text, 100))
+}
+
+// tagName turns a keyword into an Obsidian tag (no spaces)
+func tagName(keyword string) string {
+	return "keyword/" + strings.ReplaceAll(noteFileName(keyword), " ", "-")
+}
+
+// markdownLine collapses text to one line
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// storyParagraphs converts story HTML to Markdown paragraphs
+func storyParagraphs(story string) []string {
+	if strings.TrimSpace(story) == "" {
+		return nil
+	}
+	return sanitize.Paragraphs(story)
+}
+
+func oldestCase(cases []*types.ErrorLog) *types.ErrorLog {
+	oldest := cases[0]
+	for _, errorLog := range cases[1:] {
+		if errorLog.Timestamp.Before(oldest.Timestamp) {
+			oldest = errorLog
+		}
+	}
+	return oldest
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderNote merges a generated note into the existing file contents (nil for a new file).
+// Managed frontmatter keys are replaced, other keys are kept; the body between the
+// generated markers is replaced and the rest of the file is kept verbatim.
+func renderNote(note vaultNote, existing []byte) ([]byte, error) {
+	generated := vaultGeneratedStart + "\n" + note.Body + vaultGeneratedEnd
+
+	if existing == nil {
+		var out bytes.Buffer
+		out.WriteString(renderFrontmatter(note.Frontmatter, nil))
+		out.WriteString("\n")
+		out.WriteString(generated)
+		out.WriteString("\n\n## Notes\n\n")
+		return out.Bytes(), nil
+	}
+
+	humanKeys, rest := splitFrontmatter(string(existing), note.Frontmatter)
+	start := strings.Index(rest, vaultGeneratedStart)
+	end := strings.Index(rest, vaultGeneratedEnd)
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("generated section markers missing")
+	}
+
+	var out bytes.Buffer
+	out.WriteString(renderFrontmatter(note.Frontmatter, humanKeys))
+	out.WriteString(rest[:start])
+	out.WriteString(generated)
+	out.WriteString(rest[end+len(vaultGeneratedEnd):])
+	return out.Bytes(), nil
+}
+
+// renderFrontmatter writes managed fields in order, then preserved human blocks
+func renderFrontmatter(fields []vaultField, humanBlocks []string) string {
+	var out strings.Builder
+	out.WriteString("---\n")
+	for _, field := range fields {
+		switch value := field.Value.(type) {
+		case string:
+			if value != "" {
+				fmt.Fprintf(&out, "%s: %s\n", field.Key, strconv.Quote(value))
+			}
+		case int:
+			if value != 0 {
+				fmt.Fprintf(&out, "%s: %d\n", field.Key, value)
+			}
+		case time.Time:
+			if !value.IsZero() {
+				fmt.Fprintf(&out, "%s: %s\n", field.Key, value.UTC().Format(time.RFC3339))
+			}
+		case []string:
+			if values := uniqueStrings(value); len(values) > 0 {
+				fmt.Fprintf(&out, "%s:\n", field.Key)
+				for _, item := range values {
+					fmt.Fprintf(&out, "  - %s\n", strconv.Quote(item))
+				}
+			}
+		}
+	}
+	for _, block := range humanBlocks {
+		out.WriteString(block)
+	}
+	out.WriteString("---\n")
+	return out.String()
+}
+
+// splitFrontmatter returns the frontmatter blocks whose keys the exporter doesn't manage
+// (each block is a top-level key line plus its indented continuation lines) and the rest of the file
+func splitFrontmatter(content string, managed []vaultField) ([]string, string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return nil, content
+	}
+	if strings.HasPrefix(content[4:], "---\n") {
+		return nil, content[8:]
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		return nil, content
+	}
+	frontmatter := content[4 : 4+end+1]
+	rest := content[4+end+5:]
+
+	managedKeys := make(map[string]bool, len(managed))
+	for _, field := range managed {
+		managedKeys[field.Key] = true
+	}
+
+	blocks := make([]string, 0)
+	var current strings.Builder
+	keep := false
+	flush := func() {
+		if keep && current.Len() > 0 {
+			blocks = append(blocks, current.String())
+		}
+		current.Reset()
+	}
+	for _, line := range strings.SplitAfter(frontmatter, "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			flush()
+			key := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+			keep = !managedKeys[key]
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return blocks, rest
+}
+
+// writeVault writes notes under dir, touching only files whose content changes
+func writeVault(dir string, notes []vaultNote) (vaultExportStats, error) {
+	var stats vaultExportStats
+	for _, note := range notes {
+		path := filepath.Join(dir, note.Path)
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return stats, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if os.IsNotExist(err) {
+			existing = nil
+		}
+
+		content, err := renderNote(note, existing)
+		if err != nil {
+			log.Printf("⚠️  Leaving %s alone: %v", note.Path, err)
+			stats.Skipped++
+			continue
+		}
+		if existing != nil && bytes.Equal(content, existing) {
+			stats.Unchanged++
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return stats, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return stats, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if existing == nil {
+			stats.Created++
+		} else {
+			stats.Updated++
+		}
+	}
+	return stats, nil
+}
+
+// loadVaultTips returns anonymous tips from DynamoDB, or the in-memory list
+func loadVaultTips() []types.AnonymousTip {
+	if useDynamoDB && tipRepo != nil {
+		tips, err := tipRepo.GetAll()
+		if err == nil {
+			return tips
+		}
+		log.Printf("⚠️  Failed to load tips: %v", err)
+	}
+	anonymousTipsMutex.RLock()
+	defer anonymousTipsMutex.RUnlock()
+	tips := make([]types.AnonymousTip, len(anonymousTips))
+	copy(tips, anonymousTips)
+	return tips
+}
+
+// runExportVaultCommand implements `location-tracker export-vault [flags]`.
+// The vault holds full case data (notes, businesses, tips), so it is an operator tool only.
+func runExportVaultCommand(args []string) int {
+	flags := flag.NewFlagSet("export-vault", flag.ContinueOnError)
+	dir := flags.String("dir", "vault", "Vault directory (created if missing)")
+	since := flags.String("since", "", "First day to include (YYYY-MM-DD or RFC 3339)")
+	until := flags.String("until", "", "Last day to include (YYYY-MM-DD or RFC 3339)")
+	keyword := flags.String("keyword", "", "Only cases mentioning this keyword")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	filter, err := parseCaseFilter(map[string][]string{
+		"since":   {*since},
+		"until":   {*until},
+		"keyword": {*keyword},
+	})
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 2
+	}
+
+	initializeDynamoDB()
+
+	cases := filterCases(loadAllCases(), filter, 0)
+	notes := buildVaultNotes(cases, loadVaultTips())
+	stats, err := writeVault(*dir, notes)
+	if err != nil {
+		log.Printf("❌ Vault export failed: %v", err)
+		return 1
+	}
+	log.Printf("✅ Vault %s: %d created, %d updated, %d unchanged, %d skipped (%d cases)",
+		*dir, stats.Created, stats.Updated, stats.Unchanged, stats.Skipped, len(cases))
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"location-tracker/types"
+)
+
+func TestNoteFileNames(t *testing.T) {
+	files := noteFileNames([]string{"A-B", "A/B", "A:B", "Cafe", "Corner [north]", "cafe"})
+
+	for name, want := range map[string]string{"A-B": "A-B", "Cafe": "Cafe", "Corner [north]": "Corner -north-"} {
+		if files[name] != want {
+			t.Errorf("file name of %q = %q, want %q", name, files[name], want)
+		}
+	}
+	seen := make(map[string]string)
+	for name, file := range files {
+		if other, ok := seen[strings.ToLower(file)]; ok {
+			t.Errorf("%q and %q share the file name %q", name, other, file)
+		}
+		seen[strings.ToLower(file)] = name
+	}
+	for _, name := range []string{"A/B", "A:B"} {
+		if !strings.HasPrefix(files[name], "A-B ") || len(files[name]) != len("A-B ")+8 {
+			t.Errorf("file name of %q = %q, want A-B and a hash suffix", name, files[name])
+		}
+	}
+	if !strings.HasPrefix(files["cafe"], "cafe ") {
+		t.Errorf("file name of %q = %q, want a suffix: it differs from Cafe only in case", "cafe", files["cafe"])
+	}
+
+	// Stable from one export to the next, and a lossy name alone keeps the plain file name
+	if again := noteFileNames([]string{"A/B", "A:B", "Z"}); again["A:B"] != files["A:B"] || again["A/B"] != "A-B" {
+		t.Errorf("without A-B: %q", again)
+	}
+}
+
+func TestBuildVaultNotesCollidingNames(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	cases := []types.ErrorLog{
+		{ID: "1", Message: "First", Timestamp: now, NearbyBusinesses: []string{"Bar/Grill"}, AnonymousTips: []string{"x/y"}},
+		{ID: "2", Message: "Second", Timestamp: now.Add(-time.Hour), NearbyBusinesses: []string{"Bar-Grill"}, AnonymousTips: []string{"x-y"}},
+	}
+	notes := buildVaultNotes(cases, nil)
+
+	paths := make(map[string]vaultNote)
+	for _, note := range notes {
+		if _, ok := paths[note.Path]; ok {
+			t.Errorf("two notes written to %s", note.Path)
+		}
+		paths[note.Path] = note
+	}
+
+	plain := filepath.Join(vaultBusinessesDir, "Bar-Grill.md")
+	if note, ok := paths[plain]; !ok || !strings.Contains(note.Body, "# Bar-Grill\n") {
+		t.Fatalf("%s is not the Bar-Grill note", plain)
+	}
+	var suffixed string
+	for path, note := range paths {
+		if strings.HasPrefix(path, filepath.Join(vaultBusinessesDir, "Bar-Grill ")) && strings.Contains(note.Body, "# Bar/Grill\n") {
+			suffixed = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+	}
+	if suffixed == "" {
+		t.Fatal("no separate note for Bar/Grill")
+	}
+
+	// Every link points at the note of the name it shows
+	links := map[string][]string{
+		"1": {"[[Businesses/" + suffixed + "|Bar/Grill]]"},
+		"2": {"[[Businesses/Bar-Grill|Bar-Grill]]", "[[Tips/tip-x-y|tip-x-y]]"},
+	}
+	for _, note := range notes {
+		id, ok := note.Frontmatter[0].Value.(string)
+		if !strings.HasPrefix(note.Path, vaultCasesDir) || !ok {
+			continue
+		}
+		for _, link := range links[id] {
+			if !strings.Contains(note.Body, link) {
+				t.Errorf("case %s does not link %s:\n%s", id, link, note.Body)
+			}
+		}
+	}
+	index := paths[vaultIndexNote+".md"].Body
+	for _, link := range []string{"[[Businesses/Bar-Grill|Bar-Grill]]", "[[Businesses/" + suffixed + "|Bar/Grill]]"} {
+		if !strings.Contains(index, link) {
+			t.Errorf("archive index does not link %s", link)
+		}
+	}
+	tips := 0
+	for path := range paths {
+		if strings.HasPrefix(path, vaultTipsDir+string(filepath.Separator)) {
+			tips++
+		}
+	}
+	if tips != 2 {
+		t.Errorf("%d tip notes, want 2", tips)
+	}
+}
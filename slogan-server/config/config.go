@@ -0,0 +1,95 @@
+/*
+# Module: config/config.go
+Typed configuration for the slogan server: every tunable with its default, the file
+key it is read from and the environment variable that overrides it.
+
+Fields are tagged with their YAML/TOML key, an `env` variable name, and `secret`
+when the value must never be printed.
+
+## Linked Modules
+- [config/load](./load.go) - Reads a YAML or TOML file and applies env overrides
+
+## Tags
+config, settings, environment, defaults, validation
+
+## Exports
+Config, Server, OpenAI, Default, Validate
+
+<!-- LinkedDoc RDF -->
+@prefix code: <https://schema.codedoc.org/> .
+<this> a code:Module ;
+    code:name "config/config.go" ;
+    code:description "Typed configuration for the slogan server with defaults, file keys, env overrides and validation" ;
+    code:linksTo [
+        code:name "config/load" ;
+        code:path "./load.go" ;
+        code:relationship "Reads a YAML or TOML file and applies env overrides"
+    ] ;
+    code:exports :Config, :Server, :OpenAI, :Default, :Validate ;
+    code:tags "config", "settings", "environment", "defaults", "validation" .
+<!-- End LinkedDoc RDF -->
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Config is the complete slogan server configuration.
+type Config struct {
+	Server Server `yaml:"server" toml:"server"`
+	OpenAI OpenAI `yaml:"openai" toml:"openai"`
+}
+
+// Server covers the HTTP listener.
+type Server struct {
+	Port            string        `yaml:"port" toml:"port" env:"SLOGAN_SERVER_PORT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	MetricsToken    string        `yaml:"metrics_token" toml:"metrics_token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// OpenAI configures slogan generation; without an API key only fallback slogans are served.
+type OpenAI struct {
+	APIKey      string  `yaml:"api_key" toml:"api_key" env:"OPENAI_API_KEY" secret:"true"`
+	Model       string  `yaml:"model" toml:"model" env:"OPENAI_MODEL"`
+	MaxTokens   int     `yaml:"max_tokens" toml:"max_tokens" env:"OPENAI_MAX_TOKENS"`
+	Temperature float64 `yaml:"temperature" toml:"temperature" env:"OPENAI_TEMPERATURE"`
+}
+
+// Default returns the configuration used when neither a file nor the environment sets a value.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            "8080",
+			ShutdownTimeout: 25 * time.Second,
+		},
+		OpenAI: OpenAI{
+			Model:       "gpt-4o-mini",
+			MaxTokens:   200,
+			Temperature: 0.9,
+		},
+	}
+}
+
+// Validate reports every invalid setting, or nil when the configuration can be used.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "server.port (SLOGAN_SERVER_PORT) %q must be a port number", c.Server.Port)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout (SHUTDOWN_TIMEOUT) must be a positive duration")
+
+	check(c.OpenAI.Model != "", "openai.model (OPENAI_MODEL) must be set")
+	check(c.OpenAI.MaxTokens > 0, "openai.max_tokens (OPENAI_MAX_TOKENS) must be positive")
+	check(c.OpenAI.Temperature >= 0 && c.OpenAI.Temperature <= 2, "openai.temperature (OPENAI_TEMPERATURE) must be between 0 and 2")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"slogan-server/health"
+)
+
+// Health probes, served at /livez and /readyz in the same JSON shape as the other
+// services. Liveness fails only when a restart would help; readiness adds what is
+// needed to serve slogans.
+var (
+	livenessChecks  = health.NewRegistry()
+	readinessChecks = health.NewRegistry()
+
+	// lastOpenAIError is the error from the most recent OpenAI call, "" after a success
+	lastOpenAIError atomic.Value
+)
+
+// recordOpenAIResult notes whether the last OpenAI call succeeded
+func recordOpenAIResult(err error) {
+	if err != nil {
+		lastOpenAIError.Store(err.Error())
+		return
+	}
+	lastOpenAIError.Store("")
+}
+
+// initializeHealthChecks registers the readiness checks. OpenAI is optional since
+// fallback slogans are served without it.
+func initializeHealthChecks() {
+	readinessChecks.Register("fallback_slogans", func(ctx context.Context) error {
+		if len(nonsensicalSlogans) == 0 {
+			return fmt.Errorf("no fallback slogans loaded")
+		}
+		return nil
+	}, health.Options{Timeout: time.Second, CacheFor: time.Minute})
+
+	readinessChecks.Register("openai", func(ctx context.Context) error {
+		if openaiAPIKey == "" {
+			return fmt.Errorf("OPENAI_API_KEY not set, serving fallback slogans only")
+		}
+		if lastErr, _ := lastOpenAIError.Load().(string); lastErr != "" {
+			return fmt.Errorf("last generation failed: %s", lastErr)
+		}
+		return nil
+	}, health.Options{Timeout: time.Second, CacheFor: time.Second, Optional: true})
+}
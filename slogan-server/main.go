@@ -2,14 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -213,15 +217,15 @@ SLOGAN: [your slogan here]
 VERBOSE: [your verbose description here]`, errorMessage, gifContext, keywordContext)
 
 	reqBody := OpenAIRequest{
-		Model: "gpt-4o-mini",
+		Model: appConfig.OpenAI.Model,
 		Messages: []OpenAIMessage{
 			{
 				Role:    "user",
 				Content: prompt,
 			},
 		},
-		MaxTokens:   200,
-		Temperature: 0.9,
+		MaxTokens:   appConfig.OpenAI.MaxTokens,
+		Temperature: appConfig.OpenAI.Temperature,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -337,6 +341,7 @@ func handleErrorLog(responseWriter http.ResponseWriter, request *http.Request) {
 	// Try OpenAI first
 	if openaiAPIKey != "" {
 		generatedSlogan, generatedVerbose, err := generateSloganWithOpenAI(errorLogRequest.Message, errorLogRequest.GifURL, errorLogRequest.UserKeywords)
+		recordOpenAIResult(err)
 		if err != nil {
 			log.Printf("OpenAI generation failed, using fallback: %v", err)
 			slogan = getFallbackSlogan()
@@ -371,10 +376,12 @@ func healthCheck(responseWriter http.ResponseWriter, request *http.Request) {
 }
 
 func main() {
+	// Load and validate the configuration (exits for --print-config and --check-config)
+	initializeConfig(os.Args[1:])
+
 	rand.Seed(time.Now().UnixNano())
 
-	// Load OpenAI API key from environment
-	openaiAPIKey = os.Getenv("OPENAI_API_KEY")
+	openaiAPIKey = appConfig.OpenAI.APIKey
 
 	if openaiAPIKey != "" {
 		log.Printf("OpenAI API key configured, will generate slogans dynamically")
@@ -384,13 +391,34 @@ func main() {
 
 	log.Printf("Loaded %d fallback slogans", len(nonsensicalSlogans))
 
+	initializeMetrics()
+	initializeHealthChecks()
 	http.HandleFunc("/error-log", handleErrorLog)
 	http.HandleFunc("/health", healthCheck)
+	http.Handle("/livez", livenessChecks.Handler())
+	http.Handle("/readyz", readinessChecks.Handler())
 
-	port := "8080"
+	port := appConfig.Server.Port
 	log.Printf("Slogan server starting on port %s", port)
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal(err)
+	shutdownTimeout := appConfig.Server.ShutdownTimeout
+
+	server := &http.Server{Addr: ":" + port, Handler: instrumentRoutes(http.DefaultServeMux)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// On SIGTERM, let in-flight slogan requests finish before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	log.Printf("Shutting down (up to %s)...", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server did not drain: %v", err)
 	}
 }
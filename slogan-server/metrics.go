@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics, served at /metrics. Metric names match the other services so
+// dashboards can tell them apart by job alone.
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by registered route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by registered route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	externalAPIRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "external_api_requests_total",
+		Help: "Outbound API calls, by provider and outcome (ok, client_error, rate_limited, server_error, network_error).",
+	}, []string{"provider", "outcome"})
+
+	externalAPIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "external_api_request_duration_seconds",
+		Help:    "Outbound API call latency, by provider.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider"})
+)
+
+// initializeMetrics serves /metrics and counts outbound calls made through the default
+// transport, which the OpenAI client uses
+func initializeMetrics() {
+	http.DefaultTransport = instrumentedTransport{next: http.DefaultTransport}
+	http.Handle("/metrics", requireMetricsToken(promhttp.Handler()))
+}
+
+// requireMetricsToken guards the metrics endpoint with server.metrics_token as a bearer token
+// when it is set; without it /metrics is open, for scrapers on a private network
+func requireMetricsToken(next http.Handler) http.Handler {
+	token := appConfig.Server.MetricsToken
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status code a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// instrumentRoutes records every request against the mux pattern that serves it, so
+// paths with IDs in them share one label
+func instrumentRoutes(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		recorder := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		mux.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// apiProviders maps API hosts to provider labels; anything else is "other"
+var apiProviders = map[string]string{
+	"api.openai.com":                    "openai",
+	"api.anthropic.com":                 "anthropic",
+	"api.perplexity.ai":                 "perplexity",
+	"generativelanguage.googleapis.com": "gemini",
+	"maps.googleapis.com":               "google_places",
+	"places.googleapis.com":             "google_places",
+	"api.giphy.com":                     "giphy",
+	"api.pexels.com":                    "pexels",
+	"api.spotify.com":                   "spotify",
+	"accounts.spotify.com":              "spotify",
+}
+
+func apiProvider(host string) string {
+	if provider, ok := apiProviders[host]; ok {
+		return provider
+	}
+	if strings.HasSuffix(host, "-aiplatform.googleapis.com") {
+		return "gemini"
+	}
+	return "other"
+}
+
+// instrumentedTransport counts outbound calls by provider and outcome
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider := apiProvider(req.URL.Hostname())
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	externalAPIRequestDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+
+	outcome := "ok"
+	switch {
+	case err != nil:
+		outcome = "network_error"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		outcome = "rate_limited"
+	case resp.StatusCode >= 500:
+		outcome = "server_error"
+	case resp.StatusCode >= 400:
+		outcome = "client_error"
+	}
+	externalAPIRequestsTotal.WithLabelValues(provider, outcome).Inc()
+	return resp, err
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"sync"
+	"strings"
 	"time"
 
-	"github.com/justin4957/ec2-test-apps/solid-poc/internal/solid"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/shacl"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid/dynamodbstore"
 )
 
 // loggingMiddleware wraps an http.Handler and logs all requests
@@ -65,7 +70,7 @@ type SolidProvider struct {
 	LogoURL     string `json:"logo_url,omitempty"`
 }
 
-// SolidSession represents a user's Solid session
+// SolidSession is the session as the frontend sees it
 type SolidSession struct {
 	SessionID string    `json:"session_id"`
 	WebID     string    `json:"webid"`
@@ -76,11 +81,11 @@ type SolidSession struct {
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
-// In-memory session storage (replace with database in production)
-var (
-	solidSessions = make(map[string]*SolidSession)
-	sessionsMutex sync.RWMutex
-)
+// solidSessions keeps sessions in the store picked by SOLID_SESSION_STORE
+var solidSessions *solid.SessionManager
+
+// tokenVerifier checks ID tokens against the JWKS of the providers below
+var tokenVerifier *solid.Verifier
 
 // Common Solid identity providers
 var solidProviders = []SolidProvider{
@@ -105,6 +110,10 @@ var solidProviders = []SolidProvider{
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-rdf" {
+		os.Exit(runValidateRDFCommand(os.Args[2:]))
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "9090"
@@ -125,6 +134,25 @@ func main() {
 	http.HandleFunc("/api/solid/logout", loggingHandler(handleSolidLogout))
 	http.HandleFunc("/api/solid/webid/profile", loggingHandler(handleWebIDProfile))
 
+	// ID tokens must come from a listed provider and name one of our client IDs (or "solid")
+	issuers := make([]string, 0, len(solidProviders))
+	for _, provider := range solidProviders {
+		issuers = append(issuers, provider.IssuerURL)
+	}
+	audiences := []string{"solid"}
+	for _, clientID := range strings.Split(os.Getenv("SOLID_CLIENT_IDS"), ",") {
+		if clientID = strings.TrimSpace(clientID); clientID != "" {
+			audiences = append(audiences, clientID)
+		}
+	}
+	tokenVerifier = solid.NewVerifier(issuers, audiences, nil)
+
+	store, err := openSessionStore()
+	if err != nil {
+		log.Fatalf("❌ Failed to open session store: %v", err)
+	}
+	solidSessions = solid.NewSessionManager(store, tokenVerifier.Keys.Metadata)
+
 	// Start background session cleanup
 	go cleanupExpiredSessions()
 
@@ -210,7 +238,12 @@ func serializeHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		log.Printf("❌ RDF serialize failed: %v", err)
-		http.Error(w, fmt.Sprintf("Serialization failed: %v", err), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		var invalid *shacl.ValidationError
+		if errors.As(err, &invalid) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("Serialization failed: %v", err), status)
 		return
 	}
 
@@ -300,6 +333,7 @@ func createSolidSession(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		IDToken  string `json:"id_token"`
 		Provider string `json:"provider"`
+		Nonce    string `json:"nonce,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -307,8 +341,8 @@ func createSolidSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify ID token and extract WebID
-	webid, name, photo, err := verifyIDTokenAndExtractWebID(req.IDToken, req.Provider)
+	// Verify ID token (and its DPoP proof, if bound) and extract WebID
+	webid, name, photo, err := verifyIDTokenAndExtractWebID(r, req.IDToken, req.Provider, req.Nonce)
 	if err != nil {
 		log.Printf("Failed to verify ID token: %v", err)
 		http.Error(w, fmt.Sprintf("Invalid ID token: %v", err), http.StatusUnauthorized)
@@ -316,33 +350,45 @@ func createSolidSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate session ID
-	sessionID, err := generateSessionID()
+	sessionID, err := solid.RandomToken(32)
 	if err != nil {
 		http.Error(w, "Failed to generate session", http.StatusInternalServerError)
 		return
 	}
 
-	// Create session
-	session := &SolidSession{
-		SessionID: sessionID,
-		WebID:     webid,
-		Name:      name,
-		Photo:     photo,
-		Provider:  req.Provider,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour), // 24 hour session
+	// Create session. The browser holds the tokens, so the session simply lasts 24 hours
+	session := &solid.Session{
+		ID:         sessionID,
+		WebID:      webid,
+		Name:       name,
+		Photo:      photo,
+		Attributes: map[string]string{"provider": req.Provider},
+		ExpiresAt:  time.Now().Add(24 * time.Hour),
+	}
+	if err := solidSessions.Create(r.Context(), session); err != nil {
+		log.Printf("Failed to store session: %v", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
 	}
-
-	// Store session
-	sessionsMutex.Lock()
-	solidSessions[sessionID] = session
-	sessionsMutex.Unlock()
 
 	log.Printf("Created Solid session for WebID: %s", webid)
 
 	// Return session info
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session)
+	json.NewEncoder(w).Encode(sessionResponse(session))
+}
+
+// sessionResponse is the frontend view of a session
+func sessionResponse(session *solid.Session) *SolidSession {
+	return &SolidSession{
+		SessionID: session.ID,
+		WebID:     session.WebID,
+		Name:      session.Name,
+		Photo:     session.Photo,
+		Provider:  session.Attributes["provider"],
+		CreatedAt: session.CreatedAt,
+		ExpiresAt: solidSessions.Expiry(session),
+	}
 }
 
 // getSolidSessionStatus checks if a session is valid
@@ -353,31 +399,25 @@ func getSolidSessionStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionsMutex.RLock()
-	session, exists := solidSessions[sessionID]
-	sessionsMutex.RUnlock()
-
-	if !exists {
+	// Expired and idle sessions are removed on lookup
+	session, err := solidSessions.Get(r.Context(), sessionID)
+	if errors.Is(err, solid.ErrSessionNotFound) {
 		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
-
-	// Check if session expired
-	if time.Now().After(session.ExpiresAt) {
-		sessionsMutex.Lock()
-		delete(solidSessions, sessionID)
-		sessionsMutex.Unlock()
-
-		http.Error(w, "Session expired", http.StatusUnauthorized)
+	if err != nil {
+		log.Printf("Failed to load session: %v", err)
+		http.Error(w, "Failed to load session", http.StatusInternalServerError)
 		return
 	}
 
 	// Return session info
+	response := sessionResponse(session)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"valid":      true,
-		"session":    session,
-		"expires_in": int(time.Until(session.ExpiresAt).Seconds()),
+		"session":    response,
+		"expires_in": int(time.Until(response.ExpiresAt).Seconds()),
 	})
 }
 
@@ -397,14 +437,14 @@ func handleSolidLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Remove session
-	sessionsMutex.Lock()
-	session, exists := solidSessions[req.SessionID]
-	if exists {
-		delete(solidSessions, req.SessionID)
+	// Remove session (and revoke its tokens, if the server holds any)
+	session, err := solidSessions.Logout(r.Context(), req.SessionID)
+	if session != nil {
 		log.Printf("Logged out Solid session for WebID: %s", session.WebID)
 	}
-	sessionsMutex.Unlock()
+	if err != nil && !errors.Is(err, solid.ErrSessionNotFound) {
+		log.Printf("Logout of session incomplete: %v", err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -438,59 +478,40 @@ func handleWebIDProfile(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(profile)
 }
 
-// verifyIDTokenAndExtractWebID verifies the ID token and extracts WebID
-// NOTE: This is a simplified implementation. Production should:
-// 1. Fetch provider's OIDC discovery document
-// 2. Verify JWT signature using provider's public keys
-// 3. Validate token claims (iss, aud, exp, etc.)
-func verifyIDTokenAndExtractWebID(idToken, provider string) (webid, name, photo string, err error) {
-	// TODO: Implement proper JWT verification
-	// For now, this is a placeholder that trusts the frontend
-	// In production, you MUST verify the JWT signature!
-
-	log.Printf("⚠️  WARNING: ID token verification not yet implemented")
-	log.Printf("⚠️  Accepting token from provider: %s without verification", provider)
-
-	// Parse JWT payload (without verification - UNSAFE for production)
-	parts := splitJWT(idToken)
-	if len(parts) != 3 {
-		return "", "", "", fmt.Errorf("invalid JWT format")
+// verifyIDTokenAndExtractWebID verifies the ID token and extracts WebID.
+// The signature is checked against the provider's JWKS (RS256/ES256), then iss, aud,
+// exp and nonce. A DPoP-bound token (cnf.jkt) must come with a DPoP proof for this request.
+func verifyIDTokenAndExtractWebID(r *http.Request, idToken, provider, nonce string) (webid, name, photo string, err error) {
+	opts := solid.VerifyOptions{Nonce: nonce}
+	for _, known := range solidProviders {
+		if provider != "" && (provider == known.ID || provider == known.IssuerURL) {
+			opts.Issuer = known.IssuerURL
+		}
 	}
 
-	// Decode payload
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	claims, err := tokenVerifier.VerifyBoundToken(r.Context(), idToken, r.Header.Get("DPoP"), r.Method, requestURL(r), opts)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to decode JWT payload: %w", err)
+		return "", "", "", err
 	}
 
-	// Parse claims
-	var claims map[string]interface{}
-	if err := json.Unmarshal(payload, &claims); err != nil {
-		return "", "", "", fmt.Errorf("failed to parse JWT claims: %w", err)
-	}
+	return claims.WebIDOrSubject(), claims.Name, claims.Picture, nil
+}
 
-	// Extract WebID
-	webidClaim, ok := claims["webid"]
-	if !ok {
-		// Try alternative claim names
-		if sub, ok := claims["sub"].(string); ok {
-			webid = sub
-		} else {
-			return "", "", "", fmt.Errorf("no webid claim found in token")
-		}
-	} else {
-		webid = webidClaim.(string)
+// requestURL reconstructs the absolute URL the client called, which DPoP proofs sign as htu
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
 	}
-
-	// Extract optional profile info
-	if nameClaim, ok := claims["name"].(string); ok {
-		name = nameClaim
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
 	}
-	if photoClaim, ok := claims["picture"].(string); ok {
-		photo = photoClaim
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
 	}
-
-	return webid, name, photo, nil
+	// A proxy serving this app under a prefix (e.g. /solid) strips it before we see the path
+	return scheme + "://" + host + r.Header.Get("X-Forwarded-Prefix") + r.URL.Path
 }
 
 // fetchPublicWebIDProfile fetches public profile information from a WebID
@@ -518,41 +539,68 @@ func fetchPublicWebIDProfile(webid string) (map[string]interface{}, error) {
 
 // Helper functions
 
-func generateSessionID() (string, error) {
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+// openSessionStore picks the session store from SOLID_SESSION_STORE: "memory" (default),
+// "file", which keeps sessions in SOLID_SESSION_DIR, or "dynamodb", which keeps them in
+// the SOLID_SESSION_TABLE table. Both persistent stores encrypt with SOLID_SESSION_KEY.
+func openSessionStore() (solid.SessionStore, error) {
+	kind := os.Getenv("SOLID_SESSION_STORE")
+	if kind == "" || kind == "memory" {
+		log.Printf("🔐 Sessions kept in memory (set SOLID_SESSION_STORE=file or dynamodb to keep them across restarts)")
+		return solid.NewMemorySessionStore(), nil
+	}
+	if kind != "file" && kind != "dynamodb" {
+		return nil, fmt.Errorf("unknown SOLID_SESSION_STORE %q (use memory, file or dynamodb)", kind)
 	}
-	return base64.URLEncoding.EncodeToString(b), nil
-}
 
-func splitJWT(token string) []string {
-	parts := make([]string, 0, 3)
-	start := 0
-	for i := 0; i < len(token); i++ {
-		if token[i] == '.' {
-			parts = append(parts, token[start:i])
-			start = i + 1
+	key, err := solid.ParseSessionKey(os.Getenv("SOLID_SESSION_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("SOLID_SESSION_KEY: %w", err)
+	}
+	sessionCipher, err := solid.NewSessionCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case "dynamodb":
+		cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		table := os.Getenv("SOLID_SESSION_TABLE")
+		if table == "" {
+			table = "solid-poc-sessions"
 		}
+		log.Printf("🔐 Sessions kept encrypted in DynamoDB table %s", table)
+		return dynamodbstore.New(dynamodb.NewFromConfig(cfg), table, sessionCipher, solid.DefaultMaxLifetime), nil
+	default:
+		dir := os.Getenv("SOLID_SESSION_DIR")
+		if dir == "" {
+			dir = "./sessions"
+		}
+		log.Printf("🔐 Sessions kept encrypted in %s", dir)
+		return solid.NewFileSessionStore(dir, sessionCipher)
 	}
-	parts = append(parts, token[start:])
-	return parts
 }
 
-// cleanupExpiredSessions runs periodically to remove expired sessions
+// sessionSweepInterval must stay below the session manager's RefreshBefore, or access
+// tokens can expire between sweeps before they are refreshed
+const sessionSweepInterval = time.Minute
+
+// cleanupExpiredSessions runs periodically to remove expired and idle sessions and
+// refresh access tokens that are about to expire
 func cleanupExpiredSessions() {
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(sessionSweepInterval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		sessionsMutex.Lock()
-		now := time.Now()
-		for sessionID, session := range solidSessions {
-			if now.After(session.ExpiresAt) {
-				delete(solidSessions, sessionID)
-				log.Printf("Cleaned up expired session for WebID: %s", session.WebID)
-			}
+		result, err := solidSessions.Sweep(context.Background())
+		if err != nil {
+			log.Printf("Session cleanup failed: %v", err)
+			continue
+		}
+		if result.Removed > 0 {
+			log.Printf("Cleaned up %d expired sessions", result.Removed)
 		}
-		sessionsMutex.Unlock()
 	}
 }
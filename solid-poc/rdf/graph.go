@@ -0,0 +1,309 @@
+package rdf
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Triple is one RDF statement.
+type Triple struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+}
+
+// String renders the triple as an N-Triples line (without newline).
+func (t Triple) String() string {
+	return t.Subject.String() + " " + t.Predicate.String() + " " + t.Object.String() + " ."
+}
+
+// Graph is an in-memory set of triples. Triples keep insertion order, so
+// multi-valued properties written and read back come out in the same order.
+type Graph struct {
+	// Prefixes maps prefix names to namespaces, used by the writers and filled by the parsers
+	Prefixes map[string]string
+	// Base is the document IRI; writers emit IRIs under it as relative references
+	Base string
+
+	triples   []Triple
+	index     map[Triple]int
+	bySubject map[Term][]int
+	byObject  map[Term][]int
+	blankSeq  int
+}
+
+// NewGraph returns an empty graph.
+func NewGraph() *Graph {
+	return &Graph{
+		Prefixes:  make(map[string]string),
+		index:     make(map[Triple]int),
+		bySubject: make(map[Term][]int),
+		byObject:  make(map[Term][]int),
+	}
+}
+
+// Len returns the number of triples.
+func (g *Graph) Len() int {
+	return len(g.triples)
+}
+
+// Add inserts a triple, returning false if it was already present.
+func (g *Graph) Add(s, p, o Term) bool {
+	t := Triple{s, p, o}
+	if _, ok := g.index[t]; ok {
+		return false
+	}
+	g.index[t] = len(g.triples)
+	g.bySubject[s] = append(g.bySubject[s], len(g.triples))
+	g.byObject[o] = append(g.byObject[o], len(g.triples))
+	g.triples = append(g.triples, t)
+	return true
+}
+
+// AddTriple inserts t.
+func (g *Graph) AddTriple(t Triple) bool {
+	return g.Add(t.Subject, t.Predicate, t.Object)
+}
+
+// Has reports whether the graph contains the triple.
+func (g *Graph) Has(s, p, o Term) bool {
+	_, ok := g.index[Triple{s, p, o}]
+	return ok
+}
+
+// Remove deletes every triple matching the pattern (zero terms are wildcards) and returns how many.
+func (g *Graph) Remove(s, p, o Term) int {
+	matches := g.Match(s, p, o)
+	if len(matches) == 0 {
+		return 0
+	}
+	drop := make(map[Triple]bool, len(matches))
+	for _, t := range matches {
+		drop[t] = true
+	}
+
+	kept := make([]Triple, 0, len(g.triples)-len(matches))
+	for _, t := range g.triples {
+		if !drop[t] {
+			kept = append(kept, t)
+		}
+	}
+	g.reindex(kept)
+	return len(matches)
+}
+
+// reindex rebuilds the indexes over a new triple slice
+func (g *Graph) reindex(triples []Triple) {
+	g.triples = triples
+	g.index = make(map[Triple]int, len(triples))
+	g.bySubject = make(map[Term][]int)
+	g.byObject = make(map[Term][]int)
+	for i, t := range triples {
+		g.index[t] = i
+		g.bySubject[t.Subject] = append(g.bySubject[t.Subject], i)
+		g.byObject[t.Object] = append(g.byObject[t.Object], i)
+	}
+}
+
+// Match returns the triples matching a pattern, in insertion order. Zero terms match anything.
+func (g *Graph) Match(s, p, o Term) []Triple {
+	if !s.IsZero() && !p.IsZero() && !o.IsZero() {
+		if g.Has(s, p, o) {
+			return []Triple{{s, p, o}}
+		}
+		return nil
+	}
+
+	var candidates []int
+	switch {
+	case !s.IsZero():
+		candidates = g.bySubject[s]
+	case !o.IsZero():
+		candidates = g.byObject[o]
+	default:
+		var result []Triple
+		for _, t := range g.triples {
+			if p.IsZero() || t.Predicate == p {
+				result = append(result, t)
+			}
+		}
+		return result
+	}
+
+	var result []Triple
+	for _, i := range candidates {
+		t := g.triples[i]
+		if (p.IsZero() || t.Predicate == p) && (o.IsZero() || t.Object == o) && (s.IsZero() || t.Subject == s) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Triples returns all triples in insertion order.
+func (g *Graph) Triples() []Triple {
+	return append([]Triple(nil), g.triples...)
+}
+
+// Objects returns the objects of s p ?o.
+func (g *Graph) Objects(s, p Term) []Term {
+	var objects []Term
+	for _, t := range g.Match(s, p, Term{}) {
+		objects = append(objects, t.Object)
+	}
+	return objects
+}
+
+// Object returns the first object of s p ?o.
+func (g *Graph) Object(s, p Term) (Term, bool) {
+	for _, i := range g.bySubject[s] {
+		if t := g.triples[i]; t.Predicate == p {
+			return t.Object, true
+		}
+	}
+	return Term{}, false
+}
+
+// Subjects returns the distinct subjects of ?s p o, in insertion order.
+func (g *Graph) Subjects(p, o Term) []Term {
+	seen := make(map[Term]bool)
+	var subjects []Term
+	for _, t := range g.Match(Term{}, p, o) {
+		if !seen[t.Subject] {
+			seen[t.Subject] = true
+			subjects = append(subjects, t.Subject)
+		}
+	}
+	return subjects
+}
+
+// SubjectsOfType returns the subjects with rdf:type class.
+func (g *Graph) SubjectsOfType(class string) []Term {
+	return g.Subjects(IRI(RDFType), IRI(class))
+}
+
+// HasType reports whether s has rdf:type class.
+func (g *Graph) HasType(s Term, class string) bool {
+	return g.Has(s, IRI(RDFType), IRI(class))
+}
+
+// AllSubjects returns the distinct subjects in insertion order.
+func (g *Graph) AllSubjects() []Term {
+	seen := make(map[Term]bool)
+	var subjects []Term
+	for _, t := range g.triples {
+		if !seen[t.Subject] {
+			seen[t.Subject] = true
+			subjects = append(subjects, t.Subject)
+		}
+	}
+	return subjects
+}
+
+// NewBlank returns a blank node label unique within this graph.
+func (g *Graph) NewBlank() Term {
+	for {
+		g.blankSeq++
+		b := Blank("b" + strconv.Itoa(g.blankSeq))
+		if len(g.bySubject[b]) == 0 && len(g.byObject[b]) == 0 {
+			return b
+		}
+	}
+}
+
+// Merge adds every triple of other, relabelling its blank nodes so they stay distinct.
+func (g *Graph) Merge(other *Graph) {
+	relabel := make(map[Term]Term)
+	mapBlank := func(t Term) Term {
+		if !t.IsBlank() {
+			return t
+		}
+		if mapped, ok := relabel[t]; ok {
+			return mapped
+		}
+		mapped := g.NewBlank()
+		relabel[t] = mapped
+		return mapped
+	}
+	for _, t := range other.triples {
+		g.Add(mapBlank(t.Subject), t.Predicate, mapBlank(t.Object))
+	}
+	for prefix, ns := range other.Prefixes {
+		if _, ok := g.Prefixes[prefix]; !ok {
+			g.Prefixes[prefix] = ns
+		}
+	}
+}
+
+// Resolve returns a copy of g with relative IRIs resolved against base, so that
+// documents using fragment identifiers (<#location>) can be merged into one graph.
+func (g *Graph) Resolve(base string) (*Graph, error) {
+	resolved := NewGraph()
+	resolved.Base = base
+	for prefix, ns := range g.Prefixes {
+		resolved.Prefixes[prefix] = ns
+	}
+	resolve := func(t Term) (Term, error) {
+		if !t.IsIRI() {
+			return t, nil
+		}
+		iri, err := ResolveIRI(base, t.Value)
+		return IRI(iri), err
+	}
+	for _, t := range g.triples {
+		s, err := resolve(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+		o, err := resolve(t.Object)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Add(s, t.Predicate, o)
+	}
+	return resolved, nil
+}
+
+// SetPrefix declares a prefix for writing.
+func (g *Graph) SetPrefix(prefix, namespace string) {
+	g.Prefixes[prefix] = namespace
+}
+
+// UseCommonPrefixes declares the given prefixes from CommonPrefixes.
+func (g *Graph) UseCommonPrefixes(prefixes ...string) {
+	for _, prefix := range prefixes {
+		if ns, ok := CommonPrefixes[prefix]; ok {
+			g.Prefixes[prefix] = ns
+		}
+	}
+}
+
+// List returns the members of an RDF collection starting at head.
+func (g *Graph) List(head Term) []Term {
+	var members []Term
+	seen := make(map[Term]bool)
+	for head != IRI(RDFNil) && !head.IsZero() && !seen[head] {
+		seen[head] = true
+		first, ok := g.Object(head, IRI(RDFFirst))
+		if !ok {
+			break
+		}
+		members = append(members, first)
+		head, _ = g.Object(head, IRI(RDFRest))
+	}
+	return members
+}
+
+// NTriples renders the graph as sorted N-Triples, handy for comparing graphs in tests.
+func (g *Graph) NTriples() string {
+	lines := make([]string, len(g.triples))
+	for i, t := range g.triples {
+		lines[i] = t.String()
+	}
+	sort.Strings(lines)
+	out := ""
+	for _, line := range lines {
+		out += line + "\n"
+	}
+	return out
+}
@@ -0,0 +1,644 @@
+package rdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseJSONLD reads a JSON-LD document into a graph. It supports the subset
+// our pods use: inline @context objects (@vocab, @base, prefixes and term
+// definitions with @id/@type/@container), @id, @type, @value, @language,
+// @list, @graph, nested node objects and native JSON values. Remote contexts
+// are rejected rather than fetched.
+func ParseJSONLD(data []byte, base string) (*Graph, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("jsonld: invalid JSON: %w", err)
+	}
+
+	r := &jsonldReader{graph: NewGraph(), blanks: make(map[string]Term)}
+	r.graph.Base = base
+	ctx := &jsonldContext{base: base, terms: make(map[string]jsonldTerm)}
+	if err := r.topLevel(doc, ctx); err != nil {
+		return nil, err
+	}
+	return r.graph, nil
+}
+
+// ReadJSONLD reads a JSON-LD document from r.
+func ReadJSONLD(r io.Reader, base string) (*Graph, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON-LD: %w", err)
+	}
+	return ParseJSONLD(data, base)
+}
+
+// jsonldTerm is one term definition from an active context
+type jsonldTerm struct {
+	iri       string
+	typ       string // "@id", "@vocab" or a datatype IRI
+	container string
+}
+
+type jsonldContext struct {
+	vocab    string
+	base     string
+	language string
+	terms    map[string]jsonldTerm
+}
+
+func (c *jsonldContext) clone() *jsonldContext {
+	copied := *c
+	copied.terms = make(map[string]jsonldTerm, len(c.terms))
+	for k, v := range c.terms {
+		copied.terms[k] = v
+	}
+	return &copied
+}
+
+type jsonldReader struct {
+	graph  *Graph
+	blanks map[string]Term
+}
+
+// topLevel handles a document that is a node, an array of nodes, or a @graph wrapper
+func (r *jsonldReader) topLevel(doc interface{}, ctx *jsonldContext) error {
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := r.topLevel(item, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok && isGraphWrapper(v) {
+			active := ctx
+			if raw, ok := v["@context"]; ok {
+				var err error
+				if active, err = r.processContext(ctx, raw); err != nil {
+					return err
+				}
+			}
+			return r.topLevel(graph, active)
+		}
+		_, err := r.node(v, ctx)
+		return err
+	default:
+		return fmt.Errorf("jsonld: top-level value must be an object or array")
+	}
+}
+
+// isGraphWrapper reports whether an object only carries @context/@graph (and maybe @id)
+func isGraphWrapper(obj map[string]interface{}) bool {
+	for key := range obj {
+		if key != "@context" && key != "@graph" && key != "@id" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *jsonldReader) processContext(active *jsonldContext, raw interface{}) (*jsonldContext, error) {
+	switch v := raw.(type) {
+	case nil:
+		return &jsonldContext{base: active.base, terms: make(map[string]jsonldTerm)}, nil
+	case string:
+		return nil, fmt.Errorf("jsonld: remote context %q is not supported", v)
+	case []interface{}:
+		ctx := active
+		for _, item := range v {
+			var err error
+			if ctx, err = r.processContext(ctx, item); err != nil {
+				return nil, err
+			}
+		}
+		return ctx, nil
+	case map[string]interface{}:
+		ctx := active.clone()
+		if vocab, ok := v["@vocab"]; ok {
+			s, _ := vocab.(string)
+			ctx.vocab = s
+		}
+		if base, ok := v["@base"]; ok {
+			s, _ := base.(string)
+			resolved, err := ResolveIRI(active.base, s)
+			if err != nil {
+				return nil, fmt.Errorf("jsonld: %w", err)
+			}
+			ctx.base = resolved
+		}
+		if language, ok := v["@language"]; ok {
+			s, _ := language.(string)
+			ctx.language = strings.ToLower(s)
+		}
+
+		// definitions may refer to each other's prefixes, so resolve them in a stable order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if !strings.HasPrefix(key, "@") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for pass := 0; pass < 2; pass++ {
+			for _, key := range keys {
+				if err := r.defineTerm(ctx, key, v[key]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return ctx, nil
+	default:
+		return nil, fmt.Errorf("jsonld: invalid @context")
+	}
+}
+
+func (r *jsonldReader) defineTerm(ctx *jsonldContext, term string, raw interface{}) error {
+	switch def := raw.(type) {
+	case nil:
+		delete(ctx.terms, term)
+	case string:
+		iri := r.expandDefinition(ctx, term, def)
+		ctx.terms[term] = jsonldTerm{iri: iri}
+		// namespace-style definitions double as prefixes when the graph is written back out
+		if strings.HasSuffix(iri, "/") || strings.HasSuffix(iri, "#") {
+			if _, ok := r.graph.Prefixes[term]; !ok {
+				r.graph.Prefixes[term] = iri
+			}
+		}
+	case map[string]interface{}:
+		t := jsonldTerm{}
+		if id, ok := def["@id"].(string); ok {
+			t.iri = r.expandDefinition(ctx, term, id)
+		} else {
+			t.iri = r.expandDefinition(ctx, term, term)
+		}
+		if typ, ok := def["@type"].(string); ok {
+			if typ == "@id" || typ == "@vocab" {
+				t.typ = typ
+			} else {
+				t.typ = r.expandIRI(ctx, typ, true, false)
+			}
+		}
+		if container, ok := def["@container"].(string); ok {
+			t.container = container
+		}
+		ctx.terms[term] = t
+	default:
+		return fmt.Errorf("jsonld: invalid definition for term %q", term)
+	}
+	return nil
+}
+
+// expandDefinition expands the IRI of a term definition without letting it refer to itself
+func (r *jsonldReader) expandDefinition(ctx *jsonldContext, term, value string) string {
+	if prefix, suffix, ok := strings.Cut(value, ":"); ok && prefix != term {
+		if def, defined := ctx.terms[prefix]; defined && !strings.HasPrefix(suffix, "//") {
+			return def.iri + suffix
+		}
+		return value
+	}
+	if ctx.vocab != "" && !strings.HasPrefix(value, "@") {
+		return ctx.vocab + value
+	}
+	return value
+}
+
+// expandIRI expands a term, compact IRI, or relative reference. vocab selects
+// vocabulary-relative expansion (properties, types); documentRelative resolves against @base.
+func (r *jsonldReader) expandIRI(ctx *jsonldContext, value string, vocab, documentRelative bool) string {
+	if strings.HasPrefix(value, "@") || strings.HasPrefix(value, "_:") {
+		return value
+	}
+	if vocab {
+		if def, ok := ctx.terms[value]; ok {
+			return def.iri
+		}
+	}
+	if prefix, suffix, ok := strings.Cut(value, ":"); ok {
+		if strings.HasPrefix(suffix, "//") {
+			return value
+		}
+		if def, defined := ctx.terms[prefix]; defined {
+			return def.iri + suffix
+		}
+		if hasScheme(value) {
+			return value
+		}
+	}
+	if vocab && ctx.vocab != "" {
+		return ctx.vocab + value
+	}
+	if documentRelative {
+		if resolved, err := ResolveIRI(ctx.base, value); err == nil {
+			return resolved
+		}
+	}
+	return value
+}
+
+// subjectTerm turns an @id into an IRI or blank node
+func (r *jsonldReader) subjectTerm(ctx *jsonldContext, id string) Term {
+	if strings.HasPrefix(id, "_:") {
+		if node, ok := r.blanks[id]; ok {
+			return node
+		}
+		node := r.graph.NewBlank()
+		r.blanks[id] = node
+		return node
+	}
+	return IRI(r.expandIRI(ctx, id, false, true))
+}
+
+// node adds the triples of a node object and returns its subject
+func (r *jsonldReader) node(obj map[string]interface{}, ctx *jsonldContext) (Term, error) {
+	if raw, ok := obj["@context"]; ok {
+		var err error
+		if ctx, err = r.processContext(ctx, raw); err != nil {
+			return Term{}, err
+		}
+	}
+
+	var subject Term
+	if id, ok := obj["@id"].(string); ok {
+		subject = r.subjectTerm(ctx, id)
+	} else {
+		subject = r.graph.NewBlank()
+	}
+
+	switch types := obj["@type"].(type) {
+	case string:
+		r.graph.Add(subject, IRI(RDFType), r.typeTerm(ctx, types))
+	case []interface{}:
+		for _, t := range types {
+			if s, ok := t.(string); ok {
+				r.graph.Add(subject, IRI(RDFType), r.typeTerm(ctx, s))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "@") {
+			if key == "@graph" {
+				if err := r.topLevel(obj[key], ctx); err != nil {
+					return Term{}, err
+				}
+			}
+			continue
+		}
+		predicate := r.expandIRI(ctx, key, true, false)
+		if !hasScheme(predicate) || strings.HasPrefix(predicate, "_:") {
+			// terms that do not expand to an absolute IRI are dropped, as JSON-LD specifies
+			continue
+		}
+		def := ctx.terms[key]
+		objects, err := r.values(obj[key], def, ctx)
+		if err != nil {
+			return Term{}, fmt.Errorf("jsonld: property %q: %w", key, err)
+		}
+		if def.container == "@list" {
+			objects = []Term{r.list(objects)}
+		}
+		for _, object := range objects {
+			r.graph.Add(subject, IRI(predicate), object)
+		}
+	}
+	return subject, nil
+}
+
+func (r *jsonldReader) typeTerm(ctx *jsonldContext, value string) Term {
+	if strings.HasPrefix(value, "_:") {
+		return r.subjectTerm(ctx, value)
+	}
+	return IRI(r.expandIRI(ctx, value, true, true))
+}
+
+// values converts a property value (scalar, object or array) into terms
+func (r *jsonldReader) values(raw interface{}, def jsonldTerm, ctx *jsonldContext) ([]Term, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var terms []Term
+		for _, item := range v {
+			itemTerms, err := r.values(item, def, ctx)
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, itemTerms...)
+		}
+		return terms, nil
+	case string:
+		switch def.typ {
+		case "@id":
+			return []Term{r.subjectTerm(ctx, v)}, nil
+		case "@vocab":
+			return []Term{IRI(r.expandIRI(ctx, v, true, true))}, nil
+		case "":
+			if ctx.language != "" {
+				return []Term{LangLiteral(v, ctx.language)}, nil
+			}
+			return []Term{Literal(v)}, nil
+		default:
+			return []Term{TypedLiteral(v, def.typ)}, nil
+		}
+	case json.Number:
+		if def.typ != "" && def.typ != "@id" && def.typ != "@vocab" {
+			return []Term{TypedLiteral(v.String(), def.typ)}, nil
+		}
+		if !strings.ContainsAny(v.String(), ".eE") {
+			return []Term{TypedLiteral(v.String(), XSDInteger)}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", v)
+		}
+		return []Term{Double(f)}, nil
+	case bool:
+		return []Term{Boolean(v)}, nil
+	case map[string]interface{}:
+		if value, ok := v["@value"]; ok {
+			term, err := r.valueObject(value, v, ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []Term{term}, nil
+		}
+		if items, ok := v["@list"]; ok {
+			members, err := r.values(items, jsonldTerm{typ: def.typ}, ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []Term{r.list(members)}, nil
+		}
+		if id, ok := v["@id"].(string); ok && len(v) == 1 {
+			return []Term{r.subjectTerm(ctx, id)}, nil
+		}
+		node, err := r.node(v, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []Term{node}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// valueObject converts {"@value": ..., "@type"/"@language": ...}
+func (r *jsonldReader) valueObject(value interface{}, obj map[string]interface{}, ctx *jsonldContext) (Term, error) {
+	var lexical string
+	switch v := value.(type) {
+	case string:
+		lexical = v
+	case json.Number:
+		lexical = v.String()
+	case bool:
+		lexical = strconv.FormatBool(v)
+	default:
+		return Term{}, fmt.Errorf("invalid @value")
+	}
+
+	if typ, ok := obj["@type"].(string); ok {
+		return TypedLiteral(lexical, r.expandIRI(ctx, typ, true, false)), nil
+	}
+	if language, ok := obj["@language"].(string); ok {
+		return LangLiteral(lexical, language), nil
+	}
+	switch v := value.(type) {
+	case json.Number:
+		terms, err := r.values(v, jsonldTerm{}, ctx)
+		if err != nil {
+			return Term{}, err
+		}
+		return terms[0], nil
+	case bool:
+		return Boolean(v), nil
+	}
+	return Literal(lexical), nil
+}
+
+// list builds an rdf:first/rdf:rest collection
+func (r *jsonldReader) list(members []Term) Term {
+	head := IRI(RDFNil)
+	for i := len(members) - 1; i >= 0; i-- {
+		cell := r.graph.NewBlank()
+		r.graph.Add(cell, IRI(RDFFirst), members[i])
+		r.graph.Add(cell, IRI(RDFRest), head)
+		head = cell
+	}
+	return head
+}
+
+// JSONLD serializes the graph as compacted JSON-LD. vocab becomes @vocab, and
+// the graph's prefixes are declared for the IRIs that need them. A graph with
+// one top-level node is written as a single object, otherwise as a @graph array.
+func (g *Graph) JSONLD(vocab string) ([]byte, error) {
+	w := &jsonldWriter{graph: g, vocab: vocab, used: make(map[string]bool), labels: make(map[Term]string)}
+	w.prepare()
+
+	var nodes []interface{}
+	for _, subject := range g.AllSubjects() {
+		if !w.inline[subject] {
+			nodes = append(nodes, w.node(subject, map[Term]bool{}))
+		}
+	}
+
+	context := map[string]interface{}{}
+	if vocab != "" {
+		context["@vocab"] = vocab
+	}
+	for prefix := range w.used {
+		context[prefix] = g.Prefixes[prefix]
+	}
+
+	var doc map[string]interface{}
+	if len(nodes) == 1 {
+		doc = nodes[0].(map[string]interface{})
+	} else {
+		doc = map[string]interface{}{"@graph": nodes}
+	}
+	if len(context) > 0 {
+		doc["@context"] = context
+	}
+
+	result, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON-LD: %w", err)
+	}
+	return result, nil
+}
+
+type jsonldWriter struct {
+	graph    *Graph
+	vocab    string
+	prefixes []string
+	inline   map[Term]bool
+	used     map[string]bool
+	labels   map[Term]string
+}
+
+func (w *jsonldWriter) prepare() {
+	references := make(map[Term]int)
+	for _, t := range w.graph.triples {
+		if t.Object.IsBlank() {
+			references[t.Object]++
+		}
+	}
+	w.inline = make(map[Term]bool)
+	for node, count := range references {
+		if count == 1 {
+			w.inline[node] = true
+		}
+	}
+	// same rule as the Turtle writer: a cycle needs an outer node to hang from
+	tw := &turtleWriter{graph: w.graph, inline: w.inline}
+	for node := range w.inline {
+		if tw.inlineCycle(node) {
+			delete(w.inline, node)
+		}
+	}
+
+	for prefix := range w.graph.Prefixes {
+		w.prefixes = append(w.prefixes, prefix)
+	}
+	sort.Slice(w.prefixes, func(i, j int) bool {
+		a, b := w.graph.Prefixes[w.prefixes[i]], w.graph.Prefixes[w.prefixes[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return w.prefixes[i] < w.prefixes[j]
+	})
+}
+
+// node builds the JSON object for subject, nesting single-use blank nodes
+func (w *jsonldWriter) node(subject Term, visiting map[Term]bool) map[string]interface{} {
+	visiting[subject] = true
+	defer delete(visiting, subject)
+
+	obj := map[string]interface{}{}
+	if subject.IsIRI() {
+		obj["@id"] = w.reference(subject.Value)
+	} else if !w.inline[subject] {
+		obj["@id"] = w.blankLabel(subject)
+	}
+
+	var types []interface{}
+	values := map[string][]interface{}{}
+	for _, i := range w.graph.bySubject[subject] {
+		t := w.graph.triples[i]
+		if t.Predicate.Value == RDFType && t.Object.IsIRI() {
+			types = append(types, w.compact(t.Object.Value, true))
+			continue
+		}
+		key := w.compact(t.Predicate.Value, true)
+		values[key] = append(values[key], w.value(t.Object, visiting))
+	}
+	if len(types) == 1 {
+		obj["@type"] = types[0]
+	} else if len(types) > 1 {
+		obj["@type"] = types
+	}
+	for key, vals := range values {
+		if len(vals) == 1 {
+			obj[key] = vals[0]
+		} else {
+			obj[key] = vals
+		}
+	}
+	return obj
+}
+
+func (w *jsonldWriter) value(object Term, visiting map[Term]bool) interface{} {
+	switch object.Kind {
+	case KindIRI:
+		return map[string]interface{}{"@id": w.reference(object.Value)}
+	case KindBlank:
+		if w.inline[object] && !visiting[object] {
+			return w.node(object, visiting)
+		}
+		return map[string]interface{}{"@id": w.blankLabel(object)}
+	}
+
+	if object.Language != "" {
+		return map[string]interface{}{"@value": object.Value, "@language": object.Language}
+	}
+	switch object.Datatype {
+	case XSDString, "":
+		return object.Value
+	case XSDBoolean:
+		if object.Value == "true" || object.Value == "false" {
+			return object.Value == "true"
+		}
+	case XSDInteger:
+		if integerLexical.MatchString(object.Value) {
+			return json.Number(strings.TrimPrefix(object.Value, "+"))
+		}
+	case XSDDouble:
+		// native JSON numbers with a fraction read back as xsd:double
+		if f, err := object.Float(); err == nil && doubleLexical.MatchString(object.Value) {
+			lexical := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(lexical, ".") {
+				lexical += ".0"
+			}
+			return json.Number(lexical)
+		}
+	}
+	return map[string]interface{}{"@value": object.Value, "@type": w.compact(object.Datatype, true)}
+}
+
+func (w *jsonldWriter) blankLabel(node Term) string {
+	label, ok := w.labels[node]
+	if !ok {
+		label = "_:n" + strconv.Itoa(len(w.labels)+1)
+		w.labels[node] = label
+	}
+	return label
+}
+
+// reference renders an @id, relative to the document when possible
+func (w *jsonldWriter) reference(iri string) string {
+	if base := w.graph.Base; base != "" && strings.HasPrefix(iri, base) {
+		rest := iri[len(base):]
+		if rest == "" || rest[0] == '#' {
+			return rest
+		}
+	}
+	return iri
+}
+
+// compact shortens a property or type IRI using @vocab or a prefix
+func (w *jsonldWriter) compact(iri string, vocabRelative bool) string {
+	if vocabRelative && w.vocab != "" && strings.HasPrefix(iri, w.vocab) {
+		local := iri[len(w.vocab):]
+		// a vocab term must not collide with a prefix name, or it would expand to the namespace
+		if _, isPrefix := w.graph.Prefixes[local]; localName.MatchString(local) && !isPrefix {
+			return local
+		}
+	}
+	for _, prefix := range w.prefixes {
+		namespace := w.graph.Prefixes[prefix]
+		if namespace == "" || !strings.HasPrefix(iri, namespace) {
+			continue
+		}
+		local := iri[len(namespace):]
+		if localName.MatchString(local) {
+			w.used[prefix] = true
+			return prefix + ":" + local
+		}
+	}
+	return iri
+}
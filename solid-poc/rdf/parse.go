@@ -0,0 +1,60 @@
+package rdf
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Media types understood by Parse
+const (
+	MediaTypeTurtle = "text/turtle"
+	MediaTypeJSONLD = "application/ld+json"
+)
+
+// Parse reads a document in the given media type (parameters are ignored).
+func Parse(data []byte, mediaType, base string) (*Graph, error) {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	switch strings.ToLower(mediaType) {
+	case MediaTypeTurtle, "application/x-turtle":
+		return ParseTurtle(data, base)
+	case MediaTypeJSONLD, "application/json":
+		return ParseJSONLD(data, base)
+	default:
+		return nil, fmt.Errorf("unsupported RDF media type %q", mediaType)
+	}
+}
+
+// MediaTypeForPath picks the media type from a file extension (.ttl, .jsonld, .json).
+func MediaTypeForPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ttl":
+		return MediaTypeTurtle, nil
+	case ".jsonld", ".json":
+		return MediaTypeJSONLD, nil
+	default:
+		return "", fmt.Errorf("unknown RDF file extension %q", filepath.Ext(path))
+	}
+}
+
+// ParseFile reads a Turtle or JSON-LD file, choosing the syntax by extension.
+// Relative IRIs stay relative unless base is given.
+func ParseFile(path, base string) (*Graph, error) {
+	mediaType, err := MediaTypeForPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	g, err := Parse(data, mediaType, base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return g, nil
+}
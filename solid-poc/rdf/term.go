@@ -0,0 +1,244 @@
+// Package rdf is a small RDF 1.1 toolkit: terms, an in-memory triple store,
+// and Turtle and JSON-LD readers and writers for the documents this project keeps in Solid pods.
+package rdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TermKind distinguishes IRIs, blank nodes and literals.
+type TermKind uint8
+
+const (
+	// KindNone is the zero Term, used as a wildcard in Match
+	KindNone TermKind = iota
+	KindIRI
+	KindBlank
+	KindLiteral
+)
+
+// Term is an RDF term. Terms are comparable, so they can be used as map keys.
+type Term struct {
+	Kind     TermKind
+	Value    string // IRI, blank node label, or literal lexical form
+	Datatype string // literal datatype IRI
+	Language string // literal language tag (lower case)
+}
+
+// IRI returns an IRI term.
+func IRI(iri string) Term {
+	return Term{Kind: KindIRI, Value: iri}
+}
+
+// Blank returns a blank node with the given label.
+func Blank(label string) Term {
+	return Term{Kind: KindBlank, Value: label}
+}
+
+// Literal returns an xsd:string literal.
+func Literal(value string) Term {
+	return Term{Kind: KindLiteral, Value: value, Datatype: XSDString}
+}
+
+// TypedLiteral returns a literal with a datatype.
+func TypedLiteral(value, datatype string) Term {
+	if datatype == "" {
+		datatype = XSDString
+	}
+	return Term{Kind: KindLiteral, Value: value, Datatype: datatype}
+}
+
+// LangLiteral returns a language-tagged string.
+func LangLiteral(value, language string) Term {
+	return Term{Kind: KindLiteral, Value: value, Datatype: RDFLangString, Language: strings.ToLower(language)}
+}
+
+// Integer returns an xsd:integer literal.
+func Integer(n int64) Term {
+	return TypedLiteral(strconv.FormatInt(n, 10), XSDInteger)
+}
+
+// Decimal returns an xsd:decimal literal with the shortest exact lexical form.
+func Decimal(f float64) Term {
+	lexical := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(lexical, ".") {
+		lexical += ".0"
+	}
+	return TypedLiteral(lexical, XSDDecimal)
+}
+
+// Double returns an xsd:double literal in canonical form.
+func Double(f float64) Term {
+	return TypedLiteral(canonicalDouble(f), XSDDouble)
+}
+
+// Boolean returns an xsd:boolean literal.
+func Boolean(b bool) Term {
+	return TypedLiteral(strconv.FormatBool(b), XSDBoolean)
+}
+
+// DateTime returns an xsd:dateTime literal, keeping sub-second precision.
+func DateTime(t time.Time) Term {
+	return TypedLiteral(t.UTC().Format(time.RFC3339Nano), XSDDateTime)
+}
+
+// Date returns an xsd:date literal.
+func Date(t time.Time) Term {
+	return TypedLiteral(t.Format("2006-01-02"), XSDDate)
+}
+
+// canonicalDouble formats a float as an xsd:double canonical lexical form (e.g. 3.77749E1)
+func canonicalDouble(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'E', -1, 64), "E")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	exp, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(exp)
+}
+
+// IsZero reports whether t is the zero (wildcard) term.
+func (t Term) IsZero() bool { return t.Kind == KindNone }
+
+// IsIRI reports whether t is an IRI.
+func (t Term) IsIRI() bool { return t.Kind == KindIRI }
+
+// IsBlank reports whether t is a blank node.
+func (t Term) IsBlank() bool { return t.Kind == KindBlank }
+
+// IsLiteral reports whether t is a literal.
+func (t Term) IsLiteral() bool { return t.Kind == KindLiteral }
+
+// Float parses a numeric literal.
+func (t Term) Float() (float64, error) {
+	if !t.IsLiteral() {
+		return 0, fmt.Errorf("%s is not a literal", t)
+	}
+	switch t.Value {
+	case "INF", "+INF":
+		return math.Inf(1), nil
+	case "-INF":
+		return math.Inf(-1), nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(t.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", t.Value)
+	}
+	return f, nil
+}
+
+// Int parses an integer literal.
+func (t Term) Int() (int64, error) {
+	if !t.IsLiteral() {
+		return 0, fmt.Errorf("%s is not a literal", t)
+	}
+	n, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(t.Value), "+"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", t.Value)
+	}
+	return n, nil
+}
+
+// Bool parses a boolean literal ("true", "false", "1", "0").
+func (t Term) Bool() (bool, error) {
+	switch strings.TrimSpace(t.Value) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", t.Value)
+}
+
+// Time parses an xsd:dateTime or xsd:date literal.
+func (t Term) Time() (time.Time, error) {
+	value := strings.TrimSpace(t.Value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02Z07:00", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date/time %q", t.Value)
+}
+
+// String renders the term in N-Triples syntax.
+func (t Term) String() string {
+	switch t.Kind {
+	case KindIRI:
+		return "<" + escapeIRI(t.Value) + ">"
+	case KindBlank:
+		return "_:" + t.Value
+	case KindLiteral:
+		quoted := quoteString(t.Value)
+		if t.Language != "" {
+			return quoted + "@" + t.Language
+		}
+		if t.Datatype != "" && t.Datatype != XSDString {
+			return quoted + "^^<" + escapeIRI(t.Datatype) + ">"
+		}
+		return quoted
+	default:
+		return "?"
+	}
+}
+
+// quoteString writes a Turtle/N-Triples short string with the required escapes
+func quoteString(value string) string {
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// escapeIRI escapes the characters that may not appear inside <...>
+func escapeIRI(iri string) string {
+	if !strings.ContainsAny(iri, "<>\"{}|^`\\ \t\n\r") {
+		return iri
+	}
+	var b strings.Builder
+	for _, r := range iri {
+		if r <= 0x20 || strings.ContainsRune("<>\"{}|^`\\", r) {
+			fmt.Fprintf(&b, `\u%04X`, r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
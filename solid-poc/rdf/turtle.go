@@ -0,0 +1,729 @@
+package rdf
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseTurtle parses a Turtle document. base is used to resolve relative IRIs
+// (usually the URL the document was fetched from) and may be empty.
+func ParseTurtle(data []byte, base string) (*Graph, error) {
+	p := &turtleParser{
+		src:     string(data),
+		line:    1,
+		graph:   NewGraph(),
+		blanks:  make(map[string]Term),
+		baseIRI: base,
+	}
+	p.graph.Base = base
+	if err := p.parseDocument(); err != nil {
+		return nil, err
+	}
+	return p.graph, nil
+}
+
+// ReadTurtle parses a Turtle document from r.
+func ReadTurtle(r io.Reader, base string) (*Graph, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read turtle: %w", err)
+	}
+	return ParseTurtle(data, base)
+}
+
+type turtleParser struct {
+	src     string
+	pos     int
+	line    int
+	graph   *Graph
+	blanks  map[string]Term
+	baseIRI string
+}
+
+// errorf reports a syntax error at the current line
+func (p *turtleParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("turtle: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *turtleParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// peek returns the next rune without consuming it
+func (p *turtleParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+// peekAt returns the rune n bytes ahead (ASCII lookahead only)
+func (p *turtleParser) peekAt(n int) byte {
+	if p.pos+n >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+n]
+}
+
+func (p *turtleParser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+// skipSpace skips whitespace and comments
+func (p *turtleParser) skipSpace() {
+	for !p.eof() {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.next()
+		case c == '#':
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *turtleParser) expect(c byte) error {
+	p.skipSpace()
+	if p.eof() || p.src[p.pos] != c {
+		return p.errorf("expected %q, found %s", c, p.describeNext())
+	}
+	p.pos++
+	return nil
+}
+
+// describeNext quotes the upcoming input for error messages
+func (p *turtleParser) describeNext() string {
+	if p.eof() {
+		return "end of input"
+	}
+	end := p.pos + 20
+	if end > len(p.src) {
+		end = len(p.src)
+	}
+	snippet := p.src[p.pos:end]
+	if i := strings.IndexByte(snippet, '\n'); i >= 0 {
+		snippet = snippet[:i]
+	}
+	return strconv.Quote(snippet)
+}
+
+// keyword reports whether the input continues with word (case-insensitive) followed by whitespace
+func (p *turtleParser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end >= len(p.src) || !strings.EqualFold(p.src[p.pos:end], word) {
+		return false
+	}
+	c := p.src[end]
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '<'
+}
+
+func (p *turtleParser) parseDocument() error {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil
+		}
+		if err := p.parseStatement(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *turtleParser) parseStatement() error {
+	switch {
+	case p.peek() == '@':
+		p.pos++
+		switch {
+		case p.keyword("prefix"):
+			p.pos += len("prefix")
+			if err := p.parsePrefixDirective(); err != nil {
+				return err
+			}
+		case p.keyword("base"):
+			p.pos += len("base")
+			if err := p.parseBaseDirective(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unknown directive %s", p.describeNext())
+		}
+		return p.expect('.')
+	case p.keyword("PREFIX"):
+		p.pos += len("PREFIX")
+		return p.parsePrefixDirective()
+	case p.keyword("BASE"):
+		p.pos += len("BASE")
+		return p.parseBaseDirective()
+	}
+
+	if err := p.parseTriples(); err != nil {
+		return err
+	}
+	return p.expect('.')
+}
+
+func (p *turtleParser) parsePrefixDirective() error {
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && p.src[p.pos] != ':' {
+		r := p.peek()
+		if !isPNChar(r) && r != '.' {
+			return p.errorf("invalid prefix name %s", p.describeNext())
+		}
+		p.next()
+	}
+	if p.eof() {
+		return p.errorf("unterminated prefix declaration")
+	}
+	prefix := p.src[start:p.pos]
+	p.pos++
+
+	p.skipSpace()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.graph.Prefixes[prefix] = iri
+	return nil
+}
+
+func (p *turtleParser) parseBaseDirective() error {
+	p.skipSpace()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.baseIRI = iri
+	return nil
+}
+
+func (p *turtleParser) parseTriples() error {
+	p.skipSpace()
+	if p.peek() == '[' {
+		subject, empty, err := p.parseBlankNodePropertyList()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+		// "[ ... ] ." is complete on its own; "[] p o" needs the predicate list
+		if !empty && p.peek() == '.' {
+			return nil
+		}
+		return p.parsePredicateObjectList(subject)
+	}
+
+	subject, err := p.parseSubject()
+	if err != nil {
+		return err
+	}
+	return p.parsePredicateObjectList(subject)
+}
+
+func (p *turtleParser) parseSubject() (Term, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '<':
+		iri, err := p.parseIRIRef()
+		return IRI(iri), err
+	case c == '_' && p.peekAt(1) == ':':
+		return p.parseBlankNodeLabel()
+	case c == '(':
+		return p.parseCollection()
+	default:
+		iri, err := p.parsePrefixedName()
+		return IRI(iri), err
+	}
+}
+
+func (p *turtleParser) parsePredicateObjectList(subject Term) error {
+	for {
+		p.skipSpace()
+		predicate, err := p.parseVerb()
+		if err != nil {
+			return err
+		}
+		if err := p.parseObjectList(subject, predicate); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		if p.peek() != ';' {
+			return nil
+		}
+		// one or more ';' may be followed by another predicate, or end the list
+		for p.peek() == ';' {
+			p.pos++
+			p.skipSpace()
+		}
+		if c := p.peek(); c == '.' || c == ']' || c == 0 {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) parseVerb() (Term, error) {
+	if p.peek() == 'a' {
+		c := p.peekAt(1)
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '<' || c == '[' || c == '"' || c == '(' || c == '_' && p.peekAt(2) == ':' {
+			p.pos++
+			return IRI(RDFType), nil
+		}
+	}
+	if p.peek() == '<' {
+		iri, err := p.parseIRIRef()
+		return IRI(iri), err
+	}
+	iri, err := p.parsePrefixedName()
+	return IRI(iri), err
+}
+
+func (p *turtleParser) parseObjectList(subject, predicate Term) error {
+	for {
+		object, err := p.parseObject()
+		if err != nil {
+			return err
+		}
+		p.graph.Add(subject, predicate, object)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+func (p *turtleParser) parseObject() (Term, error) {
+	p.skipSpace()
+	c := p.peek()
+	switch {
+	case c == '<':
+		iri, err := p.parseIRIRef()
+		return IRI(iri), err
+	case c == '_' && p.peekAt(1) == ':':
+		return p.parseBlankNodeLabel()
+	case c == '[':
+		node, _, err := p.parseBlankNodePropertyList()
+		return node, err
+	case c == '(':
+		return p.parseCollection()
+	case c == '"' || c == '\'':
+		return p.parseRDFLiteral()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	}
+
+	for _, word := range []string{"true", "false"} {
+		if strings.HasPrefix(p.src[p.pos:], word) {
+			end := p.pos + len(word)
+			if end >= len(p.src) {
+				p.pos = end
+				return Boolean(word == "true"), nil
+			}
+			if r, _ := utf8.DecodeRuneInString(p.src[end:]); !isPNChar(r) && r != ':' {
+				p.pos = end
+				return Boolean(word == "true"), nil
+			}
+		}
+	}
+
+	iri, err := p.parsePrefixedName()
+	return IRI(iri), err
+}
+
+// parseBlankNodePropertyList parses "[ predicateObjectList ]" and reports whether it was empty
+func (p *turtleParser) parseBlankNodePropertyList() (Term, bool, error) {
+	if err := p.expect('['); err != nil {
+		return Term{}, false, err
+	}
+	node := p.graph.NewBlank()
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return node, true, nil
+	}
+	if err := p.parsePredicateObjectList(node); err != nil {
+		return Term{}, false, err
+	}
+	if err := p.expect(']'); err != nil {
+		return Term{}, false, err
+	}
+	return node, false, nil
+}
+
+// parseCollection parses "( object* )" into an rdf:first/rdf:rest list
+func (p *turtleParser) parseCollection() (Term, error) {
+	if err := p.expect('('); err != nil {
+		return Term{}, err
+	}
+	var members []Term
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return Term{}, p.errorf("unterminated collection")
+		}
+		if p.peek() == ')' {
+			p.pos++
+			break
+		}
+		member, err := p.parseObject()
+		if err != nil {
+			return Term{}, err
+		}
+		members = append(members, member)
+	}
+
+	head := IRI(RDFNil)
+	for i := len(members) - 1; i >= 0; i-- {
+		cell := p.graph.NewBlank()
+		p.graph.Add(cell, IRI(RDFFirst), members[i])
+		p.graph.Add(cell, IRI(RDFRest), head)
+		head = cell
+	}
+	return head, nil
+}
+
+func (p *turtleParser) parseBlankNodeLabel() (Term, error) {
+	p.pos += 2 // "_:"
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if isPNChar(r) || r == '.' && p.pos+1 < len(p.src) && isPNChar(rune(p.src[p.pos+1])) {
+			p.next()
+			continue
+		}
+		break
+	}
+	label := p.src[start:p.pos]
+	if label == "" {
+		return Term{}, p.errorf("empty blank node label")
+	}
+	// labels are scoped to the document; map them to fresh nodes in the graph
+	if node, ok := p.blanks[label]; ok {
+		return node, nil
+	}
+	node := p.graph.NewBlank()
+	p.blanks[label] = node
+	return node, nil
+}
+
+// parseIRIRef parses "<...>" and resolves it against the base IRI
+func (p *turtleParser) parseIRIRef() (string, error) {
+	if err := p.expect('<'); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated IRI")
+		}
+		r := p.next()
+		switch {
+		case r == '>':
+			return p.resolve(b.String())
+		case r == '\\':
+			decoded, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(decoded)
+		case r <= 0x20 || strings.ContainsRune("<\"{}|^`", r):
+			return "", p.errorf("invalid character %q in IRI", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// resolve turns a relative IRI reference into an absolute IRI
+func (p *turtleParser) resolve(ref string) (string, error) {
+	if p.baseIRI == "" {
+		return ref, nil
+	}
+	return ResolveIRI(p.baseIRI, ref)
+}
+
+// ResolveIRI resolves ref against base (RFC 3986), returning ref unchanged when it is already absolute.
+func ResolveIRI(base, ref string) (string, error) {
+	if base == "" || hasScheme(ref) {
+		return ref, nil
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base IRI %q: %w", base, err)
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid IRI %q: %w", ref, err)
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// hasScheme reports whether iri starts with a URI scheme
+func hasScheme(iri string) bool {
+	for i, r := range iri {
+		switch {
+		case r == ':':
+			return i > 0
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// parseUnicodeEscape parses the rest of \uXXXX or \UXXXXXXXX after the backslash
+func (p *turtleParser) parseUnicodeEscape() (rune, error) {
+	if p.eof() {
+		return 0, p.errorf("incomplete escape")
+	}
+	width := 0
+	switch p.next() {
+	case 'u':
+		width = 4
+	case 'U':
+		width = 8
+	default:
+		return 0, p.errorf("invalid escape in IRI")
+	}
+	if p.pos+width > len(p.src) {
+		return 0, p.errorf("incomplete unicode escape")
+	}
+	n, err := strconv.ParseUint(p.src[p.pos:p.pos+width], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return 0, p.errorf("invalid unicode escape %q", p.src[p.pos:p.pos+width])
+	}
+	p.pos += width
+	return rune(n), nil
+}
+
+// parsePrefixedName parses prefix:local and expands it
+func (p *turtleParser) parsePrefixedName() (string, error) {
+	start := p.pos
+	for !p.eof() && p.src[p.pos] != ':' {
+		r := p.peek()
+		if !isPNChar(r) && r != '.' {
+			break
+		}
+		p.next()
+	}
+	if p.eof() || p.src[p.pos] != ':' {
+		p.pos = start
+		return "", p.errorf("expected IRI, blank node or literal, found %s", p.describeNext())
+	}
+	prefix := p.src[start:p.pos]
+	namespace, ok := p.graph.Prefixes[prefix]
+	if !ok {
+		return "", p.errorf("undefined prefix %q", prefix)
+	}
+	p.pos++
+
+	var local strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case isPNChar(r) || r == ':':
+			local.WriteRune(p.next())
+		case r == '.':
+			// a dot may appear inside a local name but never ends one
+			nextRune, _ := utf8.DecodeRuneInString(p.src[p.pos+1:])
+			if p.pos+1 < len(p.src) && (isPNChar(nextRune) || nextRune == ':' || nextRune == '%' || nextRune == '\\') {
+				local.WriteRune(p.next())
+				continue
+			}
+			return namespace + local.String(), nil
+		case r == '%':
+			if p.pos+3 > len(p.src) || !isHex(p.src[p.pos+1]) || !isHex(p.src[p.pos+2]) {
+				return "", p.errorf("invalid percent escape in local name")
+			}
+			local.WriteString(p.src[p.pos : p.pos+3])
+			p.pos += 3
+		case r == '\\':
+			p.pos++
+			if p.eof() || !strings.ContainsRune("_~.-!$&'()*+,;=/?#@%", p.peek()) {
+				return "", p.errorf("invalid escape in local name")
+			}
+			local.WriteRune(p.next())
+		default:
+			return namespace + local.String(), nil
+		}
+	}
+	return namespace + local.String(), nil
+}
+
+// parseRDFLiteral parses a quoted string with an optional language tag or datatype
+func (p *turtleParser) parseRDFLiteral() (Term, error) {
+	value, err := p.parseString()
+	if err != nil {
+		return Term{}, err
+	}
+
+	if p.peek() == '@' {
+		p.pos++
+		start := p.pos
+		for !p.eof() {
+			c := p.src[p.pos]
+			if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' && p.pos > start || c == '-' && p.pos > start {
+				p.pos++
+				continue
+			}
+			break
+		}
+		if p.pos == start {
+			return Term{}, p.errorf("empty language tag")
+		}
+		return LangLiteral(value, p.src[start:p.pos]), nil
+	}
+
+	if strings.HasPrefix(p.src[p.pos:], "^^") {
+		p.pos += 2
+		var datatype string
+		if p.peek() == '<' {
+			datatype, err = p.parseIRIRef()
+		} else {
+			datatype, err = p.parsePrefixedName()
+		}
+		if err != nil {
+			return Term{}, err
+		}
+		return TypedLiteral(value, datatype), nil
+	}
+
+	return Literal(value), nil
+}
+
+// parseString parses short and triple-quoted long strings, with either quote character
+func (p *turtleParser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	long := strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3))
+	if long {
+		p.pos += 3
+	} else {
+		p.pos++
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote && !long:
+			p.pos++
+			return b.String(), nil
+		case c == quote && long && strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3)):
+			// a long string may end with up to two extra quotes before the closing delimiter
+			end := p.pos + 3
+			for end < len(p.src) && p.src[end] == quote && end-p.pos < 5 {
+				end++
+			}
+			b.WriteString(p.src[p.pos : end-3])
+			p.pos = end
+			return b.String(), nil
+		case (c == '\n' || c == '\r') && !long:
+			return "", p.errorf("newline in string literal")
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("incomplete escape")
+			}
+			switch esc := p.src[p.pos]; esc {
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 'f':
+				b.WriteByte('\f')
+			case '"', '\'', '\\':
+				b.WriteByte(esc)
+			case 'u', 'U':
+				r, err := p.parseUnicodeEscape()
+				if err != nil {
+					return "", err
+				}
+				b.WriteRune(r)
+				continue
+			default:
+				return "", p.errorf("invalid escape \\%c", esc)
+			}
+			p.pos++
+		default:
+			b.WriteRune(p.next())
+		}
+	}
+}
+
+// parseNumber parses integer, decimal and double literals
+func (p *turtleParser) parseNumber() (Term, error) {
+	start := p.pos
+	if c := p.src[p.pos]; c == '+' || c == '-' {
+		p.pos++
+	}
+	digits := p.skipDigits()
+
+	datatype := XSDInteger
+	if p.peek() == '.' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		digits += p.skipDigits()
+		datatype = XSDDecimal
+	}
+	if c := p.peek(); (c == 'e' || c == 'E') && digits > 0 {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if p.skipDigits() == 0 {
+			return Term{}, p.errorf("invalid exponent in number")
+		}
+		datatype = XSDDouble
+	}
+	if digits == 0 {
+		p.pos = start
+		return Term{}, p.errorf("invalid number %s", p.describeNext())
+	}
+	return TypedLiteral(p.src[start:p.pos], datatype), nil
+}
+
+func (p *turtleParser) skipDigits() int {
+	n := 0
+	for !p.eof() && isDigit(p.src[p.pos]) {
+		p.pos++
+		n++
+	}
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// isPNChar approximates the Turtle PN_CHARS production
+func isPNChar(r rune) bool {
+	return r == '_' || r == '-' || r == 0xB7 || unicode.IsLetter(r) || unicode.IsDigit(r) ||
+		r >= 0x0300 && r <= 0x036F || r >= 0x203F && r <= 0x2040
+}
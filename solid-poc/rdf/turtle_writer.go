@@ -0,0 +1,300 @@
+package rdf
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	integerLexical = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	decimalLexical = regexp.MustCompile(`^[+-]?[0-9]*\.[0-9]+$`)
+	doubleLexical  = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)[eE][+-]?[0-9]+$`)
+	localName      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// Turtle serializes the graph as Turtle. Subjects appear in insertion order,
+// and blank nodes used exactly once as an object are written inline as [ ... ].
+func (g *Graph) Turtle() string {
+	var buf bytes.Buffer
+	_ = g.WriteTurtle(&buf) // writes to a bytes.Buffer cannot fail
+	return buf.String()
+}
+
+// WriteTurtle serializes the graph as Turtle to w.
+func (g *Graph) WriteTurtle(w io.Writer) error {
+	tw := &turtleWriter{graph: g, labels: make(map[Term]string)}
+	tw.prepare()
+	tw.writePrefixes()
+	tw.writeSubjects()
+	_, err := w.Write(tw.out.Bytes())
+	return err
+}
+
+type turtleWriter struct {
+	graph    *Graph
+	out      bytes.Buffer
+	prefixes []string
+	inline   map[Term]bool
+	labels   map[Term]string
+	used     map[string]bool
+}
+
+// prepare decides which blank nodes can be nested and which prefixes are used
+func (tw *turtleWriter) prepare() {
+	references := make(map[Term]int)
+	for _, t := range tw.graph.triples {
+		if t.Object.IsBlank() {
+			references[t.Object]++
+		}
+	}
+
+	tw.inline = make(map[Term]bool)
+	for node, count := range references {
+		if count == 1 {
+			tw.inline[node] = true
+		}
+	}
+	// a cycle of single-use blank nodes has no outer subject to nest under
+	for node := range tw.inline {
+		if tw.inlineCycle(node) {
+			delete(tw.inline, node)
+		}
+	}
+
+	for prefix := range tw.graph.Prefixes {
+		tw.prefixes = append(tw.prefixes, prefix)
+	}
+	// longer namespaces first, so the most specific prefix wins
+	sort.Slice(tw.prefixes, func(i, j int) bool {
+		a, b := tw.graph.Prefixes[tw.prefixes[i]], tw.graph.Prefixes[tw.prefixes[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return tw.prefixes[i] < tw.prefixes[j]
+	})
+
+	tw.used = make(map[string]bool)
+	mark := func(iri string) {
+		if prefix, _, ok := tw.compact(iri); ok {
+			tw.used[prefix] = true
+		}
+	}
+	for _, t := range tw.graph.triples {
+		for _, term := range []Term{t.Subject, t.Predicate, t.Object} {
+			switch {
+			case term.IsIRI():
+				mark(term.Value)
+			case term.IsLiteral() && term.Language == "" && term.Datatype != XSDString && !tw.bareLiteral(term):
+				mark(term.Datatype)
+			}
+		}
+	}
+}
+
+// inlineCycle reports whether following inline parents from node leads back to node
+func (tw *turtleWriter) inlineCycle(node Term) bool {
+	seen := map[Term]bool{node: true}
+	current := node
+	for {
+		parents := tw.graph.byObject[current]
+		if len(parents) != 1 {
+			return false
+		}
+		parent := tw.graph.triples[parents[0]].Subject
+		if !parent.IsBlank() || !tw.inline[parent] {
+			return false
+		}
+		if seen[parent] {
+			return true
+		}
+		seen[parent] = true
+		current = parent
+	}
+}
+
+func (tw *turtleWriter) writePrefixes() {
+	names := make([]string, 0, len(tw.used))
+	for prefix := range tw.used {
+		names = append(names, prefix)
+	}
+	sort.Strings(names)
+	for _, prefix := range names {
+		tw.out.WriteString("@prefix " + prefix + ": <" + escapeIRI(tw.graph.Prefixes[prefix]) + "> .\n")
+	}
+	if len(names) > 0 {
+		tw.out.WriteString("\n")
+	}
+}
+
+func (tw *turtleWriter) writeSubjects() {
+	first := true
+	for _, subject := range tw.graph.AllSubjects() {
+		if tw.inline[subject] {
+			continue
+		}
+		if !first {
+			tw.out.WriteString("\n")
+		}
+		first = false
+		tw.out.WriteString(tw.term(subject))
+		tw.writePredicates(subject, 1, " ")
+		tw.out.WriteString(" .\n")
+	}
+}
+
+// writePredicates writes the predicate-object list of subject at the given indent depth,
+// starting with lead
+func (tw *turtleWriter) writePredicates(subject Term, depth int, lead string) {
+	var order []Term
+	objects := make(map[Term][]Term)
+	for _, i := range tw.graph.bySubject[subject] {
+		t := tw.graph.triples[i]
+		if _, ok := objects[t.Predicate]; !ok {
+			order = append(order, t.Predicate)
+		}
+		objects[t.Predicate] = append(objects[t.Predicate], t.Object)
+	}
+	// rdf:type reads best first, as "a"
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Value == RDFType && order[j].Value != RDFType
+	})
+
+	indent := strings.Repeat("    ", depth)
+	for i, predicate := range order {
+		if i == 0 {
+			tw.out.WriteString(lead)
+		} else {
+			tw.out.WriteString(" ;\n" + indent)
+		}
+		if predicate.Value == RDFType {
+			tw.out.WriteString("a")
+		} else {
+			tw.out.WriteString(tw.term(predicate))
+		}
+		for j, object := range objects[predicate] {
+			if j == 0 {
+				tw.out.WriteString(" ")
+			} else {
+				tw.out.WriteString(", ")
+			}
+			tw.writeObject(object, depth)
+		}
+	}
+}
+
+func (tw *turtleWriter) writeObject(object Term, depth int) {
+	if !tw.inline[object] {
+		tw.out.WriteString(tw.term(object))
+		return
+	}
+	if members, ok := tw.collection(object); ok {
+		tw.out.WriteString("(")
+		for _, member := range members {
+			tw.out.WriteString(" ")
+			tw.writeObject(member, depth)
+		}
+		tw.out.WriteString(" )")
+		return
+	}
+	if len(tw.graph.bySubject[object]) == 0 {
+		tw.out.WriteString("[]")
+		return
+	}
+	tw.out.WriteString("[")
+	tw.writePredicates(object, depth+1, "\n"+strings.Repeat("    ", depth+1))
+	tw.out.WriteString("\n" + strings.Repeat("    ", depth) + "]")
+}
+
+// collection returns the members of a well-formed list whose cells can all be nested
+func (tw *turtleWriter) collection(head Term) ([]Term, bool) {
+	var members []Term
+	for node := head; node.Value != RDFNil || !node.IsIRI(); {
+		triples := tw.graph.bySubject[node]
+		if !node.IsBlank() || !tw.inline[node] || len(triples) != 2 {
+			return nil, false
+		}
+		first, hasFirst := tw.graph.Object(node, IRI(RDFFirst))
+		rest, hasRest := tw.graph.Object(node, IRI(RDFRest))
+		if !hasFirst || !hasRest {
+			return nil, false
+		}
+		members = append(members, first)
+		node = rest
+	}
+	return members, true
+}
+
+// term renders a term in its shortest Turtle form
+func (tw *turtleWriter) term(t Term) string {
+	switch t.Kind {
+	case KindIRI:
+		return tw.iri(t.Value)
+	case KindBlank:
+		label, ok := tw.labels[t]
+		if !ok {
+			label = "_:n" + strconv.Itoa(len(tw.labels)+1)
+			tw.labels[t] = label
+		}
+		return label
+	case KindLiteral:
+		if t.Language != "" {
+			return quoteString(t.Value) + "@" + t.Language
+		}
+		if tw.bareLiteral(t) {
+			return t.Value
+		}
+		if t.Datatype == XSDString || t.Datatype == "" {
+			return quoteString(t.Value)
+		}
+		return quoteString(t.Value) + "^^" + tw.iri(t.Datatype)
+	}
+	return ""
+}
+
+// bareLiteral reports whether a literal can be written without quotes
+func (tw *turtleWriter) bareLiteral(t Term) bool {
+	switch t.Datatype {
+	case XSDInteger:
+		return integerLexical.MatchString(t.Value)
+	case XSDDecimal:
+		return decimalLexical.MatchString(t.Value)
+	case XSDDouble:
+		return doubleLexical.MatchString(t.Value)
+	case XSDBoolean:
+		return t.Value == "true" || t.Value == "false"
+	}
+	return false
+}
+
+// iri renders an IRI as a prefixed name, a relative reference, or <absolute>
+func (tw *turtleWriter) iri(iri string) string {
+	if prefix, local, ok := tw.compact(iri); ok {
+		return prefix + ":" + local
+	}
+	if base := tw.graph.Base; base != "" && strings.HasPrefix(iri, base) {
+		rest := iri[len(base):]
+		if rest == "" || rest[0] == '#' {
+			return "<" + escapeIRI(rest) + ">"
+		}
+	}
+	return "<" + escapeIRI(iri) + ">"
+}
+
+// compact finds a declared prefix whose namespace starts iri with a simple local name
+func (tw *turtleWriter) compact(iri string) (string, string, bool) {
+	for _, prefix := range tw.prefixes {
+		namespace := tw.graph.Prefixes[prefix]
+		if namespace == "" || !strings.HasPrefix(iri, namespace) {
+			continue
+		}
+		local := iri[len(namespace):]
+		if local == "" || localName.MatchString(local) {
+			return prefix, local, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,54 @@
+package rdf
+
+// Namespaces used by the location tracker's pod documents
+const (
+	RDFNS     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	RDFSNS    = "http://www.w3.org/2000/01/rdf-schema#"
+	XSDNS     = "http://www.w3.org/2001/XMLSchema#"
+	SchemaNS  = "http://schema.org/"
+	GeoNS     = "http://www.w3.org/2003/01/geo/wgs84_pos#"
+	DCTermsNS = "http://purl.org/dc/terms/"
+	FOAFNS    = "http://xmlns.com/foaf/0.1/"
+	SolidNS   = "http://www.w3.org/ns/solid/terms#"
+	PIMNS     = "http://www.w3.org/ns/pim/space#"
+	LDPNS     = "http://www.w3.org/ns/ldp#"
+	ACLNS     = "http://www.w3.org/ns/auth/acl#"
+	ACPNS     = "http://www.w3.org/ns/solid/acp#"
+	SHNS      = "http://www.w3.org/ns/shacl#"
+)
+
+// Frequently used IRIs
+const (
+	RDFType       = RDFNS + "type"
+	RDFFirst      = RDFNS + "first"
+	RDFRest       = RDFNS + "rest"
+	RDFNil        = RDFNS + "nil"
+	RDFLangString = RDFNS + "langString"
+
+	XSDString   = XSDNS + "string"
+	XSDBoolean  = XSDNS + "boolean"
+	XSDInteger  = XSDNS + "integer"
+	XSDDecimal  = XSDNS + "decimal"
+	XSDDouble   = XSDNS + "double"
+	XSDDateTime = XSDNS + "dateTime"
+	XSDDate     = XSDNS + "date"
+	XSDTime     = XSDNS + "time"
+	XSDAnyURI   = XSDNS + "anyURI"
+)
+
+// CommonPrefixes are the prefixes writers declare by default.
+var CommonPrefixes = map[string]string{
+	"rdf":     RDFNS,
+	"rdfs":    RDFSNS,
+	"xsd":     XSDNS,
+	"schema":  SchemaNS,
+	"geo":     GeoNS,
+	"dcterms": DCTermsNS,
+	"foaf":    FOAFNS,
+	"solid":   SolidNS,
+	"pim":     PIMNS,
+	"ldp":     LDPNS,
+	"acl":     ACLNS,
+	"acp":     ACPNS,
+	"sh":      SHNS,
+}
@@ -0,0 +1,271 @@
+package shacl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// constraint is one SHACL constraint component attached to a shape
+type constraint interface {
+	component() string
+	validate(v *validator, focus rdf.Term, values []rdf.Term) []failure
+}
+
+// failure is one failed check; value is zero for checks over the whole value set
+type failure struct {
+	value   rdf.Term
+	message string
+}
+
+// eachValue applies a per-value check
+func eachValue(values []rdf.Term, check func(rdf.Term) string) []failure {
+	var failures []failure
+	for _, value := range values {
+		if message := check(value); message != "" {
+			failures = append(failures, failure{value: value, message: message})
+		}
+	}
+	return failures
+}
+
+type countConstraint struct {
+	max   bool
+	limit int
+}
+
+func (c *countConstraint) component() string {
+	if c.max {
+		return "MaxCountConstraintComponent"
+	}
+	return "MinCountConstraintComponent"
+}
+
+func (c *countConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	switch {
+	case c.max && len(values) > c.limit:
+		return []failure{{message: fmt.Sprintf("has %d values, at most %d allowed", len(values), c.limit)}}
+	case !c.max && len(values) < c.limit:
+		return []failure{{message: fmt.Sprintf("has %d values, at least %d required", len(values), c.limit)}}
+	}
+	return nil
+}
+
+type datatypeConstraint struct {
+	datatype string
+}
+
+func (c *datatypeConstraint) component() string { return "DatatypeConstraintComponent" }
+
+func (c *datatypeConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		if !value.IsLiteral() || value.Datatype != c.datatype {
+			return fmt.Sprintf("is not a literal of datatype <%s>", c.datatype)
+		}
+		if !wellFormed(value) {
+			return fmt.Sprintf("is not a valid <%s> lexical form", c.datatype)
+		}
+		return ""
+	})
+}
+
+// lexical forms of the XSD datatypes our shapes use
+var lexicalForms = map[string]*regexp.Regexp{
+	rdf.XSDInteger:  regexp.MustCompile(`^[+-]?[0-9]+$`),
+	rdf.XSDDecimal:  regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`),
+	rdf.XSDDouble:   regexp.MustCompile(`^([+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[+-]?INF|NaN)$`),
+	rdf.XSDBoolean:  regexp.MustCompile(`^(true|false|1|0)$`),
+	rdf.XSDDateTime: regexp.MustCompile(`^-?[0-9]{4,}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?$`),
+	rdf.XSDDate:     regexp.MustCompile(`^-?[0-9]{4,}-[0-9]{2}-[0-9]{2}(Z|[+-][0-9]{2}:[0-9]{2})?$`),
+	rdf.XSDTime:     regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?$`),
+}
+
+// wellFormed reports whether a literal's lexical form fits its datatype
+func wellFormed(value rdf.Term) bool {
+	form, ok := lexicalForms[value.Datatype]
+	if !ok {
+		return true
+	}
+	if !form.MatchString(value.Value) {
+		return false
+	}
+	if value.Datatype == rdf.XSDDateTime || value.Datatype == rdf.XSDDate {
+		_, err := value.Time()
+		return err == nil
+	}
+	return true
+}
+
+type classConstraint struct {
+	class rdf.Term
+}
+
+func (c *classConstraint) component() string { return "ClassConstraintComponent" }
+
+func (c *classConstraint) validate(v *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		if value.IsLiteral() || !v.instanceOf(value, c.class) {
+			return fmt.Sprintf("is not an instance of %s", c.class)
+		}
+		return ""
+	})
+}
+
+type nodeKindConstraint struct {
+	kind string
+}
+
+func (c *nodeKindConstraint) component() string { return "NodeKindConstraintComponent" }
+
+func (c *nodeKindConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	allowed := strings.TrimPrefix(c.kind, rdf.SHNS)
+	return eachValue(values, func(value rdf.Term) string {
+		var kind string
+		switch value.Kind {
+		case rdf.KindIRI:
+			kind = "IRI"
+		case rdf.KindBlank:
+			kind = "BlankNode"
+		default:
+			kind = "Literal"
+		}
+		for _, option := range []string{"BlankNodeOrIRI", "BlankNodeOrLiteral", "IRIOrLiteral"} {
+			if allowed == option && strings.Contains(option, kind) {
+				return ""
+			}
+		}
+		if allowed == kind {
+			return ""
+		}
+		return fmt.Sprintf("is a %s, expected sh:%s", kind, allowed)
+	})
+}
+
+// rangeConstraint is sh:minInclusive, sh:maxInclusive, sh:minExclusive or sh:maxExclusive
+type rangeConstraint struct {
+	kind  string
+	bound float64
+}
+
+func (c *rangeConstraint) component() string {
+	return strings.ToUpper(c.kind[:1]) + c.kind[1:] + "ConstraintComponent"
+}
+
+func (c *rangeConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		n, err := value.Float()
+		if err != nil {
+			return "is not a number"
+		}
+		ok := false
+		switch c.kind {
+		case "minInclusive":
+			ok = n >= c.bound
+		case "maxInclusive":
+			ok = n <= c.bound
+		case "minExclusive":
+			ok = n > c.bound
+		case "maxExclusive":
+			ok = n < c.bound
+		}
+		if !ok {
+			return fmt.Sprintf("is outside sh:%s %g", c.kind, c.bound)
+		}
+		return ""
+	})
+}
+
+type lengthConstraint struct {
+	max   bool
+	limit int
+}
+
+func (c *lengthConstraint) component() string {
+	if c.max {
+		return "MaxLengthConstraintComponent"
+	}
+	return "MinLengthConstraintComponent"
+}
+
+func (c *lengthConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		if value.IsBlank() {
+			return "is a blank node"
+		}
+		length := utf8.RuneCountInString(value.Value)
+		if c.max && length > c.limit {
+			return fmt.Sprintf("is %d characters long, at most %d allowed", length, c.limit)
+		}
+		if !c.max && length < c.limit {
+			return fmt.Sprintf("is %d characters long, at least %d required", length, c.limit)
+		}
+		return ""
+	})
+}
+
+type patternConstraint struct {
+	pattern *regexp.Regexp
+}
+
+func (c *patternConstraint) component() string { return "PatternConstraintComponent" }
+
+func (c *patternConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		if value.IsBlank() || !c.pattern.MatchString(value.Value) {
+			return fmt.Sprintf("does not match pattern %q", c.pattern.String())
+		}
+		return ""
+	})
+}
+
+type inConstraint struct {
+	values []rdf.Term
+}
+
+func (c *inConstraint) component() string { return "InConstraintComponent" }
+
+func (c *inConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		for _, allowed := range c.values {
+			if value == allowed {
+				return ""
+			}
+		}
+		return "is not one of the allowed values"
+	})
+}
+
+type hasValueConstraint struct {
+	value rdf.Term
+}
+
+func (c *hasValueConstraint) component() string { return "HasValueConstraintComponent" }
+
+func (c *hasValueConstraint) validate(_ *validator, _ rdf.Term, values []rdf.Term) []failure {
+	for _, value := range values {
+		if value == c.value {
+			return nil
+		}
+	}
+	return []failure{{message: fmt.Sprintf("is missing the value %s", c.value)}}
+}
+
+type nodeConstraint struct {
+	id    rdf.Term
+	shape *shape
+}
+
+func (c *nodeConstraint) component() string { return "NodeConstraintComponent" }
+
+func (c *nodeConstraint) validate(v *validator, _ rdf.Term, values []rdf.Term) []failure {
+	return eachValue(values, func(value rdf.Term) string {
+		nested := v.conformsTo(c.shape, value)
+		if len(nested) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("does not conform to %s: %s", c.id, nested[0].describe())
+	})
+}
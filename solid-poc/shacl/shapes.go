@@ -0,0 +1,250 @@
+// Package shacl validates RDF graphs against SHACL Core shapes: targets,
+// cardinality, value type (datatype, class, node kind), value range, string
+// (pattern, length), sh:in / sh:hasValue and nested sh:node constraints.
+package shacl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+func sh(local string) rdf.Term { return rdf.IRI(rdf.SHNS + local) }
+
+// Severity of a validation result
+const (
+	Violation = rdf.SHNS + "Violation"
+	Warning   = rdf.SHNS + "Warning"
+	Info      = rdf.SHNS + "Info"
+)
+
+// Shapes is a parsed shapes graph.
+type Shapes struct {
+	nodeShapes []*shape
+	byID       map[rdf.Term]*shape
+}
+
+// shape is a node shape or property shape; property shapes carry a path
+type shape struct {
+	id          rdf.Term
+	path        *path
+	targets     []target
+	properties  []*shape
+	constraints []constraint
+	severity    string
+	message     string
+	name        string
+	deactivated bool
+}
+
+// path is a predicate path, optionally inverted
+type path struct {
+	predicate rdf.Term
+	inverse   bool
+}
+
+func (p *path) String() string {
+	if p.inverse {
+		return "^" + p.predicate.String()
+	}
+	return p.predicate.String()
+}
+
+// targetKind follows the order of the target predicates in parseShape
+type targetKind int
+
+const (
+	targetClass targetKind = iota
+	targetNode
+	targetSubjectsOf
+	targetObjectsOf
+)
+
+type target struct {
+	kind targetKind
+	term rdf.Term
+}
+
+// Parse reads the node shapes (and their property shapes) from a shapes graph.
+func Parse(g *rdf.Graph) (*Shapes, error) {
+	s := &Shapes{byID: make(map[rdf.Term]*shape)}
+
+	// node shapes are declared with a type, or implied by a target
+	var ids []rdf.Term
+	seen := make(map[rdf.Term]bool)
+	collect := func(terms []rdf.Term) {
+		for _, t := range terms {
+			if !seen[t] {
+				seen[t] = true
+				ids = append(ids, t)
+			}
+		}
+	}
+	collect(g.SubjectsOfType(rdf.SHNS + "NodeShape"))
+	for _, predicate := range []string{"targetClass", "targetNode", "targetSubjectsOf", "targetObjectsOf"} {
+		collect(g.Subjects(sh(predicate), rdf.Term{}))
+	}
+
+	for _, id := range ids {
+		parsed, err := s.parseShape(g, id, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.nodeShapes = append(s.nodeShapes, parsed)
+	}
+
+	// sh:node may name shapes that are only referenced, never targeted
+	for _, t := range g.Match(rdf.Term{}, sh("node"), rdf.Term{}) {
+		if _, ok := s.byID[t.Object]; !ok {
+			if _, err := s.parseShape(g, t.Object, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, parsed := range s.byID {
+		for _, c := range parsed.constraints {
+			if ref, ok := c.(*nodeConstraint); ok {
+				if ref.shape = s.byID[ref.id]; ref.shape == nil {
+					return nil, fmt.Errorf("shacl: sh:node refers to unknown shape %s", ref.id)
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *Shapes) parseShape(g *rdf.Graph, id rdf.Term, p *path) (*shape, error) {
+	if existing, ok := s.byID[id]; ok {
+		return existing, nil
+	}
+	result := &shape{id: id, path: p, severity: Violation}
+	s.byID[id] = result
+
+	if severity, ok := g.Object(id, sh("severity")); ok {
+		result.severity = severity.Value
+	}
+	if message, ok := g.Object(id, sh("message")); ok {
+		result.message = message.Value
+	}
+	if name, ok := g.Object(id, sh("name")); ok {
+		result.name = name.Value
+	}
+	if deactivated, ok := g.Object(id, sh("deactivated")); ok {
+		result.deactivated, _ = deactivated.Bool()
+	}
+
+	for kind, predicate := range []string{"targetClass", "targetNode", "targetSubjectsOf", "targetObjectsOf"} {
+		for _, t := range g.Objects(id, sh(predicate)) {
+			result.targets = append(result.targets, target{kind: targetKind(kind), term: t})
+		}
+	}
+
+	for _, propertyID := range g.Objects(id, sh("property")) {
+		pathTerm, ok := g.Object(propertyID, sh("path"))
+		if !ok {
+			return nil, fmt.Errorf("shacl: property shape %s has no sh:path", propertyID)
+		}
+		propertyPath, err := parsePath(g, pathTerm)
+		if err != nil {
+			return nil, err
+		}
+		property, err := s.parseShape(g, propertyID, propertyPath)
+		if err != nil {
+			return nil, err
+		}
+		result.properties = append(result.properties, property)
+	}
+
+	constraints, err := parseConstraints(g, id)
+	if err != nil {
+		return nil, fmt.Errorf("shacl: shape %s: %w", id, err)
+	}
+	result.constraints = constraints
+	return result, nil
+}
+
+// parsePath supports predicate paths and sh:inversePath
+func parsePath(g *rdf.Graph, term rdf.Term) (*path, error) {
+	if term.IsIRI() {
+		return &path{predicate: term}, nil
+	}
+	if inverse, ok := g.Object(term, sh("inversePath")); ok && inverse.IsIRI() {
+		return &path{predicate: inverse, inverse: true}, nil
+	}
+	return nil, fmt.Errorf("shacl: unsupported property path %s", term)
+}
+
+func parseConstraints(g *rdf.Graph, id rdf.Term) ([]constraint, error) {
+	var constraints []constraint
+
+	for _, predicate := range []string{"minCount", "maxCount"} {
+		if value, ok := g.Object(id, sh(predicate)); ok {
+			n, err := value.Int()
+			if err != nil {
+				return nil, fmt.Errorf("sh:%s: %w", predicate, err)
+			}
+			constraints = append(constraints, &countConstraint{max: predicate == "maxCount", limit: int(n)})
+		}
+	}
+	for _, datatype := range g.Objects(id, sh("datatype")) {
+		constraints = append(constraints, &datatypeConstraint{datatype: datatype.Value})
+	}
+	for _, class := range g.Objects(id, sh("class")) {
+		constraints = append(constraints, &classConstraint{class: class})
+	}
+	if kind, ok := g.Object(id, sh("nodeKind")); ok {
+		constraints = append(constraints, &nodeKindConstraint{kind: kind.Value})
+	}
+
+	for _, predicate := range []string{"minInclusive", "maxInclusive", "minExclusive", "maxExclusive"} {
+		if value, ok := g.Object(id, sh(predicate)); ok {
+			bound, err := value.Float()
+			if err != nil {
+				return nil, fmt.Errorf("sh:%s: %w", predicate, err)
+			}
+			constraints = append(constraints, &rangeConstraint{kind: predicate, bound: bound})
+		}
+	}
+	for _, predicate := range []string{"minLength", "maxLength"} {
+		if value, ok := g.Object(id, sh(predicate)); ok {
+			n, err := value.Int()
+			if err != nil {
+				return nil, fmt.Errorf("sh:%s: %w", predicate, err)
+			}
+			constraints = append(constraints, &lengthConstraint{max: predicate == "maxLength", limit: int(n)})
+		}
+	}
+	for _, pattern := range g.Objects(id, sh("pattern")) {
+		flags := ""
+		if f, ok := g.Object(id, sh("flags")); ok {
+			flags = f.Value
+		}
+		expr := pattern.Value
+		if strings.Contains(flags, "i") {
+			expr = "(?i)" + expr
+		}
+		if strings.Contains(flags, "s") {
+			expr = "(?s)" + expr
+		}
+		if strings.Contains(flags, "m") {
+			expr = "(?m)" + expr
+		}
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("sh:pattern %q: %w", pattern.Value, err)
+		}
+		constraints = append(constraints, &patternConstraint{pattern: compiled})
+	}
+	if list, ok := g.Object(id, sh("in")); ok {
+		constraints = append(constraints, &inConstraint{values: g.List(list)})
+	}
+	for _, value := range g.Objects(id, sh("hasValue")) {
+		constraints = append(constraints, &hasValueConstraint{value: value})
+	}
+	for _, ref := range g.Objects(id, sh("node")) {
+		constraints = append(constraints, &nodeConstraint{id: ref})
+	}
+	return constraints, nil
+}
@@ -0,0 +1,229 @@
+package shacl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Result is one validation result (sh:ValidationResult).
+type Result struct {
+	FocusNode rdf.Term
+	Path      string   // property path, empty for node shape constraints
+	Value     rdf.Term // offending value, zero for whole-set checks like sh:minCount
+	Component string   // e.g. "MinCountConstraintComponent"
+	Shape     rdf.Term
+	Severity  string
+	Message   string
+}
+
+// describe renders a result on one line
+func (r Result) describe() string {
+	var b strings.Builder
+	b.WriteString(r.FocusNode.String())
+	if r.Path != "" {
+		b.WriteString(" " + r.Path)
+	}
+	if !r.Value.IsZero() {
+		b.WriteString(" value " + r.Value.String())
+	}
+	b.WriteString(" " + r.Message)
+	b.WriteString(" (sh:" + r.Component + ")")
+	return b.String()
+}
+
+// String renders the result with its severity.
+func (r Result) String() string {
+	return strings.TrimPrefix(r.Severity, rdf.SHNS) + ": " + r.describe()
+}
+
+// Report is the outcome of validating a data graph.
+type Report struct {
+	Conforms bool
+	Results  []Result
+}
+
+// Violations returns the results with sh:Violation severity.
+func (r *Report) Violations() []Result {
+	var violations []Result
+	for _, result := range r.Results {
+		if result.Severity == Violation {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
+
+// String lists every result, one per line.
+func (r *Report) String() string {
+	if r.Conforms {
+		return "conforms"
+	}
+	lines := make([]string, len(r.Results))
+	for i, result := range r.Results {
+		lines[i] = result.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns an error describing the violations, or nil when there are none.
+// Warnings and info results do not fail validation.
+func (r *Report) Err() error {
+	violations := r.Violations()
+	if len(violations) == 0 {
+		return nil
+	}
+	message := violations[0].describe()
+	if len(violations) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(violations)-1)
+	}
+	return &ValidationError{Report: r, message: message}
+}
+
+// ValidationError is returned when data does not conform to its shapes.
+type ValidationError struct {
+	Report  *Report
+	message string
+}
+
+func (e *ValidationError) Error() string {
+	return "SHACL validation failed: " + e.message
+}
+
+// Validate checks data against every active node shape.
+func (s *Shapes) Validate(data *rdf.Graph) *Report {
+	v := &validator{data: data, visiting: make(map[visit]bool)}
+	var results []Result
+	for _, nodeShape := range s.nodeShapes {
+		if nodeShape.deactivated {
+			continue
+		}
+		for _, focus := range v.focusNodes(nodeShape) {
+			results = append(results, v.conformsTo(nodeShape, focus)...)
+		}
+	}
+	return &Report{Conforms: len(results) == 0, Results: results}
+}
+
+type visit struct {
+	shape *shape
+	focus rdf.Term
+}
+
+type validator struct {
+	data     *rdf.Graph
+	visiting map[visit]bool
+}
+
+// focusNodes resolves a shape's targets in the data graph
+func (v *validator) focusNodes(s *shape) []rdf.Term {
+	seen := make(map[rdf.Term]bool)
+	var nodes []rdf.Term
+	add := func(t rdf.Term) {
+		if !seen[t] {
+			seen[t] = true
+			nodes = append(nodes, t)
+		}
+	}
+	for _, t := range s.targets {
+		switch t.kind {
+		case targetClass:
+			for _, subject := range v.data.Subjects(rdf.IRI(rdf.RDFType), rdf.Term{}) {
+				if v.instanceOf(subject, t.term) {
+					add(subject)
+				}
+			}
+		case targetNode:
+			add(t.term)
+		case targetSubjectsOf:
+			for _, triple := range v.data.Match(rdf.Term{}, t.term, rdf.Term{}) {
+				add(triple.Subject)
+			}
+		case targetObjectsOf:
+			for _, triple := range v.data.Match(rdf.Term{}, t.term, rdf.Term{}) {
+				add(triple.Object)
+			}
+		}
+	}
+	return nodes
+}
+
+// instanceOf checks rdf:type, following rdfs:subClassOf in the data graph
+func (v *validator) instanceOf(node, class rdf.Term) bool {
+	subClassOf := rdf.IRI(rdf.RDFSNS + "subClassOf")
+	for _, typ := range v.data.Objects(node, rdf.IRI(rdf.RDFType)) {
+		seen := map[rdf.Term]bool{}
+		queue := []rdf.Term{typ}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if current == class {
+				return true
+			}
+			if seen[current] {
+				continue
+			}
+			seen[current] = true
+			queue = append(queue, v.data.Objects(current, subClassOf)...)
+		}
+	}
+	return false
+}
+
+// values follows a property path from focus
+func (v *validator) values(focus rdf.Term, p *path) []rdf.Term {
+	if p.inverse {
+		var subjects []rdf.Term
+		for _, t := range v.data.Match(rdf.Term{}, p.predicate, focus) {
+			subjects = append(subjects, t.Subject)
+		}
+		return subjects
+	}
+	return v.data.Objects(focus, p.predicate)
+}
+
+// conformsTo validates focus against a node shape and its property shapes
+func (v *validator) conformsTo(s *shape, focus rdf.Term) []Result {
+	key := visit{s, focus}
+	if v.visiting[key] {
+		// recursive shapes are assumed to conform on the second visit
+		return nil
+	}
+	v.visiting[key] = true
+	defer delete(v.visiting, key)
+
+	results := v.check(s, focus, []rdf.Term{focus})
+	for _, property := range s.properties {
+		if property.deactivated {
+			continue
+		}
+		results = append(results, v.check(property, focus, v.values(focus, property.path))...)
+	}
+	return results
+}
+
+// check evaluates one shape's own constraints over the value nodes
+func (v *validator) check(s *shape, focus rdf.Term, values []rdf.Term) []Result {
+	var results []Result
+	for _, c := range s.constraints {
+		for _, f := range c.validate(v, focus, values) {
+			result := Result{
+				FocusNode: focus,
+				Value:     f.value,
+				Component: c.component(),
+				Shape:     s.id,
+				Severity:  s.severity,
+				Message:   f.message,
+			}
+			if s.path != nil {
+				result.Path = s.path.String()
+			}
+			if s.message != "" {
+				result.Message = s.message
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
@@ -0,0 +1,347 @@
+package solid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+func acl(local string) rdf.Term { return rdf.IRI(rdf.ACLNS + local) }
+func acp(local string) rdf.Term { return rdf.IRI(rdf.ACPNS + local) }
+
+// AccessModes is a set of WAC access modes. Write implies Append.
+type AccessModes struct {
+	Read    bool
+	Append  bool
+	Write   bool
+	Control bool
+}
+
+// String lists the granted modes, e.g. "Read, Write".
+func (m AccessModes) String() string {
+	var modes []string
+	for _, mode := range []struct {
+		granted bool
+		name    string
+	}{{m.Read, "Read"}, {m.Append, "Append"}, {m.Write, "Write"}, {m.Control, "Control"}} {
+		if mode.granted {
+			modes = append(modes, mode.name)
+		}
+	}
+	if len(modes) == 0 {
+		return "none"
+	}
+	return strings.Join(modes, ", ")
+}
+
+// terms returns the acl: mode IRIs of the set
+func (m AccessModes) terms() []rdf.Term {
+	var terms []rdf.Term
+	if m.Read {
+		terms = append(terms, acl("Read"))
+	}
+	if m.Write {
+		terms = append(terms, acl("Write"))
+	} else if m.Append {
+		terms = append(terms, acl("Append"))
+	}
+	if m.Control {
+		terms = append(terms, acl("Control"))
+	}
+	return terms
+}
+
+// add grants a mode named by its acl: IRI
+func (m *AccessModes) add(mode rdf.Term) {
+	switch mode.Value {
+	case rdf.ACLNS + "Read":
+		m.Read = true
+	case rdf.ACLNS + "Append":
+		m.Append = true
+	case rdf.ACLNS + "Write":
+		m.Write, m.Append = true, true
+	case rdf.ACLNS + "Control":
+		m.Control = true
+	}
+}
+
+// remove revokes a mode named by its acl: IRI (ACP deny)
+func (m *AccessModes) remove(mode rdf.Term) {
+	switch mode.Value {
+	case rdf.ACLNS + "Read":
+		m.Read = false
+	case rdf.ACLNS + "Append":
+		m.Append, m.Write = false, false
+	case rdf.ACLNS + "Write":
+		m.Write = false
+	case rdf.ACLNS + "Control":
+		m.Control = false
+	}
+}
+
+var (
+	ownerModes  = AccessModes{Read: true, Append: true, Write: true, Control: true}
+	readerModes = AccessModes{Read: true}
+)
+
+// AccessPolicy says who may use a pod resource: the owner has full control, readers
+// and (when Public is set) everyone else may read.
+type AccessPolicy struct {
+	Owner   string   // WebID with Read, Write and Control
+	Readers []string // WebIDs the resource is shared with (read-only)
+	Public  bool     // anyone, including unauthenticated agents, may read
+}
+
+// OwnerOnly keeps a resource private to its owner.
+func OwnerOnly(owner string) AccessPolicy {
+	return AccessPolicy{Owner: owner}
+}
+
+// SharedWith lets the given WebIDs read a resource.
+func SharedWith(owner string, readers ...string) AccessPolicy {
+	return AccessPolicy{Owner: owner, Readers: readers}
+}
+
+// PublicRead lets anyone read a resource.
+func PublicRead(owner string) AccessPolicy {
+	return AccessPolicy{Owner: owner, Public: true}
+}
+
+// Visibility names the policy: "owner-only", "shared" or "public-read".
+func (p AccessPolicy) Visibility() string {
+	switch {
+	case p.Public:
+		return "public-read"
+	case len(p.readers()) > 0:
+		return "shared"
+	default:
+		return "owner-only"
+	}
+}
+
+// WithReader returns the policy with one more reader.
+func (p AccessPolicy) WithReader(webID string) AccessPolicy {
+	p.Readers = append(append([]string(nil), p.Readers...), webID)
+	p.Readers = p.readers()
+	return p
+}
+
+// WithoutReader returns the policy without the given reader.
+func (p AccessPolicy) WithoutReader(webID string) AccessPolicy {
+	var readers []string
+	for _, reader := range p.Readers {
+		if reader != webID {
+			readers = append(readers, reader)
+		}
+	}
+	p.Readers = readers
+	return p
+}
+
+// readers returns the sorted, de-duplicated readers other than the owner
+func (p AccessPolicy) readers() []string {
+	seen := map[string]bool{p.Owner: true}
+	var readers []string
+	for _, reader := range p.Readers {
+		if reader != "" && !seen[reader] {
+			seen[reader] = true
+			readers = append(readers, reader)
+		}
+	}
+	sort.Strings(readers)
+	return readers
+}
+
+func (p AccessPolicy) validate() error {
+	if !hasScheme(p.Owner) {
+		return fmt.Errorf("access policy needs the owner's WebID")
+	}
+	for _, reader := range p.Readers {
+		if !hasScheme(reader) {
+			return fmt.Errorf("invalid reader WebID %q", reader)
+		}
+	}
+	return nil
+}
+
+func hasScheme(webID string) bool {
+	return strings.HasPrefix(webID, "https://") || strings.HasPrefix(webID, "http://")
+}
+
+// isContainerURL reports whether a resource URL names a container
+func isContainerURL(resourceURL string) bool {
+	return strings.HasSuffix(resourceURL, "/")
+}
+
+// WACGraph renders the policy as the Web Access Control document (.acl) of a
+// resource. For containers the authorizations also apply to every member through
+// acl:default, unless the member has its own .acl.
+func (p AccessPolicy) WACGraph(resourceURL, aclURL string) (*rdf.Graph, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	g := rdf.NewGraph()
+	g.UseCommonPrefixes("acl", "foaf")
+	g.Base = aclURL
+	resource := rdf.IRI(resourceURL)
+
+	authorization := func(name string, modes AccessModes) rdf.Term {
+		node := rdf.IRI(aclURL + "#" + name)
+		g.Add(node, rdfType, acl("Authorization"))
+		g.Add(node, acl("accessTo"), resource)
+		if isContainerURL(resourceURL) {
+			g.Add(node, acl("default"), resource)
+		}
+		for _, mode := range modes.terms() {
+			g.Add(node, acl("mode"), mode)
+		}
+		return node
+	}
+
+	owner := authorization("owner", ownerModes)
+	g.Add(owner, acl("agent"), rdf.IRI(p.Owner))
+	if readers := p.readers(); len(readers) > 0 {
+		shared := authorization("shared", readerModes)
+		for _, reader := range readers {
+			g.Add(shared, acl("agent"), rdf.IRI(reader))
+		}
+	}
+	if p.Public {
+		public := authorization("public", readerModes)
+		g.Add(public, acl("agentClass"), rdf.IRI(rdf.FOAFNS+"Agent"))
+	}
+	return g, nil
+}
+
+// ACPGraph renders the policy as the Access Control Resource (.acr) of a resource
+// for servers that implement Access Control Policies. For containers the same
+// policies are attached as acp:memberAccessControl.
+func (p AccessPolicy) ACPGraph(resourceURL, acrURL string) (*rdf.Graph, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	g := rdf.NewGraph()
+	g.UseCommonPrefixes("acl", "acp")
+	g.Base = acrURL
+	local := func(name string) rdf.Term { return rdf.IRI(acrURL + "#" + name) }
+
+	root := local("root")
+	g.Add(root, rdfType, acp("AccessControlResource"))
+	g.Add(root, acp("resource"), rdf.IRI(resourceURL))
+
+	control := func(name string, modes AccessModes, agents ...rdf.Term) {
+		access, policy, matcher := local(name+"Access"), local(name+"Policy"), local(name+"Matcher")
+		g.Add(root, acp("accessControl"), access)
+		if isContainerURL(resourceURL) {
+			g.Add(root, acp("memberAccessControl"), access)
+		}
+		g.Add(access, rdfType, acp("AccessControl"))
+		g.Add(access, acp("apply"), policy)
+		g.Add(policy, rdfType, acp("Policy"))
+		for _, mode := range modes.terms() {
+			g.Add(policy, acp("allow"), mode)
+		}
+		g.Add(policy, acp("anyOf"), matcher)
+		g.Add(matcher, rdfType, acp("Matcher"))
+		for _, agent := range agents {
+			g.Add(matcher, acp("agent"), agent)
+		}
+	}
+
+	control("owner", ownerModes, rdf.IRI(p.Owner))
+	if readers := p.readers(); len(readers) > 0 {
+		agents := make([]rdf.Term, len(readers))
+		for i, reader := range readers {
+			agents[i] = rdf.IRI(reader)
+		}
+		control("shared", readerModes, agents...)
+	}
+	if p.Public {
+		control("public", readerModes, acp("PublicAgent"))
+	}
+	return g, nil
+}
+
+// WAC renders the policy as a Turtle .acl document.
+func (p AccessPolicy) WAC(resourceURL, aclURL string) (string, error) {
+	g, err := p.WACGraph(resourceURL, aclURL)
+	if err != nil {
+		return "", err
+	}
+	return g.Turtle(), nil
+}
+
+// ACP renders the policy as a Turtle Access Control Resource.
+func (p AccessPolicy) ACP(resourceURL, acrURL string) (string, error) {
+	g, err := p.ACPGraph(resourceURL, acrURL)
+	if err != nil {
+		return "", err
+	}
+	return g.Turtle(), nil
+}
+
+// PolicyFromWAC reads back the policy a WAC document grants on a resource: the
+// agent with Control is the owner, other agents with Read are readers, and a
+// foaf:Agent read grant makes it public.
+func PolicyFromWAC(g *rdf.Graph, resourceURL string) AccessPolicy {
+	var policy AccessPolicy
+	for _, authorization := range g.SubjectsOfType(rdf.ACLNS + "Authorization") {
+		if !wacApplies(g, authorization, resourceURL) {
+			continue
+		}
+		var modes AccessModes
+		for _, mode := range g.Objects(authorization, acl("mode")) {
+			modes.add(mode)
+		}
+		policy.collect(modes, g.Objects(authorization, acl("agent")),
+			g.Has(authorization, acl("agentClass"), rdf.IRI(rdf.FOAFNS+"Agent")))
+	}
+	policy.Readers = policy.readers()
+	return policy
+}
+
+// PolicyFromACP reads back the policy an Access Control Resource grants on its resource.
+func PolicyFromACP(g *rdf.Graph) AccessPolicy {
+	var policy AccessPolicy
+	for _, root := range g.SubjectsOfType(rdf.ACPNS + "AccessControlResource") {
+		for _, access := range g.Objects(root, acp("accessControl")) {
+			for _, p := range g.Objects(access, acp("apply")) {
+				var modes AccessModes
+				for _, mode := range g.Objects(p, acp("allow")) {
+					modes.add(mode)
+				}
+				var agents []rdf.Term
+				public := false
+				for _, matcher := range append(g.Objects(p, acp("anyOf")), g.Objects(p, acp("allOf"))...) {
+					for _, agent := range g.Objects(matcher, acp("agent")) {
+						if agent == acp("PublicAgent") {
+							public = true
+						} else {
+							agents = append(agents, agent)
+						}
+					}
+				}
+				policy.collect(modes, agents, public)
+			}
+		}
+	}
+	policy.Readers = policy.readers()
+	return policy
+}
+
+// collect folds one authorization or policy into the summary
+func (p *AccessPolicy) collect(modes AccessModes, agents []rdf.Term, public bool) {
+	for _, agent := range agents {
+		switch {
+		case modes.Control && p.Owner == "":
+			p.Owner = agent.Value
+		case modes.Read:
+			p.Readers = append(p.Readers, agent.Value)
+		}
+	}
+	if public && modes.Read {
+		p.Public = true
+	}
+}
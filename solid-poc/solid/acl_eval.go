@@ -0,0 +1,147 @@
+package solid
+
+import (
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// EvaluateWAC returns the modes a WAC document grants agent (a WebID, or "" for an
+// unauthenticated request) on a resource. The document is the one in effect for the
+// resource: its own .acl, or the nearest container's, whose acl:default rules then apply.
+// Group (acl:agentGroup) and origin restrictions are not evaluated; they grant nothing.
+func EvaluateWAC(g *rdf.Graph, resourceURL, agent string) AccessModes {
+	var modes AccessModes
+	for _, authorization := range g.SubjectsOfType(rdf.ACLNS + "Authorization") {
+		if !wacApplies(g, authorization, resourceURL) || !wacMatchesAgent(g, authorization, agent) {
+			continue
+		}
+		for _, mode := range g.Objects(authorization, acl("mode")) {
+			modes.add(mode)
+		}
+	}
+	return modes
+}
+
+// wacApplies checks acl:accessTo, and acl:default for members of a container
+func wacApplies(g *rdf.Graph, authorization rdf.Term, resourceURL string) bool {
+	if g.Has(authorization, acl("accessTo"), rdf.IRI(resourceURL)) {
+		return true
+	}
+	for _, container := range g.Objects(authorization, acl("default")) {
+		if isMember(resourceURL, container.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func wacMatchesAgent(g *rdf.Graph, authorization rdf.Term, agent string) bool {
+	if agent != "" && g.Has(authorization, acl("agent"), rdf.IRI(agent)) {
+		return true
+	}
+	for _, class := range g.Objects(authorization, acl("agentClass")) {
+		switch class.Value {
+		case rdf.FOAFNS + "Agent":
+			return true
+		case rdf.ACLNS + "AuthenticatedAgent":
+			if agent != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EvaluateACP returns the modes an Access Control Resource grants agent on a resource:
+// acp:accessControl for the resource itself, acp:memberAccessControl for resources
+// inside it when it is a container. Denied modes win over allowed ones. Matchers on
+// the client, issuer or verifiable credentials are never satisfied, since only the
+// agent is known.
+func EvaluateACP(g *rdf.Graph, resourceURL, agent string) AccessModes {
+	var allowed, denied AccessModes
+	for _, root := range g.SubjectsOfType(rdf.ACPNS + "AccessControlResource") {
+		var controls []rdf.Term
+		for _, resource := range g.Objects(root, acp("resource")) {
+			switch {
+			case resource.Value == resourceURL:
+				controls = append(controls, g.Objects(root, acp("accessControl"))...)
+			case isMember(resourceURL, resource.Value):
+				controls = append(controls, g.Objects(root, acp("memberAccessControl"))...)
+			}
+		}
+		for _, control := range controls {
+			for _, policy := range g.Objects(control, acp("apply")) {
+				if !acpSatisfied(g, policy, agent) {
+					continue
+				}
+				for _, mode := range g.Objects(policy, acp("allow")) {
+					allowed.add(mode)
+				}
+				for _, mode := range g.Objects(policy, acp("deny")) {
+					denied.add(mode)
+				}
+			}
+		}
+	}
+	for _, mode := range denied.terms() {
+		allowed.remove(mode)
+	}
+	return allowed
+}
+
+// acpSatisfied applies acp:allOf, acp:anyOf and acp:noneOf; a policy needs allOf or anyOf
+func acpSatisfied(g *rdf.Graph, policy rdf.Term, agent string) bool {
+	allOf, anyOf := g.Objects(policy, acp("allOf")), g.Objects(policy, acp("anyOf"))
+	if len(allOf) == 0 && len(anyOf) == 0 {
+		return false
+	}
+	for _, matcher := range allOf {
+		if !acpMatches(g, matcher, agent) {
+			return false
+		}
+	}
+	if len(anyOf) > 0 {
+		matched := false
+		for _, matcher := range anyOf {
+			if acpMatches(g, matcher, agent) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, matcher := range g.Objects(policy, acp("noneOf")) {
+		if acpMatches(g, matcher, agent) {
+			return false
+		}
+	}
+	return true
+}
+
+// acpMatches checks a matcher's acp:agent values
+func acpMatches(g *rdf.Graph, matcher rdf.Term, agent string) bool {
+	for _, attribute := range []string{"client", "issuer", "vc"} {
+		if len(g.Objects(matcher, acp(attribute))) > 0 {
+			return false
+		}
+	}
+	for _, value := range g.Objects(matcher, acp("agent")) {
+		switch {
+		case value == acp("PublicAgent"):
+			return true
+		case value == acp("AuthenticatedAgent") && agent != "":
+			return true
+		case agent != "" && value.Value == agent:
+			return true
+		}
+	}
+	return false
+}
+
+// isMember reports whether resourceURL lies inside (not at) containerURL
+func isMember(resourceURL, containerURL string) bool {
+	return isContainerURL(containerURL) && resourceURL != containerURL && strings.HasPrefix(resourceURL, containerURL)
+}
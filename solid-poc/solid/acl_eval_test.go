@@ -0,0 +1,160 @@
+package solid
+
+import (
+	"testing"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+const (
+	testOwner    = "https://alice.example/profile/card#me"
+	testReader   = "https://bob.example/profile/card#me"
+	testStranger = "https://mallory.example/profile/card#me"
+)
+
+var noModes AccessModes
+
+// policyDocuments renders a policy as Turtle in both access control systems and
+// parses it back, returning an evaluator for each
+func policyDocuments(t *testing.T, policy AccessPolicy, resourceURL string) map[string]func(resourceURL, agent string) AccessModes {
+	t.Helper()
+	wac, err := policy.WAC(resourceURL, resourceURL+".acl")
+	if err != nil {
+		t.Fatalf("WAC: %v", err)
+	}
+	wacGraph, err := rdf.ParseTurtle([]byte(wac), resourceURL+".acl")
+	if err != nil {
+		t.Fatalf("parsing the .acl: %v\n%s", err, wac)
+	}
+	acpDoc, err := policy.ACP(resourceURL, resourceURL+".acr")
+	if err != nil {
+		t.Fatalf("ACP: %v", err)
+	}
+	acpGraph, err := rdf.ParseTurtle([]byte(acpDoc), resourceURL+".acr")
+	if err != nil {
+		t.Fatalf("parsing the .acr: %v\n%s", err, acpDoc)
+	}
+	return map[string]func(string, string) AccessModes{
+		"WAC": func(target, agent string) AccessModes { return EvaluateWAC(wacGraph, target, agent) },
+		"ACP": func(target, agent string) AccessModes { return EvaluateACP(acpGraph, target, agent) },
+	}
+}
+
+func TestPolicyEvaluation(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy AccessPolicy
+		want   map[string]AccessModes // by agent; "" is unauthenticated
+	}{
+		{
+			name:   "owner-only",
+			policy: OwnerOnly(testOwner),
+			want: map[string]AccessModes{
+				testOwner:    ownerModes,
+				testReader:   noModes,
+				testStranger: noModes,
+				"":           noModes,
+			},
+		},
+		{
+			name:   "shared",
+			policy: SharedWith(testOwner, testReader),
+			want: map[string]AccessModes{
+				testOwner:    ownerModes,
+				testReader:   readerModes,
+				testStranger: noModes,
+				"":           noModes,
+			},
+		},
+		{
+			name:   "public-read",
+			policy: PublicRead(testOwner),
+			want: map[string]AccessModes{
+				testOwner:    ownerModes,
+				testReader:   readerModes,
+				testStranger: readerModes,
+				"":           readerModes,
+			},
+		},
+	}
+
+	const (
+		document  = "https://alice.example/private/location-tracker/location.ttl"
+		container = "https://alice.example/private/location-tracker/"
+	)
+
+	for _, tt := range tests {
+		if got := tt.policy.Visibility(); got != tt.name {
+			t.Errorf("%s: Visibility() = %q", tt.name, got)
+		}
+
+		t.Run(tt.name+"/document", func(t *testing.T) {
+			for system, evaluate := range policyDocuments(t, tt.policy, document) {
+				for agent, want := range tt.want {
+					if got := evaluate(document, agent); got != want {
+						t.Errorf("%s: %q on the document = %s, want %s", system, agent, got, want)
+					}
+					// A document's own rules never reach its neighbours
+					if got := evaluate(container+"other.ttl", agent); got != noModes {
+						t.Errorf("%s: %q on a sibling = %s, want none", system, agent, got)
+					}
+				}
+			}
+		})
+
+		t.Run(tt.name+"/container", func(t *testing.T) {
+			for system, evaluate := range policyDocuments(t, tt.policy, container) {
+				for agent, want := range tt.want {
+					for _, target := range []string{
+						container,
+						container + "location.ttl",
+						container + "2025/11/12/error.ttl",
+						container + "archive/",
+					} {
+						if got := evaluate(target, agent); got != want {
+							t.Errorf("%s: %q on %s = %s, want %s", system, agent, target, got, want)
+						}
+					}
+					// acl:default / acp:memberAccessControl only reach down the tree
+					for _, target := range []string{"https://alice.example/private/", "https://alice.example/private/location-tracker-old/x.ttl"} {
+						if got := evaluate(target, agent); got != noModes {
+							t.Errorf("%s: %q on %s = %s, want none", system, agent, target, got)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestACPDenyWins checks an acp:deny policy overrides an allow for the same agent
+func TestACPDenyWins(t *testing.T) {
+	const resource = "https://alice.example/notes/"
+	acr := `@prefix acp: <http://www.w3.org/ns/solid/acp#> .
+@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+<#root> a acp:AccessControlResource ;
+    acp:resource <` + resource + `> ;
+    acp:accessControl <#access> ;
+    acp:memberAccessControl <#access> .
+<#access> acp:apply <#readAll>, <#noStranger> .
+<#readAll> acp:allow acl:Read, acl:Write ; acp:anyOf <#everyone> .
+<#everyone> acp:agent acp:AuthenticatedAgent .
+<#noStranger> acp:deny acl:Write ; acp:anyOf <#stranger> .
+<#stranger> acp:agent <` + testStranger + `> .
+`
+	g, err := rdf.ParseTurtle([]byte(acr), resource+".acr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{resource, resource + "note.ttl"} {
+		if got, want := EvaluateACP(g, target, testReader), (AccessModes{Read: true, Append: true, Write: true}); got != want {
+			t.Errorf("reader on %s = %s, want %s", target, got, want)
+		}
+		if got, want := EvaluateACP(g, target, testStranger), (AccessModes{Read: true, Append: true}); got != want {
+			t.Errorf("stranger on %s = %s, want %s", target, got, want)
+		}
+		if got := EvaluateACP(g, target, ""); got != noModes {
+			t.Errorf("unauthenticated on %s = %s, want none", target, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package solid
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Client reads and writes pod resources with a DPoP-bound access token.
+type Client struct {
+	HTTP        *http.Client
+	AccessToken string
+	Key         *DPoPKey
+}
+
+// NewClient returns a pod client for the given token and DPoP key.
+func NewClient(accessToken string, key *DPoPKey) *Client {
+	return &Client{HTTP: DefaultHTTPClient, AccessToken: accessToken, Key: key}
+}
+
+// GetResource fetches a resource, returning its body and content type.
+func (c *Client) GetResource(ctx context.Context, resourceURL, accept string) ([]byte, string, error) {
+	resource, err := c.Get(ctx, resourceURL, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	return resource.Body, resource.ContentType, nil
+}
+
+// PutResource creates or replaces a resource. Solid servers create missing parent containers.
+func (c *Client) PutResource(ctx context.Context, resourceURL, contentType string, body []byte) error {
+	_, err := c.Put(ctx, resourceURL, contentType, body, Conditions{})
+	return err
+}
+
+// do sends an authenticated request, retrying once when the server asks for a DPoP nonce
+func (c *Client) do(ctx context.Context, method, resourceURL string, header http.Header, body []byte) (*http.Response, error) {
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	dpopNonce := ""
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, resourceURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if c.AccessToken != "" && c.Key != nil {
+			proof, err := c.Key.Proof(method, resourceURL, c.AccessToken, dpopNonce)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "DPoP "+c.AccessToken)
+			req.Header.Set("DPoP", proof)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s failed: %w", method, resourceURL, err)
+		}
+
+		nonce := resp.Header.Get("DPoP-Nonce")
+		if resp.StatusCode == http.StatusUnauthorized && nonce != "" && dpopNonce == "" && attempt == 0 {
+			resp.Body.Close()
+			dpopNonce = nonce
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// storagePattern matches pim:storage statements in a Turtle WebID profile
+var storagePattern = regexp.MustCompile(`(?:pim:storage|space:storage|<http://www\.w3\.org/ns/pim/space#storage>)\s+<([^>]+)>`)
+
+// DiscoverStorage returns the pod root advertised by a WebID profile (pim:storage).
+// When the profile doesn't list one, the WebID's origin is assumed to be the pod root.
+func DiscoverStorage(ctx context.Context, httpClient *http.Client, webID string) (string, error) {
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	parsed, err := url.Parse(webID)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid WebID %q", webID)
+	}
+	fallback := parsed.Scheme + "://" + parsed.Host + "/"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create profile request: %w", err)
+	}
+	req.Header.Set("Accept", "text/turtle")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fallback, fmt.Errorf("failed to fetch WebID profile: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fallback, fmt.Errorf("WebID profile returned status %d", resp.StatusCode)
+	}
+
+	profile, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fallback, fmt.Errorf("failed to read WebID profile: %w", err)
+	}
+
+	match := storagePattern.FindSubmatch(profile)
+	if match == nil {
+		return fallback, nil
+	}
+	storage, err := parsed.Parse(string(match[1]))
+	if err != nil {
+		return fallback, nil
+	}
+	root := storage.String()
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+	return root, nil
+}
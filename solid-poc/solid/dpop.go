@@ -0,0 +1,137 @@
+package solid
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+)
+
+// DPoPKey is the ES256 key pair a session binds its tokens to (RFC 9449).
+type DPoPKey struct {
+	private *ecdsa.PrivateKey
+}
+
+// NewDPoPKey generates a fresh P-256 key pair.
+func NewDPoPKey() (*DPoPKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DPoP key: %w", err)
+	}
+	return &DPoPKey{private: private}, nil
+}
+
+// MarshalPrivateKey encodes the private key (SEC 1 DER) so a session can outlive the process.
+func (k *DPoPKey) MarshalPrivateKey() ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(k.private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DPoP key: %w", err)
+	}
+	return der, nil
+}
+
+// ParseDPoPKey decodes a key written by MarshalPrivateKey.
+func ParseDPoPKey(der []byte) (*DPoPKey, error) {
+	private, err := x509.ParseECPrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DPoP key: %w", err)
+	}
+	if private.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("DPoP key is not a P-256 key")
+	}
+	return &DPoPKey{private: private}, nil
+}
+
+// PublicJWK returns the public key as a JSON Web Key.
+func (k *DPoPKey) PublicJWK() JWK {
+	return JWK{
+		KeyType: "EC",
+		Curve:   "P-256",
+		X:       base64.RawURLEncoding.EncodeToString(padCoordinate(k.private.PublicKey.X)),
+		Y:       base64.RawURLEncoding.EncodeToString(padCoordinate(k.private.PublicKey.Y)),
+	}
+}
+
+// Thumbprint returns the RFC 7638 JWK thumbprint, which DPoP-bound tokens carry as cnf.jkt.
+func (k *DPoPKey) Thumbprint() string {
+	thumbprint, _ := JWKThumbprint(k.PublicJWK())
+	return thumbprint
+}
+
+// Proof creates a DPoP proof JWT for one request. accessToken and nonce are optional.
+func (k *DPoPKey) Proof(method, targetURL, accessToken, nonce string) (string, error) {
+	// htu excludes query and fragment (RFC 9449 4.2)
+	htu := targetURL
+	if parsed, err := url.Parse(targetURL); err == nil {
+		parsed.RawQuery = ""
+		parsed.Fragment = ""
+		htu = parsed.String()
+	}
+
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]interface{}{
+		"typ": "dpop+jwt",
+		"alg": "ES256",
+		"jwk": k.PublicJWK(),
+	}
+	claims := map[string]interface{}{
+		"jti": jti,
+		"htm": method,
+		"htu": htu,
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	return k.sign(header, claims)
+}
+
+// sign encodes and signs a compact JWS with ES256
+func (k *DPoPKey) sign(header, claims map[string]interface{}) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, k.private, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign DPoP proof: %w", err)
+	}
+
+	// JWS ES256 signatures are the fixed-width concatenation r || s
+	signature := append(padCoordinate(r), padCoordinate(s)...)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// padCoordinate left-pads a P-256 integer to 32 bytes
+func padCoordinate(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) >= 32 {
+		return b
+	}
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
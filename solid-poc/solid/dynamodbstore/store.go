@@ -0,0 +1,164 @@
+// Package dynamodbstore keeps Solid sessions in a DynamoDB table, sealed with a
+// solid.SessionCipher so tokens never reach the table in the clear.
+//
+// The table is keyed by "session_handle" (string). Enable DynamoDB TTL on
+// "expires_at" so abandoned sessions are removed.
+package dynamodbstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+)
+
+// Store implements solid.SessionStore using DynamoDB. Items are keyed by session
+// handle (a hash of the session ID), hold the sealed session in "record" and carry
+// an "expires_at" epoch for DynamoDB TTL.
+type Store struct {
+	client      *dynamodb.Client
+	tableName   string
+	cipher      *solid.SessionCipher
+	maxLifetime time.Duration
+}
+
+// New creates a DynamoDB session store. maxLifetime sets the TTL DynamoDB deletes
+// abandoned items after, counted from session creation.
+func New(client *dynamodb.Client, tableName string, cipher *solid.SessionCipher, maxLifetime time.Duration) *Store {
+	return &Store{
+		client:      client,
+		tableName:   tableName,
+		cipher:      cipher,
+		maxLifetime: maxLifetime,
+	}
+}
+
+// Get retrieves and decrypts a session
+func (s *Store) Get(ctx context.Context, id string) (*solid.Session, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"session_handle": &dynamodbtypes.AttributeValueMemberS{Value: solid.SessionHandle(id)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Solid session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, solid.ErrSessionNotFound
+	}
+
+	session, err := s.open(result.Item)
+	if err != nil {
+		return nil, err
+	}
+	if session.ID != id {
+		return nil, solid.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// Put seals and stores a session
+func (s *Store) Put(ctx context.Context, session *solid.Session) error {
+	if s.client == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	record, err := s.cipher.Seal(session)
+	if err != nil {
+		return err
+	}
+	expiresAt := session.CreatedAt.Add(s.maxLifetime)
+	if session.ExpiresAt.After(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]dynamodbtypes.AttributeValue{
+			"session_handle": &dynamodbtypes.AttributeValueMemberS{Value: solid.SessionHandle(session.ID)},
+			"record":         &dynamodbtypes.AttributeValueMemberS{Value: string(record)},
+			"expires_at":     &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save Solid session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a session
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if s.client == nil {
+		return fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"session_handle": &dynamodbtypes.AttributeValueMemberS{Value: solid.SessionHandle(id)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete Solid session: %w", err)
+	}
+	return nil
+}
+
+// List scans all sessions. Items that no longer decrypt (e.g. after a key change) are
+// skipped and left for the TTL to remove.
+func (s *Store) List(ctx context.Context) ([]*solid.Session, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	var sessions []*solid.Session
+	var lastEvaluatedKey map[string]dynamodbtypes.AttributeValue
+
+	for {
+		input := &dynamodb.ScanInput{
+			TableName: aws.String(s.tableName),
+		}
+		if lastEvaluatedKey != nil {
+			input.ExclusiveStartKey = lastEvaluatedKey
+		}
+
+		result, err := s.client.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan Solid sessions: %w", err)
+		}
+
+		for _, item := range result.Items {
+			if session, err := s.open(item); err == nil {
+				sessions = append(sessions, session)
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return sessions, nil
+}
+
+// open decrypts the sealed record of an item
+func (s *Store) open(item map[string]dynamodbtypes.AttributeValue) (*solid.Session, error) {
+	record, ok := item["record"].(*dynamodbtypes.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("Solid session item has no record")
+	}
+	return s.cipher.Open([]byte(record.Value))
+}
@@ -0,0 +1,119 @@
+package solid
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Shorthands for the vocabulary terms the mappings use
+func schema(local string) rdf.Term  { return rdf.IRI(rdf.SchemaNS + local) }
+func geo(local string) rdf.Term     { return rdf.IRI(rdf.GeoNS + local) }
+func dcterms(local string) rdf.Term { return rdf.IRI(rdf.DCTermsNS + local) }
+
+var rdfType = rdf.IRI(rdf.RDFType)
+
+// newDocument starts a graph with the prefixes our pod documents declare
+func newDocument() *rdf.Graph {
+	g := rdf.NewGraph()
+	g.UseCommonPrefixes("schema", "geo", "xsd", "dcterms")
+	return g
+}
+
+// addString adds a string literal unless it is empty
+func addString(g *rdf.Graph, s, p rdf.Term, value string) {
+	if value != "" {
+		g.Add(s, p, rdf.Literal(value))
+	}
+}
+
+// addNode adds a typed blank node as the object of s p and returns it
+func addNode(g *rdf.Graph, s, p rdf.Term, class string) rdf.Term {
+	node := g.NewBlank()
+	g.Add(s, p, node)
+	g.Add(node, rdfType, schema(class))
+	return node
+}
+
+// addPropertyValue adds a schema:PropertyValue with the given ID
+func addPropertyValue(g *rdf.Graph, s rdf.Term, id string, value rdf.Term) {
+	node := addNode(g, s, schema("additionalProperty"), "PropertyValue")
+	g.Add(node, schema("propertyID"), rdf.Literal(id))
+	g.Add(node, schema("value"), value)
+}
+
+// documentRoot finds the top-level node of a class, i.e. one not nested under another node
+func documentRoot(g *rdf.Graph, class string) (rdf.Term, error) {
+	for _, subject := range g.SubjectsOfType(class) {
+		if len(g.Match(rdf.Term{}, rdf.Term{}, subject)) == 0 {
+			return subject, nil
+		}
+	}
+	return rdf.Term{}, fmt.Errorf("no top-level %s in document", class)
+}
+
+// stringValue returns the lexical value of s p, or "" when absent
+func stringValue(g *rdf.Graph, s, p rdf.Term) string {
+	if o, ok := g.Object(s, p); ok && !o.IsBlank() {
+		return o.Value
+	}
+	return ""
+}
+
+// stringValues returns every lexical value of s p
+func stringValues(g *rdf.Graph, s, p rdf.Term) []string {
+	var values []string
+	for _, o := range g.Objects(s, p) {
+		if !o.IsBlank() {
+			values = append(values, o.Value)
+		}
+	}
+	return values
+}
+
+// floatValue returns the number at s p
+func floatValue(g *rdf.Graph, s, p rdf.Term) (float64, bool, error) {
+	o, ok := g.Object(s, p)
+	if !ok {
+		return 0, false, nil
+	}
+	f, err := o.Float()
+	if err != nil {
+		return 0, true, fmt.Errorf("%s: %w", p.Value, err)
+	}
+	return f, true, nil
+}
+
+// timeValue returns the first date/time found among the predicates
+func timeValue(g *rdf.Graph, s rdf.Term, predicates ...rdf.Term) (time.Time, error) {
+	for _, p := range predicates {
+		if o, ok := g.Object(s, p); ok {
+			t, err := o.Time()
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%s: %w", p.Value, err)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// propertyValue finds the value of the schema:PropertyValue with the given ID
+func propertyValue(g *rdf.Graph, s rdf.Term, id string) (rdf.Term, bool) {
+	for _, node := range g.Objects(s, schema("additionalProperty")) {
+		if stringValue(g, node, schema("propertyID")) == id {
+			return g.Object(node, schema("value"))
+		}
+	}
+	return rdf.Term{}, false
+}
+
+// nodeOf returns the first node object of s p
+func nodeOf(g *rdf.Graph, s, p rdf.Term) (rdf.Term, bool) {
+	o, ok := g.Object(s, p)
+	if !ok || o.IsLiteral() {
+		return rdf.Term{}, false
+	}
+	return o, true
+}
@@ -0,0 +1,243 @@
+package solid
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWK is a JSON Web Key as published in a provider's jwks_uri.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKey converts the JWK to an *rsa.PublicKey or *ecdsa.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		if n.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key too short (%d bits)", n.BitLen())
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// decodeBigInt decodes an unpadded base64url big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// FetchJWKS downloads a key set.
+func FetchJWKS(ctx context.Context, httpClient *http.Client, jwksURI string) (*JWKS, error) {
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS returned status %d", resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	return &set, nil
+}
+
+// issuerKeys is the cached discovery document and key set of one issuer
+type issuerKeys struct {
+	meta      *ProviderMetadata
+	keys      []JWK
+	fetchedAt time.Time
+}
+
+// KeyCache caches discovery documents and JWKS per issuer.
+// Keys are refreshed after TTL, or early when a token names an unknown kid
+// (at most once per MinRefresh, so forged kids can't hammer the provider).
+type KeyCache struct {
+	HTTP       *http.Client
+	TTL        time.Duration
+	MinRefresh time.Duration
+
+	mu      sync.Mutex
+	issuers map[string]*issuerKeys
+}
+
+// NewKeyCache returns a cache with a one hour TTL.
+func NewKeyCache(httpClient *http.Client) *KeyCache {
+	return &KeyCache{
+		HTTP:       httpClient,
+		TTL:        time.Hour,
+		MinRefresh: 30 * time.Second,
+		issuers:    make(map[string]*issuerKeys),
+	}
+}
+
+// Metadata returns the (cached) discovery document of an issuer.
+func (c *KeyCache) Metadata(ctx context.Context, issuer string) (*ProviderMetadata, error) {
+	entry, err := c.load(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	return entry.meta, nil
+}
+
+// Key returns the issuer's key matching kid and alg, refreshing the set once if it isn't found.
+func (c *KeyCache) Key(ctx context.Context, issuer, kid, alg string) (crypto.PublicKey, error) {
+	entry, err := c.load(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := selectKey(entry.keys, kid, alg); ok {
+		return key.PublicKey()
+	}
+
+	entry, err = c.load(ctx, issuer, true)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := selectKey(entry.keys, kid, alg); ok {
+		return key.PublicKey()
+	}
+	return nil, fmt.Errorf("no %s key with kid %q for issuer %s", alg, kid, issuer)
+}
+
+// load returns the cached entry, fetching when missing, expired or forced
+func (c *KeyCache) load(ctx context.Context, issuer string, force bool) (*issuerKeys, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.issuers == nil {
+		c.issuers = make(map[string]*issuerKeys)
+	}
+	entry, ok := c.issuers[issuer]
+	if ok {
+		age := time.Since(entry.fetchedAt)
+		if age < c.TTL && (!force || age < c.MinRefresh) {
+			return entry, nil
+		}
+	}
+
+	meta := (*ProviderMetadata)(nil)
+	if ok {
+		meta = entry.meta
+	} else {
+		discovered, err := Discover(ctx, c.HTTP, issuer)
+		if err != nil {
+			return nil, err
+		}
+		meta = discovered
+	}
+	if meta.JWKSURI == "" {
+		return nil, fmt.Errorf("issuer %s publishes no jwks_uri", issuer)
+	}
+
+	set, err := FetchJWKS(ctx, c.HTTP, meta.JWKSURI)
+	if err != nil {
+		if ok {
+			// Keep serving the previous keys if the provider is briefly unreachable
+			return entry, nil
+		}
+		return nil, err
+	}
+
+	entry = &issuerKeys{meta: meta, keys: set.Keys, fetchedAt: time.Now()}
+	c.issuers[issuer] = entry
+	return entry, nil
+}
+
+// selectKey finds a signing key by kid (or the only compatible key when kid is empty)
+func selectKey(keys []JWK, kid, alg string) (JWK, bool) {
+	keyType := "RSA"
+	if alg == "ES256" {
+		keyType = "EC"
+	}
+
+	var candidates []JWK
+	for _, key := range keys {
+		if key.KeyType != keyType || (key.Use != "" && key.Use != "sig") || (key.Algorithm != "" && key.Algorithm != alg) {
+			continue
+		}
+		if kid != "" && key.KeyID == kid {
+			return key, true
+		}
+		candidates = append(candidates, key)
+	}
+	if kid == "" && len(candidates) == 1 {
+		return candidates[0], true
+	}
+	return JWK{}, false
+}
@@ -0,0 +1,417 @@
+package solid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Errors a StatusError matches with errors.Is
+var (
+	ErrNotFound           = errors.New("resource not found")
+	ErrConflict           = errors.New("conflicting resource state")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// StatusError reports an unexpected status from the pod.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d", e.Method, e.URL, e.StatusCode)
+}
+
+// Is maps 404, 409 and 412 onto ErrNotFound, ErrConflict and ErrPreconditionFailed.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// Conditions make a request conditional on the resource's current ETag.
+type Conditions struct {
+	IfMatch     string // only if the resource still has this ETag ("*": only if it exists)
+	IfNoneMatch string // "*": only if the resource does not exist yet
+}
+
+// apply sets the conditional request headers
+func (c Conditions) apply(header http.Header) {
+	if c.IfMatch != "" {
+		header.Set("If-Match", c.IfMatch)
+	}
+	if c.IfNoneMatch != "" {
+		header.Set("If-None-Match", c.IfNoneMatch)
+	}
+}
+
+// Resource is a fetched pod resource.
+type Resource struct {
+	URL         string
+	ContentType string
+	ETag        string
+	Container   bool   // the server typed it as an ldp:Container
+	ACL         string // its access control document (Link rel="acl"), when advertised
+	Body        []byte
+}
+
+// Link relation types used when creating containers
+const (
+	ldpBasicContainer = rdf.LDPNS + "BasicContainer"
+	ldpContainer      = rdf.LDPNS + "Container"
+)
+
+// Get fetches a resource with its ETag.
+func (c *Client) Get(ctx context.Context, resourceURL, accept string) (*Resource, error) {
+	header := http.Header{}
+	if accept != "" {
+		header.Set("Accept", accept)
+	}
+	resp, err := c.do(ctx, http.MethodGet, resourceURL, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Method: http.MethodGet, URL: resourceURL, StatusCode: resp.StatusCode}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", resourceURL, err)
+	}
+	resource := resourceFromResponse(resourceURL, resp)
+	resource.Body = data
+	return resource, nil
+}
+
+// Head fetches a resource's metadata (content type, ETag, container type) without the body.
+func (c *Client) Head(ctx context.Context, resourceURL string) (*Resource, error) {
+	resp, err := c.do(ctx, http.MethodHead, resourceURL, http.Header{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Method: http.MethodHead, URL: resourceURL, StatusCode: resp.StatusCode}
+	}
+	return resourceFromResponse(resourceURL, resp), nil
+}
+
+// GetGraph fetches an RDF resource as Turtle and parses it against its URL.
+// The returned ETag can be passed to Put or Patch for a read-modify-write.
+func (c *Client) GetGraph(ctx context.Context, resourceURL string) (*rdf.Graph, string, error) {
+	resource, err := c.Get(ctx, resourceURL, rdf.MediaTypeTurtle)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType := resource.ContentType
+	if mediaType == "" {
+		mediaType = rdf.MediaTypeTurtle
+	}
+	g, err := rdf.Parse(resource.Body, mediaType, resourceURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", resourceURL, err)
+	}
+	return g, resource.ETag, nil
+}
+
+// Put creates or replaces a resource and returns its new ETag, when the server sends one.
+func (c *Client) Put(ctx context.Context, resourceURL, contentType string, body []byte, cond Conditions) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	cond.apply(header)
+	return c.write(ctx, http.MethodPut, resourceURL, header, body)
+}
+
+// CreateContainer creates a container (the URL must end in "/").
+// An existing container is left as it is.
+func (c *Client) CreateContainer(ctx context.Context, containerURL string) error {
+	if !strings.HasSuffix(containerURL, "/") {
+		return fmt.Errorf("container URL %s must end with /", containerURL)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", rdf.MediaTypeTurtle)
+	header.Set("Link", fmt.Sprintf("<%s>; rel=\"type\"", ldpBasicContainer))
+	header.Set("If-None-Match", "*")
+	_, err := c.write(ctx, http.MethodPut, containerURL, header, nil)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// Post creates a resource in a container, suggesting a name with slug, and returns
+// the URL the server chose.
+func (c *Client) Post(ctx context.Context, containerURL, slug, contentType string, body []byte) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	if slug != "" {
+		header.Set("Slug", slug)
+	}
+	resp, err := c.do(ctx, http.MethodPost, containerURL, header, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", &StatusError{Method: http.MethodPost, URL: containerURL, StatusCode: resp.StatusCode}
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return "", fmt.Errorf("POST %s returned no usable Location", containerURL)
+	}
+	return location.String(), nil
+}
+
+// Patch applies an N3 Patch or SPARQL Update to an RDF resource, creating it when
+// it does not exist, and returns the new ETag when the server sends one.
+func (c *Client) Patch(ctx context.Context, resourceURL string, patch Patch, cond Conditions) (string, error) {
+	body, contentType, err := patch.Body()
+	if err != nil {
+		return "", err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	cond.apply(header)
+	return c.write(ctx, http.MethodPatch, resourceURL, header, body)
+}
+
+// Delete removes a resource or an empty container.
+func (c *Client) Delete(ctx context.Context, resourceURL string, cond Conditions) error {
+	header := http.Header{}
+	cond.apply(header)
+	_, err := c.write(ctx, http.MethodDelete, resourceURL, header, nil)
+	return err
+}
+
+// ListContainer returns the URLs of a container's members (ldp:contains), child
+// containers ending in "/".
+func (c *Client) ListContainer(ctx context.Context, containerURL string) ([]string, error) {
+	g, _, err := c.GetGraph(ctx, containerURL)
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	for _, member := range g.Objects(rdf.IRI(containerURL), rdf.IRI(rdf.LDPNS+"contains")) {
+		if member.IsIRI() {
+			members = append(members, member.Value)
+		}
+	}
+	return members, nil
+}
+
+// write sends a modifying request and returns the ETag of the result
+func (c *Client) write(ctx context.Context, method, resourceURL string, header http.Header, body []byte) (string, error) {
+	resp, err := c.do(ctx, method, resourceURL, header, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent, http.StatusResetContent:
+		return resp.Header.Get("ETag"), nil
+	default:
+		return "", &StatusError{Method: method, URL: resourceURL, StatusCode: resp.StatusCode}
+	}
+}
+
+// resourceFromResponse reads the metadata headers of a GET or HEAD response
+func resourceFromResponse(resourceURL string, resp *http.Response) *Resource {
+	resource := &Resource{
+		URL:         resourceURL,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	for _, link := range parseLinks(resp.Header.Values("Link")) {
+		switch {
+		case link.rel == "type" && (link.target == ldpContainer || link.target == ldpBasicContainer):
+			resource.Container = true
+		case link.rel == "acl":
+			if target, err := resp.Request.URL.Parse(link.target); err == nil {
+				resource.ACL = target.String()
+			}
+		}
+	}
+	return resource
+}
+
+type link struct {
+	target string
+	rel    string
+}
+
+// parseLinks reads Link headers such as <.acl>; rel="acl", one entry per relation
+func parseLinks(values []string) []link {
+	var links []link
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			start, end := strings.Index(part, "<"), strings.Index(part, ">")
+			if start != 0 || end < 0 {
+				continue
+			}
+			target := part[1:end]
+			for _, param := range strings.Split(part[end+1:], ";") {
+				name, rel, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					links = append(links, link{target: target, rel: r})
+				}
+			}
+		}
+	}
+	return links
+}
+
+// AccessControlSystem is the authorization scheme a pod server uses.
+type AccessControlSystem int
+
+// Access control systems Solid servers implement
+const (
+	WAC AccessControlSystem = iota // Web Access Control (.acl)
+	ACP                            // Access Control Policies (.acr)
+)
+
+// AccessControl finds the access control document of a resource and which system
+// it belongs to. Servers advertise it with Link rel="acl"; an ACP server's
+// Access Control Resource is recognised by its .acr name or ?ext=acr query.
+func (c *Client) AccessControl(ctx context.Context, resourceURL string) (string, AccessControlSystem, error) {
+	resource, err := c.Head(ctx, resourceURL)
+	if err != nil {
+		return "", WAC, err
+	}
+	if resource.ACL == "" {
+		return "", WAC, fmt.Errorf("%s does not advertise an access control document", resourceURL)
+	}
+	if strings.HasSuffix(resource.ACL, ".acr") || strings.Contains(resource.ACL, "ext=acr") {
+		return resource.ACL, ACP, nil
+	}
+	return resource.ACL, WAC, nil
+}
+
+// SetAccess replaces a resource's access control document with the policy, as an
+// .acl or an Access Control Resource depending on the server.
+func (c *Client) SetAccess(ctx context.Context, resourceURL string, policy AccessPolicy) error {
+	aclURL, system, err := c.AccessControl(ctx, resourceURL)
+	if err != nil {
+		return err
+	}
+	var document string
+	if system == ACP {
+		document, err = policy.ACP(resourceURL, aclURL)
+	} else {
+		document, err = policy.WAC(resourceURL, aclURL)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = c.Put(ctx, aclURL, rdf.MediaTypeTurtle, []byte(document), Conditions{})
+	return err
+}
+
+// GetAccess reads the policy from a resource's own access control document. It
+// fails with ErrNotFound when the resource inherits its access from a container.
+func (c *Client) GetAccess(ctx context.Context, resourceURL string) (AccessPolicy, error) {
+	aclURL, system, err := c.AccessControl(ctx, resourceURL)
+	if err != nil {
+		return AccessPolicy{}, err
+	}
+	g, _, err := c.GetGraph(ctx, aclURL)
+	if err != nil {
+		return AccessPolicy{}, err
+	}
+	if system == ACP {
+		return PolicyFromACP(g), nil
+	}
+	return PolicyFromWAC(g, resourceURL), nil
+}
+
+// PatchFormat is the media type a Patch is sent as.
+type PatchFormat string
+
+// Patch formats Solid servers accept
+const (
+	PatchN3     PatchFormat = "text/n3"
+	PatchSPARQL PatchFormat = "application/sparql-update"
+)
+
+// Patch inserts and deletes triples in an RDF resource. Deletes must name triples
+// that exist (the server rejects the whole patch otherwise) and cannot use blank nodes.
+type Patch struct {
+	Format  PatchFormat // defaults to PatchN3
+	Deletes []rdf.Triple
+	Inserts []rdf.Triple
+}
+
+// Body renders the patch document and its content type.
+func (p Patch) Body() ([]byte, string, error) {
+	for _, t := range p.Deletes {
+		if t.Subject.IsBlank() || t.Object.IsBlank() {
+			return nil, "", fmt.Errorf("patch deletes cannot contain blank nodes: %s", t)
+		}
+	}
+
+	var b strings.Builder
+	writeTriples := func(triples []rdf.Triple) {
+		for _, t := range triples {
+			b.WriteString("    " + t.String() + "\n")
+		}
+	}
+
+	switch p.Format {
+	case PatchN3, "":
+		b.WriteString("@prefix solid: <" + rdf.SolidNS + "> .\n\n")
+		b.WriteString("_:patch a solid:InsertDeletePatch")
+		if len(p.Deletes) > 0 {
+			b.WriteString(" ;\n  solid:deletes {\n")
+			writeTriples(p.Deletes)
+			b.WriteString("  }")
+		}
+		if len(p.Inserts) > 0 {
+			b.WriteString(" ;\n  solid:inserts {\n")
+			writeTriples(p.Inserts)
+			b.WriteString("  }")
+		}
+		b.WriteString(" .\n")
+		return []byte(b.String()), string(PatchN3), nil
+	case PatchSPARQL:
+		var parts []string
+		if len(p.Deletes) > 0 {
+			b.WriteString("DELETE DATA {\n")
+			writeTriples(p.Deletes)
+			b.WriteString("}")
+			parts = append(parts, b.String())
+			b.Reset()
+		}
+		if len(p.Inserts) > 0 {
+			b.WriteString("INSERT DATA {\n")
+			writeTriples(p.Inserts)
+			b.WriteString("}")
+			parts = append(parts, b.String())
+		}
+		return []byte(strings.Join(parts, " ;\n") + "\n"), string(PatchSPARQL), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported patch format %q", p.Format)
+	}
+}
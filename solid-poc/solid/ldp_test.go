@@ -0,0 +1,202 @@
+package solid_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid/ldptest"
+)
+
+const testToken = "access-token"
+
+// newPod starts an ldptest server that requires testToken and a client for it
+func newPod(t *testing.T) (*ldptest.Server, *solid.Client) {
+	t.Helper()
+	pod := ldptest.NewServer()
+	t.Cleanup(pod.Close)
+	pod.RequireToken(testToken)
+
+	key, err := solid.NewDPoPKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := solid.NewClient(testToken, key)
+	client.HTTP = pod.Client()
+	return pod, client
+}
+
+func TestLDPContainers(t *testing.T) {
+	pod, client := newPod(t)
+	ctx := context.Background()
+	notes := pod.URL + "notes/"
+
+	if err := client.CreateContainer(ctx, notes); err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if err := client.CreateContainer(ctx, notes); err != nil {
+		t.Fatalf("CreateContainer on an existing container: %v", err)
+	}
+	if err := client.CreateContainer(ctx, pod.URL+"not-a-container"); err == nil {
+		t.Error("CreateContainer accepted a URL without a trailing slash")
+	}
+
+	head, err := client.Head(ctx, notes)
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	if !head.Container {
+		t.Error("notes/ is not typed as an ldp:Container")
+	}
+	if head.ACL != notes+".acl" {
+		t.Errorf("ACL = %q, want %q", head.ACL, notes+".acl")
+	}
+
+	posted, err := client.Post(ctx, notes, "first note", rdf.MediaTypeTurtle, []byte("<#it> a <http://schema.org/Note> ."))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if posted != notes+"first-note" {
+		t.Errorf("Post created %q, want %q", posted, notes+"first-note")
+	}
+	again, err := client.Post(ctx, notes, "first note", rdf.MediaTypeTurtle, nil)
+	if err != nil {
+		t.Fatalf("Post with a taken slug: %v", err)
+	}
+	if again == posted {
+		t.Errorf("Post reused %q for a second resource", posted)
+	}
+	if _, err := client.Put(ctx, notes+"archive/old.ttl", rdf.MediaTypeTurtle, nil, solid.Conditions{}); err != nil {
+		t.Fatalf("Put into a missing container: %v", err)
+	}
+
+	members, err := client.ListContainer(ctx, notes)
+	if err != nil {
+		t.Fatalf("ListContainer: %v", err)
+	}
+	sort.Strings(members)
+	want := []string{notes + "archive/", again, posted}
+	sort.Strings(want)
+	if len(members) != len(want) {
+		t.Fatalf("ListContainer = %v, want %v", members, want)
+	}
+	for i := range want {
+		if members[i] != want[i] {
+			t.Fatalf("ListContainer = %v, want %v", members, want)
+		}
+	}
+
+	root, err := client.ListContainer(ctx, pod.URL)
+	if err != nil {
+		t.Fatalf("ListContainer on the root: %v", err)
+	}
+	if len(root) != 1 || root[0] != notes {
+		t.Errorf("root members = %v, want [%s]", root, notes)
+	}
+}
+
+func TestLDPConditionalPut(t *testing.T) {
+	pod, client := newPod(t)
+	ctx := context.Background()
+	doc := pod.URL + "profile.ttl"
+
+	created, err := client.Put(ctx, doc, rdf.MediaTypeTurtle, []byte(`<#me> <http://schema.org/name> "Alice" .`), solid.Conditions{IfNoneMatch: "*"})
+	if err != nil {
+		t.Fatalf("create with If-None-Match: %v", err)
+	}
+	if created == "" {
+		t.Fatal("create returned no ETag")
+	}
+	_, err = client.Put(ctx, doc, rdf.MediaTypeTurtle, nil, solid.Conditions{IfNoneMatch: "*"})
+	if !errors.Is(err, solid.ErrPreconditionFailed) {
+		t.Errorf("second create = %v, want ErrPreconditionFailed", err)
+	}
+
+	g, etag, err := client.GetGraph(ctx, doc)
+	if err != nil {
+		t.Fatalf("GetGraph: %v", err)
+	}
+	if etag != created {
+		t.Errorf("GET ETag %s, want %s from the PUT", etag, created)
+	}
+	if name := g.Objects(rdf.IRI(doc+"#me"), rdf.IRI(rdf.SchemaNS+"name")); len(name) != 1 || name[0].Value != "Alice" {
+		t.Errorf("schema:name = %v, want Alice", name)
+	}
+
+	updated, err := client.Put(ctx, doc, rdf.MediaTypeTurtle, []byte(`<#me> <http://schema.org/name> "Bob" .`), solid.Conditions{IfMatch: etag})
+	if err != nil {
+		t.Fatalf("update with the current ETag: %v", err)
+	}
+	if updated == etag {
+		t.Error("ETag did not change after an update")
+	}
+
+	// A writer still holding the first ETag lost the race
+	_, err = client.Put(ctx, doc, rdf.MediaTypeTurtle, []byte(`<#me> <http://schema.org/name> "Carol" .`), solid.Conditions{IfMatch: etag})
+	if !errors.Is(err, solid.ErrPreconditionFailed) {
+		t.Errorf("update with a stale ETag = %v, want ErrPreconditionFailed", err)
+	}
+	patch := solid.Patch{Inserts: []rdf.Triple{{
+		Subject:   rdf.IRI(doc + "#me"),
+		Predicate: rdf.IRI(rdf.SchemaNS + "email"),
+		Object:    rdf.Literal("carol@example.org"),
+	}}}
+	if _, err := client.Patch(ctx, doc, patch, solid.Conditions{IfMatch: etag}); !errors.Is(err, solid.ErrPreconditionFailed) {
+		t.Errorf("patch with a stale ETag = %v, want ErrPreconditionFailed", err)
+	}
+	if body, _, _ := pod.Resource("/profile.ttl"); string(body) != `<#me> <http://schema.org/name> "Bob" .` {
+		t.Errorf("stored body = %s, want Bob's update", body)
+	}
+
+	if _, err := client.Put(ctx, pod.URL+"missing.ttl", rdf.MediaTypeTurtle, nil, solid.Conditions{IfMatch: "*"}); !errors.Is(err, solid.ErrPreconditionFailed) {
+		t.Errorf("If-Match: * on a missing resource = %v, want ErrPreconditionFailed", err)
+	}
+
+	// Every request carried the DPoP-bound token and a proof
+	for _, req := range pod.Requests() {
+		if req.Header.Get("Authorization") != "DPoP "+testToken || req.Header.Get("DPoP") == "" {
+			t.Errorf("%s %s sent without a DPoP token and proof", req.Method, req.Path)
+		}
+	}
+}
+
+func TestLDPDelete(t *testing.T) {
+	pod, client := newPod(t)
+	ctx := context.Background()
+	folder := pod.URL + "notes/"
+	doc := folder + "note.ttl"
+
+	etag, err := client.Put(ctx, doc, rdf.MediaTypeTurtle, []byte("<#it> a <http://schema.org/Note> ."), solid.Conditions{})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := client.Delete(ctx, folder, solid.Conditions{}); !errors.Is(err, solid.ErrConflict) {
+		t.Errorf("deleting a non-empty container = %v, want ErrConflict", err)
+	}
+	if err := client.Delete(ctx, doc, solid.Conditions{IfMatch: `"stale"`}); !errors.Is(err, solid.ErrPreconditionFailed) {
+		t.Errorf("delete with a stale ETag = %v, want ErrPreconditionFailed", err)
+	}
+	if err := client.Delete(ctx, doc, solid.Conditions{IfMatch: etag}); err != nil {
+		t.Fatalf("delete with the current ETag: %v", err)
+	}
+	if _, err := client.Get(ctx, doc, ""); !errors.Is(err, solid.ErrNotFound) {
+		t.Errorf("GET after delete = %v, want ErrNotFound", err)
+	}
+	if err := client.Delete(ctx, doc, solid.Conditions{}); !errors.Is(err, solid.ErrNotFound) {
+		t.Errorf("second delete = %v, want ErrNotFound", err)
+	}
+
+	if err := client.Delete(ctx, folder, solid.Conditions{}); err != nil {
+		t.Fatalf("deleting the emptied container: %v", err)
+	}
+	if err := client.Delete(ctx, pod.URL, solid.Conditions{}); err == nil {
+		t.Error("deleted the pod root")
+	}
+	if paths := pod.Paths(); len(paths) != 1 || paths[0] != "/" {
+		t.Errorf("pod holds %v after deleting everything", paths)
+	}
+}
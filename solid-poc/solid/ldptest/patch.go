@@ -0,0 +1,150 @@
+package ldptest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// parsedPatch holds the ground triples a patch inserts and deletes
+type parsedPatch struct {
+	inserts *rdf.Graph
+	deletes *rdf.Graph
+}
+
+var (
+	turtlePrefix = regexp.MustCompile(`(?i)(?:@prefix|\bprefix)\s+([A-Za-z][\w.-]*)?:\s*<([^>]*)>\s*\.?`)
+	n3Formula    = regexp.MustCompile(`(solid:|<http://www\.w3\.org/ns/solid/terms#)(inserts|deletes|where)>?\s*\{`)
+	sparqlBlock  = regexp.MustCompile(`(?i)\b(INSERT|DELETE)\s+DATA\s*\{`)
+)
+
+// parseN3Patch reads a solid:InsertDeletePatch with ground formulae; solid:where
+// conditions (and so variables) are not supported
+func parseN3Patch(body, base string) (*parsedPatch, error) {
+	if !strings.Contains(body, "InsertDeletePatch") {
+		return nil, fmt.Errorf("N3 Patch must contain a solid:InsertDeletePatch")
+	}
+	prefixes := prefixDeclarations(body)
+	patch := &parsedPatch{inserts: rdf.NewGraph(), deletes: rdf.NewGraph()}
+	for _, loc := range n3Formula.FindAllStringSubmatchIndex(body, -1) {
+		keyword := body[loc[4]:loc[5]]
+		formula, err := braceBlock(body, loc[1]-1)
+		if err != nil {
+			return nil, err
+		}
+		if keyword == "where" {
+			if strings.TrimSpace(formula) != "" {
+				return nil, fmt.Errorf("solid:where conditions are not supported")
+			}
+			continue
+		}
+		if err := parseInto(patch, keyword == "inserts", prefixes+formula, base); err != nil {
+			return nil, fmt.Errorf("solid:%s: %w", keyword, err)
+		}
+	}
+	return patch, nil
+}
+
+// parseSPARQLUpdate reads INSERT DATA and DELETE DATA operations
+func parseSPARQLUpdate(body, base string) (*parsedPatch, error) {
+	prefixes := prefixDeclarations(body)
+	patch := &parsedPatch{inserts: rdf.NewGraph(), deletes: rdf.NewGraph()}
+	rest := turtlePrefix.ReplaceAllString(body, "")
+	matches := sparqlBlock.FindAllStringSubmatchIndex(rest, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("only INSERT DATA and DELETE DATA updates are supported")
+	}
+	end := 0
+	for _, loc := range matches {
+		if between := strings.Trim(rest[end:loc[0]], " \t\r\n;"); between != "" {
+			return nil, fmt.Errorf("unsupported update operation near %q", between)
+		}
+		block, err := braceBlock(rest, loc[1]-1)
+		if err != nil {
+			return nil, err
+		}
+		insert := strings.EqualFold(rest[loc[2]:loc[3]], "INSERT")
+		if err := parseInto(patch, insert, prefixes+block, base); err != nil {
+			return nil, fmt.Errorf("%s DATA: %w", strings.ToUpper(rest[loc[2]:loc[3]]), err)
+		}
+		end = loc[1] + len(block) + 1
+	}
+	if trailing := strings.Trim(rest[end:], " \t\r\n;"); trailing != "" {
+		return nil, fmt.Errorf("unsupported update operation near %q", trailing)
+	}
+	return patch, nil
+}
+
+// parseInto adds the triples of a formula to the patch's inserts or deletes
+func parseInto(patch *parsedPatch, insert bool, turtle, base string) error {
+	g, err := rdf.ParseTurtle([]byte(turtle), base)
+	if err != nil {
+		return err
+	}
+	if insert {
+		patch.inserts.Merge(g)
+		for prefix, namespace := range g.Prefixes {
+			patch.inserts.SetPrefix(prefix, namespace)
+		}
+		return nil
+	}
+	for _, t := range g.Triples() {
+		if t.Subject.IsBlank() || t.Object.IsBlank() {
+			return fmt.Errorf("blank nodes cannot be deleted: %s", t)
+		}
+		patch.deletes.AddTriple(t)
+	}
+	return nil
+}
+
+// prefixDeclarations rewrites the document's prefixes as Turtle @prefix lines
+func prefixDeclarations(body string) string {
+	var b strings.Builder
+	for _, m := range turtlePrefix.FindAllStringSubmatch(body, -1) {
+		fmt.Fprintf(&b, "@prefix %s: <%s> .\n", m[1], m[2])
+	}
+	return b.String()
+}
+
+// braceBlock returns the text between the "{" at open and its matching "}",
+// skipping braces inside IRIs, strings and comments
+func braceBlock(body string, open int) (string, error) {
+	depth := 0
+	for i := open; i < len(body); i++ {
+		switch c := body[i]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return body[open+1 : i], nil
+			}
+		case '<':
+			if end := strings.IndexByte(body[i:], '>'); end > 0 {
+				i += end
+			}
+		case '#':
+			if end := strings.IndexByte(body[i:], '\n'); end > 0 {
+				i += end
+			} else {
+				i = len(body)
+			}
+		case '"', '\'':
+			quote := string(c)
+			if strings.HasPrefix(body[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			j := i + len(quote)
+			for j < len(body) && !strings.HasPrefix(body[j:], quote) {
+				if body[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j + len(quote) - 1
+		}
+	}
+	return "", fmt.Errorf("unbalanced braces in patch")
+}
@@ -0,0 +1,619 @@
+// Package ldptest runs an in-process LDP server implementing the subset of the Solid
+// protocol the pod client uses, in the style of net/http/httptest: basic containers,
+// GET/HEAD/PUT/POST/DELETE, PATCH with N3 Patch or SPARQL Update (INSERT DATA /
+// DELETE DATA), If-Match / If-None-Match preconditions on ETags, and optionally
+// Web Access Control enforced with solid.EvaluateWAC.
+package ldptest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+)
+
+// resource is a stored document or container (paths of containers end in "/")
+type resource struct {
+	contentType string
+	body        []byte
+}
+
+// Request records a request the server handled, for asserting on client behaviour.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Status int
+}
+
+// Server is an LDP server backed by an httptest.Server. The pod root is the
+// server URL plus "/", and starts as an empty container.
+type Server struct {
+	Server *httptest.Server
+	URL    string // pod root, ending in "/"
+
+	mu        sync.Mutex
+	resources map[string]*resource
+	token     string
+	agents    map[string]string // access token -> WebID
+	enforce   bool
+	requests  []Request
+	slugSeq   int
+}
+
+// NewServer starts a server on a local port. Close it when done.
+func NewServer() *Server {
+	s := &Server{resources: map[string]*resource{"/": {}}, agents: make(map[string]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.Server.URL + "/"
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.Server.Close()
+}
+
+// Client returns an HTTP client that talks to the server.
+func (s *Server) Client() *http.Client {
+	return s.Server.Client()
+}
+
+// RequireToken makes every request need "Authorization: DPoP <token>" (or Bearer).
+// An empty token turns the check off again.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// AddAgent lets requests authenticate as webID with "Authorization: DPoP <token>"
+// (or Bearer); its token also satisfies RequireToken. Requests with other tokens are
+// unauthenticated.
+func (s *Server) AddAgent(token, webID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents[token] = webID
+}
+
+// EnforceACL turns on Web Access Control: each request needs the mode it uses
+// (Read, Append for POST, Write, or Control for .acl documents) from the resource's
+// own .acl or the nearest container .acl. Without any .acl up to the root,
+// everything is allowed.
+func (s *Server) EnforceACL() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enforce = true
+}
+
+// Modes evaluates the .acl in effect for a path and returns what webID ("" for
+// unauthenticated) may do there.
+func (s *Server) Modes(resourcePath, webID string) solid.AccessModes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	modes, _ := s.effectiveModes(resourcePath, webID)
+	return modes
+}
+
+// Seed stores a resource directly, creating its parent containers.
+func (s *Server) Seed(resourcePath, contentType string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.createParents(resourcePath)
+	s.resources[resourcePath] = &resource{contentType: contentType, body: body}
+}
+
+// Resource returns a stored resource's body and content type.
+func (s *Server) Resource(resourcePath string) ([]byte, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.resources[resourcePath]
+	if !ok {
+		return nil, "", false
+	}
+	return append([]byte(nil), r.body...), r.contentType, true
+}
+
+// Paths lists every stored resource and container, sorted.
+func (s *Server) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.resources))
+	for p := range s.resources {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Requests returns the requests handled so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Status: recorder.status})
+	}()
+
+	agent, known := "", false
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token := strings.TrimPrefix(strings.TrimPrefix(auth, "DPoP "), "Bearer ")
+		agent, known = s.agents[token]
+		known = known || (token != auth && token == s.token)
+	}
+	if s.token != "" && !known {
+		http.Error(recorder, "missing or invalid access token", http.StatusUnauthorized)
+		return
+	}
+
+	resourcePath := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") && resourcePath != "/" {
+		resourcePath += "/"
+	}
+
+	if s.enforce && !s.authorized(r.Method, resourcePath, agent) {
+		if agent == "" {
+			http.Error(recorder, "authentication required", http.StatusUnauthorized)
+		} else {
+			http.Error(recorder, "access denied for "+agent, http.StatusForbidden)
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.handleGet(recorder, r, resourcePath)
+	case http.MethodPut:
+		s.handlePut(recorder, r, resourcePath)
+	case http.MethodPost:
+		s.handlePost(recorder, r, resourcePath)
+	case http.MethodPatch:
+		s.handlePatch(recorder, r, resourcePath)
+	case http.MethodDelete:
+		s.handleDelete(recorder, r, resourcePath)
+	default:
+		recorder.Header().Set("Allow", "GET, HEAD, PUT, POST, PATCH, DELETE")
+		http.Error(recorder, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, resourcePath string) {
+	res, ok := s.resources[resourcePath]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	body, contentType := res.body, res.contentType
+	if isContainer(resourcePath) {
+		body, contentType = s.containerListing(resourcePath), rdf.MediaTypeTurtle
+	}
+	etag := etagOf(body)
+	if matchesETag(r.Header.Get("If-None-Match"), etag, true) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if header := r.Header.Get("If-Match"); header != "" && !matchesETag(header, etag, true) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return
+	}
+	s.writeMetadata(w, resourcePath, contentType, etag)
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(body)
+	}
+}
+
+func (s *Server) handlePut(w http.ResponseWriter, r *http.Request, resourcePath string) {
+	if resourcePath == "/" {
+		http.Error(w, "cannot replace the pod root", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.checkPreconditions(w, r, resourcePath) {
+		return
+	}
+	if status, message := s.checkParents(resourcePath); status != 0 {
+		http.Error(w, message, status)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	_, existed := s.resources[resourcePath]
+	s.createParents(resourcePath)
+	if isContainer(resourcePath) {
+		if !existed {
+			s.resources[resourcePath] = &resource{}
+		}
+	} else {
+		s.resources[resourcePath] = &resource{contentType: r.Header.Get("Content-Type"), body: body}
+	}
+	s.writeResult(w, resourcePath, existed)
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, containerPath string) {
+	if !isContainer(containerPath) {
+		http.Error(w, "POST is only allowed on containers", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.exists(containerPath) {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	container := strings.Contains(r.Header.Get("Link"), rdf.LDPNS+"BasicContainer") ||
+		strings.Contains(r.Header.Get("Link"), rdf.LDPNS+"Container>")
+	name := sanitizeSlug(r.Header.Get("Slug"))
+	newPath := func(name string) string {
+		if container {
+			return containerPath + name + "/"
+		}
+		return containerPath + name
+	}
+	if name == "" || s.exists(newPath(name)) {
+		for {
+			s.slugSeq++
+			candidate := fmt.Sprintf("%s%d", name, s.slugSeq)
+			if name != "" {
+				candidate = fmt.Sprintf("%s-%d", name, s.slugSeq)
+			}
+			if !s.exists(newPath(candidate)) {
+				name = candidate
+				break
+			}
+		}
+	}
+
+	created := newPath(name)
+	if container {
+		s.resources[created] = &resource{}
+	} else {
+		s.resources[created] = &resource{contentType: r.Header.Get("Content-Type"), body: body}
+	}
+	w.Header().Set("Location", s.URL+strings.TrimPrefix(created, "/"))
+	s.writeResult(w, created, false)
+}
+
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request, resourcePath string) {
+	if isContainer(resourcePath) {
+		http.Error(w, "containers cannot be patched", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.checkPreconditions(w, r, resourcePath) {
+		return
+	}
+	if status, message := s.checkParents(resourcePath); status != 0 {
+		http.Error(w, message, status)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	base := s.URL + strings.TrimPrefix(resourcePath, "/")
+	var patch *parsedPatch
+	switch mediaType {
+	case "text/n3":
+		patch, err = parseN3Patch(string(body), base)
+	case "application/sparql-update":
+		patch, err = parseSPARQLUpdate(string(body), base)
+	default:
+		w.Header().Set("Accept-Patch", "text/n3, application/sparql-update")
+		http.Error(w, "unsupported patch format "+mediaType, http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	existing, existed := s.resources[resourcePath]
+	g := rdf.NewGraph()
+	if existed {
+		contentType, _, _ := mime.ParseMediaType(existing.contentType)
+		if contentType != rdf.MediaTypeTurtle {
+			http.Error(w, "only Turtle resources can be patched", http.StatusConflict)
+			return
+		}
+		if g, err = rdf.ParseTurtle(existing.body, base); err != nil {
+			http.Error(w, "stored resource is not valid Turtle: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// a patch is applied all or nothing: every deleted triple must be present
+	for _, t := range patch.deletes.Triples() {
+		if !g.Has(t.Subject, t.Predicate, t.Object) {
+			http.Error(w, "cannot delete a triple that does not exist: "+t.String(), http.StatusConflict)
+			return
+		}
+	}
+	for _, t := range patch.deletes.Triples() {
+		g.Remove(t.Subject, t.Predicate, t.Object)
+	}
+	g.Merge(patch.inserts)
+	for prefix, namespace := range patch.inserts.Prefixes {
+		if _, ok := g.Prefixes[prefix]; !ok {
+			g.SetPrefix(prefix, namespace)
+		}
+	}
+	g.Base = base
+
+	s.createParents(resourcePath)
+	s.resources[resourcePath] = &resource{contentType: rdf.MediaTypeTurtle, body: []byte(g.Turtle())}
+	s.writeResult(w, resourcePath, existed)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request, resourcePath string) {
+	if resourcePath == "/" {
+		http.Error(w, "cannot delete the pod root", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := s.resources[resourcePath]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.checkPreconditions(w, r, resourcePath) {
+		return
+	}
+	if isContainer(resourcePath) && len(s.children(resourcePath)) > 0 {
+		http.Error(w, "container is not empty", http.StatusConflict)
+		return
+	}
+	delete(s.resources, resourcePath)
+	delete(s.resources, aclPath(resourcePath))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPreconditions evaluates If-Match and If-None-Match against the current ETag
+func (s *Server) checkPreconditions(w http.ResponseWriter, r *http.Request, resourcePath string) bool {
+	etag := ""
+	if _, ok := s.resources[resourcePath]; ok {
+		etag = s.currentETag(resourcePath)
+	}
+	if header := r.Header.Get("If-Match"); header != "" && (etag == "" || !matchesETag(header, etag, false)) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return false
+	}
+	if header := r.Header.Get("If-None-Match"); header != "" && etag != "" && matchesETag(header, etag, true) {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// checkParents rejects paths whose ancestors are documents rather than containers
+func (s *Server) checkParents(resourcePath string) (int, string) {
+	trimmed := strings.TrimSuffix(resourcePath, "/")
+	if _, ok := s.resources[trimmed+"/"]; ok && !isContainer(resourcePath) {
+		return http.StatusConflict, "a container already exists at " + trimmed + "/"
+	}
+	if _, ok := s.resources[trimmed]; ok && isContainer(resourcePath) {
+		return http.StatusConflict, "a document already exists at " + trimmed
+	}
+	for parent := parentOf(resourcePath); parent != "/" && parent != ""; parent = parentOf(parent) {
+		if _, ok := s.resources[strings.TrimSuffix(parent, "/")]; ok {
+			return http.StatusConflict, "a document already exists at " + strings.TrimSuffix(parent, "/")
+		}
+	}
+	return 0, ""
+}
+
+// createParents makes the missing containers above a path
+func (s *Server) createParents(resourcePath string) {
+	for parent := parentOf(resourcePath); parent != ""; parent = parentOf(parent) {
+		if _, ok := s.resources[parent]; !ok {
+			s.resources[parent] = &resource{}
+		}
+		if parent == "/" {
+			return
+		}
+	}
+}
+
+func (s *Server) exists(resourcePath string) bool {
+	_, ok := s.resources[resourcePath]
+	return ok
+}
+
+// children lists the direct members of a container, sorted
+func (s *Server) children(containerPath string) []string {
+	var members []string
+	for p := range s.resources {
+		if p != containerPath && parentOf(p) == containerPath && !isACL(p) {
+			members = append(members, p)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// containerListing renders a container with its ldp:contains triples
+func (s *Server) containerListing(containerPath string) []byte {
+	base := s.URL + strings.TrimPrefix(containerPath, "/")
+	g := rdf.NewGraph()
+	g.UseCommonPrefixes("ldp")
+	g.Base = base
+	container := rdf.IRI(base)
+	g.Add(container, rdf.IRI(rdf.RDFType), rdf.IRI(rdf.LDPNS+"Container"))
+	g.Add(container, rdf.IRI(rdf.RDFType), rdf.IRI(rdf.LDPNS+"BasicContainer"))
+	for _, member := range s.children(containerPath) {
+		g.Add(container, rdf.IRI(rdf.LDPNS+"contains"), rdf.IRI(s.URL+strings.TrimPrefix(member, "/")))
+	}
+	return []byte(g.Turtle())
+}
+
+// currentETag is the ETag a GET of the path would return
+func (s *Server) currentETag(resourcePath string) string {
+	if isContainer(resourcePath) {
+		return etagOf(s.containerListing(resourcePath))
+	}
+	return etagOf(s.resources[resourcePath].body)
+}
+
+// writeMetadata sets the headers shared by GET, HEAD and write responses
+func (s *Server) writeMetadata(w http.ResponseWriter, resourcePath, contentType, etag string) {
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Link", fmt.Sprintf("<%sResource>; rel=\"type\"", rdf.LDPNS))
+	if isContainer(resourcePath) {
+		w.Header().Add("Link", fmt.Sprintf("<%sContainer>; rel=\"type\"", rdf.LDPNS))
+		w.Header().Add("Link", fmt.Sprintf("<%sBasicContainer>; rel=\"type\"", rdf.LDPNS))
+	}
+	w.Header().Set("Accept-Patch", "text/n3, application/sparql-update")
+	if !isACL(resourcePath) {
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"acl\"", s.URL+strings.TrimPrefix(aclPath(resourcePath), "/")))
+	}
+}
+
+// writeResult answers a successful write with 201 Created or 205 Reset Content
+func (s *Server) writeResult(w http.ResponseWriter, resourcePath string, existed bool) {
+	w.Header().Set("ETag", s.currentETag(resourcePath))
+	if existed {
+		w.WriteHeader(http.StatusResetContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// statusRecorder remembers the status code for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// authorized checks the mode a request needs against the .acl in effect
+func (s *Server) authorized(method, resourcePath, agent string) bool {
+	target := resourcePath
+	if isACL(resourcePath) {
+		target = strings.TrimSuffix(resourcePath, ".acl")
+	}
+	modes, found := s.effectiveModes(target, agent)
+	if !found {
+		return true
+	}
+	switch {
+	case isACL(resourcePath):
+		return modes.Control
+	case method == http.MethodGet || method == http.MethodHead:
+		return modes.Read
+	case method == http.MethodPost:
+		return modes.Append
+	default:
+		return modes.Write
+	}
+}
+
+// effectiveModes evaluates the resource's own .acl, or the nearest container's
+func (s *Server) effectiveModes(resourcePath, agent string) (solid.AccessModes, bool) {
+	for current := resourcePath; current != ""; current = parentOf(current) {
+		document, ok := s.resources[aclPath(current)]
+		if !ok {
+			continue
+		}
+		aclURL := s.URL + strings.TrimPrefix(aclPath(current), "/")
+		g, err := rdf.ParseTurtle(document.body, aclURL)
+		if err != nil {
+			return solid.AccessModes{}, true
+		}
+		return solid.EvaluateWAC(g, s.URL+strings.TrimPrefix(resourcePath, "/"), agent), true
+	}
+	return solid.AccessModes{}, false
+}
+
+// aclPath is where a resource's .acl lives ("/a/b.ttl.acl", "/a/.acl")
+func aclPath(resourcePath string) string {
+	return resourcePath + ".acl"
+}
+
+func isACL(resourcePath string) bool {
+	return strings.HasSuffix(resourcePath, ".acl")
+}
+
+func isContainer(resourcePath string) bool {
+	return strings.HasSuffix(resourcePath, "/")
+}
+
+// parentOf returns the container holding a path ("" for the root)
+func parentOf(resourcePath string) string {
+	if resourcePath == "/" {
+		return ""
+	}
+	trimmed := strings.TrimSuffix(resourcePath, "/")
+	return trimmed[:strings.LastIndex(trimmed, "/")+1]
+}
+
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// matchesETag checks an If-Match / If-None-Match list; weak comparison ignores W/ prefixes
+func matchesETag(header, etag string, weak bool) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeSlug keeps a Slug header to one safe path segment
+func sanitizeSlug(slug string) string {
+	var b strings.Builder
+	for _, r := range slug {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), ".")
+}
@@ -0,0 +1,628 @@
+package solid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Application metadata attached to every resource written to a pod
+const (
+	applicationName    = "location-tracker"
+	applicationVersion = "2.0.0"
+)
+
+// LocationData is a location share as stored in a pod (SOLID_DATA_MODELS.md, Location).
+type LocationData struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+	Timestamp time.Time
+	DeviceID  string
+	Name      string
+	Locality  string
+	Region    string
+	Country   string
+	Simulated bool
+	Creator   string // WebID of the pod owner
+}
+
+// ErrorLogData is a generated error log case as stored in a pod (SOLID_DATA_MODELS.md, Error Log).
+type ErrorLogData struct {
+	ID           string
+	URL          string
+	Message      string
+	Slogan       string
+	Description  string
+	Story        string
+	SatiricalFix string
+	Timestamp    time.Time
+	GifURLs      []string
+	MemeURL      string
+	FoodImageURL string
+	SongTitle    string
+	SongArtist   string
+	SongURL      string
+	Keywords     []string
+	Creator      string
+}
+
+// TipData is an anonymous tip as stored in the submitter's pod.
+type TipData struct {
+	ID               string
+	Content          string
+	ModeratedContent string
+	ModerationStatus string
+	Keywords         []string
+	Timestamp        time.Time
+	Creator          string
+}
+
+// CommercialRealEstateData is a commercial property listing as stored in a pod
+// (SOLID_DATA_MODELS.md, Commercial Real Estate).
+type CommercialRealEstateData struct {
+	ID              string
+	Name            string
+	Description     string
+	Latitude        float64
+	Longitude       float64
+	StreetAddress   string
+	Locality        string
+	Region          string
+	PostalCode      string
+	Country         string
+	PropertyType    string // schema:additionalType, e.g. https://schema.org/OfficeOrBusinessPlace
+	Categories      []string
+	Status          string
+	FloorSize       float64
+	FloorSizeUnit   string
+	PriceRange      string
+	Price           float64
+	PriceCurrency   string
+	PriceUnit       string
+	CurrentBusiness string
+	BusinessType    string
+	Telephone       string
+	Email           string
+	URL             string
+	Keywords        string
+	QueryLocation   string
+	QueryLat        float64
+	QueryLng        float64
+	DataSource      string
+	Timestamp       time.Time
+	Creator         string
+}
+
+// Pod-relative container for everything the app writes
+const appContainer = "private/location-tracker/"
+
+// LocationPath returns the pod-relative path of a location resource.
+func LocationPath(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%slocations/%s/location-%s.ttl", appContainer, t.Format("2006/01/02"), t.Format("2006-01-02T150405.000Z"))
+}
+
+// AppContainerPath returns the pod-relative container holding all of the app's data.
+func AppContainerPath() string {
+	return appContainer
+}
+
+// LocationTrailPath returns the pod-relative container of one day's locations.
+func LocationTrailPath(day time.Time) string {
+	return fmt.Sprintf("%slocations/%s/", appContainer, day.UTC().Format("2006/01/02"))
+}
+
+// ErrorLogPath returns the pod-relative path of an error log resource.
+func ErrorLogPath(t time.Time, id string) string {
+	t = t.UTC()
+	return fmt.Sprintf("%serror-logs/%s/error-%s.jsonld", appContainer, t.Format("2006/01/02"), pathSegment(id, t))
+}
+
+// TipPath returns the pod-relative path of a tip resource.
+func TipPath(t time.Time, id string) string {
+	t = t.UTC()
+	return fmt.Sprintf("%stips/%s/tip-%s.jsonld", appContainer, t.Format("2006/01"), pathSegment(id, t))
+}
+
+// CommercialRealEstatePath returns the pod-relative path of a commercial listing resource.
+func CommercialRealEstatePath(t time.Time, id string) string {
+	t = t.UTC()
+	return fmt.Sprintf("%scommercial/%s/commercial-%s.ttl", appContainer, t.Format("2006/01"), pathSegment(id, t))
+}
+
+// pathSegment keeps IDs URL-safe, falling back to the timestamp
+func pathSegment(id string, t time.Time) string {
+	var b strings.Builder
+	for _, r := range id {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return t.Format("2006-01-02T150405Z")
+	}
+	return b.String()
+}
+
+// LocationToGraph maps a location share onto schema.org and WGS84 terms.
+func LocationToGraph(loc LocationData) *rdf.Graph {
+	timestamp := loc.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	g := newDocument()
+	s := rdf.IRI("#location")
+	g.Add(s, rdfType, schema("Place"))
+	g.Add(s, rdfType, geo("Point"))
+	g.Add(s, geo("lat"), rdf.Decimal(loc.Latitude))
+	g.Add(s, geo("long"), rdf.Decimal(loc.Longitude))
+	coordinates := addNode(g, s, schema("geo"), "GeoCoordinates")
+	g.Add(coordinates, schema("latitude"), rdf.Decimal(loc.Latitude))
+	g.Add(coordinates, schema("longitude"), rdf.Decimal(loc.Longitude))
+	g.Add(s, schema("accuracy"), rdf.Decimal(loc.Accuracy))
+	g.Add(s, dcterms("created"), rdf.DateTime(timestamp))
+	g.Add(s, schema("dateCreated"), rdf.DateTime(timestamp))
+	g.Add(s, schema("identifier"), rdf.Literal(loc.DeviceID))
+	addString(g, s, schema("name"), loc.Name)
+	if loc.Locality != "" || loc.Region != "" || loc.Country != "" {
+		address := addNode(g, s, schema("address"), "PostalAddress")
+		addString(g, address, schema("addressLocality"), loc.Locality)
+		addString(g, address, schema("addressRegion"), loc.Region)
+		addString(g, address, schema("addressCountry"), loc.Country)
+	}
+	if loc.Creator != "" {
+		g.Add(s, dcterms("creator"), rdf.IRI(loc.Creator))
+		g.Add(s, schema("creator"), rdf.IRI(loc.Creator))
+	}
+	g.Add(s, schema("applicationCategory"), rdf.Literal("LocationTracking"))
+	g.Add(s, schema("applicationSubCategory"), rdf.Literal(applicationName))
+	g.Add(s, schema("softwareVersion"), rdf.Literal(applicationVersion))
+	addPropertyValue(g, s, "simulated", rdf.Boolean(loc.Simulated))
+	g.Add(s, schema("hasMap"), rdf.Literal(fmt.Sprintf("https://www.google.com/maps?q=%.6f,%.6f", loc.Latitude, loc.Longitude)))
+	return g
+}
+
+// LocationToTurtle serializes a location share to Turtle after validating it
+// against the location shapes.
+func LocationToTurtle(loc LocationData) (string, error) {
+	g := LocationToGraph(loc)
+	if err := validateDocument(KindLocation, g); err != nil {
+		return "", err
+	}
+	return g.Turtle(), nil
+}
+
+// LocationFromGraph reads a location share back from its graph.
+func LocationFromGraph(g *rdf.Graph) (LocationData, error) {
+	s, err := documentRoot(g, rdf.GeoNS+"Point")
+	if err != nil {
+		return LocationData{}, err
+	}
+
+	var loc LocationData
+	var hasLat, hasLong bool
+	if loc.Latitude, hasLat, err = floatValue(g, s, geo("lat")); err != nil {
+		return LocationData{}, err
+	}
+	if loc.Longitude, hasLong, err = floatValue(g, s, geo("long")); err != nil {
+		return LocationData{}, err
+	}
+	if coordinates, ok := nodeOf(g, s, schema("geo")); ok {
+		if !hasLat {
+			if loc.Latitude, hasLat, err = floatValue(g, coordinates, schema("latitude")); err != nil {
+				return LocationData{}, err
+			}
+		}
+		if !hasLong {
+			if loc.Longitude, hasLong, err = floatValue(g, coordinates, schema("longitude")); err != nil {
+				return LocationData{}, err
+			}
+		}
+	}
+	if !hasLat || !hasLong {
+		return LocationData{}, fmt.Errorf("location has no coordinates")
+	}
+
+	if loc.Accuracy, _, err = floatValue(g, s, schema("accuracy")); err != nil {
+		return LocationData{}, err
+	}
+	if loc.Timestamp, err = timeValue(g, s, dcterms("created"), schema("dateCreated")); err != nil {
+		return LocationData{}, err
+	}
+	loc.DeviceID = stringValue(g, s, schema("identifier"))
+	loc.Name = stringValue(g, s, schema("name"))
+	if address, ok := nodeOf(g, s, schema("address")); ok {
+		loc.Locality = stringValue(g, address, schema("addressLocality"))
+		loc.Region = stringValue(g, address, schema("addressRegion"))
+		loc.Country = stringValue(g, address, schema("addressCountry"))
+	}
+	loc.Creator = stringValue(g, s, dcterms("creator"))
+	if loc.Creator == "" {
+		loc.Creator = stringValue(g, s, schema("creator"))
+	}
+	if simulated, ok := propertyValue(g, s, "simulated"); ok {
+		if loc.Simulated, err = simulated.Bool(); err != nil {
+			return LocationData{}, err
+		}
+	}
+	return loc, nil
+}
+
+// LocationFromTurtle parses a location resource. base is the resource URL, if known.
+func LocationFromTurtle(data []byte, base string) (LocationData, error) {
+	g, err := rdf.ParseTurtle(data, base)
+	if err != nil {
+		return LocationData{}, err
+	}
+	return LocationFromGraph(g)
+}
+
+// ErrorLogToGraph maps an error log case onto a schema:Report.
+func ErrorLogToGraph(e ErrorLogData) *rdf.Graph {
+	g := newDocument()
+	s := rdf.IRI("#error-log")
+	g.Add(s, rdfType, schema("Report"))
+	addString(g, s, schema("name"), e.Message)
+	addString(g, s, schema("reportNumber"), e.ID)
+	if !e.Timestamp.IsZero() {
+		g.Add(s, schema("dateCreated"), rdf.DateTime(e.Timestamp))
+	}
+	about := addNode(g, s, schema("about"), "SoftwareApplication")
+	g.Add(about, schema("name"), rdf.Literal("Location Tracker"))
+	g.Add(about, schema("softwareVersion"), rdf.Literal(applicationVersion))
+	addString(g, s, schema("url"), e.URL)
+	addString(g, s, schema("headline"), e.Slogan)
+	addString(g, s, schema("description"), e.Description)
+	for _, keyword := range e.Keywords {
+		addString(g, s, schema("keywords"), keyword)
+	}
+	if e.Creator != "" {
+		g.Add(s, schema("creator"), rdf.IRI(e.Creator))
+	}
+
+	for _, gif := range e.GifURLs {
+		media := addNode(g, s, schema("associatedMedia"), "ImageObject")
+		g.Add(media, schema("contentUrl"), rdf.Literal(gif))
+		g.Add(media, schema("encodingFormat"), rdf.Literal("image/gif"))
+	}
+	if e.MemeURL != "" {
+		media := addNode(g, s, schema("associatedMedia"), "ImageObject")
+		g.Add(media, schema("contentUrl"), rdf.Literal(e.MemeURL))
+		g.Add(media, schema("name"), rdf.Literal("Meme"))
+	}
+	if e.FoodImageURL != "" {
+		media := addNode(g, s, schema("associatedMedia"), "ImageObject")
+		g.Add(media, schema("contentUrl"), rdf.Literal(e.FoodImageURL))
+		g.Add(media, schema("name"), rdf.Literal("Food"))
+	}
+
+	if e.SongTitle != "" {
+		audio := addNode(g, s, schema("audio"), "MusicRecording")
+		g.Add(audio, schema("name"), rdf.Literal(e.SongTitle))
+		if e.SongArtist != "" {
+			artist := addNode(g, audio, schema("byArtist"), "MusicGroup")
+			g.Add(artist, schema("name"), rdf.Literal(e.SongArtist))
+		}
+		addString(g, audio, schema("url"), e.SongURL)
+	}
+
+	if e.Story != "" {
+		comment := addNode(g, s, schema("comment"), "Comment")
+		g.Add(comment, schema("name"), rdf.Literal("Children's Story"))
+		g.Add(comment, schema("text"), rdf.Literal(e.Story))
+	}
+	if e.SatiricalFix != "" {
+		comment := addNode(g, s, schema("comment"), "Comment")
+		g.Add(comment, schema("name"), rdf.Literal("Satirical Code Fix"))
+		g.Add(comment, schema("text"), rdf.Literal(e.SatiricalFix))
+	}
+	return g
+}
+
+// ErrorLogToJSONLD serializes an error log case to JSON-LD after validating it
+// against the error log shapes.
+func ErrorLogToJSONLD(e ErrorLogData) ([]byte, error) {
+	g := ErrorLogToGraph(e)
+	if err := validateDocument(KindErrorLog, g); err != nil {
+		return nil, err
+	}
+	return g.JSONLD(rdf.SchemaNS)
+}
+
+// ErrorLogFromGraph reads an error log case back from its graph.
+func ErrorLogFromGraph(g *rdf.Graph) (ErrorLogData, error) {
+	s, err := documentRoot(g, rdf.SchemaNS+"Report")
+	if err != nil {
+		return ErrorLogData{}, err
+	}
+
+	e := ErrorLogData{
+		ID:          stringValue(g, s, schema("reportNumber")),
+		URL:         stringValue(g, s, schema("url")),
+		Message:     stringValue(g, s, schema("name")),
+		Slogan:      stringValue(g, s, schema("headline")),
+		Description: stringValue(g, s, schema("description")),
+		Keywords:    stringValues(g, s, schema("keywords")),
+		Creator:     stringValue(g, s, schema("creator")),
+	}
+	if e.Timestamp, err = timeValue(g, s, schema("dateCreated")); err != nil {
+		return ErrorLogData{}, err
+	}
+
+	for _, media := range g.Objects(s, schema("associatedMedia")) {
+		contentURL := stringValue(g, media, schema("contentUrl"))
+		switch {
+		case contentURL == "":
+		case stringValue(g, media, schema("name")) == "Meme":
+			e.MemeURL = contentURL
+		case stringValue(g, media, schema("name")) == "Food":
+			e.FoodImageURL = contentURL
+		case stringValue(g, media, schema("encodingFormat")) == "image/gif":
+			e.GifURLs = append(e.GifURLs, contentURL)
+		case e.MemeURL == "":
+			// documents from other writers name their media freely; the first still image is the meme
+			e.MemeURL = contentURL
+		}
+	}
+
+	if audio, ok := nodeOf(g, s, schema("audio")); ok {
+		e.SongTitle = stringValue(g, audio, schema("name"))
+		e.SongURL = stringValue(g, audio, schema("url"))
+		if artist, ok := nodeOf(g, audio, schema("byArtist")); ok {
+			e.SongArtist = stringValue(g, artist, schema("name"))
+		}
+	}
+
+	for _, comment := range g.Objects(s, schema("comment")) {
+		switch stringValue(g, comment, schema("name")) {
+		case "Children's Story":
+			e.Story = stringValue(g, comment, schema("text"))
+		case "Satirical Code Fix":
+			e.SatiricalFix = stringValue(g, comment, schema("text"))
+		}
+	}
+	return e, nil
+}
+
+// ErrorLogFromJSONLD parses an error log resource. base is the resource URL, if known.
+func ErrorLogFromJSONLD(data []byte, base string) (ErrorLogData, error) {
+	g, err := rdf.ParseJSONLD(data, base)
+	if err != nil {
+		return ErrorLogData{}, err
+	}
+	return ErrorLogFromGraph(g)
+}
+
+// TipToGraph maps an anonymous tip onto a schema:Comment.
+func TipToGraph(tip TipData) *rdf.Graph {
+	g := newDocument()
+	s := rdf.IRI("#tip")
+	g.Add(s, rdfType, schema("Comment"))
+	addString(g, s, schema("identifier"), tip.ID)
+	addString(g, s, schema("text"), tip.Content)
+	if !tip.Timestamp.IsZero() {
+		g.Add(s, schema("dateCreated"), rdf.DateTime(tip.Timestamp))
+	}
+	about := addNode(g, s, schema("about"), "SoftwareApplication")
+	g.Add(about, schema("name"), rdf.Literal("Location Tracker"))
+	addString(g, s, schema("abstract"), tip.ModeratedContent)
+	if tip.ModerationStatus != "" {
+		addPropertyValue(g, s, "moderation_status", rdf.Literal(tip.ModerationStatus))
+	}
+	for _, keyword := range tip.Keywords {
+		addString(g, s, schema("keywords"), keyword)
+	}
+	if tip.Creator != "" {
+		g.Add(s, schema("author"), rdf.IRI(tip.Creator))
+	}
+	return g
+}
+
+// TipToJSONLD serializes an anonymous tip to JSON-LD after validating it
+// against the tip shapes.
+func TipToJSONLD(tip TipData) ([]byte, error) {
+	g := TipToGraph(tip)
+	if err := validateDocument(KindTip, g); err != nil {
+		return nil, err
+	}
+	return g.JSONLD(rdf.SchemaNS)
+}
+
+// TipFromGraph reads an anonymous tip back from its graph.
+func TipFromGraph(g *rdf.Graph) (TipData, error) {
+	s, err := documentRoot(g, rdf.SchemaNS+"Comment")
+	if err != nil {
+		return TipData{}, err
+	}
+
+	tip := TipData{
+		ID:               stringValue(g, s, schema("identifier")),
+		Content:          stringValue(g, s, schema("text")),
+		ModeratedContent: stringValue(g, s, schema("abstract")),
+		Keywords:         stringValues(g, s, schema("keywords")),
+		Creator:          stringValue(g, s, schema("author")),
+	}
+	if status, ok := propertyValue(g, s, "moderation_status"); ok {
+		tip.ModerationStatus = status.Value
+	}
+	if tip.Timestamp, err = timeValue(g, s, schema("dateCreated")); err != nil {
+		return TipData{}, err
+	}
+	return tip, nil
+}
+
+// TipFromJSONLD parses a tip resource. base is the resource URL, if known.
+func TipFromJSONLD(data []byte, base string) (TipData, error) {
+	g, err := rdf.ParseJSONLD(data, base)
+	if err != nil {
+		return TipData{}, err
+	}
+	return TipFromGraph(g)
+}
+
+// CommercialRealEstateToGraph maps a commercial listing onto a schema:RealEstateListing.
+func CommercialRealEstateToGraph(c CommercialRealEstateData) *rdf.Graph {
+	g := newDocument()
+	s := rdf.IRI("#property")
+	g.Add(s, rdfType, schema("RealEstateListing"))
+	g.Add(s, rdfType, schema("Place"))
+	addString(g, s, schema("identifier"), c.ID)
+	addString(g, s, schema("name"), c.Name)
+	addString(g, s, schema("description"), c.Description)
+
+	if c.Latitude != 0 || c.Longitude != 0 {
+		g.Add(s, geo("lat"), rdf.Decimal(c.Latitude))
+		g.Add(s, geo("long"), rdf.Decimal(c.Longitude))
+		coordinates := addNode(g, s, schema("geo"), "GeoCoordinates")
+		g.Add(coordinates, schema("latitude"), rdf.Decimal(c.Latitude))
+		g.Add(coordinates, schema("longitude"), rdf.Decimal(c.Longitude))
+	}
+	if c.StreetAddress != "" || c.Locality != "" || c.Region != "" || c.PostalCode != "" || c.Country != "" {
+		address := addNode(g, s, schema("address"), "PostalAddress")
+		addString(g, address, schema("streetAddress"), c.StreetAddress)
+		addString(g, address, schema("addressLocality"), c.Locality)
+		addString(g, address, schema("addressRegion"), c.Region)
+		addString(g, address, schema("postalCode"), c.PostalCode)
+		addString(g, address, schema("addressCountry"), c.Country)
+	}
+
+	if c.FloorSize > 0 {
+		size := addNode(g, s, schema("floorSize"), "QuantitativeValue")
+		g.Add(size, schema("value"), rdf.Decimal(c.FloorSize))
+		addString(g, size, schema("unitText"), c.FloorSizeUnit)
+	}
+	addString(g, s, schema("priceRange"), c.PriceRange)
+	if c.Price > 0 {
+		price := addNode(g, s, schema("price"), "PriceSpecification")
+		g.Add(price, schema("price"), rdf.Decimal(c.Price))
+		addString(g, price, schema("priceCurrency"), c.PriceCurrency)
+		addString(g, price, schema("unitText"), c.PriceUnit)
+	}
+
+	addString(g, s, schema("additionalType"), c.PropertyType)
+	for _, category := range c.Categories {
+		addString(g, s, schema("category"), category)
+	}
+	addString(g, s, schema("telephone"), c.Telephone)
+	addString(g, s, schema("email"), c.Email)
+	addString(g, s, schema("url"), c.URL)
+	addString(g, s, schema("keywords"), c.Keywords)
+	if !c.Timestamp.IsZero() {
+		g.Add(s, dcterms("created"), rdf.DateTime(c.Timestamp))
+	}
+	if c.Creator != "" {
+		g.Add(s, dcterms("creator"), rdf.IRI(c.Creator))
+	}
+
+	for _, property := range []struct{ id, value string }{
+		{"status", c.Status},
+		{"current_business", c.CurrentBusiness},
+		{"business_type", c.BusinessType},
+		{"query_location", c.QueryLocation},
+		{"data_source", c.DataSource},
+	} {
+		if property.value != "" {
+			addPropertyValue(g, s, property.id, rdf.Literal(property.value))
+		}
+	}
+	if c.QueryLat != 0 || c.QueryLng != 0 {
+		addPropertyValue(g, s, "query_lat", rdf.Decimal(c.QueryLat))
+		addPropertyValue(g, s, "query_lng", rdf.Decimal(c.QueryLng))
+	}
+	return g
+}
+
+// CommercialRealEstateToTurtle serializes a commercial listing to Turtle after
+// validating it against the commercial shapes.
+func CommercialRealEstateToTurtle(c CommercialRealEstateData) (string, error) {
+	g := CommercialRealEstateToGraph(c)
+	if err := validateDocument(KindCommercial, g); err != nil {
+		return "", err
+	}
+	return g.Turtle(), nil
+}
+
+// CommercialRealEstateFromGraph reads a commercial listing back from its graph.
+func CommercialRealEstateFromGraph(g *rdf.Graph) (CommercialRealEstateData, error) {
+	s, err := documentRoot(g, rdf.SchemaNS+"RealEstateListing")
+	if err != nil {
+		return CommercialRealEstateData{}, err
+	}
+
+	c := CommercialRealEstateData{
+		ID:           stringValue(g, s, schema("identifier")),
+		Name:         stringValue(g, s, schema("name")),
+		Description:  stringValue(g, s, schema("description")),
+		PropertyType: stringValue(g, s, schema("additionalType")),
+		Categories:   stringValues(g, s, schema("category")),
+		PriceRange:   stringValue(g, s, schema("priceRange")),
+		Telephone:    stringValue(g, s, schema("telephone")),
+		Email:        stringValue(g, s, schema("email")),
+		URL:          stringValue(g, s, schema("url")),
+		Keywords:     stringValue(g, s, schema("keywords")),
+		Creator:      stringValue(g, s, dcterms("creator")),
+	}
+
+	if c.Latitude, _, err = floatValue(g, s, geo("lat")); err != nil {
+		return CommercialRealEstateData{}, err
+	}
+	if c.Longitude, _, err = floatValue(g, s, geo("long")); err != nil {
+		return CommercialRealEstateData{}, err
+	}
+	if address, ok := nodeOf(g, s, schema("address")); ok {
+		c.StreetAddress = stringValue(g, address, schema("streetAddress"))
+		c.Locality = stringValue(g, address, schema("addressLocality"))
+		c.Region = stringValue(g, address, schema("addressRegion"))
+		c.PostalCode = stringValue(g, address, schema("postalCode"))
+		c.Country = stringValue(g, address, schema("addressCountry"))
+	}
+	if size, ok := nodeOf(g, s, schema("floorSize")); ok {
+		if c.FloorSize, _, err = floatValue(g, size, schema("value")); err != nil {
+			return CommercialRealEstateData{}, err
+		}
+		c.FloorSizeUnit = stringValue(g, size, schema("unitText"))
+	}
+	if price, ok := nodeOf(g, s, schema("price")); ok {
+		if c.Price, _, err = floatValue(g, price, schema("price")); err != nil {
+			return CommercialRealEstateData{}, err
+		}
+		c.PriceCurrency = stringValue(g, price, schema("priceCurrency"))
+		c.PriceUnit = stringValue(g, price, schema("unitText"))
+	}
+	if c.Timestamp, err = timeValue(g, s, dcterms("created")); err != nil {
+		return CommercialRealEstateData{}, err
+	}
+
+	for id, field := range map[string]*string{
+		"status":           &c.Status,
+		"current_business": &c.CurrentBusiness,
+		"business_type":    &c.BusinessType,
+		"query_location":   &c.QueryLocation,
+		"data_source":      &c.DataSource,
+	} {
+		if value, ok := propertyValue(g, s, id); ok {
+			*field = value.Value
+		}
+	}
+	for id, field := range map[string]*float64{"query_lat": &c.QueryLat, "query_lng": &c.QueryLng} {
+		if value, ok := propertyValue(g, s, id); ok {
+			if *field, err = value.Float(); err != nil {
+				return CommercialRealEstateData{}, fmt.Errorf("%s: %w", id, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// CommercialRealEstateFromTurtle parses a commercial listing resource. base is the resource URL, if known.
+func CommercialRealEstateFromTurtle(data []byte, base string) (CommercialRealEstateData, error) {
+	g, err := rdf.ParseTurtle(data, base)
+	if err != nil {
+		return CommercialRealEstateData{}, err
+	}
+	return CommercialRealEstateFromGraph(g)
+}
@@ -0,0 +1,303 @@
+package solid
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProviderMetadata is the subset of the OpenID Provider configuration used by Solid-OIDC.
+type ProviderMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RegistrationEndpoint  string   `json:"registration_endpoint,omitempty"`
+	JWKSURI               string   `json:"jwks_uri"`
+	EndSessionEndpoint    string   `json:"end_session_endpoint,omitempty"`
+	RevocationEndpoint    string   `json:"revocation_endpoint,omitempty"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// TokenResponse is the token endpoint response of an authorization code exchange.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// ClientRegistration is the result of dynamic client registration (RFC 7591).
+type ClientRegistration struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// PKCE holds a code verifier and its S256 challenge (RFC 7636).
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// DefaultHTTPClient is used for all provider and pod requests when no client is given.
+var DefaultHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// Discover fetches the OpenID configuration of an issuer.
+func Discover(ctx context.Context, httpClient *http.Client, issuer string) (*ProviderMetadata, error) {
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	configURL := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenID configuration returned status %d", resp.StatusCode)
+	}
+
+	var meta ProviderMetadata
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenID configuration: %w", err)
+	}
+
+	// The issuer in the document must match the one we asked for (OIDC Discovery 4.3)
+	if strings.TrimRight(meta.Issuer, "/") != strings.TrimRight(issuer, "/") {
+		return nil, fmt.Errorf("issuer mismatch: expected %s, got %s", issuer, meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OpenID configuration is missing required endpoints")
+	}
+
+	return &meta, nil
+}
+
+// RegisterClient dynamically registers a public client with the provider.
+func RegisterClient(ctx context.Context, httpClient *http.Client, meta *ProviderMetadata, clientName string, redirectURIs []string) (*ClientRegistration, error) {
+	if meta.RegistrationEndpoint == "" {
+		return nil, fmt.Errorf("provider does not support dynamic client registration")
+	}
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"client_name":                clientName,
+		"redirect_uris":              redirectURIs,
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+		"application_type":           "web",
+		"scope":                      "openid webid offline_access",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode registration: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.RegistrationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registration request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register client: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("client registration returned status %d", resp.StatusCode)
+	}
+
+	var reg ClientRegistration
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&reg); err != nil {
+		return nil, fmt.Errorf("failed to parse client registration: %w", err)
+	}
+	if reg.ClientID == "" {
+		return nil, fmt.Errorf("client registration returned no client_id")
+	}
+	return &reg, nil
+}
+
+// NewPKCE generates a random code verifier and its S256 challenge.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// RandomToken returns n random bytes encoded as unpadded base64url.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthorizationURL builds the authorization code request URL with PKCE.
+func AuthorizationURL(meta *ProviderMetadata, clientID, redirectURI, state, nonce string, pkce *PKCE) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", "openid webid offline_access")
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", pkce.Challenge)
+	params.Set("code_challenge_method", "S256")
+	params.Set("prompt", "consent")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// ExchangeCode redeems an authorization code for DPoP-bound tokens.
+func ExchangeCode(ctx context.Context, httpClient *http.Client, meta *ProviderMetadata, clientID, code, redirectURI, codeVerifier string, key *DPoPKey) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", clientID)
+	form.Set("code_verifier", codeVerifier)
+	return requestToken(ctx, httpClient, meta.TokenEndpoint, form, key)
+}
+
+// RefreshTokens redeems a refresh token for a new access token bound to the same DPoP key.
+// Providers that rotate refresh tokens return a new one, which replaces the old.
+func RefreshTokens(ctx context.Context, httpClient *http.Client, meta *ProviderMetadata, clientID, refreshToken string, key *DPoPKey) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	form.Set("client_id", clientID)
+	return requestToken(ctx, httpClient, meta.TokenEndpoint, form, key)
+}
+
+// ErrRevocationUnsupported is returned by RevokeToken when the provider has no revocation endpoint.
+var ErrRevocationUnsupported = errors.New("provider does not support token revocation")
+
+// RevokeToken asks the provider to revoke an access or refresh token (RFC 7009).
+// tokenTypeHint is "access_token", "refresh_token" or empty.
+func RevokeToken(ctx context.Context, httpClient *http.Client, meta *ProviderMetadata, clientID, token, tokenTypeHint string) error {
+	if meta.RevocationEndpoint == "" {
+		return ErrRevocationUnsupported
+	}
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("client_id", clientID)
+	if tokenTypeHint != "" {
+		form.Set("token_type_hint", tokenTypeHint)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.RevocationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revocation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call revocation endpoint: %w", err)
+	}
+	resp.Body.Close()
+
+	// 200 also covers tokens the provider no longer knows (RFC 7009 2.2)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// requestToken posts a token request with a DPoP proof, retrying once if the server demands a nonce
+func requestToken(ctx context.Context, httpClient *http.Client, tokenEndpoint string, form url.Values, key *DPoPKey) (*TokenResponse, error) {
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	dpopNonce := ""
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+
+		proof, err := key.Proof(http.MethodPost, tokenEndpoint, "", dpopNonce)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("DPoP", proof)
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call token endpoint: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			var oauthErr struct {
+				Error       string `json:"error"`
+				Description string `json:"error_description"`
+			}
+			json.Unmarshal(body, &oauthErr)
+
+			if oauthErr.Error == "use_dpop_nonce" && dpopNonce == "" && resp.Header.Get("DPoP-Nonce") != "" {
+				dpopNonce = resp.Header.Get("DPoP-Nonce")
+				continue
+			}
+			if oauthErr.Error != "" {
+				return nil, fmt.Errorf("token endpoint returned %s: %s", oauthErr.Error, oauthErr.Description)
+			}
+			return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+		}
+
+		var token TokenResponse
+		if err := json.Unmarshal(body, &token); err != nil {
+			return nil, fmt.Errorf("failed to parse token response: %w", err)
+		}
+		if token.AccessToken == "" {
+			return nil, fmt.Errorf("token response has no access_token")
+		}
+		return &token, nil
+	}
+
+	return nil, fmt.Errorf("token endpoint kept rejecting the DPoP nonce")
+}
@@ -0,0 +1,497 @@
+// Package oidctest runs an in-process Solid-OIDC provider for exercising login and
+// token verification without a real identity provider, in the style of net/http/httptest.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenOptions control a token minted with IssueIDToken.
+type TokenOptions struct {
+	Algorithm string        // "RS256" (default) or "ES256"
+	Audience  []string      // defaults to {"solid"}
+	WebID     string        // defaults to the "alice" WebID
+	Nonce     string        // omitted when empty
+	JKT       string        // DPoP key thumbprint for cnf.jkt; unbound when empty
+	ExpiresIn time.Duration // defaults to one hour; negative values mint expired tokens
+	Issuer    string        // overrides iss, for testing issuer checks
+	KeyID     string        // overrides kid, for testing unknown keys
+}
+
+// authorization is an issued code waiting to be redeemed
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	webID         string
+}
+
+// grant is what a refresh token can be redeemed for
+type grant struct {
+	clientID string
+	webID    string
+	jkt      string
+}
+
+// Provider is a Solid-OIDC provider backed by an httptest.Server. It serves discovery,
+// JWKS, dynamic registration, an auto-approving authorization endpoint, a token endpoint
+// issuing DPoP-bound tokens and rotating refresh tokens, token revocation (RFC 7009),
+// and WebID profiles under /{name}/profile/card.
+type Provider struct {
+	Server *httptest.Server
+	Issuer string
+
+	mu            sync.Mutex
+	rsaKey        *rsa.PrivateKey
+	ecKey         *ecdsa.PrivateKey
+	keyGeneration int
+	codes         map[string]authorization
+	refreshTokens map[string]grant
+	revoked       map[string]bool
+	tokenLifetime time.Duration
+	nextClient    int
+	loginAs       string
+	jwksRequests  int
+}
+
+// NewProvider starts a provider on a local port. Close it when done.
+func NewProvider() *Provider {
+	p := &Provider{
+		codes:         make(map[string]authorization),
+		refreshTokens: make(map[string]grant),
+		revoked:       make(map[string]bool),
+		tokenLifetime: time.Hour,
+	}
+	p.generateKeys()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/jwks", p.handleJWKS)
+	mux.HandleFunc("/register", p.handleRegister)
+	mux.HandleFunc("/authorize", p.handleAuthorize)
+	mux.HandleFunc("/token", p.handleToken)
+	mux.HandleFunc("/revoke", p.handleRevoke)
+	mux.HandleFunc("/", p.handleProfile)
+
+	p.Server = httptest.NewServer(mux)
+	p.Issuer = p.Server.URL
+	return p
+}
+
+// Close shuts the server down.
+func (p *Provider) Close() {
+	p.Server.Close()
+}
+
+// Client returns an HTTP client that talks to the provider.
+func (p *Provider) Client() *http.Client {
+	return p.Server.Client()
+}
+
+// WebID returns the WebID of a user hosted by the provider.
+func (p *Provider) WebID(name string) string {
+	return fmt.Sprintf("%s/%s/profile/card#me", p.Issuer, name)
+}
+
+// LoginAs sets the WebID the authorization endpoint logs in (default: the "alice" WebID).
+func (p *Provider) LoginAs(webID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loginAs = webID
+}
+
+// JWKSRequests reports how often the key set was fetched, for checking caching.
+func (p *Provider) JWKSRequests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksRequests
+}
+
+// SetTokenLifetime sets expires_in for the access tokens the token endpoint issues (default: one hour).
+func (p *Provider) SetTokenLifetime(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokenLifetime = d
+}
+
+// Revoked reports whether a token was revoked at the revocation endpoint.
+func (p *Provider) Revoked(token string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.revoked[token]
+}
+
+// RotateKeys replaces the signing keys; tokens signed with the old keys stop verifying.
+func (p *Provider) RotateKeys() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generateKeys()
+}
+
+// generateKeys creates fresh RSA and EC signing keys (caller holds the lock, or p is unshared)
+func (p *Provider) generateKeys() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to generate RSA key: %v", err))
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to generate EC key: %v", err))
+	}
+	p.rsaKey = rsaKey
+	p.ecKey = ecKey
+	p.keyGeneration++
+}
+
+// keyID names the current key of an algorithm
+func (p *Provider) keyID(alg string) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(alg), p.keyGeneration)
+}
+
+// IssueIDToken mints a signed ID token.
+func (p *Provider) IssueIDToken(opts TokenOptions) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.issueLocked(opts)
+}
+
+// issueLocked mints a token; the caller holds p.mu
+func (p *Provider) issueLocked(opts TokenOptions) string {
+	if opts.Algorithm == "" {
+		opts.Algorithm = "RS256"
+	}
+	if len(opts.Audience) == 0 {
+		opts.Audience = []string{"solid"}
+	}
+	if opts.WebID == "" {
+		opts.WebID = p.WebID("alice")
+	}
+	if opts.ExpiresIn == 0 {
+		opts.ExpiresIn = time.Hour
+	}
+	if opts.Issuer == "" {
+		opts.Issuer = p.Issuer
+	}
+	if opts.KeyID == "" {
+		opts.KeyID = p.keyID(opts.Algorithm)
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":   opts.Issuer,
+		"sub":   opts.WebID,
+		"webid": opts.WebID,
+		"aud":   opts.Audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(opts.ExpiresIn).Unix(),
+	}
+	if opts.Nonce != "" {
+		claims["nonce"] = opts.Nonce
+	}
+	if opts.JKT != "" {
+		claims["cnf"] = map[string]string{"jkt": opts.JKT}
+	}
+	if len(opts.Audience) > 1 {
+		claims["azp"] = opts.Audience[0]
+	}
+
+	header := map[string]interface{}{"alg": opts.Algorithm, "kid": opts.KeyID, "typ": "JWT"}
+	return p.sign(header, claims, opts.Algorithm)
+}
+
+// sign produces a compact JWS with the provider's current key
+func (p *Provider) sign(header, claims map[string]interface{}, alg string) string {
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, p.ecKey, digest[:])
+		if err != nil {
+			panic(fmt.Sprintf("oidctest: failed to sign: %v", err))
+		}
+		signature = append(fixedBytes(r), fixedBytes(s)...)
+	default:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, p.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			panic(fmt.Sprintf("oidctest: failed to sign: %v", err))
+		}
+		signature = sig
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// fixedBytes left-pads a P-256 integer to 32 bytes
+func fixedBytes(n *big.Int) []byte {
+	b := n.Bytes()
+	padded := make([]byte, 32)
+	copy(padded[32-len(b):], b)
+	return padded
+}
+
+// writeJSON encodes v with a status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (p *Provider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"registration_endpoint":                 p.Issuer + "/register",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"revocation_endpoint":                   p.Issuer + "/revoke",
+		"scopes_supported":                      []string{"openid", "webid", "offline_access"},
+		"id_token_signing_alg_values_supported": []string{"RS256", "ES256"},
+		"dpop_signing_alg_values_supported":     []string{"ES256", "RS256"},
+	})
+}
+
+func (p *Provider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksRequests++
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": p.keyID("RS256"),
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(p.rsaKey.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.rsaKey.PublicKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": p.keyID("ES256"),
+				"use": "sig",
+				"alg": "ES256",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(fixedBytes(p.ecKey.PublicKey.X)),
+				"y":   base64.RawURLEncoding.EncodeToString(fixedBytes(p.ecKey.PublicKey.Y)),
+			},
+		},
+	})
+}
+
+func (p *Provider) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p.mu.Lock()
+	p.nextClient++
+	clientID := fmt.Sprintf("client-%d", p.nextClient)
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]string{"client_id": clientID})
+}
+
+// handleAuthorize approves every request immediately and redirects back with a code
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	target, err := url.Parse(redirectURI)
+	if err != nil || redirectURI == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "PKCE required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	webID := p.loginAs
+	if webID == "" {
+		webID = p.WebID("alice")
+	}
+	p.codes[code] = authorization{
+		clientID:      query.Get("client_id"),
+		redirectURI:   redirectURI,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		webID:         webID,
+	}
+	p.mu.Unlock()
+
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	params.Set("iss", p.Issuer)
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// handleToken redeems a code (checking PKCE) or a refresh token, binding the tokens to the DPoP key
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	jkt, err := proofThumbprint(r.Header.Get("DPoP"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_dpop_proof", "error_description": err.Error()})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") == "refresh_token" {
+		// Refresh tokens are single use and stay bound to the key they were issued to
+		refreshToken := r.PostForm.Get("refresh_token")
+		previous, ok := p.refreshTokens[refreshToken]
+		delete(p.refreshTokens, refreshToken)
+		if !ok || p.revoked[refreshToken] || previous.clientID != r.PostForm.Get("client_id") || previous.jkt != jkt {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		p.writeTokensLocked(w, previous)
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	auth, ok := p.codes[code]
+	delete(p.codes, code)
+	if !ok || auth.clientID != r.PostForm.Get("client_id") || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	opts := TokenOptions{Audience: []string{auth.clientID, "solid"}, WebID: auth.webID, Nonce: auth.nonce, JKT: jkt, ExpiresIn: p.tokenLifetime}
+	refreshToken := randomString()
+	p.refreshTokens[refreshToken] = grant{clientID: auth.clientID, webID: auth.webID, jkt: jkt}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  p.issueLocked(opts),
+		"token_type":    "DPoP",
+		"expires_in":    int(p.tokenLifetime.Seconds()),
+		"id_token":      p.issueLocked(opts),
+		"refresh_token": refreshToken,
+		"scope":         "openid webid offline_access",
+	})
+}
+
+// writeTokensLocked answers a refresh with a new access token and a rotated refresh token
+func (p *Provider) writeTokensLocked(w http.ResponseWriter, g grant) {
+	opts := TokenOptions{Audience: []string{g.clientID, "solid"}, WebID: g.webID, JKT: g.jkt, ExpiresIn: p.tokenLifetime}
+	refreshToken := randomString()
+	p.refreshTokens[refreshToken] = g
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  p.issueLocked(opts),
+		"token_type":    "DPoP",
+		"expires_in":    int(p.tokenLifetime.Seconds()),
+		"refresh_token": refreshToken,
+		"scope":         "openid webid offline_access",
+	})
+}
+
+// handleRevoke revokes any token it is given; unknown tokens still get 200 (RFC 7009 2.2)
+func (p *Provider) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	token := r.PostForm.Get("token")
+	p.revoked[token] = true
+	delete(p.refreshTokens, token)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleProfile serves /{name}/profile/card as a WebID profile naming this provider
+func (p *Provider) handleProfile(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[1] != "profile" || parts[2] != "card" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/turtle")
+	fmt.Fprintf(w, `@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+@prefix pim: <http://www.w3.org/ns/pim/space#> .
+
+<#me> a foaf:Person ;
+    foaf:name %q ;
+    solid:oidcIssuer <%s> ;
+    pim:storage </%s/> .
+`, parts[0], p.Issuer, parts[0])
+}
+
+// proofThumbprint reads the jwk from a DPoP proof header; the fake trusts the client's signature
+func proofThumbprint(proof string) (string, error) {
+	parts := strings.Split(proof, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("missing DPoP proof")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid DPoP header")
+	}
+	var header struct {
+		JWK map[string]string `json:"jwk"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.JWK == nil {
+		return "", fmt.Errorf("DPoP proof has no jwk")
+	}
+	jwk := header.JWK
+	var canonical string
+	switch jwk["kty"] {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk["crv"], jwk["x"], jwk["y"])
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk["e"], jwk["n"])
+	default:
+		return "", fmt.Errorf("unsupported DPoP key type")
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// randomString returns an unguessable code
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,130 @@
+package solid
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// SerializeToTurtle converts a location data structure to Turtle RDF format.
+// Based on the schema defined in SOLID_DATA_MODELS.md
+func SerializeToTurtle(data map[string]interface{}) (string, error) {
+	loc, err := locationFromMap(data)
+	if err != nil {
+		return "", err
+	}
+	return LocationToTurtle(loc)
+}
+
+// SerializeToJSONLD converts a location data structure to JSON-LD format.
+func SerializeToJSONLD(data map[string]interface{}) (string, error) {
+	loc, err := locationFromMap(data)
+	if err != nil {
+		return "", err
+	}
+	g := LocationToGraph(loc)
+	if err := validateDocument(KindLocation, g); err != nil {
+		return "", err
+	}
+	result, err := g.JSONLD(rdf.SchemaNS)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// DeserializeFromTurtle parses Turtle RDF and extracts location data.
+func DeserializeFromTurtle(turtle string) (map[string]interface{}, error) {
+	loc, err := LocationFromTurtle([]byte(turtle), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Turtle: %w", err)
+	}
+	return locationToMap(loc), nil
+}
+
+// DeserializeFromJSONLD parses JSON-LD and extracts location data.
+func DeserializeFromJSONLD(jsonld string) (map[string]interface{}, error) {
+	g, err := rdf.ParseJSONLD([]byte(jsonld), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON-LD: %w", err)
+	}
+	loc, err := LocationFromGraph(g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON-LD: %w", err)
+	}
+	return locationToMap(loc), nil
+}
+
+// locationFromMap reads the loosely typed location payload of the /api/rdf endpoints
+func locationFromMap(data map[string]interface{}) (LocationData, error) {
+	lat, ok := data["latitude"].(float64)
+	if !ok {
+		return LocationData{}, fmt.Errorf("latitude is required and must be a number")
+	}
+
+	lng, ok := data["longitude"].(float64)
+	if !ok {
+		return LocationData{}, fmt.Errorf("longitude is required and must be a number")
+	}
+
+	loc := LocationData{
+		Latitude:  lat,
+		Longitude: lng,
+		Accuracy:  10.0,
+		Timestamp: time.Now(),
+		DeviceID:  "unknown",
+	}
+	if accuracy, ok := data["accuracy"].(float64); ok {
+		loc.Accuracy = accuracy
+	}
+	if timestamp, ok := data["timestamp"].(string); ok && timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return LocationData{}, fmt.Errorf("timestamp must be an RFC 3339 date-time: %w", err)
+		}
+		loc.Timestamp = parsed
+	}
+	if simulated, ok := data["simulated"].(bool); ok {
+		loc.Simulated = simulated
+	}
+	for key, field := range map[string]*string{
+		"device_id": &loc.DeviceID,
+		"name":      &loc.Name,
+		"locality":  &loc.Locality,
+		"region":    &loc.Region,
+		"country":   &loc.Country,
+		"creator":   &loc.Creator,
+	} {
+		if value, ok := data[key].(string); ok && value != "" {
+			*field = value
+		}
+	}
+	return loc, nil
+}
+
+// locationToMap is the inverse of locationFromMap
+func locationToMap(loc LocationData) map[string]interface{} {
+	result := map[string]interface{}{
+		"latitude":  loc.Latitude,
+		"longitude": loc.Longitude,
+		"accuracy":  loc.Accuracy,
+		"device_id": loc.DeviceID,
+		"simulated": loc.Simulated,
+	}
+	if !loc.Timestamp.IsZero() {
+		result["timestamp"] = loc.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	for key, value := range map[string]string{
+		"name":     loc.Name,
+		"locality": loc.Locality,
+		"region":   loc.Region,
+		"country":  loc.Country,
+		"creator":  loc.Creator,
+	} {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,395 @@
+package solid
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is a logged-in WebID. When it holds tokens, ExpiresAt is when the access token
+// expires and a refresh token can extend it; without tokens ExpiresAt ends the session.
+type Session struct {
+	ID           string
+	WebID        string
+	Name         string
+	Photo        string
+	Issuer       string
+	ClientID     string
+	PodURL       string
+	AccessToken  string
+	RefreshToken string
+	Key          *DPoPKey
+	Attributes   map[string]string // app-defined, e.g. access levels
+	CreatedAt    time.Time
+	LastSeen     time.Time
+	ExpiresAt    time.Time
+}
+
+// Refreshable reports whether the access token can be renewed without the user.
+func (s *Session) Refreshable() bool {
+	return s.RefreshToken != "" && s.Key != nil && s.Issuer != ""
+}
+
+// clone copies a session so stores never share mutable state with callers
+func (s *Session) clone() *Session {
+	copied := *s
+	if s.Attributes != nil {
+		copied.Attributes = make(map[string]string, len(s.Attributes))
+		for k, v := range s.Attributes {
+			copied.Attributes[k] = v
+		}
+	}
+	return &copied
+}
+
+// ErrSessionNotFound is returned for unknown, expired and logged-out sessions.
+var ErrSessionNotFound = errors.New("solid: session not found")
+
+// SessionStore persists sessions by ID. Get returns ErrSessionNotFound for unknown IDs and
+// Delete of an unknown ID is not an error. Stores hand out copies: changing a returned
+// session has no effect until it is Put back.
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	Put(ctx context.Context, s *Session) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Session, error)
+}
+
+// MemorySessionStore keeps sessions in process memory; they are lost on restart.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewMemorySessionStore returns an empty in-memory store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]*Session)}
+}
+
+// Get returns a copy of the session.
+func (m *MemorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return s.clone(), nil
+}
+
+// Put stores a copy of the session.
+func (m *MemorySessionStore) Put(ctx context.Context, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = s.clone()
+	return nil
+}
+
+// Delete removes the session.
+func (m *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// List returns copies of all sessions.
+func (m *MemorySessionStore) List(ctx context.Context) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s.clone())
+	}
+	return sessions, nil
+}
+
+// SessionHandle is the key stores file a session under: a hash of its ID, since the ID
+// itself is a bearer credential (the session cookie).
+func SessionHandle(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// SessionCipher seals sessions for stores that write them outside the process. The
+// session ID, tokens and DPoP private key are encrypted with AES-256-GCM; the rest (WebID,
+// timestamps) stays readable so stores can expire records, but is authenticated with them.
+type SessionCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSessionCipher returns a cipher for a 32-byte key.
+func NewSessionCipher(key []byte) (*SessionCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionCipher{aead: aead}, nil
+}
+
+// ParseSessionKey decodes a 32-byte key given as 64 hex characters or base64.
+func ParseSessionKey(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if key, err := hex.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(value); err == nil && len(key) == 32 {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("session key must be 32 bytes as 64 hex characters or base64")
+}
+
+// sessionRecord is the stored form of a session
+type sessionRecord struct {
+	Handle     string            `json:"handle"`
+	WebID      string            `json:"webid"`
+	Name       string            `json:"name,omitempty"`
+	Photo      string            `json:"photo,omitempty"`
+	Issuer     string            `json:"issuer,omitempty"`
+	ClientID   string            `json:"client_id,omitempty"`
+	PodURL     string            `json:"pod_url,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastSeen   time.Time         `json:"last_seen"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Secrets    []byte            `json:"secrets,omitempty"` // nonce || AES-GCM(sessionSecrets), the rest as additional data
+}
+
+// sessionSecrets are the sealed fields of a session
+type sessionSecrets struct {
+	ID           string `json:"id"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	DPoPKey      []byte `json:"dpop_key,omitempty"`
+}
+
+// Seal encodes a session with its tokens and key encrypted.
+func (c *SessionCipher) Seal(s *Session) ([]byte, error) {
+	secrets := sessionSecrets{ID: s.ID, AccessToken: s.AccessToken, RefreshToken: s.RefreshToken}
+	if s.Key != nil {
+		der, err := s.Key.MarshalPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		secrets.DPoPKey = der
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session secrets: %w", err)
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	handle := SessionHandle(s.ID)
+	record := sessionRecord{
+		Handle:     handle,
+		WebID:      s.WebID,
+		Name:       s.Name,
+		Photo:      s.Photo,
+		Issuer:     s.Issuer,
+		ClientID:   s.ClientID,
+		PodURL:     s.PodURL,
+		Attributes: s.Attributes,
+		CreatedAt:  s.CreatedAt,
+		LastSeen:   s.LastSeen,
+		ExpiresAt:  s.ExpiresAt,
+	}
+	metadata, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	record.Secrets = c.aead.Seal(nonce, nonce, plaintext, metadata)
+	return json.Marshal(record)
+}
+
+// Open decodes a sealed session. It fails if any part of the record was changed, or it
+// was sealed with a different key.
+func (c *SessionCipher) Open(data []byte) (*Session, error) {
+	var record sessionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	sealed := record.Secrets
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("session %s has no sealed secrets", record.Handle)
+	}
+	record.Secrets = nil
+	metadata, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt session: %w", err)
+	}
+	var secrets sessionSecrets
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to decode session secrets: %w", err)
+	}
+	if SessionHandle(secrets.ID) != record.Handle {
+		return nil, fmt.Errorf("session %s was sealed under another handle", record.Handle)
+	}
+
+	s := &Session{
+		ID:           secrets.ID,
+		WebID:        record.WebID,
+		Name:         record.Name,
+		Photo:        record.Photo,
+		Issuer:       record.Issuer,
+		ClientID:     record.ClientID,
+		PodURL:       record.PodURL,
+		AccessToken:  secrets.AccessToken,
+		RefreshToken: secrets.RefreshToken,
+		Attributes:   record.Attributes,
+		CreatedAt:    record.CreatedAt,
+		LastSeen:     record.LastSeen,
+		ExpiresAt:    record.ExpiresAt,
+	}
+	if len(secrets.DPoPKey) > 0 {
+		if s.Key, err = ParseDPoPKey(secrets.DPoPKey); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// FileSessionStore keeps each session sealed in its own file, named by its handle, so
+// sessions survive restarts of a single instance.
+type FileSessionStore struct {
+	dir    string
+	cipher *SessionCipher
+	mu     sync.Mutex
+}
+
+// NewFileSessionStore stores sessions under dir, creating it (mode 0700) if needed.
+func NewFileSessionStore(dir string, c *SessionCipher) (*FileSessionStore, error) {
+	if c == nil {
+		return nil, fmt.Errorf("file session store needs a session cipher")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir, cipher: c}, nil
+}
+
+// path returns the file of a session ID
+func (f *FileSessionStore) path(id string) string {
+	return filepath.Join(f.dir, SessionHandle(id)+".session")
+}
+
+// Get reads and decrypts the session.
+func (f *FileSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := os.ReadFile(f.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	s, err := f.cipher.Open(data)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID != id {
+		return nil, ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// Put writes the session atomically (mode 0600).
+func (f *FileSessionStore) Put(ctx context.Context, s *Session) error {
+	data, err := f.cipher.Seal(s)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(f.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(s.ID)); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the session file.
+func (f *FileSessionStore) Delete(ctx context.Context, id string) error {
+	err := os.Remove(f.path(id))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// List reads every session. Files that no longer decrypt (e.g. after a key change)
+// are skipped; they are removed once their session would have expired anyway.
+func (f *FileSessionStore) List(ctx context.Context) ([]*Session, error) {
+	paths, err := filepath.Glob(filepath.Join(f.dir, "*.session"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		s, err := f.cipher.Open(data)
+		if err != nil {
+			f.removeStale(path, data)
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// staleSessionAge is how long an undecryptable session file is kept before removal
+const staleSessionAge = 30 * 24 * time.Hour
+
+// removeStale deletes an unreadable session file once it is past any session lifetime
+func (f *FileSessionStore) removeStale(path string, data []byte) {
+	var record sessionRecord
+	if json.Unmarshal(data, &record) == nil && time.Since(record.LastSeen) < staleSessionAge {
+		return
+	}
+	os.Remove(path)
+}
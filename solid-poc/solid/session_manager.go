@@ -0,0 +1,258 @@
+package solid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Session manager defaults
+const (
+	DefaultIdleTimeout   = 24 * time.Hour
+	DefaultMaxLifetime   = 30 * 24 * time.Hour
+	DefaultRefreshBefore = 5 * time.Minute
+	sessionTouchInterval = time.Minute
+)
+
+// SessionManager keeps sessions in a store and alive: it expires idle sessions, refreshes
+// access tokens before ExpiresAt, and revokes tokens at the provider on logout.
+type SessionManager struct {
+	Store SessionStore
+
+	// Metadata looks up a provider's configuration, e.g. KeyCache.Metadata
+	Metadata func(ctx context.Context, issuer string) (*ProviderMetadata, error)
+	HTTP     *http.Client
+
+	IdleTimeout   time.Duration // since LastSeen; zero disables
+	MaxLifetime   time.Duration // since CreatedAt, however often the token is refreshed; zero disables
+	RefreshBefore time.Duration // refresh access tokens expiring within this window
+
+	// refreshMutex keeps two refreshes of one session (a request and a sweep) from both
+	// redeeming the same rotating refresh token
+	refreshMutex sync.Mutex
+}
+
+// NewSessionManager returns a manager with the default timeouts.
+func NewSessionManager(store SessionStore, metadata func(ctx context.Context, issuer string) (*ProviderMetadata, error)) *SessionManager {
+	return &SessionManager{
+		Store:         store,
+		Metadata:      metadata,
+		IdleTimeout:   DefaultIdleTimeout,
+		MaxLifetime:   DefaultMaxLifetime,
+		RefreshBefore: DefaultRefreshBefore,
+	}
+}
+
+// Create stores a new session, stamping CreatedAt and LastSeen.
+func (m *SessionManager) Create(ctx context.Context, s *Session) error {
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.LastSeen = now
+	return m.Store.Put(ctx, s)
+}
+
+// ended reports whether a session is over: idle too long, too old, or holding an
+// expired access token it cannot refresh
+func (m *SessionManager) ended(s *Session, now time.Time) bool {
+	if m.IdleTimeout > 0 && now.Sub(s.LastSeen) > m.IdleTimeout {
+		return true
+	}
+	if m.MaxLifetime > 0 && now.Sub(s.CreatedAt) > m.MaxLifetime {
+		return true
+	}
+	return now.After(s.ExpiresAt) && !s.Refreshable()
+}
+
+// Expiry is when the session ends if it stays idle: the idle timeout, the maximum
+// lifetime or, without a refresh token, ExpiresAt, whichever comes first. It suits
+// cookie lifetimes.
+func (m *SessionManager) Expiry(s *Session) time.Time {
+	var expiry time.Time
+	earliest := func(t time.Time) {
+		if expiry.IsZero() || t.Before(expiry) {
+			expiry = t
+		}
+	}
+	if m.IdleTimeout > 0 {
+		earliest(s.LastSeen.Add(m.IdleTimeout))
+	}
+	if m.MaxLifetime > 0 {
+		earliest(s.CreatedAt.Add(m.MaxLifetime))
+	}
+	if !s.Refreshable() {
+		earliest(s.ExpiresAt)
+	}
+	return expiry
+}
+
+// Get returns a live session and records the activity. An access token that already
+// expired (say, while the server was down) is refreshed before returning; a session that
+// ended is deleted and reported as ErrSessionNotFound.
+func (m *SessionManager) Get(ctx context.Context, id string) (*Session, error) {
+	s, err := m.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if m.ended(s, now) {
+		m.Store.Delete(ctx, id)
+		return nil, ErrSessionNotFound
+	}
+	if now.After(s.ExpiresAt) {
+		if s, err = m.Refresh(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if now.Sub(s.LastSeen) >= sessionTouchInterval {
+		s.LastSeen = now
+		if err := m.Store.Put(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// List returns the sessions that have not ended.
+func (m *SessionManager) List(ctx context.Context) ([]*Session, error) {
+	sessions, err := m.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	live := sessions[:0]
+	for _, s := range sessions {
+		if !m.ended(s, now) && now.Before(s.ExpiresAt) {
+			live = append(live, s)
+		}
+	}
+	return live, nil
+}
+
+// Refresh redeems the session's refresh token and stores the new tokens. If another
+// caller refreshed the session in the meantime its tokens are returned as they are.
+func (m *SessionManager) Refresh(ctx context.Context, id string) (*Session, error) {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
+	s, err := m.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Until(s.ExpiresAt) > m.RefreshBefore {
+		return s, nil
+	}
+	if !s.Refreshable() {
+		return nil, fmt.Errorf("session for %s has no refresh token", s.WebID)
+	}
+
+	meta, err := m.Metadata(ctx, s.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	token, err := RefreshTokens(ctx, m.HTTP, meta, s.ClientID, s.RefreshToken, s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session for %s: %w", s.WebID, err)
+	}
+
+	expiresIn := time.Duration(token.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	s.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		s.RefreshToken = token.RefreshToken
+	}
+	s.ExpiresAt = time.Now().Add(expiresIn)
+	if err := m.Store.Put(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Logout deletes the session and revokes its tokens at the provider when it supports
+// revocation. The session is gone even if revocation fails; the error says so.
+func (m *SessionManager) Logout(ctx context.Context, id string) (*Session, error) {
+	s, err := m.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Store.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	if s.Issuer == "" || (s.AccessToken == "" && s.RefreshToken == "") {
+		return s, nil
+	}
+
+	meta, err := m.Metadata(ctx, s.Issuer)
+	if err != nil {
+		return s, fmt.Errorf("could not revoke tokens: %w", err)
+	}
+	// Revoking the refresh token first ends the grant; the access token may outlive it
+	var revokeErrors []error
+	for _, token := range []struct{ value, hint string }{
+		{s.RefreshToken, "refresh_token"},
+		{s.AccessToken, "access_token"},
+	} {
+		if token.value == "" {
+			continue
+		}
+		err := RevokeToken(ctx, m.HTTP, meta, s.ClientID, token.value, token.hint)
+		if errors.Is(err, ErrRevocationUnsupported) {
+			return s, nil
+		}
+		if err != nil {
+			revokeErrors = append(revokeErrors, err)
+		}
+	}
+	return s, errors.Join(revokeErrors...)
+}
+
+// SweepResult counts what a Sweep did.
+type SweepResult struct {
+	Refreshed int
+	Removed   int
+	Failed    map[string]error // refresh failures by WebID
+}
+
+// Sweep removes ended sessions and refreshes access tokens due within RefreshBefore.
+// Run it periodically, more often than RefreshBefore.
+func (m *SessionManager) Sweep(ctx context.Context) (SweepResult, error) {
+	result := SweepResult{Failed: make(map[string]error)}
+	sessions, err := m.Store.List(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	for _, s := range sessions {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		switch {
+		case m.ended(s, now):
+			if err := m.Store.Delete(ctx, s.ID); err == nil {
+				result.Removed++
+			}
+		case s.Refreshable() && s.ExpiresAt.Sub(now) <= m.RefreshBefore:
+			// A failed refresh is retried next sweep, until the access token has expired
+			_, err := m.Refresh(ctx, s.ID)
+			switch {
+			case err == nil:
+				result.Refreshed++
+			case now.After(s.ExpiresAt):
+				result.Failed[s.WebID] = err
+				if m.Store.Delete(ctx, s.ID) == nil {
+					result.Removed++
+				}
+			default:
+				result.Failed[s.WebID] = err
+			}
+		}
+	}
+	return result, nil
+}
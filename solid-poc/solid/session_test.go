@@ -0,0 +1,369 @@
+package solid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid/oidctest"
+)
+
+const testRedirectURI = "https://app.example/callback"
+
+// login runs the authorization code flow against the provider and returns its metadata
+// and a session holding the DPoP-bound tokens
+func login(t *testing.T, provider *oidctest.Provider) (*solid.ProviderMetadata, *solid.Session) {
+	t.Helper()
+	ctx := context.Background()
+	client := provider.Client()
+
+	meta, err := solid.Discover(ctx, client, provider.Issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registration, err := solid.RegisterClient(ctx, client, meta, "session test", []string{testRedirectURI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkce, err := solid.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	response, err := noRedirect.Get(solid.AuthorizationURL(meta, registration.ClientID, testRedirectURI, "state", "nonce", pkce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	callback, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := solid.NewDPoPKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := solid.ExchangeCode(ctx, client, meta, registration.ClientID, callback.Query().Get("code"), testRedirectURI, pkce.Verifier, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := solid.RandomToken(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return meta, &solid.Session{
+		ID:           id,
+		WebID:        provider.WebID("alice"),
+		Issuer:       provider.Issuer,
+		ClientID:     registration.ClientID,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Key:          key,
+		ExpiresAt:    time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}
+}
+
+func newSessionManager(provider *oidctest.Provider, meta *solid.ProviderMetadata) *solid.SessionManager {
+	manager := solid.NewSessionManager(solid.NewMemorySessionStore(), func(ctx context.Context, issuer string) (*solid.ProviderMetadata, error) {
+		return meta, nil
+	})
+	manager.HTTP = provider.Client()
+	return manager
+}
+
+func newSessionCipher(t *testing.T, fill byte) *solid.SessionCipher {
+	t.Helper()
+	c, err := solid.NewSessionCipher(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSessionCipherSealOpen(t *testing.T) {
+	key, err := solid.NewDPoPKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	session := &solid.Session{
+		ID:           "session-id",
+		WebID:        "https://alice.example/profile/card#me",
+		Issuer:       "https://idp.example",
+		ClientID:     "client-1",
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		Key:          key,
+		Attributes:   map[string]string{"access": "full"},
+		CreatedAt:    now,
+		LastSeen:     now,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	sessionCipher := newSessionCipher(t, 1)
+	sealed, err := sessionCipher.Seal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{session.ID, session.AccessToken, session.RefreshToken} {
+		if bytes.Contains(sealed, []byte(`"`+secret+`"`)) {
+			t.Errorf("sealed record contains %q in the clear", secret)
+		}
+	}
+
+	opened, err := sessionCipher.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened.ID != session.ID || opened.AccessToken != "access-token" || opened.RefreshToken != "refresh-token" ||
+		opened.WebID != session.WebID || !opened.ExpiresAt.Equal(session.ExpiresAt) || opened.Attributes["access"] != "full" {
+		t.Errorf("opened session = %+v", opened)
+	}
+	if opened.Key == nil || opened.Key.Thumbprint() != key.Thumbprint() {
+		t.Error("DPoP key did not survive sealing")
+	}
+
+	other, err := newSessionCipher(t, 2).Seal(&solid.Session{ID: "other-id", WebID: "https://mallory.example/profile/card#me"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(record map[string]interface{})
+	}{
+		{"WebID", func(r map[string]interface{}) { r["webid"] = "https://mallory.example/profile/card#me" }},
+		{"expiry", func(r map[string]interface{}) { r["expires_at"] = now.Add(24 * time.Hour).Format(time.RFC3339) }},
+		{"last seen", func(r map[string]interface{}) { r["last_seen"] = now.Add(time.Hour).Format(time.RFC3339) }},
+		{"attributes", func(r map[string]interface{}) { r["attributes"] = map[string]string{"access": "admin"} }},
+		{"handle", func(r map[string]interface{}) { r["handle"] = solid.SessionHandle("other-id") }},
+		{"secrets", func(r map[string]interface{}) {
+			secrets := decodeSecrets(t, r)
+			secrets[len(secrets)-1] ^= 1
+			r["secrets"] = secrets
+		}},
+		{"truncated secrets", func(r map[string]interface{}) { r["secrets"] = decodeSecrets(t, r)[:8] }},
+		{"secrets of another record", func(r map[string]interface{}) {
+			var record map[string]interface{}
+			json.Unmarshal(other, &record)
+			r["secrets"] = record["secrets"]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var record map[string]interface{}
+			if err := json.Unmarshal(sealed, &record); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(record)
+			tampered, err := json.Marshal(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, err := sessionCipher.Open(tampered); err == nil {
+				t.Fatalf("tampered record opened: %+v", s)
+			}
+		})
+	}
+
+	if _, err := newSessionCipher(t, 2).Open(sealed); err == nil {
+		t.Error("record opened with another key")
+	}
+}
+
+func decodeSecrets(t *testing.T, record map[string]interface{}) []byte {
+	t.Helper()
+	var secrets []byte
+	data, _ := json.Marshal(record["secrets"])
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		t.Fatal(err)
+	}
+	return secrets
+}
+
+func TestFileSessionStoreTampered(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := solid.NewFileSessionStore(dir, newSessionCipher(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := &solid.Session{ID: "session-id", WebID: "https://alice.example/profile/card#me", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Put(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Get(ctx, session.ID); err != nil || got.WebID != session.WebID {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+
+	path := filepath.Join(dir, solid.SessionHandle(session.ID)+".session")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(data, []byte("alice.example"), []byte("malice.xample"), 1)
+	if err := os.WriteFile(path, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Get(ctx, session.ID); err == nil {
+		t.Fatalf("tampered session file read back: %+v", got)
+	}
+	if sessions, err := store.List(ctx); err != nil || len(sessions) != 0 {
+		t.Errorf("List = %d sessions, %v; want the tampered one skipped", len(sessions), err)
+	}
+}
+
+func TestSessionManagerSweep(t *testing.T) {
+	ctx := context.Background()
+	provider := oidctest.NewProvider()
+	defer provider.Close()
+
+	meta, due := login(t, provider)
+	manager := newSessionManager(provider, meta)
+	now := time.Now()
+
+	// Expires within RefreshBefore: refreshed with rotated tokens
+	due.ExpiresAt = now.Add(manager.RefreshBefore / 2)
+	if err := manager.Create(ctx, due); err != nil {
+		t.Fatal(err)
+	}
+	// Idle past IdleTimeout: removed, even though its token is fresh
+	_, idle := login(t, provider)
+	idle.LastSeen = now.Add(-manager.IdleTimeout - time.Minute)
+	idle.CreatedAt = idle.LastSeen
+	// Past MaxLifetime, however recently it was used
+	_, old := login(t, provider)
+	old.CreatedAt = now.Add(-manager.MaxLifetime - time.Minute)
+	old.LastSeen = now
+	// Expired without a refresh token: removed
+	expired := &solid.Session{ID: "no-refresh", WebID: provider.WebID("bob"), CreatedAt: now, LastSeen: now, ExpiresAt: now.Add(-time.Minute)}
+	// Fresh: left alone
+	_, fresh := login(t, provider)
+	fresh.CreatedAt, fresh.LastSeen = now, now
+	for _, s := range []*solid.Session{idle, old, expired, fresh} {
+		if err := manager.Store.Put(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := manager.Sweep(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Refreshed != 1 || result.Removed != 3 || len(result.Failed) != 0 {
+		t.Errorf("Sweep = %+v, want 1 refreshed and 3 removed", result)
+	}
+
+	refreshed, err := manager.Store.Get(ctx, due.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.AccessToken == due.AccessToken || refreshed.RefreshToken == due.RefreshToken {
+		t.Error("tokens were not replaced by the refresh")
+	}
+	if time.Until(refreshed.ExpiresAt) < 50*time.Minute {
+		t.Errorf("refreshed session expires at %s, want about an hour from now", refreshed.ExpiresAt)
+	}
+	// The old refresh token was single use
+	if _, err := solid.RefreshTokens(ctx, provider.Client(), meta, due.ClientID, due.RefreshToken, due.Key); err == nil {
+		t.Error("the rotated-out refresh token still works")
+	}
+
+	for _, s := range []*solid.Session{idle, old, expired} {
+		if _, err := manager.Store.Get(ctx, s.ID); !errors.Is(err, solid.ErrSessionNotFound) {
+			t.Errorf("session %s still stored after Sweep: %v", s.WebID, err)
+		}
+	}
+	if got, err := manager.Store.Get(ctx, fresh.ID); err != nil || got.AccessToken != fresh.AccessToken {
+		t.Errorf("fresh session changed by Sweep: %+v, %v", got, err)
+	}
+}
+
+func TestSessionManagerRefreshFailure(t *testing.T) {
+	ctx := context.Background()
+	provider := oidctest.NewProvider()
+	defer provider.Close()
+
+	meta, s := login(t, provider)
+	manager := newSessionManager(provider, meta)
+	s.RefreshToken = "not-issued"
+
+	// Still valid: the failure is reported and retried next sweep
+	s.ExpiresAt = time.Now().Add(time.Minute)
+	if err := manager.Create(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	result, err := manager.Sweep(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed[s.WebID] == nil || result.Removed != 0 {
+		t.Errorf("Sweep = %+v, want a failure and the session kept", result)
+	}
+
+	// Already expired: nothing left to retry with
+	s.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := manager.Store.Put(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := manager.Sweep(ctx); result.Removed != 1 {
+		t.Errorf("Sweep = %+v, want the expired session removed", result)
+	}
+}
+
+func TestSessionManagerLogout(t *testing.T) {
+	ctx := context.Background()
+	provider := oidctest.NewProvider()
+	defer provider.Close()
+
+	t.Run("revocation endpoint", func(t *testing.T) {
+		meta, s := login(t, provider)
+		manager := newSessionManager(provider, meta)
+		if err := manager.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := manager.Logout(ctx, s.ID); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		if !provider.Revoked(s.RefreshToken) || !provider.Revoked(s.AccessToken) {
+			t.Errorf("revoked refresh %v, access %v; want both", provider.Revoked(s.RefreshToken), provider.Revoked(s.AccessToken))
+		}
+		if _, err := manager.Get(ctx, s.ID); !errors.Is(err, solid.ErrSessionNotFound) {
+			t.Errorf("Get after Logout = %v, want ErrSessionNotFound", err)
+		}
+		if _, err := solid.RefreshTokens(ctx, provider.Client(), meta, s.ClientID, s.RefreshToken, s.Key); err == nil {
+			t.Error("revoked refresh token still works")
+		}
+	})
+
+	t.Run("no revocation endpoint", func(t *testing.T) {
+		meta, s := login(t, provider)
+		withoutRevocation := *meta
+		withoutRevocation.RevocationEndpoint = ""
+		manager := newSessionManager(provider, &withoutRevocation)
+		if err := manager.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := manager.Logout(ctx, s.ID); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		if provider.Revoked(s.RefreshToken) || provider.Revoked(s.AccessToken) {
+			t.Error("tokens revoked at an endpoint the provider did not advertise")
+		}
+		if _, err := manager.Store.Get(ctx, s.ID); !errors.Is(err, solid.ErrSessionNotFound) {
+			t.Errorf("session still stored after Logout: %v", err)
+		}
+	})
+}
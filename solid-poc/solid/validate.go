@@ -0,0 +1,173 @@
+package solid
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+	"github.com/justin4957/ec2-test-apps/solid-poc/shacl"
+)
+
+// Document kinds with their own SHACL shapes (shapes/<kind>.ttl)
+const (
+	KindLocation   = "location"
+	KindErrorLog   = "errorlog"
+	KindTip        = "tip"
+	KindCommercial = "commercial"
+)
+
+// Kinds lists every document kind that has shapes.
+var Kinds = []string{KindLocation, KindErrorLog, KindTip, KindCommercial}
+
+//go:embed shapes/*.ttl
+var shapeFiles embed.FS
+
+var (
+	shapesOnce   sync.Once
+	shapesByKind map[string]*shacl.Shapes
+	shapesErr    error
+)
+
+// loadShapes parses shapes/common.ttl together with each kind's shapes file
+func loadShapes() {
+	common, err := shapeFiles.ReadFile("shapes/common.ttl")
+	if err != nil {
+		shapesErr = err
+		return
+	}
+	shapesByKind = make(map[string]*shacl.Shapes, len(Kinds))
+	for _, kind := range Kinds {
+		data, err := shapeFiles.ReadFile("shapes/" + kind + ".ttl")
+		if err != nil {
+			shapesErr = err
+			return
+		}
+		g, err := rdf.ParseTurtle(common, "")
+		if err != nil {
+			shapesErr = fmt.Errorf("shapes/common.ttl: %w", err)
+			return
+		}
+		own, err := rdf.ParseTurtle(data, "")
+		if err != nil {
+			shapesErr = fmt.Errorf("shapes/%s.ttl: %w", kind, err)
+			return
+		}
+		g.Merge(own)
+		if shapesByKind[kind], err = shacl.Parse(g); err != nil {
+			shapesErr = fmt.Errorf("shapes/%s.ttl: %w", kind, err)
+			return
+		}
+	}
+}
+
+// ShapesFor returns the SHACL shapes a document kind is validated against.
+func ShapesFor(kind string) (*shacl.Shapes, error) {
+	shapesOnce.Do(loadShapes)
+	if shapesErr != nil {
+		return nil, shapesErr
+	}
+	shapes, ok := shapesByKind[kind]
+	if !ok {
+		return nil, fmt.Errorf("no shapes for document kind %q", kind)
+	}
+	return shapes, nil
+}
+
+// Validate checks a document graph against the shapes of its kind.
+func Validate(kind string, g *rdf.Graph) (*shacl.Report, error) {
+	shapes, err := ShapesFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	return shapes.Validate(g), nil
+}
+
+// validateDocument fails on any sh:Violation; warnings are let through
+func validateDocument(kind string, g *rdf.Graph) error {
+	report, err := Validate(kind, g)
+	if err != nil {
+		return err
+	}
+	if err := report.Err(); err != nil {
+		return fmt.Errorf("%s document: %w", kind, err)
+	}
+	return nil
+}
+
+// KindForPath guesses the document kind from a file name such as
+// location-example.ttl or commercial-2025-11-12.ttl.
+func KindForPath(path string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	for _, kind := range Kinds {
+		if strings.HasPrefix(name, kind) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// ExampleResult is the outcome of validating one example file.
+type ExampleResult struct {
+	Path   string
+	Kind   string
+	Report *shacl.Report
+	Err    error // parse or lookup failure; Report is nil
+}
+
+// Conforms reports whether the file parsed and has no violations.
+func (r ExampleResult) Conforms() bool {
+	return r.Err == nil && r.Report.Err() == nil
+}
+
+// CheckExamples validates every Turtle and JSON-LD file in dir against the
+// shapes of the kind named by its file name. It is the conformance suite for
+// rdf-schemas/examples, run by validate-rdf and TestExamplesConform.
+func CheckExamples(dir string) ([]ExampleResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, err := rdf.MediaTypeForPath(entry.Name()); err != nil {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no Turtle or JSON-LD files in %s", dir)
+	}
+
+	results := make([]ExampleResult, 0, len(paths))
+	for _, path := range paths {
+		results = append(results, CheckFile(path))
+	}
+	return results, nil
+}
+
+// CheckFile validates one Turtle or JSON-LD file against the shapes of its kind.
+func CheckFile(path string) ExampleResult {
+	result := ExampleResult{Path: path}
+	kind, ok := KindForPath(path)
+	if !ok {
+		result.Err = fmt.Errorf("cannot tell the document kind from the file name (want a %s prefix)", strings.Join(Kinds, ", "))
+		return result
+	}
+	result.Kind = kind
+	g, err := rdf.ParseFile(path, "")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Report, result.Err = Validate(kind, g)
+	return result
+}
@@ -0,0 +1,93 @@
+package solid
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// TestExamplesConform validates every file in rdf-schemas/examples against the
+// shapes of its kind, and expects each kind to have at least one example.
+func TestExamplesConform(t *testing.T) {
+	results, err := CheckExamples(filepath.Join("..", "..", "rdf-schemas", "examples"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := make(map[string]bool)
+	for _, result := range results {
+		name := filepath.Base(result.Path)
+		if result.Err != nil {
+			t.Errorf("%s: %v", name, result.Err)
+			continue
+		}
+		if !result.Conforms() {
+			t.Errorf("%s does not conform to the %s shapes:\n%s", name, result.Kind, result.Report)
+		}
+		covered[result.Kind] = true
+	}
+	for _, kind := range Kinds {
+		if !covered[kind] {
+			t.Errorf("no example for document kind %q", kind)
+		}
+	}
+}
+
+// TestInvalidDocumentsRejected checks the validator reports the expected
+// violations for the fixtures in testdata.
+func TestInvalidDocumentsRejected(t *testing.T) {
+	tests := []struct {
+		file       string
+		kind       string
+		components []string
+	}{
+		{
+			file: "location-invalid.ttl",
+			kind: KindLocation,
+			components: []string{
+				"MaxInclusiveConstraintComponent", // geo:lat 137.5
+				"MinCountConstraintComponent",     // no dcterms:created
+				"PatternConstraintComponent",      // http:// map link
+			},
+		},
+		{
+			file: "tip-invalid.jsonld",
+			kind: KindTip,
+			components: []string{
+				"MinCountConstraintComponent",     // no recipient
+				"MinExclusiveConstraintComponent", // value 0
+				"PatternConstraintComponent",      // lowercase currency, startTime
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			result := CheckFile(filepath.Join("testdata", tt.file))
+			if result.Err != nil {
+				t.Fatal(result.Err)
+			}
+			if result.Kind != tt.kind {
+				t.Errorf("kind = %q, want %q", result.Kind, tt.kind)
+			}
+			if result.Conforms() {
+				t.Fatalf("%s conforms, want violations", tt.file)
+			}
+
+			found := make(map[string]bool)
+			for _, violation := range result.Report.Violations() {
+				found[violation.Component] = true
+			}
+			for _, component := range tt.components {
+				if !found[component] {
+					got := make([]string, 0, len(found))
+					for c := range found {
+						got = append(got, c)
+					}
+					sort.Strings(got)
+					t.Errorf("no sh:%s violation; got %v\n%s", component, got, result.Report)
+				}
+			}
+		})
+	}
+}
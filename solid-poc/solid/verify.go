@@ -0,0 +1,401 @@
+package solid
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// dpopProofLifetime bounds how far a DPoP proof's iat may be from now
+const dpopProofLifetime = 5 * time.Minute
+
+// jwtHeader is the JOSE header of a compact JWS
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+	JWK       *JWK   `json:"jwk,omitempty"`
+}
+
+// parsedJWT is a split, decoded compact JWS
+type parsedJWT struct {
+	header       jwtHeader
+	payload      []byte
+	signingInput string
+	signature    []byte
+}
+
+// parseJWT splits and decodes a compact JWS without verifying it
+func parseJWT(token string) (*parsedJWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT header: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT payload: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT signature: %w", err)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT header: %w", err)
+	}
+	return &parsedJWT{
+		header:       header,
+		payload:      payload,
+		signingInput: parts[0] + "." + parts[1],
+		signature:    signature,
+	}, nil
+}
+
+// supportedAlgorithm limits tokens to RS256 and ES256; "none" and HMAC are always refused
+func supportedAlgorithm(alg string) bool {
+	return alg == "RS256" || alg == "ES256"
+}
+
+// verifySignature checks an RS256 or ES256 signature
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("RS256 requires an RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("ES256 requires an EC key")
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("invalid ES256 signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+// JWKThumbprint returns the RFC 7638 thumbprint of a public EC or RSA key.
+func JWKThumbprint(k JWK) (string, error) {
+	var canonical string
+	switch k.KeyType {
+	case "EC":
+		// Members in lexicographic order, no whitespace
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Curve, k.X, k.Y)
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// VerifyOptions are the per-login expectations for a token.
+type VerifyOptions struct {
+	Issuer string // if set, the token must come from this issuer
+	Nonce  string // if set, the token's nonce must match
+}
+
+// Verifier checks Solid-OIDC ID tokens: RS256/ES256 signatures against each trusted
+// issuer's JWKS, iss/aud/exp/iat/nonce claims, and DPoP binding (cnf.jkt).
+type Verifier struct {
+	Keys      *KeyCache
+	Issuers   map[string]bool // trusted issuers, without trailing slash
+	Audiences []string        // accepted aud values (client IDs)
+	Now       func() time.Time
+
+	// CheckWebIDIssuer makes the verifier confirm that the WebID profile lists the
+	// token's issuer as solid:oidcIssuer, as Solid-OIDC requires
+	CheckWebIDIssuer bool
+
+	// AnyIssuer accepts issuers outside Issuers, relying on the WebID profile to
+	// vouch for them. It has no effect unless CheckWebIDIssuer is also set.
+	AnyIssuer bool
+
+	mu       sync.Mutex
+	seenJTIs map[string]time.Time // DPoP proof replay cache
+}
+
+// NewVerifier returns a verifier trusting the given issuers and audiences. With no
+// issuers every token is refused, unless AnyIssuer is set.
+func NewVerifier(issuers, audiences []string, httpClient *http.Client) *Verifier {
+	trusted := make(map[string]bool, len(issuers))
+	for _, issuer := range issuers {
+		trusted[strings.TrimRight(issuer, "/")] = true
+	}
+	return &Verifier{
+		Keys:             NewKeyCache(httpClient),
+		Issuers:          trusted,
+		Audiences:        audiences,
+		Now:              time.Now,
+		CheckWebIDIssuer: true,
+		seenJTIs:         make(map[string]time.Time),
+	}
+}
+
+// now returns the verifier's clock
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// VerifyIDToken verifies an ID token's signature and claims and returns its claims.
+func (v *Verifier) VerifyIDToken(ctx context.Context, token string, opts VerifyOptions) (*IDTokenClaims, error) {
+	jwt, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	if !supportedAlgorithm(jwt.header.Algorithm) {
+		return nil, fmt.Errorf("unsupported algorithm %q", jwt.header.Algorithm)
+	}
+
+	var claims IDTokenClaims
+	if err := json.Unmarshal(jwt.payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT claims: %w", err)
+	}
+
+	// Check the issuer before fetching anything from it
+	issuer := strings.TrimRight(claims.Issuer, "/")
+	if opts.Issuer != "" && issuer != strings.TrimRight(opts.Issuer, "/") {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if !v.Issuers[issuer] && !(v.AnyIssuer && v.CheckWebIDIssuer) {
+		return nil, fmt.Errorf("untrusted issuer %q", claims.Issuer)
+	}
+
+	key, err := v.Keys.Key(ctx, claims.Issuer, jwt.header.KeyID, jwt.header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(jwt.header.Algorithm, key, jwt.signingInput, jwt.signature); err != nil {
+		return nil, err
+	}
+
+	audience := ""
+	for _, accepted := range v.Audiences {
+		if claims.Audience.Contains(accepted) {
+			audience = accepted
+			break
+		}
+	}
+	if audience == "" {
+		return nil, fmt.Errorf("token audience %v is not accepted", []string(claims.Audience))
+	}
+	if err := claims.Validate(claims.Issuer, audience, opts.Nonce, v.now()); err != nil {
+		return nil, err
+	}
+
+	if v.CheckWebIDIssuer {
+		if err := VerifyWebIDIssuer(ctx, v.Keys.HTTP, claims.WebIDOrSubject(), claims.Issuer); err != nil {
+			return nil, err
+		}
+	}
+	return &claims, nil
+}
+
+// VerifyBoundToken verifies a token presented with a DPoP proof for the current request.
+// A token carrying cnf.jkt must come with a proof signed by that key; a proof for an
+// unbound token is refused.
+func (v *Verifier) VerifyBoundToken(ctx context.Context, token, proof, method, requestURL string, opts VerifyOptions) (*IDTokenClaims, error) {
+	claims, err := v.VerifyIDToken(ctx, token, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := claims.Confirmation != nil && claims.Confirmation.JKT != ""
+	switch {
+	case !bound && proof == "":
+		return claims, nil
+	case !bound:
+		return nil, fmt.Errorf("token is not DPoP-bound")
+	case proof == "":
+		return nil, fmt.Errorf("DPoP proof required for bound token")
+	}
+
+	jkt, err := v.VerifyDPoPProof(proof, method, requestURL, token)
+	if err != nil {
+		return nil, err
+	}
+	if jkt != claims.Confirmation.JKT {
+		return nil, fmt.Errorf("DPoP key does not match token binding")
+	}
+	return claims, nil
+}
+
+// VerifyDPoPProof checks a DPoP proof for one request and returns its key thumbprint.
+// accessToken, when set, must match the proof's ath claim.
+func (v *Verifier) VerifyDPoPProof(proof, method, requestURL, accessToken string) (string, error) {
+	jwt, err := parseJWT(proof)
+	if err != nil {
+		return "", fmt.Errorf("invalid DPoP proof: %w", err)
+	}
+	if jwt.header.Type != "dpop+jwt" {
+		return "", fmt.Errorf("DPoP proof has typ %q", jwt.header.Type)
+	}
+	if !supportedAlgorithm(jwt.header.Algorithm) {
+		return "", fmt.Errorf("unsupported DPoP algorithm %q", jwt.header.Algorithm)
+	}
+	if jwt.header.JWK == nil {
+		return "", fmt.Errorf("DPoP proof has no jwk")
+	}
+
+	key, err := jwt.header.JWK.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("invalid DPoP key: %w", err)
+	}
+	if err := verifySignature(jwt.header.Algorithm, key, jwt.signingInput, jwt.signature); err != nil {
+		return "", fmt.Errorf("DPoP proof: %w", err)
+	}
+
+	var claims struct {
+		JTI string `json:"jti"`
+		HTM string `json:"htm"`
+		HTU string `json:"htu"`
+		IAT int64  `json:"iat"`
+		ATH string `json:"ath,omitempty"`
+	}
+	if err := json.Unmarshal(jwt.payload, &claims); err != nil {
+		return "", fmt.Errorf("failed to parse DPoP claims: %w", err)
+	}
+
+	if claims.HTM != method {
+		return "", fmt.Errorf("DPoP proof is for method %s", claims.HTM)
+	}
+	if normalizeHTU(claims.HTU) != normalizeHTU(requestURL) {
+		return "", fmt.Errorf("DPoP proof is for %s", claims.HTU)
+	}
+	now := v.now()
+	issuedAt := time.Unix(claims.IAT, 0)
+	if issuedAt.Before(now.Add(-dpopProofLifetime)) || issuedAt.After(now.Add(clockSkew)) {
+		return "", fmt.Errorf("DPoP proof is stale")
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.ATH != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", fmt.Errorf("DPoP proof ath does not match token")
+		}
+	}
+	if claims.JTI == "" || !v.rememberJTI(claims.JTI, now) {
+		return "", fmt.Errorf("DPoP proof replayed")
+	}
+
+	return JWKThumbprint(*jwt.header.JWK)
+}
+
+// rememberJTI records a proof ID, returning false if it was already used
+func (v *Verifier) rememberJTI(jti string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.seenJTIs == nil {
+		v.seenJTIs = make(map[string]time.Time)
+	}
+	for seen, at := range v.seenJTIs {
+		if now.Sub(at) > dpopProofLifetime+clockSkew {
+			delete(v.seenJTIs, seen)
+		}
+	}
+	if _, ok := v.seenJTIs[jti]; ok {
+		return false
+	}
+	v.seenJTIs[jti] = now
+	return true
+}
+
+// normalizeHTU compares URLs without query, fragment or default port (RFC 9449 4.3)
+func normalizeHTU(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if (parsed.Scheme == "https" && parsed.Port() == "443") || (parsed.Scheme == "http" && parsed.Port() == "80") {
+		parsed.Host = parsed.Hostname()
+	}
+	return parsed.String()
+}
+
+// VerifyWebIDIssuer confirms a WebID profile names issuer as one of its solid:oidcIssuer
+// values. The profile may be Turtle or JSON-LD.
+func VerifyWebIDIssuer(ctx context.Context, httpClient *http.Client, webID, issuer string) error {
+	if httpClient == nil {
+		httpClient = DefaultHTTPClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webID, nil)
+	if err != nil {
+		return fmt.Errorf("invalid WebID %q: %w", webID, err)
+	}
+	req.Header.Set("Accept", rdf.MediaTypeTurtle+", "+rdf.MediaTypeJSONLD+";q=0.9")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch WebID profile: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("WebID profile returned status %d", resp.StatusCode)
+	}
+
+	profile, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read WebID profile: %w", err)
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if mediaType == "" {
+		mediaType = rdf.MediaTypeTurtle
+	}
+	g, err := rdf.Parse(profile, mediaType, webID)
+	if err != nil {
+		return fmt.Errorf("failed to parse WebID profile: %w", err)
+	}
+	for _, listed := range g.Objects(rdf.IRI(webID), rdf.IRI(rdf.SolidNS+"oidcIssuer")) {
+		if listed.IsIRI() && strings.TrimRight(listed.Value, "/") == strings.TrimRight(issuer, "/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("WebID %s does not list %s as its OIDC issuer", webID, issuer)
+}
@@ -0,0 +1,238 @@
+package solid_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid/oidctest"
+)
+
+// newProvider starts an oidctest provider and a verifier trusting only it
+func newProvider(t *testing.T) (*oidctest.Provider, *solid.Verifier) {
+	t.Helper()
+	provider := oidctest.NewProvider()
+	t.Cleanup(provider.Close)
+	return provider, solid.NewVerifier([]string{provider.Issuer}, []string{"solid"}, provider.Client())
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, verifier := newProvider(t)
+	other := oidctest.NewProvider()
+	defer other.Close()
+
+	tests := []struct {
+		name    string
+		opts    oidctest.TokenOptions
+		verify  solid.VerifyOptions
+		wantErr string
+	}{
+		{name: "RS256", opts: oidctest.TokenOptions{}},
+		{name: "ES256", opts: oidctest.TokenOptions{Algorithm: "ES256"}},
+		{name: "nonce", opts: oidctest.TokenOptions{Nonce: "n-1"}, verify: solid.VerifyOptions{Nonce: "n-1"}},
+		{name: "expired", opts: oidctest.TokenOptions{ExpiresIn: -time.Hour}, wantErr: "expired"},
+		{name: "wrong audience", opts: oidctest.TokenOptions{Audience: []string{"someone-else"}}, wantErr: "audience"},
+		{name: "key missing from JWKS", opts: oidctest.TokenOptions{KeyID: "retired-key"}, wantErr: "retired-key"},
+		{name: "untrusted issuer", opts: oidctest.TokenOptions{Issuer: other.Issuer}, wantErr: "untrusted issuer"},
+		{name: "other login issuer", opts: oidctest.TokenOptions{}, verify: solid.VerifyOptions{Issuer: other.Issuer}, wantErr: "unexpected issuer"},
+		{name: "nonce mismatch", opts: oidctest.TokenOptions{Nonce: "n-1"}, verify: solid.VerifyOptions{Nonce: "n-2"}, wantErr: "nonce"},
+		{name: "WebID names another issuer", opts: oidctest.TokenOptions{WebID: other.WebID("mallory")}, wantErr: "does not list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := provider.IssueIDToken(tt.opts)
+			claims, err := verifier.VerifyIDToken(context.Background(), token, tt.verify)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyIDToken: %v", err)
+				}
+				if claims.WebIDOrSubject() != provider.WebID("alice") {
+					t.Errorf("WebID = %q, want %q", claims.WebIDOrSubject(), provider.WebID("alice"))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyIDToken = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifierIssuerList(t *testing.T) {
+	provider := oidctest.NewProvider()
+	defer provider.Close()
+	other := oidctest.NewProvider()
+	defer other.Close()
+	ctx := context.Background()
+	token := provider.IssueIDToken(oidctest.TokenOptions{})
+
+	verifier := solid.NewVerifier(nil, []string{"solid"}, provider.Client())
+	if _, err := verifier.VerifyIDToken(ctx, token, solid.VerifyOptions{}); err == nil {
+		t.Fatal("a verifier without issuers accepted a token")
+	}
+
+	verifier.AnyIssuer = true
+	verifier.CheckWebIDIssuer = false
+	if _, err := verifier.VerifyIDToken(ctx, token, solid.VerifyOptions{}); err == nil {
+		t.Fatal("AnyIssuer accepted a token without checking the WebID profile")
+	}
+
+	verifier.CheckWebIDIssuer = true
+	if _, err := verifier.VerifyIDToken(ctx, token, solid.VerifyOptions{}); err != nil {
+		t.Fatalf("AnyIssuer with the WebID check: %v", err)
+	}
+	foreign := provider.IssueIDToken(oidctest.TokenOptions{WebID: other.WebID("bob")})
+	if _, err := verifier.VerifyIDToken(ctx, foreign, solid.VerifyOptions{}); err == nil {
+		t.Fatal("AnyIssuer accepted a WebID whose profile names a different issuer")
+	}
+}
+
+func TestVerifyDPoPProof(t *testing.T) {
+	_, verifier := newProvider(t)
+	key, err := solid.NewDPoPKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const (
+		target = "https://tracker.example/api/solid/session"
+		token  = "access-token"
+	)
+	proof := func(method, htu, accessToken string) string {
+		t.Helper()
+		p, err := key.Proof(method, htu, accessToken, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	valid := proof("GET", target, token)
+	jkt, err := verifier.VerifyDPoPProof(valid, "GET", target+"?page=2", token)
+	if err != nil {
+		t.Fatalf("VerifyDPoPProof: %v", err)
+	}
+	if jkt != key.Thumbprint() {
+		t.Errorf("thumbprint = %s, want %s", jkt, key.Thumbprint())
+	}
+	if _, err := verifier.VerifyDPoPProof(valid, "GET", target, token); err == nil || !strings.Contains(err.Error(), "replayed") {
+		t.Errorf("replayed jti = %v, want a replay error", err)
+	}
+
+	tests := []struct {
+		name    string
+		proof   string
+		method  string
+		url     string
+		token   string
+		wantErr string
+	}{
+		{name: "htm", proof: proof("POST", target, token), method: "GET", url: target, token: token, wantErr: "method"},
+		{name: "htu", proof: proof("GET", "https://evil.example/api/solid/session", token), method: "GET", url: target, token: token, wantErr: "evil.example"},
+		{name: "ath", proof: proof("GET", target, "other-token"), method: "GET", url: target, token: token, wantErr: "ath"},
+		{name: "not a JWT", proof: "not.a.jwt", method: "GET", url: target, wantErr: "invalid DPoP proof"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifier.VerifyDPoPProof(tt.proof, tt.method, tt.url, tt.token); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyDPoPProof = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	verifier.Now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	if _, err := verifier.VerifyDPoPProof(proof("GET", target, ""), "GET", target, ""); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("old proof = %v, want a stale error", err)
+	}
+}
+
+func TestVerifyBoundToken(t *testing.T) {
+	provider, verifier := newProvider(t)
+	ctx := context.Background()
+	key, _ := solid.NewDPoPKey()
+	thief, _ := solid.NewDPoPKey()
+	const target = "https://tracker.example/api/solid/session"
+
+	token := provider.IssueIDToken(oidctest.TokenOptions{JKT: key.Thumbprint()})
+	proof, _ := key.Proof("GET", target, token, "")
+	if _, err := verifier.VerifyBoundToken(ctx, token, proof, "GET", target, solid.VerifyOptions{}); err != nil {
+		t.Fatalf("VerifyBoundToken: %v", err)
+	}
+	if _, err := verifier.VerifyBoundToken(ctx, token, "", "GET", target, solid.VerifyOptions{}); err == nil {
+		t.Error("bound token accepted without a proof")
+	}
+	stolen, _ := thief.Proof("GET", target, token, "")
+	if _, err := verifier.VerifyBoundToken(ctx, token, stolen, "GET", target, solid.VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("proof from another key = %v, want a binding mismatch", err)
+	}
+
+	unbound := provider.IssueIDToken(oidctest.TokenOptions{})
+	extra, _ := key.Proof("GET", target, unbound, "")
+	if _, err := verifier.VerifyBoundToken(ctx, unbound, extra, "GET", target, solid.VerifyOptions{}); err == nil {
+		t.Error("proof accepted for an unbound token")
+	}
+}
+
+func TestVerifyWebIDIssuer(t *testing.T) {
+	const issuer = "https://idp.example"
+	profiles := map[string]struct{ contentType, body string }{
+		"/prefixed": {"text/turtle", `@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+<#me> solid:oidcIssuer <https://idp.example/> .`},
+		"/full-iri": {"text/turtle; charset=utf-8", `<#me> <http://www.w3.org/ns/solid/terms#oidcIssuer> <https://idp.example> .`},
+		"/list": {"text/turtle", `@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+<#me> solid:oidcIssuer <https://other.example>, <https://idp.example> .`},
+		"/jsonld": {"application/ld+json", `{
+  "@context": {"solid": "http://www.w3.org/ns/solid/terms#"},
+  "@id": "#me",
+  "solid:oidcIssuer": {"@id": "https://idp.example"}
+}`},
+		"/other-subject": {"text/turtle", `@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+<#me> solid:oidcIssuer <https://other.example> .
+<#friend> solid:oidcIssuer <https://idp.example> .`},
+		"/comment": {"text/turtle", `@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+# solid:oidcIssuer <https://idp.example>
+<#me> solid:oidcIssuer <https://other.example> .`},
+		"/literal": {"text/turtle", `@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+<#me> solid:oidcIssuer "https://idp.example" .`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		profile, ok := profiles[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", profile.contentType)
+		fmt.Fprint(w, profile.body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/prefixed", true},
+		{"/full-iri", true},
+		{"/list", true},
+		{"/jsonld", true},
+		{"/other-subject", false},
+		{"/comment", false},
+		{"/literal", false},
+		{"/missing", false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.path, "/"), func(t *testing.T) {
+			err := solid.VerifyWebIDIssuer(context.Background(), server.Client(), server.URL+tt.path+"#me", issuer)
+			if tt.ok && err != nil {
+				t.Errorf("VerifyWebIDIssuer: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("VerifyWebIDIssuer accepted a profile that does not list the issuer")
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package sparql
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Result is the outcome of a query: Solutions for SELECT, Boolean for ASK and
+// Graph for CONSTRUCT.
+type Result struct {
+	Form      Form
+	Variables []string
+	Solutions []Solution
+	Boolean   bool
+	Graph     *rdf.Graph
+}
+
+// Exec evaluates the query against g. The context is checked between patterns, so a
+// deadline stops runaway joins.
+func (q *Query) Exec(ctx context.Context, g *rdf.Graph) (*Result, error) {
+	e := &evaluator{ctx: ctx, graph: g}
+	solutions := e.group(q.Where, []Solution{{}})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Form: q.Form}
+	switch q.Form {
+	case Ask:
+		result.Boolean = len(solutions) > 0
+		return result, nil
+	case Construct:
+		result.Graph = construct(q, slice(q, solutions))
+		return result, nil
+	}
+
+	result.Variables = q.Variables
+	if len(result.Variables) == 0 {
+		result.Variables = q.Where.variables()
+	}
+	if len(q.OrderBy) > 0 {
+		orderSolutions(solutions, q.OrderBy)
+	}
+	solutions = project(solutions, result.Variables)
+	if q.Distinct {
+		solutions = distinct(solutions, result.Variables)
+	}
+	result.Solutions = slice(q, solutions)
+	return result, nil
+}
+
+// Run parses and executes a query in one step.
+func Run(ctx context.Context, g *rdf.Graph, query, base string) (*Result, error) {
+	q, err := Parse(query, base)
+	if err != nil {
+		return nil, err
+	}
+	return q.Exec(ctx, g)
+}
+
+type evaluator struct {
+	ctx   context.Context
+	graph *rdf.Graph
+}
+
+// group joins a group's elements in order onto the input solutions, then filters
+func (e *evaluator) group(g *Group, input []Solution) []Solution {
+	solutions := input
+	for _, element := range g.Elements {
+		if e.ctx.Err() != nil {
+			return nil
+		}
+		switch el := element.(type) {
+		case BGP:
+			for _, pattern := range el {
+				solutions = e.match(pattern, solutions)
+			}
+		case *Group:
+			solutions = e.group(el, solutions)
+		case Optional:
+			var joined []Solution
+			for _, s := range solutions {
+				extended := e.group(el.Group, []Solution{s})
+				if len(extended) == 0 {
+					joined = append(joined, s)
+				}
+				joined = append(joined, extended...)
+			}
+			solutions = joined
+		}
+	}
+	if len(g.Filters) == 0 {
+		return solutions
+	}
+	filtered := solutions[:0:0]
+	for _, s := range solutions {
+		if e.passes(g.Filters, s) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+func (e *evaluator) passes(filters []Expr, s Solution) bool {
+	for _, filter := range filters {
+		if ok, err := evalBoolean(filter, s); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// match extends every solution with the triples matching one pattern
+func (e *evaluator) match(pattern TriplePattern, input []Solution) []Solution {
+	var output []Solution
+	for _, s := range input {
+		subject, predicate, object := bind(pattern.Subject, s), bind(pattern.Predicate, s), bind(pattern.Object, s)
+		for _, t := range e.graph.Match(subject, predicate, object) {
+			extended := s
+			ok := true
+			for _, pair := range []struct {
+				node Node
+				term rdf.Term
+			}{{pattern.Subject, t.Subject}, {pattern.Predicate, t.Predicate}, {pattern.Object, t.Object}} {
+				if pair.node.Var == "" {
+					continue
+				}
+				if bound, exists := extended[pair.node.Var]; exists {
+					ok = bound == pair.term
+				} else {
+					extended = extend(extended, s, pair.node.Var, pair.term)
+				}
+				if !ok {
+					break
+				}
+			}
+			if ok {
+				output = append(output, extended)
+			}
+		}
+	}
+	return output
+}
+
+// bind returns the node's term under s, or the zero (wildcard) term
+func bind(n Node, s Solution) rdf.Term {
+	if n.Var == "" {
+		return n.Term
+	}
+	return s[n.Var]
+}
+
+// extend adds a binding, copying the solution the first time it changes
+func extend(current, original Solution, name string, term rdf.Term) Solution {
+	if len(current) == len(original) {
+		copied := make(Solution, len(original)+1)
+		for k, v := range original {
+			copied[k] = v
+		}
+		current = copied
+	}
+	current[name] = term
+	return current
+}
+
+func orderSolutions(solutions []Solution, conditions []OrderCondition) {
+	sort.SliceStable(solutions, func(i, j int) bool {
+		for _, condition := range conditions {
+			a, errA := condition.Expr.eval(solutions[i])
+			b, errB := condition.Expr.eval(solutions[j])
+			c := orderTerms(a, errA != nil, b, errB != nil)
+			if c == 0 {
+				continue
+			}
+			if condition.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// orderTerms is the ORDER BY ordering: unbound or errors, blank nodes, IRIs, literals
+func orderTerms(a rdf.Term, aMissing bool, b rdf.Term, bMissing bool) int {
+	rank := func(t rdf.Term, missing bool) int {
+		if missing {
+			return 0
+		}
+		return map[rdf.TermKind]int{rdf.KindBlank: 1, rdf.KindIRI: 2, rdf.KindLiteral: 3}[t.Kind]
+	}
+	if ra, rb := rank(a, aMissing), rank(b, bMissing); ra != rb || ra == 0 {
+		return ra - rb
+	}
+	if c, err := compareTerms(a, b); err == nil {
+		return c
+	}
+	if c := strings.Compare(a.Value, b.Value); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Datatype+a.Language, b.Datatype+b.Language)
+}
+
+// project keeps only the selected variables (and drops blank node variables)
+func project(solutions []Solution, variables []string) []Solution {
+	projected := make([]Solution, len(solutions))
+	for i, s := range solutions {
+		p := make(Solution, len(variables))
+		for _, name := range variables {
+			if t, ok := s[name]; ok {
+				p[name] = t
+			}
+		}
+		projected[i] = p
+	}
+	return projected
+}
+
+func distinct(solutions []Solution, variables []string) []Solution {
+	seen := make(map[string]bool)
+	var unique []Solution
+	for _, s := range solutions {
+		var key strings.Builder
+		for _, name := range variables {
+			if t, ok := s[name]; ok {
+				key.WriteString(t.String())
+			}
+			key.WriteByte(0)
+		}
+		if !seen[key.String()] {
+			seen[key.String()] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// slice applies OFFSET and LIMIT
+func slice(q *Query, solutions []Solution) []Solution {
+	if q.Offset >= len(solutions) {
+		return nil
+	}
+	solutions = solutions[q.Offset:]
+	if q.Limit >= 0 && q.Limit < len(solutions) {
+		solutions = solutions[:q.Limit]
+	}
+	return solutions
+}
+
+// construct instantiates the template once per solution. Blank nodes in the
+// template are fresh for each solution, blank nodes from the data keep their
+// identity; triples with unbound or ill-placed terms are skipped.
+func construct(q *Query, solutions []Solution) *rdf.Graph {
+	g := rdf.NewGraph()
+	relabel := make(map[rdf.Term]rdf.Term)
+	for prefix, namespace := range q.Prefixes {
+		if _, common := rdf.CommonPrefixes[prefix]; !common || usesNamespace(q.Template, namespace) {
+			g.SetPrefix(prefix, namespace)
+		}
+	}
+	for _, s := range solutions {
+		blanks := make(map[string]rdf.Term)
+		instantiate := func(n Node) rdf.Term {
+			if !strings.HasPrefix(n.Var, "_:") {
+				t := bind(n, s)
+				if t.IsBlank() {
+					if _, ok := relabel[t]; !ok {
+						relabel[t] = g.NewBlank()
+					}
+					t = relabel[t]
+				}
+				return t
+			}
+			if _, ok := blanks[n.Var]; !ok {
+				blanks[n.Var] = g.NewBlank()
+			}
+			return blanks[n.Var]
+		}
+		for _, pattern := range q.Template {
+			subject, predicate, object := instantiate(pattern.Subject), instantiate(pattern.Predicate), instantiate(pattern.Object)
+			if subject.IsZero() || predicate.IsZero() || object.IsZero() || subject.IsLiteral() || !predicate.IsIRI() {
+				continue
+			}
+			g.Add(subject, predicate, object)
+		}
+	}
+	return g
+}
+
+// usesNamespace reports whether any fixed term of the template is in the namespace
+func usesNamespace(template []TriplePattern, namespace string) bool {
+	for _, t := range template {
+		for _, n := range []Node{t.Subject, t.Predicate, t.Object} {
+			if n.Var == "" && (strings.HasPrefix(n.Term.Value, namespace) || strings.HasPrefix(n.Term.Datatype, namespace)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package sparql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+	"github.com/justin4957/ec2-test-apps/solid-poc/sparql"
+)
+
+const ex = "https://data.example/"
+
+const prologue = `PREFIX ex: <https://data.example/>
+PREFIX schema: <http://schema.org/>
+`
+
+// testGraph has three cases; only case 1 has a story, and cases 1 and 3 share a keyword
+func testGraph() *rdf.Graph {
+	g := rdf.NewGraph()
+	schema := func(local string) rdf.Term { return rdf.IRI(rdf.SchemaNS + local) }
+	cases := []struct {
+		id       string
+		message  string
+		severity int64
+		keyword  string
+		story    string
+	}{
+		{"case1", "Null pointer in checkout", 3, "payments", "Once upon a time"},
+		{"case2", "Timeout talking to maps", 1, "maps", ""},
+		{"case3", "Card declined twice", 2, "payments", ""},
+	}
+	for _, c := range cases {
+		s := rdf.IRI(ex + c.id)
+		g.Add(s, rdf.IRI(rdf.RDFType), schema("Report"))
+		g.Add(s, schema("name"), rdf.Literal(c.message))
+		g.Add(s, rdf.IRI(ex+"severity"), rdf.Integer(c.severity))
+		g.Add(s, schema("keywords"), rdf.Literal(c.keyword))
+		if c.story != "" {
+			g.Add(s, schema("text"), rdf.LangLiteral(c.story, "en"))
+		}
+	}
+	g.Add(rdf.IRI(ex+"tip1"), rdf.IRI(rdf.RDFType), schema("Comment"))
+	g.Add(rdf.IRI(ex+"case1"), schema("isBasedOn"), rdf.IRI(ex+"tip1"))
+	return g
+}
+
+func run(t *testing.T, query string) *sparql.Result {
+	t.Helper()
+	result, err := sparql.Run(context.Background(), testGraph(), prologue+query, ex)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return result
+}
+
+// column returns one variable's values in solution order, "" where unbound
+func column(result *sparql.Result, variable string) []string {
+	values := make([]string, len(result.Solutions))
+	for i, s := range result.Solutions {
+		values[i] = s[variable].Value
+	}
+	return values
+}
+
+func TestBasicGraphPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string // ?s, ordered
+	}{
+		{"type", `SELECT ?s WHERE { ?s a schema:Report } ORDER BY ?s`, []string{ex + "case1", ex + "case2", ex + "case3"}},
+		{"join on a shared variable", `SELECT ?s WHERE { ?s a schema:Report ; schema:isBasedOn ?tip . ?tip a schema:Comment }`, []string{ex + "case1"}},
+		{"fixed object", `SELECT ?s WHERE { ?s schema:keywords "payments" } ORDER BY ?s`, []string{ex + "case1", ex + "case3"}},
+		{"typed literal", `SELECT ?s WHERE { ?s ex:severity 2 }`, []string{ex + "case3"}},
+		{"language-tagged literal", `SELECT ?s WHERE { ?s schema:text "Once upon a time"@en }`, []string{ex + "case1"}},
+		{"plain literal does not match a tagged one", `SELECT ?s WHERE { ?s schema:text "Once upon a time" }`, []string{}},
+		{"same variable twice", `SELECT ?s WHERE { ?s schema:isBasedOn ?s }`, []string{}},
+		{"no match", `SELECT ?s WHERE { ?s a schema:Place }`, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := column(run(t, tt.query), "s"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Cases sharing a keyword, each pair once in each direction
+	result := run(t, `SELECT ?a ?b WHERE { ?a schema:keywords ?k . ?b schema:keywords ?k FILTER(?a != ?b) } ORDER BY ?a`)
+	if got := column(result, "a"); !reflect.DeepEqual(got, []string{ex + "case1", ex + "case3"}) {
+		t.Errorf("keyword pairs = %q", got)
+	}
+	if !reflect.DeepEqual(result.Variables, []string{"a", "b"}) {
+		t.Errorf("Variables = %q", result.Variables)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{"numeric comparison", `?severity >= 2`, []string{ex + "case1", ex + "case3"}},
+		{"arithmetic", `?severity * 2 = 4`, []string{ex + "case3"}},
+		{"logical", `?severity > 1 && ?severity < 3 || ?severity = 1`, []string{ex + "case2", ex + "case3"}},
+		{"regex", `regex(?name, "^card", "i")`, []string{ex + "case3"}},
+		{"contains", `contains(lcase(?name), "maps")`, []string{ex + "case2"}},
+		{"string equality", `str(?s) = "https://data.example/case2"`, []string{ex + "case2"}},
+		{"negation", `!(?severity = 1)`, []string{ex + "case1", ex + "case3"}},
+		{"type error drops the solution", `?name > 1`, []string{}},
+		{"unbound variable drops the solution", `?missing = 1`, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := `SELECT ?s WHERE { ?s ex:severity ?severity ; schema:name ?name FILTER(` + tt.filter + `) } ORDER BY ?s`
+			if got := column(run(t, query), "s"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FILTER(%s) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	// A filter applies to the whole group, wherever it is written
+	result := run(t, `SELECT ?s WHERE { FILTER(?severity = 3) ?s ex:severity ?severity }`)
+	if got := column(result, "s"); !reflect.DeepEqual(got, []string{ex + "case1"}) {
+		t.Errorf("filter before its pattern = %q", got)
+	}
+}
+
+func TestOptional(t *testing.T) {
+	result := run(t, `SELECT ?s ?story WHERE { ?s a schema:Report OPTIONAL { ?s schema:text ?story } } ORDER BY ?s`)
+	if got := column(result, "s"); !reflect.DeepEqual(got, []string{ex + "case1", ex + "case2", ex + "case3"}) {
+		t.Fatalf("OPTIONAL dropped solutions: %q", got)
+	}
+	if got := column(result, "story"); !reflect.DeepEqual(got, []string{"Once upon a time", "", ""}) {
+		t.Errorf("?story = %q", got)
+	}
+	if _, bound := result.Solutions[1]["story"]; bound {
+		t.Error("?story bound for a case without one")
+	}
+	if story := result.Solutions[0]["story"]; story.Language != "en" {
+		t.Errorf("?story = %+v, want the language tag kept", story)
+	}
+
+	// !bound() after an OPTIONAL finds the cases without a story
+	result = run(t, `SELECT ?s WHERE { ?s a schema:Report OPTIONAL { ?s schema:text ?story } FILTER(!bound(?story)) } ORDER BY ?s`)
+	if got := column(result, "s"); !reflect.DeepEqual(got, []string{ex + "case2", ex + "case3"}) {
+		t.Errorf("cases without a story = %q", got)
+	}
+
+	// A filter inside the OPTIONAL only decides whether it extends the solution
+	result = run(t, `SELECT ?s ?severity WHERE { ?s a schema:Report OPTIONAL { ?s ex:severity ?severity FILTER(?severity > 2) } } ORDER BY ?s`)
+	if got := column(result, "severity"); !reflect.DeepEqual(got, []string{"3", "", ""}) {
+		t.Errorf("?severity with an inner filter = %q", got)
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	tests := []struct {
+		modifiers string
+		want      []string
+	}{
+		{`ORDER BY ?s LIMIT 2`, []string{ex + "case1", ex + "case2"}},
+		{`ORDER BY DESC(?severity) LIMIT 1`, []string{ex + "case1"}},
+		{`ORDER BY ?s OFFSET 1 LIMIT 1`, []string{ex + "case2"}},
+		{`ORDER BY ?s LIMIT 1 OFFSET 2`, []string{ex + "case3"}},
+		{`ORDER BY ?s OFFSET 5`, []string{}},
+		{`LIMIT 0`, []string{}},
+		{`ORDER BY ?s LIMIT 10`, []string{ex + "case1", ex + "case2", ex + "case3"}},
+	}
+	for _, tt := range tests {
+		query := `SELECT ?s WHERE { ?s ex:severity ?severity } ` + tt.modifiers
+		if got := column(run(t, query), "s"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.modifiers, got, tt.want)
+		}
+	}
+
+	// LIMIT applies after DISTINCT
+	result := run(t, `SELECT DISTINCT ?k WHERE { ?s schema:keywords ?k } ORDER BY ?k LIMIT 2`)
+	if got := column(result, "k"); !reflect.DeepEqual(got, []string{"maps", "payments"}) {
+		t.Errorf("DISTINCT ... LIMIT 2 = %q", got)
+	}
+
+	// and to the solutions a CONSTRUCT template is instantiated with
+	result = run(t, `CONSTRUCT { ?s schema:name ?name } WHERE { ?s schema:name ?name } ORDER BY ?s LIMIT 2`)
+	if result.Graph.Len() != 2 || !result.Graph.Has(rdf.IRI(ex+"case1"), rdf.IRI(rdf.SchemaNS+"name"), rdf.Literal("Null pointer in checkout")) {
+		t.Errorf("CONSTRUCT ... LIMIT 2 =\n%s", result.Graph.NTriples())
+	}
+}
+
+func TestAsk(t *testing.T) {
+	if !run(t, `ASK { ?s schema:isBasedOn ?tip }`).Boolean {
+		t.Error("ASK for a linked tip = false")
+	}
+	if run(t, `ASK { ?s a schema:Place }`).Boolean {
+		t.Error("ASK for a place = true")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	queries := []string{
+		`INSERT DATA { ex:a ex:b ex:c }`,
+		`DELETE WHERE { ?s ?p ?o }`,
+		`SELECT ?s WHERE { { ?s a schema:Report } UNION { ?s a schema:Comment } }`,
+		`SELECT ?s WHERE { ?s a schema:Report `,
+		`SELECT ?s WHERE { ?s a schema:Report } LIMIT -1`,
+		`SELECT ?s WHERE { ?s unknown:p ?o }`,
+	}
+	for _, query := range queries {
+		_, err := sparql.Parse(prologue+query, ex)
+		var parseErr *sparql.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) = %v, want a ParseError", query, err)
+		}
+	}
+	_, err := sparql.Parse(`INSERT DATA { <a> <b> <c> }`, ex)
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("update error = %v, want it to say the endpoint is read-only", err)
+	}
+}
+
+func TestExecDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err := sparql.Run(ctx, testGraph(), `SELECT * WHERE { ?a ?b ?c . ?d ?e ?f . ?g ?h ?i }`, ex)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run past its deadline = %v, want DeadlineExceeded", err)
+	}
+}
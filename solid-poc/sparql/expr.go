@@ -0,0 +1,637 @@
+package sparql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Solution binds variable names to terms.
+type Solution map[string]rdf.Term
+
+// Expr is a FILTER or ORDER BY expression. Evaluation errors (unbound variables,
+// type errors) make a FILTER reject the solution.
+type Expr interface {
+	eval(s Solution) (rdf.Term, error)
+}
+
+type (
+	varExpr   string
+	constExpr struct{ term rdf.Term }
+	unaryExpr struct {
+		op  string
+		arg Expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right Expr
+	}
+	callExpr struct {
+		name string // upper-case function name, or the datatype IRI of a cast
+		args []Expr
+		re   *regexp.Regexp // REGEX with a constant pattern, compiled once
+	}
+)
+
+var errUnbound = errors.New("unbound variable")
+
+// functions maps the supported built-in calls to their argument counts (min, max)
+var functions = map[string][2]int{
+	"BOUND": {1, 1}, "ISIRI": {1, 1}, "ISURI": {1, 1}, "ISBLANK": {1, 1}, "ISLITERAL": {1, 1},
+	"ISNUMERIC": {1, 1}, "STR": {1, 1}, "LANG": {1, 1}, "DATATYPE": {1, 1}, "STRLEN": {1, 1},
+	"LCASE": {1, 1}, "UCASE": {1, 1}, "CONTAINS": {2, 2}, "STRSTARTS": {2, 2}, "STRENDS": {2, 2},
+	"LANGMATCHES": {2, 2}, "SAMETERM": {2, 2}, "REGEX": {2, 3},
+}
+
+// casts are the XSD constructor functions, e.g. xsd:integer(?x)
+var casts = map[string]bool{
+	rdf.XSDString: true, rdf.XSDInteger: true, rdf.XSDDecimal: true, rdf.XSDDouble: true,
+	rdf.XSDBoolean: true, rdf.XSDDateTime: true,
+}
+
+// expression parsing, lowest precedence first
+
+func (p *parser) expression() Expr {
+	left := p.andExpression()
+	for p.accept("||") {
+		left = binaryExpr{"||", left, p.andExpression()}
+	}
+	return left
+}
+
+func (p *parser) andExpression() Expr {
+	left := p.relational()
+	for p.accept("&&") {
+		left = binaryExpr{"&&", left, p.relational()}
+	}
+	return left
+}
+
+func (p *parser) relational() Expr {
+	left := p.additive()
+	for _, op := range []string{"=", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			return binaryExpr{op, left, p.additive()}
+		}
+	}
+	p.checkSupported()
+	return left
+}
+
+func (p *parser) additive() Expr {
+	left := p.multiplicative()
+	for {
+		switch {
+		case p.accept("+"):
+			left = binaryExpr{"+", left, p.multiplicative()}
+		case p.accept("-"):
+			left = binaryExpr{"-", left, p.multiplicative()}
+		default:
+			return left
+		}
+	}
+}
+
+func (p *parser) multiplicative() Expr {
+	left := p.unary()
+	for {
+		switch {
+		case p.accept("*"):
+			left = binaryExpr{"*", left, p.unary()}
+		case p.accept("/"):
+			left = binaryExpr{"/", left, p.unary()}
+		default:
+			return left
+		}
+	}
+}
+
+func (p *parser) unary() Expr {
+	for _, op := range []string{"!", "-", "+"} {
+		if p.accept(op) {
+			return unaryExpr{op, p.unary()}
+		}
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() Expr {
+	p.checkSupported()
+	t := p.peek()
+	switch t.kind {
+	case tokPunct:
+		if p.accept("(") {
+			e := p.expression()
+			p.expect(")")
+			return e
+		}
+	case tokVar:
+		p.next()
+		return varExpr(t.value)
+	case tokIRI, tokPName:
+		iri := p.iri()
+		if !p.accept("(") {
+			return constExpr{iri}
+		}
+		if !casts[iri.Value] {
+			p.fail("unsupported function <%s>", iri.Value)
+		}
+		return p.call(iri.Value, 1, 1)
+	case tokWord:
+		name := strings.ToUpper(t.value)
+		if arity, ok := functions[name]; ok {
+			p.next()
+			p.expect("(")
+			return p.call(name, arity[0], arity[1])
+		}
+		if name != "TRUE" && name != "FALSE" {
+			p.fail("unsupported function %s", t.value)
+		}
+	}
+	return constExpr{p.literal()}
+}
+
+// call reads the arguments of a function after its "("
+func (p *parser) call(name string, min, max int) Expr {
+	c := callExpr{name: name}
+	if !p.punct(")") {
+		c.args = append(c.args, p.expression())
+		for p.accept(",") {
+			c.args = append(c.args, p.expression())
+		}
+	}
+	p.expect(")")
+	if len(c.args) < min || len(c.args) > max {
+		p.fail("%s takes %d to %d arguments", name, min, max)
+	}
+	if name == "BOUND" {
+		if _, ok := c.args[0].(varExpr); !ok {
+			p.fail("BOUND takes a variable")
+		}
+	}
+	if name == "REGEX" {
+		pattern, patternOK := c.args[1].(constExpr)
+		flags := constExpr{rdf.Literal("")}
+		flagsOK := true
+		if len(c.args) == 3 {
+			flags, flagsOK = c.args[2].(constExpr)
+		}
+		if patternOK && flagsOK {
+			re, err := compileRegex(pattern.term.Value, flags.term.Value)
+			if err != nil {
+				p.fail("%v", err)
+			}
+			c.re = re
+		}
+	}
+	return c
+}
+
+// evaluation
+
+func (e varExpr) eval(s Solution) (rdf.Term, error) {
+	if t, ok := s[string(e)]; ok {
+		return t, nil
+	}
+	return rdf.Term{}, errUnbound
+}
+
+func (e constExpr) eval(Solution) (rdf.Term, error) { return e.term, nil }
+
+func (e unaryExpr) eval(s Solution) (rdf.Term, error) {
+	v, err := e.arg.eval(s)
+	if err != nil {
+		return rdf.Term{}, err
+	}
+	if e.op == "!" {
+		b, err := effectiveBoolean(v)
+		if err != nil {
+			return rdf.Term{}, err
+		}
+		return rdf.Boolean(!b), nil
+	}
+	if !isNumeric(v) {
+		return rdf.Term{}, fmt.Errorf("%s is not numeric", v)
+	}
+	if e.op == "+" {
+		return v, nil
+	}
+	return arithmetic("-", rdf.Integer(0), v)
+}
+
+func (e binaryExpr) eval(s Solution) (rdf.Term, error) {
+	switch e.op {
+	case "||", "&&":
+		return e.logical(s)
+	}
+	left, err := e.left.eval(s)
+	if err != nil {
+		return rdf.Term{}, err
+	}
+	right, err := e.right.eval(s)
+	if err != nil {
+		return rdf.Term{}, err
+	}
+	switch e.op {
+	case "=", "!=":
+		equal, err := equalTerms(left, right)
+		if err != nil {
+			return rdf.Term{}, err
+		}
+		return rdf.Boolean(equal == (e.op == "=")), nil
+	case "<", ">", "<=", ">=":
+		c, err := compareTerms(left, right)
+		if err != nil {
+			return rdf.Term{}, err
+		}
+		result := map[string]bool{"<": c < 0, ">": c > 0, "<=": c <= 0, ">=": c >= 0}[e.op]
+		return rdf.Boolean(result), nil
+	}
+	return arithmetic(e.op, left, right)
+}
+
+// logical applies SPARQL's error-tolerant || and &&: true || error is true,
+// false && error is false
+func (e binaryExpr) logical(s Solution) (rdf.Term, error) {
+	short := e.op == "||"
+	left, leftErr := evalBoolean(e.left, s)
+	if leftErr == nil && left == short {
+		return rdf.Boolean(short), nil
+	}
+	right, rightErr := evalBoolean(e.right, s)
+	if rightErr == nil && right == short {
+		return rdf.Boolean(short), nil
+	}
+	if leftErr != nil {
+		return rdf.Term{}, leftErr
+	}
+	if rightErr != nil {
+		return rdf.Term{}, rightErr
+	}
+	return rdf.Boolean(!short), nil
+}
+
+func evalBoolean(e Expr, s Solution) (bool, error) {
+	v, err := e.eval(s)
+	if err != nil {
+		return false, err
+	}
+	return effectiveBoolean(v)
+}
+
+func (e callExpr) eval(s Solution) (rdf.Term, error) {
+	if e.name == "BOUND" {
+		_, ok := s[string(e.args[0].(varExpr))]
+		return rdf.Boolean(ok), nil
+	}
+	args := make([]rdf.Term, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(s)
+		if err != nil {
+			return rdf.Term{}, err
+		}
+		args[i] = v
+	}
+	if casts[e.name] {
+		return cast(e.name, args[0])
+	}
+
+	x := args[0]
+	switch e.name {
+	case "ISIRI", "ISURI":
+		return rdf.Boolean(x.IsIRI()), nil
+	case "ISBLANK":
+		return rdf.Boolean(x.IsBlank()), nil
+	case "ISLITERAL":
+		return rdf.Boolean(x.IsLiteral()), nil
+	case "ISNUMERIC":
+		return rdf.Boolean(isNumeric(x)), nil
+	case "STR":
+		if x.IsBlank() {
+			return rdf.Term{}, fmt.Errorf("STR of a blank node")
+		}
+		return rdf.Literal(x.Value), nil
+	case "LANG":
+		if !x.IsLiteral() {
+			return rdf.Term{}, fmt.Errorf("LANG of %s", x)
+		}
+		return rdf.Literal(x.Language), nil
+	case "DATATYPE":
+		if !x.IsLiteral() {
+			return rdf.Term{}, fmt.Errorf("DATATYPE of %s", x)
+		}
+		return rdf.IRI(datatypeOf(x)), nil
+	case "SAMETERM":
+		return rdf.Boolean(x == args[1]), nil
+	case "LANGMATCHES":
+		return rdf.Boolean(langMatches(x.Value, args[1].Value)), nil
+	}
+
+	if !isString(x) {
+		return rdf.Term{}, fmt.Errorf("%s expects a string, got %s", e.name, x)
+	}
+	switch e.name {
+	case "STRLEN":
+		return rdf.Integer(int64(len([]rune(x.Value)))), nil
+	case "LCASE", "UCASE":
+		out := x
+		if e.name == "LCASE" {
+			out.Value = strings.ToLower(x.Value)
+		} else {
+			out.Value = strings.ToUpper(x.Value)
+		}
+		return out, nil
+	case "REGEX":
+		re := e.re
+		if re == nil {
+			flags := ""
+			if len(args) == 3 {
+				flags = args[2].Value
+			}
+			var err error
+			if re, err = compileRegex(args[1].Value, flags); err != nil {
+				return rdf.Term{}, err
+			}
+		}
+		return rdf.Boolean(re.MatchString(x.Value)), nil
+	}
+
+	y := args[1]
+	if !isString(y) || (y.Language != "" && y.Language != x.Language) {
+		return rdf.Term{}, fmt.Errorf("%s arguments are not compatible", e.name)
+	}
+	switch e.name {
+	case "CONTAINS":
+		return rdf.Boolean(strings.Contains(x.Value, y.Value)), nil
+	case "STRSTARTS":
+		return rdf.Boolean(strings.HasPrefix(x.Value, y.Value)), nil
+	default: // STRENDS
+		return rdf.Boolean(strings.HasSuffix(x.Value, y.Value)), nil
+	}
+}
+
+func compileRegex(pattern, flags string) (*regexp.Regexp, error) {
+	var goFlags string
+	for _, flag := range flags {
+		switch flag {
+		case 'i', 's', 'm':
+			goFlags += string(flag)
+		case 'x':
+			pattern = regexp.MustCompile(`\s+`).ReplaceAllString(pattern, "")
+		default:
+			return nil, fmt.Errorf("unsupported regex flag %q", flag)
+		}
+	}
+	if goFlags != "" {
+		pattern = "(?" + goFlags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %v", err)
+	}
+	return re, nil
+}
+
+func langMatches(tag, pattern string) bool {
+	tag, pattern = strings.ToLower(tag), strings.ToLower(pattern)
+	if pattern == "*" {
+		return tag != ""
+	}
+	return tag == pattern || strings.HasPrefix(tag, pattern+"-")
+}
+
+// cast applies an XSD constructor function
+func cast(datatype string, x rdf.Term) (rdf.Term, error) {
+	if x.IsBlank() {
+		return rdf.Term{}, fmt.Errorf("cannot cast a blank node")
+	}
+	value := strings.TrimSpace(x.Value)
+	switch datatype {
+	case rdf.XSDString:
+		return rdf.Literal(x.Value), nil
+	case rdf.XSDBoolean:
+		if isNumeric(x) {
+			f, err := x.Float()
+			return rdf.Boolean(f != 0 && !math.IsNaN(f)), err
+		}
+		b, err := x.Bool()
+		return rdf.Boolean(b), err
+	case rdf.XSDDateTime:
+		t, err := x.Time()
+		if err != nil {
+			return rdf.Term{}, err
+		}
+		return rdf.DateTime(t), nil
+	case rdf.XSDInteger:
+		if x.Datatype == rdf.XSDBoolean {
+			value = map[string]string{"true": "1", "false": "0"}[value]
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return rdf.Integer(n), nil
+		}
+		f, err := x.Float()
+		if err != nil || !isNumeric(x) {
+			return rdf.Term{}, fmt.Errorf("cannot cast %s to xsd:integer", x)
+		}
+		return rdf.Integer(int64(f)), nil
+	}
+	if x.Datatype == rdf.XSDBoolean {
+		value = map[string]string{"true": "1", "false": "0"}[value]
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return rdf.Term{}, fmt.Errorf("cannot cast %s to a number", x)
+	}
+	if datatype == rdf.XSDDecimal {
+		return rdf.Decimal(f), nil
+	}
+	return rdf.Double(f), nil
+}
+
+// term typing
+
+// numericRank orders the numeric datatypes for type promotion; -1 means not numeric
+func numericRank(datatype string) int {
+	if !strings.HasPrefix(datatype, rdf.XSDNS) {
+		return -1
+	}
+	switch strings.TrimPrefix(datatype, rdf.XSDNS) {
+	case "integer", "int", "long", "short", "byte", "nonNegativeInteger", "positiveInteger",
+		"negativeInteger", "nonPositiveInteger", "unsignedLong", "unsignedInt", "unsignedShort", "unsignedByte":
+		return 0
+	case "decimal":
+		return 1
+	case "float":
+		return 2
+	case "double":
+		return 3
+	}
+	return -1
+}
+
+func isNumeric(t rdf.Term) bool {
+	return t.IsLiteral() && numericRank(t.Datatype) >= 0
+}
+
+// isString reports whether t is a simple, xsd:string or language-tagged literal
+func isString(t rdf.Term) bool {
+	return t.IsLiteral() && (t.Datatype == "" || t.Datatype == rdf.XSDString || t.Language != "")
+}
+
+func datatypeOf(t rdf.Term) string {
+	switch {
+	case t.Language != "":
+		return rdf.RDFLangString
+	case t.Datatype == "":
+		return rdf.XSDString
+	}
+	return t.Datatype
+}
+
+func isTemporal(t rdf.Term) bool {
+	return t.IsLiteral() && (t.Datatype == rdf.XSDDateTime || t.Datatype == rdf.XSDDate)
+}
+
+// effectiveBoolean computes a term's effective boolean value
+func effectiveBoolean(t rdf.Term) (bool, error) {
+	switch {
+	case t.IsLiteral() && t.Datatype == rdf.XSDBoolean:
+		return t.Bool()
+	case isNumeric(t):
+		f, err := t.Float()
+		return err == nil && f != 0 && !math.IsNaN(f), nil
+	case isString(t):
+		return t.Value != "", nil
+	}
+	return false, fmt.Errorf("%s has no boolean value", t)
+}
+
+// equalTerms implements = (value equality for comparable literals, term
+// equality otherwise)
+func equalTerms(a, b rdf.Term) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	if !a.IsLiteral() || !b.IsLiteral() {
+		return false, nil
+	}
+	if c, err := compareTerms(a, b); err == nil {
+		return c == 0, nil
+	}
+	return false, nil
+}
+
+// compareTerms orders two literals of comparable types
+func compareTerms(a, b rdf.Term) (int, error) {
+	switch {
+	case isNumeric(a) && isNumeric(b):
+		x, err := a.Float()
+		if err != nil {
+			return 0, err
+		}
+		y, err := b.Float()
+		if err != nil {
+			return 0, err
+		}
+		return compareFloats(x, y), nil
+	case isTemporal(a) && isTemporal(b):
+		x, err := a.Time()
+		if err != nil {
+			return 0, err
+		}
+		y, err := b.Time()
+		if err != nil {
+			return 0, err
+		}
+		return x.Compare(y), nil
+	case isString(a) && isString(b) && a.Language == b.Language:
+		return strings.Compare(a.Value, b.Value), nil
+	case a.IsLiteral() && b.IsLiteral() && a.Datatype == rdf.XSDBoolean && b.Datatype == rdf.XSDBoolean:
+		x, err := a.Bool()
+		if err != nil {
+			return 0, err
+		}
+		y, err := b.Bool()
+		if err != nil {
+			return 0, err
+		}
+		return compareBools(x, y), nil
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", a, b)
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func compareBools(x, y bool) int {
+	switch {
+	case x == y:
+		return 0
+	case y:
+		return -1
+	}
+	return 1
+}
+
+// arithmetic applies + - * / with numeric type promotion
+func arithmetic(op string, a, b rdf.Term) (rdf.Term, error) {
+	if !isNumeric(a) || !isNumeric(b) {
+		return rdf.Term{}, fmt.Errorf("%s %s %s: operands must be numeric", a, op, b)
+	}
+	rank := numericRank(a.Datatype)
+	if r := numericRank(b.Datatype); r > rank {
+		rank = r
+	}
+	if rank == 0 && op != "/" {
+		x, errX := a.Int()
+		y, errY := b.Int()
+		if errX == nil && errY == nil {
+			switch op {
+			case "+":
+				return rdf.Integer(x + y), nil
+			case "-":
+				return rdf.Integer(x - y), nil
+			case "*":
+				return rdf.Integer(x * y), nil
+			}
+		}
+	}
+	x, err := a.Float()
+	if err != nil {
+		return rdf.Term{}, err
+	}
+	y, err := b.Float()
+	if err != nil {
+		return rdf.Term{}, err
+	}
+	var result float64
+	switch op {
+	case "+":
+		result = x + y
+	case "-":
+		result = x - y
+	case "*":
+		result = x * y
+	case "/":
+		if y == 0 && rank <= 1 {
+			return rdf.Term{}, fmt.Errorf("division by zero")
+		}
+		result = x / y
+	}
+	if rank <= 1 {
+		return rdf.Decimal(result), nil
+	}
+	return rdf.Double(result), nil
+}
@@ -0,0 +1,186 @@
+package sparql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies query tokens
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIRI              // <http://...>, value without brackets
+	tokPName            // prefix:local
+	tokVar              // ?name or $name, value without the sigil
+	tokBlank            // _:label, value without "_:"
+	tokString           // value unescaped
+	tokLang             // @en, value without "@"
+	tokNumber           // integer, decimal or double lexical form
+	tokWord             // keyword, function name, "a", true/false
+	tokPunct            // { } ( ) . ; , * ^^ and operators
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokIRI:
+		return "<" + t.value + ">"
+	case tokVar:
+		return "?" + t.value
+	case tokBlank:
+		return "_:" + t.value
+	case tokString:
+		return fmt.Sprintf("%q", t.value)
+	case tokLang:
+		return "@" + t.value
+	}
+	return t.value
+}
+
+var (
+	iriRef    = regexp.MustCompile(`^<([^<>"{}|^` + "`" + `\\\x00-\x20]*)>`)
+	pname     = regexp.MustCompile(`^([A-Za-z][\w.-]*)?:((?:[\w:%-]|\.[\w:%-])*)`)
+	varName   = regexp.MustCompile(`^[?$](\w+)`)
+	blankNode = regexp.MustCompile(`^_:((?:[\w-]|\.[\w-])+)`)
+	langTag   = regexp.MustCompile(`^@([A-Za-z]+(?:-[A-Za-z0-9]+)*)`)
+	number    = regexp.MustCompile(`^(?:\d+\.?\d*[eE][+-]?\d+|\.\d+[eE][+-]?\d+|\d*\.\d+|\d+)`)
+	word      = regexp.MustCompile(`^[A-Za-z_]\w*`)
+)
+
+// punctuation, longest first
+var punctuation = []string{"^^", "&&", "||", "!=", "<=", ">=", "{", "}", "(", ")", ".", ";", ",", "*", "=", "<", ">", "!", "+", "-", "/"}
+
+// lex splits a query into tokens
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			continue
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		}
+
+		rest := query[i:]
+		if c == '"' || c == '\'' {
+			value, n, err := lexString(rest)
+			if err != nil {
+				return nil, fmt.Errorf("at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, token{tokString, value, i})
+			i += n
+			continue
+		}
+
+		matched := false
+		for _, rule := range []struct {
+			kind tokenKind
+			re   *regexp.Regexp
+		}{{tokIRI, iriRef}, {tokVar, varName}, {tokBlank, blankNode}, {tokPName, pname}, {tokLang, langTag}, {tokNumber, number}, {tokWord, word}} {
+			m := rule.re.FindStringSubmatch(rest)
+			if m == nil {
+				continue
+			}
+			value := m[0]
+			if len(m) > 1 {
+				value = m[1]
+			}
+			if rule.kind == tokPName {
+				value = m[0]
+			}
+			tokens = append(tokens, token{rule.kind, value, i})
+			i += len(m[0])
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		for _, p := range punctuation {
+			if strings.HasPrefix(rest, p) {
+				tokens = append(tokens, token{tokPunct, p, i})
+				i += len(p)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(query)}), nil
+}
+
+// lexString reads a short or long quoted string and returns its unescaped value
+// and the number of bytes consumed
+func lexString(s string) (string, int, error) {
+	quote := s[:1]
+	if strings.HasPrefix(s, strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	var b strings.Builder
+	for i := len(quote); i < len(s); i++ {
+		if strings.HasPrefix(s[i:], quote) {
+			return b.String(), i + len(quote), nil
+		}
+		c := s[i]
+		if len(quote) == 1 && (c == '\n' || c == '\r') {
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case '"', '\'', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			width := 4
+			if s[i] == 'U' {
+				width = 8
+			}
+			if i+width >= len(s) {
+				return "", 0, fmt.Errorf("truncated \\%c escape", s[i])
+			}
+			var r rune
+			if _, err := fmt.Sscanf(s[i+1:i+1+width], "%x", &r); err != nil {
+				return "", 0, fmt.Errorf("invalid \\%c escape", s[i])
+			}
+			b.WriteRune(r)
+			i += width
+		default:
+			return "", 0, fmt.Errorf("invalid escape \\%c", s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
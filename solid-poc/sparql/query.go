@@ -0,0 +1,570 @@
+// Package sparql evaluates a read-only subset of SPARQL 1.1 Query over an rdf.Graph:
+// SELECT, ASK and CONSTRUCT with basic graph patterns, FILTER, OPTIONAL, DISTINCT,
+// ORDER BY, LIMIT and OFFSET. Results are written in the SPARQL 1.1 JSON and CSV
+// result formats; CONSTRUCT returns a graph.
+package sparql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Form is the kind of query.
+type Form int
+
+// Query forms
+const (
+	Select Form = iota
+	Ask
+	Construct
+)
+
+func (f Form) String() string {
+	return [...]string{"SELECT", "ASK", "CONSTRUCT"}[f]
+}
+
+// Query is a parsed query.
+type Query struct {
+	Form      Form
+	Variables []string // projected variables; empty for SELECT *
+	Distinct  bool
+	Template  []TriplePattern // CONSTRUCT template
+	Where     *Group
+	OrderBy   []OrderCondition
+	Limit     int // -1 when unlimited
+	Offset    int
+	Prefixes  map[string]string
+}
+
+// Node is a variable or a fixed term in a triple pattern.
+type Node struct {
+	Var  string // variable name; blank nodes in patterns are variables named "_:label"
+	Term rdf.Term
+}
+
+func (n Node) String() string {
+	if n.Var != "" {
+		if strings.HasPrefix(n.Var, "_:") {
+			return n.Var
+		}
+		return "?" + n.Var
+	}
+	return n.Term.String()
+}
+
+// TriplePattern is a triple whose positions may be variables.
+type TriplePattern struct {
+	Subject, Predicate, Object Node
+}
+
+// Group is a { ... } group graph pattern. Its elements are joined in order
+// (triple blocks, nested groups, OPTIONAL left joins); its filters apply to the
+// whole group.
+type Group struct {
+	Elements []Element
+	Filters  []Expr
+}
+
+// Element is a part of a group: a BGP, a nested Group or an Optional.
+type Element interface{ element() }
+
+// BGP is a block of triple patterns.
+type BGP []TriplePattern
+
+// Optional is an OPTIONAL { ... } group.
+type Optional struct{ *Group }
+
+func (BGP) element()      {}
+func (*Group) element()   {}
+func (Optional) element() {}
+
+// OrderCondition is one ORDER BY key.
+type OrderCondition struct {
+	Expr       Expr
+	Descending bool
+}
+
+// ParseError reports a syntax error or an unsupported feature.
+type ParseError struct {
+	Offset  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("sparql: %s (at offset %d)", e.Message, e.Offset)
+}
+
+// unsupported keywords get a clear error instead of a syntax error
+var unsupported = map[string]string{
+	"UNION": "UNION", "MINUS": "MINUS", "GRAPH": "GRAPH", "SERVICE": "SERVICE",
+	"BIND": "BIND", "VALUES": "VALUES", "DESCRIBE": "DESCRIBE", "GROUP": "GROUP BY",
+	"HAVING": "HAVING", "FROM": "FROM", "EXISTS": "EXISTS", "NOT": "NOT EXISTS / NOT IN", "IN": "IN",
+}
+
+// update keywords are rejected: the endpoints using this package are read-only
+var updates = map[string]bool{
+	"INSERT": true, "DELETE": true, "LOAD": true, "CLEAR": true, "CREATE": true,
+	"DROP": true, "COPY": true, "MOVE": true, "ADD": true, "WITH": true,
+}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	base     string
+	prefixes map[string]string
+}
+
+// Parse parses a query. Relative IRIs resolve against base unless the query sets BASE.
+func Parse(query, base string) (q *Query, err error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, &ParseError{Message: err.Error()}
+	}
+	p := &parser{tokens: tokens, base: base, prefixes: make(map[string]string)}
+	for prefix, namespace := range rdf.CommonPrefixes {
+		p.prefixes[prefix] = namespace
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*ParseError)
+			if !ok {
+				panic(r)
+			}
+			q, err = nil, perr
+		}
+	}()
+	return p.query(), nil
+}
+
+// fail aborts parsing; Parse recovers the error
+func (p *parser) fail(format string, args ...interface{}) {
+	panic(&ParseError{Offset: p.peek().pos, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the (case-insensitive) keyword
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.value, kw)
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.keyword(kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) punct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.value == s
+}
+
+func (p *parser) accept(s string) bool {
+	if p.punct(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) {
+	if !p.accept(s) {
+		p.fail("expected %q, found %s", s, p.peek())
+	}
+}
+
+// checkSupported fails on update operations and query features outside the subset
+func (p *parser) checkSupported() {
+	t := p.peek()
+	if t.kind != tokWord {
+		return
+	}
+	upper := strings.ToUpper(t.value)
+	if updates[upper] {
+		p.fail("SPARQL Update (%s) is not allowed: the endpoint is read-only", upper)
+	}
+	if feature, ok := unsupported[upper]; ok {
+		p.fail("%s is not supported", feature)
+	}
+}
+
+func (p *parser) query() *Query {
+	q := &Query{Limit: -1}
+	p.prologue()
+
+	p.checkSupported()
+	switch {
+	case p.acceptKeyword("SELECT"):
+		q.Form = Select
+		if p.acceptKeyword("DISTINCT") || p.acceptKeyword("REDUCED") {
+			q.Distinct = true
+		}
+		if !p.accept("*") {
+			for p.peek().kind == tokVar {
+				q.Variables = append(q.Variables, p.next().value)
+			}
+			if len(q.Variables) == 0 {
+				if p.punct("(") {
+					p.fail("SELECT expressions are not supported")
+				}
+				p.fail("expected variables or * after SELECT")
+			}
+		}
+		p.checkSupported()
+		p.acceptKeyword("WHERE")
+		q.Where = p.group()
+	case p.acceptKeyword("ASK"):
+		q.Form = Ask
+		p.checkSupported()
+		p.acceptKeyword("WHERE")
+		q.Where = p.group()
+	case p.acceptKeyword("CONSTRUCT"):
+		q.Form = Construct
+		if p.keyword("WHERE") {
+			p.fail("CONSTRUCT WHERE shorthand is not supported; give a template")
+		}
+		p.expect("{")
+		if !p.punct("}") {
+			q.Template = p.triplesBlock()
+		}
+		p.expect("}")
+		p.checkSupported()
+		p.acceptKeyword("WHERE")
+		q.Where = p.group()
+	default:
+		p.fail("expected SELECT, ASK or CONSTRUCT, found %s", p.peek())
+	}
+
+	p.solutionModifiers(q)
+	if t := p.peek(); t.kind != tokEOF {
+		p.checkSupported()
+		p.fail("unexpected %s after the query", t)
+	}
+	q.Prefixes = p.prefixes
+	return q
+}
+
+// prologue reads BASE and PREFIX declarations
+func (p *parser) prologue() {
+	for {
+		switch {
+		case p.acceptKeyword("BASE"):
+			t := p.next()
+			if t.kind != tokIRI {
+				p.fail("expected an IRI after BASE")
+			}
+			p.base = p.resolve(t.value)
+		case p.acceptKeyword("PREFIX"):
+			t := p.next()
+			if t.kind != tokPName || !strings.HasSuffix(t.value, ":") {
+				p.fail("expected a prefix name after PREFIX")
+			}
+			iri := p.next()
+			if iri.kind != tokIRI {
+				p.fail("expected an IRI for prefix %s", t.value)
+			}
+			p.prefixes[strings.TrimSuffix(t.value, ":")] = p.resolve(iri.value)
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) solutionModifiers(q *Query) {
+	p.checkSupported()
+	if p.acceptKeyword("ORDER") {
+		if !p.acceptKeyword("BY") {
+			p.fail("expected BY after ORDER")
+		}
+		for {
+			condition, ok := p.orderCondition()
+			if !ok {
+				break
+			}
+			q.OrderBy = append(q.OrderBy, condition)
+		}
+		if len(q.OrderBy) == 0 {
+			p.fail("expected an ORDER BY condition")
+		}
+	}
+	for {
+		switch {
+		case p.acceptKeyword("LIMIT"):
+			q.Limit = p.count("LIMIT")
+		case p.acceptKeyword("OFFSET"):
+			q.Offset = p.count("OFFSET")
+		default:
+			return
+		}
+	}
+}
+
+// orderCondition reads ?var, (expr), ASC(expr) or DESC(expr)
+func (p *parser) orderCondition() (OrderCondition, bool) {
+	switch {
+	case p.peek().kind == tokVar:
+		return OrderCondition{Expr: varExpr(p.next().value)}, true
+	case p.keyword("ASC"), p.keyword("DESC"):
+		descending := strings.EqualFold(p.next().value, "DESC")
+		p.expect("(")
+		e := p.expression()
+		p.expect(")")
+		return OrderCondition{Expr: e, Descending: descending}, true
+	case p.accept("("):
+		e := p.expression()
+		p.expect(")")
+		return OrderCondition{Expr: e}, true
+	}
+	return OrderCondition{}, false
+}
+
+func (p *parser) count(clause string) int {
+	t := p.next()
+	n, err := strconv.Atoi(t.value)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		p.fail("%s needs a non-negative integer", clause)
+	}
+	return n
+}
+
+// group reads a group graph pattern
+func (p *parser) group() *Group {
+	p.expect("{")
+	g := &Group{}
+	for !p.accept("}") {
+		p.checkSupported()
+		switch {
+		case p.acceptKeyword("FILTER"):
+			g.Filters = append(g.Filters, p.constraint())
+		case p.acceptKeyword("OPTIONAL"):
+			g.Elements = append(g.Elements, Optional{p.group()})
+		case p.punct("{"):
+			g.Elements = append(g.Elements, p.group())
+		case p.peek().kind == tokEOF:
+			p.fail("unterminated group pattern")
+		default:
+			g.Elements = append(g.Elements, p.triplesBlock())
+		}
+		p.accept(".")
+	}
+	return g
+}
+
+// constraint reads the expression after FILTER: bracketed, or a function call
+func (p *parser) constraint() Expr {
+	if p.punct("(") {
+		p.next()
+		e := p.expression()
+		p.expect(")")
+		return e
+	}
+	t := p.peek()
+	if t.kind == tokWord || t.kind == tokIRI || t.kind == tokPName {
+		return p.primary()
+	}
+	p.fail("expected ( after FILTER")
+	return nil
+}
+
+// triplesBlock reads triples separated by "." up to the next non-triple element
+func (p *parser) triplesBlock() BGP {
+	var block BGP
+	for p.startsTerm() {
+		subject := p.node(true)
+		p.propertyList(subject, &block)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if len(block) == 0 {
+		p.fail("expected a triple pattern, found %s", p.peek())
+	}
+	return block
+}
+
+func (p *parser) startsTerm() bool {
+	switch t := p.peek(); t.kind {
+	case tokIRI, tokPName, tokVar, tokBlank, tokString, tokNumber:
+		return true
+	case tokWord:
+		return strings.EqualFold(t.value, "true") || strings.EqualFold(t.value, "false")
+	case tokPunct:
+		return t.value == "-" || t.value == "+"
+	}
+	return false
+}
+
+// propertyList reads "p o, o ; p o" for a subject
+func (p *parser) propertyList(subject Node, block *BGP) {
+	for {
+		var predicate Node
+		if t := p.peek(); t.kind == tokWord && t.value == "a" {
+			p.next()
+			predicate = Node{Term: rdf.IRI(rdf.RDFType)}
+		} else {
+			predicate = p.node(false)
+			if predicate.Var == "" && !predicate.Term.IsIRI() {
+				p.fail("predicate must be an IRI or a variable")
+			}
+		}
+		for {
+			*block = append(*block, TriplePattern{subject, predicate, p.node(false)})
+			if !p.accept(",") {
+				break
+			}
+		}
+		if !p.accept(";") {
+			return
+		}
+		for p.accept(";") {
+		}
+		if !p.startsTerm() && !(p.peek().kind == tokWord && p.peek().value == "a") {
+			return
+		}
+	}
+}
+
+// node reads a variable, IRI, blank node or literal
+func (p *parser) node(subject bool) Node {
+	t := p.peek()
+	switch t.kind {
+	case tokVar:
+		p.next()
+		return Node{Var: t.value}
+	case tokBlank:
+		p.next()
+		return Node{Var: "_:" + t.value}
+	case tokIRI, tokPName:
+		return Node{Term: p.iri()}
+	}
+	if subject {
+		p.fail("expected a subject, found %s", t)
+	}
+	return Node{Term: p.literal()}
+}
+
+// iri reads an IRI reference or prefixed name
+func (p *parser) iri() rdf.Term {
+	t := p.next()
+	switch t.kind {
+	case tokIRI:
+		return rdf.IRI(p.resolve(t.value))
+	case tokPName:
+		prefix, local, _ := strings.Cut(t.value, ":")
+		namespace, ok := p.prefixes[prefix]
+		if !ok {
+			p.pos--
+			p.fail("undeclared prefix %q", prefix)
+		}
+		return rdf.IRI(namespace + unescapeLocal(local))
+	}
+	p.pos--
+	p.fail("expected an IRI, found %s", t)
+	return rdf.Term{}
+}
+
+// literal reads a string (with language or datatype), number or boolean
+func (p *parser) literal() rdf.Term {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		if p.peek().kind == tokLang {
+			return rdf.LangLiteral(t.value, p.next().value)
+		}
+		if p.accept("^^") {
+			return rdf.TypedLiteral(t.value, p.iri().Value)
+		}
+		return rdf.Literal(t.value)
+	case tokNumber:
+		return numberLiteral(t.value)
+	case tokWord:
+		switch strings.ToLower(t.value) {
+		case "true":
+			return rdf.Boolean(true)
+		case "false":
+			return rdf.Boolean(false)
+		}
+	case tokPunct:
+		if (t.value == "-" || t.value == "+") && p.peek().kind == tokNumber {
+			n := numberLiteral(p.next().value)
+			if t.value == "-" {
+				n.Value = "-" + n.Value
+			}
+			return n
+		}
+	}
+	p.pos--
+	p.fail("expected a term, found %s", t)
+	return rdf.Term{}
+}
+
+func numberLiteral(lexical string) rdf.Term {
+	switch {
+	case strings.ContainsAny(lexical, "eE"):
+		return rdf.TypedLiteral(lexical, rdf.XSDDouble)
+	case strings.Contains(lexical, "."):
+		return rdf.TypedLiteral(lexical, rdf.XSDDecimal)
+	}
+	return rdf.TypedLiteral(lexical, rdf.XSDInteger)
+}
+
+// unescapeLocal removes backslash escapes from a prefixed name's local part
+func unescapeLocal(local string) string {
+	return strings.ReplaceAll(local, "\\", "")
+}
+
+func (p *parser) resolve(ref string) string {
+	if p.base == "" {
+		return ref
+	}
+	resolved, err := rdf.ResolveIRI(p.base, ref)
+	if err != nil {
+		p.fail("invalid IRI <%s>: %v", ref, err)
+	}
+	return resolved
+}
+
+// variables lists the variables a group binds, in order of appearance
+func (g *Group) variables() []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(n Node) {
+		if n.Var != "" && !strings.HasPrefix(n.Var, "_:") && !seen[n.Var] {
+			seen[n.Var] = true
+			names = append(names, n.Var)
+		}
+	}
+	var walk func(g *Group)
+	walk = func(g *Group) {
+		for _, element := range g.Elements {
+			switch e := element.(type) {
+			case BGP:
+				for _, t := range e {
+					add(t.Subject)
+					add(t.Predicate)
+					add(t.Object)
+				}
+			case *Group:
+				walk(e)
+			case Optional:
+				walk(e.Group)
+			}
+		}
+	}
+	walk(g)
+	return names
+}
@@ -0,0 +1,109 @@
+package sparql
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/rdf"
+)
+
+// Result media types
+const (
+	MediaTypeJSON = "application/sparql-results+json"
+	MediaTypeCSV  = "text/csv"
+)
+
+// jsonTerm is a term in the SPARQL 1.1 Query Results JSON Format
+type jsonTerm struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Language string `json:"xml:lang,omitempty"`
+	Datatype string `json:"datatype,omitempty"`
+}
+
+type jsonResults struct {
+	Head struct {
+		Vars []string `json:"vars,omitempty"`
+	} `json:"head"`
+	Results *struct {
+		Bindings []map[string]jsonTerm `json:"bindings"`
+	} `json:"results,omitempty"`
+	Boolean *bool `json:"boolean,omitempty"`
+}
+
+// WriteJSON writes a SELECT or ASK result in the SPARQL 1.1 JSON results format.
+func (r *Result) WriteJSON(w io.Writer) error {
+	var out jsonResults
+	switch r.Form {
+	case Ask:
+		out.Boolean = &r.Boolean
+	case Select:
+		out.Head.Vars = r.Variables
+		if out.Head.Vars == nil {
+			out.Head.Vars = []string{}
+		}
+		out.Results = &struct {
+			Bindings []map[string]jsonTerm `json:"bindings"`
+		}{Bindings: make([]map[string]jsonTerm, len(r.Solutions))}
+		for i, s := range r.Solutions {
+			binding := make(map[string]jsonTerm, len(s))
+			for name, t := range s {
+				binding[name] = toJSONTerm(t)
+			}
+			out.Results.Bindings[i] = binding
+		}
+	default:
+		return fmt.Errorf("sparql: %s results are a graph, not a result set", r.Form)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func toJSONTerm(t rdf.Term) jsonTerm {
+	switch t.Kind {
+	case rdf.KindIRI:
+		return jsonTerm{Type: "uri", Value: t.Value}
+	case rdf.KindBlank:
+		return jsonTerm{Type: "bnode", Value: t.Value}
+	}
+	term := jsonTerm{Type: "literal", Value: t.Value, Language: t.Language}
+	if t.Language == "" && t.Datatype != rdf.XSDString {
+		term.Datatype = t.Datatype
+	}
+	return term
+}
+
+// WriteCSV writes a SELECT result in the SPARQL 1.1 CSV results format: a header of
+// variable names, then one row per solution with plain values (blank nodes as _:label).
+func (r *Result) WriteCSV(w io.Writer) error {
+	if r.Form != Select {
+		return fmt.Errorf("sparql: CSV results are only defined for SELECT queries")
+	}
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	if err := writer.Write(r.Variables); err != nil {
+		return err
+	}
+	row := make([]string, len(r.Variables))
+	for _, s := range r.Solutions {
+		for i, name := range r.Variables {
+			t, ok := s[name]
+			switch {
+			case !ok:
+				row[i] = ""
+			case t.IsBlank():
+				row[i] = "_:" + t.Value
+			default:
+				row[i] = t.Value
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
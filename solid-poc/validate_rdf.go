@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/justin4957/ec2-test-apps/solid-poc/solid"
+)
+
+// runValidateRDFCommand checks Turtle/JSON-LD files (or directories of them)
+// against the SHACL shapes of the kind named by each file
+func runValidateRDFCommand(args []string) int {
+	flags := flag.NewFlagSet("validate-rdf", flag.ContinueOnError)
+	strict := flags.Bool("strict", false, "Fail on warnings as well as violations")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	targets := flags.Args()
+	if len(targets) == 0 {
+		targets = []string{"../rdf-schemas/examples"}
+	}
+
+	var results []solid.ExampleResult
+	for _, target := range targets {
+		info, err := os.Stat(target)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 2
+		}
+		if !info.IsDir() {
+			results = append(results, solid.CheckFile(target))
+			continue
+		}
+		found, err := solid.CheckExamples(target)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return 2
+		}
+		results = append(results, found...)
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Printf("❌ %s: %v\n", result.Path, result.Err)
+		case !result.Conforms() || (*strict && !result.Report.Conforms):
+			failed++
+			fmt.Printf("❌ %s (%s shapes)\n%s\n", result.Path, result.Kind, result.Report)
+		case !result.Report.Conforms:
+			fmt.Printf("⚠️  %s (%s shapes)\n%s\n", result.Path, result.Kind, result.Report)
+		default:
+			fmt.Printf("✅ %s (%s shapes)\n", result.Path, result.Kind)
+		}
+	}
+	if failed > 0 {
+		log.Printf("❌ %d of %d files do not conform", failed, len(results))
+		return 1
+	}
+	log.Printf("✅ All %d files conform", len(results))
+	return 0
+}